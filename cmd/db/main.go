@@ -13,6 +13,10 @@ func main() {
 	reset := flag.Bool("reset", false, "Setzt die Datenbank zurück (löscht alle Daten)")
 	seed := flag.Bool("seed", false, "Füllt die Datenbank mit Seed-Daten")
 	resetAndSeed := flag.Bool("reset-and-seed", false, "Setzt die Datenbank zurück und füllt sie mit Seed-Daten")
+	createTenant := flag.String("create-tenant", "", "Legt eine Organisation mit dem angegebenen Kürzel (Subdomain) an")
+	tenantName := flag.String("tenant-name", "", "Name der neuen Organisation (Standard: Kürzel)")
+	createToken := flag.String("create-token", "", "Erzeugt ein API-Token für den angegebenen Benutzernamen")
+	tenantSlug := flag.String("tenant", "default", "Kürzel der Organisation für -create-token")
 
 	flag.Parse()
 
@@ -39,11 +43,25 @@ func main() {
 			log.Fatal("Fehler beim Seed:", err)
 		}
 		log.Println("Seed erfolgreich abgeschlossen")
+	} else if *createTenant != "" {
+		tenant, err := database.CreateTenant(*tenantName, *createTenant)
+		if err != nil {
+			log.Fatal("Fehler beim Anlegen der Organisation:", err)
+		}
+		log.Printf("Organisation %q (ID %d) angelegt", tenant.Slug, tenant.ID)
+	} else if *createToken != "" {
+		token, err := database.CreateAPIToken(*tenantSlug, *createToken, "Kommandozeile")
+		if err != nil {
+			log.Fatal("Fehler beim Erzeugen des API-Tokens:", err)
+		}
+		log.Println("API-Token (wird nur einmal angezeigt):", token)
 	} else {
 		log.Println("Verwendung:")
 		log.Println("  ./db -reset              # Setzt die Datenbank zurück")
 		log.Println("  ./db -seed               # Füllt die Datenbank mit Seed-Daten")
 		log.Println("  ./db -reset-and-seed     # Reset und Seed in einem Schritt")
+		log.Println("  ./db -create-tenant nord -tenant-name \"Werk Nord\"  # Legt eine Organisation an")
+		log.Println("  ./db -create-token admin -tenant nord                # Erzeugt ein API-Token")
 		os.Exit(1)
 	}
 }
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"fmt"
 	"log"
 	"os"
 
@@ -35,8 +36,13 @@ func InitDatabase() {
 
 	log.Println("Datenbank erfolgreich verbunden")
 
-	// Auto-Migration für alle Modelle
-	if err := DB.AutoMigrate(&models.User{}, &models.Shift{}, &models.Schedule{}, &models.Team{}, &models.ShiftType{}, &models.ShiftTemplate{}); err != nil {
+	// Mandantenschutz: Zugriffe mit Organisation im Kontext werden auf diese beschränkt
+	if err := DB.Use(TenantGuard{}); err != nil {
+		log.Fatal("Fehler beim Registrieren des Mandantenschutzes:", err)
+	}
+
+	// Schema migrieren und Standardorganisation anlegen
+	if err := Migrate(DB); err != nil {
 		log.Fatal("Fehler bei der Datenbank-Migration:", err)
 	}
 
@@ -55,3 +61,100 @@ func CloseDatabase() {
 		log.Println("Datenbankverbindung geschlossen")
 	}
 }
+
+// Migrate bringt das Schema auf den aktuellen Stand: Zuerst werden globale Eindeutigkeiten aus der Zeit vor den
+// Organisationen entfernt, danach alle Modelle migriert und die Standardorganisation angelegt.
+func Migrate(db *gorm.DB) error {
+	if err := dropLegacyUniqueIndexes(db); err != nil {
+		return err
+	}
+
+	// Auto-Migration für alle Modelle
+	if err := db.AutoMigrate(
+		&models.Tenant{},
+		&models.APIToken{},
+		&models.User{},
+		&models.Shift{},
+		&models.Schedule{},
+		&models.Team{},
+		&models.ShiftType{},
+		&models.ShiftTemplate{},
+		&models.RecurringShift{},
+		&models.RecurringShiftException{},
+		&models.TeamRule{},
+		&models.CompanyHoliday{},
+		&models.SurchargeRule{},
+		&models.Absence{},
+		&models.AbsenceCreditRule{},
+		&models.TimeAccountCorrection{},
+		&models.EmploymentContract{},
+		&models.Qualification{},
+		&models.UserQualification{},
+		&models.Location{},
+		&models.TimeEntry{},
+		&models.TimeEntryBreak{},
+		&models.TimeEntryCorrection{},
+		&models.Timesheet{},
+		&models.TimesheetEvent{},
+		&models.WageTypeMapping{},
+		&models.PayrollSettings{},
+		&models.PayrollExport{},
+		&models.OnCallDuty{},
+		&models.ChecklistTemplate{},
+		&models.ShiftChecklist{},
+		&models.ShiftChecklistItem{},
+		&models.Comment{},
+		&models.CommentMention{},
+		&models.CommentRevision{},
+		&models.CalendarFeed{},
+		&models.CalendarSyncState{},
+		&models.UserInvitation{},
+	); err != nil {
+		return err
+	}
+
+	return EnsureDefaultTenant(db)
+}
+
+// legacyUniqueIndexes sind globale eindeutige Indizes älterer Installationen; sie würden gleiche Benutzernamen,
+// E-Mail-Adressen und Personalnummern in verschiedenen Organisationen verhindern
+var legacyUniqueIndexes = []struct {
+	model interface{}
+	name  string
+}{
+	{&models.User{}, "idx_users_username"},
+	{&models.User{}, "idx_users_email"},
+	{&models.User{}, "idx_users_account_number"},
+}
+
+// legacyUniqueConstraints sind globale UNIQUE-Constraints älterer Installationen auf Namen, die heute je
+// Organisation eindeutig sind
+var legacyUniqueConstraints = []struct {
+	model interface{}
+	name  string
+}{
+	{&models.Team{}, "uni_teams_name"},
+	{&models.ShiftType{}, "uni_shift_types_name"},
+	{&models.ShiftTemplate{}, "uni_shift_templates_name"},
+}
+
+// dropLegacyUniqueIndexes entfernt die globalen Eindeutigkeiten; AutoMigrate legt nur die neuen Indizes je
+// Organisation an und lässt die alten bestehen. In SQLite wird die Tabelle für Constraints neu aufgebaut.
+func dropLegacyUniqueIndexes(db *gorm.DB) error {
+	migrator := db.Migrator()
+	for _, index := range legacyUniqueIndexes {
+		if migrator.HasTable(index.model) && migrator.HasIndex(index.model, index.name) {
+			if err := migrator.DropIndex(index.model, index.name); err != nil {
+				return fmt.Errorf("Index %s konnte nicht entfernt werden: %w", index.name, err)
+			}
+		}
+	}
+	for _, constraint := range legacyUniqueConstraints {
+		if migrator.HasTable(constraint.model) && migrator.HasConstraint(constraint.model, constraint.name) {
+			if err := migrator.DropConstraint(constraint.model, constraint.name); err != nil {
+				return fmt.Errorf("Constraint %s konnte nicht entfernt werden: %w", constraint.name, err)
+			}
+		}
+	}
+	return nil
+}
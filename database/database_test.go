@@ -16,7 +16,7 @@ func setupTestDB(t *testing.T) {
 	DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	assert.NoError(t, err)
 	// Migration durchführen
-	err = DB.AutoMigrate(&models.User{}, &models.Shift{}, &models.Schedule{}, &models.Team{}, &models.ShiftType{}, &models.ShiftTemplate{})
+	err = DB.AutoMigrate(&models.User{}, &models.Shift{}, &models.Schedule{}, &models.Team{}, &models.ShiftType{}, &models.ShiftTemplate{}, &models.RecurringShift{}, &models.RecurringShiftException{}, &models.TeamRule{}, &models.CompanyHoliday{}, &models.SurchargeRule{}, &models.Absence{}, &models.AbsenceCreditRule{}, &models.TimeAccountCorrection{}, &models.EmploymentContract{}, &models.Qualification{}, &models.UserQualification{}, &models.Location{}, &models.Tenant{}, &models.APIToken{}, &models.TimeEntry{}, &models.TimeEntryBreak{}, &models.TimeEntryCorrection{}, &models.Timesheet{}, &models.TimesheetEvent{}, &models.WageTypeMapping{}, &models.PayrollSettings{}, &models.PayrollExport{}, &models.OnCallDuty{}, &models.ChecklistTemplate{}, &models.ShiftChecklist{}, &models.ShiftChecklistItem{}, &models.Comment{}, &models.CommentMention{}, &models.CommentRevision{}, &models.CalendarFeed{}, &models.CalendarSyncState{}, &models.UserInvitation{})
 	assert.NoError(t, err)
 }
 
@@ -29,7 +29,7 @@ func TestInitDatabase(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Migration sollte funktionieren
-	err = DB.AutoMigrate(&models.User{}, &models.Shift{}, &models.Schedule{}, &models.Team{}, &models.ShiftType{}, &models.ShiftTemplate{})
+	err = DB.AutoMigrate(&models.User{}, &models.Shift{}, &models.Schedule{}, &models.Team{}, &models.ShiftType{}, &models.ShiftTemplate{}, &models.RecurringShift{}, &models.RecurringShiftException{}, &models.TeamRule{}, &models.CompanyHoliday{}, &models.SurchargeRule{}, &models.Absence{}, &models.AbsenceCreditRule{}, &models.TimeAccountCorrection{}, &models.EmploymentContract{}, &models.Qualification{}, &models.UserQualification{}, &models.Location{}, &models.Tenant{}, &models.APIToken{}, &models.TimeEntry{}, &models.TimeEntryBreak{}, &models.TimeEntryCorrection{}, &models.Timesheet{}, &models.TimesheetEvent{}, &models.WageTypeMapping{}, &models.PayrollSettings{}, &models.PayrollExport{}, &models.OnCallDuty{}, &models.ChecklistTemplate{}, &models.ShiftChecklist{}, &models.ShiftChecklistItem{}, &models.Comment{}, &models.CommentMention{}, &models.CommentRevision{}, &models.CalendarFeed{}, &models.CalendarSyncState{}, &models.UserInvitation{})
 	assert.NoError(t, err)
 
 	// Prüfe, ob Tabellen existieren
@@ -70,3 +70,61 @@ func TestDatabaseErrorHandling(t *testing.T) {
 	// Stelle DB wieder her
 	DB = originalDB
 }
+
+// baselineSchema ist das Schema von Benutzern, Teams, Schichttypen und Schichtvorlagen vor Einführung der
+// Organisationen, wie es AutoMigrate damals angelegt hat (mit globalen Eindeutigkeiten)
+var baselineSchema = []string{
+	"CREATE TABLE `teams` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`updated_at` datetime,`deleted_at` datetime,`name` text NOT NULL,`description` text,`color` text DEFAULT \"#6B7280\",`is_active` numeric DEFAULT true,`sort_order` integer DEFAULT 0,CONSTRAINT `uni_teams_name` UNIQUE (`name`))",
+	"CREATE INDEX `idx_teams_deleted_at` ON `teams`(`deleted_at`)",
+	"CREATE TABLE `users` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`updated_at` datetime,`deleted_at` datetime,`username` text NOT NULL,`email` text NOT NULL,`password` text NOT NULL,`account_number` text,`name` text NOT NULL,`color` text,`role` text DEFAULT \"user\",`is_active` numeric DEFAULT true,`is_admin` numeric DEFAULT false,`team_id` integer,CONSTRAINT `fk_teams_users` FOREIGN KEY (`team_id`) REFERENCES `teams`(`id`))",
+	"CREATE UNIQUE INDEX `idx_users_account_number` ON `users`(`account_number`)",
+	"CREATE UNIQUE INDEX `idx_users_email` ON `users`(`email`)",
+	"CREATE UNIQUE INDEX `idx_users_username` ON `users`(`username`)",
+	"CREATE INDEX `idx_users_deleted_at` ON `users`(`deleted_at`)",
+	"CREATE TABLE `shift_types` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`updated_at` datetime,`deleted_at` datetime,`name` text NOT NULL,`description` text,`color` text DEFAULT \"#3B82F6\",`default_start` datetime,`default_end` datetime,`default_break` integer DEFAULT 30,`is_active` numeric DEFAULT true,`sort_order` integer DEFAULT 0,`min_duration` integer DEFAULT 0,`max_duration` integer DEFAULT 0,CONSTRAINT `uni_shift_types_name` UNIQUE (`name`))",
+	"CREATE INDEX `idx_shift_types_deleted_at` ON `shift_types`(`deleted_at`)",
+	"CREATE TABLE `shift_templates` (`id` integer PRIMARY KEY AUTOINCREMENT,`created_at` datetime,`updated_at` datetime,`deleted_at` datetime,`name` text NOT NULL,`description` text,`color` text DEFAULT \"#6B7280\",`is_active` numeric DEFAULT true,`sort_order` integer DEFAULT 0,`monday_shift_type_id` integer,`tuesday_shift_type_id` integer,`wednesday_shift_type_id` integer,`thursday_shift_type_id` integer,`friday_shift_type_id` integer,`saturday_shift_type_id` integer,`sunday_shift_type_id` integer,CONSTRAINT `fk_shift_templates_monday_shift_type` FOREIGN KEY (`monday_shift_type_id`) REFERENCES `shift_types`(`id`),CONSTRAINT `uni_shift_templates_name` UNIQUE (`name`))",
+	"CREATE INDEX `idx_shift_templates_deleted_at` ON `shift_templates`(`deleted_at`)",
+	"INSERT INTO `teams` (`name`) VALUES ('Pflege')",
+	"INSERT INTO `users` (`username`,`email`,`password`,`account_number`,`name`,`team_id`) VALUES ('anna','anna@example.com','x','1001','Anna',1)",
+	"INSERT INTO `shift_types` (`name`) VALUES ('Frühschicht')",
+	"INSERT INTO `shift_templates` (`name`) VALUES ('Woche')",
+}
+
+func TestMigrate_UpgradesBaselineSchema(t *testing.T) {
+	var err error
+	DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	for _, statement := range baselineSchema {
+		assert.NoError(t, DB.Exec(statement).Error)
+	}
+
+	assert.NoError(t, Migrate(DB))
+	// Ein zweiter Lauf ändert nichts mehr
+	assert.NoError(t, Migrate(DB))
+
+	// Bestehende Daten gehören zur Standardorganisation
+	var anna models.User
+	assert.NoError(t, DB.Where("username = ?", "anna").First(&anna).Error)
+	assert.Equal(t, models.DefaultTenantID, anna.TenantID)
+	assert.NotNil(t, anna.TeamID)
+
+	// Gleiche Namen sind in einer anderen Organisation erlaubt, in derselben weiterhin nicht
+	assert.NoError(t, DB.Create(&models.Tenant{ID: 2, Name: "Werk Nord", Slug: "nord", IsActive: true}).Error)
+	user := models.User{Username: "anna", Email: "anna@example.com", Password: "x", AccountNumber: "1001", Name: "Anna Nord"}
+	user.TenantID = 2
+	assert.NoError(t, DB.Create(&user).Error)
+	team := models.Team{Name: "Pflege"}
+	team.TenantID = 2
+	assert.NoError(t, DB.Create(&team).Error)
+	shiftType := models.ShiftType{Name: "Frühschicht"}
+	shiftType.TenantID = 2
+	assert.NoError(t, DB.Create(&shiftType).Error)
+	template := models.ShiftTemplate{Name: "Woche"}
+	template.TenantID = 2
+	assert.NoError(t, DB.Create(&template).Error)
+
+	assert.Error(t, DB.Create(&models.User{Username: "anna", Email: "anna2@example.com", Password: "x", AccountNumber: "1002", Name: "Anna Zwei"}).Error)
+	assert.Error(t, DB.Create(&models.Team{Name: "Pflege"}).Error)
+	assert.Error(t, DB.Create(&models.ShiftType{Name: "Frühschicht"}).Error)
+}
@@ -21,6 +21,96 @@ func ResetDatabase() error {
 	}
 
 	// Lösche alle Daten aus allen Tabellen
+	if err := DB.Exec("DELETE FROM api_tokens").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM calendar_feeds").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM calendar_sync_states").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM user_invitations").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM comment_revisions").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM comment_mentions").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM comments").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM shift_checklist_items").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM shift_checklists").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM checklist_templates").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM payroll_exports").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM payroll_settings").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM wage_type_mappings").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM timesheet_events").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM timesheets").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM time_entry_corrections").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM time_entry_breaks").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM time_entries").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM on_call_duties").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM team_rules").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM company_holidays").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM surcharge_rules").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM absences").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM absence_credit_rules").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM time_account_corrections").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM employment_contracts").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM user_qualifications").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM qualifications").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM recurring_shift_exceptions").Error; err != nil {
+		return err
+	}
+	if err := DB.Exec("DELETE FROM recurring_shifts").Error; err != nil {
+		return err
+	}
 	if err := DB.Exec("DELETE FROM shifts").Error; err != nil {
 		return err
 	}
@@ -39,9 +129,16 @@ func ResetDatabase() error {
 	if err := DB.Exec("DELETE FROM shift_templates").Error; err != nil {
 		return err
 	}
+	if err := DB.Exec("DELETE FROM locations").Error; err != nil {
+		return err
+	}
+	// Die Standardorganisation bleibt erhalten
+	if err := DB.Exec("DELETE FROM tenants WHERE id <> ?", models.DefaultTenantID).Error; err != nil {
+		return err
+	}
 
 	// Setze Auto-Increment-Zähler zurück
-	if err := DB.Exec("DELETE FROM sqlite_sequence WHERE name IN ('users', 'schedules', 'shifts', 'teams', 'shift_types', 'shift_templates')").Error; err != nil {
+	if err := DB.Exec("DELETE FROM sqlite_sequence WHERE name IN ('users', 'schedules', 'shifts', 'teams', 'shift_types', 'shift_templates', 'recurring_shifts', 'recurring_shift_exceptions', 'team_rules', 'company_holidays', 'surcharge_rules', 'absences', 'absence_credit_rules', 'time_account_corrections', 'employment_contracts', 'qualifications', 'user_qualifications', 'locations', 'api_tokens', 'tenants', 'time_entries', 'time_entry_breaks', 'time_entry_corrections', 'timesheets', 'timesheet_events', 'wage_type_mappings', 'payroll_settings', 'payroll_exports', 'on_call_duties', 'checklist_templates', 'shift_checklists', 'shift_checklist_items', 'comments', 'comment_mentions', 'comment_revisions', 'calendar_feeds', 'calendar_sync_states', 'user_invitations')").Error; err != nil {
 		return err
 	}
 
@@ -118,8 +215,8 @@ func SeedDatabase() error {
 			Name:         "Frühschicht",
 			Description:  "Schicht von 6:00 bis 14:00 Uhr",
 			Color:        "#3B82F6",
-			DefaultStart: time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC),
-			DefaultEnd:   time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC),
+			DefaultStart: models.NewWallClock(6, 0),
+			DefaultEnd:   models.NewWallClock(14, 0),
 			DefaultBreak: 30,
 			IsActive:     true,
 			SortOrder:    1,
@@ -130,8 +227,8 @@ func SeedDatabase() error {
 			Name:         "Spätschicht",
 			Description:  "Schicht von 14:00 bis 22:00 Uhr",
 			Color:        "#EF4444",
-			DefaultStart: time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC),
-			DefaultEnd:   time.Date(2024, 1, 1, 22, 0, 0, 0, time.UTC),
+			DefaultStart: models.NewWallClock(14, 0),
+			DefaultEnd:   models.NewWallClock(22, 0),
 			DefaultBreak: 30,
 			IsActive:     true,
 			SortOrder:    2,
@@ -142,8 +239,8 @@ func SeedDatabase() error {
 			Name:         "Nachtschicht",
 			Description:  "Schicht von 22:00 bis 6:00 Uhr",
 			Color:        "#8B5CF6",
-			DefaultStart: time.Date(2024, 1, 1, 22, 0, 0, 0, time.UTC),
-			DefaultEnd:   time.Date(2024, 1, 2, 6, 0, 0, 0, time.UTC),
+			DefaultStart: models.NewWallClock(22, 0),
+			DefaultEnd:   models.NewWallClock(6, 0),
 			DefaultBreak: 45,
 			IsActive:     true,
 			SortOrder:    3,
@@ -154,8 +251,8 @@ func SeedDatabase() error {
 			Name:         "Teilzeit",
 			Description:  "Kürzere Schicht von 4-6 Stunden",
 			Color:        "#F59E0B",
-			DefaultStart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
-			DefaultEnd:   time.Date(2024, 1, 1, 15, 0, 0, 0, time.UTC),
+			DefaultStart: models.NewWallClock(9, 0),
+			DefaultEnd:   models.NewWallClock(15, 0),
 			DefaultBreak: 15,
 			IsActive:     true,
 			SortOrder:    4,
@@ -166,8 +263,8 @@ func SeedDatabase() error {
 			Name:         "Überstunden",
 			Description:  "Längere Schicht für besondere Anlässe",
 			Color:        "#DC2626",
-			DefaultStart: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
-			DefaultEnd:   time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC),
+			DefaultStart: models.NewWallClock(8, 0),
+			DefaultEnd:   models.NewWallClock(18, 0),
 			DefaultBreak: 60,
 			IsActive:     false, // Inaktiver Schichttyp
 			SortOrder:    5,
@@ -296,27 +393,30 @@ func SeedDatabase() error {
 		DB.Save(&peterUser)
 	}
 
+	// Alle Zeiten der Seed-Daten sind Ortszeiten der Organisation
+	loc := models.OrganisationLocation()
+
 	// Erstelle Test-Schedules
 	schedules := []models.Schedule{
 		{
 			Name:        "Januar 2024",
 			Description: "Schichtplan für Januar 2024",
-			StartDate:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
-			EndDate:     time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC),
+			StartDate:   time.Date(2024, 1, 1, 0, 0, 0, 0, loc),
+			EndDate:     time.Date(2024, 1, 31, 23, 59, 59, 0, loc),
 			IsActive:    true,
 		},
 		{
 			Name:        "Februar 2024",
 			Description: "Schichtplan für Februar 2024",
-			StartDate:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
-			EndDate:     time.Date(2024, 2, 29, 23, 59, 59, 0, time.UTC),
+			StartDate:   time.Date(2024, 2, 1, 0, 0, 0, 0, loc),
+			EndDate:     time.Date(2024, 2, 29, 23, 59, 59, 0, loc),
 			IsActive:    true,
 		},
 		{
 			Name:        "März 2024",
 			Description: "Schichtplan für März 2024",
-			StartDate:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
-			EndDate:     time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC),
+			StartDate:   time.Date(2024, 3, 1, 0, 0, 0, 0, loc),
+			EndDate:     time.Date(2024, 3, 31, 23, 59, 59, 0, loc),
 			IsActive:    false, // Inaktiver Schedule
 		},
 	}
@@ -441,8 +541,8 @@ func SeedDatabase() error {
 			UserID:      createdUsers[1].ID,       // max.mustermann
 			ScheduleID:  createdSchedules[0].ID,   // Januar 2024
 			ShiftTypeID: &createdShiftTypes[0].ID, // Frühschicht
-			StartTime:   time.Date(2024, 1, 15, 6, 0, 0, 0, time.UTC),
-			EndTime:     time.Date(2024, 1, 15, 14, 0, 0, 0, time.UTC),
+			StartTime:   time.Date(2024, 1, 15, 6, 0, 0, 0, loc),
+			EndTime:     time.Date(2024, 1, 15, 14, 0, 0, 0, loc),
 			BreakTime:   30,
 			Description: "Frühschicht",
 			IsActive:    true,
@@ -451,8 +551,8 @@ func SeedDatabase() error {
 			UserID:      createdUsers[1].ID,       // max.mustermann
 			ScheduleID:  createdSchedules[0].ID,   // Januar 2024
 			ShiftTypeID: &createdShiftTypes[1].ID, // Spätschicht
-			StartTime:   time.Date(2024, 1, 16, 14, 0, 0, 0, time.UTC),
-			EndTime:     time.Date(2024, 1, 16, 22, 0, 0, 0, time.UTC),
+			StartTime:   time.Date(2024, 1, 16, 14, 0, 0, 0, loc),
+			EndTime:     time.Date(2024, 1, 16, 22, 0, 0, 0, loc),
 			BreakTime:   30,
 			Description: "Spätschicht",
 			IsActive:    true,
@@ -461,8 +561,8 @@ func SeedDatabase() error {
 			UserID:      createdUsers[2].ID,       // anna.schmidt
 			ScheduleID:  createdSchedules[0].ID,   // Januar 2024
 			ShiftTypeID: &createdShiftTypes[1].ID, // Spätschicht
-			StartTime:   time.Date(2024, 1, 15, 14, 0, 0, 0, time.UTC),
-			EndTime:     time.Date(2024, 1, 15, 22, 0, 0, 0, time.UTC),
+			StartTime:   time.Date(2024, 1, 15, 14, 0, 0, 0, loc),
+			EndTime:     time.Date(2024, 1, 15, 22, 0, 0, 0, loc),
 			BreakTime:   30,
 			Description: "Spätschicht",
 			IsActive:    true,
@@ -471,8 +571,8 @@ func SeedDatabase() error {
 			UserID:      createdUsers[2].ID,       // anna.schmidt
 			ScheduleID:  createdSchedules[0].ID,   // Januar 2024
 			ShiftTypeID: &createdShiftTypes[0].ID, // Frühschicht
-			StartTime:   time.Date(2024, 1, 16, 6, 0, 0, 0, time.UTC),
-			EndTime:     time.Date(2024, 1, 16, 14, 0, 0, 0, time.UTC),
+			StartTime:   time.Date(2024, 1, 16, 6, 0, 0, 0, loc),
+			EndTime:     time.Date(2024, 1, 16, 14, 0, 0, 0, loc),
 			BreakTime:   30,
 			Description: "Frühschicht",
 			IsActive:    true,
@@ -481,8 +581,8 @@ func SeedDatabase() error {
 			UserID:      createdUsers[3].ID,       // peter.weber
 			ScheduleID:  createdSchedules[1].ID,   // Februar 2024
 			ShiftTypeID: &createdShiftTypes[0].ID, // Frühschicht
-			StartTime:   time.Date(2024, 2, 1, 6, 0, 0, 0, time.UTC),
-			EndTime:     time.Date(2024, 2, 1, 14, 0, 0, 0, time.UTC),
+			StartTime:   time.Date(2024, 2, 1, 6, 0, 0, 0, loc),
+			EndTime:     time.Date(2024, 2, 1, 14, 0, 0, 0, loc),
 			BreakTime:   30,
 			Description: "Frühschicht",
 			IsActive:    true,
@@ -491,8 +591,8 @@ func SeedDatabase() error {
 			UserID:      createdUsers[3].ID,       // peter.weber
 			ScheduleID:  createdSchedules[1].ID,   // Februar 2024
 			ShiftTypeID: &createdShiftTypes[1].ID, // Spätschicht
-			StartTime:   time.Date(2024, 2, 2, 14, 0, 0, 0, time.UTC),
-			EndTime:     time.Date(2024, 2, 2, 22, 0, 0, 0, time.UTC),
+			StartTime:   time.Date(2024, 2, 2, 14, 0, 0, 0, loc),
+			EndTime:     time.Date(2024, 2, 2, 22, 0, 0, 0, loc),
 			BreakTime:   30,
 			Description: "Spätschicht",
 			IsActive:    true,
@@ -502,8 +602,8 @@ func SeedDatabase() error {
 			UserID:      createdUsers[1].ID,       // max.mustermann
 			ScheduleID:  createdSchedules[0].ID,   // Januar 2024
 			ShiftTypeID: &createdShiftTypes[2].ID, // Nachtschicht
-			StartTime:   time.Date(2024, 1, 20, 22, 0, 0, 0, time.UTC),
-			EndTime:     time.Date(2024, 1, 21, 6, 0, 0, 0, time.UTC),
+			StartTime:   time.Date(2024, 1, 20, 22, 0, 0, 0, loc),
+			EndTime:     time.Date(2024, 1, 21, 6, 0, 0, 0, loc),
 			BreakTime:   45,
 			Description: "Nachtschicht",
 			IsActive:    true,
@@ -512,8 +612,8 @@ func SeedDatabase() error {
 			UserID:      createdUsers[2].ID,       // anna.schmidt
 			ScheduleID:  createdSchedules[0].ID,   // Januar 2024
 			ShiftTypeID: &createdShiftTypes[3].ID, // Teilzeit
-			StartTime:   time.Date(2024, 1, 22, 9, 0, 0, 0, time.UTC),
-			EndTime:     time.Date(2024, 1, 22, 15, 0, 0, 0, time.UTC),
+			StartTime:   time.Date(2024, 1, 22, 9, 0, 0, 0, loc),
+			EndTime:     time.Date(2024, 1, 22, 15, 0, 0, 0, loc),
 			BreakTime:   15,
 			Description: "Teilzeit",
 			IsActive:    true,
@@ -16,7 +16,7 @@ func setupSeedTestDB(t *testing.T) *gorm.DB {
 	assert.NoError(t, err)
 
 	// Migration durchführen
-	err = db.AutoMigrate(&models.User{}, &models.Shift{}, &models.Schedule{}, &models.Team{}, &models.ShiftType{}, &models.ShiftTemplate{})
+	err = db.AutoMigrate(&models.User{}, &models.Shift{}, &models.Schedule{}, &models.Team{}, &models.ShiftType{}, &models.ShiftTemplate{}, &models.RecurringShift{}, &models.RecurringShiftException{}, &models.TeamRule{}, &models.CompanyHoliday{}, &models.SurchargeRule{}, &models.Absence{}, &models.AbsenceCreditRule{}, &models.TimeAccountCorrection{}, &models.EmploymentContract{}, &models.Qualification{}, &models.UserQualification{}, &models.Location{}, &models.Tenant{}, &models.APIToken{}, &models.TimeEntry{}, &models.TimeEntryBreak{}, &models.TimeEntryCorrection{}, &models.Timesheet{}, &models.TimesheetEvent{}, &models.WageTypeMapping{}, &models.PayrollSettings{}, &models.PayrollExport{}, &models.OnCallDuty{}, &models.ChecklistTemplate{}, &models.ShiftChecklist{}, &models.ShiftChecklistItem{}, &models.Comment{}, &models.CommentMention{}, &models.CommentRevision{}, &models.CalendarFeed{}, &models.CalendarSyncState{}, &models.UserInvitation{})
 	assert.NoError(t, err)
 
 	return db
@@ -0,0 +1,233 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+)
+
+// ErrCrossTenantReference wird gemeldet, wenn ein Datensatz auf einen Datensatz einer anderen Organisation verweist
+var ErrCrossTenantReference = errors.New("Verweis auf einen Datensatz einer anderen Organisation")
+
+type tenantContextKey struct{}
+
+// WithTenant liefert einen Kontext, in dem alle Datenbankzugriffe auf die Organisation tenantID beschränkt sind
+func WithTenant(ctx context.Context, tenantID uint) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext liefert die Organisation, auf die der Kontext beschränkt ist
+func TenantFromContext(ctx context.Context) (uint, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	tenantID, ok := ctx.Value(tenantContextKey{}).(uint)
+	return tenantID, ok && tenantID != 0
+}
+
+// ForTenant liefert eine Datenbankverbindung, deren Lese- und Schreibzugriffe auf die Organisation tenantID beschränkt sind
+func ForTenant(ctx context.Context, tenantID uint) *gorm.DB {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return DB.WithContext(WithTenant(ctx, tenantID))
+}
+
+// TenantGuard ist ein GORM-Plugin, das Zugriffe mit Organisation im Kontext (siehe WithTenant) auf diese beschränkt:
+// Abfragen, Änderungen und Löschungen erhalten die Bedingung tenant_id = ?, neue und gespeicherte Datensätze die
+// Organisation, und Fremdschlüssel dürfen nur auf Datensätze derselben Organisation verweisen.
+// Zugriffe ohne Organisation im Kontext (Migration, Seed, Tests) bleiben unbeschränkt.
+type TenantGuard struct{}
+
+// Name liefert den Namen des Plugins
+func (TenantGuard) Name() string {
+	return "schichtplaner:tenant_guard"
+}
+
+// Initialize registriert die Callbacks des Plugins
+func (TenantGuard) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Create().Before("gorm:create").Register("tenant:create", guardTenantCreate); err != nil {
+		return err
+	}
+	if err := callbacks.Query().Before("gorm:query").Register("tenant:query", scopeTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenant:update", guardTenantUpdate); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("tenant:delete", scopeTenant); err != nil {
+		return err
+	}
+	return callbacks.Row().Before("gorm:row").Register("tenant:row", scopeTenant)
+}
+
+// tenantField liefert die Organisation aus dem Kontext und das Feld TenantID des Models, sofern beides vorhanden ist
+func tenantField(db *gorm.DB) (uint, *schema.Field, bool) {
+	tenantID, ok := TenantFromContext(db.Statement.Context)
+	if !ok || db.Statement.Schema == nil {
+		return 0, nil, false
+	}
+	field := db.Statement.Schema.LookUpField("TenantID")
+	if field == nil {
+		return 0, nil, false
+	}
+	return tenantID, field, true
+}
+
+// scopeTenant beschränkt Abfragen, Änderungen und Löschungen auf die Organisation aus dem Kontext
+func scopeTenant(db *gorm.DB) {
+	tenantID, field, ok := tenantField(db)
+	if !ok || db.Error != nil {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
+
+// guardTenantCreate setzt die Organisation neuer Datensätze und prüft deren Fremdschlüssel.
+// Ein Upsert (z.B. durch Save ohne vorhandenen Datensatz) darf nur Datensätze derselben Organisation überschreiben.
+func guardTenantCreate(db *gorm.DB) {
+	tenantID, field, ok := tenantField(db)
+	if !ok || db.Error != nil {
+		return
+	}
+
+	forEachRecord(db.Statement.ReflectValue, func(record reflect.Value) {
+		_ = field.Set(db.Statement.Context, record, tenantID)
+		checkTenantReferences(db, record, tenantID)
+	})
+
+	if c, ok := db.Statement.Clauses["ON CONFLICT"]; ok {
+		if onConflict, ok := c.Expression.(clause.OnConflict); ok && !onConflict.DoNothing {
+			onConflict.Where.Exprs = append(onConflict.Where.Exprs,
+				clause.Eq{Column: clause.Column{Table: db.Statement.Table, Name: field.DBName}, Value: tenantID})
+			c.Expression = onConflict
+			db.Statement.Clauses["ON CONFLICT"] = c
+		}
+	}
+}
+
+// guardTenantUpdate beschränkt Änderungen auf die Organisation, verhindert das Verschieben in eine andere
+// Organisation und prüft geänderte Fremdschlüssel
+func guardTenantUpdate(db *gorm.DB) {
+	tenantID, field, ok := tenantField(db)
+	if !ok || db.Error != nil {
+		return
+	}
+	scopeTenant(db)
+
+	forEachRecord(db.Statement.ReflectValue, func(record reflect.Value) {
+		_ = field.Set(db.Statement.Context, record, tenantID)
+	})
+
+	// Bei Save und Updates mit einem Struct stehen die neuen Werte in Dest
+	dest := reflect.Indirect(reflect.ValueOf(db.Statement.Dest))
+	if dest.IsValid() && dest.Kind() == reflect.Struct && dest.Type() == db.Statement.Schema.ModelType {
+		if dest.CanAddr() {
+			if _, zero := field.ValueOf(db.Statement.Context, dest); !zero {
+				_ = field.Set(db.Statement.Context, dest, tenantID)
+			}
+		}
+		checkTenantReferences(db, dest, tenantID)
+	}
+}
+
+// checkTenantReferences prüft, ob alle gesetzten Fremdschlüssel des Datensatzes auf Datensätze der Organisation verweisen
+func checkTenantReferences(db *gorm.DB, record reflect.Value, tenantID uint) {
+	for _, relation := range db.Statement.Schema.Relationships.BelongsTo {
+		if relation.FieldSchema == nil || relation.FieldSchema.LookUpField("TenantID") == nil || len(relation.References) != 1 {
+			continue
+		}
+		reference := relation.References[0]
+		value, zero := reference.ForeignKey.ValueOf(db.Statement.Context, record)
+		if zero {
+			continue
+		}
+
+		var count int64
+		err := db.Session(&gorm.Session{NewDB: true}).Table(relation.FieldSchema.Table).
+			Where(clause.Eq{Column: clause.Column{Name: reference.PrimaryKey.DBName}, Value: value}).
+			Where(clause.Eq{Column: clause.Column{Name: "tenant_id"}, Value: tenantID}).
+			Where(clause.Eq{Column: clause.Column{Name: "deleted_at"}, Value: nil}).
+			Count(&count).Error
+		if err != nil {
+			_ = db.AddError(err)
+			return
+		}
+		if count == 0 {
+			_ = db.AddError(ErrCrossTenantReference)
+			return
+		}
+	}
+}
+
+// forEachRecord ruft fn für jeden Datensatz eines Structs oder Slices auf
+func forEachRecord(value reflect.Value, fn func(record reflect.Value)) {
+	value = reflect.Indirect(value)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			fn(reflect.Indirect(value.Index(i)))
+		}
+	case reflect.Struct:
+		fn(value)
+	}
+}
+
+// EnsureDefaultTenant legt die Standardorganisation an, der bestehende Daten zugeordnet sind
+func EnsureDefaultTenant(db *gorm.DB) error {
+	tenant := models.Tenant{ID: models.DefaultTenantID, Name: "Standard", Slug: "default", IsActive: true}
+	return db.Where(models.Tenant{ID: models.DefaultTenantID}).FirstOrCreate(&tenant).Error
+}
+
+// CreateTenant legt eine neue Organisation mit dem Kürzel slug an
+func CreateTenant(name, slug string) (*models.Tenant, error) {
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if err := services.ValidateTenantSlug(slug); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = slug
+	}
+
+	tenant := models.Tenant{Name: name, Slug: slug, IsActive: true}
+	if err := DB.Create(&tenant).Error; err != nil {
+		return nil, fmt.Errorf("Organisation konnte nicht angelegt werden: %w", err)
+	}
+	return &tenant, nil
+}
+
+// CreateAPIToken erzeugt ein API-Token für den Benutzer username der Organisation tenantSlug und liefert den Klartext
+func CreateAPIToken(tenantSlug, username, tokenName string) (string, error) {
+	var tenant models.Tenant
+	if err := DB.Where("slug = ?", tenantSlug).First(&tenant).Error; err != nil {
+		return "", fmt.Errorf("Organisation %q nicht gefunden", tenantSlug)
+	}
+
+	db := ForTenant(context.Background(), tenant.ID)
+	var user models.User
+	if err := db.Where("username = ?", username).First(&user).Error; err != nil {
+		return "", fmt.Errorf("Benutzer %q nicht gefunden", username)
+	}
+
+	plain, hash, prefix, err := services.GenerateAPIToken()
+	if err != nil {
+		return "", err
+	}
+	token := models.APIToken{UserID: user.ID, Name: tokenName, TokenHash: hash, Prefix: prefix}
+	if err := db.Create(&token).Error; err != nil {
+		return "", fmt.Errorf("API-Token konnte nicht angelegt werden: %w", err)
+	}
+	return plain, nil
+}
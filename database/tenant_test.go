@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupTenantTestDB richtet die Test-Datenbank mit TenantGuard und zwei Organisationen ein
+func setupTenantTestDB(t *testing.T) {
+	setupTestDB(t)
+	assert.NoError(t, DB.Use(TenantGuard{}))
+	assert.NoError(t, EnsureDefaultTenant(DB))
+	assert.NoError(t, DB.Create(&models.Tenant{ID: 2, Name: "Schwesterfirma", Slug: "schwester", IsActive: true}).Error)
+}
+
+func TestTenantGuard_ScopesReadsToTenant(t *testing.T) {
+	setupTenantTestDB(t)
+	first := ForTenant(context.Background(), 1)
+	second := ForTenant(context.Background(), 2)
+
+	team := models.Team{Name: "Pflege"}
+	assert.NoError(t, first.Create(&team).Error)
+	assert.Equal(t, uint(1), team.TenantID)
+
+	var teams []models.Team
+	assert.NoError(t, second.Find(&teams).Error)
+	assert.Empty(t, teams)
+	assert.Error(t, second.First(&models.Team{}, team.ID).Error)
+
+	var count int64
+	second.Model(&models.Team{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+	first.Model(&models.Team{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	// Ohne Organisation im Kontext bleibt der Zugriff unbeschränkt
+	assert.NoError(t, DB.First(&models.Team{}, team.ID).Error)
+}
+
+func TestTenantGuard_PreventsCrossTenantWrites(t *testing.T) {
+	setupTenantTestDB(t)
+	first := ForTenant(context.Background(), 1)
+	second := ForTenant(context.Background(), 2)
+
+	team := models.Team{Name: "Pflege", Description: "Original"}
+	assert.NoError(t, first.Create(&team).Error)
+
+	// Änderungen und Löschungen aus der anderen Organisation treffen keinen Datensatz
+	result := second.Model(&models.Team{}).Where("id = ?", team.ID).Update("description", "Übernommen")
+	assert.NoError(t, result.Error)
+	assert.Equal(t, int64(0), result.RowsAffected)
+
+	result = second.Delete(&models.Team{}, team.ID)
+	assert.NoError(t, result.Error)
+	assert.Equal(t, int64(0), result.RowsAffected)
+
+	// Eine vorgegebene Organisation wird überschrieben
+	foreign := models.Team{Name: "Fremd"}
+	foreign.TenantID = 1
+	assert.NoError(t, second.Create(&foreign).Error)
+	assert.Equal(t, uint(2), foreign.TenantID)
+
+	var stored models.Team
+	assert.NoError(t, DB.First(&stored, team.ID).Error)
+	assert.Equal(t, "Original", stored.Description)
+	assert.Equal(t, uint(1), stored.TenantID)
+}
+
+func TestTenantGuard_RejectsCrossTenantReferences(t *testing.T) {
+	setupTenantTestDB(t)
+	first := ForTenant(context.Background(), 1)
+	second := ForTenant(context.Background(), 2)
+
+	team := models.Team{Name: "Pflege"}
+	assert.NoError(t, first.Create(&team).Error)
+
+	user := models.User{Username: "anna", Email: "anna@example.com", Password: "x", Name: "Anna", TeamID: &team.ID}
+	assert.ErrorIs(t, second.Create(&user).Error, ErrCrossTenantReference)
+
+	user.TeamID = nil
+	assert.NoError(t, second.Create(&user).Error)
+
+	// Auch beim Aktualisieren darf nicht auf fremde Datensätze verwiesen werden
+	user.TeamID = &team.ID
+	assert.ErrorIs(t, second.Save(&user).Error, ErrCrossTenantReference)
+}
+
+func TestTenantGuard_UniquePerTenant(t *testing.T) {
+	setupTenantTestDB(t)
+	first := ForTenant(context.Background(), 1)
+	second := ForTenant(context.Background(), 2)
+
+	assert.NoError(t, first.Create(&models.Team{Name: "Pflege"}).Error)
+	assert.NoError(t, second.Create(&models.Team{Name: "Pflege"}).Error)
+	assert.Error(t, second.Create(&models.Team{Name: "Pflege"}).Error)
+
+	assert.NoError(t, first.Create(&models.User{Username: "anna", Email: "anna@example.com", Password: "x", Name: "Anna"}).Error)
+	assert.NoError(t, second.Create(&models.User{Username: "anna", Email: "anna@example.com", Password: "x", Name: "Anna"}).Error)
+}
+
+func TestCreateTenantAndAPIToken(t *testing.T) {
+	setupTenantTestDB(t)
+
+	tenant, err := CreateTenant("", "Nord")
+	assert.NoError(t, err)
+	assert.Equal(t, "nord", tenant.Slug)
+	assert.Equal(t, "nord", tenant.Name)
+
+	_, err = CreateTenant("Ungültig", "nord.example")
+	assert.Error(t, err)
+
+	db := ForTenant(context.Background(), tenant.ID)
+	assert.NoError(t, db.Create(&models.User{Username: "admin", Email: "admin@nord.example", Password: "x", Name: "Admin"}).Error)
+
+	token, err := CreateAPIToken("nord", "admin", "Test")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	_, err = CreateAPIToken("schwester", "admin", "Test")
+	assert.Error(t, err)
+}
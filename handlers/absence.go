@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+	"schichtplaner/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// GetAbsences gibt alle Abwesenheiten mit Pagination zurück; optional gefiltert nach user_id und status
+func GetAbsences(c echo.Context) error {
+	params := utils.GetPaginationParams(c)
+
+	query := tenantDB(c).Model(&models.Absence{})
+	if value := c.QueryParam("user_id"); value != "" {
+		userID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültige Benutzer-ID",
+			})
+		}
+		query = query.Where("user_id = ?", userID)
+	}
+	if status := c.QueryParam("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var absences []models.Absence
+	if err := query.Preload("User").Order("start_date DESC").Offset(params.Offset).Limit(params.PageSize).Find(&absences).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Abwesenheiten",
+		})
+	}
+
+	response := utils.CreatePaginatedResponse(absences, int(total), params)
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetAbsence gibt eine spezifische Abwesenheit zurück
+func GetAbsence(c echo.Context) error {
+	absence, err := loadAbsenceFromParam(c)
+	if err != nil || absence == nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, absence)
+}
+
+// CreateAbsence legt eine neue Abwesenheit an
+func CreateAbsence(c echo.Context) error {
+	var absence models.Absence
+	if err := c.Bind(&absence); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Abwesenheitsdaten",
+		})
+	}
+	absence.ID = 0
+
+	if message := validateAbsence(tenantDB(c), &absence); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	if message := absenceLockMessage(tenantDB(c), absence); message != "" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": message,
+		})
+	}
+
+	if err := tenantDB(c).Create(&absence).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen der Abwesenheit",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, absence)
+}
+
+// UpdateAbsence aktualisiert eine Abwesenheit, z.B. um sie zu genehmigen
+func UpdateAbsence(c echo.Context) error {
+	absence, err := loadAbsenceFromParam(c)
+	if err != nil || absence == nil {
+		return err
+	}
+
+	updateData := *absence
+	updateData.User = models.User{}
+	if err := c.Bind(&updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Abwesenheitsdaten",
+		})
+	}
+	updateData.Base = absence.Base
+
+	if message := validateAbsence(tenantDB(c), &updateData); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	// Genehmigte Abwesenheiten zählen im Arbeitszeitkonto, daher alten und neuen Stand prüfen
+	if message := absenceLockMessage(tenantDB(c), *absence, updateData); message != "" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": message,
+		})
+	}
+
+	if err := tenantDB(c).Omit("User").Save(&updateData).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Aktualisieren der Abwesenheit",
+		})
+	}
+
+	return c.JSON(http.StatusOK, updateData)
+}
+
+// DeleteAbsence löscht eine Abwesenheit
+func DeleteAbsence(c echo.Context) error {
+	absence, err := loadAbsenceFromParam(c)
+	if err != nil || absence == nil {
+		return err
+	}
+
+	if message := absenceLockMessage(tenantDB(c), *absence); message != "" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": message,
+		})
+	}
+
+	if err := tenantDB(c).Delete(absence).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen der Abwesenheit",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Abwesenheit erfolgreich gelöscht",
+	})
+}
+
+// GetAbsenceCreditRules gibt die wirksame Anrechnung aller Abwesenheitsarten zurück
+func GetAbsenceCreditRules(c echo.Context) error {
+	rules, err := loadAbsenceCreditRules(tenantDB(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Anrechnungsregeln",
+		})
+	}
+
+	return c.JSON(http.StatusOK, rules)
+}
+
+// UpdateAbsenceCreditRule legt die Anrechnung einer Abwesenheitsart fest
+func UpdateAbsenceCreditRule(c echo.Context) error {
+	var rule models.AbsenceCreditRule
+	tenantDB(c).Where("absence_type = ?", c.Param("type")).First(&rule)
+
+	existing := rule.Base
+	if err := c.Bind(&rule); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Anrechnungsdaten",
+		})
+	}
+	rule.Base = existing
+	rule.AbsenceType = c.Param("type")
+
+	if err := services.ValidateAbsenceCreditRule(rule); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if err := tenantDB(c).Save(&rule).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Speichern der Anrechnungsregel",
+		})
+	}
+
+	return c.JSON(http.StatusOK, rule)
+}
+
+// validateAbsence prüft eine Abwesenheit und normalisiert Beginn und Ende auf Kalendertage.
+// Liefert die erste Fehlermeldung oder einen leeren String.
+func validateAbsence(db *gorm.DB, absence *models.Absence) string {
+	validator := utils.NewValidator()
+	validator.RequiredUint("UserID", absence.UserID, "Benutzer ist ein Pflichtfeld")
+	validator.RequiredString("Type", absence.Type, "Abwesenheitsart ist ein Pflichtfeld")
+	validator.RequiredTime("StartDate", absence.StartDate, "Beginn ist ein Pflichtfeld")
+	validator.RequiredTime("EndDate", absence.EndDate, "Ende ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return result.Errors[0]
+	}
+
+	if !models.IsValidAbsenceType(absence.Type) {
+		return "Unbekannte Abwesenheitsart"
+	}
+	if absence.Status == "" {
+		absence.Status = models.AbsenceStatusRequested
+	}
+	if absence.Status != models.AbsenceStatusRequested && absence.Status != models.AbsenceStatusApproved && absence.Status != models.AbsenceStatusRejected {
+		return "Ungültiger Status"
+	}
+
+	absence.StartDate = calendarDay(absence.StartDate)
+	absence.EndDate = calendarDay(absence.EndDate)
+	if absence.EndDate.Before(absence.StartDate) {
+		return "Ende muss nach dem Beginn liegen"
+	}
+
+	var user models.User
+	if err := db.First(&user, absence.UserID).Error; err != nil {
+		return "Benutzer nicht gefunden"
+	}
+
+	// Abgelehnte Abwesenheiten blockieren keine neuen Anträge
+	if absence.Status != models.AbsenceStatusRejected {
+		var overlapping int64
+		db.Model(&models.Absence{}).
+			Where("user_id = ? AND id <> ? AND status <> ? AND start_date <= ? AND end_date >= ?",
+				absence.UserID, absence.ID, models.AbsenceStatusRejected, absence.EndDate, absence.StartDate).
+			Count(&overlapping)
+		if overlapping > 0 {
+			return "Abwesenheit überschneidet sich mit einer bestehenden Abwesenheit"
+		}
+	}
+	return ""
+}
+
+// absenceLockMessage prüft, ob eine der genehmigten Abwesenheiten in einen freigegebenen Monat fällt.
+// Beantragte und abgelehnte Abwesenheiten zählen nicht im Arbeitszeitkonto und sind nie gesperrt.
+func absenceLockMessage(db *gorm.DB, absences ...models.Absence) string {
+	for _, absence := range absences {
+		if absence.Status != models.AbsenceStatusApproved {
+			continue
+		}
+		// Kalendertage auf die Tagesmitte legen, damit die Zeitzone des Benutzers den Monat nicht verschiebt
+		times := []time.Time{absence.StartDate.Add(12 * time.Hour)}
+		month := time.Date(absence.StartDate.Year(), absence.StartDate.Month()+1, 1, 12, 0, 0, 0, time.UTC)
+		for ; !month.After(absence.EndDate.Add(12 * time.Hour)); month = month.AddDate(0, 1, 0) {
+			times = append(times, month)
+		}
+		if message := timesheetLockMessage(db, absence.UserID, times...); message != "" {
+			return message
+		}
+	}
+	return ""
+}
+
+// loadAbsenceFromParam lädt die Abwesenheit aus dem Pfadparameter id; bei Fehlern wird direkt geantwortet und nil geliefert
+func loadAbsenceFromParam(c echo.Context) (*models.Absence, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Abwesenheits-ID",
+		})
+	}
+
+	var absence models.Absence
+	if err := tenantDB(c).Preload("User").First(&absence, id).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Abwesenheit nicht gefunden",
+		})
+	}
+	return &absence, nil
+}
+
+// loadAbsenceCreditRules liefert die Standardanrechnung, überschrieben durch gespeicherte Regeln
+func loadAbsenceCreditRules(db *gorm.DB) ([]models.AbsenceCreditRule, error) {
+	var stored []models.AbsenceCreditRule
+	if err := db.Find(&stored).Error; err != nil {
+		return nil, err
+	}
+
+	rules := services.DefaultAbsenceCreditRules()
+	for i := range rules {
+		for _, rule := range stored {
+			if rule.AbsenceType == rules[i].AbsenceType {
+				rules[i] = rule
+			}
+		}
+	}
+	return rules, nil
+}
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAbsence(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "urlaub", Email: "urlaub@example.com", Password: "hashedpassword", Name: "Urlaub User"}
+	database.DB.Create(&user)
+
+	e := echo.New()
+	userID := strconv.Itoa(int(user.ID))
+	for _, testCase := range []struct {
+		body   string
+		status int
+	}{
+		{`{"user_id":` + userID + `,"type":"vacation","start_date":"2024-08-05T00:00:00Z","end_date":"2024-08-16T00:00:00Z"}`, http.StatusCreated},
+		{`{"user_id":` + userID + `,"type":"sick","start_date":"2024-08-12T00:00:00Z","end_date":"2024-08-12T00:00:00Z"}`, http.StatusBadRequest},
+		{`{"user_id":` + userID + `,"type":"party","start_date":"2024-09-02T00:00:00Z","end_date":"2024-09-02T00:00:00Z"}`, http.StatusBadRequest},
+		{`{"user_id":` + userID + `,"type":"sick","start_date":"2024-09-03T00:00:00Z","end_date":"2024-09-02T00:00:00Z"}`, http.StatusBadRequest},
+		{`{"user_id":9999,"type":"sick","start_date":"2024-09-02T00:00:00Z","end_date":"2024-09-02T00:00:00Z"}`, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/absences", bytes.NewBufferString(testCase.body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if assert.NoError(t, CreateAbsence(c)) {
+			assert.Equal(t, testCase.status, rec.Code, testCase.body)
+		}
+	}
+
+	var absence models.Absence
+	database.DB.First(&absence)
+	assert.Equal(t, models.AbsenceStatusRequested, absence.Status)
+}
+
+func TestUpdateAbsence_Approve(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "urlaub", Email: "urlaub@example.com", Password: "hashedpassword", Name: "Urlaub User"}
+	database.DB.Create(&user)
+	absence := models.Absence{UserID: user.ID, Type: models.AbsenceTypeVacation, Status: models.AbsenceStatusRequested,
+		StartDate: time.Date(2024, 8, 5, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 8, 9, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&absence)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBufferString(`{"status":"approved"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(absence.ID)))
+
+	if assert.NoError(t, UpdateAbsence(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var updated models.Absence
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+		assert.Equal(t, models.AbsenceStatusApproved, updated.Status)
+		assert.True(t, absence.StartDate.Equal(updated.StartDate))
+	}
+}
+
+func TestUpdateAbsenceCreditRule(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBufferString(`{"mode":"fixed","hours":7.7}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("type")
+	c.SetParamValues(models.AbsenceTypeSick)
+
+	if assert.NoError(t, UpdateAbsenceCreditRule(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	rules, err := loadAbsenceCreditRules(database.DB)
+	assert.NoError(t, err)
+	assert.Len(t, rules, len(models.AbsenceTypes))
+	for _, rule := range rules {
+		if rule.AbsenceType == models.AbsenceTypeSick {
+			assert.Equal(t, models.AbsenceCreditFixed, rule.Mode)
+			assert.Equal(t, 7.7, rule.Hours)
+		}
+	}
+}
+
+func TestUpdateAbsence_ApprovedMonthIsLocked(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "urlaub", Email: "urlaub@example.com", Password: "hashedpassword", Name: "Urlaub User"}
+	database.DB.Create(&user)
+	absence := models.Absence{UserID: user.ID, Type: models.AbsenceTypeVacation, Status: models.AbsenceStatusRequested,
+		StartDate: time.Date(2024, 7, 29, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 8, 2, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&absence)
+	database.DB.Create(&models.Timesheet{UserID: user.ID, Month: "2024-08", Status: models.TimesheetStatusApproved})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBufferString(`{"status":"approved"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(absence.ID)))
+
+	if assert.NoError(t, UpdateAbsence(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	}
+
+	var stored models.Absence
+	database.DB.First(&stored, absence.ID)
+	assert.Equal(t, models.AbsenceStatusRequested, stored.Status)
+
+	// Beantragte Abwesenheiten zählen nicht und bleiben änderbar
+	req = httptest.NewRequest(http.MethodPut, "/", bytes.NewBufferString(`{"status":"rejected"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(absence.ID)))
+
+	if assert.NoError(t, UpdateAbsence(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+}
@@ -0,0 +1,601 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+	"schichtplaner/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// calDAVBasePath ist der Pfad des CalDAV-Servers. Darunter liegen principal/ (angemeldeter Benutzer) und
+// calendars/ mit je einem Kalender pro Benutzer (user-<id>/) und Team (team-<id>/).
+const calDAVBasePath = "/api/caldav/"
+
+// calDAVSyncStateRetention ist die Aufbewahrungsdauer von Sync-Ständen, die nicht mehr ausgegeben wurden;
+// ältere Sync-Tokens führen beim Client zu einer vollständigen Synchronisation
+const calDAVSyncStateRetention = 30 * 24 * time.Hour
+
+// calDAVMethods sind die vom schreibgeschützten CalDAV-Server unterstützten Methoden
+const calDAVMethods = "OPTIONS, GET, HEAD, PROPFIND, REPORT"
+
+// Arten von CalDAV-Ressourcen
+const (
+	calDAVRoot      = "root"
+	calDAVPrincipal = "principal"
+	calDAVHome      = "home"
+	calDAVCalendar  = "calendar"
+	calDAVObject    = "object"
+)
+
+// Eigenschaften von WebDAV- und CalDAV-Ressourcen
+var (
+	davResourceType          = xml.Name{Space: services.DAVNamespace, Local: "resourcetype"}
+	davDisplayName           = xml.Name{Space: services.DAVNamespace, Local: "displayname"}
+	davCurrentUserPrincipal  = xml.Name{Space: services.DAVNamespace, Local: "current-user-principal"}
+	davPrincipalURL          = xml.Name{Space: services.DAVNamespace, Local: "principal-URL"}
+	davCurrentUserPrivileges = xml.Name{Space: services.DAVNamespace, Local: "current-user-privilege-set"}
+	davSupportedReportSet    = xml.Name{Space: services.DAVNamespace, Local: "supported-report-set"}
+	davSyncToken             = xml.Name{Space: services.DAVNamespace, Local: "sync-token"}
+	davGetETag               = xml.Name{Space: services.DAVNamespace, Local: "getetag"}
+	davGetContentType        = xml.Name{Space: services.DAVNamespace, Local: "getcontenttype"}
+	davGetLastModified       = xml.Name{Space: services.DAVNamespace, Local: "getlastmodified"}
+	davValidSyncToken        = xml.Name{Space: services.DAVNamespace, Local: "valid-sync-token"}
+	calDAVHomeSet            = xml.Name{Space: services.CalDAVNamespace, Local: "calendar-home-set"}
+	calDAVUserAddressSet     = xml.Name{Space: services.CalDAVNamespace, Local: "calendar-user-address-set"}
+	calDAVComponentSet       = xml.Name{Space: services.CalDAVNamespace, Local: "supported-calendar-component-set"}
+	calDAVCalendarData       = xml.Name{Space: services.CalDAVNamespace, Local: "calendar-data"}
+	calDAVGetCTag            = xml.Name{Space: services.CalendarServerNamespace, Local: "getctag"}
+	calDAVCalendarColor      = xml.Name{Space: services.AppleICalNamespace, Local: "calendar-color"}
+)
+
+// calDAVResource ist eine über den Pfad adressierte CalDAV-Ressource
+type calDAVResource struct {
+	Kind     string
+	Scope    string // Kalender und Termine: models.CalendarScopeUser oder models.CalendarScopeTeam
+	TargetID uint
+	Object   string // Dateiname des Termins, z.B. shift-1-42.ics
+}
+
+// calendarName liefert den Namen des Kalenders im Pfad, z.B. user-1
+func (r calDAVResource) calendarName() string {
+	return r.Scope + "-" + strconv.FormatUint(uint64(r.TargetID), 10)
+}
+
+// path liefert den Pfad der Ressource; Sammlungen enden mit /
+func (r calDAVResource) path() string {
+	switch r.Kind {
+	case calDAVRoot:
+		return calDAVBasePath
+	case calDAVPrincipal:
+		return calDAVBasePath + "principal/"
+	case calDAVHome:
+		return calDAVBasePath + "calendars/"
+	case calDAVCalendar:
+		return calDAVBasePath + "calendars/" + r.calendarName() + "/"
+	default:
+		return calDAVBasePath + "calendars/" + r.calendarName() + "/" + r.Object
+	}
+}
+
+// calDAVItem ist ein Termin als CalDAV-Kalenderobjekt
+type calDAVItem struct {
+	Href  string
+	ETag  string
+	Data  string
+	Event models.CalendarEvent
+}
+
+// CalDAVMiddleware meldet CalDAV-Clients per API-Token an (Basic-Authentifizierung mit dem Token als Passwort oder
+// Authorization: Bearer …) und beschränkt die Anfrage auf dessen Organisation. Die Anmeldung ist immer erforderlich.
+func CalDAVMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		plain := bearerToken(c)
+		if plain == "" {
+			_, plain, _ = c.Request().BasicAuth()
+		}
+
+		// Ohne diese Angabe fragen Kalender-Apps nicht nach den Zugangsdaten
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Basic realm="Schichtplaner"`)
+		if plain == "" {
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error": "Anmeldung mit API-Token erforderlich",
+			})
+		}
+		token, err := authenticateAPIToken(c, plain)
+		if err != nil || token == nil {
+			return err
+		}
+		c.Response().Header().Del(echo.HeaderWWWAuthenticate)
+
+		var tenant models.Tenant
+		if err := database.DB.First(&tenant, token.TenantID).Error; err != nil || !tenant.IsActive {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "Organisation ist deaktiviert",
+			})
+		}
+
+		c.Set(tenantIDContextKey, tenant.ID)
+		c.Set(currentUserContextKey, &token.User)
+		c.SetRequest(c.Request().WithContext(database.WithTenant(c.Request().Context(), tenant.ID)))
+		return next(c)
+	}
+}
+
+// CalDAVWellKnown leitet die Dienstsuche der Kalender-Apps (/.well-known/caldav, RFC 6764) auf den CalDAV-Server um
+func CalDAVWellKnown(c echo.Context) error {
+	return c.Redirect(http.StatusMovedPermanently, calDAVBasePath)
+}
+
+// CalDAV beantwortet die Anfragen an den schreibgeschützten CalDAV-Server: PROPFIND für Benutzer, Kalender und
+// Termine, REPORT (calendar-query, calendar-multiget, sync-collection) und GET für einzelne Termine
+func CalDAV(c echo.Context) error {
+	c.Response().Header().Set("DAV", "1, 3, calendar-access")
+	c.Response().Header().Set(echo.HeaderAllow, calDAVMethods)
+	if c.Request().Method == http.MethodOptions {
+		return c.NoContent(http.StatusOK)
+	}
+
+	resource, err := resolveCalDAVResource(c)
+	if err != nil || resource == nil {
+		return err
+	}
+
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead:
+		return getCalDAVObject(c, *resource)
+	case echo.PROPFIND:
+		return calDAVPropfind(c, *resource)
+	case echo.REPORT:
+		return calDAVReport(c, *resource)
+	}
+	return c.JSON(http.StatusMethodNotAllowed, map[string]string{
+		"error": "Die Kalender sind schreibgeschützt",
+	})
+}
+
+// getCalDAVObject liefert einen einzelnen Termin als iCalendar-Datei
+func getCalDAVObject(c echo.Context, resource calDAVResource) error {
+	if resource.Kind != calDAVObject {
+		return c.JSON(http.StatusMethodNotAllowed, map[string]string{
+			"error": "Nur einzelne Termine können abgerufen werden",
+		})
+	}
+	objects, err := loadCalDAVObjects(c, resource)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Termine",
+		})
+	}
+	for _, object := range objects {
+		if object.Href == resource.path() {
+			c.Response().Header().Set("ETag", object.ETag)
+			return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", []byte(object.Data))
+		}
+	}
+	return c.JSON(http.StatusNotFound, map[string]string{
+		"error": "Termin nicht gefunden",
+	})
+}
+
+// calDAVPropfind liefert die Eigenschaften einer Ressource und bei Depth: 1 ihrer direkten Kinder
+func calDAVPropfind(c echo.Context, resource calDAVResource) error {
+	request, err := readDAVRequest(c)
+	if err != nil || request == nil {
+		return err
+	}
+	if request.Kind != services.DAVRequestPropfind {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "PROPFIND erwartet ein propfind-Element",
+		})
+	}
+	withChildren := c.Request().Header.Get("Depth") != "0"
+
+	var responses []services.DAVResponse
+	switch resource.Kind {
+	case calDAVObject:
+		objects, err := loadCalDAVObjects(c, resource)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Fehler beim Laden der Termine",
+			})
+		}
+		for _, object := range objects {
+			if object.Href == resource.path() {
+				responses = append(responses, calDAVObjectResponse(*request, object))
+			}
+		}
+		if len(responses) == 0 {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "Termin nicht gefunden",
+			})
+		}
+	case calDAVCalendar:
+		responses, err = calDAVCalendarResponses(c, *request, resource, withChildren)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Fehler beim Laden des Kalenders",
+			})
+		}
+	case calDAVHome:
+		responses = append(responses, davResponse(*request, resource.path(), calDAVHomeProperties()))
+		if withChildren {
+			calendars, err := calDAVCalendars(c)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]string{
+					"error": "Fehler beim Laden der Kalender",
+				})
+			}
+			for _, calendar := range calendars {
+				calendarResponses, err := calDAVCalendarResponses(c, *request, calendar, false)
+				if err != nil {
+					return c.JSON(http.StatusInternalServerError, map[string]string{
+						"error": "Fehler beim Laden der Kalender",
+					})
+				}
+				responses = append(responses, calendarResponses...)
+			}
+		}
+	default:
+		responses = append(responses, davResponse(*request, resource.path(), calDAVPrincipalProperties(c, resource.Kind)))
+		if resource.Kind == calDAVRoot && withChildren {
+			responses = append(responses,
+				davResponse(*request, calDAVResource{Kind: calDAVPrincipal}.path(), calDAVPrincipalProperties(c, calDAVPrincipal)),
+				davResponse(*request, calDAVResource{Kind: calDAVHome}.path(), calDAVHomeProperties()))
+		}
+	}
+	return respondDAV(c, responses, "")
+}
+
+// calDAVReport beantwortet calendar-query (optional mit Zeitraum), calendar-multiget und sync-collection für einen Kalender
+func calDAVReport(c echo.Context, resource calDAVResource) error {
+	if resource.Kind != calDAVCalendar {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "REPORT wird nur für Kalender unterstützt",
+		})
+	}
+	request, err := readDAVRequest(c)
+	if err != nil || request == nil {
+		return err
+	}
+	objects, err := loadCalDAVObjects(c, resource)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Termine",
+		})
+	}
+	byHref := make(map[string]calDAVItem, len(objects))
+	for _, object := range objects {
+		byHref[object.Href] = object
+	}
+
+	var responses []services.DAVResponse
+	switch request.Kind {
+	case services.DAVRequestCalendarQuery:
+		for _, object := range objects {
+			if services.CalendarEventInRange(object.Event, request.Start, request.End) {
+				responses = append(responses, calDAVObjectResponse(*request, object))
+			}
+		}
+		return respondDAV(c, responses, "")
+
+	case services.DAVRequestCalendarMultiget:
+		for _, href := range request.Hrefs {
+			path := href
+			if parsed, err := url.Parse(href); err == nil {
+				path = parsed.Path
+			}
+			if object, ok := byHref[path]; ok {
+				responses = append(responses, calDAVObjectResponse(*request, object))
+			} else {
+				responses = append(responses, services.DAVResponse{Href: href, Status: http.StatusNotFound})
+			}
+		}
+		return respondDAV(c, responses, "")
+
+	case services.DAVRequestSyncCollection:
+		var previous map[string]string
+		if request.SyncToken != "" {
+			var state models.CalendarSyncState
+			if err := tenantDB(c).Where("calendar = ? AND token = ?", resource.calendarName(), request.SyncToken).First(&state).Error; err != nil {
+				// Unbekanntes oder abgelaufenes Token: der Client synchronisiert vollständig neu (RFC 6578)
+				return c.Blob(http.StatusForbidden, "application/xml; charset=utf-8", []byte(services.DAVError(davValidSyncToken)))
+			}
+			previous = state.ETags
+		}
+
+		token, current, err := saveCalDAVSyncState(c, resource, objects)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Fehler beim Speichern des Sync-Stands",
+			})
+		}
+		changed, removed := services.CalendarSyncChanges(previous, current)
+		for _, href := range changed {
+			responses = append(responses, calDAVObjectResponse(*request, byHref[href]))
+		}
+		for _, href := range removed {
+			responses = append(responses, services.DAVResponse{Href: href, Status: http.StatusNotFound})
+		}
+		return respondDAV(c, responses, token)
+	}
+
+	return c.JSON(http.StatusBadRequest, map[string]string{
+		"error": "Nicht unterstützte REPORT-Anfrage: " + request.Kind,
+	})
+}
+
+// resolveCalDAVResource ermittelt die Ressource aus dem Pfad und prüft bei Kalendern und Terminen Existenz und
+// Berechtigung; bei Fehlern wird direkt geantwortet und nil geliefert
+func resolveCalDAVResource(c echo.Context) (*calDAVResource, error) {
+	path := strings.Trim(strings.TrimPrefix(c.Request().URL.Path, strings.TrimSuffix(calDAVBasePath, "/")), "/")
+	var parts []string
+	if path != "" {
+		parts = strings.Split(path, "/")
+	}
+
+	switch {
+	case len(parts) == 0:
+		return &calDAVResource{Kind: calDAVRoot}, nil
+	case len(parts) == 1 && parts[0] == "principal":
+		return &calDAVResource{Kind: calDAVPrincipal}, nil
+	case len(parts) == 1 && parts[0] == "calendars":
+		return &calDAVResource{Kind: calDAVHome}, nil
+	case (len(parts) == 2 || len(parts) == 3) && parts[0] == "calendars":
+		scope, rawID, _ := strings.Cut(parts[1], "-")
+		targetID, err := strconv.ParseUint(rawID, 10, 32)
+		if err != nil || (scope != models.CalendarScopeUser && scope != models.CalendarScopeTeam) ||
+			validateCalendarTarget(tenantDB(c), scope, uint(targetID)) != "" {
+			return nil, c.JSON(http.StatusNotFound, map[string]string{
+				"error": "Kalender nicht gefunden",
+			})
+		}
+		if !canSubscribeCalendar(c, scope, uint(targetID)) {
+			return nil, c.JSON(http.StatusForbidden, map[string]string{
+				"error": "Keine Berechtigung für diesen Kalender",
+			})
+		}
+
+		resource := calDAVResource{Kind: calDAVCalendar, Scope: scope, TargetID: uint(targetID)}
+		if len(parts) == 3 {
+			resource.Kind = calDAVObject
+			resource.Object = parts[2]
+		}
+		return &resource, nil
+	}
+
+	return nil, c.JSON(http.StatusNotFound, map[string]string{
+		"error": "CalDAV-Ressource nicht gefunden",
+	})
+}
+
+// readDAVRequest liest den XML-Rumpf einer PROPFIND- oder REPORT-Anfrage; bei Fehlern wird direkt geantwortet
+func readDAVRequest(c echo.Context) (*services.DAVRequest, error) {
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, 1<<20))
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Anfrage konnte nicht gelesen werden",
+		})
+	}
+	request, err := services.ParseDAVRequest(body)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	return &request, nil
+}
+
+// calDAVCalendars liefert die Kalender, die der angemeldete Benutzer sieht: Administratoren alle aktiven Benutzer und
+// alle Teams, Planer den eigenen Kalender und alle Teams, alle anderen den eigenen Kalender und den ihres Teams
+func calDAVCalendars(c echo.Context) ([]calDAVResource, error) {
+	user := currentUser(c)
+	db := tenantDB(c)
+
+	userIDs := []uint{user.ID}
+	if user.IsAdmin {
+		if err := db.Model(&models.User{}).Where("is_active = ?", true).Order("name ASC, id ASC").Pluck("id", &userIDs).Error; err != nil {
+			return nil, err
+		}
+	}
+	var teamIDs []uint
+	if user.CanApprove() {
+		if err := db.Model(&models.Team{}).Order("name ASC, id ASC").Pluck("id", &teamIDs).Error; err != nil {
+			return nil, err
+		}
+	} else if user.TeamID != nil {
+		teamIDs = []uint{*user.TeamID}
+	}
+
+	calendars := make([]calDAVResource, 0, len(userIDs)+len(teamIDs))
+	for _, id := range userIDs {
+		calendars = append(calendars, calDAVResource{Kind: calDAVCalendar, Scope: models.CalendarScopeUser, TargetID: id})
+	}
+	for _, id := range teamIDs {
+		calendars = append(calendars, calDAVResource{Kind: calDAVCalendar, Scope: models.CalendarScopeTeam, TargetID: id})
+	}
+	return calendars, nil
+}
+
+// loadCalDAVObjects lädt die Termine eines Kalenders im Zeitraum der Kalender-Abonnements
+func loadCalDAVObjects(c echo.Context, resource calDAVResource) ([]calDAVItem, error) {
+	now := time.Now()
+	_, events, err := calendarEvents(tenantDB(c), currentTenantID(c), resource.Scope, resource.TargetID,
+		now.AddDate(0, 0, -calendarFeedPastDays), now.AddDate(0, 0, calendarFeedFutureDays))
+	if err != nil {
+		return nil, err
+	}
+
+	calendarPath := calDAVResource{Kind: calDAVCalendar, Scope: resource.Scope, TargetID: resource.TargetID}.path()
+	objects := make([]calDAVItem, 0, len(events))
+	for _, event := range events {
+		data := services.CalendarObject(event)
+		objects = append(objects, calDAVItem{
+			Href:  calendarPath + services.CalendarObjectName(event),
+			ETag:  services.CalendarObjectETag(data),
+			Data:  data,
+			Event: event,
+		})
+	}
+	return objects, nil
+}
+
+// saveCalDAVSyncState speichert den aktuellen Stand eines Kalenders unter seinem Sync-Token und entfernt lange nicht
+// mehr ausgegebene Stände. Liefert Token und Stand (Pfad → ETag).
+func saveCalDAVSyncState(c echo.Context, resource calDAVResource, objects []calDAVItem) (string, map[string]string, error) {
+	etags := make(map[string]string, len(objects))
+	for _, object := range objects {
+		etags[object.Href] = object.ETag
+	}
+	token := services.CalendarSyncToken(etags)
+	now := time.Now()
+	db := tenantDB(c)
+
+	var state models.CalendarSyncState
+	if err := db.Where("calendar = ? AND token = ?", resource.calendarName(), token).First(&state).Error; err == nil {
+		if err := db.Model(&state).UpdateColumn("updated_at", now).Error; err != nil {
+			return "", nil, err
+		}
+	} else {
+		state = models.CalendarSyncState{Calendar: resource.calendarName(), Token: token, ETags: etags}
+		if err := db.Create(&state).Error; err != nil {
+			return "", nil, err
+		}
+	}
+
+	if err := db.Unscoped().Where("calendar = ? AND updated_at < ?", resource.calendarName(), now.Add(-calDAVSyncStateRetention)).
+		Delete(&models.CalendarSyncState{}).Error; err != nil {
+		return "", nil, err
+	}
+	return token, etags, nil
+}
+
+// calDAVCalendarResponses liefert die Eigenschaften eines Kalenders und mit withObjects die seiner Termine.
+// Termine werden nur geladen, wenn sie oder getctag bzw. sync-token benötigt werden.
+func calDAVCalendarResponses(c echo.Context, request services.DAVRequest, resource calDAVResource, withObjects bool) ([]services.DAVResponse, error) {
+	name, color := "", ""
+	if resource.Scope == models.CalendarScopeUser {
+		var user models.User
+		if err := tenantDB(c).First(&user, resource.TargetID).Error; err != nil {
+			return nil, err
+		}
+		name, color = "Schichten "+user.Name, user.Color
+	} else {
+		var team models.Team
+		if err := tenantDB(c).First(&team, resource.TargetID).Error; err != nil {
+			return nil, err
+		}
+		name, color = "Team "+team.Name, team.Color
+	}
+
+	properties := []services.DAVProperty{
+		{Name: davResourceType, Value: "<d:collection/><c:calendar/>"},
+		{Name: davDisplayName, Value: services.DAVText(name)},
+		{Name: davCurrentUserPrincipal, Value: davHref(calDAVResource{Kind: calDAVPrincipal}.path())},
+		{Name: davCurrentUserPrivileges, Value: "<d:privilege><d:read/></d:privilege>"},
+		{Name: calDAVComponentSet, Value: `<c:comp name="VEVENT"/>`},
+		{Name: davSupportedReportSet, Value: "<d:supported-report><d:report><c:calendar-query/></d:report></d:supported-report>" +
+			"<d:supported-report><d:report><c:calendar-multiget/></d:report></d:supported-report>" +
+			"<d:supported-report><d:report><d:sync-collection/></d:report></d:supported-report>"},
+	}
+	if color != "" {
+		properties = append(properties, services.DAVProperty{Name: calDAVCalendarColor, Value: services.DAVText(color)})
+	}
+
+	var objects []calDAVItem
+	if withObjects || services.WantsDAVProperty(request, calDAVGetCTag) || services.WantsDAVProperty(request, davSyncToken) {
+		var err error
+		if objects, err = loadCalDAVObjects(c, resource); err != nil {
+			return nil, err
+		}
+		token, _, err := saveCalDAVSyncState(c, resource, objects)
+		if err != nil {
+			return nil, err
+		}
+		properties = append(properties,
+			services.DAVProperty{Name: calDAVGetCTag, Value: services.DAVText(token)},
+			services.DAVProperty{Name: davSyncToken, Value: services.DAVText(token)})
+	}
+
+	responses := []services.DAVResponse{davResponse(request, resource.path(), properties)}
+	if withObjects {
+		for _, object := range objects {
+			responses = append(responses, calDAVObjectResponse(request, object))
+		}
+	}
+	return responses, nil
+}
+
+// calDAVObjectResponse liefert die Eigenschaften eines Termins. Der Inhalt (calendar-data) ist bei PROPFIND
+// mit allprop nicht enthalten.
+func calDAVObjectResponse(request services.DAVRequest, object calDAVItem) services.DAVResponse {
+	properties := []services.DAVProperty{
+		{Name: davResourceType},
+		{Name: davGetETag, Value: services.DAVText(object.ETag)},
+		{Name: davGetContentType, Value: "text/calendar; charset=utf-8; component=VEVENT"},
+	}
+	if !object.Event.LastModified.IsZero() {
+		properties = append(properties, services.DAVProperty{Name: davGetLastModified, Value: object.Event.LastModified.UTC().Format(http.TimeFormat)})
+	}
+	if request.Kind != services.DAVRequestPropfind || !request.AllProp {
+		properties = append(properties, services.DAVProperty{Name: calDAVCalendarData, Value: services.DAVText(object.Data)})
+	}
+	return davResponse(request, object.Href, properties)
+}
+
+// calDAVPrincipalProperties liefert die Eigenschaften des Einstiegspunkts bzw. des angemeldeten Benutzers
+func calDAVPrincipalProperties(c echo.Context, kind string) []services.DAVProperty {
+	user := currentUser(c)
+	principal := davHref(calDAVResource{Kind: calDAVPrincipal}.path())
+	resourceType := "<d:collection/>"
+	if kind == calDAVPrincipal {
+		resourceType = "<d:collection/><d:principal/>"
+	}
+
+	properties := []services.DAVProperty{
+		{Name: davResourceType, Value: resourceType},
+		{Name: davDisplayName, Value: services.DAVText(user.Name)},
+		{Name: davCurrentUserPrincipal, Value: principal},
+		{Name: davPrincipalURL, Value: principal},
+		{Name: calDAVHomeSet, Value: davHref(calDAVResource{Kind: calDAVHome}.path())},
+		{Name: davCurrentUserPrivileges, Value: "<d:privilege><d:read/></d:privilege>"},
+	}
+	if user.Email != "" {
+		properties = append(properties, services.DAVProperty{Name: calDAVUserAddressSet, Value: davHref("mailto:" + user.Email)})
+	}
+	return properties
+}
+
+// calDAVHomeProperties liefert die Eigenschaften der Sammlung aller Kalender
+func calDAVHomeProperties() []services.DAVProperty {
+	return []services.DAVProperty{
+		{Name: davResourceType, Value: "<d:collection/>"},
+		{Name: davDisplayName, Value: "Kalender"},
+		{Name: davCurrentUserPrincipal, Value: davHref(calDAVResource{Kind: calDAVPrincipal}.path())},
+		{Name: davCurrentUserPrivileges, Value: "<d:privilege><d:read/></d:privilege>"},
+	}
+}
+
+// davResponse wählt die angefragten Eigenschaften einer Ressource für die Multistatus-Antwort aus
+func davResponse(request services.DAVRequest, href string, available []services.DAVProperty) services.DAVResponse {
+	found, missing := services.SelectDAVProperties(request, available)
+	return services.DAVResponse{Href: href, Properties: found, Missing: missing}
+}
+
+// davHref erzeugt ein href-Element
+func davHref(href string) string {
+	return "<d:href>" + services.DAVText(href) + "</d:href>"
+}
+
+// respondDAV sendet eine Multistatus-Antwort (207)
+func respondDAV(c echo.Context, responses []services.DAVResponse, syncToken string) error {
+	return c.Blob(http.StatusMultiStatus, "application/xml; charset=utf-8", []byte(services.DAVMultistatus(responses, syncToken)))
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveCalDAV führt eine CalDAV-Anfrage mit Basic-Authentifizierung (API-Token als Passwort) aus
+func serveCalDAV(t *testing.T, method, path, token, depth, body string) *httptest.ResponseRecorder {
+	e := echo.New()
+	caldav := e.Group("/api/caldav", CalDAVMiddleware)
+	caldav.Any("", CalDAV)
+	caldav.Any("/*", CalDAV)
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.SetBasicAuth("kalender", token)
+	}
+	if depth != "" {
+		req.Header.Set("Depth", depth)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCalDAV(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+	assert.NoError(t, database.EnsureDefaultTenant(database.DB))
+
+	team := models.Team{Name: "Pflege", Color: "#10B981"}
+	database.DB.Create(&team)
+	user := models.User{Username: "kalender", Email: "kalender@example.com", Password: "x", AccountNumber: "C1", Name: "Kalender User", TeamID: &team.ID, IsActive: true}
+	other := models.User{Username: "andere", Email: "andere@example.com", Password: "x", AccountNumber: "C2", Name: "Andere", IsActive: true}
+	database.DB.Create(&user)
+	database.DB.Create(&other)
+	token := createTestAPIToken(t, user, nil)
+
+	now := time.Now().UTC().Truncate(time.Hour)
+	schedule := models.Schedule{Name: "Aktuell", StartDate: now, EndDate: now.AddDate(0, 1, 0)}
+	database.DB.Create(&schedule)
+	early := models.Shift{UserID: user.ID, ScheduleID: schedule.ID, StartTime: now.Add(24 * time.Hour), EndTime: now.Add(32 * time.Hour), IsActive: true}
+	late := models.Shift{UserID: user.ID, ScheduleID: schedule.ID, StartTime: now.Add(72 * time.Hour), EndTime: now.Add(80 * time.Hour), IsActive: true}
+	database.DB.Create(&early)
+	database.DB.Create(&late)
+
+	calendarPath := "/api/caldav/calendars/user-" + strconv.Itoa(int(user.ID)) + "/"
+	earlyHref := calendarPath + "shift-1-" + strconv.Itoa(int(early.ID)) + ".ics"
+	lateHref := calendarPath + "shift-1-" + strconv.Itoa(int(late.ID)) + ".ics"
+
+	// Ohne Token fordert der Server die Anmeldung an
+	rec := serveCalDAV(t, echo.PROPFIND, "/api/caldav/", "", "0", "")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderWWWAuthenticate), "Basic")
+
+	// Dienstsuche: Benutzer und Kalendersammlung
+	rec = serveCalDAV(t, echo.PROPFIND, "/api/caldav/", token, "0",
+		`<d:propfind xmlns:d="DAV:"><d:prop><d:current-user-principal/></d:prop></d:propfind>`)
+	assert.Equal(t, http.StatusMultiStatus, rec.Code)
+	assert.Contains(t, rec.Body.String(), "<d:current-user-principal><d:href>/api/caldav/principal/</d:href></d:current-user-principal>")
+
+	// Eigener Kalender und Teamkalender, nicht der anderer Benutzer
+	rec = serveCalDAV(t, echo.PROPFIND, "/api/caldav/calendars/", token, "1",
+		`<d:propfind xmlns:d="DAV:" xmlns:ical="http://apple.com/ns/ical/"><d:prop><d:resourcetype/><d:displayname/><ical:calendar-color/></d:prop></d:propfind>`)
+	assert.Equal(t, http.StatusMultiStatus, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "<d:href>"+calendarPath+"</d:href>")
+	assert.Contains(t, body, "<d:displayname>Team Pflege</d:displayname>")
+	assert.Contains(t, body, "<ical:calendar-color>#10B981</ical:calendar-color>")
+	assert.NotContains(t, body, "user-"+strconv.Itoa(int(other.ID)))
+
+	rec = serveCalDAV(t, echo.PROPFIND, "/api/caldav/calendars/user-"+strconv.Itoa(int(other.ID))+"/", token, "0", "")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	// Sync-Token des Kalenders
+	rec = serveCalDAV(t, echo.PROPFIND, calendarPath, token, "0",
+		`<d:propfind xmlns:d="DAV:"><d:prop><d:sync-token/></d:prop></d:propfind>`)
+	assert.Equal(t, http.StatusMultiStatus, rec.Code)
+	match := regexp.MustCompile(`<d:sync-token>([^<]+)</d:sync-token>`).FindStringSubmatch(rec.Body.String())
+	if !assert.Len(t, match, 2) {
+		return
+	}
+	syncToken := match[1]
+
+	// calendar-query mit Zeitraum liefert nur die erste Schicht
+	end := now.Add(48 * time.Hour).Format("20060102T150405Z")
+	rec = serveCalDAV(t, echo.REPORT, calendarPath, token, "1",
+		`<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:prop><d:getetag/><c:calendar-data/></d:prop>`+
+			`<c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VEVENT"><c:time-range start="`+now.Format("20060102T150405Z")+`" end="`+end+`"/>`+
+			`</c:comp-filter></c:comp-filter></c:filter></c:calendar-query>`)
+	assert.Equal(t, http.StatusMultiStatus, rec.Code)
+	body = rec.Body.String()
+	assert.Contains(t, body, earlyHref)
+	assert.NotContains(t, body, lateHref)
+	assert.Contains(t, body, "BEGIN:VEVENT")
+
+	// Einzelner Termin per GET
+	rec = serveCalDAV(t, http.MethodGet, earlyHref, token, "", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentType), "text/calendar")
+	assert.NotEmpty(t, rec.Header().Get("ETag"))
+
+	// Änderung und Löschung erscheinen in der inkrementellen Synchronisation
+	database.DB.Model(&early).Updates(map[string]interface{}{"description": "Übergabe um 6 Uhr", "updated_at": now.Add(time.Minute)})
+	database.DB.Delete(&late)
+	rec = serveCalDAV(t, echo.REPORT, calendarPath, token, "1",
+		`<d:sync-collection xmlns:d="DAV:"><d:sync-token>`+syncToken+`</d:sync-token><d:sync-level>1</d:sync-level><d:prop><d:getetag/></d:prop></d:sync-collection>`)
+	assert.Equal(t, http.StatusMultiStatus, rec.Code)
+	body = rec.Body.String()
+	assert.Contains(t, body, "<d:href>"+earlyHref+"</d:href><d:propstat>")
+	assert.Contains(t, body, "<d:href>"+lateHref+"</d:href><d:status>HTTP/1.1 404 Not Found</d:status>")
+	assert.NotContains(t, body, syncToken+"<")
+
+	rec = serveCalDAV(t, echo.REPORT, calendarPath, token, "1",
+		`<d:sync-collection xmlns:d="DAV:"><d:sync-token>urn:schichtplaner:sync:unbekannt</d:sync-token><d:prop><d:getetag/></d:prop></d:sync-collection>`)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "valid-sync-token")
+
+	// Schreibzugriffe sind nicht möglich
+	rec = serveCalDAV(t, http.MethodPut, earlyHref, token, "", "BEGIN:VCALENDAR")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
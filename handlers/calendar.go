@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+	"schichtplaner/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// Zeitraum der Termine in Kalender-Abonnements relativ zum Abruf
+const (
+	calendarFeedPastDays   = 90
+	calendarFeedFutureDays = 365
+)
+
+// GetUserCalendar liefert die Schichten und genehmigten Abwesenheiten eines Benutzers als iCalendar-Abonnement (?token=…)
+func GetUserCalendar(c echo.Context) error {
+	return serveCalendarFeed(c, models.CalendarScopeUser)
+}
+
+// GetTeamCalendar liefert die Schichten und genehmigten Abwesenheiten eines Teams als iCalendar-Abonnement (?token=…)
+func GetTeamCalendar(c echo.Context) error {
+	return serveCalendarFeed(c, models.CalendarScopeTeam)
+}
+
+// GetScheduleCalendar liefert die Schichten eines Schichtplans als iCalendar-Abonnement (?token=…)
+func GetScheduleCalendar(c echo.Context) error {
+	return serveCalendarFeed(c, models.CalendarScopeSchedule)
+}
+
+// GetCalendarFeeds gibt die Kalender-Abonnements zurück. Angemeldete Benutzer ohne Administratorrechte sehen
+// ihr eigenes Abonnement und die von ihnen erzeugten.
+func GetCalendarFeeds(c echo.Context) error {
+	query := tenantDB(c).Model(&models.CalendarFeed{})
+	if user := currentUser(c); user != nil && !user.IsAdmin {
+		query = query.Where("created_by_id = ? OR (scope = ? AND target_id = ?)", user.ID, models.CalendarScopeUser, user.ID)
+	}
+
+	var feeds []models.CalendarFeed
+	if err := query.Order("scope ASC, target_id ASC").Find(&feeds).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Kalender-Abonnements",
+		})
+	}
+
+	return c.JSON(http.StatusOK, feeds)
+}
+
+// CreateCalendarFeed erzeugt das Token für das Abonnement eines Benutzers, Teams oder Schichtplans (scope, target_id).
+// Besteht bereits ein Abonnement, wird ein neues Token erzeugt und das bisherige ungültig.
+func CreateCalendarFeed(c echo.Context) error {
+	var request struct {
+		Scope    string `json:"scope"`
+		TargetID uint   `json:"target_id"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Angaben zum Kalender-Abonnement",
+		})
+	}
+	if message := validateCalendarTarget(tenantDB(c), request.Scope, request.TargetID); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	if !canSubscribeCalendar(c, request.Scope, request.TargetID) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Keine Berechtigung für dieses Kalender-Abonnement",
+		})
+	}
+
+	token, hash, prefix, err := services.GenerateCalendarFeedToken()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	feed := models.CalendarFeed{Scope: request.Scope, TargetID: request.TargetID}
+	status := http.StatusCreated
+	if err := tenantDB(c).Where("scope = ? AND target_id = ?", request.Scope, request.TargetID).First(&feed).Error; err == nil {
+		status = http.StatusOK
+	}
+	feed.TokenHash = hash
+	feed.Prefix = prefix
+	feed.LastAccessedAt = nil
+	feed.CreatedByID = nil
+	if actor := currentUser(c); actor != nil {
+		feed.CreatedByID = &actor.ID
+	}
+	if err := tenantDB(c).Save(&feed).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Speichern des Kalender-Abonnements",
+		})
+	}
+
+	feed.Token = token
+	feed.URL = feed.Path() + "?token=" + token
+	return c.JSON(status, feed)
+}
+
+// DeleteCalendarFeed widerruft ein Kalender-Abonnement
+func DeleteCalendarFeed(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Abonnement-ID",
+		})
+	}
+
+	var feed models.CalendarFeed
+	if err := tenantDB(c).First(&feed, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Kalender-Abonnement nicht gefunden",
+		})
+	}
+	if !canSubscribeCalendar(c, feed.Scope, feed.TargetID) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Keine Berechtigung für dieses Kalender-Abonnement",
+		})
+	}
+
+	// Endgültig löschen, damit der eindeutige Index einem neuen Abonnement für dasselbe Ziel nicht im Weg steht
+	if err := tenantDB(c).Unscoped().Delete(&feed).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen des Kalender-Abonnements",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Kalender-Abonnement erfolgreich widerrufen",
+	})
+}
+
+// serveCalendarFeed prüft das Token aus dem Query-Parameter token und liefert die Termine als iCalendar-Datei.
+// Kalender-Apps senden keine Anmeldung mit; das Token bestimmt daher auch die Organisation.
+func serveCalendarFeed(c echo.Context, scope string) error {
+	targetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige ID",
+		})
+	}
+	token := c.QueryParam("token")
+	if token == "" {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Token (token) ist ein Pflichtfeld",
+		})
+	}
+
+	// Die Organisation ist hier noch unbekannt, daher ohne Beschränkung suchen
+	var feed models.CalendarFeed
+	if err := database.DB.Where("token_hash = ?", services.HashAPIToken(token)).First(&feed).Error; err != nil ||
+		feed.Scope != scope || feed.TargetID != uint(targetID) {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Kalender-Abonnement nicht gefunden",
+		})
+	}
+	var tenant models.Tenant
+	if err := database.DB.First(&tenant, feed.TenantID).Error; err != nil || !tenant.IsActive {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Kalender-Abonnement nicht gefunden",
+		})
+	}
+
+	c.Set(tenantIDContextKey, tenant.ID)
+	c.SetRequest(c.Request().WithContext(database.WithTenant(c.Request().Context(), tenant.ID)))
+
+	now := time.Now()
+	name, events, err := calendarEvents(tenantDB(c), tenant.ID, scope, feed.TargetID,
+		now.AddDate(0, 0, -calendarFeedPastDays), now.AddDate(0, 0, calendarFeedFutureDays))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen des Kalenders",
+		})
+	}
+	tenantDB(c).Model(&feed).UpdateColumn("last_accessed_at", now)
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `inline; filename="calendar.ics"`)
+	return c.Blob(http.StatusOK, "text/calendar; charset=utf-8", []byte(services.ICSCalendar(name, events, now)))
+}
+
+// calendarEvents lädt Name und Termine eines Kalenders (Benutzer, Team oder Schichtplan) für Schichten, die im
+// Zeitraum [from, to) beginnen, und genehmigte Abwesenheiten, die ihn berühren
+func calendarEvents(db *gorm.DB, tenantID uint, scope string, targetID uint, from, to time.Time) (string, []models.CalendarEvent, error) {
+	shiftQuery := db.Preload("ShiftType").Preload("Schedule").Preload("User").
+		Where("start_time >= ? AND start_time < ?", from, to)
+	var userIDs []uint
+	name := ""
+	withUser := true
+
+	switch scope {
+	case models.CalendarScopeUser:
+		var user models.User
+		if err := db.First(&user, targetID).Error; err != nil {
+			return "", nil, err
+		}
+		name = "Schichten " + user.Name
+		userIDs = []uint{user.ID}
+		withUser = false
+		shiftQuery = shiftQuery.Where("user_id = ?", user.ID)
+	case models.CalendarScopeTeam:
+		var team models.Team
+		if err := db.First(&team, targetID).Error; err != nil {
+			return "", nil, err
+		}
+		name = "Team " + team.Name
+		if err := db.Model(&models.User{}).Where("team_id = ?", team.ID).Pluck("id", &userIDs).Error; err != nil {
+			return "", nil, err
+		}
+		shiftQuery = shiftQuery.Where("user_id IN ?", append(userIDs, 0))
+	default:
+		var schedule models.Schedule
+		if err := db.First(&schedule, targetID).Error; err != nil {
+			return "", nil, err
+		}
+		name = "Schichtplan " + schedule.Name
+		shiftQuery = shiftQuery.Where("schedule_id = ?", schedule.ID)
+	}
+
+	var shifts []models.Shift
+	if err := shiftQuery.Order("start_time ASC, id ASC").Find(&shifts).Error; err != nil {
+		return "", nil, err
+	}
+	var locations []models.Location
+	if err := db.Find(&locations).Error; err != nil {
+		return "", nil, err
+	}
+	byID := make(map[uint]models.Location, len(locations))
+	for _, location := range locations {
+		byID[location.ID] = location
+	}
+
+	events := make([]models.CalendarEvent, 0, len(shifts))
+	for _, shift := range shifts {
+		events = append(events, services.ShiftCalendarEvent(tenantID, shift, byID, withUser))
+	}
+
+	if len(userIDs) > 0 {
+		var absences []models.Absence
+		if err := db.Preload("User").
+			Where("user_id IN ? AND status = ? AND start_date < ? AND end_date >= ?", userIDs, models.AbsenceStatusApproved, to, calendarDay(from)).
+			Order("start_date ASC, id ASC").Find(&absences).Error; err != nil {
+			return "", nil, err
+		}
+		for _, absence := range absences {
+			events = append(events, services.AbsenceCalendarEvent(tenantID, absence, withUser))
+		}
+	}
+	return name, events, nil
+}
+
+// validateCalendarTarget prüft Umfang und Ziel eines Kalender-Abonnements.
+// Liefert eine Fehlermeldung oder einen leeren String.
+func validateCalendarTarget(db *gorm.DB, scope string, targetID uint) string {
+	var model interface{}
+	switch scope {
+	case models.CalendarScopeUser:
+		model = &models.User{}
+	case models.CalendarScopeTeam:
+		model = &models.Team{}
+	case models.CalendarScopeSchedule:
+		model = &models.Schedule{}
+	default:
+		return "Umfang (scope) muss user, team oder schedule sein"
+	}
+	if targetID == 0 {
+		return "Ziel (target_id) ist ein Pflichtfeld"
+	}
+
+	var count int64
+	db.Model(model).Where("id = ?", targetID).Count(&count)
+	if count == 0 {
+		return "Ziel des Kalender-Abonnements nicht gefunden"
+	}
+	return ""
+}
+
+// canSubscribeCalendar prüft, ob der angemeldete Benutzer das Abonnement verwalten darf: den eigenen Kalender,
+// den Kalender des eigenen Teams (Planer jedes Teams) und jeden Schichtplan. Ohne Anmeldung ist alles erlaubt.
+func canSubscribeCalendar(c echo.Context, scope string, targetID uint) bool {
+	user := currentUser(c)
+	switch {
+	case user == nil || user.IsAdmin:
+		return true
+	case scope == models.CalendarScopeUser:
+		return user.ID == targetID
+	case scope == models.CalendarScopeTeam:
+		return user.CanApprove() || user.TeamID != nil && *user.TeamID == targetID
+	default:
+		return true
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// fetchCalendar ruft das iCalendar-Abonnement eines Benutzers mit Token ab
+func fetchCalendar(t *testing.T, userID uint, token string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?token="+token, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.FormatUint(uint64(userID), 10))
+	assert.NoError(t, GetUserCalendar(c))
+	return rec
+}
+
+func TestUserCalendarFeed(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+	assert.NoError(t, database.EnsureDefaultTenant(database.DB))
+
+	user := models.User{Username: "kalender", Email: "kalender@example.com", Password: "x", AccountNumber: "I1", Name: "Kalender User", IsActive: true}
+	other := models.User{Username: "neugierig", Email: "neugierig@example.com", Password: "x", AccountNumber: "I2", Name: "Neugierig", IsActive: true}
+	database.DB.Create(&user)
+	database.DB.Create(&other)
+
+	now := time.Now().UTC().Truncate(time.Hour)
+	shiftType := models.ShiftType{Name: "Spätschicht", Color: "#8B5CF6"}
+	database.DB.Create(&shiftType)
+	schedule := models.Schedule{Name: "Aktuell", StartDate: now, EndDate: now.AddDate(0, 1, 0)}
+	database.DB.Create(&schedule)
+	shift := models.Shift{UserID: user.ID, ScheduleID: schedule.ID, ShiftTypeID: &shiftType.ID, StartTime: now.Add(24 * time.Hour), EndTime: now.Add(32 * time.Hour), IsActive: true}
+	database.DB.Create(&shift)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 7)
+	database.DB.Create(&models.Absence{UserID: user.ID, Type: models.AbsenceTypeVacation, StartDate: day, EndDate: day, Status: models.AbsenceStatusApproved})
+	database.DB.Create(&models.Absence{UserID: user.ID, Type: models.AbsenceTypeTraining, StartDate: day.AddDate(0, 0, 1), EndDate: day.AddDate(0, 0, 1), Status: models.AbsenceStatusRequested})
+
+	create := func(actor *models.User) *httptest.ResponseRecorder {
+		e := echo.New()
+		body := `{"scope":"user","target_id":` + strconv.Itoa(int(user.ID)) + `}`
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.Set(currentUserContextKey, actor)
+		assert.NoError(t, CreateCalendarFeed(c))
+		return rec
+	}
+
+	// Nur der Benutzer selbst darf seinen Kalender abonnieren
+	assert.Equal(t, http.StatusForbidden, create(&other).Code)
+
+	rec := create(&user)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var feed models.CalendarFeed
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &feed))
+	assert.Contains(t, feed.URL, "/api/users/"+strconv.Itoa(int(user.ID))+"/calendar.ics?token=spc_")
+
+	rec = fetchCalendar(t, user.ID, feed.Token)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentType), "text/calendar")
+	body := rec.Body.String()
+	assert.Contains(t, body, "UID:shift-1-"+strconv.Itoa(int(shift.ID))+"@schichtplaner")
+	assert.Contains(t, body, "SUMMARY:Spätschicht\r\n")
+	assert.Contains(t, body, "SUMMARY:Urlaub\r\n")
+	assert.NotContains(t, body, "Fortbildung")
+
+	// Falsches Ziel und falsches Token
+	assert.Equal(t, http.StatusNotFound, fetchCalendar(t, other.ID, feed.Token).Code)
+	assert.Equal(t, http.StatusNotFound, fetchCalendar(t, user.ID, "spc_falsch").Code)
+	assert.Equal(t, http.StatusUnauthorized, fetchCalendar(t, user.ID, "").Code)
+
+	// Neues Token macht das alte ungültig
+	rec = create(&user)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var regenerated models.CalendarFeed
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &regenerated))
+	assert.Equal(t, feed.ID, regenerated.ID)
+	assert.Equal(t, http.StatusNotFound, fetchCalendar(t, user.ID, feed.Token).Code)
+	assert.Equal(t, http.StatusOK, fetchCalendar(t, user.ID, regenerated.Token).Code)
+
+	// Nach dem Widerruf kann das Abonnement neu angelegt werden
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(currentUserContextKey, &user)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(feed.ID)))
+	if assert.NoError(t, DeleteCalendarFeed(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	assert.Equal(t, http.StatusNotFound, fetchCalendar(t, user.ID, regenerated.Token).Code)
+
+	rec = create(&user)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var recreated models.CalendarFeed
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &recreated))
+	assert.Equal(t, http.StatusOK, fetchCalendar(t, user.ID, recreated.Token).Code)
+}
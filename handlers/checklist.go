@@ -0,0 +1,427 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+	"schichtplaner/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// GetChecklistTemplates gibt alle Checklisten-Vorlagen zurück
+func GetChecklistTemplates(c echo.Context) error {
+	var templates []models.ChecklistTemplate
+	if err := tenantDB(c).Order("name ASC").Find(&templates).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Checklisten-Vorlagen",
+		})
+	}
+
+	return c.JSON(http.StatusOK, templates)
+}
+
+// CreateChecklistTemplate legt eine neue Checklisten-Vorlage an
+func CreateChecklistTemplate(c echo.Context) error {
+	template := models.ChecklistTemplate{IsActive: true}
+	if err := c.Bind(&template); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Checklisten-Vorlage",
+		})
+	}
+	template.ID = 0
+
+	if message := validateChecklistTemplate(tenantDB(c), &template); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	if err := tenantDB(c).Create(&template).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen der Checklisten-Vorlage",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, template)
+}
+
+// UpdateChecklistTemplate aktualisiert eine Checklisten-Vorlage; bereits angelegte Checklisten bleiben unverändert
+func UpdateChecklistTemplate(c echo.Context) error {
+	template, err := loadChecklistTemplateFromParam(c)
+	if err != nil || template == nil {
+		return err
+	}
+
+	updateData := *template
+	updateData.Items = nil
+	if err := c.Bind(&updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Checklisten-Vorlage",
+		})
+	}
+	updateData.Base = template.Base
+
+	if message := validateChecklistTemplate(tenantDB(c), &updateData); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	// Save statt Updates, damit auch is_active=false übernommen wird
+	if err := tenantDB(c).Save(&updateData).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Aktualisieren der Checklisten-Vorlage",
+		})
+	}
+
+	return c.JSON(http.StatusOK, updateData)
+}
+
+// DeleteChecklistTemplate löscht eine Checklisten-Vorlage, die keinem Schichttyp mehr zugeordnet ist
+func DeleteChecklistTemplate(c echo.Context) error {
+	template, err := loadChecklistTemplateFromParam(c)
+	if err != nil || template == nil {
+		return err
+	}
+
+	var shiftTypes []models.ShiftType
+	tenantDB(c).Find(&shiftTypes)
+	for _, shiftType := range shiftTypes {
+		for _, id := range shiftType.ChecklistTemplateIDs {
+			if id == template.ID {
+				return c.JSON(http.StatusBadRequest, map[string]string{
+					"error": "Checklisten-Vorlage kann nicht gelöscht werden, da sie noch einem Schichttyp zugeordnet ist",
+				})
+			}
+		}
+	}
+
+	if err := tenantDB(c).Delete(template).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen der Checklisten-Vorlage",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Checklisten-Vorlage erfolgreich gelöscht",
+	})
+}
+
+// GetShiftChecklists gibt die Checklisten einer Schicht zurück; fehlende Checklisten aus den Vorlagen des
+// Schichttyps werden dabei angelegt
+func GetShiftChecklists(c echo.Context) error {
+	shift, err := loadShiftFromParam(c)
+	if err != nil || shift == nil {
+		return err
+	}
+
+	if err := ensureShiftChecklists(tenantDB(c), []models.Shift{*shift}); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Anlegen der Checklisten",
+		})
+	}
+
+	checklists, err := loadShiftChecklists(tenantDB(c), []uint{shift.ID})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Checklisten",
+		})
+	}
+
+	return c.JSON(http.StatusOK, checklists)
+}
+
+// UpdateShiftChecklistItem hakt eine Aufgabe einer Schicht-Checkliste ab ("done": true) oder setzt sie zurück.
+// Nur der eingeplante Benutzer und Administratoren dürfen Aufgaben abhaken.
+func UpdateShiftChecklistItem(c echo.Context) error {
+	shift, err := loadShiftFromParam(c)
+	if err != nil || shift == nil {
+		return err
+	}
+	if !canManageUser(c, shift.UserID) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Nur der eingeplante Benutzer darf Aufgaben dieser Schicht abhaken",
+		})
+	}
+
+	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Aufgaben-ID",
+		})
+	}
+
+	var request struct {
+		Done *bool `json:"done"`
+	}
+	if err := c.Bind(&request); err != nil || request.Done == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Erledigt (done) ist ein Pflichtfeld",
+		})
+	}
+
+	var item models.ShiftChecklistItem
+	if err := tenantDB(c).
+		Where("id = ? AND checklist_id IN (?)", itemID, tenantDB(c).Model(&models.ShiftChecklist{}).Select("id").Where("shift_id = ?", shift.ID)).
+		First(&item).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Aufgabe nicht gefunden",
+		})
+	}
+
+	if *request.Done && item.CompletedAt == nil {
+		now := time.Now()
+		item.CompletedAt = &now
+		item.CompletedByID = nil
+		if actor := currentUser(c); actor != nil {
+			item.CompletedByID = &actor.ID
+		}
+	} else if !*request.Done {
+		item.CompletedAt = nil
+		item.CompletedByID = nil
+	}
+
+	if err := tenantDB(c).Save(&item).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Speichern der Aufgabe",
+		})
+	}
+
+	checklists, err := loadShiftChecklists(tenantDB(c), []uint{shift.ID})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Checklisten",
+		})
+	}
+	for _, checklist := range checklists {
+		if checklist.ID == item.ChecklistID {
+			return c.JSON(http.StatusOK, checklist)
+		}
+	}
+	return c.JSON(http.StatusOK, item)
+}
+
+// GetChecklistReport liefert die unvollständigen Checklisten je Kalendertag und Standort für Schichten, die im
+// Zeitraum [from, to) beginnen (RFC3339, Standard: heute in der Zeitzone der Organisation); optional gefiltert nach location_id
+func GetChecklistReport(c echo.Context) error {
+	orgLoc := models.OrganisationLocation()
+	now := time.Now().In(orgLoc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, orgLoc)
+	from, to, message := periodFromQuery(c, today, today.AddDate(0, 0, 1))
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	locationID, message := locationIDFromQuery(c)
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	query := tenantDB(c).Preload("User").Preload("Schedule").
+		Where("start_time >= ? AND start_time < ? AND shift_type_id IS NOT NULL", from, to)
+	if locationID != 0 {
+		query = filterShiftsByLocation(query, locationID)
+	}
+	var shifts []models.Shift
+	if err := query.Order("start_time ASC").Find(&shifts).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Schichten",
+		})
+	}
+
+	if err := ensureShiftChecklists(tenantDB(c), shifts); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Anlegen der Checklisten",
+		})
+	}
+	shiftIDs := make([]uint, 0, len(shifts))
+	for _, shift := range shifts {
+		shiftIDs = append(shiftIDs, shift.ID)
+	}
+	checklists, err := loadShiftChecklists(tenantDB(c), shiftIDs)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Checklisten",
+		})
+	}
+
+	var locations []models.Location
+	tenantDB(c).Find(&locations)
+	byID := make(map[uint]models.Location, len(locations))
+	for _, location := range locations {
+		byID[location.ID] = location
+	}
+
+	return c.JSON(http.StatusOK, services.IncompleteChecklists(shifts, checklists, byID, time.Now()))
+}
+
+// validateChecklistTemplate prüft eine Checklisten-Vorlage und liefert die erste Fehlermeldung oder einen leeren String
+func validateChecklistTemplate(db *gorm.DB, template *models.ChecklistTemplate) string {
+	validator := utils.NewValidator()
+	validator.RequiredString("Name", template.Name, "Name ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return result.Errors[0]
+	}
+
+	template.Items = services.NormalizeChecklistItems(template.Items)
+	if len(template.Items) == 0 {
+		return "Checkliste muss mindestens eine Aufgabe enthalten"
+	}
+
+	var duplicates int64
+	db.Model(&models.ChecklistTemplate{}).Where("name = ? AND id <> ?", template.Name, template.ID).Count(&duplicates)
+	if duplicates > 0 {
+		return "Checklisten-Vorlage mit diesem Namen existiert bereits"
+	}
+	return ""
+}
+
+// validateChecklistTemplateIDs prüft, ob alle einem Schichttyp zugeordneten Vorlagen existieren.
+// Liefert eine Fehlermeldung oder einen leeren String.
+func validateChecklistTemplateIDs(db *gorm.DB, ids []uint) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	var count int64
+	db.Model(&models.ChecklistTemplate{}).Where("id IN ?", ids).Count(&count)
+	if int(count) != len(uniqueIDs(ids)) {
+		return "Checklisten-Vorlage nicht gefunden"
+	}
+	return ""
+}
+
+// uniqueIDs entfernt doppelte IDs unter Beibehaltung der Reihenfolge
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// ensureShiftChecklists legt für die Schichten die noch fehlenden Checklisten aus den Vorlagen ihrer Schichttypen an
+func ensureShiftChecklists(db *gorm.DB, shifts []models.Shift) error {
+	typeIDs := make([]uint, 0)
+	shiftIDs := make([]uint, 0, len(shifts))
+	for _, shift := range shifts {
+		if shift.ShiftTypeID != nil {
+			typeIDs = append(typeIDs, *shift.ShiftTypeID)
+			shiftIDs = append(shiftIDs, shift.ID)
+		}
+	}
+	if len(shiftIDs) == 0 {
+		return nil
+	}
+
+	var shiftTypes []models.ShiftType
+	if err := db.Where("id IN ?", uniqueIDs(typeIDs)).Find(&shiftTypes).Error; err != nil {
+		return err
+	}
+	templateIDs := make([]uint, 0)
+	for _, shiftType := range shiftTypes {
+		templateIDs = append(templateIDs, shiftType.ChecklistTemplateIDs...)
+	}
+	if len(templateIDs) == 0 {
+		return nil
+	}
+	var templates []models.ChecklistTemplate
+	if err := db.Where("id IN ?", uniqueIDs(templateIDs)).Find(&templates).Error; err != nil {
+		return err
+	}
+	templatesByID := make(map[uint]models.ChecklistTemplate, len(templates))
+	for _, template := range templates {
+		templatesByID[template.ID] = template
+	}
+
+	var existing []models.ShiftChecklist
+	if err := db.Where("shift_id IN ?", shiftIDs).Find(&existing).Error; err != nil {
+		return err
+	}
+	existingByShift := make(map[uint][]models.ShiftChecklist)
+	for _, checklist := range existing {
+		existingByShift[checklist.ShiftID] = append(existingByShift[checklist.ShiftID], checklist)
+	}
+
+	missing := make([]models.ShiftChecklist, 0)
+	for _, shift := range shifts {
+		if shift.ShiftTypeID == nil {
+			continue
+		}
+		var shiftTemplates []models.ChecklistTemplate
+		for _, shiftType := range shiftTypes {
+			if shiftType.ID != *shift.ShiftTypeID {
+				continue
+			}
+			for _, id := range uniqueIDs(shiftType.ChecklistTemplateIDs) {
+				if template, ok := templatesByID[id]; ok {
+					shiftTemplates = append(shiftTemplates, template)
+				}
+			}
+		}
+		missing = append(missing, services.MissingShiftChecklists(shift, shiftTemplates, existingByShift[shift.ID])...)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return db.Create(&missing).Error
+}
+
+// loadShiftChecklists lädt die Checklisten der Schichten mit ihren Aufgaben
+func loadShiftChecklists(db *gorm.DB, shiftIDs []uint) ([]models.ShiftChecklist, error) {
+	checklists := make([]models.ShiftChecklist, 0)
+	if len(shiftIDs) == 0 {
+		return checklists, nil
+	}
+	err := db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Where("shift_id IN ?", shiftIDs).Order("shift_id ASC, id ASC").Find(&checklists).Error
+	return checklists, err
+}
+
+// loadShiftFromParam lädt die Schicht aus dem Pfadparameter id; bei Fehlern wird direkt geantwortet und nil geliefert
+func loadShiftFromParam(c echo.Context) (*models.Shift, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Schicht-ID",
+		})
+	}
+
+	var shift models.Shift
+	if err := tenantDB(c).First(&shift, id).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Schicht nicht gefunden",
+		})
+	}
+	return &shift, nil
+}
+
+// loadChecklistTemplateFromParam lädt die Vorlage aus dem Pfadparameter id; bei Fehlern wird direkt geantwortet und nil geliefert
+func loadChecklistTemplateFromParam(c echo.Context) (*models.ChecklistTemplate, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Checklisten-Vorlagen-ID",
+		})
+	}
+
+	var template models.ChecklistTemplate
+	if err := tenantDB(c).First(&template, id).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Checklisten-Vorlage nicht gefunden",
+		})
+	}
+	return &template, nil
+}
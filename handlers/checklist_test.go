@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShiftChecklists(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "kasse", Email: "kasse@example.com", Password: "x", AccountNumber: "C1", Name: "Kasse User", IsActive: true}
+	other := models.User{Username: "fremd", Email: "fremd@example.com", Password: "x", AccountNumber: "C2", Name: "Fremd", IsActive: true}
+	database.DB.Create(&user)
+	database.DB.Create(&other)
+
+	// Vorlage ohne Aufgaben wird abgelehnt
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"name":"Leer","items":[" "]}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	assert.NoError(t, CreateChecklistTemplate(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"name":"Schließdienst","items":["Kassensturz","Alarmanlage"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	assert.NoError(t, CreateChecklistTemplate(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var template models.ChecklistTemplate
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &template))
+
+	shiftType := models.ShiftType{Name: "Spätschicht", ChecklistTemplateIDs: []uint{template.ID}}
+	database.DB.Create(&shiftType)
+	location := models.Location{Name: "Filiale Nord"}
+	database.DB.Create(&location)
+	start := time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC)
+	schedule := models.Schedule{Name: "März", StartDate: start, EndDate: start.AddDate(0, 0, 7), LocationID: &location.ID}
+	database.DB.Create(&schedule)
+	shift := models.Shift{UserID: user.ID, ScheduleID: schedule.ID, ShiftTypeID: &shiftType.ID, StartTime: start, EndTime: start.Add(8 * time.Hour)}
+	database.DB.Create(&shift)
+
+	// Checkliste wird beim ersten Abruf aus der Vorlage angelegt
+	rec = callHandler(t, GetShiftChecklists, handlerRequest{method: http.MethodGet, params: pathParams{"id": shift.ID}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var checklists []models.ShiftChecklist
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &checklists))
+	if !assert.Len(t, checklists, 1) || !assert.Len(t, checklists[0].Items, 2) {
+		return
+	}
+	item := checklists[0].Items[0]
+	assert.Equal(t, "Kassensturz", item.Title)
+
+	// Nur der eingeplante Benutzer hakt ab
+	assert.Equal(t, http.StatusForbidden, callHandler(t, UpdateShiftChecklistItem, handlerRequest{method: http.MethodPut, params: pathParams{"id": shift.ID, "item_id": item.ID}, actor: &other, body: `{"done":true}`}).Code)
+	rec = callHandler(t, UpdateShiftChecklistItem, handlerRequest{method: http.MethodPut, params: pathParams{"id": shift.ID, "item_id": item.ID}, actor: &user, body: `{"done":true}`})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"open_items":1`)
+
+	var stored models.ShiftChecklistItem
+	database.DB.First(&stored, item.ID)
+	assert.NotNil(t, stored.CompletedAt)
+	if assert.NotNil(t, stored.CompletedByID) {
+		assert.Equal(t, user.ID, *stored.CompletedByID)
+	}
+
+	// Vorlage ist noch einem Schichttyp zugeordnet
+	assert.Equal(t, http.StatusBadRequest, callHandler(t, DeleteChecklistTemplate, handlerRequest{method: http.MethodDelete, params: pathParams{"id": template.ID}}).Code)
+
+	// Bericht: eine offene Aufgabe am 04.03. in Filiale Nord
+	req = httptest.NewRequest(http.MethodGet, "/?from=2024-03-04T00:00:00Z&to=2024-03-05T00:00:00Z&location_id="+strconv.Itoa(int(location.ID)), nil)
+	rec = httptest.NewRecorder()
+	assert.NoError(t, GetChecklistReport(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var reports []models.ChecklistDayReport
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &reports))
+	if assert.Len(t, reports, 1) {
+		assert.Equal(t, "Filiale Nord", reports[0].LocationName)
+		if assert.Len(t, reports[0].Checklists, 1) {
+			assert.Equal(t, 1, reports[0].Checklists[0].OpenItems)
+			assert.True(t, reports[0].Checklists[0].Overdue)
+		}
+	}
+
+	// Nach dem Abhaken der letzten Aufgabe ist der Bericht leer
+	callHandler(t, UpdateShiftChecklistItem, handlerRequest{method: http.MethodPut, params: pathParams{"id": shift.ID, "item_id": checklists[0].Items[1].ID}, actor: &user, body: `{"done":true}`})
+	rec = httptest.NewRecorder()
+	assert.NoError(t, GetChecklistReport(e.NewContext(req, rec)))
+	assert.Equal(t, "[]\n", rec.Body.String())
+}
@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// commentRequest sind die Angaben beim Schreiben oder Bearbeiten eines Kommentars
+type commentRequest struct {
+	Body       string `json:"body"`
+	ParentID   *uint  `json:"parent_id"`
+	IsHandover bool   `json:"is_handover"`
+}
+
+// GetShiftComments gibt die Kommentar-Threads einer Schicht zurück
+func GetShiftComments(c echo.Context) error {
+	return getComments(c, models.CommentTargetShift)
+}
+
+// CreateShiftComment schreibt einen Kommentar oder eine Übergabenotiz zu einer Schicht
+func CreateShiftComment(c echo.Context) error {
+	return createComment(c, models.CommentTargetShift)
+}
+
+// GetScheduleComments gibt die Kommentar-Threads eines Schichtplans zurück
+func GetScheduleComments(c echo.Context) error {
+	return getComments(c, models.CommentTargetSchedule)
+}
+
+// CreateScheduleComment schreibt einen Kommentar zu einem Schichtplan
+func CreateScheduleComment(c echo.Context) error {
+	return createComment(c, models.CommentTargetSchedule)
+}
+
+// GetComment gibt einen Kommentar mit seinem Bearbeitungsverlauf zurück
+func GetComment(c echo.Context) error {
+	comment, err := loadCommentFromParam(c)
+	if err != nil || comment == nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, comment)
+}
+
+// UpdateComment bearbeitet den Text eines Kommentars; der vorherige Text wird im Verlauf gespeichert.
+// Nur der Verfasser und Administratoren dürfen Kommentare bearbeiten.
+func UpdateComment(c echo.Context) error {
+	comment, err := loadCommentFromParam(c)
+	if err != nil || comment == nil {
+		return err
+	}
+	if !canEditComment(c, *comment) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Nur der Verfasser darf den Kommentar bearbeiten",
+		})
+	}
+
+	var request commentRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Kommentardaten",
+		})
+	}
+	request.Body = strings.TrimSpace(request.Body)
+	if err := services.ValidateCommentBody(request.Body); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	if request.Body == comment.Body {
+		return c.JSON(http.StatusOK, comment)
+	}
+
+	revision := models.CommentRevision{CommentID: comment.ID, Body: comment.Body}
+	if actor := currentUser(c); actor != nil {
+		revision.EditorID = &actor.ID
+	}
+	now := time.Now()
+	comment.Body = request.Body
+	comment.EditedAt = &now
+
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&revision).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("Author", "Mentions", "Revisions").Save(comment).Error; err != nil {
+			return err
+		}
+		return saveCommentMentions(tx, comment)
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Bearbeiten des Kommentars",
+		})
+	}
+
+	updated, _ := loadComment(tenantDB(c), comment.ID)
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteComment löscht einen Kommentar ohne Antworten. Nur der Verfasser und Administratoren dürfen Kommentare löschen.
+func DeleteComment(c echo.Context) error {
+	comment, err := loadCommentFromParam(c)
+	if err != nil || comment == nil {
+		return err
+	}
+	if !canEditComment(c, *comment) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Nur der Verfasser darf den Kommentar löschen",
+		})
+	}
+
+	var replies int64
+	tenantDB(c).Model(&models.Comment{}).Where("parent_id = ?", comment.ID).Count(&replies)
+	if replies > 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Kommentar mit Antworten kann nicht gelöscht werden",
+		})
+	}
+
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.CommentMention{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(comment).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen des Kommentars",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Kommentar erfolgreich gelöscht",
+	})
+}
+
+// GetShiftHandover gibt die Übergabenotizen der vorherigen Schicht desselben Schichttyps und Teams zurück
+func GetShiftHandover(c echo.Context) error {
+	shift, err := loadShiftFromParam(c)
+	if err != nil || shift == nil {
+		return err
+	}
+
+	handover := models.ShiftHandover{Notes: make([]models.Comment, 0)}
+	if shift.ShiftTypeID == nil {
+		return c.JSON(http.StatusOK, handover)
+	}
+
+	var user models.User
+	tenantDB(c).First(&user, shift.UserID)
+	teamUsers := tenantDB(c).Model(&models.User{}).Select("id")
+	if user.TeamID != nil {
+		teamUsers = teamUsers.Where("team_id = ?", *user.TeamID)
+	} else {
+		teamUsers = teamUsers.Where("team_id IS NULL")
+	}
+
+	// Nur die letzte frühere Schicht ist relevant; eine Woche Vorlauf deckt auch Wochenendpausen ab
+	var candidates []models.Shift
+	if err := tenantDB(c).Preload("User").
+		Where("shift_type_id = ? AND id <> ? AND start_time < ? AND start_time >= ? AND user_id IN (?)",
+			*shift.ShiftTypeID, shift.ID, shift.StartTime, shift.StartTime.AddDate(0, 0, -7), teamUsers).
+		Find(&candidates).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Schichten",
+		})
+	}
+
+	previous := services.PreviousHandoverShift(*shift, candidates)
+	if previous == nil {
+		return c.JSON(http.StatusOK, handover)
+	}
+	handover.PreviousShift = previous
+	if err := tenantDB(c).Preload("Author").Preload("Mentions").
+		Where("target_type = ? AND target_id = ? AND is_handover = ?", models.CommentTargetShift, previous.ID, true).
+		Order("created_at ASC, id ASC").Find(&handover.Notes).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Übergabenotizen",
+		})
+	}
+
+	return c.JSON(http.StatusOK, handover)
+}
+
+// GetUserMentions gibt die Kommentare zurück, in denen ein Benutzer erwähnt wurde (neueste zuerst, höchstens 100)
+func GetUserMentions(c echo.Context) error {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return err
+	}
+	if !canManageUser(c, user.ID) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Keine Berechtigung für die Erwähnungen dieses Benutzers",
+		})
+	}
+
+	mentions := tenantDB(c).Model(&models.CommentMention{}).Select("comment_id").Where("user_id = ?", user.ID)
+	var comments []models.Comment
+	if err := tenantDB(c).Preload("Author").Where("id IN (?)", mentions).
+		Order("created_at DESC, id DESC").Limit(100).Find(&comments).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Erwähnungen",
+		})
+	}
+
+	return c.JSON(http.StatusOK, comments)
+}
+
+// getComments gibt die Kommentare eines Objekts als Threads zurück
+func getComments(c echo.Context, targetType string) error {
+	targetID, err := loadCommentTarget(c, targetType)
+	if err != nil || targetID == 0 {
+		return err
+	}
+
+	var comments []models.Comment
+	if err := tenantDB(c).Preload("Author").Preload("Mentions").
+		Where("target_type = ? AND target_id = ?", targetType, targetID).
+		Find(&comments).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Kommentare",
+		})
+	}
+
+	return c.JSON(http.StatusOK, services.CommentThreads(comments))
+}
+
+// createComment schreibt einen Kommentar oder eine Antwort zu einem Objekt und speichert die Erwähnungen
+func createComment(c echo.Context, targetType string) error {
+	targetID, err := loadCommentTarget(c, targetType)
+	if err != nil || targetID == 0 {
+		return err
+	}
+
+	var request commentRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Kommentardaten",
+		})
+	}
+	request.Body = strings.TrimSpace(request.Body)
+	if err := services.ValidateCommentBody(request.Body); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	if request.IsHandover && targetType != models.CommentTargetShift {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Übergabenotizen sind nur an Schichten möglich",
+		})
+	}
+	if request.ParentID != nil {
+		var parent models.Comment
+		if err := tenantDB(c).Where("target_type = ? AND target_id = ?", targetType, targetID).First(&parent, *request.ParentID).Error; err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Beantworteter Kommentar nicht gefunden",
+			})
+		}
+	}
+
+	comment := models.Comment{
+		TargetType: targetType,
+		TargetID:   targetID,
+		ParentID:   request.ParentID,
+		Body:       request.Body,
+		IsHandover: request.IsHandover,
+	}
+	if actor := currentUser(c); actor != nil {
+		comment.AuthorID = &actor.ID
+	}
+
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Author", "Mentions", "Revisions").Create(&comment).Error; err != nil {
+			return err
+		}
+		return saveCommentMentions(tx, &comment)
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Speichern des Kommentars",
+		})
+	}
+
+	created, _ := loadComment(tenantDB(c), comment.ID)
+	return c.JSON(http.StatusCreated, created)
+}
+
+// saveCommentMentions ersetzt die Erwähnungen eines Kommentars durch die im Text genannten Benutzer;
+// unbekannte Benutzernamen werden ignoriert
+func saveCommentMentions(tx *gorm.DB, comment *models.Comment) error {
+	if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.CommentMention{}).Error; err != nil {
+		return err
+	}
+
+	usernames := services.MentionedUsernames(comment.Body)
+	if len(usernames) == 0 {
+		return nil
+	}
+	var users []models.User
+	if err := tx.Where("LOWER(username) IN ?", usernames).Find(&users).Error; err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return nil
+	}
+
+	mentions := make([]models.CommentMention, 0, len(users))
+	for _, user := range users {
+		mentions = append(mentions, models.CommentMention{CommentID: comment.ID, UserID: user.ID})
+	}
+	return tx.Create(&mentions).Error
+}
+
+// canEditComment prüft, ob der angemeldete Benutzer den Kommentar bearbeiten darf; ohne Anmeldung ist alles erlaubt
+func canEditComment(c echo.Context, comment models.Comment) bool {
+	user := currentUser(c)
+	return user == nil || user.IsAdmin || comment.AuthorID != nil && *comment.AuthorID == user.ID
+}
+
+// loadCommentTarget prüft, ob das kommentierte Objekt aus dem Pfadparameter id existiert, und liefert seine ID.
+// Bei Fehlern wird direkt geantwortet und 0 geliefert.
+func loadCommentTarget(c echo.Context, targetType string) (uint, error) {
+	if targetType == models.CommentTargetShift {
+		shift, err := loadShiftFromParam(c)
+		if err != nil || shift == nil {
+			return 0, err
+		}
+		return shift.ID, nil
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return 0, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Schichtplan-ID",
+		})
+	}
+	var schedule models.Schedule
+	if err := tenantDB(c).First(&schedule, id).Error; err != nil {
+		return 0, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Schichtplan nicht gefunden",
+		})
+	}
+	return schedule.ID, nil
+}
+
+// loadComment lädt einen Kommentar mit Verfasser, Erwähnungen und Bearbeitungsverlauf
+func loadComment(db *gorm.DB, id uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := db.Preload("Author").Preload("Mentions").Preload("Revisions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC, id ASC")
+	}).First(&comment, id).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// loadCommentFromParam lädt den Kommentar aus dem Pfadparameter id; bei Fehlern wird direkt geantwortet und nil geliefert
+func loadCommentFromParam(c echo.Context) (*models.Comment, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Kommentar-ID",
+		})
+	}
+
+	comment, err := loadComment(tenantDB(c), uint(id))
+	if err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Kommentar nicht gefunden",
+		})
+	}
+	return comment, nil
+}
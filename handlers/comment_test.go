@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommentThreadsAndMentions(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	author := models.User{Username: "autor", Email: "autor@example.com", Password: "x", AccountNumber: "M1", Name: "Autor", IsActive: true}
+	mentioned := models.User{Username: "Anna", Email: "anna@example.com", Password: "x", AccountNumber: "M2", Name: "Anna", IsActive: true}
+	database.DB.Create(&author)
+	database.DB.Create(&mentioned)
+
+	schedule := models.Schedule{Name: "März", StartDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&schedule)
+
+	// Übergabenotizen nur an Schichten
+	rec := callHandler(t, CreateScheduleComment, handlerRequest{params: pathParams{"id": schedule.ID}, actor: &author, body: `{"body":"Notiz","is_handover":true}`})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callHandler(t, CreateScheduleComment, handlerRequest{params: pathParams{"id": schedule.ID}, actor: &author, body: `{"body":"@anna bitte Ostern prüfen"}`})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var comment models.Comment
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &comment))
+	if assert.Len(t, comment.Mentions, 1) {
+		assert.Equal(t, mentioned.ID, comment.Mentions[0].UserID)
+	}
+
+	parent := strconv.Itoa(int(comment.ID))
+	rec = callHandler(t, CreateScheduleComment, handlerRequest{params: pathParams{"id": schedule.ID}, actor: &mentioned, body: `{"body":"Erledigt","parent_id":` + parent + `}`})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	rec = callHandler(t, GetScheduleComments, handlerRequest{params: pathParams{"id": schedule.ID}})
+	var threads []models.Comment
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &threads))
+	if assert.Len(t, threads, 1) {
+		assert.Len(t, threads[0].Replies, 1)
+	}
+
+	// Bearbeiten nur durch den Verfasser, mit Verlauf
+	assert.Equal(t, http.StatusForbidden, callHandler(t, UpdateComment, handlerRequest{params: pathParams{"id": comment.ID}, actor: &mentioned, body: `{"body":"Fremd"}`}).Code)
+	rec = callHandler(t, UpdateComment, handlerRequest{params: pathParams{"id": comment.ID}, actor: &author, body: `{"body":"Bitte Ostern prüfen"}`})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var updated models.Comment
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+	assert.NotNil(t, updated.EditedAt)
+	assert.Empty(t, updated.Mentions)
+	if assert.Len(t, updated.Revisions, 1) {
+		assert.Equal(t, "@anna bitte Ostern prüfen", updated.Revisions[0].Body)
+	}
+
+	// Kommentar mit Antworten bleibt erhalten
+	assert.Equal(t, http.StatusBadRequest, callHandler(t, DeleteComment, handlerRequest{params: pathParams{"id": comment.ID}, actor: &author}).Code)
+
+	rec = callHandler(t, GetUserMentions, handlerRequest{params: pathParams{"id": mentioned.ID}, actor: &mentioned})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "[]\n", rec.Body.String())
+}
+
+func TestShiftHandover(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	team := models.Team{Name: "Leitstelle"}
+	database.DB.Create(&team)
+	early := models.User{Username: "frueh", Email: "frueh@example.com", Password: "x", AccountNumber: "H1", Name: "Früh", TeamID: &team.ID, IsActive: true}
+	late := models.User{Username: "spaet", Email: "spaet@example.com", Password: "x", AccountNumber: "H2", Name: "Spät", TeamID: &team.ID, IsActive: true}
+	outsider := models.User{Username: "extern", Email: "extern@example.com", Password: "x", AccountNumber: "H3", Name: "Extern", IsActive: true}
+	database.DB.Create(&early)
+	database.DB.Create(&late)
+	database.DB.Create(&outsider)
+
+	shiftType := models.ShiftType{Name: "Dienst"}
+	database.DB.Create(&shiftType)
+	schedule := models.Schedule{Name: "März", StartDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&schedule)
+	start := time.Date(2024, 3, 4, 6, 0, 0, 0, time.UTC)
+	first := models.Shift{UserID: early.ID, ScheduleID: schedule.ID, ShiftTypeID: &shiftType.ID, StartTime: start, EndTime: start.Add(8 * time.Hour)}
+	other := models.Shift{UserID: outsider.ID, ScheduleID: schedule.ID, ShiftTypeID: &shiftType.ID, StartTime: start.Add(4 * time.Hour), EndTime: start.Add(12 * time.Hour)}
+	next := models.Shift{UserID: late.ID, ScheduleID: schedule.ID, ShiftTypeID: &shiftType.ID, StartTime: start.Add(8 * time.Hour), EndTime: start.Add(16 * time.Hour)}
+	database.DB.Create(&first)
+	database.DB.Create(&other)
+	database.DB.Create(&next)
+
+	assert.Equal(t, http.StatusCreated, callHandler(t, CreateShiftComment, handlerRequest{params: pathParams{"id": first.ID}, actor: &early, body: `{"body":"Fahrzeug 2 in der Werkstatt","is_handover":true}`}).Code)
+	assert.Equal(t, http.StatusCreated, callHandler(t, CreateShiftComment, handlerRequest{params: pathParams{"id": first.ID}, actor: &early, body: `{"body":"Normaler Kommentar"}`}).Code)
+	assert.Equal(t, http.StatusCreated, callHandler(t, CreateShiftComment, handlerRequest{params: pathParams{"id": other.ID}, actor: &outsider, body: `{"body":"Anderes Team","is_handover":true}`}).Code)
+
+	rec := callHandler(t, GetShiftHandover, handlerRequest{params: pathParams{"id": next.ID}, actor: &late})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var handover models.ShiftHandover
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &handover))
+	if assert.NotNil(t, handover.PreviousShift) {
+		assert.Equal(t, first.ID, handover.PreviousShift.ID)
+	}
+	if assert.Len(t, handover.Notes, 1) {
+		assert.Equal(t, "Fahrzeug 2 in der Werkstatt", handover.Notes[0].Body)
+	}
+}
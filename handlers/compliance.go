@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// ScheduleComplianceReport ist das Ergebnis der Arbeitszeitprüfung eines Schichtplans
+type ScheduleComplianceReport struct {
+	ScheduleID  uint                                  `json:"schedule_id"`
+	IsCompliant bool                                  `json:"is_compliant"` // Keine Verstöße mit Schweregrad error
+	Errors      int                                   `json:"errors"`
+	Warnings    int                                   `json:"warnings"`
+	Violations  []models.ComplianceViolation          `json:"violations"`
+	ByShift     map[uint][]models.ComplianceViolation `json:"by_shift"`
+}
+
+// GetScheduleCompliance prüft alle Schichten eines Schichtplans gegen das Arbeitszeitgesetz
+func GetScheduleCompliance(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Schichtplan-ID",
+		})
+	}
+
+	var schedule models.Schedule
+	if err := tenantDB(c).First(&schedule, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Schichtplan nicht gefunden",
+		})
+	}
+
+	var scheduleShifts []models.Shift
+	if err := tenantDB(c).Where("schedule_id = ?", schedule.ID).Find(&scheduleShifts).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Schichten",
+		})
+	}
+
+	shiftIDs := make(map[uint]bool, len(scheduleShifts))
+	var userIDs []uint
+	seenUsers := make(map[uint]bool)
+	for _, shift := range scheduleShifts {
+		shiftIDs[shift.ID] = true
+		if !seenUsers[shift.UserID] {
+			seenUsers[shift.UserID] = true
+			userIDs = append(userIDs, shift.UserID)
+		}
+	}
+
+	report := ScheduleComplianceReport{
+		ScheduleID: schedule.ID,
+		Violations: make([]models.ComplianceViolation, 0),
+		ByShift:    make(map[uint][]models.ComplianceViolation),
+	}
+
+	for _, userID := range userIDs {
+		violations, err := userComplianceViolations(tenantDB(c), userID, schedule.StartDate, schedule.EndDate)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Fehler beim Laden der Schichten",
+			})
+		}
+
+		for _, violation := range violations {
+			inSchedule := shiftIDs[violation.ShiftID] ||
+				(violation.ShiftID == 0 && !violation.Date.Before(schedule.StartDate) && !violation.Date.After(schedule.EndDate))
+			if !inSchedule {
+				continue
+			}
+
+			report.Violations = append(report.Violations, violation)
+			if violation.ShiftID != 0 {
+				report.ByShift[violation.ShiftID] = append(report.ByShift[violation.ShiftID], violation)
+			}
+			if violation.Severity == models.SeverityError {
+				report.Errors++
+			} else {
+				report.Warnings++
+			}
+		}
+	}
+
+	report.IsCompliant = report.Errors == 0
+	return c.JSON(http.StatusOK, report)
+}
+
+// newComplianceChecker erstellt den Checker mit der Konfiguration der Anwendung; Kalendertage werden in loc gebildet
+// und Feiertage nach dem Bundesland der Organisation bestimmt
+func newComplianceChecker(db *gorm.DB, loc *time.Location) *services.ComplianceChecker {
+	config := services.DefaultArbZGConfig()
+	config.Location = loc
+	if calendar, err := loadHolidayCalendar(db, models.OrganisationState()); err == nil {
+		config.IsHoliday = calendar.IsHoliday
+	}
+	return services.NewComplianceChecker(config)
+}
+
+// userComplianceViolations prüft Arbeitszeitgesetz, Teamregeln, Arbeitsverträge und Ruhezeiten nach Rufbereitschaftseinsätzen eines Benutzers für den Zeitraum [from, to].
+// Schichten aus anderen Plänen fließen in Ruhezeiten und den Ausgleichszeitraum ein.
+func userComplianceViolations(db *gorm.DB, userID uint, from, to time.Time) ([]models.ComplianceViolation, error) {
+	shifts, err := loadComplianceShifts(db, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	// Kalendertage, Sonntage und Wochenenden richten sich nach der Zeitzone des Benutzers
+	var user models.User
+	db.First(&user, userID)
+	loc := user.Location()
+
+	violations := newComplianceChecker(db, loc).CheckUser(userID, shifts)
+
+	rules, err := loadUserTeamRules(db, user)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) > 0 {
+		evaluator := services.NewTeamRuleEvaluator(loc)
+		violations = append(violations, evaluator.Evaluate(rules, userID, shifts, from, to)...)
+	}
+
+	// Vertragshistorie: Schichten ohne gültigen Vertrag und Überschreitung der vertraglichen Wochenarbeitszeit
+	contracts, err := loadUserContracts(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	violations = append(violations, services.CheckContracts(userID, contracts, shifts, from, to, loc)...)
+
+	// Einsätze in der Rufbereitschaft unterbrechen die Ruhezeit vor der nächsten Schicht
+	var callOuts []models.TimeEntry
+	if err := db.Where("user_id = ? AND on_call_duty_id IS NOT NULL AND clock_out >= ? AND clock_out <= ?", userID, from, to).
+		Find(&callOuts).Error; err != nil {
+		return nil, err
+	}
+	config := services.DefaultArbZGConfig()
+	config.Location = loc
+	violations = append(violations, services.OnCallRestViolations(config, userID, shifts, callOuts)...)
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Date.Before(violations[j].Date) })
+
+	return violations, nil
+}
+
+// loadComplianceShifts lädt die Schichten eines Benutzers, die für die Prüfung des Zeitraums relevant sind
+func loadComplianceShifts(db *gorm.DB, userID uint, from, to time.Time) ([]models.Shift, error) {
+	config := services.DefaultArbZGConfig()
+	windowStart := from.AddDate(0, 0, -7*config.AveragePeriodWeeks)
+	windowEnd := to.Add(24 * time.Hour)
+
+	var shifts []models.Shift
+	err := db.
+		Where("user_id = ? AND end_time >= ? AND start_time <= ?", userID, windowStart, windowEnd).
+		Order("start_time ASC").
+		Find(&shifts).Error
+	return shifts, err
+}
+
+// loadUserTeamRules lädt die aktiven Regeln des Teams, dem der Benutzer angehört
+func loadUserTeamRules(db *gorm.DB, user models.User) ([]models.TeamRule, error) {
+	if user.TeamID == nil {
+		return nil, nil
+	}
+
+	var rules []models.TeamRule
+	err := db.Where("team_id = ? AND is_active = ?", *user.TeamID, true).Find(&rules).Error
+	return rules, err
+}
+
+// checkShiftCompliance liefert die Verstöße, die eine gespeicherte Schicht betreffen.
+// Berücksichtigt werden auch Verstöße benachbarter Schichten, etwa eine verkürzte Ruhezeit,
+// sowie zeitraumbezogene Verstöße im Monat der Schicht.
+func checkShiftCompliance(db *gorm.DB, shiftID uint) []models.ComplianceViolation {
+	var shift models.Shift
+	if err := db.First(&shift, shiftID).Error; err != nil {
+		return nil
+	}
+
+	var user models.User
+	db.First(&user, shift.UserID)
+
+	start := shift.StartTime.In(user.Location())
+	monthStart := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+	monthEnd := monthStart.AddDate(0, 1, 0).Add(-time.Second)
+
+	all, err := userComplianceViolations(db, shift.UserID, monthStart, monthEnd)
+	if err != nil {
+		return nil
+	}
+
+	from := shift.StartTime.Add(-24 * time.Hour)
+	to := shift.EndTime.Add(24 * time.Hour)
+
+	var violations []models.ComplianceViolation
+	for _, violation := range all {
+		nearby := !violation.Date.Before(from) && !violation.Date.After(to)
+		if violation.ShiftID == shift.ID || violation.ShiftID == 0 || nearby {
+			violations = append(violations, violation)
+		}
+	}
+	return violations
+}
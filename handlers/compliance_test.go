@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetScheduleCompliance(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "arbzg", Email: "arbzg@example.com", Password: "hashedpassword", Name: "ArbZG User"}
+	database.DB.Create(&user)
+
+	schedule := models.Schedule{
+		Name:      "Januar 2024",
+		StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC),
+	}
+	database.DB.Create(&schedule)
+
+	// Spätschicht und Frühschicht am Folgetag verletzen die Ruhezeit
+	database.DB.Create(&models.Shift{
+		UserID: user.ID, ScheduleID: schedule.ID, BreakTime: 30,
+		StartTime: time.Date(2024, 1, 8, 14, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2024, 1, 8, 22, 0, 0, 0, time.UTC),
+	})
+	database.DB.Create(&models.Shift{
+		UserID: user.ID, ScheduleID: schedule.ID, BreakTime: 30,
+		StartTime: time.Date(2024, 1, 9, 6, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2024, 1, 9, 14, 0, 0, 0, time.UTC),
+	})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(schedule.ID)))
+
+	if assert.NoError(t, GetScheduleCompliance(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var report ScheduleComplianceReport
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.False(t, report.IsCompliant)
+		assert.Equal(t, 1, report.Errors)
+		assert.Len(t, report.Violations, 1)
+		assert.Equal(t, "arbzg_min_rest", report.Violations[0].Rule)
+	}
+}
+
+func TestCreateShift_ReturnsComplianceWarnings(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "arbzg", Email: "arbzg@example.com", Password: "hashedpassword", Name: "ArbZG User"}
+	database.DB.Create(&user)
+
+	schedule := models.Schedule{
+		Name:      "Januar 2024",
+		StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC),
+	}
+	database.DB.Create(&schedule)
+
+	// 12 Stunden ohne Pause verletzen Höchstarbeitszeit und Pausenregel, die Schicht wird trotzdem gespeichert
+	shiftJSON, _ := json.Marshal(models.Shift{
+		UserID:     user.ID,
+		ScheduleID: schedule.ID,
+		StartTime:  time.Date(2024, 1, 10, 6, 0, 0, 0, time.UTC),
+		EndTime:    time.Date(2024, 1, 10, 18, 0, 0, 0, time.UTC),
+	})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/shifts", bytes.NewBuffer(shiftJSON))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, CreateShift(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var shift models.Shift
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &shift))
+		assert.NotZero(t, shift.ID)
+		assert.Len(t, shift.Warnings, 2)
+	}
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+	"schichtplaner/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// VacationEntitlement ist der anteilige Urlaubsanspruch eines Benutzers in einem Kalenderjahr
+type VacationEntitlement struct {
+	UserID    uint                        `json:"user_id"`
+	Year      int                         `json:"year"`
+	Days      float64                     `json:"days"`
+	Contracts []models.EmploymentContract `json:"contracts"` // Im Jahr gültige Verträge
+}
+
+// GetUserContracts gibt die Vertragshistorie eines Benutzers zurück
+func GetUserContracts(c echo.Context) error {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return err
+	}
+
+	contracts, err := loadUserContracts(tenantDB(c), user.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Verträge",
+		})
+	}
+
+	return c.JSON(http.StatusOK, contracts)
+}
+
+// CreateUserContract legt einen neuen Vertrag an. Ein unbefristeter Vorgängervertrag endet am Vortag des neuen Vertrags.
+func CreateUserContract(c echo.Context) error {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return err
+	}
+
+	contract := models.EmploymentContract{WorkingDaysPerWeek: 5}
+	if err := c.Bind(&contract); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Vertragsdaten",
+		})
+	}
+	contract.ID = 0
+	contract.UserID = user.ID
+
+	if message := validateContract(&contract); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	message := ""
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		var previous models.EmploymentContract
+		if tx.Where("user_id = ? AND valid_to IS NULL AND valid_from < ?", user.ID, contract.ValidFrom).First(&previous).Error == nil {
+			end := contract.ValidFrom.AddDate(0, 0, -1)
+			if err := tx.Model(&previous).Update("valid_to", end).Error; err != nil {
+				return err
+			}
+		}
+
+		if contractOverlaps(tx, contract) {
+			message = "Vertrag überschneidet sich mit einem bestehenden Vertrag"
+			return gorm.ErrInvalidData
+		}
+		return tx.Create(&contract).Error
+	})
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen des Vertrags",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, contract)
+}
+
+// UpdateUserContract aktualisiert einen Vertrag
+func UpdateUserContract(c echo.Context) error {
+	contract, err := loadContractFromParams(c)
+	if err != nil || contract == nil {
+		return err
+	}
+
+	updateData := *contract
+	if err := c.Bind(&updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Vertragsdaten",
+		})
+	}
+	updateData.Base = contract.Base
+	updateData.UserID = contract.UserID
+
+	if message := validateContract(&updateData); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	if contractOverlaps(tenantDB(c), updateData) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Vertrag überschneidet sich mit einem bestehenden Vertrag",
+		})
+	}
+
+	// Save statt Updates, damit auch ein entferntes Vertragsende übernommen wird
+	if err := tenantDB(c).Save(&updateData).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Aktualisieren des Vertrags",
+		})
+	}
+
+	return c.JSON(http.StatusOK, updateData)
+}
+
+// DeleteUserContract löscht einen Vertrag
+func DeleteUserContract(c echo.Context) error {
+	contract, err := loadContractFromParams(c)
+	if err != nil || contract == nil {
+		return err
+	}
+
+	if err := tenantDB(c).Delete(contract).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen des Vertrags",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Vertrag erfolgreich gelöscht",
+	})
+}
+
+// GetVacationEntitlement gibt den anteiligen Urlaubsanspruch eines Jahres (year, Standard: aktuelles Jahr) zurück
+func GetVacationEntitlement(c echo.Context) error {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return err
+	}
+
+	year := time.Now().In(user.Location()).Year()
+	if value := c.QueryParam("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1900 || parsed > 9999 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültiges Jahr",
+			})
+		}
+		year = parsed
+	}
+
+	contracts, err := loadUserContracts(tenantDB(c), user.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Verträge",
+		})
+	}
+
+	inYear := make([]models.EmploymentContract, 0)
+	yearStart := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	for _, contract := range contracts {
+		if !contract.ValidFrom.After(yearEnd) && (contract.ValidTo == nil || !contract.ValidTo.Before(yearStart)) {
+			inYear = append(inYear, contract)
+		}
+	}
+
+	return c.JSON(http.StatusOK, VacationEntitlement{
+		UserID:    user.ID,
+		Year:      year,
+		Days:      services.VacationEntitlement(contracts, year),
+		Contracts: inYear,
+	})
+}
+
+// validateContract prüft einen Vertrag und normalisiert die Gültigkeit auf Kalendertage.
+// Liefert die erste Fehlermeldung oder einen leeren String.
+func validateContract(contract *models.EmploymentContract) string {
+	validator := utils.NewValidator()
+	validator.RequiredTime("ValidFrom", contract.ValidFrom, "Vertragsbeginn ist ein Pflichtfeld")
+	validator.RequiredString("EmploymentType", contract.EmploymentType, "Beschäftigungsart ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return result.Errors[0]
+	}
+
+	contract.ValidFrom = calendarDay(contract.ValidFrom)
+	if contract.ValidTo != nil {
+		validTo := calendarDay(*contract.ValidTo)
+		contract.ValidTo = &validTo
+	}
+
+	if err := services.ValidateEmploymentContract(*contract); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// contractOverlaps prüft, ob sich der Vertrag mit einem anderen Vertrag desselben Benutzers überschneidet
+func contractOverlaps(db *gorm.DB, contract models.EmploymentContract) bool {
+	query := db.Model(&models.EmploymentContract{}).
+		Where("user_id = ? AND id <> ?", contract.UserID, contract.ID).
+		Where("valid_to IS NULL OR valid_to >= ?", contract.ValidFrom)
+	if contract.ValidTo != nil {
+		query = query.Where("valid_from <= ?", *contract.ValidTo)
+	}
+
+	var count int64
+	query.Count(&count)
+	return count > 0
+}
+
+// loadUserContracts lädt die Vertragshistorie eines Benutzers chronologisch
+func loadUserContracts(db *gorm.DB, userID uint) ([]models.EmploymentContract, error) {
+	var contracts []models.EmploymentContract
+	err := db.Where("user_id = ?", userID).Order("valid_from ASC").Find(&contracts).Error
+	return contracts, err
+}
+
+// loadContractFromParams lädt den Vertrag aus den Pfadparametern id und contract_id; bei Fehlern wird direkt geantwortet und nil geliefert
+func loadContractFromParams(c echo.Context) (*models.EmploymentContract, error) {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	contractID, err := strconv.ParseUint(c.Param("contract_id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Vertrags-ID",
+		})
+	}
+
+	var contract models.EmploymentContract
+	if err := tenantDB(c).Where("user_id = ?", user.ID).First(&contract, contractID).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Vertrag nicht gefunden",
+		})
+	}
+	return &contract, nil
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// postContract legt über den Handler einen Vertrag an und liefert den Statuscode
+func postContract(t *testing.T, userID uint, body string) int {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(userID)))
+
+	assert.NoError(t, CreateUserContract(c))
+	return rec.Code
+}
+
+func TestCreateUserContract_ClosesPreviousContract(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "vertrag", Email: "vertrag@example.com", Password: "hashedpassword", Name: "Vertrag User"}
+	database.DB.Create(&user)
+
+	assert.Equal(t, http.StatusCreated, postContract(t, user.ID,
+		`{"valid_from":"2024-01-01T00:00:00Z","weekly_hours":40,"employment_type":"full_time","vacation_days":30}`))
+	assert.Equal(t, http.StatusCreated, postContract(t, user.ID,
+		`{"valid_from":"2024-06-15T00:00:00Z","weekly_hours":20,"working_days_per_week":4,"employment_type":"part_time","vacation_days":20}`))
+
+	contracts, err := loadUserContracts(database.DB, user.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, contracts, 2) {
+		assert.NotNil(t, contracts[0].ValidTo)
+		assert.True(t, time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC).Equal(*contracts[0].ValidTo))
+		assert.Nil(t, contracts[1].ValidTo)
+		assert.Equal(t, 5, contracts[0].WorkingDaysPerWeek)
+	}
+
+	// Überschneidung mit dem Vollzeitvertrag
+	assert.Equal(t, http.StatusBadRequest, postContract(t, user.ID,
+		`{"valid_from":"2024-03-01T00:00:00Z","valid_to":"2024-03-31T00:00:00Z","weekly_hours":30,"employment_type":"part_time"}`))
+	// Unbekannte Beschäftigungsart
+	assert.Equal(t, http.StatusBadRequest, postContract(t, user.ID,
+		`{"valid_from":"2025-01-01T00:00:00Z","weekly_hours":30,"employment_type":"freelancer"}`))
+}
+
+func TestTimeAccount_ProRatesContractChange(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "vertrag", Email: "vertrag@example.com", Password: "hashedpassword", Name: "Vertrag User", WeeklyHours: 40}
+	database.DB.Create(&user)
+	end := time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC)
+	database.DB.Create(&models.EmploymentContract{UserID: user.ID, ValidFrom: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), ValidTo: &end,
+		WeeklyHours: 40, WorkingDaysPerWeek: 5, EmploymentType: models.EmploymentFullTime})
+	database.DB.Create(&models.EmploymentContract{UserID: user.ID, ValidFrom: time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC),
+		WeeklyHours: 20, WorkingDaysPerWeek: 4, EmploymentType: models.EmploymentPartTime})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "month")
+	c.SetParamValues(strconv.Itoa(int(user.ID)), "2024-06")
+
+	if assert.NoError(t, GetUserTimeAccountMonth(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var sheet models.TimeAccountMonth
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &sheet))
+		// 10 Vollzeittage à 8 Stunden, 8 Teilzeittage à 5 Stunden
+		assert.Equal(t, 120.0, sheet.TargetHours)
+		assert.Equal(t, 0.0, sheet.OpeningBalance)
+	}
+}
+
+func TestGetVacationEntitlement(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "vertrag", Email: "vertrag@example.com", Password: "hashedpassword", Name: "Vertrag User"}
+	database.DB.Create(&user)
+	database.DB.Create(&models.EmploymentContract{UserID: user.ID, ValidFrom: time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC),
+		WeeklyHours: 40, WorkingDaysPerWeek: 5, EmploymentType: models.EmploymentFullTime, VacationDays: 30})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?year=2023", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(user.ID)))
+
+	if assert.NoError(t, GetVacationEntitlement(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var entitlement VacationEntitlement
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &entitlement))
+		assert.Equal(t, 8.0, entitlement.Days)
+		assert.Len(t, entitlement.Contracts, 1)
+	}
+}
+
+func TestCreateShift_WarnsWithoutContract(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "vertrag", Email: "vertrag@example.com", Password: "hashedpassword", Name: "Vertrag User"}
+	database.DB.Create(&user)
+	database.DB.Create(&models.EmploymentContract{UserID: user.ID, ValidFrom: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		WeeklyHours: 40, WorkingDaysPerWeek: 5, EmploymentType: models.EmploymentFullTime})
+	schedule := models.Schedule{Name: "Juni", StartDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&schedule)
+
+	body := `{"user_id":` + strconv.Itoa(int(user.ID)) + `,"schedule_id":` + strconv.Itoa(int(schedule.ID)) +
+		`,"start_time":"2024-06-28T08:00:00","end_time":"2024-06-28T16:30:00","break_time":30}`
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/shifts", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, CreateShift(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), "contract_missing")
+	}
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// GetTeamFairness wertet die Verteilung unbeliebter Schichten (Wochenende, Nacht, Feiertag, Spät) im Team für den
+// Zeitraum [from, to) aus (RFC3339, Standard: die letzten drei Monate einschließlich des aktuellen).
+// Die Anzahl je Benutzer wird auf 100 Sollstunden normalisiert und mit dem Teammittel verglichen.
+func GetTeamFairness(c echo.Context) error {
+	team, err := loadTeamFromParam(c)
+	if err != nil || team == nil {
+		return err
+	}
+
+	orgLoc := models.OrganisationLocation()
+	now := time.Now().In(orgLoc)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, orgLoc)
+	from, to, message := periodFromQuery(c, monthStart.AddDate(0, -2, 0), monthStart.AddDate(0, 1, 0))
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	report, err := teamFairness(tenantDB(c), *team, from, to)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Berechnen der Fairnessauswertung",
+		})
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// GetShiftCandidates liefert die Teammitglieder als Kandidaten für eine Schicht, geordnet nach ihrer bisherigen
+// Belastung in den Kategorien der Schicht; am wenigsten belastete verfügbare Kandidaten zuerst.
+// Team ist team_id oder das Team des eingeteilten Benutzers; Zeitraum [from, to) (RFC3339, Standard: drei Monate vor Schichtbeginn).
+func GetShiftCandidates(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Schicht-ID",
+		})
+	}
+
+	var shift models.Shift
+	if err := tenantDB(c).Preload("User").First(&shift, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Schicht nicht gefunden",
+		})
+	}
+
+	teamID := uint(0)
+	if shift.User.TeamID != nil {
+		teamID = *shift.User.TeamID
+	}
+	if value := c.QueryParam("team_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültige Team-ID",
+			})
+		}
+		teamID = uint(parsed)
+	}
+	if teamID == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Team (team_id) ist ein Pflichtfeld, wenn der eingeteilte Benutzer keinem Team angehört",
+		})
+	}
+	var team models.Team
+	if err := tenantDB(c).First(&team, teamID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Team nicht gefunden",
+		})
+	}
+
+	from, to, message := periodFromQuery(c, shift.StartTime.AddDate(0, -3, 0), shift.StartTime)
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	report, err := teamFairness(tenantDB(c), team, from, to)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Berechnen der Fairnessauswertung",
+		})
+	}
+	calendar, err := loadHolidayCalendar(tenantDB(c), models.OrganisationState())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Feiertage",
+		})
+	}
+	unavailable, err := unavailableCandidates(tenantDB(c), shift, report.Users)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Prüfen der Verfügbarkeit",
+		})
+	}
+
+	categories := services.ShiftFairnessCategories(shift, shift.Location(), calendar)
+	return c.JSON(http.StatusOK, models.ShiftCandidates{
+		ShiftID:    shift.ID,
+		TeamID:     team.ID,
+		Categories: categories,
+		From:       from,
+		To:         to,
+		Candidates: services.RankCandidates(report.Users, categories, unavailable),
+	})
+}
+
+// teamFairness berechnet die Fairnessauswertung der Teammitglieder für Schichten mit Beginn in [from, to).
+// Die Sollstunden ergeben sich aus der Vertragshistorie bzw. der Wochenarbeitszeit je Kalendertag in der Zeitzone des Benutzers.
+func teamFairness(db *gorm.DB, team models.Team, from, to time.Time) (*models.TeamFairnessReport, error) {
+	var members []models.User
+	if err := db.Where("team_id = ?", team.ID).Order("name ASC").Find(&members).Error; err != nil {
+		return nil, err
+	}
+	calendar, err := loadHolidayCalendar(db, models.OrganisationState())
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]services.FairnessInput, 0, len(members))
+	for _, member := range members {
+		contracts, err := loadUserContracts(db, member.ID)
+		if err != nil {
+			return nil, err
+		}
+		var shifts []models.Shift
+		if err := db.Where("user_id = ? AND start_time >= ? AND start_time < ?", member.ID, from, to).Find(&shifts).Error; err != nil {
+			return nil, err
+		}
+
+		target := services.ContractTarget(contracts, member.WeeklyHours)
+		loc := member.Location()
+		first, last := from.In(loc), to.In(loc)
+		var targetHours float64
+		for day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, time.UTC); day.Before(time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC)); day = day.AddDate(0, 0, 1) {
+			targetHours += target(day)
+		}
+		inputs = append(inputs, services.FairnessInput{User: member, Shifts: shifts, TargetHours: targetHours})
+	}
+
+	report := models.TeamFairnessReport{TeamID: team.ID, TeamName: team.Name, From: from, To: to}
+	report.Mean, report.Users = services.TeamFairness(inputs, calendar)
+	return &report, nil
+}
+
+// unavailableCandidates ermittelt je Kandidat den Grund, warum er die Schicht nicht übernehmen kann:
+// bereits eingeteilt, überschneidende Schicht oder genehmigte Abwesenheit
+func unavailableCandidates(db *gorm.DB, shift models.Shift, users []models.UserFairness) (map[uint]string, error) {
+	userIDs := make([]uint, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.UserID)
+	}
+
+	unavailable := map[uint]string{shift.UserID: "Bereits eingeteilt"}
+	var overlapping []models.Shift
+	if err := db.Where("user_id IN ? AND id <> ? AND start_time < ? AND end_time > ?", userIDs, shift.ID, shift.EndTime, shift.StartTime).
+		Find(&overlapping).Error; err != nil {
+		return nil, err
+	}
+	for _, other := range overlapping {
+		if unavailable[other.UserID] == "" {
+			unavailable[other.UserID] = "Überschneidung mit einer anderen Schicht"
+		}
+	}
+
+	local := shift.StartTime.In(shift.Location())
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	var absences []models.Absence
+	if err := db.Where("user_id IN ? AND status = ? AND start_date <= ? AND end_date >= ?", userIDs, models.AbsenceStatusApproved, day, day).
+		Find(&absences).Error; err != nil {
+		return nil, err
+	}
+	for _, absence := range absences {
+		if unavailable[absence.UserID] == "" {
+			unavailable[absence.UserID] = "Abwesend"
+		}
+	}
+	return unavailable, nil
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamFairnessAndShiftCandidates(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	team := models.Team{Name: "Station 1"}
+	database.DB.Create(&team)
+	anna := models.User{Username: "anna", Email: "anna@example.com", Password: "x", AccountNumber: "F1", Name: "Anna", TimeZone: "UTC", WeeklyHours: 40, TeamID: &team.ID, IsActive: true}
+	ben := models.User{Username: "ben", Email: "ben@example.com", Password: "x", AccountNumber: "F2", Name: "Ben", TimeZone: "UTC", WeeklyHours: 40, TeamID: &team.ID, IsActive: true}
+	cem := models.User{Username: "cem", Email: "cem@example.com", Password: "x", AccountNumber: "F3", Name: "Cem", TimeZone: "UTC", WeeklyHours: 40, TeamID: &team.ID, IsActive: true}
+	database.DB.Create(&anna)
+	database.DB.Create(&ben)
+	database.DB.Create(&cem)
+
+	schedule := models.Schedule{Name: "März", StartDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&schedule)
+	at := func(day, hour int) time.Time { return time.Date(2024, 3, day, hour, 0, 0, 0, time.UTC) }
+	// Anna hatte zwei Wochenenddienste, Ben einen
+	database.DB.Create(&models.Shift{UserID: anna.ID, ScheduleID: schedule.ID, StartTime: at(2, 6), EndTime: at(2, 14)})
+	database.DB.Create(&models.Shift{UserID: anna.ID, ScheduleID: schedule.ID, StartTime: at(9, 6), EndTime: at(9, 14)})
+	database.DB.Create(&models.Shift{UserID: ben.ID, ScheduleID: schedule.ID, StartTime: at(16, 6), EndTime: at(16, 14)})
+	// Offene Wochenendschicht, vorerst Anna zugeordnet; Cem ist abwesend
+	open := models.Shift{UserID: anna.ID, ScheduleID: schedule.ID, StartTime: at(30, 6), EndTime: at(30, 14)}
+	database.DB.Create(&open)
+	database.DB.Create(&models.Absence{UserID: cem.ID, Type: models.AbsenceTypeVacation, Status: models.AbsenceStatusApproved, StartDate: at(30, 0), EndDate: at(30, 0)})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?from=2024-03-01T00:00:00Z&to=2024-03-30T00:00:00Z", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(team.ID)))
+	assert.NoError(t, GetTeamFairness(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var report models.TeamFairnessReport
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	if assert.Len(t, report.Users, 3) {
+		assert.Equal(t, "Anna", report.Users[0].UserName)
+		assert.Equal(t, 2.0, report.Users[0].Counts.Weekend)
+		assert.Greater(t, report.Users[0].Score, 0.0)
+		assert.Equal(t, "Cem", report.Users[2].UserName)
+		assert.Less(t, report.Users[2].Score, 0.0)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/?from=2024-03-01T00:00:00Z&to=2024-03-30T00:00:00Z", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(open.ID)))
+	assert.NoError(t, GetShiftCandidates(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var candidates models.ShiftCandidates
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &candidates))
+	assert.Equal(t, []string{models.FairnessWeekend}, candidates.Categories)
+	if assert.Len(t, candidates.Candidates, 3) {
+		// Ben ist der am wenigsten belastete verfügbare Kandidat
+		assert.Equal(t, "Ben", candidates.Candidates[0].UserName)
+		assert.True(t, candidates.Candidates[0].Available)
+		assert.False(t, candidates.Candidates[1].Available)
+		assert.False(t, candidates.Candidates[2].Available)
+	}
+}
@@ -2,6 +2,9 @@ package handlers
 
 import (
 	"net/http"
+	"time"
+
+	"schichtplaner/models"
 
 	"github.com/labstack/echo/v4"
 )
@@ -10,3 +13,12 @@ import (
 func HealthCheckHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, map[string]string{"status": "ok"})
 }
+
+// GetTimeZone gibt die Zeitzone der Organisation und ihren aktuellen UTC-Offset zurück
+func GetTimeZone(c echo.Context) error {
+	loc := models.OrganisationLocation()
+	return c.JSON(http.StatusOK, map[string]string{
+		"time_zone":  loc.String(),
+		"utc_offset": time.Now().In(loc).Format("-07:00"),
+	})
+}
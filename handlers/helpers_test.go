@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// pathParams enthält die Pfadparameter eines Testaufrufs; Werte werden mit fmt.Sprint formatiert
+type pathParams map[string]interface{}
+
+// handlerRequest beschreibt den Aufruf eines Handlers im Test
+type handlerRequest struct {
+	method string       // Standard: POST
+	target string       // Pfad mit Query-Parametern, Standard: /
+	params pathParams   // Pfadparameter
+	actor  *models.User // Angemeldeter Benutzer, nil ohne Anmeldung
+	body   string       // JSON-Body
+}
+
+// callHandler führt einen Handler mit der Anfrage aus und liefert den Recorder
+func callHandler(t *testing.T, handler echo.HandlerFunc, request handlerRequest) *httptest.ResponseRecorder {
+	if request.method == "" {
+		request.method = http.MethodPost
+	}
+	if request.target == "" {
+		request.target = "/"
+	}
+
+	req := httptest.NewRequest(request.method, request.target, bytes.NewBufferString(request.body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(req, rec)
+	if request.actor != nil {
+		c.Set(currentUserContextKey, request.actor)
+	}
+	if len(request.params) > 0 {
+		names := make([]string, 0, len(request.params))
+		values := make([]string, 0, len(request.params))
+		for name, value := range request.params {
+			names = append(names, name)
+			values = append(values, fmt.Sprint(value))
+		}
+		c.SetParamNames(names...)
+		c.SetParamValues(values...)
+	}
+	assert.NoError(t, handler(c))
+	return rec
+}
+
+// jsonBody kodiert value als JSON-Body für callHandler
+func jsonBody(t *testing.T, value interface{}) string {
+	payload, err := json.Marshal(value)
+	assert.NoError(t, err)
+	return string(payload)
+}
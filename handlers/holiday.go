@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+	"schichtplaner/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// GetHolidays gibt die gesetzlichen und betrieblichen Feiertage eines Jahres zurück.
+// Ohne state gilt das Bundesland der Organisation, ohne year das aktuelle Jahr.
+func GetHolidays(c echo.Context) error {
+	year := time.Now().In(models.OrganisationLocation()).Year()
+	if value := c.QueryParam("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1583 || parsed > 9999 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültiges Jahr",
+			})
+		}
+		year = parsed
+	}
+
+	state := models.OrganisationState()
+	if value := c.QueryParam("state"); value != "" {
+		state = value
+	}
+
+	calendar, err := loadHolidayCalendar(tenantDB(c), state)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Unbekanntes Bundesland",
+		})
+	}
+
+	return c.JSON(http.StatusOK, calendar.Holidays(year))
+}
+
+// GetCompanyHolidays gibt alle betrieblichen Feiertage zurück
+func GetCompanyHolidays(c echo.Context) error {
+	var holidays []models.CompanyHoliday
+	if err := tenantDB(c).Order("date ASC").Find(&holidays).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der betrieblichen Feiertage",
+		})
+	}
+
+	return c.JSON(http.StatusOK, holidays)
+}
+
+// CreateCompanyHoliday legt einen betrieblichen Feiertag an
+func CreateCompanyHoliday(c echo.Context) error {
+	var holiday models.CompanyHoliday
+	if err := c.Bind(&holiday); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Feiertagsdaten",
+		})
+	}
+
+	if message := validateCompanyHoliday(&holiday); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	if err := tenantDB(c).Create(&holiday).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen des betrieblichen Feiertags",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, holiday)
+}
+
+// UpdateCompanyHoliday aktualisiert einen betrieblichen Feiertag
+func UpdateCompanyHoliday(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Feiertags-ID",
+		})
+	}
+
+	var holiday models.CompanyHoliday
+	if err := tenantDB(c).First(&holiday, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Betrieblicher Feiertag nicht gefunden",
+		})
+	}
+
+	updateData := holiday
+	if err := c.Bind(&updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Feiertagsdaten",
+		})
+	}
+	updateData.Base = holiday.Base
+
+	if message := validateCompanyHoliday(&updateData); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	// Save statt Updates, damit auch recurring=false übernommen wird
+	if err := tenantDB(c).Save(&updateData).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Aktualisieren des betrieblichen Feiertags",
+		})
+	}
+
+	return c.JSON(http.StatusOK, updateData)
+}
+
+// DeleteCompanyHoliday löscht einen betrieblichen Feiertag
+func DeleteCompanyHoliday(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Feiertags-ID",
+		})
+	}
+
+	var holiday models.CompanyHoliday
+	if err := tenantDB(c).First(&holiday, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Betrieblicher Feiertag nicht gefunden",
+		})
+	}
+
+	if err := tenantDB(c).Delete(&holiday).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen des betrieblichen Feiertags",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Betrieblicher Feiertag erfolgreich gelöscht",
+	})
+}
+
+// validateCompanyHoliday prüft einen betrieblichen Feiertag und normalisiert das Datum auf den Kalendertag.
+// Liefert die erste Fehlermeldung oder einen leeren String.
+func validateCompanyHoliday(holiday *models.CompanyHoliday) string {
+	validator := utils.NewValidator()
+	validator.RequiredString("Name", holiday.Name, "Name ist ein Pflichtfeld")
+	validator.RequiredTime("Date", holiday.Date, "Datum ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return result.Errors[0]
+	}
+
+	if holiday.State != "" && !models.IsValidState(holiday.State) {
+		return "Unbekanntes Bundesland"
+	}
+
+	holiday.Date = calendarDay(holiday.Date)
+	return ""
+}
+
+// calendarDay liefert den Kalendertag von t in der Zeitzone der Organisation als 00:00 Uhr UTC
+func calendarDay(t time.Time) time.Time {
+	local := t.In(models.OrganisationLocation())
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// loadHolidayCalendar erstellt den Feiertagskalender eines Bundeslands inklusive betrieblicher Feiertage
+func loadHolidayCalendar(db *gorm.DB, state string) (*services.HolidayCalendar, error) {
+	var company []models.CompanyHoliday
+	if err := db.Find(&company).Error; err != nil {
+		return nil, err
+	}
+	return services.NewHolidayCalendar(state, company)
+}
+
+// markShiftHolidays kennzeichnet Schichten, die einen Feiertag berühren, in der Zeitzone des jeweiligen Benutzers
+func markShiftHolidays(calendar *services.HolidayCalendar, shifts []models.Shift) {
+	for i := range shifts {
+		if holiday, ok := calendar.HolidayDuring(shifts[i].StartTime, shifts[i].EndTime, shifts[i].Location()); ok {
+			shifts[i].Holiday = &holiday
+		}
+	}
+}
+
+// holidaysBetween liefert die Feiertage, deren Kalendertag im Zeitraum [from, to] in der Zeitzone loc liegt
+func holidaysBetween(calendar *services.HolidayCalendar, from, to time.Time, loc *time.Location) []models.Holiday {
+	fromLocal, toLocal := from.In(loc), to.In(loc)
+	first := time.Date(fromLocal.Year(), fromLocal.Month(), fromLocal.Day(), 0, 0, 0, 0, time.UTC)
+	last := time.Date(toLocal.Year(), toLocal.Month(), toLocal.Day(), 0, 0, 0, 0, time.UTC)
+
+	holidays := make([]models.Holiday, 0)
+	for year := first.Year(); year <= last.Year(); year++ {
+		for _, holiday := range calendar.Holidays(year) {
+			if !holiday.Date.Before(first) && !holiday.Date.After(last) {
+				holidays = append(holidays, holiday)
+			}
+		}
+	}
+	return holidays
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHolidays(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	database.DB.Create(&models.CompanyHoliday{Name: "Betriebsausflug", Date: time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC)})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/holidays?year=2024&state=BY", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, GetHolidays(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var holidays []models.Holiday
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &holidays))
+
+		names := make(map[string]models.Holiday)
+		for _, holiday := range holidays {
+			names[holiday.Date.Format("2006-01-02")] = holiday
+		}
+		assert.Equal(t, "Fronleichnam", names["2024-05-30"].Name)
+		assert.Equal(t, models.StateBY, names["2024-05-30"].State)
+		assert.Equal(t, "Ostermontag", names["2024-04-01"].Name)
+		assert.True(t, names["2024-06-14"].Company)
+	}
+}
+
+func TestGetHolidays_InvalidParameters(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	e := echo.New()
+	for _, query := range []string{"?state=XX", "?year=abc"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/holidays"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if assert.NoError(t, GetHolidays(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		}
+	}
+}
+
+func TestCreateCompanyHoliday(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	// 23:30 UTC ist in Berlin bereits der 24.12.
+	body, _ := json.Marshal(models.CompanyHoliday{Name: "Heiligabend", Date: time.Date(2024, 12, 23, 23, 30, 0, 0, time.UTC), Recurring: true})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/holidays/company", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, CreateCompanyHoliday(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var holiday models.CompanyHoliday
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &holiday))
+		assert.True(t, time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC).Equal(holiday.Date))
+		assert.True(t, holiday.Recurring)
+	}
+}
+
+func TestCreateCompanyHoliday_Invalid(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	e := echo.New()
+	for _, holiday := range []models.CompanyHoliday{
+		{Date: time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC)},
+		{Name: "Ohne Datum"},
+		{Name: "Falsches Land", Date: time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC), State: "XX"},
+	} {
+		body, _ := json.Marshal(holiday)
+		req := httptest.NewRequest(http.MethodPost, "/api/holidays/company", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if assert.NoError(t, CreateCompanyHoliday(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code, holiday.Name)
+		}
+	}
+
+	var count int64
+	database.DB.Model(&models.CompanyHoliday{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestGetSchedule_MarksHolidayShifts(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	loc := models.OrganisationLocation()
+	user := models.User{Username: "feiertag", Email: "feiertag@example.com", Password: "hashedpassword", Name: "Feiertag User"}
+	database.DB.Create(&user)
+
+	schedule := models.Schedule{
+		Name:      "Jahreswechsel",
+		StartDate: time.Date(2024, 12, 30, 0, 0, 0, 0, loc),
+		EndDate:   time.Date(2025, 1, 2, 0, 0, 0, 0, loc),
+		IsActive:  true,
+	}
+	database.DB.Create(&schedule)
+
+	regular := models.Shift{UserID: user.ID, ScheduleID: schedule.ID,
+		StartTime: time.Date(2024, 12, 30, 6, 0, 0, 0, loc), EndTime: time.Date(2024, 12, 30, 14, 0, 0, 0, loc)}
+	night := models.Shift{UserID: user.ID, ScheduleID: schedule.ID,
+		StartTime: time.Date(2024, 12, 31, 22, 0, 0, 0, loc), EndTime: time.Date(2025, 1, 1, 6, 0, 0, 0, loc)}
+	database.DB.Create(&regular)
+	database.DB.Create(&night)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(schedule.ID)))
+
+	if assert.NoError(t, GetSchedule(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var result models.Schedule
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.Len(t, result.Holidays, 1)
+		assert.Equal(t, "Neujahr", result.Holidays[0].Name)
+
+		holidays := make(map[uint]*models.Holiday)
+		for _, shift := range result.Shifts {
+			holidays[shift.ID] = shift.Holiday
+		}
+		assert.Nil(t, holidays[regular.ID])
+		if assert.NotNil(t, holidays[night.ID]) {
+			assert.Equal(t, "Neujahr", holidays[night.ID].Name)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+	"schichtplaner/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// GetLocations gibt alle Standorte zurück, sortiert nach SortOrder und Name
+func GetLocations(c echo.Context) error {
+	var locations []models.Location
+	if err := tenantDB(c).Order("sort_order ASC, name ASC").Find(&locations).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Standorte",
+		})
+	}
+
+	return c.JSON(http.StatusOK, locations)
+}
+
+// GetLocation gibt einen spezifischen Standort zurück
+func GetLocation(c echo.Context) error {
+	location, err := loadLocationFromParam(c)
+	if err != nil || location == nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, location)
+}
+
+// CreateLocation legt einen neuen Standort an
+func CreateLocation(c echo.Context) error {
+	location := models.Location{Country: "DE", IsActive: true}
+	if err := c.Bind(&location); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Standortdaten",
+		})
+	}
+	location.ID = 0
+
+	if message := validateLocation(tenantDB(c), &location); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	if err := tenantDB(c).Create(&location).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen des Standorts",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, location)
+}
+
+// UpdateLocation aktualisiert einen Standort
+func UpdateLocation(c echo.Context) error {
+	location, err := loadLocationFromParam(c)
+	if err != nil || location == nil {
+		return err
+	}
+
+	updateData := *location
+	if err := c.Bind(&updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Standortdaten",
+		})
+	}
+	updateData.Base = location.Base
+
+	if message := validateLocation(tenantDB(c), &updateData); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	// Save statt Updates, damit auch entfernte Koordinaten und is_active=false übernommen werden
+	if err := tenantDB(c).Save(&updateData).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Aktualisieren des Standorts",
+		})
+	}
+
+	return c.JSON(http.StatusOK, updateData)
+}
+
+// DeleteLocation löscht einen Standort, sofern ihm keine Teams, Schichtpläne oder Schichten zugeordnet sind
+func DeleteLocation(c echo.Context) error {
+	location, err := loadLocationFromParam(c)
+	if err != nil || location == nil {
+		return err
+	}
+
+	var teams, schedules, shifts int64
+	tenantDB(c).Model(&models.Team{}).Where("location_id = ?", location.ID).Count(&teams)
+	tenantDB(c).Model(&models.Schedule{}).Where("location_id = ?", location.ID).Count(&schedules)
+	tenantDB(c).Model(&models.Shift{}).Where("location_id = ?", location.ID).Count(&shifts)
+	if teams+schedules+shifts > 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Standort kann nicht gelöscht werden, da ihm noch Teams, Schichtpläne oder Schichten zugeordnet sind",
+		})
+	}
+
+	if err := tenantDB(c).Delete(location).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen des Standorts",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Standort erfolgreich gelöscht",
+	})
+}
+
+// GetLocationStaffing liefert die Besetzung eines Standorts je Kalendertag im Zeitraum [from, to)
+// (RFC3339, Standard: die nächsten 7 Tage ab heute in der Zeitzone des Standorts)
+func GetLocationStaffing(c echo.Context) error {
+	location, err := loadLocationFromParam(c)
+	if err != nil || location == nil {
+		return err
+	}
+
+	loc := location.TimeLocation()
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	to := from.AddDate(0, 0, 7)
+	from, to, message := periodFromQuery(c, from, to)
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	var shifts []models.Shift
+	if err := filterShiftsByLocation(tenantDB(c).Preload("ShiftType"), location.ID).
+		Where("start_time >= ? AND start_time < ?", from, to).
+		Order("start_time ASC").Find(&shifts).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Schichten",
+		})
+	}
+
+	return c.JSON(http.StatusOK, services.LocationStaffing(*location, shifts, loc, true))
+}
+
+// GetLocationReport fasst die Schichten je Standort für einen Monat (month=YYYY-MM, Standard: aktueller Monat) zusammen.
+// Schichten ohne Standort erscheinen unter location_id 0.
+func GetLocationReport(c echo.Context) error {
+	orgLoc := models.OrganisationLocation()
+	now := time.Now().In(orgLoc)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, orgLoc)
+	if value := c.QueryParam("month"); value != "" {
+		parsed, err := models.ParseMonth(value, orgLoc)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültiger Monat (month), erwartet YYYY-MM",
+			})
+		}
+		monthStart = parsed
+	}
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	var locations []models.Location
+	if err := tenantDB(c).Order("sort_order ASC, name ASC").Find(&locations).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Standorte",
+		})
+	}
+
+	var shifts []models.Shift
+	if err := tenantDB(c).Preload("Schedule").
+		Where("start_time >= ? AND start_time < ?", monthStart, monthEnd).
+		Order("start_time ASC").Find(&shifts).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Schichten",
+		})
+	}
+
+	byLocation := make(map[uint][]models.Shift)
+	for _, shift := range shifts {
+		id := services.EffectiveLocationID(shift)
+		byLocation[id] = append(byLocation[id], shift)
+	}
+
+	reports := make([]models.LocationReport, 0, len(locations)+1)
+	for _, location := range locations {
+		reports = append(reports, services.LocationStaffing(location, byLocation[location.ID], location.TimeLocation(), false))
+	}
+	if unassigned := byLocation[0]; len(unassigned) > 0 {
+		reports = append(reports, services.LocationStaffing(models.Location{Name: "Ohne Standort"}, unassigned, orgLoc, false))
+	}
+
+	return c.JSON(http.StatusOK, reports)
+}
+
+// filterShiftsByLocation beschränkt eine Schichtabfrage auf einen Standort; Schichten ohne eigenen Standort
+// gehören zum Standort ihres Schichtplans
+func filterShiftsByLocation(query *gorm.DB, locationID uint) *gorm.DB {
+	schedules := query.Session(&gorm.Session{NewDB: true}).Model(&models.Schedule{}).Select("id").Where("location_id = ?", locationID)
+	return query.Where("shifts.location_id = ? OR (shifts.location_id IS NULL AND shifts.schedule_id IN (?))", locationID, schedules)
+}
+
+// locationIDFromQuery liest den optionalen Filter location_id; 0 = kein Filter.
+// Liefert bei ungültigen Werten eine Fehlermeldung.
+func locationIDFromQuery(c echo.Context) (uint, string) {
+	value := c.QueryParam("location_id")
+	if value == "" {
+		return 0, ""
+	}
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || id == 0 {
+		return 0, "Ungültige Standort-ID"
+	}
+	return uint(id), ""
+}
+
+// periodFromQuery liest den Zeitraum aus from und to (RFC3339); fehlende Angaben werden durch die Standardwerte ersetzt.
+// Liefert bei ungültigen Werten eine Fehlermeldung.
+func periodFromQuery(c echo.Context, from, to time.Time) (time.Time, time.Time, string) {
+	var err error
+	if value := c.QueryParam("from"); value != "" {
+		if from, err = time.Parse(time.RFC3339, value); err != nil {
+			return from, to, "Ungültiges Startdatum (from), erwartet RFC3339"
+		}
+	}
+	if value := c.QueryParam("to"); value != "" {
+		if to, err = time.Parse(time.RFC3339, value); err != nil {
+			return from, to, "Ungültiges Enddatum (to), erwartet RFC3339"
+		}
+	}
+	if !to.After(from) {
+		return from, to, "Enddatum muss nach dem Startdatum liegen"
+	}
+	return from, to, ""
+}
+
+// validateLocationReference prüft, ob ein optional referenzierter Standort existiert.
+// Liefert eine Fehlermeldung oder einen leeren String.
+func validateLocationReference(db *gorm.DB, locationID *uint) string {
+	if locationID == nil {
+		return ""
+	}
+	var count int64
+	db.Model(&models.Location{}).Where("id = ?", *locationID).Count(&count)
+	if count == 0 {
+		return "Standort nicht gefunden"
+	}
+	return ""
+}
+
+// validateLocation prüft einen Standort und liefert die erste Fehlermeldung oder einen leeren String
+func validateLocation(db *gorm.DB, location *models.Location) string {
+	validator := utils.NewValidator()
+	validator.RequiredString("Name", location.Name, "Name ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return result.Errors[0]
+	}
+
+	if err := services.ValidateLocation(*location); err != nil {
+		return err.Error()
+	}
+
+	var duplicates int64
+	db.Model(&models.Location{}).Where("name = ? AND id <> ?", location.Name, location.ID).Count(&duplicates)
+	if duplicates > 0 {
+		return "Standort mit diesem Namen existiert bereits"
+	}
+	return ""
+}
+
+// loadLocationFromParam lädt den Standort aus dem Pfadparameter id; bei Fehlern wird direkt geantwortet und nil geliefert
+func loadLocationFromParam(c echo.Context) (*models.Location, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Standort-ID",
+		})
+	}
+
+	var location models.Location
+	if err := tenantDB(c).First(&location, id).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Standort nicht gefunden",
+		})
+	}
+	return &location, nil
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+	"schichtplaner/utils"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateLocation_Validation(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	post := func(body string) int {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		assert.NoError(t, CreateLocation(e.NewContext(req, rec)))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusCreated, post(`{"name":"Werk Nord","city":"Hamburg","time_zone":"Europe/Berlin","latitude":53.55,"longitude":9.99}`))
+	assert.Equal(t, http.StatusBadRequest, post(`{"name":"Werk Nord"}`))
+	assert.Equal(t, http.StatusBadRequest, post(`{"name":"Werk Süd","time_zone":"Europe/Nirgendwo"}`))
+	assert.Equal(t, http.StatusBadRequest, post(`{"name":"Werk West","latitude":51.2}`))
+	assert.Equal(t, http.StatusBadRequest, post(`{"city":"Köln"}`))
+
+	var location models.Location
+	database.DB.First(&location)
+	assert.Equal(t, "DE", location.Country)
+	assert.True(t, location.IsActive)
+}
+
+func TestGetShifts_FilterByLocation(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	north := models.Location{Name: "Nord"}
+	south := models.Location{Name: "Süd"}
+	database.DB.Create(&north)
+	database.DB.Create(&south)
+	user := models.User{Username: "standort", Email: "standort@example.com", Password: "hashedpassword", Name: "Standort User"}
+	database.DB.Create(&user)
+	schedule := models.Schedule{Name: "Nord Juni", LocationID: &north.ID, StartDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&schedule)
+
+	start := time.Date(2024, 6, 10, 6, 0, 0, 0, time.UTC)
+	// Erbt den Standort des Plans
+	database.DB.Create(&models.Shift{UserID: user.ID, ScheduleID: schedule.ID, StartTime: start, EndTime: start.Add(8 * time.Hour)})
+	// Aushilfe am anderen Standort
+	database.DB.Create(&models.Shift{UserID: user.ID, ScheduleID: schedule.ID, LocationID: &south.ID, StartTime: start.AddDate(0, 0, 1), EndTime: start.AddDate(0, 0, 1).Add(8 * time.Hour)})
+
+	count := func(locationID uint) int {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/?location_id="+strconv.Itoa(int(locationID)), nil)
+		rec := httptest.NewRecorder()
+		assert.NoError(t, GetShifts(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response utils.PaginatedResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response.Pagination.Total
+	}
+	assert.Equal(t, 1, count(north.ID))
+	assert.Equal(t, 1, count(south.ID))
+
+	// Standortfilter für Schichtpläne
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?location_id="+strconv.Itoa(int(south.ID)), nil)
+	rec := httptest.NewRecorder()
+	assert.NoError(t, GetSchedules(e.NewContext(req, rec)))
+	var response utils.PaginatedResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, 0, response.Pagination.Total)
+
+	// Ungültiger Filter
+	req = httptest.NewRequest(http.MethodGet, "/?location_id=abc", nil)
+	rec = httptest.NewRecorder()
+	assert.NoError(t, GetTeams(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Standort mit Zuordnungen kann nicht gelöscht werden
+	req = httptest.NewRequest(http.MethodDelete, "/", nil)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(north.ID)))
+	assert.NoError(t, DeleteLocation(c))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGetLocationStaffing(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	site := models.Location{Name: "Werk", TimeZone: "Europe/Berlin"}
+	database.DB.Create(&site)
+	alice := models.User{Username: "alice", Email: "alice@example.com", Password: "hashedpassword", Name: "Alice"}
+	bob := models.User{Username: "bob", Email: "bob@example.com", Password: "hashedpassword", Name: "Bob"}
+	database.DB.Create(&alice)
+	database.DB.Create(&bob)
+	schedule := models.Schedule{Name: "Plan", LocationID: &site.ID, StartDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&schedule)
+	other := models.Schedule{Name: "Ohne Standort", StartDate: schedule.StartDate, EndDate: schedule.EndDate}
+	database.DB.Create(&other)
+
+	start := time.Date(2024, 6, 10, 4, 0, 0, 0, time.UTC)
+	database.DB.Create(&models.Shift{UserID: alice.ID, ScheduleID: schedule.ID, StartTime: start, EndTime: start.Add(8 * time.Hour)})
+	database.DB.Create(&models.Shift{UserID: bob.ID, ScheduleID: schedule.ID, StartTime: start, EndTime: start.Add(8 * time.Hour)})
+	database.DB.Create(&models.Shift{UserID: bob.ID, ScheduleID: other.ID, StartTime: start.AddDate(0, 0, 1), EndTime: start.AddDate(0, 0, 1).Add(8 * time.Hour)})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?from=2024-06-10T00:00:00%2B02:00&to=2024-06-17T00:00:00%2B02:00", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(site.ID)))
+
+	if assert.NoError(t, GetLocationStaffing(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var report models.LocationReport
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Equal(t, 2, report.Shifts)
+		if assert.Len(t, report.Days, 1) {
+			assert.Equal(t, 2, report.Days[0].Users)
+			assert.Equal(t, 16.0, report.Days[0].PlannedHours)
+		}
+	}
+
+	// Monatsbericht je Standort inklusive Schichten ohne Standort
+	req = httptest.NewRequest(http.MethodGet, "/?month=2024-06", nil)
+	rec = httptest.NewRecorder()
+	if assert.NoError(t, GetLocationReport(e.NewContext(req, rec))) {
+		var reports []models.LocationReport
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &reports))
+		if assert.Len(t, reports, 2) {
+			assert.Equal(t, site.ID, reports[0].LocationID)
+			assert.Equal(t, 2, reports[0].Shifts)
+			assert.Equal(t, uint(0), reports[1].LocationID)
+			assert.Equal(t, 1, reports[1].Shifts)
+		}
+	}
+}
@@ -0,0 +1,366 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+	"schichtplaner/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// callOutRequest sind die Angaben eines Einsatzes in der Rufbereitschaft
+type callOutRequest struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Note  string    `json:"note"`
+}
+
+// GetOnCallDuties gibt die Rufbereitschaften im Zeitraum [from, to) zurück (RFC3339, Standard: aktueller Monat);
+// optional gefiltert nach user_id und team_id
+func GetOnCallDuties(c echo.Context) error {
+	now := time.Now()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	from, to, message := periodFromQuery(c, monthStart, monthStart.AddDate(0, 1, 0))
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	query := tenantDB(c).Where("start_time < ? AND end_time > ?", to, from)
+	for _, filter := range []struct{ param, column, message string }{
+		{"user_id", "user_id", "Ungültige Benutzer-ID"},
+		{"team_id", "team_id", "Ungültige Team-ID"},
+	} {
+		value := c.QueryParam(filter.param)
+		if value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": filter.message,
+			})
+		}
+		query = query.Where(filter.column+" = ?", id)
+	}
+
+	var duties []models.OnCallDuty
+	if err := query.Preload("User").Preload("CallOuts", func(db *gorm.DB) *gorm.DB {
+		return db.Order("clock_in ASC")
+	}).Order("start_time ASC").Find(&duties).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Rufbereitschaften",
+		})
+	}
+
+	return c.JSON(http.StatusOK, duties)
+}
+
+// GetCurrentOnCall gibt die aktuell laufenden Rufbereitschaften zurück ("Wer hat jetzt Rufbereitschaft?");
+// optional gefiltert nach team_id
+func GetCurrentOnCall(c echo.Context) error {
+	now := time.Now()
+	query := tenantDB(c).Where("start_time <= ? AND end_time > ?", now, now)
+	if value := c.QueryParam("team_id"); value != "" {
+		teamID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültige Team-ID",
+			})
+		}
+		query = query.Where("team_id = ?", teamID)
+	}
+
+	var duties []models.OnCallDuty
+	if err := query.Preload("User").Preload("CallOuts").Order("start_time ASC").Find(&duties).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Rufbereitschaften",
+		})
+	}
+
+	return c.JSON(http.StatusOK, duties)
+}
+
+// GetOnCallDuty gibt eine Rufbereitschaft mit ihren Einsätzen zurück
+func GetOnCallDuty(c echo.Context) error {
+	duty, err := loadOnCallDutyFromParam(c)
+	if err != nil || duty == nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, duty)
+}
+
+// CreateOnCallDuty teilt einem Benutzer eine Rufbereitschaft zu
+func CreateOnCallDuty(c echo.Context) error {
+	var duty models.OnCallDuty
+	if err := c.Bind(&duty); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Rufbereitschaftsdaten",
+		})
+	}
+	duty.ID = 0
+	duty.CallOuts = nil
+
+	if message := validateOnCallDuty(tenantDB(c), &duty); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	if err := tenantDB(c).Omit("User", "CallOuts").Create(&duty).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen der Rufbereitschaft",
+		})
+	}
+
+	created, _ := loadOnCallDuty(tenantDB(c), duty.ID)
+	return c.JSON(http.StatusCreated, created)
+}
+
+// UpdateOnCallDuty aktualisiert eine Rufbereitschaft; bereits erfasste Einsätze müssen im neuen Zeitraum liegen
+func UpdateOnCallDuty(c echo.Context) error {
+	duty, err := loadOnCallDutyFromParam(c)
+	if err != nil || duty == nil {
+		return err
+	}
+
+	updateData := *duty
+	updateData.User = models.User{}
+	updateData.CallOuts = nil
+	if err := c.Bind(&updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Rufbereitschaftsdaten",
+		})
+	}
+	updateData.Base = duty.Base
+	updateData.CallOuts = duty.CallOuts
+
+	if len(duty.CallOuts) > 0 && updateData.UserID != duty.UserID {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Rufbereitschaft mit Einsätzen kann keinem anderen Benutzer zugeteilt werden",
+		})
+	}
+	if message := validateOnCallDuty(tenantDB(c), &updateData); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	for _, callOut := range duty.CallOuts {
+		if callOut.ClockOut == nil || !updateData.Covers(callOut.ClockIn, *callOut.ClockOut) {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Erfasste Einsätze müssen innerhalb der Rufbereitschaft liegen",
+			})
+		}
+	}
+
+	if err := tenantDB(c).Omit("User", "CallOuts").Save(&updateData).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Aktualisieren der Rufbereitschaft",
+		})
+	}
+
+	updated, _ := loadOnCallDuty(tenantDB(c), updateData.ID)
+	return c.JSON(http.StatusOK, updated)
+}
+
+// DeleteOnCallDuty löscht eine Rufbereitschaft ohne erfasste Einsätze
+func DeleteOnCallDuty(c echo.Context) error {
+	duty, err := loadOnCallDutyFromParam(c)
+	if err != nil || duty == nil {
+		return err
+	}
+
+	if len(duty.CallOuts) > 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Rufbereitschaft mit erfassten Einsätzen kann nicht gelöscht werden",
+		})
+	}
+
+	if err := tenantDB(c).Delete(duty).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen der Rufbereitschaft",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Rufbereitschaft erfolgreich gelöscht",
+	})
+}
+
+// CreateCallOut erfasst einen Einsatz während der Rufbereitschaft als Zeitbuchung. Der Einsatz zählt als Arbeitszeit;
+// die Antwort enthält Hinweise, wenn bis zur nächsten Schicht keine volle Ruhezeit mehr bleibt.
+func CreateCallOut(c echo.Context) error {
+	duty, err := loadOnCallDutyFromParam(c)
+	if err != nil || duty == nil {
+		return err
+	}
+	if !canManageUser(c, duty.UserID) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Keine Berechtigung für die Zeiterfassung dieses Benutzers",
+		})
+	}
+
+	var request callOutRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Einsatzdaten",
+		})
+	}
+
+	validator := utils.NewValidator()
+	validator.RequiredTime("Start", request.Start, "Beginn ist ein Pflichtfeld")
+	validator.RequiredTime("End", request.End, "Ende ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+	if !request.End.After(request.Start) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ende muss nach dem Beginn liegen",
+		})
+	}
+	if request.End.After(time.Now()) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Einsätze können erst nach ihrem Ende erfasst werden",
+		})
+	}
+	if !duty.Covers(request.Start, request.End) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Einsatz muss innerhalb der Rufbereitschaft liegen",
+		})
+	}
+	if message := timesheetLockMessage(tenantDB(c), duty.UserID, request.Start); message != "" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": message,
+		})
+	}
+
+	end := request.End
+	entry := models.TimeEntry{
+		UserID:       duty.UserID,
+		OnCallDutyID: &duty.ID,
+		ClockIn:      request.Start,
+		ClockOut:     &end,
+		Source:       models.TimeEntrySourceCallOut,
+		Note:         strings.TrimSpace(request.Note),
+	}
+
+	message := ""
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		if timeEntryOverlaps(tx, entry) {
+			message = "Einsatz überschneidet sich mit einer bestehenden Zeitbuchung"
+			return gorm.ErrInvalidData
+		}
+		return tx.Create(&entry).Error
+	})
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erfassen des Einsatzes",
+		})
+	}
+
+	updated, err := loadOnCallDuty(tenantDB(c), duty.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Rufbereitschaft",
+		})
+	}
+	updated.Warnings = onCallRestWarnings(tenantDB(c), *updated)
+	return c.JSON(http.StatusCreated, updated)
+}
+
+// validateOnCallDuty prüft eine Rufbereitschaft. Sie darf sich nicht mit Schichten oder anderen Rufbereitschaften
+// des Benutzers überschneiden; Ruhe- und Schlafzeiten dürfen dagegen in der Bereitschaft liegen.
+// Liefert die erste Fehlermeldung oder einen leeren String.
+func validateOnCallDuty(db *gorm.DB, duty *models.OnCallDuty) string {
+	validator := utils.NewValidator()
+	validator.RequiredUint("UserID", duty.UserID, "Benutzer ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return result.Errors[0]
+	}
+	if err := services.ValidateOnCallDuty(*duty); err != nil {
+		return err.Error()
+	}
+	duty.Note = strings.TrimSpace(duty.Note)
+
+	var user models.User
+	if err := db.First(&user, duty.UserID).Error; err != nil {
+		return "Benutzer nicht gefunden"
+	}
+	if duty.TeamID != nil {
+		var count int64
+		db.Model(&models.Team{}).Where("id = ?", *duty.TeamID).Count(&count)
+		if count == 0 {
+			return "Team nicht gefunden"
+		}
+	}
+
+	var overlapping int64
+	db.Model(&models.OnCallDuty{}).
+		Where("user_id = ? AND id <> ? AND start_time < ? AND end_time > ?", duty.UserID, duty.ID, duty.EndTime, duty.StartTime).
+		Count(&overlapping)
+	if overlapping > 0 {
+		return "Rufbereitschaft überschneidet sich mit einer bestehenden Rufbereitschaft"
+	}
+	db.Model(&models.Shift{}).
+		Where("user_id = ? AND start_time < ? AND end_time > ?", duty.UserID, duty.EndTime, duty.StartTime).
+		Count(&overlapping)
+	if overlapping > 0 {
+		return "Rufbereitschaft überschneidet sich mit einer Schicht des Benutzers"
+	}
+	return ""
+}
+
+// onCallRestWarnings prüft die Ruhezeit zwischen den Einsätzen einer Rufbereitschaft und der jeweils nächsten Schicht
+func onCallRestWarnings(db *gorm.DB, duty models.OnCallDuty) []models.ComplianceViolation {
+	config := services.DefaultArbZGConfig()
+	config.Location = duty.User.Location()
+
+	var shifts []models.Shift
+	db.Where("user_id = ? AND start_time >= ? AND start_time < ?", duty.UserID, duty.StartTime, duty.EndTime.Add(config.MinRest)).
+		Order("start_time ASC").Find(&shifts)
+	return services.OnCallRestViolations(config, duty.UserID, shifts, duty.CallOuts)
+}
+
+// loadOnCallDuty lädt eine Rufbereitschaft mit Benutzer und Einsätzen
+func loadOnCallDuty(db *gorm.DB, id uint) (*models.OnCallDuty, error) {
+	var duty models.OnCallDuty
+	if err := db.Preload("User").Preload("CallOuts", func(db *gorm.DB) *gorm.DB {
+		return db.Order("clock_in ASC")
+	}).First(&duty, id).Error; err != nil {
+		return nil, err
+	}
+	return &duty, nil
+}
+
+// loadOnCallDutyFromParam lädt die Rufbereitschaft aus dem Pfadparameter id; bei Fehlern wird direkt geantwortet und nil geliefert
+func loadOnCallDutyFromParam(c echo.Context) (*models.OnCallDuty, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Rufbereitschafts-ID",
+		})
+	}
+
+	duty, err := loadOnCallDuty(tenantDB(c), uint(id))
+	if err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Rufbereitschaft nicht gefunden",
+		})
+	}
+	return duty, nil
+}
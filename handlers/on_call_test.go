@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+	"schichtplaner/services"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnCallDutyAndCallOuts(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "bereit", Email: "bereit@example.com", Password: "x", Name: "Bereit User", IsActive: true}
+	database.DB.Create(&user)
+	userID := strconv.Itoa(int(user.ID))
+
+	schedule := models.Schedule{Name: "März", StartDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&schedule)
+	shift := models.Shift{UserID: user.ID, ScheduleID: schedule.ID, StartTime: time.Date(2024, 3, 5, 6, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)}
+	database.DB.Create(&shift)
+
+	// Überschneidung mit einer Schicht wird abgelehnt, die Nacht davor ist erlaubt
+	rec := callHandler(t, CreateOnCallDuty, handlerRequest{body: `{"user_id":` + userID + `,"start_time":"2024-03-04T18:00:00Z","end_time":"2024-03-05T07:00:00Z"}`})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callHandler(t, CreateOnCallDuty, handlerRequest{body: `{"user_id":` + userID + `,"start_time":"2024-03-04T18:00:00Z","end_time":"2024-03-05T06:00:00Z","allowance":30}`})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var duty models.OnCallDuty
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &duty))
+	assert.Contains(t, rec.Body.String(), `"standby_minutes":720`)
+
+	// Zweite Rufbereitschaft im selben Zeitraum
+	rec = callHandler(t, CreateOnCallDuty, handlerRequest{body: `{"user_id":` + userID + `,"start_time":"2024-03-04T22:00:00Z","end_time":"2024-03-05T02:00:00Z"}`})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Einsätze außerhalb der Bereitschaft werden abgelehnt
+	rec = callHandler(t, CreateCallOut, handlerRequest{params: pathParams{"id": duty.ID}, body: `{"start":"2024-03-04T17:00:00Z","end":"2024-03-04T19:00:00Z"}`})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Nächtlicher Einsatz zählt als Arbeitszeit und unterbricht die Ruhezeit vor der Frühschicht
+	rec = callHandler(t, CreateCallOut, handlerRequest{params: pathParams{"id": duty.ID}, body: `{"start":"2024-03-05T01:00:00Z","end":"2024-03-05T02:30:00Z","note":"Störung Server"}`})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"call_out_minutes":90`)
+	assert.Contains(t, rec.Body.String(), services.RuleOnCallRest)
+
+	var entry models.TimeEntry
+	assert.NoError(t, database.DB.Where("on_call_duty_id = ?", duty.ID).First(&entry).Error)
+	assert.Equal(t, models.TimeEntrySourceCallOut, entry.Source)
+
+	violations, err := userComplianceViolations(database.DB, user.ID, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	found := false
+	for _, violation := range violations {
+		found = found || violation.Rule == services.RuleOnCallRest
+	}
+	assert.True(t, found)
+
+	// Überschneidende Einsätze und Löschen mit Einsätzen werden abgelehnt
+	rec = callHandler(t, CreateCallOut, handlerRequest{params: pathParams{"id": duty.ID}, body: `{"start":"2024-03-05T02:00:00Z","end":"2024-03-05T03:00:00Z"}`})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, http.StatusBadRequest, callHandler(t, DeleteOnCallDuty, handlerRequest{params: pathParams{"id": duty.ID}}).Code)
+}
+
+func TestGetCurrentOnCall(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	team := models.Team{Name: "IT"}
+	database.DB.Create(&team)
+	user := models.User{Username: "jetzt", Email: "jetzt@example.com", Password: "x", Name: "Jetzt User", IsActive: true}
+	database.DB.Create(&user)
+
+	now := time.Now()
+	database.DB.Create(&models.OnCallDuty{UserID: user.ID, TeamID: &team.ID, StartTime: now.Add(-time.Hour), EndTime: now.Add(time.Hour)})
+	database.DB.Create(&models.OnCallDuty{UserID: user.ID, TeamID: &team.ID, StartTime: now.Add(2 * time.Hour), EndTime: now.Add(4 * time.Hour)})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?team_id="+strconv.Itoa(int(team.ID)), nil)
+	rec := httptest.NewRecorder()
+	assert.NoError(t, GetCurrentOnCall(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var duties []models.OnCallDuty
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &duties))
+	if assert.Len(t, duties, 1) {
+		assert.Equal(t, "Jetzt User", duties[0].User.Name)
+	}
+}
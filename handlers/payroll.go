@@ -0,0 +1,474 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// payrollForbiddenMessage ist die Fehlermeldung für Benutzer ohne Zugriff auf die Lohnabrechnung
+const payrollForbiddenMessage = "Nur Administratoren dürfen die Lohnabrechnung bearbeiten"
+
+// GetWageTypeMappings gibt alle Lohnarten-Zuordnungen zurück
+func GetWageTypeMappings(c echo.Context) error {
+	if !canManagePayroll(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": payrollForbiddenMessage,
+		})
+	}
+
+	var mappings []models.WageTypeMapping
+	if err := tenantDB(c).Order("category ASC, key ASC").Find(&mappings).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Lohnarten",
+		})
+	}
+
+	return c.JSON(http.StatusOK, mappings)
+}
+
+// CreateWageTypeMapping legt eine Lohnarten-Zuordnung an
+func CreateWageTypeMapping(c echo.Context) error {
+	if !canManagePayroll(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": payrollForbiddenMessage,
+		})
+	}
+
+	var mapping models.WageTypeMapping
+	if err := c.Bind(&mapping); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Lohnartendaten",
+		})
+	}
+	mapping.ID = 0
+
+	if message := validateWageTypeMapping(tenantDB(c), &mapping); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	if err := tenantDB(c).Create(&mapping).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen der Lohnart",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, mapping)
+}
+
+// UpdateWageTypeMapping aktualisiert eine Lohnarten-Zuordnung
+func UpdateWageTypeMapping(c echo.Context) error {
+	mapping, err := loadWageTypeMappingFromParam(c)
+	if err != nil || mapping == nil {
+		return err
+	}
+
+	updateData := *mapping
+	if err := c.Bind(&updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Lohnartendaten",
+		})
+	}
+	updateData.Base = mapping.Base
+
+	if message := validateWageTypeMapping(tenantDB(c), &updateData); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	if err := tenantDB(c).Save(&updateData).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Aktualisieren der Lohnart",
+		})
+	}
+
+	return c.JSON(http.StatusOK, updateData)
+}
+
+// DeleteWageTypeMapping löscht eine Lohnarten-Zuordnung
+func DeleteWageTypeMapping(c echo.Context) error {
+	mapping, err := loadWageTypeMappingFromParam(c)
+	if err != nil || mapping == nil {
+		return err
+	}
+
+	// Endgültig löschen, damit der eindeutige Index eine neue Zuordnung für Kategorie und Schlüssel zulässt
+	if err := tenantDB(c).Unscoped().Delete(mapping).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen der Lohnart",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Lohnart erfolgreich gelöscht",
+	})
+}
+
+// GetPayrollSettings gibt Berater- und Mandantennummer für DATEV zurück
+func GetPayrollSettings(c echo.Context) error {
+	if !canManagePayroll(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": payrollForbiddenMessage,
+		})
+	}
+
+	settings, err := loadPayrollSettings(tenantDB(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der DATEV-Einstellungen",
+		})
+	}
+
+	return c.JSON(http.StatusOK, settings)
+}
+
+// UpdatePayrollSettings speichert Berater- und Mandantennummer für DATEV
+func UpdatePayrollSettings(c echo.Context) error {
+	if !canManagePayroll(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": payrollForbiddenMessage,
+		})
+	}
+
+	settings, err := loadPayrollSettings(tenantDB(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der DATEV-Einstellungen",
+		})
+	}
+
+	updateData := *settings
+	if err := c.Bind(&updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige DATEV-Einstellungen",
+		})
+	}
+	updateData.Base = settings.Base
+	updateData.ConsultantNumber = strings.TrimSpace(updateData.ConsultantNumber)
+	updateData.ClientNumber = strings.TrimSpace(updateData.ClientNumber)
+
+	if err := services.ValidatePayrollSettings(updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	if err := tenantDB(c).Save(&updateData).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Speichern der DATEV-Einstellungen",
+		})
+	}
+
+	return c.JSON(http.StatusOK, updateData)
+}
+
+// GetPayrollPreview zeigt die zu übergebenden Werte eines Abrechnungsmonats (month=YYYY-MM) mit Prüfhinweisen,
+// bisherigen Exporten und – ohne blockierende Hinweise – der LODAS-Importdatei
+func GetPayrollPreview(c echo.Context) error {
+	if !canManagePayroll(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": payrollForbiddenMessage,
+		})
+	}
+
+	month := c.QueryParam("month")
+	if _, err := models.ParseMonth(month, time.UTC); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültiger Monat (month), erwartet YYYY-MM",
+		})
+	}
+
+	preview, err := buildPayrollPreview(tenantDB(c), month)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Berechnen der Lohndaten",
+		})
+	}
+
+	return c.JSON(http.StatusOK, preview)
+}
+
+// GetPayrollExports gibt die bisherigen Exporte zurück, optional gefiltert nach month (YYYY-MM)
+func GetPayrollExports(c echo.Context) error {
+	if !canManagePayroll(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": payrollForbiddenMessage,
+		})
+	}
+
+	query := tenantDB(c).Order("created_at DESC, id DESC")
+	if month := c.QueryParam("month"); month != "" {
+		query = query.Where("month = ?", month)
+	}
+
+	var exports []models.PayrollExport
+	if err := query.Find(&exports).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Exporte",
+		})
+	}
+
+	return c.JSON(http.StatusOK, exports)
+}
+
+// CreatePayrollExport erzeugt die LODAS-Importdatei eines Abrechnungsmonats und protokolliert den Export.
+// Blockierende Prüfhinweise verhindern den Export; ein bereits exportierter Monat erfordert force=true.
+func CreatePayrollExport(c echo.Context) error {
+	if !canManagePayroll(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": payrollForbiddenMessage,
+		})
+	}
+
+	var request struct {
+		Month string `json:"month"`
+		Force bool   `json:"force"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Exportdaten",
+		})
+	}
+	if _, err := models.ParseMonth(request.Month, time.UTC); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültiger Monat (month), erwartet YYYY-MM",
+		})
+	}
+
+	preview, err := buildPayrollPreview(tenantDB(c), request.Month)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Berechnen der Lohndaten",
+		})
+	}
+	if !preview.CanExport() {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error":  "Export nicht möglich, bitte die Prüfhinweise beheben",
+			"issues": preview.Issues,
+		})
+	}
+	if len(preview.Exports) > 0 && !request.Force {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": "Der Monat wurde bereits exportiert; erneuter Export mit force=true",
+		})
+	}
+
+	users := make(map[uint]bool)
+	for _, line := range preview.Lines {
+		users[line.UserID] = true
+	}
+	export := models.PayrollExport{
+		Month:    request.Month,
+		FileName: fmt.Sprintf("lodas_%s_%d.txt", request.Month, len(preview.Exports)+1),
+		Content:  preview.File,
+		Lines:    len(preview.Lines),
+		Users:    len(users),
+	}
+	if user := currentUser(c); user != nil {
+		export.ExportedByID = &user.ID
+	}
+
+	if err := tenantDB(c).Create(&export).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Speichern des Exports",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, export)
+}
+
+// DownloadPayrollExport liefert die Importdatei eines Exports
+func DownloadPayrollExport(c echo.Context) error {
+	if !canManagePayroll(c) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": payrollForbiddenMessage,
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Export-ID",
+		})
+	}
+
+	var export models.PayrollExport
+	if err := tenantDB(c).First(&export, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Export nicht gefunden",
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", export.FileName))
+	return c.Blob(http.StatusOK, "text/plain; charset=us-ascii", []byte(export.Content))
+}
+
+// buildPayrollPreview berechnet die Werte aller Benutzer für einen Abrechnungsmonat (YYYY-MM) und prüft Personalnummern,
+// Lohnarten, DATEV-Einstellungen und Freigabe der Stundenzettel. Pauschalen für Rufbereitschaften werden nicht
+// exportiert, sondern als Hinweis gemeldet.
+func buildPayrollPreview(db *gorm.DB, month string) (*models.PayrollPreview, error) {
+	monthStart, _ := models.ParseMonth(month, time.UTC)
+	orgMonthStart, _ := models.ParseMonth(month, models.OrganisationLocation())
+
+	var users []models.User
+	if err := db.Order("account_number ASC, name ASC").Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	var shifts []models.Shift
+	if err := db.Preload("User").
+		Where("start_time >= ? AND start_time < ?", orgMonthStart, orgMonthStart.AddDate(0, 1, 0)).
+		Order("user_id ASC, start_time ASC").Find(&shifts).Error; err != nil {
+		return nil, err
+	}
+	calculator, err := loadSurchargeCalculator(db)
+	if err != nil {
+		return nil, err
+	}
+	surcharges := make(map[uint][]models.SurchargeLine)
+	for _, summary := range summarizeUserSurcharges(calculator, shifts, monthStart) {
+		surcharges[summary.UserID] = summary.Lines
+	}
+
+	var approved []uint
+	if err := db.Model(&models.Timesheet{}).Where("month = ? AND status = ?", month, models.TimesheetStatusApproved).
+		Pluck("user_id", &approved).Error; err != nil {
+		return nil, err
+	}
+	approvedUsers := make(map[uint]bool)
+	for _, userID := range approved {
+		approvedUsers[userID] = true
+	}
+
+	var duties []models.OnCallDuty
+	if err := db.Where("start_time >= ? AND start_time < ? AND allowance > 0", orgMonthStart, orgMonthStart.AddDate(0, 1, 0)).
+		Find(&duties).Error; err != nil {
+		return nil, err
+	}
+	allowances := make(map[uint]float64)
+	for _, duty := range duties {
+		allowances[duty.UserID] += duty.Allowance
+	}
+
+	preview := models.PayrollPreview{Month: month, Lines: make([]models.PayrollLine, 0), Issues: make([]models.PayrollIssue, 0)}
+	for _, user := range users {
+		months, err := timeAccountMonths(db, user, monthStart, monthStart, true)
+		if err != nil {
+			return nil, err
+		}
+		userID := user.ID
+		if allowance := allowances[user.ID]; allowance > 0 {
+			preview.Issues = append(preview.Issues, models.PayrollIssue{UserID: &userID,
+				Message: fmt.Sprintf("%s: Rufbereitschaftspauschalen von %s € sind nicht im Export enthalten", user.Name, strings.Replace(strconv.FormatFloat(allowance, 'f', 2, 64), ".", ",", 1))})
+		}
+
+		lines := services.PayrollLines(user, months[0], surcharges[user.ID])
+		if len(lines) == 0 {
+			continue
+		}
+		preview.Lines = append(preview.Lines, lines...)
+
+		if err := services.ValidatePersonnelNumber(user.AccountNumber); err != nil {
+			preview.Issues = append(preview.Issues, models.PayrollIssue{UserID: &userID, Message: user.Name + ": " + err.Error(), Blocking: true})
+		}
+		if !approvedUsers[user.ID] {
+			preview.Issues = append(preview.Issues, models.PayrollIssue{UserID: &userID, Message: user.Name + ": Stundenzettel ist nicht freigegeben"})
+		}
+	}
+
+	var mappings []models.WageTypeMapping
+	if err := db.Find(&mappings).Error; err != nil {
+		return nil, err
+	}
+	preview.Issues = append(preview.Issues, services.ApplyWageTypes(preview.Lines, mappings)...)
+
+	settings, err := loadPayrollSettings(db)
+	if err != nil {
+		return nil, err
+	}
+	if err := services.ValidatePayrollSettings(*settings); err != nil {
+		preview.Issues = append(preview.Issues, models.PayrollIssue{Message: "DATEV-Einstellungen: " + err.Error(), Blocking: true})
+	}
+
+	if err := db.Where("month = ?", month).Order("created_at ASC, id ASC").Find(&preview.Exports).Error; err != nil {
+		return nil, err
+	}
+	if preview.CanExport() {
+		preview.File = services.LodasFile(*settings, monthStart, preview.Lines)
+	}
+	return &preview, nil
+}
+
+// canManagePayroll prüft, ob der angemeldete Benutzer die Lohnabrechnung bearbeiten darf; ohne Anmeldung ist alles erlaubt
+func canManagePayroll(c echo.Context) bool {
+	user := currentUser(c)
+	return user == nil || user.IsAdmin
+}
+
+// loadPayrollSettings lädt die DATEV-Einstellungen der Organisation; ohne gespeicherte Einstellungen werden leere geliefert
+func loadPayrollSettings(db *gorm.DB) (*models.PayrollSettings, error) {
+	var settings models.PayrollSettings
+	if err := db.Order("id ASC").First(&settings).Error; err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// validateWageTypeMapping prüft eine Lohnarten-Zuordnung und deren Eindeutigkeit.
+// Liefert eine Fehlermeldung oder einen leeren String.
+func validateWageTypeMapping(db *gorm.DB, mapping *models.WageTypeMapping) string {
+	mapping.Key = strings.TrimSpace(mapping.Key)
+	mapping.WageType = strings.TrimSpace(mapping.WageType)
+	if mapping.Unit == "" {
+		mapping.Unit = models.PayrollUnitHours
+		if mapping.Category == models.PayrollCategoryAbsence {
+			mapping.Unit = models.PayrollUnitDays
+		}
+	}
+	if err := services.ValidateWageTypeMapping(*mapping); err != nil {
+		return err.Error()
+	}
+
+	var duplicates int64
+	db.Model(&models.WageTypeMapping{}).Where("category = ? AND key = ? AND id <> ?", mapping.Category, mapping.Key, mapping.ID).Count(&duplicates)
+	if duplicates > 0 {
+		return "Für diese Kategorie und diesen Schlüssel ist bereits eine Lohnart zugeordnet"
+	}
+	return ""
+}
+
+// loadWageTypeMappingFromParam lädt die Lohnarten-Zuordnung aus dem Pfadparameter id; bei Fehlern wird direkt
+// geantwortet und nil geliefert
+func loadWageTypeMappingFromParam(c echo.Context) (*models.WageTypeMapping, error) {
+	if !canManagePayroll(c) {
+		return nil, c.JSON(http.StatusForbidden, map[string]string{
+			"error": payrollForbiddenMessage,
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Lohnart-ID",
+		})
+	}
+
+	var mapping models.WageTypeMapping
+	if err := tenantDB(c).First(&mapping, id).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Lohnart nicht gefunden",
+		})
+	}
+	return &mapping, nil
+}
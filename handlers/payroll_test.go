@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayrollExport(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "lohn", Email: "lohn@example.com", Password: "x", AccountNumber: "42", Name: "Lohn User", WeeklyHours: 40, IsActive: true}
+	database.DB.Create(&user)
+	schedule := models.Schedule{Name: "März", StartDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&schedule)
+	// Sonntagsschicht mit 4 Stunden
+	database.DB.Create(&models.Shift{UserID: user.ID, ScheduleID: schedule.ID, StartTime: time.Date(2024, 3, 3, 9, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 3, 3, 13, 0, 0, 0, time.UTC)})
+
+	rec := callHandler(t, GetPayrollPreview, handlerRequest{target: "/?month=2024-03"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var preview models.PayrollPreview
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &preview))
+	assert.Len(t, preview.Lines, 2)
+	assert.False(t, preview.CanExport())
+	assert.Empty(t, preview.File)
+
+	// Ohne Zuordnungen und Einstellungen ist kein Export möglich
+	rec = callHandler(t, CreatePayrollExport, handlerRequest{body: `{"month":"2024-03"}`})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Keine Lohnart für Arbeitsstunden zugeordnet")
+
+	assert.Equal(t, http.StatusCreated, callHandler(t, CreateWageTypeMapping, handlerRequest{body: `{"category":"regular","wage_type":"100"}`}).Code)
+	assert.Equal(t, http.StatusCreated, callHandler(t, CreateWageTypeMapping, handlerRequest{body: `{"category":"surcharge","key":"Sonntagsarbeit","wage_type":"250"}`}).Code)
+	assert.Equal(t, http.StatusBadRequest, callHandler(t, CreateWageTypeMapping, handlerRequest{body: `{"category":"regular","wage_type":"101"}`}).Code)
+	assert.Equal(t, http.StatusBadRequest, callHandler(t, UpdatePayrollSettings, handlerRequest{body: `{"consultant_number":"12","client_number":"1"}`}).Code)
+	assert.Equal(t, http.StatusOK, callHandler(t, UpdatePayrollSettings, handlerRequest{body: `{"consultant_number":"1234567","client_number":"12345"}`}).Code)
+
+	rec = callHandler(t, CreatePayrollExport, handlerRequest{body: `{"month":"2024-03"}`})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var export models.PayrollExport
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &export))
+	assert.Equal(t, "lodas_2024-03_1.txt", export.FileName)
+	assert.Equal(t, 1, export.Users)
+
+	// Erneuter Export nur ausdrücklich
+	assert.Equal(t, http.StatusConflict, callHandler(t, CreatePayrollExport, handlerRequest{body: `{"month":"2024-03"}`}).Code)
+	assert.Equal(t, http.StatusCreated, callHandler(t, CreatePayrollExport, handlerRequest{body: `{"month":"2024-03","force":true}`}).Code)
+
+	rec = callHandler(t, DownloadPayrollExport, handlerRequest{params: pathParams{"id": "1"}})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "10;01032024;1;4,00;100;42;")
+	assert.Contains(t, rec.Body.String(), "10;01032024;1;4,00;250;42;")
+
+	rec = callHandler(t, GetPayrollPreview, handlerRequest{target: "/?month=2024-03"})
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &preview))
+	assert.Len(t, preview.Exports, 2)
+
+	// Nicht-Administratoren haben keinen Zugriff
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?month=2024-03", nil)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(currentUserContextKey, &user)
+	assert.NoError(t, GetPayrollPreview(c))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestDeleteWageTypeMapping_AllowsRecreate(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	rec := callHandler(t, CreateWageTypeMapping, handlerRequest{body: `{"category":"regular","wage_type":"100"}`})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var mapping models.WageTypeMapping
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &mapping))
+
+	assert.Equal(t, http.StatusOK, callHandler(t, DeleteWageTypeMapping, handlerRequest{params: pathParams{"id": strconv.Itoa(int(mapping.ID))}}).Code)
+	assert.Equal(t, http.StatusCreated, callHandler(t, CreateWageTypeMapping, handlerRequest{body: `{"category":"regular","wage_type":"110"}`}).Code)
+}
+
+func TestPayrollPreview_OnCall(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "ruf", Email: "ruf@example.com", Password: "x", AccountNumber: "43", Name: "Ruf User", WeeklyHours: 40, TimeZone: "UTC", IsActive: true}
+	database.DB.Create(&user)
+	duty := models.OnCallDuty{UserID: user.ID, StartTime: time.Date(2024, 3, 8, 18, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 3, 9, 6, 0, 0, 0, time.UTC), Allowance: 50}
+	database.DB.Create(&duty)
+	clockOut := time.Date(2024, 3, 9, 1, 30, 0, 0, time.UTC)
+	database.DB.Create(&models.TimeEntry{UserID: user.ID, OnCallDutyID: &duty.ID, ClockIn: time.Date(2024, 3, 8, 23, 0, 0, 0, time.UTC), ClockOut: &clockOut, Source: models.TimeEntrySourceCallOut})
+
+	rec := callHandler(t, GetPayrollPreview, handlerRequest{target: "/?month=2024-03"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var preview models.PayrollPreview
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &preview))
+	if assert.Len(t, preview.Lines, 1) {
+		assert.Equal(t, models.PayrollCategoryRegular, preview.Lines[0].Category)
+		assert.Equal(t, 2.5, preview.Lines[0].Value)
+	}
+	found := false
+	for _, issue := range preview.Issues {
+		if issue.Message == "Ruf User: Rufbereitschaftspauschalen von 50,00 € sind nicht im Export enthalten" {
+			found = true
+			assert.False(t, issue.Blocking)
+		}
+	}
+	assert.True(t, found)
+}
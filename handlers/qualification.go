@@ -0,0 +1,460 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+	"schichtplaner/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// GetQualifications gibt den Qualifikationskatalog zurück
+func GetQualifications(c echo.Context) error {
+	var qualifications []models.Qualification
+	if err := tenantDB(c).Order("name ASC").Find(&qualifications).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Qualifikationen",
+		})
+	}
+
+	return c.JSON(http.StatusOK, qualifications)
+}
+
+// CreateQualification legt eine neue Qualifikation im Katalog an
+func CreateQualification(c echo.Context) error {
+	qualification := models.Qualification{Enforcement: models.QualificationEnforcementWarn, IsActive: true}
+	if err := c.Bind(&qualification); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Qualifikationsdaten",
+		})
+	}
+	qualification.ID = 0
+
+	if message := validateQualification(tenantDB(c), &qualification); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	if err := tenantDB(c).Create(&qualification).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen der Qualifikation",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, qualification)
+}
+
+// UpdateQualification aktualisiert eine Qualifikation
+func UpdateQualification(c echo.Context) error {
+	qualification, err := loadQualificationFromParam(c)
+	if err != nil || qualification == nil {
+		return err
+	}
+
+	updateData := *qualification
+	if err := c.Bind(&updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Qualifikationsdaten",
+		})
+	}
+	updateData.Base = qualification.Base
+
+	if message := validateQualification(tenantDB(c), &updateData); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	// Save statt Updates, damit auch is_active=false übernommen wird
+	if err := tenantDB(c).Save(&updateData).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Aktualisieren der Qualifikation",
+		})
+	}
+
+	return c.JSON(http.StatusOK, updateData)
+}
+
+// DeleteQualification löscht eine Qualifikation samt aller Nachweise der Benutzer
+func DeleteQualification(c echo.Context) error {
+	qualification, err := loadQualificationFromParam(c)
+	if err != nil || qualification == nil {
+		return err
+	}
+
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("qualification_id = ?", qualification.ID).Delete(&models.UserQualification{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(qualification).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen der Qualifikation",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Qualifikation erfolgreich gelöscht",
+	})
+}
+
+// GetUserQualifications gibt die Qualifikationsnachweise eines Benutzers zurück
+func GetUserQualifications(c echo.Context) error {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return err
+	}
+
+	var entries []models.UserQualification
+	if err := tenantDB(c).Preload("Qualification").Where("user_id = ?", user.ID).Order("qualification_id ASC, expires_on ASC").Find(&entries).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Qualifikationen",
+		})
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
+
+// CreateUserQualification erfasst einen Qualifikationsnachweis. Ohne Ablaufdatum gilt die Standardgültigkeit der Qualifikation.
+func CreateUserQualification(c echo.Context) error {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return err
+	}
+
+	var entry models.UserQualification
+	if err := c.Bind(&entry); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Qualifikationsdaten",
+		})
+	}
+	entry.ID = 0
+	entry.UserID = user.ID
+
+	if message := validateUserQualification(tenantDB(c), &entry); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	if entry.ExpiresOn == nil && entry.AcquiredOn != nil {
+		entry.ExpiresOn = services.DefaultExpiry(entry.Qualification, *entry.AcquiredOn)
+	}
+
+	if err := tenantDB(c).Omit("User", "Qualification").Create(&entry).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen des Qualifikationsnachweises",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, entry)
+}
+
+// UpdateUserQualification aktualisiert einen Qualifikationsnachweis, z.B. nach einer Auffrischung
+func UpdateUserQualification(c echo.Context) error {
+	entry, err := loadUserQualificationFromParams(c)
+	if err != nil || entry == nil {
+		return err
+	}
+
+	updateData := *entry
+	updateData.Qualification = models.Qualification{}
+	if err := c.Bind(&updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Qualifikationsdaten",
+		})
+	}
+	updateData.Base = entry.Base
+	updateData.UserID = entry.UserID
+
+	if message := validateUserQualification(tenantDB(c), &updateData); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	// Save statt Updates, damit auch ein entferntes Ablaufdatum übernommen wird
+	if err := tenantDB(c).Omit("User", "Qualification").Save(&updateData).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Aktualisieren des Qualifikationsnachweises",
+		})
+	}
+
+	return c.JSON(http.StatusOK, updateData)
+}
+
+// DeleteUserQualification löscht einen Qualifikationsnachweis
+func DeleteUserQualification(c echo.Context) error {
+	entry, err := loadUserQualificationFromParams(c)
+	if err != nil || entry == nil {
+		return err
+	}
+
+	if err := tenantDB(c).Delete(entry).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen des Qualifikationsnachweises",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Qualifikationsnachweis erfolgreich gelöscht",
+	})
+}
+
+// GetExpiringQualifications listet Nachweise, die in den nächsten days Tagen (Standard: 30) ablaufen.
+// Nachweise, die bereits durch einen länger gültigen Nachweis derselben Qualifikation erneuert wurden, entfallen.
+func GetExpiringQualifications(c echo.Context) error {
+	days := 30
+	if value := c.QueryParam("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > 3660 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültige Anzahl Tage (days)",
+			})
+		}
+		days = parsed
+	}
+
+	today := calendarDay(time.Now())
+	until := today.AddDate(0, 0, days)
+
+	var entries []models.UserQualification
+	if err := tenantDB(c).Preload("User").Preload("Qualification").Order("expires_on ASC").Find(&entries).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Qualifikationen",
+		})
+	}
+
+	expiries := make([]models.QualificationExpiry, 0)
+	for _, entry := range entries {
+		if entry.ExpiresOn == nil || entry.ExpiresOn.Before(today) || entry.ExpiresOn.After(until) || isRenewed(entry, entries) {
+			continue
+		}
+		expiries = append(expiries, models.QualificationExpiry{
+			UserQualificationID: entry.ID,
+			UserID:              entry.UserID,
+			UserName:            entry.User.Name,
+			QualificationID:     entry.QualificationID,
+			QualificationName:   entry.Qualification.Name,
+			ExpiresOn:           *entry.ExpiresOn,
+			DaysLeft:            int(entry.ExpiresOn.Sub(today).Hours() / 24),
+		})
+	}
+
+	return c.JSON(http.StatusOK, expiries)
+}
+
+// GetUnderQualifiedShifts listet Schichten im Zeitraum [from, to) (RFC3339, Standard: die nächsten 28 Tage ab jetzt),
+// deren Benutzer eine geforderte Qualifikation fehlt oder deren Nachweis am Schichttag nicht gültig ist
+func GetUnderQualifiedShifts(c echo.Context) error {
+	now := time.Now()
+	from, to, message := periodFromQuery(c, now, now.AddDate(0, 0, 28))
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	var shifts []models.Shift
+	if err := tenantDB(c).Preload("User").Preload("ShiftType").
+		Where("end_time > ? AND start_time < ?", from, to).
+		Order("start_time ASC").Find(&shifts).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Schichten",
+		})
+	}
+
+	var qualifications []models.Qualification
+	var entries []models.UserQualification
+	if err := tenantDB(c).Find(&qualifications).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Qualifikationen",
+		})
+	}
+	if err := tenantDB(c).Find(&entries).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Qualifikationen",
+		})
+	}
+
+	catalogue := make(map[uint]models.Qualification, len(qualifications))
+	for _, qualification := range qualifications {
+		catalogue[qualification.ID] = qualification
+	}
+	held := make(map[uint][]models.UserQualification)
+	for _, entry := range entries {
+		held[entry.UserID] = append(held[entry.UserID], entry)
+	}
+
+	report := make([]models.UnderQualifiedShift, 0)
+	for _, shift := range shifts {
+		var shiftType *models.ShiftType
+		if shift.ShiftTypeID != nil {
+			shiftType = &shift.ShiftType
+		}
+
+		var required []models.Qualification
+		for _, id := range services.RequiredQualificationIDs(shift, shiftType) {
+			if qualification, ok := catalogue[id]; ok {
+				required = append(required, qualification)
+			}
+		}
+
+		violations := services.CheckQualifications(shift, required, held[shift.UserID], shift.Location())
+		if len(violations) > 0 {
+			report = append(report, models.UnderQualifiedShift{Shift: shift, Violations: violations})
+		}
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// shiftQualificationViolations prüft, ob der Benutzer einer Schicht alle von Schicht und Schichttyp geforderten Qualifikationen besitzt
+func shiftQualificationViolations(db *gorm.DB, shift models.Shift) ([]models.ComplianceViolation, error) {
+	var shiftType *models.ShiftType
+	if shift.ShiftTypeID != nil {
+		var loaded models.ShiftType
+		if db.First(&loaded, *shift.ShiftTypeID).Error == nil {
+			shiftType = &loaded
+		}
+	}
+
+	ids := services.RequiredQualificationIDs(shift, shiftType)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var required []models.Qualification
+	if err := db.Where("id IN ?", ids).Order("name ASC").Find(&required).Error; err != nil {
+		return nil, err
+	}
+	var held []models.UserQualification
+	if err := db.Where("user_id = ?", shift.UserID).Find(&held).Error; err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	db.First(&user, shift.UserID)
+
+	return services.CheckQualifications(shift, required, held, user.Location()), nil
+}
+
+// blockingViolation liefert die Meldung des ersten Verstoßes mit Schweregrad error oder einen leeren String
+func blockingViolation(violations []models.ComplianceViolation) string {
+	for _, violation := range violations {
+		if violation.Severity == models.SeverityError {
+			return violation.Message
+		}
+	}
+	return ""
+}
+
+// isRenewed prüft, ob es zum Nachweis einen länger gültigen Nachweis derselben Qualifikation desselben Benutzers gibt
+func isRenewed(entry models.UserQualification, entries []models.UserQualification) bool {
+	for _, other := range entries {
+		if other.ID == entry.ID || other.UserID != entry.UserID || other.QualificationID != entry.QualificationID {
+			continue
+		}
+		if other.ExpiresOn == nil || other.ExpiresOn.After(*entry.ExpiresOn) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateQualification prüft eine Qualifikation und liefert die erste Fehlermeldung oder einen leeren String
+func validateQualification(db *gorm.DB, qualification *models.Qualification) string {
+	validator := utils.NewValidator()
+	validator.RequiredString("Name", qualification.Name, "Name ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return result.Errors[0]
+	}
+
+	if qualification.Enforcement == "" {
+		qualification.Enforcement = models.QualificationEnforcementWarn
+	}
+	if err := services.ValidateQualification(*qualification); err != nil {
+		return err.Error()
+	}
+
+	var duplicates int64
+	db.Model(&models.Qualification{}).Where("name = ? AND id <> ?", qualification.Name, qualification.ID).Count(&duplicates)
+	if duplicates > 0 {
+		return "Qualifikation mit diesem Namen existiert bereits"
+	}
+	return ""
+}
+
+// validateUserQualification prüft einen Nachweis, lädt die Qualifikation und normalisiert die Daten auf Kalendertage.
+// Liefert die erste Fehlermeldung oder einen leeren String.
+func validateUserQualification(db *gorm.DB, entry *models.UserQualification) string {
+	validator := utils.NewValidator()
+	validator.RequiredUint("QualificationID", entry.QualificationID, "Qualifikation ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return result.Errors[0]
+	}
+
+	if err := db.First(&entry.Qualification, entry.QualificationID).Error; err != nil {
+		return "Qualifikation nicht gefunden"
+	}
+
+	if entry.AcquiredOn != nil {
+		acquired := calendarDay(*entry.AcquiredOn)
+		entry.AcquiredOn = &acquired
+	}
+	if entry.ExpiresOn != nil {
+		expires := calendarDay(*entry.ExpiresOn)
+		entry.ExpiresOn = &expires
+	}
+	if entry.AcquiredOn != nil && entry.ExpiresOn != nil && entry.ExpiresOn.Before(*entry.AcquiredOn) {
+		return "Ablaufdatum muss nach dem Erwerb liegen"
+	}
+	return ""
+}
+
+// loadQualificationFromParam lädt die Qualifikation aus dem Pfadparameter id; bei Fehlern wird direkt geantwortet und nil geliefert
+func loadQualificationFromParam(c echo.Context) (*models.Qualification, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Qualifikations-ID",
+		})
+	}
+
+	var qualification models.Qualification
+	if err := tenantDB(c).First(&qualification, id).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Qualifikation nicht gefunden",
+		})
+	}
+	return &qualification, nil
+}
+
+// loadUserQualificationFromParams lädt den Nachweis aus den Pfadparametern id und entry_id; bei Fehlern wird direkt geantwortet und nil geliefert
+func loadUserQualificationFromParams(c echo.Context) (*models.UserQualification, error) {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	entryID, err := strconv.ParseUint(c.Param("entry_id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Nachweis-ID",
+		})
+	}
+
+	var entry models.UserQualification
+	if err := tenantDB(c).Preload("Qualification").Where("user_id = ?", user.ID).First(&entry, entryID).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Qualifikationsnachweis nicht gefunden",
+		})
+	}
+	return &entry, nil
+}
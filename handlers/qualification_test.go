@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// postQualifiedShift legt über den Handler eine Schicht an und liefert den Recorder
+func postQualifiedShift(t *testing.T, body string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/shifts", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	assert.NoError(t, CreateShift(c))
+	return rec
+}
+
+func TestCreateShift_QualificationEnforcement(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "quali", Email: "quali@example.com", Password: "hashedpassword", Name: "Quali User"}
+	database.DB.Create(&user)
+	schedule := models.Schedule{Name: "Juni", StartDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&schedule)
+
+	firstAid := models.Qualification{Name: "Ersthelfer", Enforcement: models.QualificationEnforcementWarn, IsActive: true}
+	forklift := models.Qualification{Name: "Staplerschein", Enforcement: models.QualificationEnforcementBlock, IsActive: true}
+	database.DB.Create(&firstAid)
+	database.DB.Create(&forklift)
+	shiftType := models.ShiftType{Name: "Lager", RequiredQualificationIDs: []uint{forklift.ID}}
+	database.DB.Create(&shiftType)
+
+	body := func(extra string) string {
+		return `{"user_id":` + strconv.Itoa(int(user.ID)) + `,"schedule_id":` + strconv.Itoa(int(schedule.ID)) +
+			`,"shift_type_id":` + strconv.Itoa(int(shiftType.ID)) +
+			`,"start_time":"2024-06-10T06:00:00","end_time":"2024-06-10T14:00:00"` + extra + `}`
+	}
+
+	// Staplerschein fehlt: Schicht wird abgelehnt
+	rec := postQualifiedShift(t, body(""))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Staplerschein")
+
+	var count int64
+	database.DB.Model(&models.Shift{}).Count(&count)
+	assert.Equal(t, int64(0), count)
+
+	// Mit Staplerschein wird die Schicht gespeichert, der fehlende Ersthelfer nur gemeldet
+	database.DB.Create(&models.UserQualification{UserID: user.ID, QualificationID: forklift.ID})
+	rec = postQualifiedShift(t, body(`,"required_qualification_ids":[`+strconv.Itoa(int(firstAid.ID))+`]`))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var created models.Shift
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, []uint{firstAid.ID}, created.RequiredQualificationIDs)
+	found := false
+	for _, warning := range created.Warnings {
+		if warning.Rule == "qualification_missing" {
+			found = true
+			assert.Equal(t, created.ID, warning.ShiftID)
+			assert.Contains(t, warning.Message, "Ersthelfer")
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestCreateUserQualification_DefaultExpiry(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "quali", Email: "quali@example.com", Password: "hashedpassword", Name: "Quali User"}
+	database.DB.Create(&user)
+	firstAid := models.Qualification{Name: "Ersthelfer", Enforcement: models.QualificationEnforcementWarn, ValidityMonths: 24, IsActive: true}
+	database.DB.Create(&firstAid)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"qualification_id":`+strconv.Itoa(int(firstAid.ID))+`,"acquired_on":"2024-03-01T00:00:00Z"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(user.ID)))
+
+	if assert.NoError(t, CreateUserQualification(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var entry models.UserQualification
+		database.DB.First(&entry)
+		if assert.NotNil(t, entry.ExpiresOn) {
+			assert.True(t, time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC).Equal(*entry.ExpiresOn))
+		}
+	}
+}
+
+func TestQualificationReports(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "quali", Email: "quali@example.com", Password: "hashedpassword", Name: "Quali User"}
+	database.DB.Create(&user)
+	firstAid := models.Qualification{Name: "Ersthelfer", Enforcement: models.QualificationEnforcementWarn, IsActive: true}
+	keys := models.Qualification{Name: "Schlüssel", Enforcement: models.QualificationEnforcementWarn, IsActive: true}
+	database.DB.Create(&firstAid)
+	database.DB.Create(&keys)
+
+	today := calendarDay(time.Now())
+	soon := today.AddDate(0, 0, 10)
+	later := today.AddDate(0, 0, 90)
+	database.DB.Create(&models.UserQualification{UserID: user.ID, QualificationID: firstAid.ID, ExpiresOn: &soon})
+	// Erneuerter Schlüsselnachweis: der ablaufende Nachweis erscheint nicht im Bericht
+	database.DB.Create(&models.UserQualification{UserID: user.ID, QualificationID: keys.ID, ExpiresOn: &soon})
+	database.DB.Create(&models.UserQualification{UserID: user.ID, QualificationID: keys.ID, ExpiresOn: &later})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?days=30", nil)
+	rec := httptest.NewRecorder()
+	if assert.NoError(t, GetExpiringQualifications(e.NewContext(req, rec))) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var expiries []models.QualificationExpiry
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &expiries))
+		if assert.Len(t, expiries, 1) {
+			assert.Equal(t, "Ersthelfer", expiries[0].QualificationName)
+			assert.Equal(t, 10, expiries[0].DaysLeft)
+		}
+	}
+
+	// Schicht nach Ablauf des Ersthelfer-Nachweises
+	schedule := models.Schedule{Name: "Plan", StartDate: today, EndDate: today.AddDate(0, 1, 0)}
+	database.DB.Create(&schedule)
+	start := today.AddDate(0, 0, 14).Add(8 * time.Hour)
+	database.DB.Create(&models.Shift{UserID: user.ID, ScheduleID: schedule.ID, StartTime: start, EndTime: start.Add(8 * time.Hour),
+		RequiredQualificationIDs: []uint{firstAid.ID, keys.ID}})
+	database.DB.Create(&models.Shift{UserID: user.ID, ScheduleID: schedule.ID, StartTime: start.AddDate(0, 0, -10), EndTime: start.AddDate(0, 0, -10).Add(8 * time.Hour)})
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	if assert.NoError(t, GetUnderQualifiedShifts(e.NewContext(req, rec))) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var report []models.UnderQualifiedShift
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		if assert.Len(t, report, 1) && assert.Len(t, report[0].Violations, 1) {
+			assert.Equal(t, "qualification_expired", report[0].Violations[0].Rule)
+		}
+	}
+}
+
+func TestRecurringShiftAndImport_QualificationEnforcement(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "quali", Email: "quali@example.com", Password: "hashedpassword", AccountNumber: "2001", Name: "Quali User", TimeZone: "UTC", IsActive: true}
+	database.DB.Create(&user)
+	schedule := models.Schedule{Name: "Juni", StartDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&schedule)
+	forklift := models.Qualification{Name: "Staplerschein", Enforcement: models.QualificationEnforcementBlock, IsActive: true}
+	database.DB.Create(&forklift)
+	shiftType := models.ShiftType{Name: "Lager", ShortCode: "L", DefaultStart: models.NewWallClock(6, 0), DefaultEnd: models.NewWallClock(14, 0),
+		RequiredQualificationIDs: []uint{forklift.ID}, IsActive: true}
+	database.DB.Create(&shiftType)
+
+	// Serie ohne Staplerschein wird abgelehnt
+	body, _ := json.Marshal(models.RecurringShift{
+		UserID:      user.ID,
+		ShiftTypeID: shiftType.ID,
+		ScheduleID:  schedule.ID,
+		RRule:       "FREQ=WEEKLY;BYDAY=MO",
+		StartTime:   time.Date(2024, 6, 3, 6, 0, 0, 0, time.UTC),
+		EndTime:     time.Date(2024, 6, 3, 14, 0, 0, 0, time.UTC),
+		IsActive:    true,
+	})
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if assert.NoError(t, CreateRecurringShift(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "03.06.2024: Qualifikation \\\"Staplerschein\\\" fehlt")
+	}
+
+	// Import meldet den Verstoß im Probelauf als Fehler der Zeile
+	payload, _ := json.Marshal(map[string]interface{}{"csv": "Personalnummer;Mo 03.06.\n2001;L\n", "dry_run": true})
+	req = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(schedule.ID)))
+	if assert.NoError(t, ImportSchedule(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var report models.ScheduleImportReport
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Equal(t, 1, report.Invalid)
+		if assert.Len(t, report.Rows, 1) {
+			assert.Equal(t, []string{"03.06.: Qualifikation \"Staplerschein\" fehlt"}, report.Rows[0].Errors)
+		}
+	}
+
+	var series, shifts int64
+	database.DB.Model(&models.RecurringShift{}).Count(&series)
+	database.DB.Model(&models.Shift{}).Count(&shifts)
+	assert.Zero(t, series)
+	assert.Zero(t, shifts)
+}
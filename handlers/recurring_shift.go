@@ -0,0 +1,840 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+	"schichtplaner/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// Bearbeitungsumfang für einzelne Vorkommen einer Serie
+const (
+	RecurrenceScopeThis      = "this"      // Nur dieses Vorkommen
+	RecurrenceScopeFollowing = "following" // Dieses und alle folgenden Vorkommen
+	RecurrenceScopeAll       = "all"       // Alle Vorkommen der Serie
+)
+
+// recurringOccurrenceRequest enthält die Änderungen an einem Vorkommen
+type recurringOccurrenceRequest struct {
+	UserID      uint      `json:"user_id"`
+	ShiftTypeID uint      `json:"shift_type_id"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	BreakTime   *int      `json:"break_time"`
+	Description *string   `json:"description"`
+}
+
+// GetRecurringShifts gibt alle wiederkehrenden Schichten mit Pagination zurück
+func GetRecurringShifts(c echo.Context) error {
+	params := utils.GetPaginationParams(c)
+
+	var recurringShifts []models.RecurringShift
+	var total int64
+
+	// Zähle die Gesamtanzahl
+	tenantDB(c).Model(&models.RecurringShift{}).Count(&total)
+
+	// Lade die paginierten Daten mit Preloads
+	if err := tenantDB(c).Preload("User").Preload("ShiftType").Preload("Exceptions").Offset(params.Offset).Limit(params.PageSize).Find(&recurringShifts).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der wiederkehrenden Schichten",
+		})
+	}
+
+	response := utils.CreatePaginatedResponse(recurringShifts, int(total), params)
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetRecurringShift gibt eine spezifische wiederkehrende Schicht zurück
+func GetRecurringShift(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Serien-ID",
+		})
+	}
+
+	var recurringShift models.RecurringShift
+	if err := tenantDB(c).Preload("User").Preload("ShiftType").Preload("Schedule").Preload("Exceptions").First(&recurringShift, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Wiederkehrende Schicht nicht gefunden",
+		})
+	}
+
+	return c.JSON(http.StatusOK, recurringShift)
+}
+
+// CreateRecurringShift erstellt eine neue wiederkehrende Schicht und erzeugt ihre Schichten im Schichtplan
+func CreateRecurringShift(c echo.Context) error {
+	var recurringShift models.RecurringShift
+
+	if err := c.Bind(&recurringShift); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Seriendaten",
+		})
+	}
+
+	if message := validateRecurringShift(tenantDB(c), &recurringShift); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	err := tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&recurringShift).Error; err != nil {
+			return err
+		}
+		return syncRecurringShiftInstances(tx, &recurringShift)
+	})
+	if err != nil {
+		return respondRecurringShiftError(c, err, "Fehler beim Erstellen der wiederkehrenden Schicht")
+	}
+
+	return c.JSON(http.StatusCreated, recurringShift)
+}
+
+// UpdateRecurringShift aktualisiert eine Serie und berechnet alle nicht einzeln bearbeiteten Vorkommen neu
+func UpdateRecurringShift(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Serien-ID",
+		})
+	}
+
+	var recurringShift models.RecurringShift
+	if err := tenantDB(c).First(&recurringShift, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Wiederkehrende Schicht nicht gefunden",
+		})
+	}
+
+	var updateData models.RecurringShift
+	if err := c.Bind(&updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Seriendaten",
+		})
+	}
+
+	if message := validateRecurringShift(tenantDB(c), &updateData); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	// Übernimm alle Felder, damit auch Until und Count zurückgesetzt werden können
+	updateData.Base = recurringShift.Base
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&updateData).Error; err != nil {
+			return err
+		}
+		return syncRecurringShiftInstances(tx, &updateData)
+	})
+	if err != nil {
+		return respondRecurringShiftError(c, err, "Fehler beim Aktualisieren der wiederkehrenden Schicht")
+	}
+
+	return c.JSON(http.StatusOK, updateData)
+}
+
+// DeleteRecurringShift löscht eine Serie mit allen Vorkommen und Ausnahmen
+func DeleteRecurringShift(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Serien-ID",
+		})
+	}
+
+	var recurringShift models.RecurringShift
+	if err := tenantDB(c).First(&recurringShift, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Wiederkehrende Schicht nicht gefunden",
+		})
+	}
+
+	if err := tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		return deleteRecurringShiftSeries(tx, &recurringShift)
+	}); err != nil {
+		return respondRecurringShiftError(c, err, "Fehler beim Löschen der wiederkehrenden Schicht")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Wiederkehrende Schicht erfolgreich gelöscht",
+	})
+}
+
+// ExpandRecurringShift erzeugt bzw. aktualisiert die Schichten einer Serie im zugehörigen Schichtplan
+func ExpandRecurringShift(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Serien-ID",
+		})
+	}
+
+	var recurringShift models.RecurringShift
+	if err := tenantDB(c).First(&recurringShift, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Wiederkehrende Schicht nicht gefunden",
+		})
+	}
+
+	if err := tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		return syncRecurringShiftInstances(tx, &recurringShift)
+	}); err != nil {
+		return respondRecurringShiftError(c, err, "Fehler beim Erzeugen der Schichten")
+	}
+
+	var shifts []models.Shift
+	tenantDB(c).Where("recurring_shift_id = ?", recurringShift.ID).Order("start_time ASC").Find(&shifts)
+
+	return c.JSON(http.StatusOK, shifts)
+}
+
+// GetRecurringShiftOccurrences berechnet die Vorkommen einer Serie in einem Zeitraum, ohne sie zu speichern
+func GetRecurringShiftOccurrences(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Serien-ID",
+		})
+	}
+
+	var recurringShift models.RecurringShift
+	if err := tenantDB(c).Preload("Schedule").Preload("Exceptions").First(&recurringShift, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Wiederkehrende Schicht nicht gefunden",
+		})
+	}
+
+	// Standardzeitraum ist der Zeitraum des Schichtplans
+	from, to := recurringShift.Schedule.StartDate, recurringShift.Schedule.EndDate
+	if value := c.QueryParam("from"); value != "" {
+		if from, err = time.Parse(time.RFC3339, value); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültiger Parameter from",
+			})
+		}
+	}
+	if value := c.QueryParam("to"); value != "" {
+		if to, err = time.Parse(time.RFC3339, value); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültiger Parameter to",
+			})
+		}
+	}
+
+	shifts, err := services.ExpandRecurringShift(recurringShift, from, to)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige RRULE: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, shifts)
+}
+
+// CreateRecurringShiftException schließt ein Vorkommen der Serie aus (EXDATE)
+func CreateRecurringShiftException(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Serien-ID",
+		})
+	}
+
+	var recurringShift models.RecurringShift
+	if err := tenantDB(c).First(&recurringShift, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Wiederkehrende Schicht nicht gefunden",
+		})
+	}
+
+	var exception models.RecurringShiftException
+	if err := c.Bind(&exception); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Ausnahmedaten",
+		})
+	}
+
+	validator := utils.NewValidator()
+	validator.RequiredTime("Date", exception.Date, "Datum ist ein Pflichtfeld")
+
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+
+	exception.RecurringShiftID = recurringShift.ID
+	exception.Date = exception.Date.UTC()
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&exception).Error; err != nil {
+			return err
+		}
+		// Vorkommen in Go vergleichen: gespeicherte Zeitpunkte können einen Zeitzonenversatz enthalten
+		var instances []models.Shift
+		if err := tx.Where("recurring_shift_id = ?", recurringShift.ID).Find(&instances).Error; err != nil {
+			return err
+		}
+		for _, instance := range instances {
+			if instance.RecurrenceID != nil && instance.RecurrenceID.Equal(exception.Date) {
+				if err := checkShiftLocks(tx, instance); err != nil {
+					return err
+				}
+				if err := tx.Delete(&instance).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return syncRecurringShiftInstances(tx, &recurringShift)
+	})
+	if err != nil {
+		return respondRecurringShiftError(c, err, "Fehler beim Erstellen der Ausnahme")
+	}
+
+	return c.JSON(http.StatusCreated, exception)
+}
+
+// DeleteRecurringShiftException entfernt eine Ausnahme und stellt das Vorkommen wieder her
+func DeleteRecurringShiftException(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Serien-ID",
+		})
+	}
+
+	exceptionID, err := strconv.ParseUint(c.Param("exception_id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Ausnahme-ID",
+		})
+	}
+
+	var exception models.RecurringShiftException
+	if err := tenantDB(c).Where("recurring_shift_id = ?", id).First(&exception, exceptionID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Ausnahme nicht gefunden",
+		})
+	}
+
+	var recurringShift models.RecurringShift
+	if err := tenantDB(c).First(&recurringShift, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Wiederkehrende Schicht nicht gefunden",
+		})
+	}
+
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&exception).Error; err != nil {
+			return err
+		}
+		return syncRecurringShiftInstances(tx, &recurringShift)
+	})
+	if err != nil {
+		return respondRecurringShiftError(c, err, "Fehler beim Löschen der Ausnahme")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Ausnahme erfolgreich gelöscht",
+	})
+}
+
+// UpdateRecurringShiftOccurrence bearbeitet ein Vorkommen mit scope=this|following|all
+func UpdateRecurringShiftOccurrence(c echo.Context) error {
+	recurringShift, shift, scope, err := loadRecurringShiftOccurrence(c)
+	if err != nil || recurringShift == nil {
+		return err
+	}
+
+	var request recurringOccurrenceRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Schichtdaten",
+		})
+	}
+
+	validator := utils.NewValidator()
+	validator.RequiredTime("StartTime", request.StartTime, "Startzeit ist ein Pflichtfeld")
+	validator.RequiredTime("EndTime", request.EndTime, "Endzeit ist ein Pflichtfeld")
+	validator.TimeRange("StartTime", "EndTime", request.StartTime, request.EndTime, "Startzeit muss vor Endzeit liegen")
+
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		switch scope {
+		case RecurrenceScopeThis:
+			previous := *shift
+			applyOccurrenceRequestToShift(shift, request)
+			shift.RecurrenceModified = true
+			if err := checkShiftLocks(tx, previous, *shift); err != nil {
+				return err
+			}
+			if err := checkShiftQualifications(tx, recurringShift, *shift); err != nil {
+				return err
+			}
+			return tx.Save(shift).Error
+
+		case RecurrenceScopeFollowing:
+			if !shift.RecurrenceID.After(recurringShift.StartTime) {
+				return updateWholeSeries(tx, recurringShift, shift, request)
+			}
+			successor, err := splitRecurringShift(tx, recurringShift, *shift.RecurrenceID)
+			if err != nil {
+				return err
+			}
+			return updateWholeSeries(tx, successor, shift, request)
+
+		default:
+			return updateWholeSeries(tx, recurringShift, shift, request)
+		}
+	})
+	if err != nil {
+		return respondRecurringShiftError(c, err, "Fehler beim Aktualisieren des Vorkommens")
+	}
+
+	tenantDB(c).First(shift, shift.ID)
+	return c.JSON(http.StatusOK, shift)
+}
+
+// DeleteRecurringShiftOccurrence löscht ein Vorkommen mit scope=this|following|all
+func DeleteRecurringShiftOccurrence(c echo.Context) error {
+	recurringShift, shift, scope, err := loadRecurringShiftOccurrence(c)
+	if err != nil || recurringShift == nil {
+		return err
+	}
+
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		switch scope {
+		case RecurrenceScopeThis:
+			if err := checkShiftLocks(tx, *shift); err != nil {
+				return err
+			}
+			exception := models.RecurringShiftException{
+				RecurringShiftID: recurringShift.ID,
+				Date:             shift.RecurrenceID.UTC(),
+			}
+			if err := tx.Create(&exception).Error; err != nil {
+				return err
+			}
+			return tx.Delete(shift).Error
+
+		case RecurrenceScopeFollowing:
+			if !shift.RecurrenceID.After(recurringShift.StartTime) {
+				return deleteRecurringShiftSeries(tx, recurringShift)
+			}
+			successor, err := splitRecurringShift(tx, recurringShift, *shift.RecurrenceID)
+			if err != nil {
+				return err
+			}
+			return deleteRecurringShiftSeries(tx, successor)
+
+		default:
+			return deleteRecurringShiftSeries(tx, recurringShift)
+		}
+	})
+	if err != nil {
+		return respondRecurringShiftError(c, err, "Fehler beim Löschen des Vorkommens")
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Vorkommen erfolgreich gelöscht",
+	})
+}
+
+// validateRecurringShift prüft Pflichtfelder, RRULE und Referenzen einer Serie.
+// Liefert die erste Fehlermeldung oder einen leeren String.
+func validateRecurringShift(db *gorm.DB, recurringShift *models.RecurringShift) string {
+	validator := utils.NewValidator()
+	validator.RequiredUint("UserID", recurringShift.UserID, "Benutzer-ID ist ein Pflichtfeld")
+	validator.RequiredUint("ShiftTypeID", recurringShift.ShiftTypeID, "Schichttyp-ID ist ein Pflichtfeld")
+	validator.RequiredUint("ScheduleID", recurringShift.ScheduleID, "Schichtplan-ID ist ein Pflichtfeld")
+	validator.RequiredString("RRule", recurringShift.RRule, "RRULE ist ein Pflichtfeld")
+	validator.RequiredTime("StartTime", recurringShift.StartTime, "Startzeit ist ein Pflichtfeld")
+	validator.RequiredTime("EndTime", recurringShift.EndTime, "Endzeit ist ein Pflichtfeld")
+	validator.TimeRange("StartTime", "EndTime", recurringShift.StartTime, recurringShift.EndTime, "Startzeit muss vor Endzeit liegen")
+
+	if result := validator.Validate(); !result.IsValid {
+		return result.Errors[0]
+	}
+
+	if recurringShift.Until != nil && recurringShift.Count > 0 {
+		return "Enddatum und Anzahl dürfen nicht gemeinsam gesetzt werden"
+	}
+
+	if _, err := services.ParseRRule(recurringShift.RRule); err != nil {
+		return "Ungültige RRULE: " + err.Error()
+	}
+
+	var user models.User
+	if err := db.First(&user, recurringShift.UserID).Error; err != nil {
+		return "Benutzer nicht gefunden"
+	}
+
+	// Ohne Angabe gilt die Zeitzone des Benutzers, sonst die der Organisation
+	if recurringShift.TimeZone == "" {
+		recurringShift.TimeZone = user.TimeZone
+	}
+	if _, err := models.LoadLocation(recurringShift.TimeZone); err != nil {
+		return "Ungültige Zeitzone"
+	}
+	if err := db.First(&models.ShiftType{}, recurringShift.ShiftTypeID).Error; err != nil {
+		return "Schichttyp nicht gefunden"
+	}
+	if err := db.First(&models.Schedule{}, recurringShift.ScheduleID).Error; err != nil {
+		return "Schichtplan nicht gefunden"
+	}
+
+	return ""
+}
+
+// loadRecurringShiftOccurrence lädt Serie, Vorkommen und Bearbeitungsumfang aus der Anfrage.
+// Bei einem Fehler wurde bereits geantwortet und die Serie ist nil.
+func loadRecurringShiftOccurrence(c echo.Context) (*models.RecurringShift, *models.Shift, string, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, nil, "", c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Serien-ID",
+		})
+	}
+
+	shiftID, err := strconv.ParseUint(c.Param("shift_id"), 10, 32)
+	if err != nil {
+		return nil, nil, "", c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Schicht-ID",
+		})
+	}
+
+	scope := c.QueryParam("scope")
+	if scope == "" {
+		scope = RecurrenceScopeThis
+	}
+	if scope != RecurrenceScopeThis && scope != RecurrenceScopeFollowing && scope != RecurrenceScopeAll {
+		return nil, nil, "", c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültiger Bearbeitungsumfang, erlaubt sind this, following und all",
+		})
+	}
+
+	var recurringShift models.RecurringShift
+	if err := tenantDB(c).First(&recurringShift, id).Error; err != nil {
+		return nil, nil, "", c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Wiederkehrende Schicht nicht gefunden",
+		})
+	}
+
+	var shift models.Shift
+	if err := tenantDB(c).Where("recurring_shift_id = ?", recurringShift.ID).First(&shift, shiftID).Error; err != nil || shift.RecurrenceID == nil {
+		return nil, nil, "", c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Vorkommen nicht gefunden",
+		})
+	}
+
+	return &recurringShift, &shift, scope, nil
+}
+
+// applyOccurrenceRequestToShift überträgt die Änderungen auf eine einzelne Schicht
+func applyOccurrenceRequestToShift(shift *models.Shift, request recurringOccurrenceRequest) {
+	if request.UserID != 0 {
+		shift.UserID = request.UserID
+	}
+	if request.ShiftTypeID != 0 {
+		shiftTypeID := request.ShiftTypeID
+		shift.ShiftTypeID = &shiftTypeID
+	}
+	shift.StartTime = request.StartTime
+	shift.EndTime = request.EndTime
+	if request.BreakTime != nil {
+		shift.BreakTime = *request.BreakTime
+	}
+	if request.Description != nil {
+		shift.Description = *request.Description
+	}
+}
+
+// updateWholeSeries überträgt die Änderungen eines Vorkommens auf die gesamte Serie.
+// Die Zeitverschiebung gegenüber dem ursprünglichen Vorkommen wird auf den Serienbeginn angewendet.
+func updateWholeSeries(tx *gorm.DB, recurringShift *models.RecurringShift, shift *models.Shift, request recurringOccurrenceRequest) error {
+	offset := request.StartTime.Sub(*shift.RecurrenceID)
+	recurringShift.StartTime = recurringShift.StartTime.Add(offset)
+	recurringShift.EndTime = recurringShift.StartTime.Add(request.EndTime.Sub(request.StartTime))
+	if request.UserID != 0 {
+		recurringShift.UserID = request.UserID
+	}
+	if request.ShiftTypeID != 0 {
+		recurringShift.ShiftTypeID = request.ShiftTypeID
+	}
+	if request.BreakTime != nil {
+		recurringShift.BreakTime = *request.BreakTime
+	}
+	if request.Description != nil {
+		recurringShift.Description = *request.Description
+	}
+
+	if err := tx.Save(recurringShift).Error; err != nil {
+		return err
+	}
+	return syncRecurringShiftInstances(tx, recurringShift)
+}
+
+// splitRecurringShift beendet eine Serie vor dem Vorkommen at und legt für den Rest eine Nachfolgeserie an.
+// Ausnahmen und Vorkommen ab at werden der Nachfolgeserie zugeordnet.
+func splitRecurringShift(tx *gorm.DB, recurringShift *models.RecurringShift, at time.Time) (*models.RecurringShift, error) {
+	rule, err := services.RecurrenceRuleFor(*recurringShift)
+	if err != nil {
+		return nil, err
+	}
+
+	successor := *recurringShift
+	successor.Base = models.Base{}
+	successor.Exceptions = nil
+	successor.Shifts = nil
+	successor.EndTime = at.Add(recurringShift.EndTime.Sub(recurringShift.StartTime))
+	successor.StartTime = at
+
+	if rule.Count > 0 {
+		// Bei begrenzter Anzahl wird die Anzahl auf beide Serien aufgeteilt
+		before, err := services.CountOccurrencesBefore(*recurringShift, at)
+		if err != nil {
+			return nil, err
+		}
+		recurringShift.Count = before
+		successor.Count = rule.Count - before
+	} else {
+		until := at.Add(-time.Second)
+		recurringShift.Until = &until
+	}
+
+	if err := tx.Save(recurringShift).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Create(&successor).Error; err != nil {
+		return nil, err
+	}
+
+	// Zeitpunkte in Go vergleichen: in SQLite werden Zeitpunkte mit Zeitzonenversatz als Text verglichen
+	var exceptions []models.RecurringShiftException
+	if err := tx.Where("recurring_shift_id = ?", recurringShift.ID).Find(&exceptions).Error; err != nil {
+		return nil, err
+	}
+	exceptionIDs := make([]uint, 0, len(exceptions))
+	for _, exception := range exceptions {
+		if !exception.Date.Before(at) {
+			exceptionIDs = append(exceptionIDs, exception.ID)
+		}
+	}
+	if len(exceptionIDs) > 0 {
+		if err := tx.Model(&models.RecurringShiftException{}).Where("id IN ?", exceptionIDs).
+			Update("recurring_shift_id", successor.ID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var instances []models.Shift
+	if err := tx.Where("recurring_shift_id = ?", recurringShift.ID).Find(&instances).Error; err != nil {
+		return nil, err
+	}
+	shiftIDs := make([]uint, 0, len(instances))
+	for _, instance := range instances {
+		if instance.RecurrenceID != nil && !instance.RecurrenceID.Before(at) {
+			shiftIDs = append(shiftIDs, instance.ID)
+		}
+	}
+	if len(shiftIDs) > 0 {
+		if err := tx.Model(&models.Shift{}).Where("id IN ?", shiftIDs).
+			Update("recurring_shift_id", successor.ID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &successor, nil
+}
+
+// deleteRecurringShiftSeries löscht eine Serie inklusive aller Vorkommen und Ausnahmen
+func deleteRecurringShiftSeries(tx *gorm.DB, recurringShift *models.RecurringShift) error {
+	var instances []models.Shift
+	if err := tx.Where("recurring_shift_id = ?", recurringShift.ID).Find(&instances).Error; err != nil {
+		return err
+	}
+	if err := checkShiftLocks(tx, instances...); err != nil {
+		return err
+	}
+	if err := tx.Where("recurring_shift_id = ?", recurringShift.ID).Delete(&models.Shift{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("recurring_shift_id = ?", recurringShift.ID).Delete(&models.RecurringShiftException{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(recurringShift).Error
+}
+
+// syncRecurringShiftInstances gleicht die gespeicherten Schichten einer Serie mit ihren berechneten
+// Vorkommen im Zeitraum des Schichtplans ab. Einzeln bearbeitete Vorkommen bleiben unverändert.
+func syncRecurringShiftInstances(tx *gorm.DB, recurringShift *models.RecurringShift) error {
+	var schedule models.Schedule
+	if err := tx.First(&schedule, recurringShift.ScheduleID).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("recurring_shift_id = ?", recurringShift.ID).Find(&recurringShift.Exceptions).Error; err != nil {
+		return err
+	}
+
+	expected, err := services.ExpandRecurringShift(*recurringShift, schedule.StartDate, schedule.EndDate)
+	if err != nil {
+		return err
+	}
+
+	var existing []models.Shift
+	if err := tx.Where("recurring_shift_id = ?", recurringShift.ID).Find(&existing).Error; err != nil {
+		return err
+	}
+
+	byRecurrenceID := make(map[int64]*models.Shift, len(existing))
+	for i := range existing {
+		if existing[i].RecurrenceID != nil {
+			byRecurrenceID[existing[i].RecurrenceID.Unix()] = &existing[i]
+		}
+	}
+
+	// Zuerst alle Änderungen bestimmen, damit freigegebene Monate vor dem Speichern geprüft werden können
+	var creates, updates, deletes, touched []models.Shift
+	seen := make(map[uint]bool, len(existing))
+	for _, shift := range expected {
+		// RECURRENCE-ID in UTC speichern, damit Vergleiche in der Datenbank nicht vom Zeitzonenversatz abhängen
+		recurrenceID := shift.RecurrenceID.UTC()
+		shift.RecurrenceID = &recurrenceID
+		current, ok := byRecurrenceID[shift.RecurrenceID.Unix()]
+		if !ok {
+			creates = append(creates, shift)
+			touched = append(touched, shift)
+			continue
+		}
+
+		seen[current.ID] = true
+		if current.RecurrenceModified || !recurringInstanceChanged(*current, shift) {
+			continue
+		}
+		shift.Base = current.Base
+		updates = append(updates, shift)
+		touched = append(touched, *current, shift)
+	}
+
+	for _, shift := range existing {
+		if !seen[shift.ID] && !shift.RecurrenceModified {
+			deletes = append(deletes, shift)
+			touched = append(touched, shift)
+		}
+	}
+
+	if err := checkShiftLocks(tx, touched...); err != nil {
+		return err
+	}
+	if err := checkShiftQualifications(tx, recurringShift, append(creates, updates...)...); err != nil {
+		return err
+	}
+
+	for i := range creates {
+		if err := tx.Create(&creates[i]).Error; err != nil {
+			return err
+		}
+	}
+	for i := range updates {
+		if err := tx.Save(&updates[i]).Error; err != nil {
+			return err
+		}
+	}
+	for i := range deletes {
+		if err := tx.Delete(&deletes[i]).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recurringInstanceChanged prüft, ob sich ein neu berechnetes Vorkommen von der gespeicherten Schicht unterscheidet
+func recurringInstanceChanged(current, expected models.Shift) bool {
+	sameShiftType := (current.ShiftTypeID == nil) == (expected.ShiftTypeID == nil) &&
+		(current.ShiftTypeID == nil || *current.ShiftTypeID == *expected.ShiftTypeID)
+	return !sameShiftType ||
+		current.UserID != expected.UserID ||
+		current.ScheduleID != expected.ScheduleID ||
+		!current.StartTime.Equal(expected.StartTime) ||
+		!current.EndTime.Equal(expected.EndTime) ||
+		current.BreakTime != expected.BreakTime ||
+		current.Description != expected.Description ||
+		current.IsActive != expected.IsActive
+}
+
+// checkShiftLocks liefert errTimesheetLocked, wenn eine der Schichten in einem freigegebenen Monat ihres Benutzers liegt
+func checkShiftLocks(tx *gorm.DB, shifts ...models.Shift) error {
+	times := make(map[uint][]time.Time)
+	for _, shift := range shifts {
+		times[shift.UserID] = append(times[shift.UserID], shift.StartTime)
+	}
+	for userID, userTimes := range times {
+		if timesheetLockMessage(tx, userID, userTimes...) != "" {
+			return errTimesheetLocked
+		}
+	}
+	return nil
+}
+
+// blockedOccurrenceError meldet ein Vorkommen, dessen Benutzer eine Qualifikation mit Durchsetzung "block" fehlt
+type blockedOccurrenceError struct {
+	message string
+}
+
+func (e *blockedOccurrenceError) Error() string {
+	return e.message
+}
+
+// checkShiftQualifications liefert einen blockedOccurrenceError für das erste Vorkommen mit blockierendem
+// Qualifikationsverstoß; die Meldung nennt den Tag in der Zeitzone der Serie
+func checkShiftQualifications(tx *gorm.DB, recurringShift *models.RecurringShift, shifts ...models.Shift) error {
+	location, err := models.LoadLocation(recurringShift.TimeZone)
+	if err != nil {
+		return err
+	}
+	for _, shift := range shifts {
+		violations, err := shiftQualificationViolations(tx, shift)
+		if err != nil {
+			return err
+		}
+		if message := blockingViolation(violations); message != "" {
+			return &blockedOccurrenceError{message: shift.StartTime.In(location).Format("02.01.2006") + ": " + message}
+		}
+	}
+	return nil
+}
+
+// respondRecurringShiftError antwortet auf einen Fehler beim Speichern einer Serie. Änderungen in freigegebenen
+// Monaten werden mit 409, fehlende Qualifikationen mit 400 abgelehnt, alle anderen Fehler mit der übergebenen Meldung.
+func respondRecurringShiftError(c echo.Context, err error, message string) error {
+	if errors.Is(err, errTimesheetLocked) {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	var blocked *blockedOccurrenceError
+	if errors.As(err, &blocked) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": blocked.Error(),
+		})
+	}
+	return c.JSON(http.StatusInternalServerError, map[string]string{
+		"error": message,
+	})
+}
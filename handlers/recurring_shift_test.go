@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// createRecurringShiftFixture legt Benutzer, Schichttyp, Schichtplan und eine Serie über den Handler an
+func createRecurringShiftFixture(t *testing.T, rrule string) models.RecurringShift {
+	user := models.User{Username: "serie", Email: "serie@example.com", Password: "hashedpassword", Name: "Serien User"}
+	database.DB.Create(&user)
+
+	shiftType := models.ShiftType{Name: "Frühschicht"}
+	database.DB.Create(&shiftType)
+
+	schedule := models.Schedule{
+		Name:      "Januar 2024",
+		StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC),
+	}
+	database.DB.Create(&schedule)
+
+	body, _ := json.Marshal(models.RecurringShift{
+		UserID:      user.ID,
+		ShiftTypeID: shiftType.ID,
+		ScheduleID:  schedule.ID,
+		RRule:       rrule,
+		StartTime:   time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC),
+		EndTime:     time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC),
+		IsActive:    true,
+	})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/recurring-shifts", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	assert.NoError(t, CreateRecurringShift(c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var recurringShift models.RecurringShift
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &recurringShift))
+	return recurringShift
+}
+
+// recurringShiftInstances lädt die gespeicherten Vorkommen einer Serie
+func recurringShiftInstances(recurringShiftID uint) []models.Shift {
+	var shifts []models.Shift
+	database.DB.Where("recurring_shift_id = ?", recurringShiftID).Order("start_time ASC").Find(&shifts)
+	return shifts
+}
+
+func TestCreateRecurringShift_ExpandsIntoSchedule(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	recurringShift := createRecurringShiftFixture(t, "FREQ=WEEKLY;BYDAY=MO")
+
+	// Montage im Januar 2024: 1., 8., 15., 22., 29.
+	shifts := recurringShiftInstances(recurringShift.ID)
+	assert.Len(t, shifts, 5)
+	assert.Equal(t, 8*time.Hour, shifts[0].EndTime.Sub(shifts[0].StartTime))
+}
+
+func TestCreateRecurringShift_InvalidRRule(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	body, _ := json.Marshal(models.RecurringShift{
+		UserID:      1,
+		ShiftTypeID: 1,
+		ScheduleID:  1,
+		RRule:       "FREQ=SOMETIMES",
+		StartTime:   time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC),
+		EndTime:     time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC),
+	})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/recurring-shifts", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, CreateRecurringShift(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		// Nach einem Validierungsfehler darf keine Serie gespeichert werden
+		var count int64
+		database.DB.Model(&models.RecurringShift{}).Count(&count)
+		assert.Zero(t, count)
+	}
+}
+
+func TestDeleteRecurringShiftOccurrence_ThisCreatesException(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	recurringShift := createRecurringShiftFixture(t, "FREQ=WEEKLY;BYDAY=MO")
+	shifts := recurringShiftInstances(recurringShift.ID)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/?scope=this", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "shift_id")
+	c.SetParamValues(strconv.Itoa(int(recurringShift.ID)), strconv.Itoa(int(shifts[1].ID)))
+
+	if assert.NoError(t, DeleteRecurringShiftOccurrence(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	var exceptions []models.RecurringShiftException
+	database.DB.Where("recurring_shift_id = ?", recurringShift.ID).Find(&exceptions)
+	assert.Len(t, exceptions, 1)
+	assert.Len(t, recurringShiftInstances(recurringShift.ID), 4)
+
+	// Eine erneute Expansion darf das ausgeschlossene Vorkommen nicht wiederherstellen
+	req = httptest.NewRequest(http.MethodPost, "/", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(recurringShift.ID)))
+
+	if assert.NoError(t, ExpandRecurringShift(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	assert.Len(t, recurringShiftInstances(recurringShift.ID), 4)
+}
+
+func TestUpdateRecurringShiftOccurrence_FollowingSplitsSeries(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	recurringShift := createRecurringShiftFixture(t, "FREQ=WEEKLY;BYDAY=MO")
+	shifts := recurringShiftInstances(recurringShift.ID)
+
+	// Ab dem 15.01. beginnt die Schicht zwei Stunden später
+	body, _ := json.Marshal(map[string]interface{}{
+		"start_time": time.Date(2024, 1, 15, 8, 0, 0, 0, time.UTC),
+		"end_time":   time.Date(2024, 1, 15, 16, 0, 0, 0, time.UTC),
+	})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/?scope=following", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "shift_id")
+	c.SetParamValues(strconv.Itoa(int(recurringShift.ID)), strconv.Itoa(int(shifts[2].ID)))
+
+	if assert.NoError(t, UpdateRecurringShiftOccurrence(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	var series []models.RecurringShift
+	database.DB.Order("id ASC").Find(&series)
+	assert.Len(t, series, 2)
+	assert.NotNil(t, series[0].Until)
+
+	original := recurringShiftInstances(series[0].ID)
+	successor := recurringShiftInstances(series[1].ID)
+	assert.Len(t, original, 2)
+	assert.Len(t, successor, 3)
+	assert.Equal(t, 6, original[1].StartTime.UTC().Hour())
+	for _, shift := range successor {
+		assert.Equal(t, 8, shift.StartTime.UTC().Hour())
+	}
+}
+
+// postRecurringShiftException führt CreateRecurringShiftException mit einem JSON-Rumpf aus
+func postRecurringShiftException(t *testing.T, recurringShiftID uint, body string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(recurringShiftID)))
+	assert.NoError(t, CreateRecurringShiftException(c))
+	return rec
+}
+
+func TestCreateRecurringShiftException(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	recurringShift := createRecurringShiftFixture(t, "FREQ=WEEKLY;BYDAY=MO")
+	assert.Len(t, recurringShiftInstances(recurringShift.ID), 5)
+
+	// Ohne Datum wird nichts gespeichert
+	rec := postRecurringShiftException(t, recurringShift.ID, `{}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"error":"Datum ist ein Pflichtfeld"}`, rec.Body.String())
+	var count int64
+	database.DB.Model(&models.RecurringShiftException{}).Count(&count)
+	assert.Zero(t, count)
+
+	// Das Vorkommen wird unabhängig vom Zeitzonenversatz der Angabe gefunden
+	rec = postRecurringShiftException(t, recurringShift.ID, `{"date":"2024-01-08T06:00:00Z"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Len(t, recurringShiftInstances(recurringShift.ID), 4)
+
+	rec = postRecurringShiftException(t, recurringShift.ID, `{"date":"2024-01-22T11:30:00+05:30"}`)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	shifts := recurringShiftInstances(recurringShift.ID)
+	if assert.Len(t, shifts, 3) {
+		for _, shift := range shifts {
+			assert.NotEqual(t, 8, shift.StartTime.Day())
+			assert.NotEqual(t, 22, shift.StartTime.Day())
+		}
+	}
+
+	// Auch eine erneute Expansion stellt die Vorkommen nicht wieder her
+	e := echo.New()
+	rec = httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(recurringShift.ID)))
+	if assert.NoError(t, ExpandRecurringShift(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+	assert.Len(t, recurringShiftInstances(recurringShift.ID), 3)
+}
+
+func TestDeleteRecurringShiftOccurrence_FollowingKeepsOrderAcrossOffsets(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	recurringShift := createRecurringShiftFixture(t, "FREQ=WEEKLY;BYDAY=MO")
+	// Ausnahme vor und nach dem Teilungszeitpunkt, mit unterschiedlichem Zeitzonenversatz angegeben
+	assert.Equal(t, http.StatusCreated, postRecurringShiftException(t, recurringShift.ID, `{"date":"2024-01-08T07:00:00+01:00"}`).Code)
+	assert.Equal(t, http.StatusCreated, postRecurringShiftException(t, recurringShift.ID, `{"date":"2024-01-22T01:00:00-05:00"}`).Code)
+	shifts := recurringShiftInstances(recurringShift.ID)
+	assert.Len(t, shifts, 3)
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodDelete, "/?scope=following", nil), rec)
+	c.SetParamNames("id", "shift_id")
+	c.SetParamValues(strconv.Itoa(int(recurringShift.ID)), strconv.Itoa(int(shifts[1].ID)))
+	if assert.NoError(t, DeleteRecurringShiftOccurrence(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	// Nur das Vorkommen vom 01.01. und die Ausnahme vom 08.01. bleiben bei der ursprünglichen Serie
+	remaining := recurringShiftInstances(recurringShift.ID)
+	if assert.Len(t, remaining, 1) {
+		assert.Equal(t, 1, remaining[0].StartTime.Day())
+	}
+	var exceptions []models.RecurringShiftException
+	database.DB.Where("recurring_shift_id = ?", recurringShift.ID).Find(&exceptions)
+	if assert.Len(t, exceptions, 1) {
+		assert.True(t, exceptions[0].Date.Equal(time.Date(2024, 1, 8, 6, 0, 0, 0, time.UTC)))
+	}
+	var total int64
+	database.DB.Model(&models.Shift{}).Count(&total)
+	assert.Equal(t, int64(1), total)
+}
+
+func TestRecurringShift_ApprovedMonthIsLocked(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	recurringShift := createRecurringShiftFixture(t, "FREQ=WEEKLY;BYDAY=MO")
+	shifts := recurringShiftInstances(recurringShift.ID)
+	database.DB.Create(&models.Timesheet{UserID: recurringShift.UserID, Month: "2024-01", Status: models.TimesheetStatusApproved})
+
+	e := echo.New()
+	id := strconv.Itoa(int(recurringShift.ID))
+
+	// Ohne Änderung an den Vorkommen bleibt die Expansion möglich
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	if assert.NoError(t, ExpandRecurringShift(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	updated := recurringShift
+	updated.StartTime = recurringShift.StartTime.Add(time.Hour)
+	updated.EndTime = recurringShift.EndTime.Add(time.Hour)
+	body, _ := json.Marshal(updated)
+	req = httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	if assert.NoError(t, UpdateRecurringShift(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	if assert.NoError(t, DeleteRecurringShift(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	}
+
+	for _, scope := range []string{RecurrenceScopeThis, RecurrenceScopeFollowing} {
+		req = httptest.NewRequest(http.MethodDelete, "/?scope="+scope, nil)
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		c.SetParamNames("id", "shift_id")
+		c.SetParamValues(id, strconv.Itoa(int(shifts[2].ID)))
+		if assert.NoError(t, DeleteRecurringShiftOccurrence(c)) {
+			assert.Equal(t, http.StatusConflict, rec.Code, scope)
+		}
+	}
+
+	rec = postRecurringShiftException(t, recurringShift.ID, `{"date":"2024-01-08T06:00:00Z"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// Alle Änderungen wurden zurückgerollt
+	var series, exceptions int64
+	database.DB.Model(&models.RecurringShift{}).Count(&series)
+	database.DB.Model(&models.RecurringShiftException{}).Count(&exceptions)
+	assert.Equal(t, int64(1), series)
+	assert.Zero(t, exceptions)
+	instances := recurringShiftInstances(recurringShift.ID)
+	if assert.Len(t, instances, 5) {
+		assert.Equal(t, 6, instances[0].StartTime.UTC().Hour())
+	}
+}
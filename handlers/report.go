@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// varianceTypeLabels sind die Bezeichnungen der Abweichungsarten im CSV-Export
+var varianceTypeLabels = map[string]string{
+	models.VarianceLateStart:     "Verspäteter Beginn",
+	models.VarianceEarlyLeave:    "Vorzeitiges Gehen",
+	models.VarianceUnplannedWork: "Ungeplante Arbeit",
+	models.VarianceNoShow:        "Nicht angetreten",
+}
+
+// GetVarianceReport vergleicht geplante Schichten mit den Zeitbuchungen im Zeitraum [from, to) (RFC3339, Standard:
+// aktueller Monat) und meldet verspäteten Beginn, vorzeitiges Gehen, ungeplante Arbeit und nicht angetretene Schichten.
+// Optionale Filter: user_id, team_id, type; tolerance_minutes legt die Toleranz fest (Standard: 5).
+// Mit format=csv oder Accept: text/csv wird eine CSV-Datei geliefert.
+func GetVarianceReport(c echo.Context) error {
+	orgLoc := models.OrganisationLocation()
+	now := time.Now().In(orgLoc)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, orgLoc)
+	from, to, message := periodFromQuery(c, monthStart, monthStart.AddDate(0, 1, 0))
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	tolerance := services.DefaultVarianceToleranceMinutes
+	if value := c.QueryParam("tolerance_minutes"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > 240 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Toleranz (tolerance_minutes) muss zwischen 0 und 240 Minuten liegen",
+			})
+		}
+		tolerance = parsed
+	}
+	varianceType := c.QueryParam("type")
+	if varianceType != "" && varianceTypeLabels[varianceType] == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Unbekannte Abweichungsart (type)",
+		})
+	}
+
+	userQuery := tenantDB(c).Preload("Team")
+	if value := c.QueryParam("user_id"); value != "" {
+		userID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültige Benutzer-ID",
+			})
+		}
+		userQuery = userQuery.Where("id = ?", userID)
+	}
+	if value := c.QueryParam("team_id"); value != "" {
+		teamID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültige Team-ID",
+			})
+		}
+		userQuery = userQuery.Where("team_id = ?", teamID)
+	}
+	// Benutzer ohne Freigaberechte sehen nur ihre eigenen Abweichungen
+	if user := currentUser(c); user != nil && !user.CanApprove() {
+		userQuery = userQuery.Where("id = ?", user.ID)
+	}
+
+	var users []models.User
+	if err := userQuery.Find(&users).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Benutzer",
+		})
+	}
+	userIDs := make([]uint, 0, len(users))
+	usersByID := make(map[uint]models.User, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.ID)
+		usersByID[user.ID] = user
+	}
+
+	var shifts []models.Shift
+	if err := tenantDB(c).Where("user_id IN ? AND start_time >= ? AND start_time < ?", userIDs, from, to).
+		Order("start_time ASC").Find(&shifts).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Schichten",
+		})
+	}
+	// Kommen bis zu ShiftMatchLeadTime vor Beginn der ersten Schicht gehört noch zum Zeitraum
+	var entries []models.TimeEntry
+	if err := tenantDB(c).Preload("Breaks").
+		Where("user_id IN ? AND clock_in >= ? AND clock_in < ?", userIDs, from.Add(-services.ShiftMatchLeadTime), to).
+		Order("clock_in ASC").Find(&entries).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Zeitbuchungen",
+		})
+	}
+
+	// Genehmigte Abwesenheiten entschuldigen geplante Schichten; Kalendertage in UTC, daher einen Tag Puffer
+	var absences []models.Absence
+	if err := tenantDB(c).Where("user_id IN ? AND status = ? AND start_date <= ? AND end_date >= ?", userIDs, models.AbsenceStatusApproved, to, from.AddDate(0, 0, -1)).
+		Find(&absences).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Abwesenheiten",
+		})
+	}
+
+	variances := make([]models.Variance, 0)
+	for _, variance := range services.PlannedActualVariances(shifts, entries, absences, usersByID, time.Duration(tolerance)*time.Minute, time.Now()) {
+		// Ungeplante Arbeit vor dem Zeitraum wurde nur für die Zuordnung geladen
+		if variance.Type == models.VarianceUnplannedWork && variance.ActualStart.Before(from) {
+			continue
+		}
+		if varianceType == "" || variance.Type == varianceType {
+			variances = append(variances, variance)
+		}
+	}
+
+	if wantsCSV(c) {
+		rows := [][]string{{"Datum", "Personalnummer", "Benutzer", "Team", "Art", "Soll-Beginn", "Soll-Ende", "Ist-Beginn", "Ist-Ende", "Minuten"}}
+		for _, variance := range variances {
+			loc := usersByID[variance.UserID].Location()
+			rows = append(rows, []string{
+				variance.Date.Format("02.01.2006"),
+				variance.AccountNumber,
+				variance.UserName,
+				variance.TeamName,
+				varianceTypeLabels[variance.Type],
+				csvTime(variance.PlannedStart, loc),
+				csvTime(variance.PlannedEnd, loc),
+				csvTime(variance.ActualStart, loc),
+				csvTime(variance.ActualEnd, loc),
+				strconv.Itoa(variance.Minutes),
+			})
+		}
+		return respondCSV(c, fmt.Sprintf("abweichungen_%s_%s.csv", from.Format("2006-01-02"), to.Format("2006-01-02")), rows)
+	}
+
+	report := models.VarianceReport{From: from, To: to, ToleranceMinutes: tolerance, Variances: variances}
+	report.ByUser, report.ByTeam, report.ByDay = services.SummarizeVariances(variances)
+	return c.JSON(http.StatusOK, report)
+}
+
+// wantsCSV prüft, ob die Antwort als CSV gewünscht ist (format=csv oder Accept: text/csv)
+func wantsCSV(c echo.Context) bool {
+	if format := c.QueryParam("format"); format != "" {
+		return strings.EqualFold(format, "csv")
+	}
+	return strings.Contains(c.Request().Header.Get(echo.HeaderAccept), "text/csv")
+}
+
+// Antwortformate für Exporte, die neben CSV auch Excel-Arbeitsmappen anbieten
+const (
+	exportFormatJSON = "json"
+	exportFormatCSV  = "csv"
+	exportFormatXLSX = "xlsx"
+)
+
+// xlsxContentType ist der Medientyp von Excel-Arbeitsmappen
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// exportFormat liefert das gewünschte Antwortformat: format=csv/xlsx oder Accept: text/csv bzw. der Medientyp von
+// Excel-Arbeitsmappen; sonst JSON. Unbekannte Werte von format ergeben einen leeren String.
+func exportFormat(c echo.Context) string {
+	switch format := strings.ToLower(c.QueryParam("format")); format {
+	case "":
+	case exportFormatJSON, exportFormatCSV, exportFormatXLSX:
+		return format
+	default:
+		return ""
+	}
+	accept := c.Request().Header.Get(echo.HeaderAccept)
+	switch {
+	case strings.Contains(accept, xlsxContentType):
+		return exportFormatXLSX
+	case strings.Contains(accept, "text/csv"):
+		return exportFormatCSV
+	}
+	return exportFormatJSON
+}
+
+// respondXLSX liefert eine Excel-Arbeitsmappe als Download
+func respondXLSX(c echo.Context, filename string, data []byte) error {
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Blob(http.StatusOK, xlsxContentType, data)
+}
+
+// respondCSV liefert rows als CSV-Datei mit Semikolon als Trennzeichen und UTF-8-BOM, damit Tabellenkalkulationen
+// Umlaute und Spalten korrekt erkennen
+func respondCSV(c echo.Context, filename string, rows [][]string) error {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	writer := csv.NewWriter(&buf)
+	writer.Comma = ';'
+	if err := writer.WriteAll(rows); err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen der CSV-Datei",
+		})
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.Blob(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// csvTime formatiert einen optionalen Zeitpunkt in der Zeitzone loc für den CSV-Export
+func csvTime(t *time.Time, loc *time.Location) string {
+	if t == nil {
+		return ""
+	}
+	return t.In(loc).Format("02.01.2006 15:04")
+}
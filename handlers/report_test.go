@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetVarianceReport(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "abweichung", Email: "abweichung@example.com", Password: "x", AccountNumber: "A1", Name: "Abweichung User", TimeZone: "UTC", IsActive: true}
+	database.DB.Create(&user)
+	schedule := models.Schedule{Name: "März", StartDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&schedule)
+	late := models.Shift{UserID: user.ID, ScheduleID: schedule.ID, StartTime: time.Date(2024, 3, 4, 6, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC)}
+	missed := models.Shift{UserID: user.ID, ScheduleID: schedule.ID, StartTime: time.Date(2024, 3, 5, 6, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)}
+	database.DB.Create(&late)
+	database.DB.Create(&missed)
+	clockOut := time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC)
+	database.DB.Create(&models.TimeEntry{UserID: user.ID, ShiftID: &late.ID, ClockIn: time.Date(2024, 3, 4, 6, 10, 0, 0, time.UTC), ClockOut: &clockOut, Source: models.TimeEntrySourceClock})
+
+	request := func(query, accept string) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/reports/variances?from=2024-03-01T00:00:00Z&to=2024-04-01T00:00:00Z"+query, nil)
+		if accept != "" {
+			req.Header.Set(echo.HeaderAccept, accept)
+		}
+		rec := httptest.NewRecorder()
+		assert.NoError(t, GetVarianceReport(e.NewContext(req, rec)))
+		return rec
+	}
+
+	rec := request("", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var report models.VarianceReport
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, 5, report.ToleranceMinutes)
+	if assert.Len(t, report.Variances, 2) {
+		assert.Equal(t, models.VarianceLateStart, report.Variances[0].Type)
+		assert.Equal(t, 10, report.Variances[0].Minutes)
+		assert.Equal(t, models.VarianceNoShow, report.Variances[1].Type)
+	}
+	assert.Len(t, report.ByDay, 2)
+
+	// Größere Toleranz und Filter nach Art
+	assert.NoError(t, json.Unmarshal(request("&tolerance_minutes=15", "").Body.Bytes(), &report))
+	assert.Len(t, report.Variances, 1)
+	assert.NoError(t, json.Unmarshal(request("&type=late_start", "").Body.Bytes(), &report))
+	assert.Len(t, report.Variances, 1)
+
+	assert.Equal(t, http.StatusBadRequest, request("&tolerance_minutes=-1", "").Code)
+	assert.Equal(t, http.StatusBadRequest, request("&type=unbekannt", "").Code)
+
+	// CSV über format oder Accept-Header
+	rec = request("&format=csv", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentType), "text/csv")
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.Contains(t, lines[0], "Datum;Personalnummer;Benutzer")
+		assert.Contains(t, lines[1], "04.03.2024;A1;Abweichung User;;Verspäteter Beginn;04.03.2024 06:00")
+	}
+	assert.Contains(t, request("", "text/csv").Header().Get(echo.HeaderContentDisposition), "abweichungen_2024-03-01_2024-04-01.csv")
+
+	// Genehmigte Krankmeldung: die Schicht gilt nicht als nicht angetreten
+	day := time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC)
+	database.DB.Create(&models.Absence{UserID: user.ID, Type: models.AbsenceTypeSick, Status: models.AbsenceStatusApproved, StartDate: day, EndDate: day})
+	assert.NoError(t, json.Unmarshal(request("", "").Body.Bytes(), &report))
+	if assert.Len(t, report.Variances, 1) {
+		assert.Equal(t, models.VarianceLateStart, report.Variances[0].Type)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"net/http"
 	"strconv"
 
-	"schichtplaner/database"
 	"schichtplaner/models"
 	"schichtplaner/utils"
 
@@ -18,11 +17,23 @@ func GetSchedules(c echo.Context) error {
 	var schedules []models.Schedule
 	var total int64
 
+	// Optionaler Standortfilter
+	query := tenantDB(c).Model(&models.Schedule{})
+	locationID, message := locationIDFromQuery(c)
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	if locationID != 0 {
+		query = query.Where("location_id = ?", locationID)
+	}
+
 	// Zähle die Gesamtanzahl
-	database.DB.Model(&models.Schedule{}).Count(&total)
+	query.Count(&total)
 
 	// Lade die paginierten Daten mit Preloads
-	if err := database.DB.Preload("Shifts").Offset(params.Offset).Limit(params.PageSize).Find(&schedules).Error; err != nil {
+	if err := query.Preload("Location").Preload("Shifts").Offset(params.Offset).Limit(params.PageSize).Find(&schedules).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Laden der Schichtpläne",
 		})
@@ -42,12 +53,18 @@ func GetSchedule(c echo.Context) error {
 	}
 
 	var schedule models.Schedule
-	if err := database.DB.Preload("Shifts.User").First(&schedule, id).Error; err != nil {
+	if err := tenantDB(c).Preload("Location").Preload("Shifts.User").First(&schedule, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Schichtplan nicht gefunden",
 		})
 	}
 
+	// Feiertage im Zeitraum und Schichten an Feiertagen kennzeichnen
+	if calendar, err := loadHolidayCalendar(tenantDB(c), models.OrganisationState()); err == nil {
+		schedule.Holidays = holidaysBetween(calendar, schedule.StartDate, schedule.EndDate, models.OrganisationLocation())
+		markShiftHolidays(calendar, schedule.Shifts)
+	}
+
 	return c.JSON(http.StatusOK, schedule)
 }
 
@@ -68,11 +85,18 @@ func CreateSchedule(c echo.Context) error {
 	validator.RequiredTime("EndDate", schedule.EndDate, "Enddatum ist ein Pflichtfeld")
 	validator.TimeRange("StartDate", "EndDate", schedule.StartDate, schedule.EndDate, "Startdatum muss vor Enddatum liegen")
 
-	if err := validator.ValidateAndRespond(c); err != nil {
-		return err
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+	if message := validateLocationReference(tenantDB(c), schedule.LocationID); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
 	}
 
-	if err := database.DB.Create(&schedule).Error; err != nil {
+	if err := tenantDB(c).Create(&schedule).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Erstellen des Schichtplans",
 		})
@@ -91,7 +115,7 @@ func UpdateSchedule(c echo.Context) error {
 	}
 
 	var schedule models.Schedule
-	if err := database.DB.First(&schedule, id).Error; err != nil {
+	if err := tenantDB(c).First(&schedule, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Schichtplan nicht gefunden",
 		})
@@ -111,11 +135,18 @@ func UpdateSchedule(c echo.Context) error {
 	validator.RequiredTime("EndDate", updateData.EndDate, "Enddatum ist ein Pflichtfeld")
 	validator.TimeRange("StartDate", "EndDate", updateData.StartDate, updateData.EndDate, "Startdatum muss vor Enddatum liegen")
 
-	if err := validator.ValidateAndRespond(c); err != nil {
-		return err
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+	if message := validateLocationReference(tenantDB(c), updateData.LocationID); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
 	}
 
-	if err := database.DB.Model(&schedule).Updates(updateData).Error; err != nil {
+	if err := tenantDB(c).Model(&schedule).Updates(updateData).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Aktualisieren des Schichtplans",
 		})
@@ -134,13 +165,13 @@ func DeleteSchedule(c echo.Context) error {
 	}
 
 	var schedule models.Schedule
-	if err := database.DB.First(&schedule, id).Error; err != nil {
+	if err := tenantDB(c).First(&schedule, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Schichtplan nicht gefunden",
 		})
 	}
 
-	if err := database.DB.Delete(&schedule).Error; err != nil {
+	if err := tenantDB(c).Delete(&schedule).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Löschen des Schichtplans",
 		})
@@ -159,10 +190,10 @@ func GetActiveSchedules(c echo.Context) error {
 	var total int64
 
 	// Zähle die Gesamtanzahl der aktiven Schichtpläne
-	database.DB.Model(&models.Schedule{}).Where("is_active = ?", true).Count(&total)
+	tenantDB(c).Model(&models.Schedule{}).Where("is_active = ?", true).Count(&total)
 
 	// Lade die paginierten Daten
-	if err := database.DB.Where("is_active = ?", true).Preload("Shifts").Offset(params.Offset).Limit(params.PageSize).Find(&schedules).Error; err != nil {
+	if err := tenantDB(c).Where("is_active = ?", true).Preload("Shifts").Offset(params.Offset).Limit(params.PageSize).Find(&schedules).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Laden der aktiven Schichtpläne",
 		})
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetScheduleGrid liefert einen Schichtplan als Raster Benutzer × Tage mit den Kürzeln der Schichttypen und
+// Abwesenheiten, den Summen je Benutzer und der Besetzung je Tag, gruppiert nach Teams. Zeilen gibt es für alle
+// aktiven Benutzer und für Benutzer mit Schichten im Plan; team_id beschränkt das Raster auf ein Team.
+// Mit format=csv/xlsx oder dem passenden Accept-Header wird eine Datei geliefert.
+func GetScheduleGrid(c echo.Context) error {
+	format := exportFormat(c)
+	if format == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Format muss json, csv oder xlsx sein",
+		})
+	}
+
+	schedule, grid, err := loadScheduleGrid(c)
+	if err != nil || schedule == nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("schichtplan_%d_%s", schedule.ID, schedule.StartDate.In(models.OrganisationLocation()).Format("2006-01-02"))
+	switch format {
+	case exportFormatCSV:
+		return respondCSV(c, filename+".csv", services.ScheduleGridRows(grid))
+	case exportFormatXLSX:
+		data, err := services.ScheduleGridXLSX(grid)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Fehler beim Erstellen der Excel-Datei",
+			})
+		}
+		return respondXLSX(c, filename+".xlsx", data)
+	}
+	return c.JSON(http.StatusOK, grid)
+}
+
+// GetSchedulePDF liefert den druckbaren Dienstplan als PDF (A4 quer) mit Teams, Kürzeln in den Farben der Schichttypen,
+// Legende und Unterschriftszeilen für Arbeitgeber und Betriebsrat. layout week druckt je Kalenderwoche, month je
+// Kalendermonat eine Tabelle; ohne Angabe wird bis sieben Tage week, sonst month verwendet. team_id wie beim Planraster.
+func GetSchedulePDF(c echo.Context) error {
+	layout := c.QueryParam("layout")
+	if layout != "" && layout != models.ScheduleGridLayoutWeek && layout != models.ScheduleGridLayoutMonth {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Layout muss week oder month sein",
+		})
+	}
+
+	schedule, grid, err := loadScheduleGrid(c)
+	if err != nil || schedule == nil {
+		return err
+	}
+	if layout == "" {
+		layout = models.ScheduleGridLayoutMonth
+		if len(grid.Days) <= 7 {
+			layout = models.ScheduleGridLayoutWeek
+		}
+	}
+
+	loc := models.OrganisationLocation()
+	data, err := services.ScheduleGridPDF(grid, layout, time.Now().In(loc))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen der PDF-Datei",
+		})
+	}
+
+	filename := fmt.Sprintf("dienstplan_%d_%s.pdf", schedule.ID, schedule.StartDate.In(loc).Format("2006-01-02"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("inline; filename=%q", filename))
+	return c.Blob(http.StatusOK, "application/pdf", data)
+}
+
+// loadScheduleGrid lädt den Schichtplan aus dem Pfad und erstellt sein Planraster: Zeilen für alle aktiven Benutzer und
+// für Benutzer mit Schichten im Plan, optional beschränkt auf team_id, mit genehmigten Abwesenheiten im Zeitraum
+func loadScheduleGrid(c echo.Context) (*models.Schedule, models.ScheduleGrid, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, models.ScheduleGrid{}, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Schichtplan-ID",
+		})
+	}
+
+	var schedule models.Schedule
+	if err := tenantDB(c).First(&schedule, id).Error; err != nil {
+		return nil, models.ScheduleGrid{}, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Schichtplan nicht gefunden",
+		})
+	}
+
+	var shifts []models.Shift
+	if err := tenantDB(c).Preload("ShiftType").Where("schedule_id = ?", schedule.ID).Find(&shifts).Error; err != nil {
+		return nil, models.ScheduleGrid{}, c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Schichten",
+		})
+	}
+	userIDs := make([]uint, 0, len(shifts))
+	for _, shift := range shifts {
+		userIDs = append(userIDs, shift.UserID)
+	}
+
+	query := tenantDB(c).Where("is_active = ? OR id IN ?", true, userIDs)
+	if teamID := c.QueryParam("team_id"); teamID != "" {
+		id, err := strconv.ParseUint(teamID, 10, 32)
+		if err != nil {
+			return nil, models.ScheduleGrid{}, c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültige Team-ID",
+			})
+		}
+		query = query.Where("team_id = ?", id)
+	}
+	var users []models.User
+	if err := query.Find(&users).Error; err != nil {
+		return nil, models.ScheduleGrid{}, c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Benutzer",
+		})
+	}
+
+	var teams []models.Team
+	if err := tenantDB(c).Find(&teams).Error; err != nil {
+		return nil, models.ScheduleGrid{}, c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Teams",
+		})
+	}
+
+	loc := models.OrganisationLocation()
+	var absences []models.Absence
+	if len(users) > 0 {
+		ids := make([]uint, len(users))
+		for i, user := range users {
+			ids[i] = user.ID
+		}
+		if err := tenantDB(c).
+			Where("user_id IN ? AND status = ? AND start_date <= ? AND end_date >= ?",
+				ids, models.AbsenceStatusApproved, calendarDay(schedule.EndDate), calendarDay(schedule.StartDate)).
+			Find(&absences).Error; err != nil {
+			return nil, models.ScheduleGrid{}, c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Fehler beim Laden der Abwesenheiten",
+			})
+		}
+	}
+
+	return &schedule, services.BuildScheduleGrid(schedule, users, teams, shifts, absences, loc), nil
+}
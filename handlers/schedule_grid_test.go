@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetScheduleGrid(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	team := models.Team{Name: "Pflege", Color: "#10B981"}
+	database.DB.Create(&team)
+	shiftType := models.ShiftType{Name: "Frühschicht", ShortCode: "F", Color: "#F59E0B"}
+	database.DB.Create(&shiftType)
+	user := models.User{Username: "raster", Email: "raster@example.com", Password: "x", AccountNumber: "R1", Name: "Raster User", TeamID: &team.ID, IsActive: true}
+	other := models.User{Username: "raster2", Email: "raster2@example.com", Password: "x", AccountNumber: "R2", Name: "Raster Zwei", IsActive: true}
+	database.DB.Create(&user)
+	database.DB.Create(&other)
+	schedule := models.Schedule{Name: "April", StartDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 4, 7, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&schedule)
+	database.DB.Create(&models.Shift{UserID: user.ID, ScheduleID: schedule.ID, ShiftTypeID: &shiftType.ID, StartTime: time.Date(2024, 4, 2, 6, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 4, 2, 14, 0, 0, 0, time.UTC)})
+	database.DB.Create(&models.Absence{UserID: other.ID, Type: models.AbsenceTypeSick, Status: models.AbsenceStatusApproved, StartDate: time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC)})
+	database.DB.Create(&models.Absence{UserID: other.ID, Type: models.AbsenceTypeVacation, Status: models.AbsenceStatusRequested, StartDate: time.Date(2024, 4, 4, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 4, 4, 0, 0, 0, 0, time.UTC)})
+
+	request := func(id uint, query, accept string) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/schedules/grid"+query, nil)
+		if accept != "" {
+			req.Header.Set(echo.HeaderAccept, accept)
+		}
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(strconv.FormatUint(uint64(id), 10))
+		assert.NoError(t, GetScheduleGrid(c))
+		return rec
+	}
+
+	rec := request(schedule.ID, "", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var grid models.ScheduleGrid
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &grid))
+	assert.Len(t, grid.Days, 7)
+	if assert.Len(t, grid.Groups, 2) {
+		assert.Equal(t, "Pflege", grid.Groups[0].Name)
+		assert.Equal(t, []string{"F"}, grid.Groups[0].Rows[0].Cells[1].Codes)
+		assert.Equal(t, []string{"K"}, grid.Groups[1].Rows[0].Cells[2].Codes)
+		assert.Empty(t, grid.Groups[1].Rows[0].Cells[3].Codes, "nur genehmigte Abwesenheiten")
+	}
+
+	// Filter nach Team
+	assert.NoError(t, json.Unmarshal(request(schedule.ID, "?team_id="+strconv.FormatUint(uint64(team.ID), 10), "").Body.Bytes(), &grid))
+	assert.Len(t, grid.Groups, 1)
+
+	rec = request(schedule.ID, "?format=csv", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentType), "text/csv")
+	lines := strings.Split(strings.TrimPrefix(rec.Body.String(), "\ufeff"), "\n")
+	assert.Equal(t, "Team;Name;Personalnummer;Mo 01.04.;Di 02.04.;Mi 03.04.;Do 04.04.;Fr 05.04.;Sa 06.04.;So 07.04.;Schichten;Stunden", lines[0])
+	assert.Equal(t, "Pflege;Raster User;R1;;F;;;;;;1;8,00", lines[1])
+
+	rec = request(schedule.ID, "", xlsxContentType)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, xlsxContentType, rec.Header().Get(echo.HeaderContentType))
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), ".xlsx")
+	_, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	assert.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, request(schedule.ID, "?format=pdf", "").Code)
+	assert.Equal(t, http.StatusNotFound, request(9999, "", "").Code)
+}
+
+func TestGetSchedulePDF(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "druck", Email: "druck@example.com", Password: "x", AccountNumber: "D1", Name: "Druck User", IsActive: true}
+	database.DB.Create(&user)
+	week := models.Schedule{Name: "KW 14", StartDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 4, 7, 0, 0, 0, 0, time.UTC)}
+	month := models.Schedule{Name: "April", StartDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&week)
+	database.DB.Create(&month)
+	database.DB.Create(&models.Shift{UserID: user.ID, ScheduleID: week.ID, StartTime: time.Date(2024, 4, 2, 6, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 4, 2, 14, 0, 0, 0, time.UTC)})
+
+	request := func(id uint, query string) *httptest.ResponseRecorder {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/schedules/pdf"+query, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(strconv.FormatUint(uint64(id), 10))
+		assert.NoError(t, GetSchedulePDF(c))
+		return rec
+	}
+
+	rec := request(week.ID, "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/pdf", rec.Header().Get(echo.HeaderContentType))
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "dienstplan_")
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "%PDF-"))
+	assert.Contains(t, rec.Body.String(), "/Count 1")
+
+	// Ein Monat ergibt ohne Angabe eine Seite, wochenweise fünf
+	assert.Contains(t, request(month.ID, "").Body.String(), "/Count 1")
+	assert.Contains(t, request(month.ID, "?layout=week").Body.String(), "/Count 5")
+
+	assert.Equal(t, http.StatusBadRequest, request(month.ID, "?layout=year").Code)
+	assert.Equal(t, http.StatusNotFound, request(9999, "").Code)
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// ImportSchedule legt Schichten und genehmigte Abwesenheiten aus einem Planraster (CSV oder Excel) im Schichtplan an
+// (nur Planer und Administratoren). Zeilen werden über Personalnummer oder Name Benutzern zugeordnet, die Kürzel der
+// Zellen über die Kürzeltabelle: codes (Kürzel → Schichttyp-ID), absence_codes (Kürzel → Abwesenheitsart) und
+// ignore_codes ergänzen die Kürzel der Schichttypen und Abwesenheiten. xlsx enthält die Datei Base64-kodiert.
+// Mit dry_run wird nur geprüft; der Import erfolgt vollständig oder gar nicht.
+func ImportSchedule(c echo.Context) error {
+	if user := currentUser(c); user != nil && !user.CanApprove() {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Nur Planer und Administratoren dürfen Schichtpläne importieren",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Schichtplan-ID",
+		})
+	}
+
+	var request struct {
+		CSV          string            `json:"csv"`
+		XLSX         string            `json:"xlsx"` // Base64-kodiert
+		Codes        map[string]uint   `json:"codes"`
+		AbsenceCodes map[string]string `json:"absence_codes"`
+		IgnoreCodes  []string          `json:"ignore_codes"`
+		DryRun       bool              `json:"dry_run"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Importdaten",
+		})
+	}
+	if (request.CSV == "") == (request.XLSX == "") {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Entweder csv oder xlsx muss angegeben werden",
+		})
+	}
+
+	var schedule models.Schedule
+	if err := tenantDB(c).First(&schedule, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Schichtplan nicht gefunden",
+		})
+	}
+
+	var shiftTypes []models.ShiftType
+	if err := tenantDB(c).Find(&shiftTypes).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Schichttypen",
+		})
+	}
+	codes, err := services.ScheduleImportCodes(shiftTypes, request.Codes, request.AbsenceCodes, request.IgnoreCodes)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	var rows [][]string
+	if request.XLSX != "" {
+		data, decodeErr := base64.StdEncoding.DecodeString(request.XLSX)
+		if decodeErr != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Excel-Datei muss Base64-kodiert sein",
+			})
+		}
+		rows, err = services.ReadXLSX(data)
+	} else {
+		rows, err = services.ReadCSVRows(request.CSV)
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	var users []models.User
+	if err := tenantDB(c).Find(&users).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Benutzer",
+		})
+	}
+
+	report, err := services.ParseScheduleImport(rows, schedule, users, shiftTypes, codes, models.OrganisationLocation())
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+	if len(report.Rows) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Tabelle enthält keine Zeilen mit Kürzeln",
+		})
+	}
+	report.DryRun = request.DryRun
+
+	userIDs := make([]uint, 0, len(report.Rows))
+	for _, row := range report.Rows {
+		if row.UserID != 0 {
+			userIDs = append(userIDs, row.UserID)
+		}
+	}
+	if len(userIDs) > 0 {
+		// Nachtschichten reichen in den Folgetag, Zeitzonen verschieben die Tagesgrenzen
+		first, last := report.Columns[0].Date, report.Columns[len(report.Columns)-1].Date
+		var shifts []models.Shift
+		if err := tenantDB(c).Where("user_id IN ? AND start_time < ? AND end_time > ?", userIDs, last.AddDate(0, 0, 2), first.AddDate(0, 0, -1)).
+			Find(&shifts).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Fehler beim Laden der Schichten",
+			})
+		}
+		var absences []models.Absence
+		if err := tenantDB(c).Where("user_id IN ? AND status <> ? AND start_date <= ? AND end_date >= ?", userIDs, models.AbsenceStatusRejected, last, first).
+			Find(&absences).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Fehler beim Laden der Abwesenheiten",
+			})
+		}
+		services.CheckScheduleImport(&report, shifts, absences)
+	}
+
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		if row.UserID == 0 {
+			continue
+		}
+		var times []time.Time
+		for _, entry := range row.Entries {
+			if entry.Skipped {
+				continue
+			}
+			if entry.StartTime != nil {
+				times = append(times, *entry.StartTime)
+			} else {
+				times = append(times, entry.Date)
+			}
+		}
+		if len(times) > 0 {
+			if message := timesheetLockMessage(tenantDB(c), row.UserID, times...); message != "" {
+				row.Errors = append(row.Errors, message)
+			}
+		}
+
+		// Fehlende Qualifikationen mit Durchsetzung "block" verhindern den Import der Zeile
+		for _, entry := range row.Entries {
+			if entry.Skipped || entry.StartTime == nil {
+				continue
+			}
+			shift := models.Shift{UserID: row.UserID, ShiftTypeID: &entry.ShiftTypeID, StartTime: *entry.StartTime, EndTime: *entry.EndTime}
+			violations, err := shiftQualificationViolations(tenantDB(c), shift)
+			if err != nil {
+				return c.JSON(http.StatusInternalServerError, map[string]string{
+					"error": "Fehler beim Prüfen der Qualifikationen",
+				})
+			}
+			if message := blockingViolation(violations); message != "" {
+				row.Errors = append(row.Errors, entry.Date.Format("02.01.")+": "+message)
+			}
+		}
+	}
+	services.SummarizeScheduleImport(&report)
+
+	if request.DryRun {
+		return c.JSON(http.StatusOK, report)
+	}
+	if report.Invalid > 0 {
+		report.Error = fmt.Sprintf("Import abgebrochen: %d von %d Zeilen enthalten Fehler", report.Invalid, report.Total)
+		return c.JSON(http.StatusBadRequest, report)
+	}
+
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		for _, row := range report.Rows {
+			shifts, absences := services.ScheduleImportRecords(row, schedule.ID)
+			for i := range shifts {
+				if err := tx.Create(&shifts[i]).Error; err != nil {
+					return fmt.Errorf("Zeile %d: %w", row.Line, err)
+				}
+			}
+			for i := range absences {
+				if err := tx.Create(&absences[i]).Error; err != nil {
+					return fmt.Errorf("Zeile %d: %w", row.Line, err)
+				}
+			}
+			report.ShiftsCreated += len(shifts)
+			report.AbsencesCreated += len(absences)
+		}
+		return nil
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Import des Schichtplans: " + err.Error(),
+		})
+	}
+	return c.JSON(http.StatusCreated, report)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+	"schichtplaner/services"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportSchedule(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	early := models.ShiftType{Name: "Frühschicht", ShortCode: "F", DefaultStart: models.NewWallClock(6, 0), DefaultEnd: models.NewWallClock(14, 0), DefaultBreak: 30, IsActive: true}
+	late := models.ShiftType{Name: "Spätschicht", DefaultStart: models.NewWallClock(14, 0), DefaultEnd: models.NewWallClock(22, 0), IsActive: true}
+	database.DB.Create(&early)
+	database.DB.Create(&late)
+	anna := models.User{Username: "anna", Email: "anna@example.com", Password: "x", AccountNumber: "1001", Name: "Anna Berg", TimeZone: "UTC", IsActive: true}
+	bernd := models.User{Username: "bernd", Email: "bernd@example.com", Password: "x", AccountNumber: "1002", Name: "Bernd Krause", TimeZone: "UTC", IsActive: true}
+	database.DB.Create(&anna)
+	database.DB.Create(&bernd)
+	schedule := models.Schedule{Name: "April", StartDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 4, 7, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&schedule)
+	// Bereits geplante Schicht wird beim Import übersprungen
+	database.DB.Create(&models.Shift{UserID: anna.ID, ScheduleID: schedule.ID, ShiftTypeID: &early.ID, StartTime: time.Date(2024, 4, 1, 6, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 4, 1, 14, 0, 0, 0, time.UTC), IsActive: true})
+
+	request := func(body interface{}) *httptest.ResponseRecorder {
+		payload, err := json.Marshal(body)
+		assert.NoError(t, err)
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/api/schedules/import", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(strconv.FormatUint(uint64(schedule.ID), 10))
+		assert.NoError(t, ImportSchedule(c))
+		return rec
+	}
+	count := func(model interface{}) int64 {
+		var n int64
+		database.DB.Model(model).Count(&n)
+		return n
+	}
+
+	csvData := "Name;Mo 01.04.;Di 02.04.;Mi 03.04.;Do 04.04.\n" +
+		"Anna Berg;F;SP;U;U\n" +
+		"1002;-;F;X;\n"
+
+	// Probelauf mit unbekanntem Kürzel meldet den Fehler je Zeile
+	rec := request(map[string]interface{}{"csv": csvData, "codes": map[string]uint{"SP": late.ID}, "dry_run": true})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var report models.ScheduleImportReport
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 1, report.Invalid)
+	assert.Equal(t, []string{"Mi 03.04.: Unbekanntes Kürzel „X“"}, report.Rows[1].Errors)
+	assert.Equal(t, []string{"01.04.: Schicht F ist bereits vorhanden"}, report.Rows[0].Warnings)
+	assert.Equal(t, int64(1), count(&models.Shift{}))
+
+	// Mit fehlerhaften Zeilen wird nichts importiert
+	rec = request(map[string]interface{}{"csv": csvData, "codes": map[string]uint{"SP": late.ID}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Import abgebrochen: 1 von 2 Zeilen enthalten Fehler")
+	assert.Equal(t, int64(1), count(&models.Shift{}))
+
+	rec = request(map[string]interface{}{"csv": csvData, "codes": map[string]uint{"SP": late.ID}, "ignore_codes": []string{"X"}})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, 2, report.ShiftsCreated)
+	assert.Equal(t, 1, report.AbsencesCreated)
+	assert.Equal(t, int64(3), count(&models.Shift{}))
+	var absence models.Absence
+	assert.NoError(t, database.DB.First(&absence).Error)
+	assert.Equal(t, anna.ID, absence.UserID)
+	assert.Equal(t, models.AbsenceStatusApproved, absence.Status)
+	assert.Equal(t, time.Date(2024, 4, 4, 0, 0, 0, 0, time.UTC), absence.EndDate.UTC())
+	var shift models.Shift
+	assert.NoError(t, database.DB.Where("user_id = ? AND shift_type_id = ?", anna.ID, late.ID).First(&shift).Error)
+	assert.Equal(t, time.Date(2024, 4, 2, 14, 0, 0, 0, time.UTC), shift.StartTime.UTC())
+
+	// Erneuter Import derselben Datei legt nichts doppelt an
+	rec = request(map[string]interface{}{"csv": csvData, "codes": map[string]uint{"SP": late.ID}, "ignore_codes": []string{"X"}})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, int64(3), count(&models.Shift{}))
+	assert.Equal(t, int64(1), count(&models.Absence{}))
+
+	// Excel-Datei im Format des Planrasters
+	data, err := services.WriteXLSX(services.XLSXSheet{Name: "April", Rows: [][]services.XLSXCell{
+		{{Value: "Personalnummer"}, {Value: "Mo 01.04."}},
+		{{Value: "1002"}, {Value: "F"}},
+	}})
+	assert.NoError(t, err)
+	rec = request(map[string]interface{}{"xlsx": base64.StdEncoding.EncodeToString(data), "dry_run": true})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, 1, report.Shifts)
+
+	rec = request(map[string]interface{}{"xlsx": "kein base64!"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = request(map[string]interface{}{"csv": csvData, "xlsx": "x"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = request(map[string]interface{}{"csv": csvData, "codes": map[string]uint{"SP": 999}})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
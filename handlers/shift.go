@@ -1,14 +1,17 @@
 package handlers
 
 import (
+	"encoding/json"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
-	"schichtplaner/database"
 	"schichtplaner/models"
 	"schichtplaner/utils"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 // GetShifts gibt alle Schichten mit Pagination zurück
@@ -18,11 +21,23 @@ func GetShifts(c echo.Context) error {
 	var shifts []models.Shift
 	var total int64
 
+	// Optionaler Standortfilter
+	query := tenantDB(c).Model(&models.Shift{})
+	locationID, message := locationIDFromQuery(c)
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	if locationID != 0 {
+		query = filterShiftsByLocation(query, locationID)
+	}
+
 	// Zähle die Gesamtanzahl
-	database.DB.Model(&models.Shift{}).Count(&total)
+	query.Count(&total)
 
 	// Lade die paginierten Daten mit Preloads
-	if err := database.DB.Preload("User").Preload("Schedule").Offset(params.Offset).Limit(params.PageSize).Find(&shifts).Error; err != nil {
+	if err := query.Preload("User").Preload("Schedule").Offset(params.Offset).Limit(params.PageSize).Find(&shifts).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Laden der Schichten",
 		})
@@ -42,7 +57,7 @@ func GetShift(c echo.Context) error {
 	}
 
 	var shift models.Shift
-	if err := database.DB.Preload("User").Preload("Schedule").First(&shift, id).Error; err != nil {
+	if err := tenantDB(c).Preload("User").Preload("Schedule").First(&shift, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Schicht nicht gefunden",
 		})
@@ -55,7 +70,7 @@ func GetShift(c echo.Context) error {
 func CreateShift(c echo.Context) error {
 	var shift models.Shift
 
-	if err := c.Bind(&shift); err != nil {
+	if err := bindShift(c, &shift); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": "Ungültige Schichtdaten",
 		})
@@ -69,16 +84,48 @@ func CreateShift(c echo.Context) error {
 	validator.RequiredTime("EndTime", shift.EndTime, "Endzeit ist ein Pflichtfeld")
 	validator.TimeRange("StartTime", "EndTime", shift.StartTime, shift.EndTime, "Startzeit muss vor Endzeit liegen")
 
-	if err := validator.ValidateAndRespond(c); err != nil {
-		return err
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+	if message := validateLocationReference(tenantDB(c), shift.LocationID); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	if message := timesheetLockMessage(tenantDB(c), shift.UserID, shift.StartTime); message != "" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": message,
+		})
 	}
 
-	if err := database.DB.Create(&shift).Error; err != nil {
+	// Fehlende Qualifikationen mit Durchsetzung "block" verhindern das Speichern, alle anderen werden als Hinweis gemeldet
+	qualificationWarnings, err := shiftQualificationViolations(tenantDB(c), shift)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Prüfen der Qualifikationen",
+		})
+	}
+	if message := blockingViolation(qualificationWarnings); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	if err := tenantDB(c).Create(&shift).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Erstellen der Schicht",
 		})
 	}
 
+	// Hinweise zum Arbeitszeitgesetz, das Speichern wird dadurch nicht verhindert
+	shift.Warnings = checkShiftCompliance(tenantDB(c), shift.ID)
+	for _, warning := range qualificationWarnings {
+		warning.ShiftID = shift.ID
+		shift.Warnings = append(shift.Warnings, warning)
+	}
+
 	return c.JSON(http.StatusCreated, shift)
 }
 
@@ -92,14 +139,14 @@ func UpdateShift(c echo.Context) error {
 	}
 
 	var shift models.Shift
-	if err := database.DB.First(&shift, id).Error; err != nil {
+	if err := tenantDB(c).First(&shift, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Schicht nicht gefunden",
 		})
 	}
 
 	var updateData models.Shift
-	if err := c.Bind(&updateData); err != nil {
+	if err := bindShift(c, &updateData); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": "Ungültige Schichtdaten",
 		})
@@ -113,16 +160,63 @@ func UpdateShift(c echo.Context) error {
 	validator.RequiredTime("EndTime", updateData.EndTime, "Endzeit ist ein Pflichtfeld")
 	validator.TimeRange("StartTime", "EndTime", updateData.StartTime, updateData.EndTime, "Startzeit muss vor Endzeit liegen")
 
-	if err := validator.ValidateAndRespond(c); err != nil {
-		return err
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+	if message := validateLocationReference(tenantDB(c), updateData.LocationID); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	// Weder der bisherige noch der neue Monat darf in einem freigegebenen Stundenzettel liegen
+	if message := timesheetLockMessage(tenantDB(c), shift.UserID, shift.StartTime); message != "" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": message,
+		})
+	}
+	if message := timesheetLockMessage(tenantDB(c), updateData.UserID, updateData.StartTime); message != "" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": message,
+		})
+	}
+
+	// Einzeln bearbeitete Vorkommen einer Serie werden bei der Neuberechnung nicht überschrieben
+	if shift.RecurringShiftID != nil {
+		updateData.RecurrenceModified = true
+	}
+
+	// Qualifikationsprüfung mit dem Stand nach der Aktualisierung; nicht übermittelte Felder bleiben erhalten
+	candidate := updateData
+	candidate.ID = shift.ID
+	if candidate.ShiftTypeID == nil {
+		candidate.ShiftTypeID = shift.ShiftTypeID
+	}
+	if candidate.RequiredQualificationIDs == nil {
+		candidate.RequiredQualificationIDs = shift.RequiredQualificationIDs
+	}
+	qualificationWarnings, err := shiftQualificationViolations(tenantDB(c), candidate)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Prüfen der Qualifikationen",
+		})
+	}
+	if message := blockingViolation(qualificationWarnings); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
 	}
 
-	if err := database.DB.Model(&shift).Updates(updateData).Error; err != nil {
+	if err := tenantDB(c).Model(&shift).Updates(updateData).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Aktualisieren der Schicht",
 		})
 	}
 
+	// Hinweise zum Arbeitszeitgesetz, das Speichern wird dadurch nicht verhindert
+	shift.Warnings = append(checkShiftCompliance(tenantDB(c), shift.ID), qualificationWarnings...)
+
 	return c.JSON(http.StatusOK, shift)
 }
 
@@ -136,13 +230,18 @@ func DeleteShift(c echo.Context) error {
 	}
 
 	var shift models.Shift
-	if err := database.DB.First(&shift, id).Error; err != nil {
+	if err := tenantDB(c).First(&shift, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Schicht nicht gefunden",
 		})
 	}
+	if message := timesheetLockMessage(tenantDB(c), shift.UserID, shift.StartTime); message != "" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": message,
+		})
+	}
 
-	if err := database.DB.Delete(&shift).Error; err != nil {
+	if err := tenantDB(c).Delete(&shift).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Löschen der Schicht",
 		})
@@ -164,7 +263,7 @@ func GetShiftsByUser(c echo.Context) error {
 
 	// Prüfe, ob der User existiert
 	var user models.User
-	if err := database.DB.First(&user, userID).Error; err != nil {
+	if err := tenantDB(c).First(&user, userID).Error; err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": "Benutzer nicht gefunden",
 		})
@@ -176,10 +275,10 @@ func GetShiftsByUser(c echo.Context) error {
 	var total int64
 
 	// Zähle die Gesamtanzahl der Schichten des Benutzers
-	database.DB.Model(&models.Shift{}).Where("user_id = ?", userID).Count(&total)
+	tenantDB(c).Model(&models.Shift{}).Where("user_id = ?", userID).Count(&total)
 
 	// Lade die paginierten Daten
-	if err := database.DB.Where("user_id = ?", userID).Preload("User").Preload("Schedule").Offset(params.Offset).Limit(params.PageSize).Find(&shifts).Error; err != nil {
+	if err := tenantDB(c).Where("user_id = ?", userID).Preload("User").Preload("Schedule").Offset(params.Offset).Limit(params.PageSize).Find(&shifts).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Laden der Schichten",
 		})
@@ -188,3 +287,71 @@ func GetShiftsByUser(c echo.Context) error {
 	response := utils.CreatePaginatedResponse(shifts, int(total), params)
 	return c.JSON(http.StatusOK, response)
 }
+
+// bindShift liest Schichtdaten aus dem Request-Body. Start- und Endzeit ohne Offset (z.B. "2024-03-31T01:30")
+// werden als Ortszeit interpretiert: in der Zeitzone aus "time_zone", sonst der des Benutzers bzw. der Organisation.
+func bindShift(c echo.Context, shift *models.Shift) error {
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return err
+	}
+
+	times := make(map[string]string)
+	for _, key := range []string{"start_time", "end_time"} {
+		var value string
+		if json.Unmarshal(raw[key], &value) == nil && value != "" {
+			times[key] = value
+			delete(raw, key)
+		}
+	}
+
+	var zoneName string
+	if value, ok := raw["time_zone"]; ok {
+		if err := json.Unmarshal(value, &zoneName); err != nil {
+			return err
+		}
+		delete(raw, "time_zone")
+	}
+
+	rest, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(rest, shift); err != nil {
+		return err
+	}
+
+	loc, err := shiftInputLocation(tenantDB(c), shift.UserID, zoneName)
+	if err != nil {
+		return err
+	}
+	if value, ok := times["start_time"]; ok {
+		if shift.StartTime, err = models.ParseLocalTime(value, loc); err != nil {
+			return err
+		}
+	}
+	if value, ok := times["end_time"]; ok {
+		if shift.EndTime, err = models.ParseLocalTime(value, loc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shiftInputLocation bestimmt die Zeitzone für Ortszeiten: explizit angegeben, die des Benutzers oder die der Organisation
+func shiftInputLocation(db *gorm.DB, userID uint, zoneName string) (*time.Location, error) {
+	if zoneName != "" {
+		return models.LoadLocation(zoneName)
+	}
+
+	var user models.User
+	if userID != 0 && db.First(&user, userID).Error == nil {
+		return user.Location(), nil
+	}
+	return models.OrganisationLocation(), nil
+}
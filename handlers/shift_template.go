@@ -4,11 +4,11 @@ import (
 	"net/http"
 	"strconv"
 
-	"schichtplaner/database"
 	"schichtplaner/models"
 	"schichtplaner/utils"
 
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 // GetShiftTemplates gibt alle Schichtvorlagen mit Pagination zurück
@@ -19,10 +19,10 @@ func GetShiftTemplates(c echo.Context) error {
 	var total int64
 
 	// Zähle die Gesamtanzahl
-	database.DB.Model(&models.ShiftTemplate{}).Count(&total)
+	tenantDB(c).Model(&models.ShiftTemplate{}).Count(&total)
 
 	// Lade die paginierten Daten mit allen Schichttypen, sortiert nach SortOrder und Name
-	if err := database.DB.Preload("MondayShiftType").
+	if err := tenantDB(c).Preload("MondayShiftType").
 		Preload("TuesdayShiftType").
 		Preload("WednesdayShiftType").
 		Preload("ThursdayShiftType").
@@ -46,7 +46,7 @@ func GetShiftTemplates(c echo.Context) error {
 func GetActiveShiftTemplates(c echo.Context) error {
 	var shiftTemplates []models.ShiftTemplate
 
-	if err := database.DB.Preload("MondayShiftType").
+	if err := tenantDB(c).Preload("MondayShiftType").
 		Preload("TuesdayShiftType").
 		Preload("WednesdayShiftType").
 		Preload("ThursdayShiftType").
@@ -74,7 +74,7 @@ func GetShiftTemplate(c echo.Context) error {
 	}
 
 	var shiftTemplate models.ShiftTemplate
-	if err := database.DB.Preload("MondayShiftType").
+	if err := tenantDB(c).Preload("MondayShiftType").
 		Preload("TuesdayShiftType").
 		Preload("WednesdayShiftType").
 		Preload("ThursdayShiftType").
@@ -104,25 +104,27 @@ func CreateShiftTemplate(c echo.Context) error {
 	validator := utils.NewValidator()
 	validator.RequiredString("Name", shiftTemplate.Name, "Name ist ein Pflichtfeld")
 
-	if err := validator.ValidateAndRespond(c); err != nil {
-		return err
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
 	}
 
 	// Validiere, dass alle Schichttyp-IDs existieren (falls gesetzt)
-	if err := validateShiftTypeIDs(&shiftTemplate); err != nil {
+	if err := validateShiftTypeIDs(tenantDB(c), &shiftTemplate); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": err.Error(),
 		})
 	}
 
-	if err := database.DB.Create(&shiftTemplate).Error; err != nil {
+	if err := tenantDB(c).Create(&shiftTemplate).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Erstellen der Schichtvorlage",
 		})
 	}
 
 	// Lade die erstellte Vorlage mit allen Schichttypen
-	if err := database.DB.Preload("MondayShiftType").
+	if err := tenantDB(c).Preload("MondayShiftType").
 		Preload("TuesdayShiftType").
 		Preload("WednesdayShiftType").
 		Preload("ThursdayShiftType").
@@ -148,7 +150,7 @@ func UpdateShiftTemplate(c echo.Context) error {
 	}
 
 	var shiftTemplate models.ShiftTemplate
-	if err := database.DB.First(&shiftTemplate, id).Error; err != nil {
+	if err := tenantDB(c).First(&shiftTemplate, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Schichtvorlage nicht gefunden",
 		})
@@ -165,25 +167,27 @@ func UpdateShiftTemplate(c echo.Context) error {
 	validator := utils.NewValidator()
 	validator.RequiredString("Name", updateData.Name, "Name ist ein Pflichtfeld")
 
-	if err := validator.ValidateAndRespond(c); err != nil {
-		return err
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
 	}
 
 	// Validiere, dass alle Schichttyp-IDs existieren (falls gesetzt)
-	if err := validateShiftTypeIDs(&updateData); err != nil {
+	if err := validateShiftTypeIDs(tenantDB(c), &updateData); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": err.Error(),
 		})
 	}
 
-	if err := database.DB.Model(&shiftTemplate).Updates(updateData).Error; err != nil {
+	if err := tenantDB(c).Model(&shiftTemplate).Updates(updateData).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Aktualisieren der Schichtvorlage",
 		})
 	}
 
 	// Lade die aktualisierte Vorlage mit allen Schichttypen
-	if err := database.DB.Preload("MondayShiftType").
+	if err := tenantDB(c).Preload("MondayShiftType").
 		Preload("TuesdayShiftType").
 		Preload("WednesdayShiftType").
 		Preload("ThursdayShiftType").
@@ -209,13 +213,13 @@ func DeleteShiftTemplate(c echo.Context) error {
 	}
 
 	var shiftTemplate models.ShiftTemplate
-	if err := database.DB.First(&shiftTemplate, id).Error; err != nil {
+	if err := tenantDB(c).First(&shiftTemplate, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Schichtvorlage nicht gefunden",
 		})
 	}
 
-	if err := database.DB.Delete(&shiftTemplate).Error; err != nil {
+	if err := tenantDB(c).Delete(&shiftTemplate).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Löschen der Schichtvorlage",
 		})
@@ -236,7 +240,7 @@ func ToggleShiftTemplateActive(c echo.Context) error {
 	}
 
 	var shiftTemplate models.ShiftTemplate
-	if err := database.DB.First(&shiftTemplate, id).Error; err != nil {
+	if err := tenantDB(c).First(&shiftTemplate, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Schichtvorlage nicht gefunden",
 		})
@@ -244,7 +248,7 @@ func ToggleShiftTemplateActive(c echo.Context) error {
 
 	shiftTemplate.IsActive = !shiftTemplate.IsActive
 
-	if err := database.DB.Save(&shiftTemplate).Error; err != nil {
+	if err := tenantDB(c).Save(&shiftTemplate).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Ändern des Status",
 		})
@@ -267,7 +271,7 @@ func UpdateShiftTemplateOrder(c echo.Context) error {
 	}
 
 	var shiftTemplate models.ShiftTemplate
-	if err := database.DB.First(&shiftTemplate, orderData.ID).Error; err != nil {
+	if err := tenantDB(c).First(&shiftTemplate, orderData.ID).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Schichtvorlage nicht gefunden",
 		})
@@ -275,7 +279,7 @@ func UpdateShiftTemplateOrder(c echo.Context) error {
 
 	shiftTemplate.SortOrder = orderData.SortOrder
 
-	if err := database.DB.Save(&shiftTemplate).Error; err != nil {
+	if err := tenantDB(c).Save(&shiftTemplate).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Aktualisieren der Sortierung",
 		})
@@ -285,7 +289,7 @@ func UpdateShiftTemplateOrder(c echo.Context) error {
 }
 
 // validateShiftTypeIDs prüft, ob alle gesetzten Schichttyp-IDs existieren
-func validateShiftTypeIDs(template *models.ShiftTemplate) error {
+func validateShiftTypeIDs(db *gorm.DB, template *models.ShiftTemplate) error {
 	shiftTypeIDs := []*uint{
 		template.MondayShiftTypeID,
 		template.TuesdayShiftTypeID,
@@ -299,7 +303,7 @@ func validateShiftTypeIDs(template *models.ShiftTemplate) error {
 	for _, id := range shiftTypeIDs {
 		if id != nil {
 			var shiftType models.ShiftType
-			if err := database.DB.First(&shiftType, *id).Error; err != nil {
+			if err := db.First(&shiftType, *id).Error; err != nil {
 				return err
 			}
 		}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
@@ -81,3 +82,105 @@ func TestCreateShift(t *testing.T) {
 		assert.Equal(t, http.StatusCreated, rec.Code)
 	}
 }
+
+func TestCreateShift_LocalTimeAcrossDST(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "nacht", Email: "nacht@example.com", Password: "hashedpassword", Name: "Nacht User", TimeZone: "Europe/Berlin"}
+	database.DB.Create(&user)
+	schedule := models.Schedule{Name: "März 2024", StartDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC)}
+	database.DB.Create(&schedule)
+
+	// Ortszeiten ohne Offset, die Nacht der Umstellung auf Sommerzeit dauert nur 7 Stunden
+	body := `{"user_id": ` + strconv.Itoa(int(user.ID)) + `, "schedule_id": ` + strconv.Itoa(int(schedule.ID)) + `,
+		"start_time": "2024-03-30T22:00", "end_time": "2024-03-31T06:00", "break_time": 45}`
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/shifts", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, CreateShift(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var response map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, "2024-03-30T21:00:00Z", response["start_utc"])
+		assert.Equal(t, "2024-03-31T04:00:00Z", response["end_utc"])
+		assert.Equal(t, "2024-03-31T06:00:00+02:00", response["end_local"])
+		assert.Equal(t, float64(7*60), response["duration_minutes"])
+
+		var stored models.Shift
+		database.DB.First(&stored)
+		assert.Equal(t, 7*time.Hour-45*time.Minute, stored.NetDuration())
+	}
+}
+
+func TestCreateShift_InvalidTimeZone(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	body := `{"user_id": 1, "schedule_id": 1, "start_time": "2024-03-30T22:00", "end_time": "2024-03-31T06:00", "time_zone": "Mars/Olympus"}`
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/shifts", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, CreateShift(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestCreateShift_InvalidInput(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	// Endzeit vor Startzeit: nur eine Fehlerantwort, keine Schicht
+	body := `{"user_id": 1, "schedule_id": 1, "start_time": "2024-04-01T14:00:00Z", "end_time": "2024-04-01T06:00:00Z"}`
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/shifts", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, CreateShift(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error":"Startzeit muss vor Endzeit liegen"}`, rec.Body.String())
+	}
+	var count int64
+	database.DB.Model(&models.Shift{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestUpdateShift_InvalidInput(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	start := time.Date(2024, 4, 1, 6, 0, 0, 0, time.UTC)
+	shift := models.Shift{UserID: 1, ScheduleID: 1, StartTime: start, EndTime: start.Add(8 * time.Hour), Description: "Früh"}
+	database.DB.Create(&shift)
+
+	body := `{"user_id": 1, "schedule_id": 1, "start_time": "2024-04-01T06:00:00Z", "description": "Geändert"}`
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/api/shifts/"+strconv.Itoa(int(shift.ID)), bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(shift.ID)))
+
+	if assert.NoError(t, UpdateShift(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.JSONEq(t, `{"error":"Endzeit ist ein Pflichtfeld"}`, rec.Body.String())
+	}
+	var stored models.Shift
+	database.DB.First(&stored, shift.ID)
+	assert.Equal(t, "Früh", stored.Description)
+	assert.True(t, stored.EndTime.Equal(shift.EndTime))
+}
@@ -1,10 +1,11 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"unicode/utf8"
 
-	"schichtplaner/database"
 	"schichtplaner/models"
 	"schichtplaner/utils"
 
@@ -19,10 +20,10 @@ func GetShiftTypes(c echo.Context) error {
 	var total int64
 
 	// Zähle die Gesamtanzahl
-	database.DB.Model(&models.ShiftType{}).Count(&total)
+	tenantDB(c).Model(&models.ShiftType{}).Count(&total)
 
 	// Lade die paginierten Daten, sortiert nach SortOrder und Name
-	if err := database.DB.Order("sort_order ASC, name ASC").Offset(params.Offset).Limit(params.PageSize).Find(&shiftTypes).Error; err != nil {
+	if err := tenantDB(c).Order("sort_order ASC, name ASC").Offset(params.Offset).Limit(params.PageSize).Find(&shiftTypes).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Laden der Schichttypen",
 		})
@@ -36,7 +37,7 @@ func GetShiftTypes(c echo.Context) error {
 func GetActiveShiftTypes(c echo.Context) error {
 	var shiftTypes []models.ShiftType
 
-	if err := database.DB.Where("is_active = ?", true).Order("sort_order ASC, name ASC").Find(&shiftTypes).Error; err != nil {
+	if err := tenantDB(c).Where("is_active = ?", true).Order("sort_order ASC, name ASC").Find(&shiftTypes).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Laden der aktiven Schichttypen",
 		})
@@ -55,7 +56,7 @@ func GetShiftType(c echo.Context) error {
 	}
 
 	var shiftType models.ShiftType
-	if err := database.DB.First(&shiftType, id).Error; err != nil {
+	if err := tenantDB(c).First(&shiftType, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Schichttyp nicht gefunden",
 		})
@@ -78,9 +79,11 @@ func CreateShiftType(c echo.Context) error {
 	validator := utils.NewValidator()
 	validator.RequiredString("Name", shiftType.Name, "Name ist ein Pflichtfeld")
 
-	// Validiere Standardzeiten (nur wenn beide gesetzt sind)
-	if !shiftType.DefaultStart.IsZero() && !shiftType.DefaultEnd.IsZero() {
-		validator.TimeRange("DefaultStart", "DefaultEnd", shiftType.DefaultStart, shiftType.DefaultEnd, "Standard-Startzeit muss vor Standard-Endzeit liegen")
+	// Validiere Standardzeiten (nur wenn beide gesetzt sind); ein Ende vor dem Beginn bedeutet Schichtende am Folgetag
+	if shiftType.DefaultStart.Valid && shiftType.DefaultStart == shiftType.DefaultEnd {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Standard-Startzeit und Standard-Endzeit dürfen nicht gleich sein",
+		})
 	}
 
 	// Validiere Dauer-Beschränkungen (nur wenn beide > 0 sind)
@@ -88,11 +91,23 @@ func CreateShiftType(c echo.Context) error {
 		validator.NumberRange("MinDuration", "MaxDuration", shiftType.MinDuration, shiftType.MaxDuration, "Mindestdauer darf nicht größer als Maximaldauer sein")
 	}
 
-	if err := validator.ValidateAndRespond(c); err != nil {
-		return err
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+	if utf8.RuneCountInString(shiftType.ShortCode) > models.MaxShiftTypeShortCodeLength {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("Kürzel darf höchstens %d Zeichen lang sein", models.MaxShiftTypeShortCodeLength),
+		})
+	}
+	if message := validateChecklistTemplateIDs(tenantDB(c), shiftType.ChecklistTemplateIDs); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
 	}
 
-	if err := database.DB.Create(&shiftType).Error; err != nil {
+	if err := tenantDB(c).Create(&shiftType).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Erstellen des Schichttyps",
 		})
@@ -111,7 +126,7 @@ func UpdateShiftType(c echo.Context) error {
 	}
 
 	var shiftType models.ShiftType
-	if err := database.DB.First(&shiftType, id).Error; err != nil {
+	if err := tenantDB(c).First(&shiftType, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Schichttyp nicht gefunden",
 		})
@@ -128,9 +143,11 @@ func UpdateShiftType(c echo.Context) error {
 	validator := utils.NewValidator()
 	validator.RequiredString("Name", updateData.Name, "Name ist ein Pflichtfeld")
 
-	// Validiere Standardzeiten (nur wenn beide gesetzt sind)
-	if !updateData.DefaultStart.IsZero() && !updateData.DefaultEnd.IsZero() {
-		validator.TimeRange("DefaultStart", "DefaultEnd", updateData.DefaultStart, updateData.DefaultEnd, "Standard-Startzeit muss vor Standard-Endzeit liegen")
+	// Validiere Standardzeiten (nur wenn beide gesetzt sind); ein Ende vor dem Beginn bedeutet Schichtende am Folgetag
+	if updateData.DefaultStart.Valid && updateData.DefaultStart == updateData.DefaultEnd {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Standard-Startzeit und Standard-Endzeit dürfen nicht gleich sein",
+		})
 	}
 
 	// Validiere Dauer-Beschränkungen (nur wenn beide > 0 sind)
@@ -138,11 +155,23 @@ func UpdateShiftType(c echo.Context) error {
 		validator.NumberRange("MinDuration", "MaxDuration", updateData.MinDuration, updateData.MaxDuration, "Mindestdauer darf nicht größer als Maximaldauer sein")
 	}
 
-	if err := validator.ValidateAndRespond(c); err != nil {
-		return err
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+	if utf8.RuneCountInString(updateData.ShortCode) > models.MaxShiftTypeShortCodeLength {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("Kürzel darf höchstens %d Zeichen lang sein", models.MaxShiftTypeShortCodeLength),
+		})
+	}
+	if message := validateChecklistTemplateIDs(tenantDB(c), updateData.ChecklistTemplateIDs); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
 	}
 
-	if err := database.DB.Model(&shiftType).Updates(updateData).Error; err != nil {
+	if err := tenantDB(c).Model(&shiftType).Updates(updateData).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Aktualisieren des Schichttyps",
 		})
@@ -161,7 +190,7 @@ func DeleteShiftType(c echo.Context) error {
 	}
 
 	var shiftType models.ShiftType
-	if err := database.DB.First(&shiftType, id).Error; err != nil {
+	if err := tenantDB(c).First(&shiftType, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Schichttyp nicht gefunden",
 		})
@@ -169,14 +198,14 @@ func DeleteShiftType(c echo.Context) error {
 
 	// Prüfe, ob der Schichttyp noch verwendet wird
 	var count int64
-	database.DB.Model(&models.Shift{}).Where("shift_type_id = ?", id).Count(&count)
+	tenantDB(c).Model(&models.Shift{}).Where("shift_type_id = ?", id).Count(&count)
 	if count > 0 {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": "Schichttyp kann nicht gelöscht werden, da er noch verwendet wird",
 		})
 	}
 
-	if err := database.DB.Delete(&shiftType).Error; err != nil {
+	if err := tenantDB(c).Delete(&shiftType).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Löschen des Schichttyps",
 		})
@@ -197,7 +226,7 @@ func ToggleShiftTypeActive(c echo.Context) error {
 	}
 
 	var shiftType models.ShiftType
-	if err := database.DB.First(&shiftType, id).Error; err != nil {
+	if err := tenantDB(c).First(&shiftType, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Schichttyp nicht gefunden",
 		})
@@ -205,7 +234,7 @@ func ToggleShiftTypeActive(c echo.Context) error {
 
 	shiftType.IsActive = !shiftType.IsActive
 
-	if err := database.DB.Save(&shiftType).Error; err != nil {
+	if err := tenantDB(c).Save(&shiftType).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Ändern des Status",
 		})
@@ -228,7 +257,7 @@ func UpdateShiftTypeOrder(c echo.Context) error {
 	}
 
 	var shiftType models.ShiftType
-	if err := database.DB.First(&shiftType, orderData.ID).Error; err != nil {
+	if err := tenantDB(c).First(&shiftType, orderData.ID).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Schichttyp nicht gefunden",
 		})
@@ -236,7 +265,7 @@ func UpdateShiftTypeOrder(c echo.Context) error {
 
 	shiftType.SortOrder = orderData.SortOrder
 
-	if err := database.DB.Save(&shiftType).Error; err != nil {
+	if err := tenantDB(c).Save(&shiftType).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Aktualisieren der Sortierung",
 		})
@@ -6,8 +6,8 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
-	"time"
 
+	"schichtplaner/database"
 	"schichtplaner/models"
 
 	"github.com/labstack/echo/v4"
@@ -15,11 +15,11 @@ import (
 )
 
 func TestCreateShiftTypeValidation(t *testing.T) {
-	// Test: Ungültige Zeiten
+	// Test: Ungültige Zeiten (Beginn gleich Ende)
 	shiftType := models.ShiftType{
 		Name:         "Ungültige Schicht",
-		DefaultStart: time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC),
-		DefaultEnd:   time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC),
+		DefaultStart: models.NewWallClock(14, 0),
+		DefaultEnd:   models.NewWallClock(14, 0),
 	}
 
 	jsonData, _ := json.Marshal(shiftType)
@@ -29,7 +29,7 @@ func TestCreateShiftTypeValidation(t *testing.T) {
 	_ = echo.New().NewContext(req, rec)
 
 	// Die Validierung würde hier erfolgen, aber wir testen nur die Logik
-	assert.True(t, shiftType.DefaultStart.After(shiftType.DefaultEnd))
+	assert.Equal(t, shiftType.DefaultStart, shiftType.DefaultEnd)
 }
 
 func TestCreateShiftTypeDurationValidation(t *testing.T) {
@@ -56,8 +56,8 @@ func TestShiftTypeModelStructure(t *testing.T) {
 		Name:         "Test Schicht",
 		Description:  "Test Beschreibung",
 		Color:        "#3B82F6",
-		DefaultStart: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
-		DefaultEnd:   time.Date(2024, 1, 1, 16, 0, 0, 0, time.UTC),
+		DefaultStart: models.NewWallClock(8, 0),
+		DefaultEnd:   models.NewWallClock(16, 0),
 		DefaultBreak: 30,
 		IsActive:     true,
 		SortOrder:    1,
@@ -80,8 +80,8 @@ func TestShiftTypeJSONSerialization(t *testing.T) {
 		Name:         "Test Schicht",
 		Description:  "Test Beschreibung",
 		Color:        "#10B981",
-		DefaultStart: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
-		DefaultEnd:   time.Date(2024, 1, 1, 16, 0, 0, 0, time.UTC),
+		DefaultStart: models.NewWallClock(8, 0),
+		DefaultEnd:   models.NewWallClock(16, 0),
 		DefaultBreak: 30,
 		IsActive:     true,
 		SortOrder:    1,
@@ -95,3 +95,21 @@ func TestShiftTypeJSONSerialization(t *testing.T) {
 	assert.Contains(t, string(jsonData), "#10B981")
 	assert.Contains(t, string(jsonData), "true")
 }
+
+func TestShiftTypeWallClockPersistence(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	shiftType := models.ShiftType{Name: "Nachtschicht", DefaultStart: models.NewWallClock(22, 0), DefaultEnd: models.NewWallClock(6, 0)}
+	assert.NoError(t, database.DB.Create(&shiftType).Error)
+
+	var stored models.ShiftType
+	assert.NoError(t, database.DB.First(&stored, shiftType.ID).Error)
+	assert.Equal(t, models.NewWallClock(22, 0), stored.DefaultStart)
+	assert.Equal(t, models.NewWallClock(6, 0), stored.DefaultEnd)
+
+	// Ältere Datensätze speichern die Uhrzeit als Datum
+	database.DB.Exec("UPDATE shift_types SET default_start = ? WHERE id = ?", "2024-01-01 14:00:00+00:00", shiftType.ID)
+	assert.NoError(t, database.DB.First(&stored, shiftType.ID).Error)
+	assert.Equal(t, models.NewWallClock(14, 0), stored.DefaultStart)
+}
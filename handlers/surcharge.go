@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+	"schichtplaner/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// GetSurchargeRules gibt alle Zuschlagsregeln zurück
+func GetSurchargeRules(c echo.Context) error {
+	var rules []models.SurchargeRule
+	if err := tenantDB(c).Order("id ASC").Find(&rules).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Zuschlagsregeln",
+		})
+	}
+
+	return c.JSON(http.StatusOK, rules)
+}
+
+// CreateSurchargeRule legt eine neue Zuschlagsregel an
+func CreateSurchargeRule(c echo.Context) error {
+	rule := models.SurchargeRule{IsActive: true}
+	if err := c.Bind(&rule); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Zuschlagsdaten",
+		})
+	}
+	rule.ID = 0
+
+	if message := validateSurchargeRule(&rule); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	if err := tenantDB(c).Create(&rule).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen der Zuschlagsregel",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateSurchargeRule aktualisiert eine Zuschlagsregel
+func UpdateSurchargeRule(c echo.Context) error {
+	rule, err := loadSurchargeRuleFromParam(c)
+	if err != nil || rule == nil {
+		return err
+	}
+
+	updateData := *rule
+	if err := c.Bind(&updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Zuschlagsdaten",
+		})
+	}
+	updateData.Base = rule.Base
+
+	if message := validateSurchargeRule(&updateData); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	// Save statt Updates, damit auch is_active=false übernommen wird
+	if err := tenantDB(c).Save(&updateData).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Aktualisieren der Zuschlagsregel",
+		})
+	}
+
+	return c.JSON(http.StatusOK, updateData)
+}
+
+// DeleteSurchargeRule löscht eine Zuschlagsregel
+func DeleteSurchargeRule(c echo.Context) error {
+	rule, err := loadSurchargeRuleFromParam(c)
+	if err != nil || rule == nil {
+		return err
+	}
+
+	if err := tenantDB(c).Delete(rule).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen der Zuschlagsregel",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Zuschlagsregel erfolgreich gelöscht",
+	})
+}
+
+// ApplySurchargePreset ersetzt alle Zuschlagsregeln durch die steuerfreien Sätze nach §3b EStG
+func ApplySurchargePreset(c echo.Context) error {
+	rules := services.EStG3bSurchargeRules()
+
+	err := tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.SurchargeRule{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&rules).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Übernehmen der Voreinstellung",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, rules)
+}
+
+// GetShiftSurcharges berechnet die Zuschläge einer Schicht
+func GetShiftSurcharges(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Schicht-ID",
+		})
+	}
+
+	var shift models.Shift
+	if err := tenantDB(c).Preload("User").First(&shift, id).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Schicht nicht gefunden",
+		})
+	}
+
+	calculator, err := loadSurchargeCalculator(tenantDB(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Zuschlagsregeln",
+		})
+	}
+
+	return c.JSON(http.StatusOK, calculator.Calculate(shift, shift.Location()))
+}
+
+// GetSurchargeReport fasst die Zuschläge je Benutzer für einen Monat (month=YYYY-MM) zusammen.
+// Optional kann der Bericht über user_id auf einen Benutzer beschränkt werden.
+func GetSurchargeReport(c echo.Context) error {
+	loc := models.OrganisationLocation()
+	monthStart, err := models.ParseMonth(c.QueryParam("month"), loc)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültiger Monat (month), erwartet YYYY-MM",
+		})
+	}
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	query := tenantDB(c).Preload("User").
+		Where("start_time >= ? AND start_time < ?", monthStart, monthEnd).
+		Order("user_id ASC, start_time ASC")
+	if value := c.QueryParam("user_id"); value != "" {
+		userID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültige Benutzer-ID",
+			})
+		}
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var shifts []models.Shift
+	if err := query.Find(&shifts).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Schichten",
+		})
+	}
+
+	calculator, err := loadSurchargeCalculator(tenantDB(c))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Zuschlagsregeln",
+		})
+	}
+
+	return c.JSON(http.StatusOK, summarizeUserSurcharges(calculator, shifts, monthStart))
+}
+
+// summarizeUserSurcharges berechnet die Zuschläge der nach Benutzer sortierten Schichten und fasst sie je Benutzer zusammen
+func summarizeUserSurcharges(calculator *services.SurchargeCalculator, shifts []models.Shift, month time.Time) []models.UserSurchargeSummary {
+	summaries := make([]models.UserSurchargeSummary, 0)
+	for _, shift := range shifts {
+		if len(summaries) == 0 || summaries[len(summaries)-1].UserID != shift.UserID {
+			summaries = append(summaries, models.UserSurchargeSummary{
+				UserID:   shift.UserID,
+				UserName: shift.User.Name,
+				Month:    month.Format("2006-01"),
+				Shifts:   make([]models.ShiftSurcharge, 0),
+			})
+		}
+		summary := &summaries[len(summaries)-1]
+		summary.Shifts = append(summary.Shifts, calculator.Calculate(shift, shift.Location()))
+	}
+
+	for i := range summaries {
+		summaries[i].WorkedMinutes, summaries[i].Lines = services.SummarizeSurcharges(summaries[i].Shifts)
+	}
+	return summaries
+}
+
+// loadSurchargeCalculator erstellt den Zuschlagsrechner; ohne gespeicherte Regeln gelten die Sätze nach §3b EStG
+func loadSurchargeCalculator(db *gorm.DB) (*services.SurchargeCalculator, error) {
+	var rules []models.SurchargeRule
+	if err := db.Order("id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		rules = services.EStG3bSurchargeRules()
+	}
+
+	calendar, err := loadHolidayCalendar(db, models.OrganisationState())
+	if err != nil {
+		return nil, err
+	}
+	return services.NewSurchargeCalculator(rules, calendar), nil
+}
+
+// validateSurchargeRule prüft eine Zuschlagsregel und liefert die erste Fehlermeldung oder einen leeren String
+func validateSurchargeRule(rule *models.SurchargeRule) string {
+	validator := utils.NewValidator()
+	validator.RequiredString("Name", rule.Name, "Name ist ein Pflichtfeld")
+	validator.RequiredString("Type", rule.Type, "Zuschlagstyp ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return result.Errors[0]
+	}
+
+	if err := services.ValidateSurchargeRule(*rule); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// loadSurchargeRuleFromParam lädt die Zuschlagsregel aus dem Pfadparameter id; bei Fehlern wird direkt geantwortet und nil geliefert
+func loadSurchargeRuleFromParam(c echo.Context) (*models.SurchargeRule, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Regel-ID",
+		})
+	}
+
+	var rule models.SurchargeRule
+	if err := tenantDB(c).First(&rule, id).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Zuschlagsregel nicht gefunden",
+		})
+	}
+	return &rule, nil
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// createSurchargeFixture legt einen Benutzer mit einer Nachtschicht in den Sonntag und einer Feiertagsschicht an
+func createSurchargeFixture() (models.User, models.Shift) {
+	loc := models.OrganisationLocation()
+	user := models.User{Username: "zuschlag", Email: "zuschlag@example.com", Password: "hashedpassword", Name: "Zuschlag User"}
+	database.DB.Create(&user)
+
+	night := models.Shift{UserID: user.ID, BreakTime: 0,
+		StartTime: time.Date(2024, 6, 1, 22, 0, 0, 0, loc), EndTime: time.Date(2024, 6, 2, 6, 0, 0, 0, loc)}
+	database.DB.Create(&night)
+	database.DB.Create(&models.Shift{UserID: user.ID, BreakTime: 30,
+		StartTime: time.Date(2024, 5, 30, 8, 0, 0, 0, loc), EndTime: time.Date(2024, 5, 30, 16, 30, 0, 0, loc)})
+	return user, night
+}
+
+func TestGetShiftSurcharges(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	_, night := createSurchargeFixture()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(night.ID)))
+
+	if assert.NoError(t, GetShiftSurcharges(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var result models.ShiftSurcharge
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.Equal(t, night.ID, result.ShiftID)
+		assert.Equal(t, 480.0, result.WorkedMinutes)
+		assert.Len(t, result.Lines, 3)
+	}
+}
+
+func TestGetSurchargeReport(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+	models.SetOrganisationState(models.StateBY)
+	defer models.SetOrganisationState("")
+
+	user, _ := createSurchargeFixture()
+
+	// Eigene Regeln ersetzen die Voreinstellung
+	database.DB.Create(&models.SurchargeRule{Name: "Feiertag", Type: models.SurchargeTypeHoliday, Percentage: 100, IsActive: true})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/surcharges?month=2024-05", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, GetSurchargeReport(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var summaries []models.UserSurchargeSummary
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &summaries))
+		if assert.Len(t, summaries, 1) {
+			assert.Equal(t, user.ID, summaries[0].UserID)
+			assert.Equal(t, "2024-05", summaries[0].Month)
+			assert.Len(t, summaries[0].Shifts, 1) // Nachtschicht beginnt im Juni
+			if assert.Len(t, summaries[0].Lines, 1) {
+				// Fronleichnam in Bayern, 8 Stunden netto
+				assert.Equal(t, "Feiertag", summaries[0].Lines[0].Name)
+				assert.Equal(t, 8.0, summaries[0].Lines[0].Hours)
+			}
+		}
+	}
+}
+
+func TestGetSurchargeReport_InvalidMonth(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/surcharges?month=05-2024", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, GetSurchargeReport(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestCreateSurchargeRule(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	e := echo.New()
+	body := []byte(`{"name":"Spätarbeit","type":"night","percentage":10,"window_start":"18:00","window_end":"20:00"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/surcharge-rules", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, CreateSurchargeRule(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var rule models.SurchargeRule
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rule))
+		assert.Equal(t, "18:00", rule.WindowStart.String())
+		assert.True(t, rule.IsActive)
+	}
+
+	body = []byte(`{"name":"Ohne Fenster","type":"night","percentage":10}`)
+	req = httptest.NewRequest(http.MethodPost, "/api/surcharge-rules", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	if assert.NoError(t, CreateSurchargeRule(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestApplySurchargePreset(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	database.DB.Create(&models.SurchargeRule{Name: "Alt", Type: models.SurchargeTypeSunday, Percentage: 30, IsActive: true})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/surcharge-rules/preset", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, ApplySurchargePreset(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var rules []models.SurchargeRule
+		database.DB.Find(&rules)
+		assert.Len(t, rules, 9)
+		for _, rule := range rules {
+			assert.NotEqual(t, "Alt", rule.Name)
+		}
+	}
+}
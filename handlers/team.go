@@ -4,7 +4,6 @@ import (
 	"net/http"
 	"strconv"
 
-	"schichtplaner/database"
 	"schichtplaner/models"
 	"schichtplaner/utils"
 
@@ -18,11 +17,23 @@ func GetTeams(c echo.Context) error {
 	var teams []models.Team
 	var total int64
 
+	// Optionaler Standortfilter
+	query := tenantDB(c).Model(&models.Team{})
+	locationID, message := locationIDFromQuery(c)
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	if locationID != 0 {
+		query = query.Where("location_id = ?", locationID)
+	}
+
 	// Zähle die Gesamtanzahl
-	database.DB.Model(&models.Team{}).Count(&total)
+	query.Count(&total)
 
 	// Lade die paginierten Daten, sortiert nach SortOrder und Name
-	if err := database.DB.Preload("Users").Order("sort_order ASC, name ASC").Offset(params.Offset).Limit(params.PageSize).Find(&teams).Error; err != nil {
+	if err := query.Preload("Location").Preload("Users").Order("sort_order ASC, name ASC").Offset(params.Offset).Limit(params.PageSize).Find(&teams).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Laden der Teams",
 		})
@@ -36,7 +47,7 @@ func GetTeams(c echo.Context) error {
 func GetActiveTeams(c echo.Context) error {
 	var teams []models.Team
 
-	if err := database.DB.Where("is_active = ?", true).Preload("Users").Order("sort_order ASC, name ASC").Find(&teams).Error; err != nil {
+	if err := tenantDB(c).Where("is_active = ?", true).Preload("Users").Order("sort_order ASC, name ASC").Find(&teams).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Laden der aktiven Teams",
 		})
@@ -55,7 +66,7 @@ func GetTeam(c echo.Context) error {
 	}
 
 	var team models.Team
-	if err := database.DB.Preload("Users").First(&team, id).Error; err != nil {
+	if err := tenantDB(c).Preload("Location").Preload("Users").First(&team, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Team nicht gefunden",
 		})
@@ -79,12 +90,19 @@ func CreateTeam(c echo.Context) error {
 	validator := utils.NewValidator()
 	validator.RequiredString("Name", team.Name, "Name ist ein Pflichtfeld")
 
-	if err := validator.ValidateAndRespond(c); err != nil {
-		return err
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+	if message := validateLocationReference(tenantDB(c), team.LocationID); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
 	}
 
 	// Erstelle das Team
-	if err := database.DB.Create(&team).Error; err != nil {
+	if err := tenantDB(c).Create(&team).Error; err != nil {
 		c.Logger().Errorf("Fehler beim Erstellen des Teams: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Erstellen des Teams: " + err.Error(),
@@ -104,7 +122,7 @@ func UpdateTeam(c echo.Context) error {
 	}
 
 	var team models.Team
-	if err := database.DB.First(&team, id).Error; err != nil {
+	if err := tenantDB(c).First(&team, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Team nicht gefunden",
 		})
@@ -121,11 +139,18 @@ func UpdateTeam(c echo.Context) error {
 	validator := utils.NewValidator()
 	validator.RequiredString("Name", updateData.Name, "Name ist ein Pflichtfeld")
 
-	if err := validator.ValidateAndRespond(c); err != nil {
-		return err
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+	if message := validateLocationReference(tenantDB(c), updateData.LocationID); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
 	}
 
-	if err := database.DB.Model(&team).Updates(updateData).Error; err != nil {
+	if err := tenantDB(c).Model(&team).Updates(updateData).Error; err != nil {
 		c.Logger().Errorf("Fehler beim Aktualisieren des Teams: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Aktualisieren des Teams: " + err.Error(),
@@ -145,7 +170,7 @@ func DeleteTeam(c echo.Context) error {
 	}
 
 	var team models.Team
-	if err := database.DB.First(&team, id).Error; err != nil {
+	if err := tenantDB(c).First(&team, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Team nicht gefunden",
 		})
@@ -153,14 +178,14 @@ func DeleteTeam(c echo.Context) error {
 
 	// Prüfe, ob das Team noch Mitglieder hat
 	var count int64
-	database.DB.Model(&models.User{}).Where("team_id = ?", id).Count(&count)
+	tenantDB(c).Model(&models.User{}).Where("team_id = ?", id).Count(&count)
 	if count > 0 {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": "Team kann nicht gelöscht werden, da es noch Mitglieder hat",
 		})
 	}
 
-	if err := database.DB.Delete(&team).Error; err != nil {
+	if err := tenantDB(c).Delete(&team).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Löschen des Teams",
 		})
@@ -181,7 +206,7 @@ func ToggleTeamActive(c echo.Context) error {
 	}
 
 	var team models.Team
-	if err := database.DB.First(&team, id).Error; err != nil {
+	if err := tenantDB(c).First(&team, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Team nicht gefunden",
 		})
@@ -189,7 +214,7 @@ func ToggleTeamActive(c echo.Context) error {
 
 	team.IsActive = !team.IsActive
 
-	if err := database.DB.Save(&team).Error; err != nil {
+	if err := tenantDB(c).Save(&team).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Ändern des Status",
 		})
@@ -219,7 +244,7 @@ func AddUserToTeam(c echo.Context) error {
 
 	// Prüfe, ob das Team existiert
 	var team models.Team
-	if err := database.DB.First(&team, teamID).Error; err != nil {
+	if err := tenantDB(c).First(&team, teamID).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Team nicht gefunden",
 		})
@@ -227,7 +252,7 @@ func AddUserToTeam(c echo.Context) error {
 
 	// Prüfe, ob der Benutzer existiert
 	var user models.User
-	if err := database.DB.First(&user, requestData.UserID).Error; err != nil {
+	if err := tenantDB(c).First(&user, requestData.UserID).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Benutzer nicht gefunden",
 		})
@@ -235,7 +260,7 @@ func AddUserToTeam(c echo.Context) error {
 
 	// Füge den Benutzer zum Team hinzu
 	user.TeamID = &team.ID
-	if err := database.DB.Save(&user).Error; err != nil {
+	if err := tenantDB(c).Save(&user).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Hinzufügen des Benutzers zum Team",
 		})
@@ -264,7 +289,7 @@ func RemoveUserFromTeam(c echo.Context) error {
 
 	// Prüfe, ob der Benutzer im Team ist
 	var user models.User
-	if err := database.DB.Where("id = ? AND team_id = ?", userID, teamID).First(&user).Error; err != nil {
+	if err := tenantDB(c).Where("id = ? AND team_id = ?", userID, teamID).First(&user).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Benutzer nicht im Team gefunden",
 		})
@@ -272,7 +297,7 @@ func RemoveUserFromTeam(c echo.Context) error {
 
 	// Entferne den Benutzer aus dem Team
 	user.TeamID = nil
-	if err := database.DB.Save(&user).Error; err != nil {
+	if err := tenantDB(c).Save(&user).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Entfernen des Benutzers aus dem Team",
 		})
@@ -293,7 +318,7 @@ func GetTeamMembers(c echo.Context) error {
 	}
 
 	var users []models.User
-	if err := database.DB.Where("team_id = ?", teamID).Find(&users).Error; err != nil {
+	if err := tenantDB(c).Where("team_id = ?", teamID).Find(&users).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Laden der Team-Mitglieder",
 		})
@@ -316,7 +341,7 @@ func UpdateTeamOrder(c echo.Context) error {
 	}
 
 	var team models.Team
-	if err := database.DB.First(&team, orderData.ID).Error; err != nil {
+	if err := tenantDB(c).First(&team, orderData.ID).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Team nicht gefunden",
 		})
@@ -324,7 +349,7 @@ func UpdateTeamOrder(c echo.Context) error {
 
 	team.SortOrder = orderData.SortOrder
 
-	if err := database.DB.Save(&team).Error; err != nil {
+	if err := tenantDB(c).Save(&team).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Aktualisieren der Sortierung",
 		})
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+	"schichtplaner/utils"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TeamRuleEvaluation ist das Ergebnis der Regelprüfung eines Teams
+type TeamRuleEvaluation struct {
+	TeamID      uint                         `json:"team_id"`
+	From        time.Time                    `json:"from"`
+	To          time.Time                    `json:"to"`
+	IsCompliant bool                         `json:"is_compliant"` // Keine Verstöße gegen harte Regeln
+	Errors      int                          `json:"errors"`
+	Warnings    int                          `json:"warnings"`
+	Violations  []models.ComplianceViolation `json:"violations"`
+}
+
+// GetTeamRules gibt alle Regeln eines Teams zurück
+func GetTeamRules(c echo.Context) error {
+	team, err := loadTeamFromParam(c)
+	if err != nil || team == nil {
+		return err
+	}
+
+	var rules []models.TeamRule
+	if err := tenantDB(c).Where("team_id = ?", team.ID).Order("id ASC").Find(&rules).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Teamregeln",
+		})
+	}
+
+	return c.JSON(http.StatusOK, rules)
+}
+
+// CreateTeamRule legt eine neue Regel für ein Team an
+func CreateTeamRule(c echo.Context) error {
+	team, err := loadTeamFromParam(c)
+	if err != nil || team == nil {
+		return err
+	}
+
+	rule := models.TeamRule{IsActive: true}
+	if err := c.Bind(&rule); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Regel-Daten",
+		})
+	}
+	rule.ID = 0
+	rule.TeamID = team.ID
+
+	if message := validateTeamRule(&rule); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	if err := tenantDB(c).Create(&rule).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen der Teamregel",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateTeamRule aktualisiert eine Regel eines Teams
+func UpdateTeamRule(c echo.Context) error {
+	rule, err := loadTeamRuleFromParams(c)
+	if err != nil || rule == nil {
+		return err
+	}
+
+	updateData := *rule
+	if err := c.Bind(&updateData); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Regel-Daten",
+		})
+	}
+	updateData.ID = rule.ID
+	updateData.TeamID = rule.TeamID
+	updateData.CreatedAt = rule.CreatedAt
+
+	if message := validateTeamRule(&updateData); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	// Save statt Updates, damit auch is_active=false übernommen wird
+	if err := tenantDB(c).Save(&updateData).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Aktualisieren der Teamregel",
+		})
+	}
+
+	return c.JSON(http.StatusOK, updateData)
+}
+
+// DeleteTeamRule löscht eine Regel eines Teams
+func DeleteTeamRule(c echo.Context) error {
+	rule, err := loadTeamRuleFromParams(c)
+	if err != nil || rule == nil {
+		return err
+	}
+
+	if err := tenantDB(c).Delete(rule).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen der Teamregel",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Teamregel erfolgreich gelöscht",
+	})
+}
+
+// EvaluateTeamRules prüft die Schichten der Teammitglieder im Zeitraum gegen die aktiven Teamregeln.
+// Optional kann die Prüfung über user_id auf ein Mitglied beschränkt werden.
+func EvaluateTeamRules(c echo.Context) error {
+	team, err := loadTeamFromParam(c)
+	if err != nil || team == nil {
+		return err
+	}
+
+	from, err := time.Parse(time.RFC3339, c.QueryParam("from"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültiges Startdatum (from), erwartet RFC3339",
+		})
+	}
+	to, err := time.Parse(time.RFC3339, c.QueryParam("to"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültiges Enddatum (to), erwartet RFC3339",
+		})
+	}
+	if !to.After(from) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Enddatum muss nach dem Startdatum liegen",
+		})
+	}
+
+	membersQuery := tenantDB(c).Where("team_id = ?", team.ID)
+	if userIDParam := c.QueryParam("user_id"); userIDParam != "" {
+		userID, err := strconv.ParseUint(userIDParam, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültige Benutzer-ID",
+			})
+		}
+		membersQuery = membersQuery.Where("id = ?", userID)
+	}
+
+	var members []models.User
+	if err := membersQuery.Find(&members).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Teammitglieder",
+		})
+	}
+
+	var rules []models.TeamRule
+	if err := tenantDB(c).Where("team_id = ? AND is_active = ?", team.ID, true).Find(&rules).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Teamregeln",
+		})
+	}
+
+	evaluation := TeamRuleEvaluation{
+		TeamID:     team.ID,
+		From:       from,
+		To:         to,
+		Violations: make([]models.ComplianceViolation, 0),
+	}
+
+	for _, member := range members {
+		loc := member.Location()
+		evaluator := services.NewTeamRuleEvaluator(loc)
+
+		// Ein Tag Vorlauf, damit Schichtfolgen über den Periodenbeginn erkannt werden
+		var shifts []models.Shift
+		if err := tenantDB(c).
+			Where("user_id = ? AND end_time >= ? AND start_time <= ?", member.ID, from.AddDate(0, 0, -1), to).
+			Order("start_time ASC").
+			Find(&shifts).Error; err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Fehler beim Laden der Schichten",
+			})
+		}
+
+		for _, violation := range evaluator.Evaluate(rules, member.ID, shifts, from, to) {
+			if violation.Date.Before(startOfDayIn(from, loc)) || violation.Date.After(to) {
+				continue
+			}
+			evaluation.Violations = append(evaluation.Violations, violation)
+			if violation.Severity == models.SeverityError {
+				evaluation.Errors++
+			} else {
+				evaluation.Warnings++
+			}
+		}
+	}
+
+	evaluation.IsCompliant = evaluation.Errors == 0
+	return c.JSON(http.StatusOK, evaluation)
+}
+
+// validateTeamRule prüft Pflichtfelder und Parameter einer Regel und setzt die Standard-Verbindlichkeit.
+// Liefert die erste Fehlermeldung oder einen leeren String.
+func validateTeamRule(rule *models.TeamRule) string {
+	validator := utils.NewValidator()
+	validator.RequiredString("Name", rule.Name, "Name ist ein Pflichtfeld")
+	validator.RequiredString("Type", rule.Type, "Regeltyp ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return result.Errors[0]
+	}
+
+	if rule.Severity == "" {
+		rule.Severity = models.TeamRuleSeverityHard
+	}
+	if err := services.ValidateTeamRule(*rule); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// loadTeamFromParam lädt das Team aus dem Pfadparameter id; bei Fehlern wird direkt geantwortet und nil geliefert
+func loadTeamFromParam(c echo.Context) (*models.Team, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Team-ID",
+		})
+	}
+
+	var team models.Team
+	if err := tenantDB(c).First(&team, id).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Team nicht gefunden",
+		})
+	}
+	return &team, nil
+}
+
+// loadTeamRuleFromParams lädt die Regel aus den Pfadparametern id und rule_id
+func loadTeamRuleFromParams(c echo.Context) (*models.TeamRule, error) {
+	team, err := loadTeamFromParam(c)
+	if err != nil || team == nil {
+		return nil, err
+	}
+
+	ruleID, err := strconv.ParseUint(c.Param("rule_id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Regel-ID",
+		})
+	}
+
+	var rule models.TeamRule
+	if err := tenantDB(c).Where("team_id = ?", team.ID).First(&rule, ruleID).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Teamregel nicht gefunden",
+		})
+	}
+	return &rule, nil
+}
+
+// startOfDayIn liefert den Beginn des Kalendertags in der angegebenen Zeitzone
+func startOfDayIn(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
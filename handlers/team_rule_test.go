@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// createTeamRuleFixture legt ein Team mit einem Mitglied und fünf Frühschichten in Folge an
+func createTeamRuleFixture() (models.Team, models.User) {
+	team := models.Team{Name: "Station A", IsActive: true}
+	database.DB.Create(&team)
+
+	user := models.User{Username: "regel", Email: "regel@example.com", Password: "hashedpassword", Name: "Regel User", TeamID: &team.ID}
+	database.DB.Create(&user)
+
+	for day := 0; day < 5; day++ {
+		database.DB.Create(&models.Shift{
+			UserID: user.ID, BreakTime: 30,
+			StartTime: time.Date(2024, 1, 8+day, 6, 0, 0, 0, time.UTC),
+			EndTime:   time.Date(2024, 1, 8+day, 14, 0, 0, 0, time.UTC),
+		})
+	}
+	return team, user
+}
+
+func TestCreateTeamRule(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	team, _ := createTeamRuleFixture()
+
+	body, _ := json.Marshal(models.TeamRule{
+		Name:       "Max. 3 Tage",
+		Type:       models.TeamRuleMaxConsecutiveDays,
+		Parameters: models.TeamRuleParameters{Max: 3},
+	})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(team.ID)))
+
+	if assert.NoError(t, CreateTeamRule(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+
+		var rule models.TeamRule
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rule))
+		assert.Equal(t, team.ID, rule.TeamID)
+		assert.Equal(t, models.TeamRuleSeverityHard, rule.Severity)
+		assert.Equal(t, 3, rule.Parameters.Max)
+		assert.True(t, rule.IsActive)
+	}
+}
+
+func TestCreateTeamRule_InvalidParameters(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	team, _ := createTeamRuleFixture()
+
+	body, _ := json.Marshal(models.TeamRule{Name: "Ohne Grenze", Type: models.TeamRuleMaxConsecutiveDays})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(team.ID)))
+
+	if assert.NoError(t, CreateTeamRule(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestUpdateTeamRule_Deactivate(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	team, _ := createTeamRuleFixture()
+	rule := models.TeamRule{TeamID: team.ID, Name: "Max. 3 Tage", Type: models.TeamRuleMaxConsecutiveDays, IsActive: true,
+		Parameters: models.TeamRuleParameters{Max: 3}}
+	database.DB.Create(&rule)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBufferString(`{"is_active": false, "severity": "soft"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "rule_id")
+	c.SetParamValues(strconv.Itoa(int(team.ID)), strconv.Itoa(int(rule.ID)))
+
+	if assert.NoError(t, UpdateTeamRule(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var stored models.TeamRule
+		database.DB.First(&stored, rule.ID)
+		assert.False(t, stored.IsActive)
+		assert.Equal(t, models.TeamRuleSeveritySoft, stored.Severity)
+		assert.Equal(t, 3, stored.Parameters.Max)
+	}
+}
+
+func TestDeleteTeamRule_WrongTeam(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	team, _ := createTeamRuleFixture()
+	other := models.Team{Name: "Station B"}
+	database.DB.Create(&other)
+	rule := models.TeamRule{TeamID: team.ID, Name: "Max. 3 Tage", Type: models.TeamRuleMaxConsecutiveDays, Parameters: models.TeamRuleParameters{Max: 3}}
+	database.DB.Create(&rule)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "rule_id")
+	c.SetParamValues(strconv.Itoa(int(other.ID)), strconv.Itoa(int(rule.ID)))
+
+	if assert.NoError(t, DeleteTeamRule(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestEvaluateTeamRules(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	team, user := createTeamRuleFixture()
+	database.DB.Create(&models.TeamRule{TeamID: team.ID, Name: "Max. 3 Tage", Type: models.TeamRuleMaxConsecutiveDays,
+		Severity: models.TeamRuleSeveritySoft, IsActive: true, Parameters: models.TeamRuleParameters{Max: 3}})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?from=2024-01-01T00:00:00Z&to=2024-01-31T23:59:59Z", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(team.ID)))
+
+	if assert.NoError(t, EvaluateTeamRules(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var evaluation TeamRuleEvaluation
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &evaluation))
+		assert.True(t, evaluation.IsCompliant)
+		assert.Equal(t, 1, evaluation.Warnings)
+		if assert.Len(t, evaluation.Violations, 1) {
+			assert.Equal(t, user.ID, evaluation.Violations[0].UserID)
+		}
+	}
+}
+
+func TestEvaluateTeamRules_InvalidRange(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	team, _ := createTeamRuleFixture()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?from=2024-01-31T00:00:00Z&to=2024-01-01T00:00:00Z", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(team.ID)))
+
+	if assert.NoError(t, EvaluateTeamRules(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestGetScheduleCompliance_IncludesTeamRules(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	team, _ := createTeamRuleFixture()
+	database.DB.Create(&models.TeamRule{TeamID: team.ID, Name: "Max. 3 Tage", Type: models.TeamRuleMaxConsecutiveDays,
+		IsActive: true, Parameters: models.TeamRuleParameters{Max: 3}})
+
+	schedule := models.Schedule{
+		Name:      "Januar 2024",
+		StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC),
+	}
+	database.DB.Create(&schedule)
+	database.DB.Model(&models.Shift{}).Where("1 = 1").Update("schedule_id", schedule.ID)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(schedule.ID)))
+
+	if assert.NoError(t, GetScheduleCompliance(c)) {
+		var report ScheduleComplianceReport
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.False(t, report.IsCompliant)
+		if assert.Len(t, report.Violations, 1) {
+			assert.Equal(t, models.TeamRuleMaxConsecutiveDays, report.Violations[0].Rule)
+			assert.NotZero(t, report.Violations[0].RuleID)
+		}
+	}
+}
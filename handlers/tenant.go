@@ -0,0 +1,353 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+	"schichtplaner/services"
+	"schichtplaner/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// Schlüssel für die von TenantMiddleware im Echo-Kontext abgelegten Werte
+const (
+	tenantIDContextKey    = "tenant_id"
+	currentUserContextKey = "current_user"
+)
+
+// TenantMiddleware ermittelt die Organisation einer Anfrage und beschränkt alle Datenbankzugriffe der Handler auf sie.
+// Reihenfolge: API-Token (Authorization: Bearer …) → Subdomain unterhalb von models.TenantBaseDomain() →
+// Standardorganisation, sofern keine Anmeldung erforderlich ist. Die Subdomain wählt die Organisation nur zusammen
+// mit einem API-Token eines ihrer Benutzer; ohne Token wird sie auch ohne Anmeldepflicht abgelehnt.
+func TenantMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var tenantID uint
+		authenticated := false
+
+		if bearerToken(c) != "" {
+			token, err := authenticateAPIToken(c, bearerToken(c))
+			if err != nil || token == nil {
+				return err
+			}
+			tenantID = token.TenantID
+			authenticated = true
+			c.Set(currentUserContextKey, &token.User)
+		}
+
+		if slug, ok := models.TenantSlugFromHost(c.Request().Host, models.TenantBaseDomain()); ok {
+			if !authenticated {
+				return c.JSON(http.StatusUnauthorized, map[string]string{
+					"error": "Anmeldung erforderlich",
+				})
+			}
+			var tenant models.Tenant
+			if err := database.DB.Where("slug = ?", slug).First(&tenant).Error; err != nil {
+				return c.JSON(http.StatusNotFound, map[string]string{
+					"error": "Organisation nicht gefunden",
+				})
+			}
+			if tenantID != 0 && tenantID != tenant.ID {
+				return c.JSON(http.StatusForbidden, map[string]string{
+					"error": "Token gehört zu einer anderen Organisation",
+				})
+			}
+			tenantID = tenant.ID
+		}
+
+		if !authenticated && models.AuthRequired() {
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error": "Anmeldung erforderlich",
+			})
+		}
+		if tenantID == 0 {
+			tenantID = models.DefaultTenantID
+		}
+
+		var tenant models.Tenant
+		if err := database.DB.First(&tenant, tenantID).Error; err != nil {
+			return c.JSON(http.StatusNotFound, map[string]string{
+				"error": "Organisation nicht gefunden",
+			})
+		}
+		if !tenant.IsActive {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "Organisation ist deaktiviert",
+			})
+		}
+
+		c.Set(tenantIDContextKey, tenant.ID)
+		c.SetRequest(c.Request().WithContext(database.WithTenant(c.Request().Context(), tenant.ID)))
+		return next(c)
+	}
+}
+
+// authenticateAPIToken prüft das mitgesendete API-Token plain; bei ungültigem Token wird direkt geantwortet und nil geliefert
+func authenticateAPIToken(c echo.Context, plain string) (*models.APIToken, error) {
+	// Die Organisation ist hier noch unbekannt, daher ohne Beschränkung suchen
+	var token models.APIToken
+	if err := database.DB.Preload("User").Where("token_hash = ?", services.HashAPIToken(plain)).First(&token).Error; err != nil {
+		return nil, c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Ungültiges API-Token",
+		})
+	}
+	now := time.Now()
+	if token.IsExpired(now) {
+		return nil, c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "API-Token ist abgelaufen",
+		})
+	}
+	if !token.User.IsActive || token.User.TenantID != token.TenantID {
+		return nil, c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "Benutzer des API-Tokens ist deaktiviert",
+		})
+	}
+
+	database.DB.Model(&token).UpdateColumn("last_used_at", now)
+	return &token, nil
+}
+
+// bearerToken liest das Token aus dem Header Authorization: Bearer …
+func bearerToken(c echo.Context) string {
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+	scheme, value, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
+
+// currentTenantID liefert die Organisation der Anfrage; ohne TenantMiddleware die Standardorganisation
+func currentTenantID(c echo.Context) uint {
+	if tenantID, ok := c.Get(tenantIDContextKey).(uint); ok && tenantID != 0 {
+		return tenantID
+	}
+	return models.DefaultTenantID
+}
+
+// currentUser liefert den per API-Token angemeldeten Benutzer oder nil
+func currentUser(c echo.Context) *models.User {
+	user, _ := c.Get(currentUserContextKey).(*models.User)
+	return user
+}
+
+// tenantDB liefert die auf die Organisation der Anfrage beschränkte Datenbankverbindung
+func tenantDB(c echo.Context) *gorm.DB {
+	return database.ForTenant(c.Request().Context(), currentTenantID(c))
+}
+
+// canManageUser prüft, ob der angemeldete Benutzer Daten des Benutzers userID verwalten darf.
+// Ohne Anmeldung (SCHICHTPLANER_REQUIRE_AUTH nicht gesetzt) ist alles erlaubt; das betrifft nur die
+// Standardorganisation, da andere Organisationen stets ein API-Token erfordern.
+func canManageUser(c echo.Context, userID uint) bool {
+	user := currentUser(c)
+	return user == nil || user.IsAdmin || user.ID == userID
+}
+
+// GetCurrentUser gibt den angemeldeten Benutzer und seine Organisation zurück
+func GetCurrentUser(c echo.Context) error {
+	var tenant models.Tenant
+	if err := database.DB.First(&tenant, currentTenantID(c)).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Organisation nicht gefunden",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"user":   currentUser(c),
+		"tenant": tenant,
+	})
+}
+
+// GetTenant gibt die Organisation der Anfrage zurück
+func GetTenant(c echo.Context) error {
+	var tenant models.Tenant
+	if err := database.DB.First(&tenant, currentTenantID(c)).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Organisation nicht gefunden",
+		})
+	}
+
+	return c.JSON(http.StatusOK, tenant)
+}
+
+// UpdateTenant ändert Name und Kürzel der Organisation der Anfrage (nur Administratoren)
+func UpdateTenant(c echo.Context) error {
+	if user := currentUser(c); user != nil && !user.IsAdmin {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Nur Administratoren dürfen die Organisation ändern",
+		})
+	}
+
+	var tenant models.Tenant
+	if err := database.DB.First(&tenant, currentTenantID(c)).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Organisation nicht gefunden",
+		})
+	}
+
+	var request struct {
+		Name string `json:"name"`
+		Slug string `json:"slug"`
+	}
+	request.Name, request.Slug = tenant.Name, tenant.Slug
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Organisationsdaten",
+		})
+	}
+	request.Slug = strings.ToLower(strings.TrimSpace(request.Slug))
+
+	validator := utils.NewValidator()
+	validator.RequiredString("Name", request.Name, "Name ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+	if err := services.ValidateTenantSlug(request.Slug); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	var duplicates int64
+	database.DB.Model(&models.Tenant{}).Where("slug = ? AND id <> ?", request.Slug, tenant.ID).Count(&duplicates)
+	if duplicates > 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Kürzel wird bereits von einer anderen Organisation verwendet",
+		})
+	}
+
+	tenant.Name, tenant.Slug = request.Name, request.Slug
+	if err := database.DB.Save(&tenant).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Aktualisieren der Organisation",
+		})
+	}
+
+	return c.JSON(http.StatusOK, tenant)
+}
+
+// GetUserAPITokens gibt die API-Tokens eines Benutzers zurück (ohne Klartext)
+func GetUserAPITokens(c echo.Context) error {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return err
+	}
+	if !canManageUser(c, user.ID) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Keine Berechtigung für die API-Tokens dieses Benutzers",
+		})
+	}
+
+	var tokens []models.APIToken
+	if err := tenantDB(c).Where("user_id = ?", user.ID).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der API-Tokens",
+		})
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// CreateUserAPIToken erzeugt ein API-Token für einen Benutzer. Der Klartext ist nur in dieser Antwort enthalten.
+func CreateUserAPIToken(c echo.Context) error {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return err
+	}
+	if !canManageUser(c, user.ID) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Keine Berechtigung für die API-Tokens dieses Benutzers",
+		})
+	}
+
+	var request struct {
+		Name      string     `json:"name"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Tokendaten",
+		})
+	}
+
+	validator := utils.NewValidator()
+	validator.RequiredString("Name", request.Name, "Name ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(time.Now()) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ablaufdatum muss in der Zukunft liegen",
+		})
+	}
+
+	plain, hash, prefix, err := services.GenerateAPIToken()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erzeugen des API-Tokens",
+		})
+	}
+
+	token := models.APIToken{
+		UserID:    user.ID,
+		Name:      request.Name,
+		TokenHash: hash,
+		Prefix:    prefix,
+		ExpiresAt: request.ExpiresAt,
+	}
+	if err := tenantDB(c).Create(&token).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen des API-Tokens",
+		})
+	}
+	token.Token = plain
+
+	return c.JSON(http.StatusCreated, token)
+}
+
+// DeleteUserAPIToken widerruft ein API-Token eines Benutzers
+func DeleteUserAPIToken(c echo.Context) error {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return err
+	}
+	if !canManageUser(c, user.ID) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Keine Berechtigung für die API-Tokens dieses Benutzers",
+		})
+	}
+
+	tokenID, err := strconv.ParseUint(c.Param("token_id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Token-ID",
+		})
+	}
+
+	var token models.APIToken
+	if err := tenantDB(c).Where("user_id = ?", user.ID).First(&token, tokenID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "API-Token nicht gefunden",
+		})
+	}
+
+	if err := tenantDB(c).Delete(&token).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen des API-Tokens",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "API-Token erfolgreich gelöscht",
+	})
+}
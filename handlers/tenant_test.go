@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+	"schichtplaner/services"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupTenantFixtures legt die Standardorganisation und die Organisation "nord" (ID 2) mit je einem Benutzer und Team an
+func setupTenantFixtures(t *testing.T) (models.User, models.User) {
+	assert.NoError(t, database.EnsureDefaultTenant(database.DB))
+	assert.NoError(t, database.DB.Create(&models.Tenant{ID: 2, Name: "Werk Nord", Slug: "nord", IsActive: true}).Error)
+
+	standard := models.User{Username: "anna", Email: "anna@example.com", Password: "x", AccountNumber: "1001", Name: "Anna", IsActive: true}
+	assert.NoError(t, database.DB.Create(&standard).Error)
+	assert.NoError(t, database.DB.Create(&models.Team{Name: "Pflege"}).Error)
+
+	north := models.User{Username: "anna", Email: "anna@example.com", Password: "x", AccountNumber: "1001", Name: "Anna Nord", IsActive: true, IsAdmin: true}
+	north.TenantID = 2
+	assert.NoError(t, database.DB.Create(&north).Error)
+	team := models.Team{Name: "Pflege"}
+	team.TenantID = 2
+	assert.NoError(t, database.DB.Create(&team).Error)
+	return standard, north
+}
+
+// createTestAPIToken legt ein API-Token für user an und liefert den Klartext
+func createTestAPIToken(t *testing.T, user models.User, expiresAt *time.Time) string {
+	plain, hash, prefix, err := services.GenerateAPIToken()
+	assert.NoError(t, err)
+	token := models.APIToken{UserID: user.ID, Name: "Test", TokenHash: hash, Prefix: prefix, ExpiresAt: expiresAt}
+	token.TenantID = user.TenantID
+	assert.NoError(t, database.DB.Create(&token).Error)
+	return plain
+}
+
+// serveWithTenant führt handler hinter TenantMiddleware aus und liefert Status und Antwort
+func serveWithTenant(t *testing.T, req *http.Request, handler echo.HandlerFunc) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.GET("/api/teams", handler, TenantMiddleware)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestTenantMiddleware_Resolution(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+	defer models.SetTenantBaseDomain("")
+	defer models.SetAuthRequired(false)
+
+	standard, north := setupTenantFixtures(t)
+	northToken := createTestAPIToken(t, north, nil)
+	expired := time.Now().Add(-time.Hour)
+	expiredToken := createTestAPIToken(t, standard, &expired)
+	models.SetTenantBaseDomain("schichtplaner.example")
+
+	teamsOf := func(host, token string) (int, []models.Team) {
+		req := httptest.NewRequest(http.MethodGet, "/api/teams", nil)
+		req.Host = host
+		if token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+		rec := serveWithTenant(t, req, GetTeams)
+		var response struct {
+			Data []models.Team `json:"data"`
+		}
+		if rec.Code == http.StatusOK {
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		}
+		return rec.Code, response.Data
+	}
+
+	// Ohne Token und Subdomain: Standardorganisation
+	code, teams := teamsOf("localhost:3000", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, teams, 1)
+
+	// Ohne Token erlaubt die Subdomain keinen Zugriff auf eine fremde Organisation, auch ohne Anmeldepflicht
+	code, _ = teamsOf("nord.schichtplaner.example", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = teamsOf("sued.schichtplaner.example", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = teamsOf("sued.schichtplaner.example", northToken)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// Token der Organisation nord
+	code, teams = teamsOf("localhost", northToken)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Len(t, teams, 1)
+	code, teams = teamsOf("nord.schichtplaner.example", northToken)
+	assert.Equal(t, http.StatusOK, code)
+	var northTeam models.Team
+	database.DB.Where("tenant_id = ?", 2).First(&northTeam)
+	if assert.Len(t, teams, 1) {
+		assert.Equal(t, northTeam.ID, teams[0].ID)
+	}
+	code, _ = teamsOf("default.schichtplaner.example", northToken)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	// Ungültige und abgelaufene Tokens
+	code, _ = teamsOf("localhost", "sp_falsch")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = teamsOf("localhost", expiredToken)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	// Mit Anmeldepflicht reicht die Subdomain nicht aus
+	models.SetAuthRequired(true)
+	code, _ = teamsOf("nord.schichtplaner.example", "")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	code, _ = teamsOf("nord.schichtplaner.example", northToken)
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestTenantIsolation_Handlers(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	standard, _ := setupTenantFixtures(t)
+
+	// Ein Benutzer der Standardorganisation ist in der Organisation nord nicht sichtbar
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(tenantIDContextKey, uint(2))
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.FormatUint(uint64(standard.ID), 10))
+	assert.NoError(t, GetUser(c))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// Teamnamen sind je Organisation eindeutig
+	req = httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"name":"Pflege"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set(tenantIDContextKey, uint(2))
+	assert.NoError(t, CreateTeam(c))
+	assert.NotEqual(t, http.StatusCreated, rec.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"name":"Küche"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set(tenantIDContextKey, uint(2))
+	assert.NoError(t, CreateTeam(c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var team models.Team
+	assert.NoError(t, database.DB.Where("name = ?", "Küche").First(&team).Error)
+	assert.Equal(t, uint(2), team.TenantID)
+}
+
+func TestUserAPITokens(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	standard, _ := setupTenantFixtures(t)
+	id := strconv.FormatUint(uint64(standard.ID), 10)
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"name":"Dienstplan-App"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	assert.NoError(t, CreateUserAPIToken(c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var created models.APIToken
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.Token)
+	assert.Equal(t, created.Token[:len(created.Prefix)], created.Prefix)
+
+	var stored models.APIToken
+	assert.NoError(t, database.DB.First(&stored, created.ID).Error)
+	assert.Equal(t, services.HashAPIToken(created.Token), stored.TokenHash)
+
+	// Die Liste enthält weder Klartext noch Hash
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+	assert.NoError(t, GetUserAPITokens(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), created.Token)
+	assert.NotContains(t, rec.Body.String(), stored.TokenHash)
+
+	// Andere Benutzer dürfen die Tokens nicht verwalten
+	other := models.User{Username: "ben", Email: "ben@example.com", Password: "x", AccountNumber: "1002", Name: "Ben", IsActive: true}
+	assert.NoError(t, database.DB.Create(&other).Error)
+	req = httptest.NewRequest(http.MethodDelete, "/", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set(currentUserContextKey, &other)
+	c.SetParamNames("id", "token_id")
+	c.SetParamValues(id, strconv.FormatUint(uint64(created.ID), 10))
+	assert.NoError(t, DeleteUserAPIToken(c))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id", "token_id")
+	c.SetParamValues(id, strconv.FormatUint(uint64(created.ID), 10))
+	assert.NoError(t, DeleteUserAPIToken(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Error(t, database.DB.First(&models.APIToken{}, created.ID).Error)
+}
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+	"schichtplaner/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// TimeAccountYear ist die Jahresübersicht des Arbeitszeitkontos eines Benutzers
+type TimeAccountYear struct {
+	UserID         uint                      `json:"user_id"`
+	Year           int                       `json:"year"`
+	OpeningBalance float64                   `json:"opening_balance"` // Übertrag aus dem Vorjahr
+	ClosingBalance float64                   `json:"closing_balance"`
+	Months         []models.TimeAccountMonth `json:"months"`
+}
+
+// GetUserTimeAccount gibt die Monatsabschlüsse eines Jahres (year, Standard: aktuelles Jahr) zurück
+func GetUserTimeAccount(c echo.Context) error {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return err
+	}
+
+	year := time.Now().In(user.Location()).Year()
+	if value := c.QueryParam("year"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1900 || parsed > 9999 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültiges Jahr",
+			})
+		}
+		year = parsed
+	}
+
+	months, err := timeAccountMonths(tenantDB(c), *user, time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(year, time.December, 1, 0, 0, 0, 0, time.UTC), false)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Berechnen des Arbeitszeitkontos",
+		})
+	}
+
+	return c.JSON(http.StatusOK, TimeAccountYear{
+		UserID:         user.ID,
+		Year:           year,
+		OpeningBalance: months[0].OpeningBalance,
+		ClosingBalance: months[len(months)-1].ClosingBalance,
+		Months:         months,
+	})
+}
+
+// GetUserTimeAccountMonth gibt den Kontoauszug eines Monats (YYYY-MM) mit Tageszeilen und Korrekturen zurück
+func GetUserTimeAccountMonth(c echo.Context) error {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return err
+	}
+
+	month, err := models.ParseMonth(c.Param("month"), time.UTC)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültiger Monat, erwartet YYYY-MM",
+		})
+	}
+
+	months, err := timeAccountMonths(tenantDB(c), *user, month, month, true)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Berechnen des Arbeitszeitkontos",
+		})
+	}
+
+	sheet := months[0]
+	tenantDB(c).Where("user_id = ? AND date >= ? AND date < ?", user.ID, month, month.AddDate(0, 1, 0)).
+		Order("date ASC").Find(&sheet.Corrections)
+
+	return c.JSON(http.StatusOK, sheet)
+}
+
+// GetTimeAccountCorrections gibt alle manuellen Korrekturen eines Benutzers zurück
+func GetTimeAccountCorrections(c echo.Context) error {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return err
+	}
+
+	var corrections []models.TimeAccountCorrection
+	if err := tenantDB(c).Where("user_id = ?", user.ID).Order("date ASC").Find(&corrections).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Korrekturen",
+		})
+	}
+
+	return c.JSON(http.StatusOK, corrections)
+}
+
+// CreateTimeAccountCorrection bucht eine manuelle Korrektur mit Begründung auf das Arbeitszeitkonto
+func CreateTimeAccountCorrection(c echo.Context) error {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return err
+	}
+
+	var correction models.TimeAccountCorrection
+	if err := c.Bind(&correction); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Korrekturdaten",
+		})
+	}
+	correction.ID = 0
+	correction.UserID = user.ID
+
+	validator := utils.NewValidator()
+	validator.RequiredTime("Date", correction.Date, "Datum ist ein Pflichtfeld")
+	validator.RequiredString("Reason", correction.Reason, "Begründung ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+	if correction.Hours == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Stunden dürfen nicht 0 sein",
+		})
+	}
+	correction.Date = calendarDay(correction.Date)
+
+	if message := timesheetLockMessage(tenantDB(c), user.ID, correction.Date.Add(12*time.Hour)); message != "" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": message,
+		})
+	}
+
+	if err := tenantDB(c).Create(&correction).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen der Korrektur",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, correction)
+}
+
+// DeleteTimeAccountCorrection löscht eine manuelle Korrektur
+func DeleteTimeAccountCorrection(c echo.Context) error {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return err
+	}
+
+	correctionID, err := strconv.ParseUint(c.Param("correction_id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Korrektur-ID",
+		})
+	}
+
+	var correction models.TimeAccountCorrection
+	if err := tenantDB(c).Where("user_id = ?", user.ID).First(&correction, correctionID).Error; err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Korrektur nicht gefunden",
+		})
+	}
+
+	if message := timesheetLockMessage(tenantDB(c), user.ID, correction.Date.Add(12*time.Hour)); message != "" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": message,
+		})
+	}
+
+	if err := tenantDB(c).Delete(&correction).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen der Korrektur",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Korrektur erfolgreich gelöscht",
+	})
+}
+
+// GetTeamTimeAccounts fasst die Arbeitszeitkonten aller Teammitglieder vom Jahresbeginn bis einschließlich month
+// (YYYY-MM, Standard: aktueller Monat) zusammen
+func GetTeamTimeAccounts(c echo.Context) error {
+	team, err := loadTeamFromParam(c)
+	if err != nil || team == nil {
+		return err
+	}
+
+	now := time.Now().In(models.OrganisationLocation())
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if value := c.QueryParam("month"); value != "" {
+		to, err = models.ParseMonth(value, time.UTC)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültiger Monat (month), erwartet YYYY-MM",
+			})
+		}
+	}
+	from := time.Date(to.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	var members []models.User
+	if err := tenantDB(c).Where("team_id = ?", team.ID).Order("name ASC").Find(&members).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Teammitglieder",
+		})
+	}
+
+	summaries := make([]models.TimeAccountSummary, 0, len(members))
+	for _, member := range members {
+		months, err := timeAccountMonths(tenantDB(c), member, from, to, false)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Fehler beim Berechnen des Arbeitszeitkontos",
+			})
+		}
+
+		summary := services.SummarizeTimeAccount(months)
+		summary.UserID = member.ID
+		summary.UserName = member.Name
+		summaries = append(summaries, summary)
+	}
+
+	return c.JSON(http.StatusOK, summaries)
+}
+
+// timeAccountMonths berechnet das Arbeitszeitkonto eines Benutzers ab Kontobeginn und liefert die Monate von from bis
+// einschließlich to (Monatsbeginn, 00:00 Uhr UTC). Kontobeginn ist der Monat der ersten Schicht, des ersten Einsatzes
+// in einer Rufbereitschaft, Abwesenheit, Korrektur oder des ersten Vertrags; das Soll ergibt sich tagesgenau aus dem
+// jeweils gültigen Vertrag.
+func timeAccountMonths(db *gorm.DB, user models.User, from, to time.Time, withDays bool) ([]models.TimeAccountMonth, error) {
+	loc := user.Location()
+	end := to.AddDate(0, 1, 0)
+	endInstant := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, loc)
+
+	var shifts []models.Shift
+	if err := db.Where("user_id = ? AND start_time < ?", user.ID, endInstant).Order("start_time ASC").Find(&shifts).Error; err != nil {
+		return nil, err
+	}
+	var callOuts []models.TimeEntry
+	if err := db.Preload("Breaks").Where("user_id = ? AND on_call_duty_id IS NOT NULL AND clock_in < ?", user.ID, endInstant).
+		Order("clock_in ASC").Find(&callOuts).Error; err != nil {
+		return nil, err
+	}
+	var absences []models.Absence
+	if err := db.Where("user_id = ? AND status = ? AND start_date < ?", user.ID, models.AbsenceStatusApproved, end).Find(&absences).Error; err != nil {
+		return nil, err
+	}
+	var corrections []models.TimeAccountCorrection
+	if err := db.Where("user_id = ? AND date < ?", user.ID, end).Find(&corrections).Error; err != nil {
+		return nil, err
+	}
+	contracts, err := loadUserContracts(db, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := from
+	earliest := func(day time.Time) {
+		month := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if month.Before(start) {
+			start = month
+		}
+	}
+	if len(shifts) > 0 {
+		earliest(shifts[0].StartTime.In(loc))
+	}
+	if len(callOuts) > 0 {
+		earliest(callOuts[0].ClockIn.In(loc))
+	}
+	for _, absence := range absences {
+		earliest(absence.StartDate)
+	}
+	for _, correction := range corrections {
+		earliest(correction.Date)
+	}
+	for _, contract := range contracts {
+		earliest(contract.ValidFrom)
+	}
+
+	rules, err := loadAbsenceCreditRules(db)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := loadHolidayCalendar(db, models.OrganisationState())
+	if err != nil {
+		return nil, err
+	}
+
+	calculator := services.NewTimeAccountCalculator(loc, services.ContractTarget(contracts, user.WeeklyHours), calendar, rules, time.Now())
+	months := calculator.Months(start, to, 0, shifts, callOuts, absences, corrections, withDays)
+	for i := range months {
+		months[i].UserID = user.ID
+	}
+	return months[len(months)-monthsBetween(from, to):], nil
+}
+
+// monthsBetween liefert die Anzahl der Monate von from bis einschließlich to
+func monthsBetween(from, to time.Time) int {
+	return (to.Year()-from.Year())*12 + int(to.Month()-from.Month()) + 1
+}
+
+// loadUserFromParam lädt den Benutzer aus dem Pfadparameter id; bei Fehlern wird direkt geantwortet und nil geliefert
+func loadUserFromParam(c echo.Context) (*models.User, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Benutzer-ID",
+		})
+	}
+
+	var user models.User
+	if err := tenantDB(c).First(&user, id).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Benutzer nicht gefunden",
+		})
+	}
+	return &user, nil
+}
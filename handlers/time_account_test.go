@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// createTimeAccountFixture legt einen Teammitarbeiter mit 40 Wochenstunden, Schichten im Dezember 2023 und Juni 2024,
+// einem genehmigten Urlaubstag und einer Korrektur an
+func createTimeAccountFixture() (models.Team, models.User) {
+	loc := models.OrganisationLocation()
+	team := models.Team{Name: "Konto Team", IsActive: true}
+	database.DB.Create(&team)
+
+	user := models.User{Username: "konto", Email: "konto@example.com", Password: "hashedpassword", Name: "Konto User", TeamID: &team.ID, WeeklyHours: 40}
+	database.DB.Create(&user)
+
+	database.DB.Create(&models.Shift{UserID: user.ID, BreakTime: 30,
+		StartTime: time.Date(2023, 12, 29, 8, 0, 0, 0, loc), EndTime: time.Date(2023, 12, 29, 16, 30, 0, 0, loc)})
+	database.DB.Create(&models.Shift{UserID: user.ID, BreakTime: 30,
+		StartTime: time.Date(2024, 6, 3, 6, 0, 0, 0, loc), EndTime: time.Date(2024, 6, 3, 16, 30, 0, 0, loc)})
+	database.DB.Create(&models.Absence{UserID: user.ID, Type: models.AbsenceTypeVacation, Status: models.AbsenceStatusApproved,
+		StartDate: time.Date(2024, 6, 4, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 6, 4, 0, 0, 0, 0, time.UTC)})
+	database.DB.Create(&models.TimeAccountCorrection{UserID: user.ID, Date: time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC), Hours: 4, Reason: "Übertrag Altsystem"})
+	return team, user
+}
+
+func TestGetUserTimeAccountMonth(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	_, user := createTimeAccountFixture()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "month")
+	c.SetParamValues(strconv.Itoa(int(user.ID)), "2024-06")
+
+	if assert.NoError(t, GetUserTimeAccountMonth(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var sheet models.TimeAccountMonth
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &sheet))
+		assert.Equal(t, "2024-06", sheet.Month)
+		assert.Equal(t, 160.0, sheet.TargetHours)
+		assert.Equal(t, 10.0, sheet.ActualHours)
+		assert.Equal(t, 8.0, sheet.AbsenceCreditHours)
+		assert.Equal(t, 4.0, sheet.CorrectionHours)
+		assert.Equal(t, -138.0, sheet.Balance)
+		assert.Len(t, sheet.Days, 30)
+		assert.Len(t, sheet.Corrections, 1)
+		// Kontobeginn im Dezember 2023: 8 Ist gegen 8 Soll am 29.12., Soll Januar bis Mai wird belastet
+		assert.Less(t, sheet.OpeningBalance, 0.0)
+	}
+}
+
+func TestGetUserTimeAccount(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	_, user := createTimeAccountFixture()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?year=2024", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(user.ID)))
+
+	if assert.NoError(t, GetUserTimeAccount(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var account TimeAccountYear
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &account))
+		assert.Equal(t, 2024, account.Year)
+		assert.Len(t, account.Months, 12)
+		// Dezember 2023: 21 Werktage, davon 2 Feiertage, à 8 Soll gegen 8 Ist
+		assert.Equal(t, -144.0, account.OpeningBalance)
+		assert.Equal(t, account.Months[11].ClosingBalance, account.ClosingBalance)
+		assert.Equal(t, account.Months[4].ClosingBalance, account.Months[5].OpeningBalance)
+	}
+}
+
+func TestCreateTimeAccountCorrection(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	_, user := createTimeAccountFixture()
+
+	e := echo.New()
+	for _, testCase := range []struct {
+		body   string
+		status int
+	}{
+		{`{"date":"2024-06-10T00:00:00Z","hours":-1.5,"reason":"Zu früh gegangen"}`, http.StatusCreated},
+		{`{"date":"2024-06-10T00:00:00Z","hours":2}`, http.StatusBadRequest},
+		{`{"date":"2024-06-10T00:00:00Z","reason":"Ohne Stunden"}`, http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(testCase.body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(strconv.Itoa(int(user.ID)))
+
+		if assert.NoError(t, CreateTimeAccountCorrection(c)) {
+			assert.Equal(t, testCase.status, rec.Code, testCase.body)
+		}
+	}
+
+	var count int64
+	database.DB.Model(&models.TimeAccountCorrection{}).Where("user_id = ?", user.ID).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestGetTeamTimeAccounts(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	team, user := createTimeAccountFixture()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?month=2024-06", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(team.ID)))
+
+	if assert.NoError(t, GetTeamTimeAccounts(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var summaries []models.TimeAccountSummary
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &summaries))
+		if assert.Len(t, summaries, 1) {
+			assert.Equal(t, user.ID, summaries[0].UserID)
+			assert.Equal(t, "2024-01", summaries[0].From)
+			assert.Equal(t, "2024-06", summaries[0].To)
+			assert.Equal(t, 10.0, summaries[0].ActualHours)
+			assert.Equal(t, summaries[0].Balance-144.0, summaries[0].ClosingBalance)
+		}
+	}
+}
+
+func TestTimeAccountCorrection_ApprovedMonthIsLocked(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	_, user := createTimeAccountFixture()
+	var correction models.TimeAccountCorrection
+	database.DB.Where("user_id = ?", user.ID).First(&correction)
+	database.DB.Create(&models.Timesheet{UserID: user.ID, Month: "2024-06", Status: models.TimesheetStatusApproved})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"date":"2024-06-10T00:00:00Z","hours":-1.5,"reason":"Zu früh gegangen"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.Itoa(int(user.ID)))
+
+	if assert.NoError(t, CreateTimeAccountCorrection(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id", "correction_id")
+	c.SetParamValues(strconv.Itoa(int(user.ID)), strconv.Itoa(int(correction.ID)))
+
+	if assert.NoError(t, DeleteTimeAccountCorrection(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	}
+
+	var count int64
+	database.DB.Model(&models.TimeAccountCorrection{}).Where("user_id = ?", user.ID).Count(&count)
+	assert.Equal(t, int64(1), count)
+}
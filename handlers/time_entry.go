@@ -0,0 +1,581 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+	"schichtplaner/utils"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// timeClockRequest sind die Angaben beim Stempeln; ohne user_id gilt der angemeldete Benutzer
+type timeClockRequest struct {
+	UserID uint   `json:"user_id"`
+	Note   string `json:"note"`
+}
+
+// timeEntryBreakInput ist eine Pause in einer manuellen Erfassung oder Korrektur
+type timeEntryBreakInput struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end"`
+}
+
+// timeEntryInput sind die Angaben einer manuellen Erfassung oder Korrektur; reason ist Pflicht
+type timeEntryInput struct {
+	UserID   uint                  `json:"user_id"`
+	ClockIn  time.Time             `json:"clock_in"`
+	ClockOut *time.Time            `json:"clock_out"`
+	Breaks   []timeEntryBreakInput `json:"breaks"`
+	Note     string                `json:"note"`
+	Reason   string                `json:"reason"`
+}
+
+// GetTimeEntries gibt die Zeitbuchungen im Zeitraum [from, to) mit Pagination zurück (RFC3339, Standard: aktueller Monat);
+// optional gefiltert nach user_id. Angemeldete Benutzer ohne Administratorrechte sehen nur ihre eigenen Buchungen.
+func GetTimeEntries(c echo.Context) error {
+	params := utils.GetPaginationParams(c)
+
+	loc := models.OrganisationLocation()
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	from, to, message := periodFromQuery(c, from, from.AddDate(0, 1, 0))
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+
+	query := tenantDB(c).Model(&models.TimeEntry{}).Where("clock_in >= ? AND clock_in < ?", from, to)
+	if value := c.QueryParam("user_id"); value != "" {
+		userID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültige Benutzer-ID",
+			})
+		}
+		if !canManageUser(c, uint(userID)) {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "Keine Berechtigung für die Zeiterfassung dieses Benutzers",
+			})
+		}
+		query = query.Where("user_id = ?", userID)
+	} else if user := currentUser(c); user != nil && !user.IsAdmin {
+		query = query.Where("user_id = ?", user.ID)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var entries []models.TimeEntry
+	if err := query.Preload("Breaks").Order("clock_in DESC").Offset(params.Offset).Limit(params.PageSize).Find(&entries).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Zeitbuchungen",
+		})
+	}
+
+	response := utils.CreatePaginatedResponse(entries, int(total), params)
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetTimeEntry gibt eine Zeitbuchung mit Pausen, Korrekturen und zugeordneter Schicht zurück
+func GetTimeEntry(c echo.Context) error {
+	entry, err := loadTimeEntryFromParam(c)
+	if err != nil || entry == nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, entry)
+}
+
+// GetCurrentTimeEntry gibt die laufende Zeitbuchung des angemeldeten Benutzers (bzw. von user_id) zurück
+func GetCurrentTimeEntry(c echo.Context) error {
+	var userID uint
+	if value := c.QueryParam("user_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültige Benutzer-ID",
+			})
+		}
+		userID = uint(id)
+	}
+	user, err := timeTrackingUser(c, userID)
+	if err != nil || user == nil {
+		return err
+	}
+
+	entry, err := loadRunningTimeEntry(tenantDB(c), user.ID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Keine laufende Zeiterfassung",
+		})
+	}
+
+	return c.JSON(http.StatusOK, entry)
+}
+
+// ClockIn startet die Zeiterfassung (Kommen) und ordnet sie der passenden geplanten Schicht zu.
+// Läuft bereits eine Zeiterfassung, wird mit 409 geantwortet.
+func ClockIn(c echo.Context) error {
+	var request timeClockRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Angaben zur Zeiterfassung",
+		})
+	}
+	user, err := timeTrackingUser(c, request.UserID)
+	if err != nil || user == nil {
+		return err
+	}
+
+	now := time.Now()
+	entry := models.TimeEntry{UserID: user.ID, ClockIn: now, Source: models.TimeEntrySourceClock, Note: request.Note, Breaks: []models.TimeEntryBreak{}}
+	message := ""
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		var running int64
+		tx.Model(&models.TimeEntry{}).Where("user_id = ? AND clock_out IS NULL", user.ID).Count(&running)
+		if running > 0 {
+			message = "Zeiterfassung läuft bereits"
+			return gorm.ErrInvalidData
+		}
+		if message = timesheetLockMessage(tx, user.ID, now); message != "" {
+			return gorm.ErrInvalidData
+		}
+		if timeEntryOverlaps(tx, entry) {
+			message = "Zeiterfassung überschneidet sich mit einer bestehenden Zeitbuchung"
+			return gorm.ErrInvalidData
+		}
+
+		entry.ShiftID = matchPlannedShift(tx, user.ID, now)
+		return tx.Create(&entry).Error
+	})
+	if message != "" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": message,
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Starten der Zeiterfassung",
+		})
+	}
+
+	return c.JSON(http.StatusCreated, entry)
+}
+
+// ClockOut beendet die laufende Zeiterfassung (Gehen); eine laufende Pause wird dabei beendet
+func ClockOut(c echo.Context) error {
+	return updateRunningTimeEntry(c, func(tx *gorm.DB, entry *models.TimeEntry, now time.Time) string {
+		if b := entry.OpenBreak(); b != nil {
+			b.End = &now
+			if err := tx.Save(b).Error; err != nil {
+				return "Fehler beim Beenden der Pause"
+			}
+		}
+
+		entry.ClockOut = &now
+		if entry.ShiftID == nil {
+			entry.ShiftID = matchPlannedShift(tx, entry.UserID, entry.ClockIn)
+		}
+		if err := tx.Omit("User", "Shift", "Breaks", "Corrections").Save(entry).Error; err != nil {
+			return "Fehler beim Beenden der Zeiterfassung"
+		}
+		return ""
+	})
+}
+
+// StartBreak beginnt eine Pause in der laufenden Zeiterfassung
+func StartBreak(c echo.Context) error {
+	return updateRunningTimeEntry(c, func(tx *gorm.DB, entry *models.TimeEntry, now time.Time) string {
+		if entry.OpenBreak() != nil {
+			return "Pause läuft bereits"
+		}
+
+		b := models.TimeEntryBreak{TimeEntryID: entry.ID, Start: now}
+		if err := tx.Create(&b).Error; err != nil {
+			return "Fehler beim Starten der Pause"
+		}
+		entry.Breaks = append(entry.Breaks, b)
+		return ""
+	})
+}
+
+// EndBreak beendet die laufende Pause
+func EndBreak(c echo.Context) error {
+	return updateRunningTimeEntry(c, func(tx *gorm.DB, entry *models.TimeEntry, now time.Time) string {
+		b := entry.OpenBreak()
+		if b == nil {
+			return "Keine laufende Pause"
+		}
+
+		b.End = &now
+		if err := tx.Save(b).Error; err != nil {
+			return "Fehler beim Beenden der Pause"
+		}
+		return ""
+	})
+}
+
+// CreateTimeEntry erfasst eine abgeschlossene Zeitbuchung nachträglich; eine Begründung (reason) ist Pflicht
+func CreateTimeEntry(c echo.Context) error {
+	var request timeEntryInput
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Zeitbuchung",
+		})
+	}
+	user, err := timeTrackingUser(c, request.UserID)
+	if err != nil || user == nil {
+		return err
+	}
+
+	entry := models.TimeEntry{UserID: user.ID, Source: models.TimeEntrySourceManual}
+	applyTimeEntryInput(&entry, request)
+	if message := validateTimeEntryInput(entry, request); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	if entry.ClockOut == nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Gehen ist bei nachträglicher Erfassung ein Pflichtfeld",
+		})
+	}
+	if message := timesheetLockMessage(tenantDB(c), entry.UserID, entry.ClockIn); message != "" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": message,
+		})
+	}
+
+	message := ""
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		if timeEntryOverlaps(tx, entry) {
+			message = "Zeitbuchung überschneidet sich mit einer bestehenden Zeitbuchung"
+			return gorm.ErrInvalidData
+		}
+
+		entry.ShiftID = matchPlannedShift(tx, entry.UserID, entry.ClockIn)
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		correction := newTimeEntryCorrection(c, entry, request.Reason)
+		return tx.Create(&correction).Error
+	})
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Erstellen der Zeitbuchung",
+		})
+	}
+
+	created, _ := loadTimeEntry(tenantDB(c), entry.ID)
+	return c.JSON(http.StatusCreated, created)
+}
+
+// CorrectTimeEntry korrigiert Kommen, Gehen und Pausen einer Zeitbuchung. Eine Begründung (reason) ist Pflicht;
+// die vorherigen Werte werden protokolliert.
+func CorrectTimeEntry(c echo.Context) error {
+	entry, err := loadTimeEntryFromParam(c)
+	if err != nil || entry == nil {
+		return err
+	}
+
+	// Zeiger kopieren, damit Bind die geladenen Werte für das Protokoll nicht überschreibt
+	request := timeEntryInput{ClockIn: entry.ClockIn, ClockOut: copyTime(entry.ClockOut), Note: entry.Note}
+	for _, b := range entry.Breaks {
+		request.Breaks = append(request.Breaks, timeEntryBreakInput{Start: b.Start, End: copyTime(b.End)})
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Zeitbuchung",
+		})
+	}
+
+	previous := *entry
+	updated := *entry
+	applyTimeEntryInput(&updated, request)
+	if message := validateTimeEntryInput(updated, request); message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	if message := timesheetLockMessage(tenantDB(c), entry.UserID, previous.ClockIn, updated.ClockIn); message != "" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": message,
+		})
+	}
+
+	message := ""
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		if timeEntryOverlaps(tx, updated) {
+			message = "Zeitbuchung überschneidet sich mit einer bestehenden Zeitbuchung"
+			return gorm.ErrInvalidData
+		}
+
+		updated.ShiftID = matchPlannedShift(tx, updated.UserID, updated.ClockIn)
+		if err := tx.Omit("User", "Shift", "Breaks", "Corrections").Save(&updated).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("time_entry_id = ?", updated.ID).Delete(&models.TimeEntryBreak{}).Error; err != nil {
+			return err
+		}
+		for i := range updated.Breaks {
+			updated.Breaks[i].TimeEntryID = updated.ID
+			if err := tx.Create(&updated.Breaks[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		correction := newTimeEntryCorrection(c, updated, request.Reason)
+		previousBreakMinutes := int(previous.BreakDuration(time.Now()) / time.Minute)
+		correction.PreviousClockIn = &previous.ClockIn
+		correction.PreviousClockOut = previous.ClockOut
+		correction.PreviousBreakMinutes = &previousBreakMinutes
+		return tx.Create(&correction).Error
+	})
+	if message != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Korrigieren der Zeitbuchung",
+		})
+	}
+
+	corrected, _ := loadTimeEntry(tenantDB(c), entry.ID)
+	return c.JSON(http.StatusOK, corrected)
+}
+
+// DeleteTimeEntry löscht eine Zeitbuchung mit ihren Pausen
+func DeleteTimeEntry(c echo.Context) error {
+	entry, err := loadTimeEntryFromParam(c)
+	if err != nil || entry == nil {
+		return err
+	}
+	if message := timesheetLockMessage(tenantDB(c), entry.UserID, entry.ClockIn); message != "" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": message,
+		})
+	}
+
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("time_entry_id = ?", entry.ID).Delete(&models.TimeEntryBreak{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(entry).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Löschen der Zeitbuchung",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Zeitbuchung erfolgreich gelöscht",
+	})
+}
+
+// updateRunningTimeEntry lädt die laufende Zeitbuchung des Benutzers und ändert sie mit update in einer Transaktion.
+// update liefert eine Fehlermeldung oder einen leeren String.
+func updateRunningTimeEntry(c echo.Context, update func(tx *gorm.DB, entry *models.TimeEntry, now time.Time) string) error {
+	var request timeClockRequest
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Angaben zur Zeiterfassung",
+		})
+	}
+	user, err := timeTrackingUser(c, request.UserID)
+	if err != nil || user == nil {
+		return err
+	}
+
+	var entry *models.TimeEntry
+	message := ""
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		running, err := loadRunningTimeEntry(tx, user.ID)
+		if err != nil {
+			message = "Keine laufende Zeiterfassung"
+			return gorm.ErrInvalidData
+		}
+		entry = running
+		if message = timesheetLockMessage(tx, user.ID, entry.ClockIn); message != "" {
+			return gorm.ErrInvalidData
+		}
+		if message = update(tx, entry, time.Now()); message != "" {
+			return gorm.ErrInvalidData
+		}
+		return nil
+	})
+	if message != "" {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": message,
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Aktualisieren der Zeiterfassung",
+		})
+	}
+
+	return c.JSON(http.StatusOK, entry)
+}
+
+// timeTrackingUser ermittelt den Benutzer einer Zeiterfassung: ohne userID den angemeldeten Benutzer, sonst den
+// Benutzer userID, sofern der angemeldete Benutzer ihn verwalten darf. Bei Fehlern wird direkt geantwortet und nil geliefert.
+func timeTrackingUser(c echo.Context, userID uint) (*models.User, error) {
+	if userID == 0 {
+		if user := currentUser(c); user != nil {
+			return user, nil
+		}
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Benutzer-ID (user_id) ist ohne Anmeldung ein Pflichtfeld",
+		})
+	}
+	if !canManageUser(c, userID) {
+		return nil, c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Keine Berechtigung für die Zeiterfassung dieses Benutzers",
+		})
+	}
+
+	var user models.User
+	if err := tenantDB(c).First(&user, userID).Error; err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Benutzer nicht gefunden",
+		})
+	}
+	return &user, nil
+}
+
+// matchPlannedShift liefert die geplante Schicht des Benutzers, der ein Kommen zum Zeitpunkt at zugeordnet wird
+func matchPlannedShift(db *gorm.DB, userID uint, at time.Time) *uint {
+	var shifts []models.Shift
+	db.Where("user_id = ? AND start_time <= ? AND end_time > ?", userID, at.Add(services.ShiftMatchLeadTime), at).Find(&shifts)
+	if shift := services.MatchShift(at, shifts); shift != nil {
+		return &shift.ID
+	}
+	return nil
+}
+
+// timeEntryOverlaps prüft, ob sich die Zeitbuchung mit einer anderen Buchung desselben Benutzers überschneidet;
+// laufende Buchungen reichen bis jetzt
+func timeEntryOverlaps(db *gorm.DB, entry models.TimeEntry) bool {
+	end := time.Now()
+	if entry.ClockOut != nil {
+		end = *entry.ClockOut
+	}
+	var count int64
+	db.Model(&models.TimeEntry{}).
+		Where("user_id = ? AND id <> ? AND clock_in < ? AND (clock_out IS NULL OR clock_out > ?)", entry.UserID, entry.ID, end, entry.ClockIn).
+		Count(&count)
+	return count > 0
+}
+
+// applyTimeEntryInput übernimmt Kommen, Gehen, Pausen und Notiz aus einer Erfassung oder Korrektur
+func applyTimeEntryInput(entry *models.TimeEntry, request timeEntryInput) {
+	entry.ClockIn = request.ClockIn
+	entry.ClockOut = request.ClockOut
+	entry.Note = request.Note
+	entry.Breaks = make([]models.TimeEntryBreak, 0, len(request.Breaks))
+	for _, b := range request.Breaks {
+		entry.Breaks = append(entry.Breaks, models.TimeEntryBreak{TimeEntryID: entry.ID, Start: b.Start, End: b.End})
+	}
+}
+
+// validateTimeEntryInput prüft eine Erfassung oder Korrektur und liefert die erste Fehlermeldung oder einen leeren String
+func validateTimeEntryInput(entry models.TimeEntry, request timeEntryInput) string {
+	validator := utils.NewValidator()
+	validator.RequiredString("Reason", request.Reason, "Begründung ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return result.Errors[0]
+	}
+
+	if err := services.ValidateTimeEntry(entry); err != nil {
+		return err.Error()
+	}
+	if entry.ClockIn.After(time.Now()) || entry.ClockOut != nil && entry.ClockOut.After(time.Now()) {
+		return "Zeitbuchungen dürfen nicht in der Zukunft liegen"
+	}
+	return ""
+}
+
+// copyTime liefert eine Kopie eines optionalen Zeitpunkts
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	value := *t
+	return &value
+}
+
+// newTimeEntryCorrection erstellt den Protokolleintrag einer Erfassung oder Korrektur mit den neuen Werten
+func newTimeEntryCorrection(c echo.Context, entry models.TimeEntry, reason string) models.TimeEntryCorrection {
+	correction := models.TimeEntryCorrection{
+		TimeEntryID:  entry.ID,
+		Reason:       reason,
+		ClockIn:      entry.ClockIn,
+		ClockOut:     entry.ClockOut,
+		BreakMinutes: int(entry.BreakDuration(time.Now()) / time.Minute),
+	}
+	if user := currentUser(c); user != nil {
+		correction.CorrectedByID = &user.ID
+	}
+	return correction
+}
+
+// loadRunningTimeEntry lädt die laufende Zeitbuchung eines Benutzers mit ihren Pausen
+func loadRunningTimeEntry(db *gorm.DB, userID uint) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	if err := db.Preload("Breaks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("start ASC")
+	}).Where("user_id = ? AND clock_out IS NULL", userID).First(&entry).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// loadTimeEntry lädt eine Zeitbuchung mit Pausen, Korrekturen und zugeordneter Schicht
+func loadTimeEntry(db *gorm.DB, id uint) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	if err := db.Preload("Breaks", func(db *gorm.DB) *gorm.DB {
+		return db.Order("start ASC")
+	}).Preload("Corrections", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC")
+	}).Preload("Shift").First(&entry, id).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// loadTimeEntryFromParam lädt die Zeitbuchung aus dem Pfadparameter id; bei Fehlern wird direkt geantwortet und nil geliefert
+func loadTimeEntryFromParam(c echo.Context) (*models.TimeEntry, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Zeitbuchungs-ID",
+		})
+	}
+
+	entry, err := loadTimeEntry(tenantDB(c), uint(id))
+	if err != nil {
+		return nil, c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Zeitbuchung nicht gefunden",
+		})
+	}
+	if !canManageUser(c, entry.UserID) {
+		return nil, c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Keine Berechtigung für die Zeiterfassung dieses Benutzers",
+		})
+	}
+	return entry, nil
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClockInAndOut(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "stempel", Email: "stempel@example.com", Password: "x", Name: "Stempel User", IsActive: true}
+	database.DB.Create(&user)
+
+	now := time.Now()
+	schedule := models.Schedule{Name: "Aktuell", StartDate: now.AddDate(0, 0, -1), EndDate: now.AddDate(0, 0, 1)}
+	database.DB.Create(&schedule)
+	shift := models.Shift{UserID: user.ID, ScheduleID: schedule.ID, StartTime: now.Add(30 * time.Minute), EndTime: now.Add(8 * time.Hour)}
+	database.DB.Create(&shift)
+
+	// Ohne Anmeldung und ohne user_id ist der Benutzer unbekannt
+	rec := callHandler(t, ClockIn, handlerRequest{body: `{}`})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callHandler(t, ClockIn, handlerRequest{actor: &user, body: `{"note":"Frühdienst"}`})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var entry models.TimeEntry
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &entry))
+	if assert.NotNil(t, entry.ShiftID) {
+		assert.Equal(t, shift.ID, *entry.ShiftID)
+	}
+
+	// Doppeltes Kommen wird abgelehnt
+	rec = callHandler(t, ClockIn, handlerRequest{body: `{"user_id":` + strconv.Itoa(int(user.ID)) + `}`})
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// Pause
+	assert.Equal(t, http.StatusOK, callHandler(t, StartBreak, handlerRequest{actor: &user}).Code)
+	assert.Equal(t, http.StatusConflict, callHandler(t, StartBreak, handlerRequest{actor: &user}).Code)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(currentUserContextKey, &user)
+	assert.NoError(t, GetCurrentTimeEntry(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"on_break":true`)
+
+	// Gehen beendet auch die laufende Pause
+	rec = callHandler(t, ClockOut, handlerRequest{actor: &user})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"is_running":false`)
+
+	var stored models.TimeEntry
+	assert.NoError(t, database.DB.Preload("Breaks").First(&stored, entry.ID).Error)
+	assert.NotNil(t, stored.ClockOut)
+	if assert.Len(t, stored.Breaks, 1) {
+		assert.NotNil(t, stored.Breaks[0].End)
+	}
+
+	assert.Equal(t, http.StatusConflict, callHandler(t, ClockOut, handlerRequest{actor: &user}).Code)
+	assert.Equal(t, http.StatusConflict, callHandler(t, EndBreak, handlerRequest{actor: &user}).Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set(currentUserContextKey, &user)
+	assert.NoError(t, GetCurrentTimeEntry(c))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestTimeEntryCorrections(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	user := models.User{Username: "korrektur", Email: "korrektur@example.com", Password: "x", AccountNumber: "K1", Name: "Korrektur User", IsActive: true}
+	other := models.User{Username: "andere", Email: "andere@example.com", Password: "x", AccountNumber: "K2", Name: "Andere", IsActive: true}
+	database.DB.Create(&user)
+	database.DB.Create(&other)
+	userID := strconv.Itoa(int(user.ID))
+
+	// Nachträgliche Erfassung nur mit Begründung
+	body := `{"user_id":` + userID + `,"clock_in":"2024-03-04T06:00:00Z","clock_out":"2024-03-04T14:30:00Z",` +
+		`"breaks":[{"start":"2024-03-04T10:00:00Z","end":"2024-03-04T10:30:00Z"}]`
+	assert.Equal(t, http.StatusBadRequest, callHandler(t, CreateTimeEntry, handlerRequest{body: body + `}`}).Code)
+
+	rec := callHandler(t, CreateTimeEntry, handlerRequest{body: body + `,"reason":"Stempeln vergessen"}`})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var entry models.TimeEntry
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &entry))
+	assert.Equal(t, models.TimeEntrySourceManual, entry.Source)
+	assert.Contains(t, rec.Body.String(), `"worked_minutes":480`)
+
+	// Überschneidung mit der bestehenden Buchung
+	overlap := `{"user_id":` + userID + `,"clock_in":"2024-03-04T14:00:00Z","clock_out":"2024-03-04T16:00:00Z","reason":"Nachtrag"}`
+	assert.Equal(t, http.StatusBadRequest, callHandler(t, CreateTimeEntry, handlerRequest{body: overlap}).Code)
+
+	// Pause außerhalb der Buchung
+	invalid := `{"clock_out":"2024-03-04T15:00:00Z","breaks":[{"start":"2024-03-04T05:00:00Z","end":"2024-03-04T05:30:00Z"}],"reason":"Korrektur"}`
+	assert.Equal(t, http.StatusBadRequest, callHandler(t, CorrectTimeEntry, handlerRequest{params: pathParams{"id": entry.ID}, body: invalid}).Code)
+
+	// Andere Benutzer dürfen nicht korrigieren
+	assert.Equal(t, http.StatusForbidden, callHandler(t, CorrectTimeEntry, handlerRequest{params: pathParams{"id": entry.ID}, actor: &other, body: `{"reason":"Fremd"}`}).Code)
+
+	rec = callHandler(t, CorrectTimeEntry, handlerRequest{params: pathParams{"id": entry.ID}, body: `{"clock_out":"2024-03-04T15:00:00Z","reason":"Länger geblieben"}`})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var corrected models.TimeEntry
+	assert.NoError(t, database.DB.Preload("Breaks").Preload("Corrections").First(&corrected, entry.ID).Error)
+	assert.True(t, time.Date(2024, 3, 4, 15, 0, 0, 0, time.UTC).Equal(*corrected.ClockOut))
+	assert.Len(t, corrected.Breaks, 1)
+	if assert.Len(t, corrected.Corrections, 2) {
+		last := corrected.Corrections[1]
+		assert.Equal(t, "Länger geblieben", last.Reason)
+		assert.True(t, time.Date(2024, 3, 4, 14, 30, 0, 0, time.UTC).Equal(*last.PreviousClockOut))
+		assert.Equal(t, 30, *last.PreviousBreakMinutes)
+		assert.Equal(t, 30, last.BreakMinutes)
+	}
+}
@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"schichtplaner/models"
+	"schichtplaner/services"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+// GetTimesheets gibt die gespeicherten Stundenzettel zurück, optional gefiltert nach month (YYYY-MM) und status.
+// Angemeldete Benutzer ohne Freigaberechte sehen nur ihre eigenen Stundenzettel.
+func GetTimesheets(c echo.Context) error {
+	query := tenantDB(c).Preload("User")
+	if month := c.QueryParam("month"); month != "" {
+		if _, err := models.ParseMonth(month, time.UTC); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültiger Monat (month), erwartet YYYY-MM",
+			})
+		}
+		query = query.Where("month = ?", month)
+	}
+	if status := c.QueryParam("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if user := currentUser(c); user != nil && !user.CanApprove() {
+		query = query.Where("user_id = ?", user.ID)
+	}
+
+	var timesheets []models.Timesheet
+	if err := query.Order("month DESC, user_id ASC").Find(&timesheets).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Stundenzettel",
+		})
+	}
+
+	return c.JSON(http.StatusOK, timesheets)
+}
+
+// GetUserTimesheet gibt den Stundenzettel eines Benutzers für einen Monat (YYYY-MM) mit Tageszeilen und Verlauf zurück.
+// Ohne gespeicherten Stundenzettel wird ein offener Stundenzettel berechnet.
+func GetUserTimesheet(c echo.Context) error {
+	user, timesheet, err := loadTimesheetFromParams(c)
+	if err != nil || timesheet == nil {
+		return err
+	}
+	if !canManageUser(c, user.ID) && !canApproveTimesheet(c, user.ID) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Keine Berechtigung für den Stundenzettel dieses Benutzers",
+		})
+	}
+
+	days, err := timesheetDays(tenantDB(c), *user, timesheet.Month)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Berechnen des Stundenzettels",
+		})
+	}
+	// Freigegebene Stundenzettel zeigen die festgeschriebenen Summen
+	if !timesheet.IsLocked() {
+		services.SummarizeTimesheet(timesheet, days)
+	}
+	timesheet.Days = days
+
+	return c.JSON(http.StatusOK, timesheet)
+}
+
+// SubmitTimesheet reicht den Stundenzettel eines Monats zur Freigabe ein
+func SubmitTimesheet(c echo.Context) error {
+	return transitionTimesheet(c, models.TimesheetActionSubmit)
+}
+
+// ApproveTimesheet gibt einen eingereichten Stundenzettel frei; danach sind Zeitbuchungen und Schichten des Monats gesperrt
+func ApproveTimesheet(c echo.Context) error {
+	return transitionTimesheet(c, models.TimesheetActionApprove)
+}
+
+// ReturnTimesheet gibt einen eingereichten Stundenzettel mit Anmerkungen (comment) an den Mitarbeiter zurück
+func ReturnTimesheet(c echo.Context) error {
+	return transitionTimesheet(c, models.TimesheetActionReturn)
+}
+
+// ReopenTimesheet hebt die Freigabe eines Stundenzettels mit Begründung (comment) auf, damit der Monat wieder bearbeitet werden kann
+func ReopenTimesheet(c echo.Context) error {
+	return transitionTimesheet(c, models.TimesheetActionReopen)
+}
+
+// transitionTimesheet führt eine Aktion im Freigabeablauf aus, schreibt die Summen fest und protokolliert den Schritt
+func transitionTimesheet(c echo.Context, action string) error {
+	user, timesheet, err := loadTimesheetFromParams(c)
+	if err != nil || timesheet == nil {
+		return err
+	}
+
+	var request struct {
+		Comment string `json:"comment"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Angaben zum Stundenzettel",
+		})
+	}
+	request.Comment = strings.TrimSpace(request.Comment)
+
+	if action == models.TimesheetActionSubmit {
+		if !canManageUser(c, user.ID) {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "Keine Berechtigung für den Stundenzettel dieses Benutzers",
+			})
+		}
+	} else if !canApproveTimesheet(c, user.ID) {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Nur Planer und Teamleitungen dürfen Stundenzettel anderer Benutzer freigeben",
+		})
+	}
+	if (action == models.TimesheetActionReturn || action == models.TimesheetActionReopen) && request.Comment == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Anmerkung (comment) ist ein Pflichtfeld",
+		})
+	}
+
+	status, err := services.TimesheetTransition(timesheet.Status, action)
+	if err != nil {
+		return c.JSON(http.StatusConflict, map[string]string{
+			"error": err.Error(),
+		})
+	}
+
+	monthStart, monthEnd := timesheetPeriod(*user, timesheet.Month)
+	if action == models.TimesheetActionSubmit {
+		var running int64
+		tenantDB(c).Model(&models.TimeEntry{}).
+			Where("user_id = ? AND clock_out IS NULL AND clock_in >= ? AND clock_in < ?", user.ID, monthStart, monthEnd).
+			Count(&running)
+		if running > 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Stundenzettel kann nicht eingereicht werden, solange eine Zeiterfassung des Monats läuft",
+			})
+		}
+	}
+
+	days, err := timesheetDays(tenantDB(c), *user, timesheet.Month)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Berechnen des Stundenzettels",
+		})
+	}
+
+	now := time.Now()
+	event := models.TimesheetEvent{Action: action, FromStatus: timesheet.Status, ToStatus: status, Comment: request.Comment}
+	if actor := currentUser(c); actor != nil {
+		event.ActorID = &actor.ID
+	}
+
+	services.SummarizeTimesheet(timesheet, days)
+	timesheet.Status = status
+	timesheet.Comment = request.Comment
+	switch action {
+	case models.TimesheetActionSubmit:
+		timesheet.SubmittedAt = &now
+	case models.TimesheetActionApprove:
+		timesheet.ApprovedAt = &now
+		timesheet.ApprovedByID = event.ActorID
+	case models.TimesheetActionReopen:
+		timesheet.ApprovedAt = nil
+		timesheet.ApprovedByID = nil
+	}
+
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("User", "Events").Save(timesheet).Error; err != nil {
+			return err
+		}
+		event.TimesheetID = timesheet.ID
+		return tx.Create(&event).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Speichern des Stundenzettels",
+		})
+	}
+
+	tenantDB(c).Where("timesheet_id = ?", timesheet.ID).Order("created_at ASC, id ASC").Find(&timesheet.Events)
+	timesheet.Days = days
+	return c.JSON(http.StatusOK, timesheet)
+}
+
+// canApproveTimesheet prüft, ob der angemeldete Benutzer Stundenzettel des Benutzers userID freigeben darf.
+// Eigene Stundenzettel dürfen nicht freigegeben werden; ohne Anmeldung ist alles erlaubt.
+func canApproveTimesheet(c echo.Context, userID uint) bool {
+	user := currentUser(c)
+	return user == nil || user.CanApprove() && user.ID != userID
+}
+
+// timesheetPeriod liefert Beginn und Ende eines Monats (YYYY-MM) in der Zeitzone des Benutzers
+func timesheetPeriod(user models.User, month string) (time.Time, time.Time) {
+	start, _ := models.ParseMonth(month, user.Location())
+	return start, start.AddDate(0, 1, 0)
+}
+
+// timesheetDays berechnet die Tageszeilen eines Monats aus geplanten Schichten und Zeitbuchungen
+func timesheetDays(db *gorm.DB, user models.User, month string) ([]models.TimesheetDay, error) {
+	start, end := timesheetPeriod(user, month)
+
+	var shifts []models.Shift
+	if err := db.Where("user_id = ? AND start_time >= ? AND start_time < ?", user.ID, start, end).Find(&shifts).Error; err != nil {
+		return nil, err
+	}
+	var entries []models.TimeEntry
+	if err := db.Preload("Breaks").Where("user_id = ? AND clock_in >= ? AND clock_in < ?", user.ID, start, end).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	first, _ := models.ParseMonth(month, time.UTC)
+	return services.TimesheetDays(first, first.AddDate(0, 1, 0), user.Location(), shifts, entries, time.Now()), nil
+}
+
+// errTimesheetLocked wird gemeldet, wenn eine Änderung einen freigegebenen Monat betreffen würde
+var errTimesheetLocked = errors.New("Der Stundenzettel des Monats ist freigegeben; Änderungen erfordern eine Wiedereröffnung")
+
+// timesheetLockMessage prüft, ob einer der Zeitpunkte in einem freigegebenen Monat des Benutzers liegt.
+// Liefert eine Fehlermeldung oder einen leeren String.
+func timesheetLockMessage(db *gorm.DB, userID uint, times ...time.Time) string {
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return ""
+	}
+
+	months := make([]string, 0, len(times))
+	for _, t := range times {
+		months = append(months, t.In(user.Location()).Format("2006-01"))
+	}
+
+	var count int64
+	db.Model(&models.Timesheet{}).Where("user_id = ? AND month IN ? AND status = ?", userID, months, models.TimesheetStatusApproved).Count(&count)
+	if count > 0 {
+		return errTimesheetLocked.Error()
+	}
+	return ""
+}
+
+// loadTimesheetFromParams lädt Benutzer (id) und Stundenzettel (month) aus den Pfadparametern. Ohne gespeicherten
+// Stundenzettel wird ein offener, noch nicht gespeicherter geliefert. Bei Fehlern wird direkt geantwortet und nil geliefert.
+func loadTimesheetFromParams(c echo.Context) (*models.User, *models.Timesheet, error) {
+	user, err := loadUserFromParam(c)
+	if err != nil || user == nil {
+		return nil, nil, err
+	}
+
+	month := c.Param("month")
+	if _, err := models.ParseMonth(month, time.UTC); err != nil {
+		return nil, nil, c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültiger Monat, erwartet YYYY-MM",
+		})
+	}
+
+	timesheet := models.Timesheet{UserID: user.ID, Month: month, Status: models.TimesheetStatusOpen}
+	if err := tenantDB(c).Preload("Events", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC, id ASC")
+	}).Where("user_id = ? AND month = ?", user.ID, month).First(&timesheet).Error; err != nil && err != gorm.ErrRecordNotFound {
+		return nil, nil, c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden des Stundenzettels",
+		})
+	}
+	return user, &timesheet, nil
+}
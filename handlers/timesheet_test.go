@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimesheetApprovalWorkflow(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	employee := models.User{Username: "zettel", Email: "zettel@example.com", Password: "x", AccountNumber: "Z1", Name: "Zettel User", Role: models.RoleEmployee, IsActive: true}
+	planner := models.User{Username: "planer", Email: "planer@example.com", Password: "x", AccountNumber: "Z2", Name: "Planer", Role: models.RoleManager, IsActive: true}
+	database.DB.Create(&employee)
+	database.DB.Create(&planner)
+
+	schedule := models.Schedule{Name: "März", StartDate: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)}
+	database.DB.Create(&schedule)
+	shift := models.Shift{UserID: employee.ID, ScheduleID: schedule.ID, StartTime: time.Date(2024, 3, 4, 6, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC)}
+	database.DB.Create(&shift)
+	clockOut := time.Date(2024, 3, 4, 14, 30, 0, 0, time.UTC)
+	entry := models.TimeEntry{UserID: employee.ID, ClockIn: shift.StartTime, ClockOut: &clockOut, Source: models.TimeEntrySourceManual}
+	database.DB.Create(&entry)
+
+	// Ohne gespeicherten Stundenzettel wird ein offener berechnet
+	rec := callHandler(t, GetUserTimesheet, handlerRequest{params: pathParams{"id": employee.ID, "month": "2024-03"}, actor: &employee})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var timesheet models.Timesheet
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &timesheet))
+	assert.Equal(t, models.TimesheetStatusOpen, timesheet.Status)
+	assert.Equal(t, 8.0, timesheet.PlannedHours)
+	assert.Equal(t, 8.5, timesheet.ActualHours)
+	assert.Len(t, timesheet.Days, 31)
+
+	assert.Equal(t, http.StatusBadRequest, callHandler(t, GetUserTimesheet, handlerRequest{params: pathParams{"id": employee.ID, "month": "2024-13"}}).Code)
+
+	// Freigabe erst nach dem Einreichen, und nicht durch den Mitarbeiter selbst
+	assert.Equal(t, http.StatusConflict, callHandler(t, ApproveTimesheet, handlerRequest{params: pathParams{"id": employee.ID, "month": "2024-03"}, actor: &planner}).Code)
+	assert.Equal(t, http.StatusOK, callHandler(t, SubmitTimesheet, handlerRequest{params: pathParams{"id": employee.ID, "month": "2024-03"}, actor: &employee}).Code)
+	assert.Equal(t, http.StatusForbidden, callHandler(t, ApproveTimesheet, handlerRequest{params: pathParams{"id": employee.ID, "month": "2024-03"}, actor: &employee}).Code)
+
+	// Rückgabe nur mit Anmerkung
+	assert.Equal(t, http.StatusBadRequest, callHandler(t, ReturnTimesheet, handlerRequest{params: pathParams{"id": employee.ID, "month": "2024-03"}, actor: &planner, body: `{}`}).Code)
+	assert.Equal(t, http.StatusOK, callHandler(t, ReturnTimesheet, handlerRequest{params: pathParams{"id": employee.ID, "month": "2024-03"}, actor: &planner, body: `{"comment":"Pause fehlt"}`}).Code)
+	assert.Equal(t, http.StatusOK, callHandler(t, SubmitTimesheet, handlerRequest{params: pathParams{"id": employee.ID, "month": "2024-03"}, actor: &employee}).Code)
+
+	rec = callHandler(t, ApproveTimesheet, handlerRequest{params: pathParams{"id": employee.ID, "month": "2024-03"}, actor: &planner})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &timesheet))
+	assert.Equal(t, models.TimesheetStatusApproved, timesheet.Status)
+	if assert.NotNil(t, timesheet.ApprovedByID) {
+		assert.Equal(t, planner.ID, *timesheet.ApprovedByID)
+	}
+	assert.Len(t, timesheet.Events, 4)
+
+	// Freigegebene Monate sind gesperrt
+	correction := `{"clock_out":"2024-03-04T15:00:00Z","reason":"Länger geblieben"}`
+	assert.Equal(t, http.StatusConflict, callHandler(t, CorrectTimeEntry, handlerRequest{params: pathParams{"id": entry.ID}, body: correction}).Code)
+	assert.Equal(t, http.StatusConflict, callHandler(t, DeleteTimeEntry, handlerRequest{params: pathParams{"id": entry.ID}}).Code)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	rec = httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(strconv.FormatUint(uint64(shift.ID), 10))
+	assert.NoError(t, DeleteShift(c))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// Nach der Wiedereröffnung mit Begründung ist der Monat wieder bearbeitbar
+	assert.Equal(t, http.StatusBadRequest, callHandler(t, ReopenTimesheet, handlerRequest{params: pathParams{"id": employee.ID, "month": "2024-03"}, actor: &planner}).Code)
+	rec = callHandler(t, ReopenTimesheet, handlerRequest{params: pathParams{"id": employee.ID, "month": "2024-03"}, actor: &planner, body: `{"comment":"Nachtrag Überstunden"}`})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &timesheet))
+	assert.Equal(t, models.TimesheetStatusOpen, timesheet.Status)
+	assert.Nil(t, timesheet.ApprovedAt)
+	if assert.Len(t, timesheet.Events, 5) {
+		assert.Equal(t, "Nachtrag Überstunden", timesheet.Events[4].Comment)
+	}
+	assert.Equal(t, http.StatusOK, callHandler(t, CorrectTimeEntry, handlerRequest{params: pathParams{"id": entry.ID}, body: correction}).Code)
+
+	// Je Benutzer und Monat gibt es nur einen Stundenzettel
+	assert.Error(t, database.DB.Create(&models.Timesheet{UserID: employee.ID, Month: "2024-03"}).Error)
+}
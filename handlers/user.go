@@ -5,7 +5,6 @@ import (
 	"strconv"
 	"time"
 
-	"schichtplaner/database"
 	"schichtplaner/models"
 	"schichtplaner/utils"
 
@@ -21,10 +20,10 @@ func GetUsers(c echo.Context) error {
 	var total int64
 
 	// Zähle die Gesamtanzahl
-	database.DB.Model(&models.User{}).Count(&total)
+	tenantDB(c).Model(&models.User{}).Count(&total)
 
 	// Lade die paginierten Daten
-	if err := database.DB.Offset(params.Offset).Limit(params.PageSize).Find(&users).Error; err != nil {
+	if err := tenantDB(c).Offset(params.Offset).Limit(params.PageSize).Find(&users).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Laden der Benutzer",
 		})
@@ -44,7 +43,7 @@ func GetUser(c echo.Context) error {
 	}
 
 	var user models.User
-	if err := database.DB.Preload("Shifts").Preload("Team").First(&user, id).Error; err != nil {
+	if err := tenantDB(c).Preload("Shifts").Preload("Team").First(&user, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Benutzer nicht gefunden",
 		})
@@ -56,15 +55,17 @@ func GetUser(c echo.Context) error {
 // CreateUser erstellt einen neuen Benutzer
 func CreateUser(c echo.Context) error {
 	var userRequest struct {
-		Username      string `json:"username"`
-		Email         string `json:"email"`
-		Password      string `json:"password"`
-		AccountNumber string `json:"account_number"`
-		Name          string `json:"name"`
-		Color         string `json:"color"`
-		Role          string `json:"role"`
-		IsActive      bool   `json:"is_active"`
-		IsAdmin       bool   `json:"is_admin"`
+		Username      string  `json:"username"`
+		Email         string  `json:"email"`
+		Password      string  `json:"password"`
+		AccountNumber string  `json:"account_number"`
+		Name          string  `json:"name"`
+		Color         string  `json:"color"`
+		Role          string  `json:"role"`
+		IsActive      bool    `json:"is_active"`
+		IsAdmin       bool    `json:"is_admin"`
+		TimeZone      string  `json:"time_zone"`
+		WeeklyHours   float64 `json:"weekly_hours"`
 	}
 
 	if err := c.Bind(&userRequest); err != nil {
@@ -80,8 +81,24 @@ func CreateUser(c echo.Context) error {
 	validator.RequiredString("Password", userRequest.Password, "Passwort ist ein Pflichtfeld")
 	validator.RequiredString("Name", userRequest.Name, "Name ist ein Pflichtfeld")
 
-	if err := validator.ValidateAndRespond(c); err != nil {
-		return err
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+
+	// Zeitzone muss eine gültige IANA-Zeitzone sein (leer = Zeitzone der Organisation)
+	if _, err := models.LoadLocation(userRequest.TimeZone); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Zeitzone",
+		})
+	}
+
+	// Wochenarbeitszeit höchstens 60 Stunden (6 Werktage à 10 Stunden nach ArbZG)
+	if userRequest.WeeklyHours < 0 || userRequest.WeeklyHours > 60 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Wochenarbeitszeit muss zwischen 0 und 60 Stunden liegen",
+		})
 	}
 
 	// Hash das Passwort
@@ -95,8 +112,7 @@ func CreateUser(c echo.Context) error {
 	// Generiere eine AccountNumber falls nicht angegeben
 	accountNumber := userRequest.AccountNumber
 	if accountNumber == "" {
-		// Einfache Generierung basierend auf Username und Timestamp
-		accountNumber = "ACC-" + userRequest.Username + "-" + strconv.FormatInt(time.Now().Unix(), 10)
+		accountNumber = defaultAccountNumber(userRequest.Username)
 	}
 
 	// Erstelle den neuen Benutzer
@@ -110,9 +126,11 @@ func CreateUser(c echo.Context) error {
 		Role:          userRequest.Role,
 		IsActive:      userRequest.IsActive,
 		IsAdmin:       userRequest.IsAdmin,
+		TimeZone:      userRequest.TimeZone,
+		WeeklyHours:   userRequest.WeeklyHours,
 	}
 
-	if err := database.DB.Create(&user).Error; err != nil {
+	if err := tenantDB(c).Create(&user).Error; err != nil {
 		// Log den spezifischen Fehler für Debugging
 		c.Logger().Errorf("Fehler beim Erstellen des Benutzers: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
@@ -133,22 +151,24 @@ func UpdateUser(c echo.Context) error {
 	}
 
 	var user models.User
-	if err := database.DB.First(&user, id).Error; err != nil {
+	if err := tenantDB(c).First(&user, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Benutzer nicht gefunden",
 		})
 	}
 
 	var updateRequest struct {
-		Username      string `json:"username"`
-		Email         string `json:"email"`
-		Password      string `json:"password,omitempty"`
-		AccountNumber string `json:"account_number"`
-		Name          string `json:"name"`
-		Color         string `json:"color"`
-		Role          string `json:"role"`
-		IsActive      bool   `json:"is_active"`
-		IsAdmin       bool   `json:"is_admin"`
+		Username      string  `json:"username"`
+		Email         string  `json:"email"`
+		Password      string  `json:"password,omitempty"`
+		AccountNumber string  `json:"account_number"`
+		Name          string  `json:"name"`
+		Color         string  `json:"color"`
+		Role          string  `json:"role"`
+		IsActive      bool    `json:"is_active"`
+		IsAdmin       bool    `json:"is_admin"`
+		TimeZone      string  `json:"time_zone"`
+		WeeklyHours   float64 `json:"weekly_hours"`
 	}
 
 	if err := c.Bind(&updateRequest); err != nil {
@@ -163,8 +183,24 @@ func UpdateUser(c echo.Context) error {
 	validator.RequiredString("Email", updateRequest.Email, "E-Mail ist ein Pflichtfeld")
 	validator.RequiredString("Name", updateRequest.Name, "Name ist ein Pflichtfeld")
 
-	if err := validator.ValidateAndRespond(c); err != nil {
-		return err
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+
+	// Zeitzone muss eine gültige IANA-Zeitzone sein (leer = Zeitzone der Organisation)
+	if _, err := models.LoadLocation(updateRequest.TimeZone); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Zeitzone",
+		})
+	}
+
+	// Wochenarbeitszeit höchstens 60 Stunden (6 Werktage à 10 Stunden nach ArbZG)
+	if updateRequest.WeeklyHours < 0 || updateRequest.WeeklyHours > 60 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Wochenarbeitszeit muss zwischen 0 und 60 Stunden liegen",
+		})
 	}
 
 	// Aktualisiere die Felder
@@ -179,6 +215,8 @@ func UpdateUser(c echo.Context) error {
 	user.Role = updateRequest.Role
 	user.IsActive = updateRequest.IsActive
 	user.IsAdmin = updateRequest.IsAdmin
+	user.TimeZone = updateRequest.TimeZone
+	user.WeeklyHours = updateRequest.WeeklyHours
 
 	// Hash das Passwort nur wenn es geändert wurde
 	if updateRequest.Password != "" {
@@ -191,7 +229,7 @@ func UpdateUser(c echo.Context) error {
 		user.Password = string(hashedPassword)
 	}
 
-	if err := database.DB.Save(&user).Error; err != nil {
+	if err := tenantDB(c).Save(&user).Error; err != nil {
 		// Log den spezifischen Fehler für Debugging
 		c.Logger().Errorf("Fehler beim Aktualisieren des Benutzers: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
@@ -212,13 +250,13 @@ func DeleteUser(c echo.Context) error {
 	}
 
 	var user models.User
-	if err := database.DB.First(&user, id).Error; err != nil {
+	if err := tenantDB(c).First(&user, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Benutzer nicht gefunden",
 		})
 	}
 
-	if err := database.DB.Delete(&user).Error; err != nil {
+	if err := tenantDB(c).Delete(&user).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Löschen des Benutzers",
 		})
@@ -237,10 +275,10 @@ func GetActiveUsers(c echo.Context) error {
 	var total int64
 
 	// Zähle die Gesamtanzahl der aktiven Benutzer
-	database.DB.Model(&models.User{}).Where("is_active = ?", true).Count(&total)
+	tenantDB(c).Model(&models.User{}).Where("is_active = ?", true).Count(&total)
 
 	// Lade die paginierten Daten
-	if err := database.DB.Where("is_active = ?", true).Offset(params.Offset).Limit(params.PageSize).Find(&users).Error; err != nil {
+	if err := tenantDB(c).Where("is_active = ?", true).Offset(params.Offset).Limit(params.PageSize).Find(&users).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Laden der aktiven Benutzer",
 		})
@@ -260,7 +298,7 @@ func ChangePassword(c echo.Context) error {
 	}
 
 	var user models.User
-	if err := database.DB.First(&user, id).Error; err != nil {
+	if err := tenantDB(c).First(&user, id).Error; err != nil {
 		return c.JSON(http.StatusNotFound, map[string]string{
 			"error": "Benutzer nicht gefunden",
 		})
@@ -294,7 +332,7 @@ func ChangePassword(c echo.Context) error {
 
 	user.Password = string(hashedPassword)
 
-	if err := database.DB.Save(&user).Error; err != nil {
+	if err := tenantDB(c).Save(&user).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Aktualisieren des Passworts",
 		})
@@ -320,10 +358,10 @@ func GetUsersByTeam(c echo.Context) error {
 	var total int64
 
 	// Zähle die Gesamtanzahl der Benutzer im Team
-	database.DB.Model(&models.User{}).Where("team_id = ?", teamID).Count(&total)
+	tenantDB(c).Model(&models.User{}).Where("team_id = ?", teamID).Count(&total)
 
 	// Lade die paginierten Daten
-	if err := database.DB.Where("team_id = ?", teamID).Preload("Team").Offset(params.Offset).Limit(params.PageSize).Find(&users).Error; err != nil {
+	if err := tenantDB(c).Where("team_id = ?", teamID).Preload("Team").Offset(params.Offset).Limit(params.PageSize).Find(&users).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Laden der Team-Benutzer",
 		})
@@ -341,10 +379,10 @@ func GetUsersWithoutTeam(c echo.Context) error {
 	var total int64
 
 	// Zähle die Gesamtanzahl der Benutzer ohne Team
-	database.DB.Model(&models.User{}).Where("team_id IS NULL").Count(&total)
+	tenantDB(c).Model(&models.User{}).Where("team_id IS NULL").Count(&total)
 
 	// Lade die paginierten Daten
-	if err := database.DB.Where("team_id IS NULL").Offset(params.Offset).Limit(params.PageSize).Find(&users).Error; err != nil {
+	if err := tenantDB(c).Where("team_id IS NULL").Offset(params.Offset).Limit(params.PageSize).Find(&users).Error; err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Fehler beim Laden der Benutzer ohne Team",
 		})
@@ -353,3 +391,9 @@ func GetUsersWithoutTeam(c echo.Context) error {
 	response := utils.CreatePaginatedResponse(users, int(total), params)
 	return c.JSON(http.StatusOK, response)
 }
+
+// defaultAccountNumber erzeugt eine Personalnummer für Benutzer ohne Angabe (einfache Generierung basierend auf
+// Username und Timestamp)
+func defaultAccountNumber(username string) string {
+	return "ACC-" + username + "-" + strconv.FormatInt(time.Now().Unix(), 10)
+}
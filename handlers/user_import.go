@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+	"schichtplaner/services"
+	"schichtplaner/utils"
+
+	"github.com/labstack/echo/v4"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// userInvitationValidity ist die Gültigkeit von Einladungen importierter Benutzer
+const userInvitationValidity = 14 * 24 * time.Hour
+
+// ImportUsers legt Benutzer aus einer CSV-Datei an (nur Administratoren). Spalten werden über mapping (Feld → Spalte)
+// oder anhand üblicher Überschriften zugeordnet. Mit dry_run werden die Zeilen nur geprüft; der Import erfolgt
+// vollständig oder gar nicht. password_mode initial vergibt initial_password oder je Benutzer ein erzeugtes
+// Startpasswort, invitation erzeugt Einladungen, mit denen die Benutzer ihr Passwort selbst festlegen.
+func ImportUsers(c echo.Context) error {
+	if user := currentUser(c); user != nil && !user.IsAdmin {
+		return c.JSON(http.StatusForbidden, map[string]string{
+			"error": "Nur Administratoren dürfen Benutzer importieren",
+		})
+	}
+
+	var request struct {
+		CSV             string            `json:"csv"`
+		Mapping         map[string]string `json:"mapping"`
+		DryRun          bool              `json:"dry_run"`
+		PasswordMode    string            `json:"password_mode"`
+		InitialPassword string            `json:"initial_password"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Importdaten",
+		})
+	}
+	switch request.PasswordMode {
+	case "":
+		request.PasswordMode = models.UserImportPasswordInitial
+	case models.UserImportPasswordInitial:
+	case models.UserImportPasswordInvitation:
+		if request.InitialPassword != "" {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Bei Einladungen wird kein Startpasswort vergeben",
+			})
+		}
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Zugangsart (password_mode) muss initial oder invitation sein",
+		})
+	}
+
+	columns, mapping, rows, err := services.ParseUserImportCSV(request.CSV, request.Mapping)
+	if err != nil {
+		message := err.Error()
+		if len(columns) > 0 {
+			message += " (Spalten: " + strings.Join(columns, ", ") + ")"
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": message,
+		})
+	}
+	if len(rows) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "CSV-Datei enthält keine Benutzer",
+		})
+	}
+
+	// Gelöschte Benutzer belegen Benutzername, E-Mail und Personalnummer weiterhin
+	var existing []models.User
+	if err := tenantDB(c).Unscoped().Find(&existing).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Benutzer",
+		})
+	}
+	var teams []models.Team
+	if err := tenantDB(c).Find(&teams).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Teams",
+		})
+	}
+	services.ValidateUserImport(rows, existing, teams)
+
+	report := models.UserImportReport{DryRun: request.DryRun, Columns: columns, Mapping: mapping, Total: len(rows), Rows: rows}
+	for _, row := range rows {
+		if len(row.Errors) > 0 {
+			report.Invalid++
+		}
+	}
+	report.Valid = report.Total - report.Invalid
+	if request.DryRun {
+		return c.JSON(http.StatusOK, report)
+	}
+	if report.Invalid > 0 {
+		report.Error = fmt.Sprintf("Import abgebrochen: %d von %d Zeilen enthalten Fehler", report.Invalid, report.Total)
+		return c.JSON(http.StatusBadRequest, report)
+	}
+
+	sharedHash := ""
+	if request.InitialPassword != "" {
+		hashed, err := bcrypt.GenerateFromPassword([]byte(request.InitialPassword), bcrypt.DefaultCost)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Fehler beim Verschlüsseln des Passworts",
+			})
+		}
+		sharedHash = string(hashed)
+	}
+
+	now := time.Now()
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		for i := range rows {
+			row := &rows[i]
+			passwordHash := sharedHash
+			if passwordHash == "" {
+				// Bei Einladungen bleibt das erzeugte Passwort unbekannt, bis der Benutzer ein eigenes festlegt
+				password, err := services.GenerateInitialPassword()
+				if err != nil {
+					return err
+				}
+				hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+				if err != nil {
+					return err
+				}
+				passwordHash = string(hashed)
+				if request.PasswordMode == models.UserImportPasswordInitial {
+					row.InitialPassword = password
+				}
+			}
+
+			accountNumber := row.AccountNumber
+			if accountNumber == "" {
+				accountNumber = defaultAccountNumber(row.Username)
+			}
+			user := models.User{
+				Username:      row.Username,
+				Email:         row.Email,
+				Password:      passwordHash,
+				AccountNumber: accountNumber,
+				Name:          row.Name,
+				Role:          row.Role,
+				IsActive:      true,
+				TeamID:        row.TeamID,
+			}
+			if err := tx.Create(&user).Error; err != nil {
+				return fmt.Errorf("Zeile %d: %w", row.Line, err)
+			}
+			row.UserID = user.ID
+
+			if request.PasswordMode == models.UserImportPasswordInvitation {
+				token, hash, prefix, err := services.GenerateInvitationToken()
+				if err != nil {
+					return err
+				}
+				invitation := models.UserInvitation{UserID: user.ID, TokenHash: hash, Prefix: prefix, ExpiresAt: now.Add(userInvitationValidity)}
+				if err := tx.Create(&invitation).Error; err != nil {
+					return err
+				}
+				row.InvitationToken = token
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Import der Benutzer: " + err.Error(),
+		})
+	}
+
+	report.Rows = rows
+	report.Created = len(rows)
+	return c.JSON(http.StatusCreated, report)
+}
+
+// ExportUsers liefert die Benutzer als CSV-Datei in der Spaltenfolge des Imports.
+// Filter: team_id (oder none für Benutzer ohne Team), role und is_active.
+func ExportUsers(c echo.Context) error {
+	query := tenantDB(c).Preload("Team").Order("name ASC, id ASC")
+	switch teamID := c.QueryParam("team_id"); teamID {
+	case "":
+	case "none":
+		query = query.Where("team_id IS NULL")
+	default:
+		id, err := strconv.ParseUint(teamID, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "Ungültige Team-ID",
+			})
+		}
+		query = query.Where("team_id = ?", id)
+	}
+	if role := c.QueryParam("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+	if active := c.QueryParam("is_active"); active != "" {
+		isActive, err := strconv.ParseBool(active)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "is_active muss true oder false sein",
+			})
+		}
+		query = query.Where("is_active = ?", isActive)
+	}
+
+	var users []models.User
+	if err := query.Find(&users).Error; err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Laden der Benutzer",
+		})
+	}
+
+	header := make([]string, 0, len(services.UserImportFields)+1)
+	for _, field := range services.UserImportFields {
+		header = append(header, services.UserImportColumn(field))
+	}
+	rows := [][]string{append(header, "Aktiv")}
+	for _, user := range users {
+		active := "Nein"
+		if user.IsActive {
+			active = "Ja"
+		}
+		team := ""
+		if user.TeamID != nil {
+			team = user.Team.Name
+		}
+		rows = append(rows, []string{user.Username, user.Email, user.Name, user.AccountNumber, team, user.Role, active})
+	}
+	return respondCSV(c, "benutzer_"+time.Now().Format("2006-01-02")+".csv", rows)
+}
+
+// AcceptInvitation legt mit einer Einladung das Passwort eines importierten Benutzers fest (token, password).
+// Eingeladene Benutzer haben noch kein API-Token; die Einladung bestimmt daher auch die Organisation.
+func AcceptInvitation(c echo.Context) error {
+	var request struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Ungültige Angaben zur Einladung",
+		})
+	}
+
+	validator := utils.NewValidator()
+	validator.RequiredString("Token", request.Token, "Einladung (token) ist ein Pflichtfeld")
+	validator.RequiredString("Password", request.Password, "Passwort ist ein Pflichtfeld")
+	if result := validator.Validate(); !result.IsValid {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": result.Errors[0],
+		})
+	}
+
+	// Die Organisation ist hier noch unbekannt, daher ohne Beschränkung suchen
+	var invitation models.UserInvitation
+	if err := database.DB.Where("token_hash = ?", services.HashAPIToken(request.Token)).First(&invitation).Error; err != nil ||
+		!invitation.IsUsable(time.Now()) {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "Einladung ist ungültig oder abgelaufen",
+		})
+	}
+	c.Set(tenantIDContextKey, invitation.TenantID)
+	c.SetRequest(c.Request().WithContext(database.WithTenant(c.Request().Context(), invitation.TenantID)))
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Verschlüsseln des Passworts",
+		})
+	}
+
+	now := time.Now()
+	err = tenantDB(c).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", invitation.UserID).Update("password", string(hashedPassword)).Error; err != nil {
+			return err
+		}
+		return tx.Model(&invitation).Update("accepted_at", now).Error
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Fehler beim Festlegen des Passworts",
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Passwort erfolgreich festgelegt",
+	})
+}
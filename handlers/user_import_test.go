@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestImportUsers(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+	assert.NoError(t, database.EnsureDefaultTenant(database.DB))
+
+	team := models.Team{Name: "Küche"}
+	database.DB.Create(&team)
+	database.DB.Create(&models.User{Username: "anna", Email: "anna@example.com", Password: "x", AccountNumber: "1001", Name: "Anna", IsActive: true})
+
+	csvData := "Benutzername;E-Mail;Name;Personalnummer;Team;Rolle\n" +
+		"saison1;saison1@example.com;Saison Eins;2001;Küche;employee\n" +
+		"saison2;keine-adresse;Saison Zwei;2002;Bar;\n"
+
+	// Probelauf meldet Fehler je Zeile, ohne etwas anzulegen
+	rec := callHandler(t, ImportUsers, handlerRequest{body: jsonBody(t, map[string]interface{}{"csv": csvData, "dry_run": true})})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var report models.UserImportReport
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 1, report.Invalid)
+	assert.Equal(t, []string{"Ungültige E-Mail-Adresse: keine-adresse", "Unbekanntes Team: Bar"}, report.Rows[1].Errors)
+
+	// Mit fehlerhaften Zeilen wird nichts importiert
+	rec = callHandler(t, ImportUsers, handlerRequest{body: jsonBody(t, map[string]interface{}{"csv": csvData})})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Import abgebrochen")
+	var count int64
+	database.DB.Model(&models.User{}).Count(&count)
+	assert.Equal(t, int64(1), count)
+
+	// Einladungen statt Startpasswort
+	csvData = strings.Replace(csvData, "saison2;keine-adresse;Saison Zwei;2002;Bar;", "saison2;saison2@example.com;Saison Zwei;2002;;", 1)
+	rec = callHandler(t, ImportUsers, handlerRequest{body: jsonBody(t, map[string]interface{}{"csv": csvData, "password_mode": "invitation"})})
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	report = models.UserImportReport{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, 2, report.Created)
+	assert.Empty(t, report.Rows[0].InitialPassword)
+	assert.True(t, strings.HasPrefix(report.Rows[0].InvitationToken, "spi_"))
+
+	var imported models.User
+	database.DB.Where("username = ?", "saison1").First(&imported)
+	assert.Equal(t, team.ID, *imported.TeamID)
+	assert.Equal(t, models.RoleEmployee, imported.Role)
+
+	// Ohne Passwort bleiben Benutzer und Einladung unverändert
+	rec = callHandler(t, AcceptInvitation, handlerRequest{body: jsonBody(t, map[string]string{"token": report.Rows[0].InvitationToken, "password": ""})})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.JSONEq(t, `{"error":"Passwort ist ein Pflichtfeld"}`, rec.Body.String())
+	var unchanged models.User
+	database.DB.First(&unchanged, imported.ID)
+	assert.Equal(t, imported.Password, unchanged.Password)
+	var invitation models.UserInvitation
+	database.DB.Where("user_id = ?", imported.ID).First(&invitation)
+	assert.Nil(t, invitation.AcceptedAt)
+
+	// Die Einladung legt das Passwort fest und kann nur einmal verwendet werden
+	accept := map[string]string{"token": report.Rows[0].InvitationToken, "password": "Sommer2024!"}
+	assert.Equal(t, http.StatusOK, callHandler(t, AcceptInvitation, handlerRequest{body: jsonBody(t, accept)}).Code)
+	database.DB.First(&imported, imported.ID)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(imported.Password), []byte("Sommer2024!")))
+	assert.Equal(t, http.StatusNotFound, callHandler(t, AcceptInvitation, handlerRequest{body: jsonBody(t, accept)}).Code)
+
+	// Erneuter Import meldet die nun vorhandenen Benutzer als doppelt
+	rec = callHandler(t, ImportUsers, handlerRequest{body: jsonBody(t, map[string]interface{}{"csv": csvData, "password_mode": "initial", "dry_run": true})})
+	assert.Contains(t, rec.Body.String(), "Benutzername saison1 bereits vergeben")
+
+	// Export mit Filter auf das Team
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/?team_id=1", nil)
+	rec = httptest.NewRecorder()
+	assert.NoError(t, ExportUsers(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	lines := strings.Split(strings.TrimSpace(strings.TrimPrefix(rec.Body.String(), "\ufeff")), "\n")
+	assert.Equal(t, []string{"Benutzername;E-Mail;Name;Personalnummer;Team;Rolle;Aktiv", "saison1;saison1@example.com;Saison Eins;2001;Küche;employee;Ja"}, lines)
+}
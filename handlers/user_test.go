@@ -24,9 +24,10 @@ func setupTestDB() {
 	if err != nil {
 		panic("Failed to connect to test database")
 	}
+	database.DB.Use(database.TenantGuard{})
 
 	// Auto-Migration für Tests
-	database.DB.AutoMigrate(&models.User{}, &models.Shift{}, &models.Schedule{}, &models.Team{}, &models.ShiftType{})
+	database.DB.AutoMigrate(&models.User{}, &models.Shift{}, &models.Schedule{}, &models.Team{}, &models.ShiftType{}, &models.RecurringShift{}, &models.RecurringShiftException{}, &models.TeamRule{}, &models.CompanyHoliday{}, &models.SurchargeRule{}, &models.Absence{}, &models.AbsenceCreditRule{}, &models.TimeAccountCorrection{}, &models.EmploymentContract{}, &models.Qualification{}, &models.UserQualification{}, &models.Location{}, &models.Tenant{}, &models.APIToken{}, &models.TimeEntry{}, &models.TimeEntryBreak{}, &models.TimeEntryCorrection{}, &models.Timesheet{}, &models.TimesheetEvent{}, &models.WageTypeMapping{}, &models.PayrollSettings{}, &models.PayrollExport{}, &models.OnCallDuty{}, &models.ChecklistTemplate{}, &models.ShiftChecklist{}, &models.ShiftChecklistItem{}, &models.Comment{}, &models.CommentMention{}, &models.CommentRevision{}, &models.CalendarFeed{}, &models.CalendarSyncState{}, &models.UserInvitation{})
 }
 
 func cleanupTestDB() {
@@ -178,3 +179,60 @@ func TestChangePassword(t *testing.T) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 	}
 }
+
+func TestCreateUser_InvalidTimeZone(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	userJSON, _ := json.Marshal(map[string]interface{}{
+		"username":  "zeitzone",
+		"email":     "zeitzone@example.com",
+		"password":  "password123",
+		"name":      "Zeitzone User",
+		"time_zone": "Europe/Atlantis",
+	})
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/users", bytes.NewBuffer(userJSON))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if assert.NoError(t, CreateUser(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestCreateHandlers_InvalidInput(t *testing.T) {
+	setupTestDB()
+	defer cleanupTestDB()
+
+	// Pflichtfelder fehlen: nur eine Fehlerantwort, der Handler speichert nichts
+	for _, testCase := range []struct {
+		handler echo.HandlerFunc
+		message string
+	}{
+		{CreateSchedule, "Name ist ein Pflichtfeld"},
+		{CreateTeam, "Name ist ein Pflichtfeld"},
+		{CreateShiftType, "Name ist ein Pflichtfeld"},
+		{CreateShiftTemplate, "Name ist ein Pflichtfeld"},
+		{CreateUser, "Benutzername ist ein Pflichtfeld"},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+
+		if assert.NoError(t, testCase.handler(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.JSONEq(t, `{"error":"`+testCase.message+`"}`, rec.Body.String())
+		}
+	}
+
+	var schedules, teams, shiftTypes, users int64
+	database.DB.Model(&models.Schedule{}).Count(&schedules)
+	database.DB.Model(&models.Team{}).Count(&teams)
+	database.DB.Model(&models.ShiftType{}).Count(&shiftTypes)
+	database.DB.Model(&models.User{}).Count(&users)
+	assert.Zero(t, schedules+teams+shiftTypes+users)
+}
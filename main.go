@@ -3,12 +3,15 @@ package main
 import (
 	"context"
 	"fmt"
+	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 
 	"schichtplaner/database"
 	"schichtplaner/frontend"
+	"schichtplaner/models"
 	"schichtplaner/routes"
 
 	"github.com/labstack/echo-contrib/echoprometheus"
@@ -21,6 +24,37 @@ func main() {
 	database.InitDatabase()
 	defer database.CloseDatabase()
 
+	// Zeitzone der Organisation (Standard: Europe/Berlin)
+	if name := os.Getenv("SCHICHTPLANER_TIMEZONE"); name != "" {
+		loc, err := models.LoadLocation(name)
+		if err != nil {
+			log.Fatal("Fehler beim Laden der Zeitzone:", err)
+		}
+		models.SetOrganisationLocation(loc)
+	}
+
+	// Bundesland der Organisation für gesetzliche Feiertage
+	if state := os.Getenv("SCHICHTPLANER_BUNDESLAND"); state != "" {
+		if !models.IsValidState(state) {
+			log.Fatal("Unbekanntes Bundesland:", state)
+		}
+		models.SetOrganisationState(state)
+	}
+
+	// Organisationen über Subdomains auflösen, z.B. nord.schichtplaner.example
+	if domain := os.Getenv("SCHICHTPLANER_BASE_DOMAIN"); domain != "" {
+		models.SetTenantBaseDomain(domain)
+	}
+
+	// API-Anfragen nur mit gültigem API-Token zulassen
+	if required := os.Getenv("SCHICHTPLANER_REQUIRE_AUTH"); required != "" {
+		enabled, err := strconv.ParseBool(required)
+		if err != nil {
+			log.Fatal("Ungültiger Wert für SCHICHTPLANER_REQUIRE_AUTH:", required)
+		}
+		models.SetAuthRequired(enabled)
+	}
+
 	// Echo-Server erstellen
 	e := echo.New()
 
@@ -0,0 +1,105 @@
+package models
+
+import "time"
+
+// Arten von Abwesenheiten
+const (
+	AbsenceTypeVacation     = "vacation"      // Urlaub
+	AbsenceTypeSick         = "sick"          // Krankheit
+	AbsenceTypeTraining     = "training"      // Fortbildung
+	AbsenceTypeSpecialLeave = "special_leave" // Sonderurlaub
+	AbsenceTypeUnpaid       = "unpaid"        // Unbezahlter Urlaub
+	AbsenceTypeCompensatory = "compensatory"  // Freizeitausgleich
+)
+
+// AbsenceTypes enthält alle Abwesenheitsarten
+var AbsenceTypes = []string{
+	AbsenceTypeVacation, AbsenceTypeSick, AbsenceTypeTraining,
+	AbsenceTypeSpecialLeave, AbsenceTypeUnpaid, AbsenceTypeCompensatory,
+}
+
+// absenceTypeLabels enthält die deutschen Bezeichnungen der Abwesenheitsarten
+var absenceTypeLabels = map[string]string{
+	AbsenceTypeVacation:     "Urlaub",
+	AbsenceTypeSick:         "Krankheit",
+	AbsenceTypeTraining:     "Fortbildung",
+	AbsenceTypeSpecialLeave: "Sonderurlaub",
+	AbsenceTypeUnpaid:       "Unbezahlter Urlaub",
+	AbsenceTypeCompensatory: "Freizeitausgleich",
+}
+
+// absenceTypeCodes enthält die Kürzel der Abwesenheitsarten im Planraster
+var absenceTypeCodes = map[string]string{
+	AbsenceTypeVacation:     "U",
+	AbsenceTypeSick:         "K",
+	AbsenceTypeTraining:     "FB",
+	AbsenceTypeSpecialLeave: "SU",
+	AbsenceTypeUnpaid:       "UU",
+	AbsenceTypeCompensatory: "FZA",
+}
+
+// AbsenceTypeCode liefert das Kürzel einer Abwesenheitsart im Planraster, z.B. U für Urlaub
+func AbsenceTypeCode(absenceType string) string {
+	if code, ok := absenceTypeCodes[absenceType]; ok {
+		return code
+	}
+	return absenceType
+}
+
+// AbsenceTypeLabel liefert die deutsche Bezeichnung einer Abwesenheitsart oder die Art selbst, wenn sie unbekannt ist
+func AbsenceTypeLabel(absenceType string) string {
+	if label, ok := absenceTypeLabels[absenceType]; ok {
+		return label
+	}
+	return absenceType
+}
+
+// IsValidAbsenceType prüft, ob die Abwesenheitsart bekannt ist
+func IsValidAbsenceType(absenceType string) bool {
+	for _, t := range AbsenceTypes {
+		if t == absenceType {
+			return true
+		}
+	}
+	return false
+}
+
+// Status einer Abwesenheit
+const (
+	AbsenceStatusRequested = "requested" // Beantragt
+	AbsenceStatusApproved  = "approved"  // Genehmigt
+	AbsenceStatusRejected  = "rejected"  // Abgelehnt
+)
+
+// Absence repräsentiert eine ganztägige Abwesenheit eines Benutzers
+type Absence struct {
+	Base
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Type      string    `gorm:"not null" json:"type"`
+	StartDate time.Time `gorm:"not null;index" json:"start_date"` // Erster Kalendertag, 00:00 Uhr UTC
+	EndDate   time.Time `gorm:"not null;index" json:"end_date"`   // Letzter Kalendertag (inklusive), 00:00 Uhr UTC
+	Status    string    `gorm:"default:'requested'" json:"status"`
+	Note      string    `json:"note"`
+}
+
+// Covers prüft, ob die Abwesenheit den Kalendertag day (00:00 Uhr UTC) umfasst
+func (a Absence) Covers(day time.Time) bool {
+	return !day.Before(a.StartDate) && !day.After(a.EndDate)
+}
+
+// Gutschriftsarten für Abwesenheiten auf dem Arbeitszeitkonto
+const (
+	AbsenceCreditTarget       = "target"        // Sollzeit des Tages wird gutgeschrieben
+	AbsenceCreditFixed        = "fixed"         // Fester Stundenwert je Arbeitstag wird gutgeschrieben
+	AbsenceCreditNone         = "none"          // Keine Gutschrift, die Sollzeit wird dem Konto belastet
+	AbsenceCreditReduceTarget = "reduce_target" // Sollzeit entfällt ohne Gutschrift
+)
+
+// AbsenceCreditRule legt fest, wie eine Abwesenheitsart auf dem Arbeitszeitkonto angerechnet wird
+type AbsenceCreditRule struct {
+	Base
+	AbsenceType string  `gorm:"not null;uniqueIndex:idx_absence_credit_rules_tenant_type,expression:tenant_id\\,absence_type" json:"absence_type"`
+	Mode        string  `gorm:"not null" json:"mode"`
+	Hours       float64 `json:"hours,omitempty"` // Stunden je Arbeitstag bei Mode fixed
+}
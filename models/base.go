@@ -12,4 +12,5 @@ type Base struct {
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	TenantID  uint           `gorm:"not null;default:1;index" json:"-"` // Organisation; wird vom Mandantenschutz der Datenbank gesetzt
 }
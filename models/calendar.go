@@ -0,0 +1,58 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// Umfang eines Kalender-Abonnements
+const (
+	CalendarScopeUser     = "user"     // Schichten und genehmigte Abwesenheiten eines Benutzers
+	CalendarScopeTeam     = "team"     // Schichten und genehmigte Abwesenheiten aller Teammitglieder
+	CalendarScopeSchedule = "schedule" // Alle Schichten eines Schichtplans
+)
+
+// CalendarFeed ist ein per geheimem Token geschütztes iCalendar-Abonnement (ICS) eines Benutzers, Teams oder
+// Schichtplans. Gespeichert wird nur der SHA-256-Hash des Tokens; ein neues Token macht das alte ungültig.
+type CalendarFeed struct {
+	Base
+	Scope          string     `gorm:"not null;uniqueIndex:idx_calendar_feeds_tenant_scope_target,expression:tenant_id\\,scope\\,target_id" json:"scope"`
+	TargetID       uint       `gorm:"not null" json:"target_id"` // ID des Benutzers, Teams bzw. Schichtplans
+	TokenHash      string     `gorm:"not null;uniqueIndex" json:"-"`
+	Prefix         string     `json:"prefix"`        // Erste Zeichen des Tokens zur Wiedererkennung
+	CreatedByID    *uint      `json:"created_by_id"` // Angemeldeter Benutzer beim Erzeugen des Tokens
+	LastAccessedAt *time.Time `json:"last_accessed_at"`
+
+	// Klartext-Token und Abo-Pfad, nur in der Antwort auf das Erzeugen enthalten (werden nicht gespeichert)
+	Token string `gorm:"-" json:"token,omitempty"`
+	URL   string `gorm:"-" json:"url,omitempty"`
+}
+
+// Path liefert den Pfad des Abonnements ohne Token, z.B. /api/users/1/calendar.ics
+func (f CalendarFeed) Path() string {
+	return "/api/" + f.Scope + "s/" + strconv.FormatUint(uint64(f.TargetID), 10) + "/calendar.ics"
+}
+
+// CalendarEvent ist ein Termin im iCalendar-Abonnement (wird nicht gespeichert)
+type CalendarEvent struct {
+	UID          string    // Stabil je Schicht bzw. Abwesenheit, damit Änderungen den Termin ersetzen
+	Start        time.Time // Beginn; bei ganztägigen Terminen der erste Kalendertag (00:00 Uhr UTC)
+	End          time.Time // Ende; bei ganztägigen Terminen der Tag nach dem letzten Kalendertag
+	AllDay       bool
+	Summary      string
+	Description  string
+	Location     string
+	Color        string // Hex-Farbe des Schichttyps
+	Category     string
+	Cancelled    bool
+	LastModified time.Time
+}
+
+// CalendarSyncState speichert den Stand eines CalDAV-Kalenders zu einem ausgegebenen Sync-Token (RFC 6578),
+// damit spätere Synchronisationen nur Änderungen und gelöschte Termine liefern
+type CalendarSyncState struct {
+	Base
+	Calendar string            `gorm:"not null;uniqueIndex:idx_calendar_sync_states_tenant_calendar_token,expression:tenant_id\\,calendar\\,token" json:"calendar"` // z.B. user-1 oder team-2
+	Token    string            `gorm:"not null" json:"token"`
+	ETags    map[string]string `gorm:"serializer:json" json:"etags"` // Pfad des Objekts → ETag
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ChecklistTemplate ist eine Vorlage für wiederkehrende Aufgaben einer Schicht (z.B. Kassensturz, Alarmanlage).
+// Vorlagen werden Schichttypen zugeordnet und je Schicht als Checkliste angelegt.
+type ChecklistTemplate struct {
+	Base
+	Name        string   `gorm:"not null;uniqueIndex:idx_checklist_templates_tenant_name,expression:tenant_id\\,name" json:"name"`
+	Description string   `json:"description"`
+	Items       []string `gorm:"serializer:json" json:"items"`  // Aufgaben in der Reihenfolge der Abarbeitung
+	IsActive    bool     `gorm:"default:true" json:"is_active"` // Inaktive Vorlagen werden nicht mehr neu angelegt
+}
+
+// ShiftChecklist ist die Checkliste einer Schicht, angelegt aus einer Vorlage ihres Schichttyps
+type ShiftChecklist struct {
+	Base
+	ShiftID    uint   `gorm:"not null;uniqueIndex:idx_shift_checklists_tenant_shift_template,expression:tenant_id\\,shift_id\\,template_id" json:"shift_id"`
+	TemplateID uint   `gorm:"not null" json:"template_id"`
+	Name       string `json:"name"` // Name der Vorlage beim Anlegen
+
+	Items []ShiftChecklistItem `gorm:"foreignKey:ChecklistID" json:"items"`
+}
+
+// OpenItems liefert die Anzahl der noch nicht abgehakten Aufgaben
+func (c ShiftChecklist) OpenItems() int {
+	open := 0
+	for _, item := range c.Items {
+		if item.CompletedAt == nil {
+			open++
+		}
+	}
+	return open
+}
+
+// IsComplete prüft, ob alle Aufgaben abgehakt sind
+func (c ShiftChecklist) IsComplete() bool {
+	return c.OpenItems() == 0
+}
+
+// MarshalJSON ergänzt die Checkliste um die Anzahl offener Aufgaben und den Erledigt-Status
+func (c ShiftChecklist) MarshalJSON() ([]byte, error) {
+	type shiftChecklistJSON ShiftChecklist
+	return json.Marshal(struct {
+		shiftChecklistJSON
+		OpenItems  int  `json:"open_items"`
+		IsComplete bool `json:"is_complete"`
+	}{
+		shiftChecklistJSON: shiftChecklistJSON(c),
+		OpenItems:          c.OpenItems(),
+		IsComplete:         c.IsComplete(),
+	})
+}
+
+// ShiftChecklistItem ist eine Aufgabe einer Schicht-Checkliste
+type ShiftChecklistItem struct {
+	Base
+	ChecklistID   uint       `gorm:"not null;index" json:"checklist_id"`
+	Position      int        `json:"position"`
+	Title         string     `gorm:"not null" json:"title"`
+	CompletedAt   *time.Time `json:"completed_at"`    // Zeitpunkt des Abhakens, leer = offen
+	CompletedByID *uint      `json:"completed_by_id"` // Angemeldeter Benutzer beim Abhaken
+}
+
+// IncompleteChecklist ist eine unvollständige Checkliste im Bericht (wird nicht gespeichert)
+type IncompleteChecklist struct {
+	ChecklistID uint      `json:"checklist_id"`
+	Name        string    `json:"name"`
+	ShiftID     uint      `json:"shift_id"`
+	UserID      uint      `json:"user_id"`
+	UserName    string    `json:"user_name"`
+	StartTime   time.Time `json:"start_time"`
+	EndTime     time.Time `json:"end_time"`
+	OpenItems   int       `json:"open_items"`
+	TotalItems  int       `json:"total_items"`
+	Overdue     bool      `json:"overdue"` // Schicht ist bereits beendet
+}
+
+// ChecklistDayReport fasst die unvollständigen Checklisten eines Standorts an einem Kalendertag zusammen (wird nicht gespeichert)
+type ChecklistDayReport struct {
+	Date         time.Time             `json:"date"`        // Kalendertag in der Zeitzone des Standorts, 00:00 Uhr UTC
+	LocationID   uint                  `json:"location_id"` // 0 = ohne Standort
+	LocationName string                `json:"location_name"`
+	Checklists   []IncompleteChecklist `json:"checklists"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Objekte, an denen Kommentare hängen können
+const (
+	CommentTargetShift    = "shift"
+	CommentTargetSchedule = "schedule"
+)
+
+// Comment repräsentiert einen Kommentar an einer Schicht oder einem Schichtplan. Antworten verweisen über ParentID
+// auf den beantworteten Kommentar; Übergabenotizen (IsHandover) werden der nächsten Schicht desselben Typs und Teams angezeigt.
+type Comment struct {
+	Base
+	TargetType string     `gorm:"not null;index:idx_comments_target" json:"target_type"` // shift oder schedule
+	TargetID   uint       `gorm:"not null;index:idx_comments_target" json:"target_id"`
+	ParentID   *uint      `gorm:"index" json:"parent_id"` // Beantworteter Kommentar, leer = neuer Thread
+	AuthorID   *uint      `json:"author_id"`              // Angemeldeter Benutzer, leer ohne Anmeldung
+	Author     *User      `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	Body       string     `gorm:"not null" json:"body"`
+	IsHandover bool       `gorm:"default:false" json:"is_handover"` // Übergabenotiz für die nächste Schicht (nur an Schichten)
+	EditedAt   *time.Time `json:"edited_at"`                        // Zeitpunkt der letzten Bearbeitung
+
+	Mentions  []CommentMention  `gorm:"foreignKey:CommentID" json:"mentions,omitempty"`
+	Revisions []CommentRevision `gorm:"foreignKey:CommentID" json:"revisions,omitempty"`
+
+	// Antworten im Thread (werden nicht gespeichert)
+	Replies []Comment `gorm:"-" json:"replies,omitempty"`
+}
+
+// CommentMention ist die Erwähnung eines Benutzers (@benutzername) in einem Kommentar
+type CommentMention struct {
+	Base
+	CommentID uint `gorm:"not null;index" json:"comment_id"`
+	UserID    uint `gorm:"not null;index" json:"user_id"`
+}
+
+// CommentRevision speichert den Text eines Kommentars vor einer Bearbeitung
+type CommentRevision struct {
+	Base
+	CommentID uint   `gorm:"not null;index" json:"comment_id"`
+	Body      string `json:"body"`      // Text vor der Bearbeitung
+	EditorID  *uint  `json:"editor_id"` // Bearbeitender Benutzer
+}
+
+// ShiftHandover sind die Übergabenotizen der vorherigen Schicht desselben Typs und Teams (wird nicht gespeichert)
+type ShiftHandover struct {
+	PreviousShift *Shift    `json:"previous_shift"`
+	Notes         []Comment `json:"notes"`
+}
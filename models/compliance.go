@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// Schweregrade für Regelverstöße
+const (
+	SeverityError   = "error"   // Verstoß, der behoben werden muss
+	SeverityWarning = "warning" // Hinweis, der geprüft werden sollte
+)
+
+// ComplianceViolation beschreibt einen Regelverstoß eines Benutzers (wird nicht gespeichert)
+type ComplianceViolation struct {
+	Rule     string    `json:"rule"`
+	RuleID   uint      `json:"rule_id,omitempty"` // Gesetzt bei Verstößen gegen Teamregeln
+	Severity string    `json:"severity"`
+	UserID   uint      `json:"user_id"`
+	ShiftID  uint      `json:"shift_id,omitempty"` // Leer bei Verstößen, die sich auf einen Zeitraum beziehen
+	Date     time.Time `json:"date"`
+	Message  string    `json:"message"`
+}
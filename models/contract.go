@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Beschäftigungsarten
+const (
+	EmploymentFullTime = "full_time" // Vollzeit
+	EmploymentPartTime = "part_time" // Teilzeit
+	EmploymentMinijob  = "minijob"   // Geringfügige Beschäftigung
+	EmploymentTrainee  = "trainee"   // Ausbildung
+)
+
+// EmploymentTypes enthält alle Beschäftigungsarten
+var EmploymentTypes = []string{EmploymentFullTime, EmploymentPartTime, EmploymentMinijob, EmploymentTrainee}
+
+// IsValidEmploymentType prüft, ob die Beschäftigungsart bekannt ist
+func IsValidEmploymentType(employmentType string) bool {
+	for _, t := range EmploymentTypes {
+		if t == employmentType {
+			return true
+		}
+	}
+	return false
+}
+
+// EmploymentContract ist ein Arbeitsvertrag mit Gültigkeitszeitraum; die Verträge eines Benutzers bilden seine Vertragshistorie
+type EmploymentContract struct {
+	Base
+	UserID             uint       `gorm:"not null;index" json:"user_id"`
+	ValidFrom          time.Time  `gorm:"not null;index" json:"valid_from"` // Erster Kalendertag, 00:00 Uhr UTC
+	ValidTo            *time.Time `gorm:"index" json:"valid_to"`            // Letzter Kalendertag (inklusive), leer = unbefristet
+	WeeklyHours        float64    `gorm:"not null" json:"weekly_hours"`
+	WorkingDaysPerWeek int        `gorm:"default:5" json:"working_days_per_week"` // Arbeitstage ab Montag
+	EmploymentType     string     `gorm:"not null" json:"employment_type"`
+	VacationDays       float64    `json:"vacation_days"` // Urlaubsanspruch pro Kalenderjahr
+	Note               string     `json:"note"`
+}
+
+// Covers prüft, ob der Vertrag am Kalendertag day (00:00 Uhr UTC) gilt
+func (c EmploymentContract) Covers(day time.Time) bool {
+	return !day.Before(c.ValidFrom) && (c.ValidTo == nil || !day.After(*c.ValidTo))
+}
+
+// IsWorkingDay prüft, ob der Wochentag nach dem Vertrag ein Arbeitstag ist; Arbeitstage beginnen am Montag
+func (c EmploymentContract) IsWorkingDay(weekday time.Weekday) bool {
+	index := (int(weekday) + 6) % 7 // Montag = 0, Sonntag = 6
+	return index < c.WorkingDaysPerWeek
+}
+
+// DailyHours liefert die Sollstunden je Arbeitstag
+func (c EmploymentContract) DailyHours() float64 {
+	if c.WorkingDaysPerWeek <= 0 {
+		return 0
+	}
+	return c.WeeklyHours / float64(c.WorkingDaysPerWeek)
+}
@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// Kategorien unbeliebter Schichten für die Fairnessauswertung
+const (
+	FairnessWeekend = "weekend" // Beginn am Samstag oder Sonntag
+	FairnessNight   = "night"   // Mehr als 2 Stunden zwischen 23:00 und 06:00 Uhr (§ 2 ArbZG)
+	FairnessHoliday = "holiday" // Beginn an einem Feiertag
+	FairnessLate    = "late"    // Ende nach 20:00 Uhr, keine Nachtschicht
+)
+
+// FairnessCategories enthält alle Kategorien der Fairnessauswertung
+var FairnessCategories = []string{FairnessWeekend, FairnessNight, FairnessHoliday, FairnessLate}
+
+// FairnessCounts enthält je Kategorie einen Wert (Anzahl, normalisierte Anzahl oder Abweichung)
+type FairnessCounts struct {
+	Weekend float64 `json:"weekend"`
+	Night   float64 `json:"night"`
+	Holiday float64 `json:"holiday"`
+	Late    float64 `json:"late"`
+}
+
+// Get liefert den Wert einer Kategorie
+func (f FairnessCounts) Get(category string) float64 {
+	switch category {
+	case FairnessWeekend:
+		return f.Weekend
+	case FairnessNight:
+		return f.Night
+	case FairnessHoliday:
+		return f.Holiday
+	case FairnessLate:
+		return f.Late
+	}
+	return 0
+}
+
+// Add erhöht den Wert einer Kategorie um value
+func (f *FairnessCounts) Add(category string, value float64) {
+	switch category {
+	case FairnessWeekend:
+		f.Weekend += value
+	case FairnessNight:
+		f.Night += value
+	case FairnessHoliday:
+		f.Holiday += value
+	case FairnessLate:
+		f.Late += value
+	}
+}
+
+// Total liefert die Summe über alle Kategorien
+func (f FairnessCounts) Total() float64 {
+	return f.Weekend + f.Night + f.Holiday + f.Late
+}
+
+// UserFairness ist die Belastung eines Benutzers mit unbeliebten Schichten (wird nicht gespeichert)
+type UserFairness struct {
+	UserID      uint           `json:"user_id"`
+	UserName    string         `json:"user_name"`
+	TargetHours float64        `json:"target_hours"` // Sollstunden laut Vertrag im Zeitraum
+	Shifts      int            `json:"shifts"`
+	Counts      FairnessCounts `json:"counts"`     // Anzahl je Kategorie
+	Normalized  FairnessCounts `json:"normalized"` // Anzahl je 100 Sollstunden
+	Deviation   FairnessCounts `json:"deviation"`  // Abweichung der normalisierten Anzahl vom Teammittel
+	Score       float64        `json:"score"`      // Summe der Abweichungen; positiv = überdurchschnittlich belastet
+}
+
+// TeamFairnessReport ist die Fairnessauswertung eines Teams für einen Zeitraum (wird nicht gespeichert)
+type TeamFairnessReport struct {
+	TeamID   uint           `json:"team_id"`
+	TeamName string         `json:"team_name"`
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	Mean     FairnessCounts `json:"mean"` // Teammittel der normalisierten Anzahl
+	Users    []UserFairness `json:"users"`
+}
+
+// FairnessCandidate ist ein Kandidat für eine Schicht mit seiner Belastung (wird nicht gespeichert)
+type FairnessCandidate struct {
+	UserFairness
+	Rank       int     `json:"rank"`
+	ShiftScore float64 `json:"shift_score"` // Summe der Abweichungen in den Kategorien der Schicht
+	Available  bool    `json:"available"`
+	Reason     string  `json:"reason,omitempty"` // Grund, warum der Kandidat nicht verfügbar ist
+}
+
+// ShiftCandidates ist die Rangfolge der Kandidaten für eine Schicht (wird nicht gespeichert)
+type ShiftCandidates struct {
+	ShiftID    uint                `json:"shift_id"`
+	TeamID     uint                `json:"team_id"`
+	Categories []string            `json:"categories"` // Kategorien der Schicht
+	From       time.Time           `json:"from"`
+	To         time.Time           `json:"to"`
+	Candidates []FairnessCandidate `json:"candidates"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// Kürzel der Bundesländer nach ISO 3166-2:DE
+const (
+	StateBW = "BW" // Baden-Württemberg
+	StateBY = "BY" // Bayern
+	StateBE = "BE" // Berlin
+	StateBB = "BB" // Brandenburg
+	StateHB = "HB" // Bremen
+	StateHH = "HH" // Hamburg
+	StateHE = "HE" // Hessen
+	StateMV = "MV" // Mecklenburg-Vorpommern
+	StateNI = "NI" // Niedersachsen
+	StateNW = "NW" // Nordrhein-Westfalen
+	StateRP = "RP" // Rheinland-Pfalz
+	StateSL = "SL" // Saarland
+	StateSN = "SN" // Sachsen
+	StateST = "ST" // Sachsen-Anhalt
+	StateSH = "SH" // Schleswig-Holstein
+	StateTH = "TH" // Thüringen
+)
+
+// States enthält alle Bundesländer
+var States = []string{
+	StateBW, StateBY, StateBE, StateBB, StateHB, StateHH, StateHE, StateMV,
+	StateNI, StateNW, StateRP, StateSL, StateSN, StateST, StateSH, StateTH,
+}
+
+// IsValidState prüft, ob das Kürzel ein Bundesland bezeichnet
+func IsValidState(state string) bool {
+	for _, s := range States {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	organisationStateMu sync.RWMutex
+	organisationState   string
+)
+
+// OrganisationState liefert das Bundesland der Organisation; leer = nur bundesweite Feiertage
+func OrganisationState() string {
+	organisationStateMu.RLock()
+	defer organisationStateMu.RUnlock()
+	return organisationState
+}
+
+// SetOrganisationState setzt das Bundesland der Organisation
+func SetOrganisationState(state string) {
+	organisationStateMu.Lock()
+	defer organisationStateMu.Unlock()
+	organisationState = state
+}
+
+// Holiday ist ein Feiertag an einem Kalendertag (wird nicht gespeichert)
+type Holiday struct {
+	Date    time.Time `json:"date"` // Kalendertag, 00:00 Uhr UTC
+	Name    string    `json:"name"`
+	State   string    `json:"state,omitempty"` // Leer = bundesweit
+	Company bool      `json:"company"`         // Betrieblicher Feiertag statt gesetzlichem Feiertag
+}
+
+// CompanyHoliday ist ein betrieblicher Feiertag, etwa ein Betriebsausflug oder Heiligabend
+type CompanyHoliday struct {
+	Base
+	Date      time.Time `gorm:"not null;index" json:"date"` // Kalendertag
+	Name      string    `gorm:"not null" json:"name"`
+	State     string    `json:"state,omitempty"`                // Optional: nur in diesem Bundesland
+	Recurring bool      `gorm:"default:false" json:"recurring"` // Jedes Jahr am selben Tag
+}
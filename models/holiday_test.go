@@ -0,0 +1,24 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidState(t *testing.T) {
+	assert.Len(t, States, 16)
+	assert.True(t, IsValidState(StateBY))
+	assert.True(t, IsValidState("NW"))
+	assert.False(t, IsValidState("by"))
+	assert.False(t, IsValidState(""))
+	assert.False(t, IsValidState("XX"))
+}
+
+func TestOrganisationState(t *testing.T) {
+	defer SetOrganisationState("")
+
+	assert.Equal(t, "", OrganisationState())
+	SetOrganisationState(StateHE)
+	assert.Equal(t, StateHE, OrganisationState())
+}
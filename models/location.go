@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Location repräsentiert einen Standort (Betriebsstätte) mit Anschrift, Zeitzone und optionalen Geokoordinaten
+type Location struct {
+	Base
+	Name       string   `gorm:"not null;uniqueIndex:idx_locations_tenant_name,expression:tenant_id\\,name" json:"name"`
+	Street     string   `json:"street"`
+	PostalCode string   `json:"postal_code"`
+	City       string   `json:"city"`
+	Country    string   `gorm:"default:'DE'" json:"country"` // ISO 3166-1 alpha-2
+	TimeZone   string   `json:"time_zone,omitempty"`         // Optionale IANA-Zeitzone, leer = Zeitzone der Organisation
+	Latitude   *float64 `json:"latitude"`
+	Longitude  *float64 `json:"longitude"`
+	IsActive   bool     `gorm:"default:true" json:"is_active"`
+	SortOrder  int      `gorm:"default:0" json:"sort_order"` // Sortierreihenfolge
+}
+
+// TimeLocation liefert die Zeitzone des Standorts oder die Zeitzone der Organisation
+func (l Location) TimeLocation() *time.Location {
+	return locationOrDefault(l.TimeZone)
+}
+
+// LocationStaffingDay ist die Besetzung eines Standorts an einem Kalendertag (wird nicht gespeichert)
+type LocationStaffingDay struct {
+	Date         time.Time      `json:"date"` // Kalendertag in der Zeitzone des Standorts, 00:00 Uhr UTC
+	Shifts       int            `json:"shifts"`
+	Users        int            `json:"users"` // Anzahl verschiedener eingeplanter Benutzer
+	PlannedHours float64        `json:"planned_hours"`
+	ByShiftType  map[string]int `json:"by_shift_type,omitempty"` // Schichten je Schichttyp (Name)
+}
+
+// LocationReport fasst die Schichten eines Standorts in einem Zeitraum zusammen (wird nicht gespeichert)
+type LocationReport struct {
+	LocationID   uint                  `json:"location_id"` // 0 = Schichten ohne Standort
+	LocationName string                `json:"location_name"`
+	Shifts       int                   `json:"shifts"`
+	Users        int                   `json:"users"`
+	PlannedHours float64               `json:"planned_hours"`
+	Days         []LocationStaffingDay `json:"days,omitempty"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OnCallDuty repräsentiert einen Rufbereitschaftsdienst. Die Bereitschaftszeit ist keine Arbeitszeit und wird mit
+// einer Pauschale vergütet; nur Einsätze (Zeitbuchungen mit Herkunft call_out) zählen als Arbeitszeit.
+type OnCallDuty struct {
+	Base
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	TeamID    *uint     `gorm:"index" json:"team_id"` // Optional: Team, für das die Rufbereitschaft gilt
+	StartTime time.Time `gorm:"not null;index" json:"start_time"`
+	EndTime   time.Time `gorm:"not null;index" json:"end_time"`
+	Allowance float64   `json:"allowance"` // Pauschale in Euro
+	Note      string    `json:"note"`
+
+	CallOuts []TimeEntry `gorm:"foreignKey:OnCallDutyID" json:"call_outs,omitempty"`
+
+	// Hinweise zu Ruhezeiten nach Einsätzen (werden nicht gespeichert)
+	Warnings []ComplianceViolation `gorm:"-" json:"warnings,omitempty"`
+}
+
+// IsActiveAt prüft, ob die Rufbereitschaft zum Zeitpunkt t läuft
+func (d OnCallDuty) IsActiveAt(t time.Time) bool {
+	return !t.Before(d.StartTime) && t.Before(d.EndTime)
+}
+
+// Covers prüft, ob der Zeitraum [start, end] innerhalb der Rufbereitschaft liegt
+func (d OnCallDuty) Covers(start, end time.Time) bool {
+	return !start.Before(d.StartTime) && !end.After(d.EndTime)
+}
+
+// CallOutDuration liefert die Arbeitszeit aller Einsätze (laufende bis now)
+func (d OnCallDuty) CallOutDuration(now time.Time) time.Duration {
+	var total time.Duration
+	for _, callOut := range d.CallOuts {
+		total += callOut.WorkedDuration(now)
+	}
+	return total
+}
+
+// MarshalJSON ergänzt die Rufbereitschaft um Bereitschafts- und Einsatzzeit in Minuten
+func (d OnCallDuty) MarshalJSON() ([]byte, error) {
+	type onCallDutyJSON OnCallDuty
+	return json.Marshal(struct {
+		onCallDutyJSON
+		StandbyMinutes int `json:"standby_minutes"`
+		CallOutMinutes int `json:"call_out_minutes"`
+		CallOutCount   int `json:"call_out_count"`
+	}{
+		onCallDutyJSON: onCallDutyJSON(d),
+		StandbyMinutes: int(d.EndTime.Sub(d.StartTime) / time.Minute),
+		CallOutMinutes: int(d.CallOutDuration(time.Now()) / time.Minute),
+		CallOutCount:   len(d.CallOuts),
+	})
+}
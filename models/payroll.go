@@ -0,0 +1,88 @@
+package models
+
+// Kategorien der Lohnarten-Zuordnung
+const (
+	PayrollCategoryRegular   = "regular"   // Geleistete Stunden ohne Überstunden
+	PayrollCategoryOvertime  = "overtime"  // Positiver Monatssaldo des Arbeitszeitkontos
+	PayrollCategorySurcharge = "surcharge" // Zuschlagspflichtige Stunden je Zuschlagsregel (Key = Name der Regel)
+	PayrollCategoryAbsence   = "absence"   // Abwesenheiten je Abwesenheitsart (Key = Abwesenheitsart)
+)
+
+// PayrollCategories enthält alle Kategorien der Lohnarten-Zuordnung
+var PayrollCategories = []string{PayrollCategoryRegular, PayrollCategoryOvertime, PayrollCategorySurcharge, PayrollCategoryAbsence}
+
+// Einheiten der übergebenen Werte
+const (
+	PayrollUnitHours = "hours" // Stunden (DATEV Bearbeitungsschlüssel 1)
+	PayrollUnitDays  = "days"  // Tage (DATEV Bearbeitungsschlüssel 2)
+)
+
+// WageTypeMapping ordnet eine Kategorie (und bei Zuschlägen und Abwesenheiten einen Schlüssel) einer DATEV-Lohnart zu
+type WageTypeMapping struct {
+	Base
+	Category    string `gorm:"not null;uniqueIndex:idx_wage_type_mappings_tenant_category_key,expression:tenant_id\\,category\\,key" json:"category"`
+	Key         string `gorm:"not null;default:''" json:"key"` // Name der Zuschlagsregel bzw. Abwesenheitsart, sonst leer
+	WageType    string `gorm:"not null" json:"wage_type"`      // Lohnartennummer in LODAS, z.B. "100"
+	Unit        string `gorm:"default:'hours'" json:"unit"`
+	Description string `json:"description"`
+}
+
+// PayrollSettings enthält die DATEV-Stammdaten der Organisation
+type PayrollSettings struct {
+	Base
+	ConsultantNumber string `json:"consultant_number"` // Beraternummer, 4 bis 7 Ziffern
+	ClientNumber     string `json:"client_number"`     // Mandantennummer, 1 bis 5 Ziffern
+}
+
+// PayrollExport protokolliert einen exportierten Abrechnungsmonat
+type PayrollExport struct {
+	Base
+	Month        string `gorm:"not null;index" json:"month"` // YYYY-MM
+	FileName     string `json:"file_name"`
+	Content      string `json:"-"` // Erzeugte Importdatei, erneut abrufbar
+	Lines        int    `json:"lines"`
+	Users        int    `json:"users"`
+	ExportedByID *uint  `json:"exported_by_id"`
+}
+
+// PayrollLine ist ein zu übergebender Wert eines Benutzers (wird nicht gespeichert)
+type PayrollLine struct {
+	UserID          uint    `json:"user_id"`
+	UserName        string  `json:"user_name"`
+	PersonnelNumber string  `json:"personnel_number"` // AccountNumber des Benutzers
+	Category        string  `json:"category"`
+	Key             string  `json:"key,omitempty"`
+	WageType        string  `json:"wage_type"` // Leer, solange keine Zuordnung besteht
+	Unit            string  `json:"unit"`
+	Value           float64 `json:"value"`
+	Hours           float64 `json:"-"`
+	Days            float64 `json:"-"`
+}
+
+// PayrollIssue ist ein Hinweis der Exportprüfung (wird nicht gespeichert). Blockierende Hinweise verhindern den Export.
+type PayrollIssue struct {
+	UserID   *uint  `json:"user_id,omitempty"`
+	Category string `json:"category,omitempty"`
+	Key      string `json:"key,omitempty"`
+	Message  string `json:"message"`
+	Blocking bool   `json:"blocking"`
+}
+
+// PayrollPreview ist die Vorschau eines Abrechnungsmonats (wird nicht gespeichert)
+type PayrollPreview struct {
+	Month   string          `json:"month"` // YYYY-MM
+	Lines   []PayrollLine   `json:"lines"`
+	Issues  []PayrollIssue  `json:"issues"`
+	Exports []PayrollExport `json:"exports"` // Bisherige Exporte des Monats
+	File    string          `json:"file,omitempty"`
+}
+
+// CanExport prüft, ob die Vorschau keine blockierenden Hinweise enthält
+func (p PayrollPreview) CanExport() bool {
+	for _, issue := range p.Issues {
+		if issue.Blocking {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Durchsetzung fehlender Qualifikationen beim Speichern einer Schicht
+const (
+	QualificationEnforcementWarn  = "warn"  // Schicht wird gespeichert, fehlende Qualifikation als Hinweis gemeldet
+	QualificationEnforcementBlock = "block" // Schicht wird abgelehnt
+)
+
+// Qualification ist ein Eintrag im Qualifikationskatalog (z.B. Ersthelfer, Staplerschein, Schlüsselberechtigung)
+type Qualification struct {
+	Base
+	Name           string `gorm:"not null;uniqueIndex:idx_qualifications_tenant_name,expression:tenant_id\\,name" json:"name"`
+	Description    string `json:"description"`
+	Enforcement    string `gorm:"default:'warn'" json:"enforcement"` // warn oder block
+	ValidityMonths int    `gorm:"default:0" json:"validity_months"`  // Standardgültigkeit neuer Nachweise, 0 = unbegrenzt
+	IsActive       bool   `gorm:"default:true" json:"is_active"`     // Inaktive Qualifikationen werden nicht mehr geprüft
+}
+
+// UserQualification ist der Nachweis einer Qualifikation durch einen Benutzer
+type UserQualification struct {
+	Base
+	UserID          uint          `gorm:"not null;index" json:"user_id"`
+	User            User          `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	QualificationID uint          `gorm:"not null;index" json:"qualification_id"`
+	Qualification   Qualification `gorm:"foreignKey:QualificationID" json:"qualification,omitempty"`
+	AcquiredOn      *time.Time    `json:"acquired_on"`             // Erster gültiger Kalendertag, 00:00 Uhr UTC
+	ExpiresOn       *time.Time    `gorm:"index" json:"expires_on"` // Letzter gültiger Kalendertag (inklusive), leer = unbefristet
+	Note            string        `json:"note"`
+}
+
+// ValidOn prüft, ob der Nachweis am Kalendertag day (00:00 Uhr UTC) gültig ist
+func (q UserQualification) ValidOn(day time.Time) bool {
+	if q.AcquiredOn != nil && day.Before(*q.AcquiredOn) {
+		return false
+	}
+	return q.ExpiresOn == nil || !day.After(*q.ExpiresOn)
+}
+
+// QualificationExpiry ist ein Eintrag im Bericht über ablaufende Qualifikationen (wird nicht gespeichert)
+type QualificationExpiry struct {
+	UserQualificationID uint      `json:"user_qualification_id"`
+	UserID              uint      `json:"user_id"`
+	UserName            string    `json:"user_name"`
+	QualificationID     uint      `json:"qualification_id"`
+	QualificationName   string    `json:"qualification_name"`
+	ExpiresOn           time.Time `json:"expires_on"`
+	DaysLeft            int       `json:"days_left"`
+}
+
+// UnderQualifiedShift ist eine Schicht, deren Benutzer geforderte Qualifikationen fehlen (wird nicht gespeichert)
+type UnderQualifiedShift struct {
+	Shift      Shift                 `json:"shift"`
+	Violations []ComplianceViolation `json:"violations"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// RecurringShift repräsentiert eine wiederkehrende Schicht, deren Termine durch eine
+// iCalendar-RRULE (RFC 5545) beschrieben werden, z.B. "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU"
+type RecurringShift struct {
+	Base
+	UserID      uint       `gorm:"not null" json:"user_id"`
+	User        User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ShiftTypeID uint       `gorm:"not null" json:"shift_type_id"`
+	ShiftType   ShiftType  `gorm:"foreignKey:ShiftTypeID" json:"shift_type,omitempty"`
+	ScheduleID  uint       `gorm:"not null" json:"schedule_id"`
+	Schedule    Schedule   `gorm:"foreignKey:ScheduleID" json:"schedule,omitempty"`
+	RRule       string     `gorm:"not null" json:"rrule"`
+	StartTime   time.Time  `gorm:"not null" json:"start_time"`  // Beginn des ersten Vorkommens (DTSTART)
+	EndTime     time.Time  `gorm:"not null" json:"end_time"`    // Ende des ersten Vorkommens, bestimmt die Dauer
+	Until       *time.Time `json:"until"`                       // Optionales Enddatum der Serie
+	Count       int        `gorm:"default:0" json:"count"`      // Optionale Anzahl an Vorkommen (0 = unbegrenzt)
+	BreakTime   int        `gorm:"default:0" json:"break_time"` // in Minuten
+	Description string     `json:"description"`
+	IsActive    bool       `gorm:"default:true" json:"is_active"`
+	TimeZone    string     `json:"time_zone,omitempty"` // IANA-Zeitzone der Uhrzeit (TZID), leer = Zeitzone der Organisation
+
+	// Beziehungen
+	Exceptions []RecurringShiftException `gorm:"foreignKey:RecurringShiftID" json:"exceptions,omitempty"`
+	Shifts     []Shift                   `gorm:"foreignKey:RecurringShiftID" json:"shifts,omitempty"`
+}
+
+// RecurringShiftException repräsentiert ein ausgeschlossenes Vorkommen einer Serie (EXDATE)
+type RecurringShiftException struct {
+	Base
+	RecurringShiftID uint      `gorm:"not null;index" json:"recurring_shift_id"`
+	Date             time.Time `gorm:"not null" json:"date"` // Ursprüngliche Startzeit des Vorkommens
+}
+
+// Location liefert die Zeitzone, in der die Uhrzeit der Vorkommen konstant bleibt
+func (r RecurringShift) Location() *time.Location {
+	return locationOrDefault(r.TimeZone)
+}
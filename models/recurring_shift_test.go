@@ -0,0 +1,51 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecurringShift_CreateWithExceptions(t *testing.T) {
+	db := setupTestDB(t)
+
+	user := User{Username: "serie", Email: "serie@example.com", Password: "hashedpassword", Name: "Serien User"}
+	db.Create(&user)
+
+	shiftType := ShiftType{Name: "Frühschicht"}
+	db.Create(&shiftType)
+
+	schedule := Schedule{
+		Name:      "Januar 2024",
+		StartDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC),
+	}
+	db.Create(&schedule)
+
+	recurringShift := RecurringShift{
+		UserID:      user.ID,
+		ShiftTypeID: shiftType.ID,
+		ScheduleID:  schedule.ID,
+		RRule:       "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU",
+		StartTime:   time.Date(2024, 1, 2, 6, 0, 0, 0, time.UTC),
+		EndTime:     time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC),
+		Exceptions: []RecurringShiftException{
+			{Date: time.Date(2024, 1, 16, 6, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	result := db.Create(&recurringShift)
+	assert.NoError(t, result.Error)
+	assert.NotZero(t, recurringShift.ID)
+
+	var loaded RecurringShift
+	result = db.Preload("Exceptions").Preload("ShiftType").First(&loaded, recurringShift.ID)
+	assert.NoError(t, result.Error)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU", loaded.RRule)
+	assert.Equal(t, "Frühschicht", loaded.ShiftType.Name)
+	assert.Len(t, loaded.Exceptions, 1)
+	assert.Nil(t, loaded.Until)
+	assert.Equal(t, 0, loaded.Count)
+	assert.True(t, loaded.IsActive, "IsActive sollte standardmäßig true sein")
+}
@@ -12,5 +12,10 @@ type Schedule struct {
 	StartDate   time.Time `gorm:"not null" json:"start_date"`
 	EndDate     time.Time `gorm:"not null" json:"end_date"`
 	IsActive    bool      `gorm:"default:true" json:"is_active"`
+	LocationID  *uint     `gorm:"index" json:"location_id"` // Optionaler Standort, gilt für Schichten ohne eigenen Standort
+	Location    *Location `gorm:"foreignKey:LocationID" json:"location,omitempty"`
 	Shifts      []Shift   `gorm:"foreignKey:ScheduleID" json:"shifts,omitempty"`
+
+	// Feiertage im Zeitraum des Plans (werden nicht gespeichert)
+	Holidays []Holiday `gorm:"-" json:"holidays,omitempty"`
 }
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Layouts des gedruckten Dienstplans
+const (
+	ScheduleGridLayoutWeek  = "week"  // Eine Kalenderwoche je Seite
+	ScheduleGridLayoutMonth = "month" // Ein Kalendermonat je Seite
+)
+
+// ScheduleGrid ist ein Schichtplan als Raster Benutzer × Tage, gruppiert nach Teams (wird nicht gespeichert)
+type ScheduleGrid struct {
+	ScheduleID   uint                `json:"schedule_id"`
+	ScheduleName string              `json:"schedule_name"`
+	Days         []time.Time         `json:"days"` // Kalendertage des Plans (00:00 Uhr UTC)
+	Groups       []ScheduleGridGroup `json:"groups"`
+	ShiftTypes   []ShiftType         `json:"shift_types"` // Legende in Sortierreihenfolge
+	Headcounts   []int               `json:"headcounts"`  // Besetzung je Tag (Benutzer mit mindestens einer Schicht)
+
+	// Besetzung je Tag und Schichttyp (ID des Schichttyps, 0 = ohne Schichttyp)
+	ShiftTypeHeadcounts map[uint][]int `json:"shift_type_headcounts"`
+}
+
+// ScheduleGridGroup ist ein Team im Planraster; TeamID 0 steht für Benutzer ohne Team
+type ScheduleGridGroup struct {
+	TeamID uint              `json:"team_id"`
+	Name   string            `json:"name"`
+	Color  string            `json:"color,omitempty"`
+	Rows   []ScheduleGridRow `json:"rows"`
+}
+
+// ScheduleGridRow ist die Zeile eines Benutzers mit einer Zelle je Tag und den Summen
+type ScheduleGridRow struct {
+	UserID        uint               `json:"user_id"`
+	Name          string             `json:"name"`
+	AccountNumber string             `json:"account_number,omitempty"`
+	Cells         []ScheduleGridCell `json:"cells"`
+	Shifts        int                `json:"shifts"`  // Anzahl der Schichten
+	Minutes       int                `json:"minutes"` // Geplante Arbeitszeit ohne Pausen
+}
+
+// ScheduleGridCell ist ein Tag eines Benutzers: Kürzel der Schichten und Abwesenheiten
+type ScheduleGridCell struct {
+	Codes   []string `json:"codes,omitempty"`
+	Color   string   `json:"color,omitempty"`   // Farbe des ersten Schichttyps
+	Minutes int      `json:"minutes,omitempty"` // Geplante Arbeitszeit des Tages ohne Pausen
+}
+
+// Text liefert die Kürzel der Zelle, z.B. F oder F/S
+func (c ScheduleGridCell) Text() string {
+	return strings.Join(c.Codes, "/")
+}
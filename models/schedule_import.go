@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// ScheduleImportCode ordnet ein Kürzel des Planrasters einem Schichttyp oder einer Abwesenheitsart zu
+// (wird nicht gespeichert)
+type ScheduleImportCode struct {
+	Code        string   `json:"code"`
+	ShiftTypeID uint     `json:"shift_type_id,omitempty"`
+	ShiftType   string   `json:"shift_type,omitempty"` // Name des Schichttyps
+	AbsenceType string   `json:"absence_type,omitempty"`
+	Ignore      bool     `json:"ignore,omitempty"`    // Kürzel für freie Tage, es wird nichts angelegt
+	Ambiguous   []string `json:"ambiguous,omitempty"` // Schichttypen, die ohne Zuordnung dasselbe Kürzel haben
+}
+
+// ScheduleImportColumn ist eine Tagesspalte der importierten Tabelle
+type ScheduleImportColumn struct {
+	Column string    `json:"column"` // Spaltenname wie in Excel, z.B. D
+	Header string    `json:"header"` // Überschrift der Spalte
+	Date   time.Time `json:"date"`   // Kalendertag (00:00 Uhr UTC)
+}
+
+// ScheduleImportEntry ist eine Schicht oder ein Abwesenheitstag aus einer Zelle
+type ScheduleImportEntry struct {
+	Date        time.Time  `json:"date"`
+	Code        string     `json:"code"`
+	ShiftTypeID uint       `json:"shift_type_id,omitempty"`
+	StartTime   *time.Time `json:"start_time,omitempty"`
+	EndTime     *time.Time `json:"end_time,omitempty"`
+	BreakTime   int        `json:"break_time,omitempty"`
+	AbsenceType string     `json:"absence_type,omitempty"`
+	Skipped     bool       `json:"skipped,omitempty"` // Bereits vorhanden, wird nicht erneut angelegt
+}
+
+// ScheduleImportRow ist die Zeile eines Benutzers im Import mit ihren Prüfergebnissen (wird nicht gespeichert)
+type ScheduleImportRow struct {
+	Line     int                   `json:"line"`  // Zeile in der Tabelle (erste Zeile = 1)
+	Label    string                `json:"label"` // Beschriftung der Zeile (Name oder Personalnummer)
+	UserID   uint                  `json:"user_id,omitempty"`
+	UserName string                `json:"user_name,omitempty"`
+	Entries  []ScheduleImportEntry `json:"entries"`
+	Errors   []string              `json:"errors,omitempty"`
+	Warnings []string              `json:"warnings,omitempty"`
+}
+
+// ScheduleImportReport ist das Ergebnis eines Probelaufs oder Imports eines Planrasters (wird nicht gespeichert)
+type ScheduleImportReport struct {
+	DryRun     bool                   `json:"dry_run"`
+	ScheduleID uint                   `json:"schedule_id"`
+	HeaderLine int                    `json:"header_line"` // Zeile mit den Tagesüberschriften
+	Columns    []ScheduleImportColumn `json:"columns"`
+	Codes      []ScheduleImportCode   `json:"codes"` // Verwendete Kürzeltabelle
+	Total      int                    `json:"total"`
+	Valid      int                    `json:"valid"`
+	Invalid    int                    `json:"invalid"`
+	Shifts     int                    `json:"shifts"`   // Anzulegende Schichten
+	Absences   int                    `json:"absences"` // Anzulegende Abwesenheiten (zusammenhängende Tage)
+	Rows       []ScheduleImportRow    `json:"rows"`
+	Warnings   []string               `json:"warnings,omitempty"`
+	Error      string                 `json:"error,omitempty"` // Grund, warum nicht importiert wurde
+
+	// Nur nach dem Import gesetzt
+	ShiftsCreated   int `json:"shifts_created"`
+	AbsencesCreated int `json:"absences_created"`
+}
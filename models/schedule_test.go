@@ -14,7 +14,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	assert.NoError(t, err)
 
 	// Migration durchführen
-	err = db.AutoMigrate(&User{}, &Shift{}, &Schedule{}, &Team{}, &ShiftType{}, &ShiftTemplate{})
+	err = db.AutoMigrate(&User{}, &Shift{}, &Schedule{}, &Team{}, &ShiftType{}, &ShiftTemplate{}, &RecurringShift{}, &RecurringShiftException{}, &TeamRule{}, &CompanyHoliday{}, &SurchargeRule{}, &Absence{}, &AbsenceCreditRule{}, &TimeAccountCorrection{}, &EmploymentContract{}, &Qualification{}, &UserQualification{}, &Location{}, &Tenant{}, &APIToken{}, &TimeEntry{}, &TimeEntryBreak{}, &TimeEntryCorrection{}, &Timesheet{}, &TimesheetEvent{}, &WageTypeMapping{}, &PayrollSettings{}, &PayrollExport{}, &OnCallDuty{}, &ChecklistTemplate{}, &ShiftChecklist{}, &ShiftChecklistItem{}, &Comment{}, &CommentMention{}, &CommentRevision{}, &CalendarFeed{}, &CalendarSyncState{}, &UserInvitation{})
 	assert.NoError(t, err)
 
 	return db
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -18,4 +19,45 @@ type Shift struct {
 	IsActive    bool      `gorm:"default:true" json:"is_active"`
 	ScheduleID  uint      `gorm:"not null" json:"schedule_id"`
 	Schedule    Schedule  `gorm:"foreignKey:ScheduleID" json:"schedule,omitempty"`
+	LocationID  *uint     `gorm:"index" json:"location_id"` // Optionaler Standort, leer = Standort des Schichtplans
+
+	// Qualifikationen, die zusätzlich zu denen des Schichttyps erforderlich sind
+	RequiredQualificationIDs []uint `gorm:"serializer:json" json:"required_qualification_ids"`
+
+	// Herkunft aus einer wiederkehrenden Schicht
+	RecurringShiftID   *uint      `gorm:"index" json:"recurring_shift_id,omitempty"`
+	RecurrenceID       *time.Time `json:"recurrence_id,omitempty"`                  // Ursprüngliche Startzeit des Vorkommens (RECURRENCE-ID)
+	RecurrenceModified bool       `gorm:"default:false" json:"recurrence_modified"` // Einzeln bearbeitet, wird bei Neuberechnung nicht überschrieben
+
+	// Hinweise der Regelprüfung beim Speichern (werden nicht gespeichert)
+	Warnings []ComplianceViolation `gorm:"-" json:"warnings,omitempty"`
+
+	// Feiertag, den die Schicht berührt (wird nicht gespeichert)
+	Holiday *Holiday `gorm:"-" json:"holiday,omitempty"`
+}
+
+// NetDuration liefert die Arbeitszeit der Schicht abzüglich der Pause
+func (s Shift) NetDuration() time.Duration {
+	return s.EndTime.Sub(s.StartTime) - time.Duration(s.BreakTime)*time.Minute
+}
+
+// Location liefert die Zeitzone, in der die Schicht dargestellt wird: die des Benutzers, sofern geladen,
+// sonst die der Organisation
+func (s Shift) Location() *time.Location {
+	if s.User.ID != 0 {
+		return s.User.Location()
+	}
+	return OrganisationLocation()
+}
+
+// MarshalJSON ergänzt die Schicht um Orts- und UTC-Darstellung von Beginn und Ende
+func (s Shift) MarshalJSON() ([]byte, error) {
+	type shiftJSON Shift
+	return json.Marshal(struct {
+		shiftJSON
+		LocalTimes
+	}{
+		shiftJSON:  shiftJSON(s),
+		LocalTimes: NewLocalTimes(s.StartTime, s.EndTime, s.Location()),
+	})
 }
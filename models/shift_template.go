@@ -3,7 +3,7 @@ package models
 // ShiftTemplate repräsentiert eine Schichtvorlage mit 7 Tagen
 type ShiftTemplate struct {
 	Base
-	Name        string `gorm:"not null;unique" json:"name"`
+	Name        string `gorm:"not null;uniqueIndex:idx_shift_templates_tenant_name,expression:tenant_id\\,name" json:"name"`
 	Description string `json:"description"`
 	Color       string `gorm:"default:'#6B7280'" json:"color"` // Hex-Farbe für UI
 	IsActive    bool   `gorm:"default:true" json:"is_active"`
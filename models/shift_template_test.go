@@ -2,7 +2,6 @@ package models
 
 import (
 	"testing"
-	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -80,8 +79,8 @@ func TestShiftTemplateRelationships(t *testing.T) {
 		Name:         "Frühschicht",
 		Description:  "Schicht von 6:00 bis 14:00 Uhr",
 		Color:        "#3B82F6",
-		DefaultStart: time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC),
-		DefaultEnd:   time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC),
+		DefaultStart: NewWallClock(6, 0),
+		DefaultEnd:   NewWallClock(14, 0),
 		DefaultBreak: 30,
 		IsActive:     true,
 		SortOrder:    1,
@@ -92,8 +91,8 @@ func TestShiftTemplateRelationships(t *testing.T) {
 		Name:         "Spätschicht",
 		Description:  "Schicht von 14:00 bis 22:00 Uhr",
 		Color:        "#EF4444",
-		DefaultStart: time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC),
-		DefaultEnd:   time.Date(2024, 1, 1, 22, 0, 0, 0, time.UTC),
+		DefaultStart: NewWallClock(14, 0),
+		DefaultEnd:   NewWallClock(22, 0),
 		DefaultBreak: 30,
 		IsActive:     true,
 		SortOrder:    2,
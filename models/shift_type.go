@@ -1,20 +1,59 @@
 package models
 
 import (
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
+// MaxShiftTypeShortCodeLength ist die maximale Länge des Kürzels eines Schichttyps in Zeichen
+const MaxShiftTypeShortCodeLength = 4
+
 // ShiftType repräsentiert einen Schichttyp (z.B. Frühschicht, Spätschicht, Nachtschicht)
 type ShiftType struct {
 	Base
-	Name         string    `gorm:"not null;unique" json:"name"`
+	Name         string    `gorm:"not null;uniqueIndex:idx_shift_types_tenant_name,expression:tenant_id\\,name" json:"name"`
 	Description  string    `json:"description"`
+	ShortCode    string    `json:"short_code"`                      // Kürzel im Planraster, z.B. F, S oder N
 	Color        string    `gorm:"default:'#3B82F6'" json:"color"`  // Hex-Farbe für UI
-	DefaultStart time.Time `json:"default_start"`                   // Standard-Startzeit
-	DefaultEnd   time.Time `json:"default_end"`                     // Standard-Endzeit
+	DefaultStart WallClock `json:"default_start"`                   // Standard-Startzeit als Uhrzeit (z.B. 06:00)
+	DefaultEnd   WallClock `json:"default_end"`                     // Standard-Endzeit; vor dem Beginn = endet am Folgetag
 	DefaultBreak int       `gorm:"default:30" json:"default_break"` // Standard-Pausenzeit in Minuten
 	IsActive     bool      `gorm:"default:true" json:"is_active"`
 	SortOrder    int       `gorm:"default:0" json:"sort_order"`   // Sortierreihenfolge
 	MinDuration  int       `gorm:"default:0" json:"min_duration"` // Mindestdauer in Minuten
 	MaxDuration  int       `gorm:"default:0" json:"max_duration"` // Maximaldauer in Minuten
+
+	// Qualifikationen, die jede Schicht dieses Typs erfordert
+	RequiredQualificationIDs []uint `gorm:"serializer:json" json:"required_qualification_ids"`
+
+	// Checklisten-Vorlagen, die für jede Schicht dieses Typs angelegt werden
+	ChecklistTemplateIDs []uint `gorm:"serializer:json" json:"checklist_template_ids"`
+}
+
+// Code liefert das Kürzel des Schichttyps; ohne Kürzel den ersten Buchstaben des Namens
+func (st ShiftType) Code() string {
+	if st.ShortCode != "" {
+		return st.ShortCode
+	}
+	first, _ := utf8.DecodeRuneInString(st.Name)
+	if first == utf8.RuneError {
+		return "?"
+	}
+	return strings.ToUpper(string(first))
+}
+
+// ShiftTimesOn liefert Beginn und Ende der Standardzeiten am Kalendertag von day in der Zeitzone loc.
+// Liegt das Ende nicht nach dem Beginn, endet die Schicht am Folgetag. Die Dauer berücksichtigt Zeitumstellungen.
+func (st ShiftType) ShiftTimesOn(day time.Time, loc *time.Location) (time.Time, time.Time, bool) {
+	if !st.DefaultStart.Valid || !st.DefaultEnd.Valid {
+		return time.Time{}, time.Time{}, false
+	}
+
+	start := st.DefaultStart.On(day, loc)
+	end := st.DefaultEnd.On(day, loc)
+	if !end.After(start) {
+		end = st.DefaultEnd.On(start.AddDate(0, 0, 1), loc)
+	}
+	return start, end, true
 }
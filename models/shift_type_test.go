@@ -13,8 +13,8 @@ func TestShiftTypeValidation(t *testing.T) {
 		Name:         "Frühschicht",
 		Description:  "Schicht von 6:00 bis 14:00 Uhr",
 		Color:        "#3B82F6",
-		DefaultStart: time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC),
-		DefaultEnd:   time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC),
+		DefaultStart: NewWallClock(6, 0),
+		DefaultEnd:   NewWallClock(14, 0),
 		DefaultBreak: 30,
 		IsActive:     true,
 		SortOrder:    1,
@@ -52,14 +52,35 @@ func TestShiftTypeDefaultValues(t *testing.T) {
 }
 
 func TestShiftTypeTimeValidation(t *testing.T) {
-	// Test: Startzeit nach Endzeit
+	// Test: Endzeit vor Startzeit bedeutet Schichtende am Folgetag
 	shiftType := ShiftType{
-		DefaultStart: time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC),
-		DefaultEnd:   time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC),
+		DefaultStart: NewWallClock(22, 0),
+		DefaultEnd:   NewWallClock(6, 0),
 	}
 
-	// Die Validierung erfolgt im Handler, nicht im Model
-	assert.True(t, shiftType.DefaultStart.After(shiftType.DefaultEnd))
+	start, end, ok := shiftType.ShiftTimesOn(time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC), time.UTC)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2024, 1, 8, 22, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2024, 1, 9, 6, 0, 0, 0, time.UTC), end)
+}
+
+func TestShiftTypeShiftTimesOn_DST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	night := ShiftType{DefaultStart: NewWallClock(22, 0), DefaultEnd: NewWallClock(6, 0)}
+
+	// Umstellung auf Sommerzeit: die Nacht ist eine Stunde kürzer
+	start, end, _ := night.ShiftTimesOn(time.Date(2024, 3, 30, 12, 0, 0, 0, berlin), berlin)
+	assert.Equal(t, 7*time.Hour, end.Sub(start))
+
+	// Umstellung auf Winterzeit: die Nacht ist eine Stunde länger
+	start, end, _ = night.ShiftTimesOn(time.Date(2024, 10, 26, 12, 0, 0, 0, berlin), berlin)
+	assert.Equal(t, 9*time.Hour, end.Sub(start))
+
+	// Ohne Standardzeiten gibt es keine Schichtzeiten
+	_, _, ok := ShiftType{}.ShiftTimesOn(start, berlin)
+	assert.False(t, ok)
 }
 
 func TestShiftTypeDurationValidation(t *testing.T) {
@@ -72,3 +93,10 @@ func TestShiftTypeDurationValidation(t *testing.T) {
 	// Die Validierung erfolgt im Handler, nicht im Model
 	assert.True(t, shiftType.MinDuration > shiftType.MaxDuration)
 }
+
+func TestShiftTypeCode(t *testing.T) {
+	assert.Equal(t, "F", ShiftType{Name: "Frühschicht", ShortCode: "F"}.Code())
+	assert.Equal(t, "Ü", ShiftType{Name: "übergabe"}.Code())
+	assert.Equal(t, "?", ShiftType{}.Code())
+	assert.Equal(t, "FZA", AbsenceTypeCode(AbsenceTypeCompensatory))
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// Arten von Zuschlägen
+const (
+	SurchargeTypeNight      = "night"       // Arbeit im Zeitfenster, z.B. 20:00 bis 06:00
+	SurchargeTypeSunday     = "sunday"      // Arbeit an Sonntagen
+	SurchargeTypeHoliday    = "holiday"     // Arbeit an gesetzlichen und betrieblichen Feiertagen
+	SurchargeTypeSpecialDay = "special_day" // Arbeit an einem festen Kalendertag, z.B. 24.12.
+)
+
+// SurchargeRule ist eine prozentuale Zuschlagsregel.
+// Nachtzuschläge werden zu Tageszuschlägen (Sonntag, Feiertag, Sondertag) addiert;
+// innerhalb beider Gruppen gilt je Minute nur der höchste Satz.
+type SurchargeRule struct {
+	Base
+	Name       string  `gorm:"not null" json:"name"`
+	Type       string  `gorm:"not null" json:"type"`
+	Percentage float64 `gorm:"not null" json:"percentage"`
+	// Zeitfenster in Ortszeit; über Mitternacht, wenn Ende <= Beginn. Leer = ganzer Tag
+	WindowStart WallClock `json:"window_start"`
+	WindowEnd   WallClock `json:"window_end"`
+	// Kalendertag für Sondertage
+	Month int `json:"month,omitempty"`
+	Day   int `json:"day,omitempty"`
+	// Nur wenn die Schicht vor Mitternacht des Kalendertags begonnen hat (§3b Abs. 3 Nr. 1 EStG)
+	StartedBeforeMidnight bool `gorm:"default:false" json:"started_before_midnight"`
+	IsActive              bool `gorm:"default:true" json:"is_active"`
+}
+
+// IsNight gibt an, ob die Regel zur Gruppe der Nachtzuschläge gehört
+func (r SurchargeRule) IsNight() bool {
+	return r.Type == SurchargeTypeNight
+}
+
+// SurchargeLine ist die zuschlagspflichtige Zeit einer Regel
+type SurchargeLine struct {
+	RuleID     uint    `json:"rule_id,omitempty"`
+	Name       string  `json:"name"`
+	Type       string  `json:"type"`
+	Percentage float64 `json:"percentage"`
+	Minutes    float64 `json:"minutes"` // Nettominuten nach Abzug der Pause
+	Hours      float64 `json:"hours"`
+}
+
+// ShiftSurcharge ist das Zuschlagsergebnis einer Schicht (wird nicht gespeichert)
+type ShiftSurcharge struct {
+	ShiftID       uint            `json:"shift_id"`
+	UserID        uint            `json:"user_id"`
+	StartTime     time.Time       `json:"start_time"`
+	EndTime       time.Time       `json:"end_time"`
+	WorkedMinutes float64         `json:"worked_minutes"` // Nettoarbeitszeit
+	Lines         []SurchargeLine `json:"lines"`
+}
+
+// UserSurchargeSummary fasst die Zuschläge eines Benutzers in einem Monat zusammen
+type UserSurchargeSummary struct {
+	UserID        uint             `json:"user_id"`
+	UserName      string           `json:"user_name"`
+	Month         string           `json:"month"` // YYYY-MM
+	WorkedMinutes float64          `json:"worked_minutes"`
+	Lines         []SurchargeLine  `json:"lines"` // Je Regel summiert
+	Shifts        []ShiftSurcharge `json:"shifts"`
+}
@@ -3,12 +3,15 @@ package models
 // Team repräsentiert ein Team im System
 type Team struct {
 	Base
-	Name        string `gorm:"not null;unique" json:"name"`
+	Name        string `gorm:"not null;uniqueIndex:idx_teams_tenant_name,expression:tenant_id\\,name" json:"name"`
 	Description string `json:"description"`
 	Color       string `gorm:"default:'#6B7280'" json:"color"` // Hex-Farbe für UI
 	IsActive    bool   `gorm:"default:true" json:"is_active"`
 	SortOrder   int    `gorm:"default:0" json:"sort_order"` // Sortierreihenfolge
+	LocationID  *uint  `gorm:"index" json:"location_id"`    // Optionaler Standort des Teams
 
 	// Beziehungen
-	Users []User `gorm:"foreignKey:TeamID" json:"users,omitempty"`
+	Location *Location  `gorm:"foreignKey:LocationID" json:"location,omitempty"`
+	Users    []User     `gorm:"foreignKey:TeamID" json:"users,omitempty"`
+	Rules    []TeamRule `gorm:"foreignKey:TeamID" json:"rules,omitempty"`
 }
@@ -0,0 +1,44 @@
+package models
+
+// Regeltypen für teamspezifische Planungsregeln
+const (
+	TeamRuleMaxConsecutiveDays   = "max_consecutive_days"   // Höchstens Max Arbeitstage in Folge
+	TeamRuleMaxConsecutiveNights = "max_consecutive_nights" // Höchstens Max Nachtschichten in Folge
+	TeamRuleForbiddenSequence    = "forbidden_sequence"     // Kein Schichttyp To am Tag nach Schichttyp From
+	TeamRuleMinFreeWeekends      = "min_free_weekends"      // Mindestens Min freie Wochenenden je Monat
+)
+
+// Verbindlichkeit einer Teamregel
+const (
+	TeamRuleSeverityHard = "hard" // Verstoß ist ein Fehler
+	TeamRuleSeveritySoft = "soft" // Verstoß ist ein Hinweis
+)
+
+// TeamRuleParameters enthält die Parameter einer Teamregel, welche Felder genutzt werden, hängt vom Typ ab
+type TeamRuleParameters struct {
+	Max             int    `json:"max,omitempty"`
+	Min             int    `json:"min,omitempty"`
+	ShiftTypeIDs    []uint `json:"shift_type_ids,omitempty"` // Nachtschichten; leer = Erkennung über die Uhrzeit
+	FromShiftTypeID uint   `json:"from_shift_type_id,omitempty"`
+	ToShiftTypeID   uint   `json:"to_shift_type_id,omitempty"`
+}
+
+// TeamRule repräsentiert eine Planungsregel, die für alle Mitglieder eines Teams gilt
+type TeamRule struct {
+	Base
+	TeamID      uint               `gorm:"not null;index" json:"team_id"`
+	Name        string             `gorm:"not null" json:"name"`
+	Description string             `json:"description"`
+	Type        string             `gorm:"not null" json:"type"`
+	Severity    string             `gorm:"default:'hard'" json:"severity"`
+	Parameters  TeamRuleParameters `gorm:"serializer:json" json:"parameters"`
+	IsActive    bool               `gorm:"default:true" json:"is_active"`
+}
+
+// ViolationSeverity liefert den Schweregrad, mit dem Verstöße gegen die Regel gemeldet werden
+func (r TeamRule) ViolationSeverity() string {
+	if r.Severity == TeamRuleSeveritySoft {
+		return SeverityWarning
+	}
+	return SeverityError
+}
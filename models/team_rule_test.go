@@ -0,0 +1,32 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTeamRuleViolationSeverity(t *testing.T) {
+	assert.Equal(t, SeverityError, TeamRule{Severity: TeamRuleSeverityHard}.ViolationSeverity())
+	assert.Equal(t, SeverityError, TeamRule{}.ViolationSeverity())
+	assert.Equal(t, SeverityWarning, TeamRule{Severity: TeamRuleSeveritySoft}.ViolationSeverity())
+}
+
+func TestTeamRuleJSONSerialization(t *testing.T) {
+	rule := TeamRule{
+		TeamID:     1,
+		Name:       "Keine Früh nach Nacht",
+		Type:       TeamRuleForbiddenSequence,
+		Parameters: TeamRuleParameters{FromShiftTypeID: 3, ToShiftTypeID: 1},
+	}
+
+	data, err := json.Marshal(rule)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"from_shift_type_id":3`)
+	assert.NotContains(t, string(data), `"max"`)
+
+	var decoded TeamRule
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, rule.Parameters, decoded.Parameters)
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultTenantID ist die Organisation, der bestehende Daten und Anfragen ohne Anmeldung und Subdomain zugeordnet werden
+const DefaultTenantID uint = 1
+
+// Tenant repräsentiert eine Organisation (Mandant). Alle Models mit Base gehören genau einer Organisation an.
+type Tenant struct {
+	ID        uint           `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	Name      string         `gorm:"not null" json:"name"`
+	Slug      string         `gorm:"not null;uniqueIndex" json:"slug"` // Subdomain, z.B. "nord" für nord.example.com
+	IsActive  bool           `gorm:"default:true" json:"is_active"`
+}
+
+var (
+	tenantConfigMu   sync.RWMutex
+	tenantBaseDomain string
+	authRequired     bool
+)
+
+// TenantBaseDomain liefert die Domain, unter deren Subdomains die Organisationen erreichbar sind (leer = keine Auflösung über Subdomains)
+func TenantBaseDomain() string {
+	tenantConfigMu.RLock()
+	defer tenantConfigMu.RUnlock()
+	return tenantBaseDomain
+}
+
+// SetTenantBaseDomain setzt die Domain für die Auflösung der Organisation über Subdomains
+func SetTenantBaseDomain(domain string) {
+	tenantConfigMu.Lock()
+	defer tenantConfigMu.Unlock()
+	tenantBaseDomain = strings.ToLower(strings.Trim(domain, "."))
+}
+
+// AuthRequired gibt an, ob API-Anfragen ein gültiges API-Token erfordern
+func AuthRequired() bool {
+	tenantConfigMu.RLock()
+	defer tenantConfigMu.RUnlock()
+	return authRequired
+}
+
+// SetAuthRequired legt fest, ob API-Anfragen ein gültiges API-Token erfordern
+func SetAuthRequired(required bool) {
+	tenantConfigMu.Lock()
+	defer tenantConfigMu.Unlock()
+	authRequired = required
+}
+
+// TenantSlugFromHost liefert die Subdomain eines Hosts unterhalb von baseDomain, z.B. "nord" für "nord.example.com:3000"
+func TenantSlugFromHost(host, baseDomain string) (string, bool) {
+	if baseDomain == "" {
+		return "", false
+	}
+	host = strings.ToLower(host)
+	if i := strings.LastIndexByte(host, ':'); i != -1 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	slug, ok := strings.CutSuffix(host, "."+baseDomain)
+	if !ok || slug == "" || strings.Contains(slug, ".") {
+		return "", false
+	}
+	return slug, true
+}
+
+// APIToken ist ein persönliches Zugangstoken eines Benutzers; gespeichert wird nur der SHA-256-Hash
+type APIToken struct {
+	Base
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	User       User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Name       string     `gorm:"not null" json:"name"`
+	TokenHash  string     `gorm:"not null;uniqueIndex" json:"-"`
+	Prefix     string     `json:"prefix"` // Erste Zeichen des Tokens zur Wiedererkennung
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+
+	// Klartext-Token, nur in der Antwort auf das Erstellen enthalten (wird nicht gespeichert)
+	Token string `gorm:"-" json:"token,omitempty"`
+}
+
+// IsExpired prüft, ob das Token zum Zeitpunkt now abgelaufen ist
+func (t APIToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantSlugFromHost(t *testing.T) {
+	testCases := []struct {
+		host     string
+		expected string
+		ok       bool
+	}{
+		{"nord.schichtplaner.example", "nord", true},
+		{"Nord.Schichtplaner.Example:3000", "nord", true},
+		{"schichtplaner.example", "", false},
+		{"a.b.schichtplaner.example", "", false},
+		{"nord.anderes.example", "", false},
+		{"localhost:3000", "", false},
+	}
+
+	for _, tc := range testCases {
+		slug, ok := TenantSlugFromHost(tc.host, "schichtplaner.example")
+		assert.Equal(t, tc.ok, ok, tc.host)
+		assert.Equal(t, tc.expected, slug, tc.host)
+	}
+
+	// Ohne Basis-Domain werden keine Subdomains ausgewertet
+	_, ok := TenantSlugFromHost("nord.schichtplaner.example", "")
+	assert.False(t, ok)
+}
+
+func TestAPIToken_IsExpired(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	assert.False(t, APIToken{}.IsExpired(now))
+	assert.True(t, APIToken{ExpiresAt: &past}.IsExpired(now))
+	assert.True(t, APIToken{ExpiresAt: &now}.IsExpired(now))
+	assert.False(t, APIToken{ExpiresAt: &future}.IsExpired(now))
+}
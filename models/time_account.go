@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// TimeAccountCorrection ist eine manuelle Buchung auf dem Arbeitszeitkonto
+type TimeAccountCorrection struct {
+	Base
+	UserID uint      `gorm:"not null;index" json:"user_id"`
+	Date   time.Time `gorm:"not null;index" json:"date"` // Kalendertag, 00:00 Uhr UTC
+	Hours  float64   `gorm:"not null" json:"hours"`      // Positiv = Gutschrift, negativ = Abzug
+	Reason string    `gorm:"not null" json:"reason"`
+}
+
+// TimeAccountDay ist eine Zeile des monatlichen Kontoauszugs (wird nicht gespeichert)
+type TimeAccountDay struct {
+	Date            time.Time `json:"date"` // Kalendertag, 00:00 Uhr UTC
+	TargetHours     float64   `json:"target_hours"`
+	PlannedHours    float64   `json:"planned_hours"`
+	ActualHours     float64   `json:"actual_hours"`
+	CallOutHours    float64   `json:"call_out_hours"` // Einsätze während einer Rufbereitschaft
+	AbsenceType     string    `json:"absence_type,omitempty"`
+	CreditHours     float64   `json:"credit_hours"`
+	CorrectionHours float64   `json:"correction_hours"`
+	Holiday         string    `json:"holiday,omitempty"`
+}
+
+// TimeAccountMonth ist der Monatsabschluss des Arbeitszeitkontos (wird nicht gespeichert).
+// Saldo = Ist + Einsätze + Gutschriften + Korrekturen - Soll
+type TimeAccountMonth struct {
+	UserID             uint                    `json:"user_id"`
+	Month              string                  `json:"month"` // YYYY-MM
+	TargetHours        float64                 `json:"target_hours"`
+	PlannedHours       float64                 `json:"planned_hours"`
+	ActualHours        float64                 `json:"actual_hours"`
+	CallOutHours       float64                 `json:"call_out_hours"` // Einsätze während einer Rufbereitschaft
+	AbsenceCreditHours float64                 `json:"absence_credit_hours"`
+	CorrectionHours    float64                 `json:"correction_hours"`
+	Balance            float64                 `json:"balance"`         // Saldo des Monats
+	OpeningBalance     float64                 `json:"opening_balance"` // Übertrag aus dem Vormonat
+	ClosingBalance     float64                 `json:"closing_balance"` // Kontostand am Monatsende
+	Days               []TimeAccountDay        `json:"days,omitempty"`
+	Corrections        []TimeAccountCorrection `json:"corrections,omitempty"`
+}
+
+// TimeAccountSummary fasst das Arbeitszeitkonto eines Benutzers für einen Zeitraum zusammen (wird nicht gespeichert)
+type TimeAccountSummary struct {
+	UserID             uint    `json:"user_id"`
+	UserName           string  `json:"user_name"`
+	From               string  `json:"from"` // YYYY-MM
+	To                 string  `json:"to"`   // YYYY-MM
+	TargetHours        float64 `json:"target_hours"`
+	PlannedHours       float64 `json:"planned_hours"`
+	ActualHours        float64 `json:"actual_hours"`
+	CallOutHours       float64 `json:"call_out_hours"`
+	AbsenceCreditHours float64 `json:"absence_credit_hours"`
+	CorrectionHours    float64 `json:"correction_hours"`
+	Balance            float64 `json:"balance"`         // Saldo des Zeitraums
+	ClosingBalance     float64 `json:"closing_balance"` // Kontostand am Ende des Zeitraums
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Herkunft einer Zeitbuchung
+const (
+	TimeEntrySourceClock   = "clock"    // Über Kommen/Gehen gestempelt
+	TimeEntrySourceManual  = "manual"   // Nachträglich manuell erfasst
+	TimeEntrySourceCallOut = "call_out" // Einsatz während einer Rufbereitschaft
+)
+
+// TimeEntry repräsentiert eine Zeitbuchung (Kommen bis Gehen) eines Benutzers
+type TimeEntry struct {
+	Base
+	UserID       uint       `gorm:"not null;index" json:"user_id"`
+	User         User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ShiftID      *uint      `gorm:"index" json:"shift_id"` // Automatisch zugeordnete geplante Schicht
+	Shift        *Shift     `gorm:"foreignKey:ShiftID" json:"shift,omitempty"`
+	OnCallDutyID *uint      `gorm:"index" json:"on_call_duty_id,omitempty"` // Rufbereitschaft, zu der der Einsatz gehört
+	ClockIn      time.Time  `gorm:"not null;index" json:"clock_in"`
+	ClockOut     *time.Time `gorm:"index" json:"clock_out"` // Leer, solange die Zeiterfassung läuft
+	Source       string     `gorm:"default:'clock'" json:"source"`
+	Note         string     `json:"note"`
+
+	Breaks      []TimeEntryBreak      `gorm:"foreignKey:TimeEntryID" json:"breaks"`
+	Corrections []TimeEntryCorrection `gorm:"foreignKey:TimeEntryID" json:"corrections,omitempty"`
+}
+
+// TimeEntryBreak ist eine Pause innerhalb einer Zeitbuchung
+type TimeEntryBreak struct {
+	Base
+	TimeEntryID uint       `gorm:"not null;index" json:"time_entry_id"`
+	Start       time.Time  `gorm:"not null" json:"start"`
+	End         *time.Time `json:"end"` // Leer, solange die Pause läuft
+}
+
+// TimeEntryCorrection protokolliert eine manuelle Erfassung oder Korrektur einer Zeitbuchung mit Begründung
+type TimeEntryCorrection struct {
+	Base
+	TimeEntryID   uint   `gorm:"not null;index" json:"time_entry_id"`
+	CorrectedByID *uint  `json:"corrected_by_id"` // Angemeldeter Benutzer, leer ohne Anmeldung
+	Reason        string `gorm:"not null" json:"reason"`
+
+	// Werte vor der Korrektur, leer bei manueller Erfassung
+	PreviousClockIn      *time.Time `json:"previous_clock_in"`
+	PreviousClockOut     *time.Time `json:"previous_clock_out"`
+	PreviousBreakMinutes *int       `json:"previous_break_minutes"`
+
+	ClockIn      time.Time  `json:"clock_in"`
+	ClockOut     *time.Time `json:"clock_out"`
+	BreakMinutes int        `json:"break_minutes"`
+}
+
+// IsRunning prüft, ob die Zeiterfassung noch läuft
+func (e TimeEntry) IsRunning() bool {
+	return e.ClockOut == nil
+}
+
+// OpenBreak liefert die laufende Pause oder nil
+func (e *TimeEntry) OpenBreak() *TimeEntryBreak {
+	for i := range e.Breaks {
+		if e.Breaks[i].End == nil {
+			return &e.Breaks[i]
+		}
+	}
+	return nil
+}
+
+// end liefert das Ende der Zeitbuchung; laufende Buchungen enden vorläufig bei now
+func (e TimeEntry) end(now time.Time) time.Time {
+	if e.ClockOut != nil {
+		return *e.ClockOut
+	}
+	return now
+}
+
+// BreakDuration liefert die Summe der Pausen; laufende Pausen zählen bis zum Ende der Buchung bzw. bis now
+func (e TimeEntry) BreakDuration(now time.Time) time.Duration {
+	end := e.end(now)
+	var total time.Duration
+	for _, b := range e.Breaks {
+		breakEnd := end
+		if b.End != nil && b.End.Before(end) {
+			breakEnd = *b.End
+		}
+		if breakEnd.After(b.Start) {
+			total += breakEnd.Sub(b.Start)
+		}
+	}
+	return total
+}
+
+// WorkedDuration liefert die Arbeitszeit der Buchung abzüglich der Pausen; laufende Buchungen zählen bis now
+func (e TimeEntry) WorkedDuration(now time.Time) time.Duration {
+	worked := e.end(now).Sub(e.ClockIn) - e.BreakDuration(now)
+	if worked < 0 {
+		return 0
+	}
+	return worked
+}
+
+// MarshalJSON ergänzt die Zeitbuchung um Arbeits- und Pausenzeit in Minuten (laufende Buchungen bis jetzt)
+func (e TimeEntry) MarshalJSON() ([]byte, error) {
+	type timeEntryJSON TimeEntry
+	now := time.Now()
+	return json.Marshal(struct {
+		timeEntryJSON
+		WorkedMinutes int  `json:"worked_minutes"`
+		BreakMinutes  int  `json:"break_minutes"`
+		IsRunning     bool `json:"is_running"`
+		OnBreak       bool `json:"on_break"`
+	}{
+		timeEntryJSON: timeEntryJSON(e),
+		WorkedMinutes: int(e.WorkedDuration(now) / time.Minute),
+		BreakMinutes:  int(e.BreakDuration(now) / time.Minute),
+		IsRunning:     e.IsRunning(),
+		OnBreak:       e.IsRunning() && e.OpenBreak() != nil,
+	})
+}
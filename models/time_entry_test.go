@@ -0,0 +1,28 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeEntryDurations(t *testing.T) {
+	start := time.Date(2024, 3, 4, 6, 0, 0, 0, time.UTC)
+	breakStart := start.Add(4 * time.Hour)
+	breakEnd := breakStart.Add(30 * time.Minute)
+	end := start.Add(8 * time.Hour)
+
+	entry := TimeEntry{ClockIn: start, ClockOut: &end, Breaks: []TimeEntryBreak{{Start: breakStart, End: &breakEnd}}}
+	assert.False(t, entry.IsRunning())
+	assert.Equal(t, 30*time.Minute, entry.BreakDuration(end.Add(time.Hour)))
+	assert.Equal(t, 7*time.Hour+30*time.Minute, entry.WorkedDuration(end.Add(time.Hour)))
+
+	// Laufende Buchung mit laufender Pause zählt bis now
+	running := TimeEntry{ClockIn: start, Breaks: []TimeEntryBreak{{Start: breakStart}}}
+	now := breakStart.Add(10 * time.Minute)
+	assert.True(t, running.IsRunning())
+	assert.NotNil(t, running.OpenBreak())
+	assert.Equal(t, 10*time.Minute, running.BreakDuration(now))
+	assert.Equal(t, 4*time.Hour, running.WorkedDuration(now))
+}
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Status eines Stundenzettels
+const (
+	TimesheetStatusOpen      = "open"      // In Bearbeitung durch den Mitarbeiter
+	TimesheetStatusSubmitted = "submitted" // Zur Freigabe eingereicht
+	TimesheetStatusReturned  = "returned"  // Mit Anmerkungen zurückgegeben
+	TimesheetStatusApproved  = "approved"  // Freigegeben, Zeitbuchungen und Schichten des Monats sind gesperrt
+)
+
+// Aktionen im Freigabeablauf eines Stundenzettels
+const (
+	TimesheetActionSubmit  = "submit"  // Einreichen
+	TimesheetActionApprove = "approve" // Freigeben
+	TimesheetActionReturn  = "return"  // Zurückgeben
+	TimesheetActionReopen  = "reopen"  // Freigabe aufheben
+)
+
+// Timesheet repräsentiert den Stundenzettel eines Benutzers für einen Monat. Die Summen werden beim Einreichen
+// und Freigeben festgeschrieben.
+type Timesheet struct {
+	Base
+	UserID       uint       `gorm:"not null;uniqueIndex:idx_timesheets_tenant_user_month,expression:tenant_id\\,user_id\\,month" json:"user_id"`
+	User         User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Month        string     `gorm:"not null" json:"month"` // YYYY-MM
+	Status       string     `gorm:"default:'open'" json:"status"`
+	PlannedHours float64    `json:"planned_hours"`
+	ActualHours  float64    `json:"actual_hours"`
+	BreakMinutes int        `json:"break_minutes"`
+	SubmittedAt  *time.Time `json:"submitted_at"`
+	ApprovedAt   *time.Time `json:"approved_at"`
+	ApprovedByID *uint      `json:"approved_by_id"`
+	Comment      string     `json:"comment"` // Letzte Anmerkung, z.B. Grund der Rückgabe
+
+	Events []TimesheetEvent `gorm:"foreignKey:TimesheetID" json:"events,omitempty"`
+
+	// Tageszeilen aus Schichten und Zeitbuchungen (werden nicht gespeichert)
+	Days []TimesheetDay `gorm:"-" json:"days,omitempty"`
+}
+
+// IsLocked prüft, ob Zeitbuchungen und Schichten des Monats gesperrt sind
+func (t Timesheet) IsLocked() bool {
+	return t.Status == TimesheetStatusApproved
+}
+
+// TimesheetEvent protokolliert einen Schritt im Freigabeablauf eines Stundenzettels
+type TimesheetEvent struct {
+	Base
+	TimesheetID uint   `gorm:"not null;index" json:"timesheet_id"`
+	Action      string `gorm:"not null" json:"action"`
+	FromStatus  string `json:"from_status"`
+	ToStatus    string `json:"to_status"`
+	ActorID     *uint  `json:"actor_id"` // Angemeldeter Benutzer, leer ohne Anmeldung
+	Comment     string `json:"comment"`
+}
+
+// TimesheetDay ist eine Zeile des Stundenzettels (wird nicht gespeichert)
+type TimesheetDay struct {
+	Date         time.Time `json:"date"` // Kalendertag, 00:00 Uhr UTC
+	PlannedHours float64   `json:"planned_hours"`
+	ActualHours  float64   `json:"actual_hours"`
+	BreakMinutes int       `json:"break_minutes"`
+	Difference   float64   `json:"difference"` // Ist - Plan
+	Shifts       int       `json:"shifts"`
+	Entries      int       `json:"entries"`
+}
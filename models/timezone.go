@@ -0,0 +1,123 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	// Zeitzonendatenbank einbetten, damit Europe/Berlin auch ohne System-tzdata verfügbar ist
+	_ "time/tzdata"
+)
+
+// DefaultTimeZone ist die Zeitzone der Organisation, wenn keine andere konfiguriert ist
+const DefaultTimeZone = "Europe/Berlin"
+
+var (
+	organisationLocationMu sync.RWMutex
+	organisationLocation   = mustLoadLocation(DefaultTimeZone)
+)
+
+// OrganisationLocation liefert die konfigurierte Zeitzone der Organisation
+func OrganisationLocation() *time.Location {
+	organisationLocationMu.RLock()
+	defer organisationLocationMu.RUnlock()
+	return organisationLocation
+}
+
+// SetOrganisationLocation setzt die Zeitzone der Organisation
+func SetOrganisationLocation(loc *time.Location) {
+	if loc == nil {
+		return
+	}
+	organisationLocationMu.Lock()
+	defer organisationLocationMu.Unlock()
+	organisationLocation = loc
+}
+
+// LoadLocation lädt eine IANA-Zeitzone; ein leerer Name liefert die Zeitzone der Organisation
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return OrganisationLocation(), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("ungültige Zeitzone: %s", name)
+	}
+	return loc, nil
+}
+
+// locationOrDefault lädt eine Zeitzone und fällt bei Fehlern auf die Zeitzone der Organisation zurück
+func locationOrDefault(name string) *time.Location {
+	loc, err := LoadLocation(name)
+	if err != nil {
+		return OrganisationLocation()
+	}
+	return loc
+}
+
+// localTimeLayouts sind die akzeptierten Formate für Ortszeiten ohne Offset
+var localTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// ParseLocalTime liest einen Zeitpunkt. Angaben mit Offset (RFC 3339) bleiben unverändert,
+// Angaben ohne Offset werden als Ortszeit in loc interpretiert.
+func ParseLocalTime(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("ungültige Zeitangabe: %s", value)
+}
+
+// ParseMonth liest einen Monat im Format YYYY-MM und liefert den Monatsbeginn in loc
+func ParseMonth(value string, loc *time.Location) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01", value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("ungültiger Monat: %s", value)
+	}
+	return t, nil
+}
+
+// LocalTimes enthält Beginn und Ende eines Zeitraums in Orts- und UTC-Darstellung
+type LocalTimes struct {
+	TimeZone        string `json:"time_zone"`
+	StartLocal      string `json:"start_local"`
+	EndLocal        string `json:"end_local"`
+	StartUTC        string `json:"start_utc"`
+	EndUTC          string `json:"end_utc"`
+	DurationMinutes int    `json:"duration_minutes"` // Tatsächlich vergangene Zeit, auch über Zeitumstellungen
+}
+
+// NewLocalTimes berechnet die Darstellungen eines Zeitraums für die Zeitzone loc
+func NewLocalTimes(start, end time.Time, loc *time.Location) LocalTimes {
+	times := LocalTimes{TimeZone: loc.String()}
+	if !start.IsZero() {
+		times.StartLocal = start.In(loc).Format(time.RFC3339)
+		times.StartUTC = start.UTC().Format(time.RFC3339)
+	}
+	if !end.IsZero() {
+		times.EndLocal = end.In(loc).Format(time.RFC3339)
+		times.EndUTC = end.UTC().Format(time.RFC3339)
+	}
+	if !start.IsZero() && !end.IsZero() {
+		times.DurationMinutes = int(end.Sub(start) / time.Minute)
+	}
+	return times
+}
+
+// mustLoadLocation lädt eine eingebettete Zeitzone und bricht bei Fehlern ab
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
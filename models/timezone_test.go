@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLocalTime(t *testing.T) {
+	berlin, err := LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	// Ohne Offset wird die Angabe als Ortszeit interpretiert (Winterzeit: UTC+1)
+	local, err := ParseLocalTime("2024-01-15T06:00", berlin)
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2024, 1, 15, 5, 0, 0, 0, time.UTC).Equal(local))
+
+	// Sommerzeit: UTC+2
+	local, err = ParseLocalTime("2024-07-15 06:00:00", berlin)
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2024, 7, 15, 4, 0, 0, 0, time.UTC).Equal(local))
+
+	// Angaben mit Offset bleiben unverändert
+	absolute, err := ParseLocalTime("2024-01-15T06:00:00Z", berlin)
+	assert.NoError(t, err)
+	assert.True(t, time.Date(2024, 1, 15, 6, 0, 0, 0, time.UTC).Equal(absolute))
+
+	_, err = ParseLocalTime("15.01.2024", berlin)
+	assert.Error(t, err)
+}
+
+func TestLoadLocation(t *testing.T) {
+	loc, err := LoadLocation("")
+	assert.NoError(t, err)
+	assert.Equal(t, OrganisationLocation(), loc)
+
+	_, err = LoadLocation("Mars/Olympus")
+	assert.Error(t, err)
+}
+
+func TestNewLocalTimes_AcrossDST(t *testing.T) {
+	berlin, _ := LoadLocation("Europe/Berlin")
+
+	// Nachtschicht in der Nacht der Umstellung auf Winterzeit dauert 9 Stunden
+	start := time.Date(2024, 10, 26, 22, 0, 0, 0, berlin)
+	end := time.Date(2024, 10, 27, 6, 0, 0, 0, berlin)
+	times := NewLocalTimes(start, end, berlin)
+
+	assert.Equal(t, "Europe/Berlin", times.TimeZone)
+	assert.Equal(t, "2024-10-26T22:00:00+02:00", times.StartLocal)
+	assert.Equal(t, "2024-10-27T06:00:00+01:00", times.EndLocal)
+	assert.Equal(t, "2024-10-26T20:00:00Z", times.StartUTC)
+	assert.Equal(t, "2024-10-27T05:00:00Z", times.EndUTC)
+	assert.Equal(t, 9*60, times.DurationMinutes)
+}
+
+func TestShiftJSON_IncludesLocalTimes(t *testing.T) {
+	shift := Shift{
+		UserID:    1,
+		StartTime: time.Date(2024, 3, 30, 21, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2024, 3, 31, 4, 0, 0, 0, time.UTC),
+		User:      User{Base: Base{ID: 1}, TimeZone: "Europe/Berlin"},
+	}
+
+	data, err := json.Marshal(shift)
+	assert.NoError(t, err)
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "2024-03-30T22:00:00+01:00", decoded["start_local"])
+	assert.Equal(t, "2024-03-31T06:00:00+02:00", decoded["end_local"])
+	assert.Equal(t, float64(7*60), decoded["duration_minutes"])
+	assert.Equal(t, float64(1), decoded["user_id"])
+
+	// Die Antwort lässt sich wieder in eine Schicht einlesen
+	var roundTrip Shift
+	assert.NoError(t, json.Unmarshal(data, &roundTrip))
+	assert.True(t, shift.StartTime.Equal(roundTrip.StartTime))
+}
+
+func TestWallClock(t *testing.T) {
+	clock, err := ParseWallClock("06:30:00")
+	assert.NoError(t, err)
+	assert.Equal(t, NewWallClock(6, 30), clock)
+	assert.Equal(t, "06:30", clock.String())
+
+	// Ältere Werte mit Datum werden auf die Uhrzeit reduziert
+	legacy, err := ParseWallClock("2024-01-01T22:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, NewWallClock(22, 0), legacy)
+
+	_, err = ParseWallClock("25:00")
+	assert.Error(t, err)
+
+	data, err := json.Marshal(ShiftType{DefaultStart: NewWallClock(6, 0)})
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"default_start":"06:00"`)
+	assert.Contains(t, string(data), `"default_end":null`)
+
+	var shiftType ShiftType
+	assert.NoError(t, json.Unmarshal([]byte(`{"default_start":"14:00","default_end":"22:00:00"}`), &shiftType))
+	assert.Equal(t, NewWallClock(14, 0), shiftType.DefaultStart)
+	assert.Equal(t, NewWallClock(22, 0), shiftType.DefaultEnd)
+
+	var scanned WallClock
+	assert.NoError(t, scanned.Scan(time.Date(2024, 1, 1, 8, 15, 0, 0, time.UTC)))
+	assert.Equal(t, NewWallClock(8, 15), scanned)
+	value, err := scanned.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "08:15", value)
+}
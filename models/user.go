@@ -1,18 +1,40 @@
 package models
 
+import "time"
+
+// Rollen von Benutzern
+const (
+	RoleAdmin    = "admin"
+	RoleManager  = "manager" // Planer bzw. Teamleitung
+	RoleEmployee = "employee"
+	RoleUser     = "user"
+)
+
 // User repräsentiert einen Benutzer im System
 type User struct {
 	Base
-	Username      string  `gorm:"uniqueIndex;not null" json:"username"`
-	Email         string  `gorm:"uniqueIndex;not null" json:"email"`
+	Username      string  `gorm:"not null;uniqueIndex:idx_users_tenant_username,expression:tenant_id\\,username" json:"username"`
+	Email         string  `gorm:"not null;uniqueIndex:idx_users_tenant_email,expression:tenant_id\\,email" json:"email"`
 	Password      string  `gorm:"not null" json:"-"` // "-" versteckt das Passwort in JSON-Responses
-	AccountNumber string  `gorm:"uniqueIndex" json:"account_number,omitempty"`
+	AccountNumber string  `gorm:"uniqueIndex:idx_users_tenant_account_number,expression:tenant_id\\,account_number" json:"account_number,omitempty"`
 	Name          string  `gorm:"not null" json:"name"`
 	Color         string  `json:"color"`
 	Role          string  `gorm:"default:'user'" json:"role"`
 	IsActive      bool    `gorm:"default:true" json:"is_active"`
 	IsAdmin       bool    `gorm:"default:false" json:"is_admin"`
-	TeamID        *uint   `json:"team_id"` // Optional, da nicht alle User einem Team angehören müssen
+	TimeZone      string  `json:"time_zone,omitempty"` // Optionale IANA-Zeitzone, leer = Zeitzone der Organisation
+	WeeklyHours   float64 `json:"weekly_hours"`        // Wochenarbeitszeit ohne Vertragshistorie, verteilt auf Montag bis Freitag
+	TeamID        *uint   `json:"team_id"`             // Optional, da nicht alle User einem Team angehören müssen
 	Team          Team    `gorm:"foreignKey:TeamID" json:"team,omitempty"`
 	Shifts        []Shift `gorm:"foreignKey:UserID" json:"shifts,omitempty"`
 }
+
+// Location liefert die Zeitzone des Benutzers oder die Zeitzone der Organisation
+func (u User) Location() *time.Location {
+	return locationOrDefault(u.TimeZone)
+}
+
+// CanApprove prüft, ob der Benutzer Stundenzettel anderer Benutzer freigeben darf (Administratoren, Planer und Teamleitungen)
+func (u User) CanApprove() bool {
+	return u.IsAdmin || u.Role == RoleAdmin || u.Role == RoleManager
+}
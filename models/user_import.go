@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+// Felder des CSV-Benutzerimports, denen Spalten zugeordnet werden
+const (
+	UserImportFieldUsername      = "username"
+	UserImportFieldEmail         = "email"
+	UserImportFieldName          = "name"
+	UserImportFieldAccountNumber = "account_number"
+	UserImportFieldTeam          = "team" // Name des Teams
+	UserImportFieldRole          = "role"
+)
+
+// Zugangsarten für importierte Benutzer
+const (
+	UserImportPasswordInitial    = "initial"    // Startpasswort (vorgegeben oder je Benutzer erzeugt)
+	UserImportPasswordInvitation = "invitation" // Einladung, mit der der Benutzer sein Passwort selbst setzt
+)
+
+// UserImportRow ist eine Zeile des Benutzerimports mit ihren Prüfergebnissen (wird nicht gespeichert)
+type UserImportRow struct {
+	Line          int      `json:"line"` // Zeile in der CSV-Datei (Kopfzeile = 1)
+	Username      string   `json:"username"`
+	Email         string   `json:"email"`
+	Name          string   `json:"name"`
+	AccountNumber string   `json:"account_number,omitempty"`
+	Team          string   `json:"team,omitempty"`
+	TeamID        *uint    `json:"team_id,omitempty"`
+	Role          string   `json:"role"`
+	Errors        []string `json:"errors,omitempty"`
+
+	// Nur nach dem Import gesetzt
+	UserID          uint   `json:"user_id,omitempty"`
+	InitialPassword string `json:"initial_password,omitempty"` // Erzeugtes Startpasswort
+	InvitationToken string `json:"invitation_token,omitempty"` // Klartext der Einladung
+}
+
+// UserImportReport ist das Ergebnis eines Probelaufs oder Imports (wird nicht gespeichert)
+type UserImportReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Columns []string          `json:"columns"` // Spalten der Kopfzeile
+	Mapping map[string]string `json:"mapping"` // Feld → Spalte
+	Total   int               `json:"total"`
+	Valid   int               `json:"valid"`
+	Invalid int               `json:"invalid"`
+	Created int               `json:"created"`
+	Rows    []UserImportRow   `json:"rows"`
+	Error   string            `json:"error,omitempty"` // Grund, warum nicht importiert wurde
+}
+
+// UserInvitation ist eine Einladung, mit der ein importierter Benutzer sein Passwort selbst festlegt.
+// Gespeichert wird nur der SHA-256-Hash des Tokens.
+type UserInvitation struct {
+	Base
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	User       User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	TokenHash  string     `gorm:"not null;uniqueIndex" json:"-"`
+	Prefix     string     `json:"prefix"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+}
+
+// IsUsable prüft, ob die Einladung noch angenommen werden kann
+func (i UserInvitation) IsUsable(now time.Time) bool {
+	return i.AcceptedAt == nil && now.Before(i.ExpiresAt)
+}
@@ -240,8 +240,8 @@ func TestUser_Relationships(t *testing.T) {
 		Name:         "Test Shift Type",
 		Description:  "Test Shift Type Description",
 		Color:        "#3B82F6",
-		DefaultStart: time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC),
-		DefaultEnd:   time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC),
+		DefaultStart: NewWallClock(6, 0),
+		DefaultEnd:   NewWallClock(14, 0),
 		DefaultBreak: 30,
 		IsActive:     true,
 		SortOrder:    1,
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// Arten von Abweichungen zwischen Plan und Ist
+const (
+	VarianceLateStart     = "late_start"     // Verspäteter Beginn
+	VarianceEarlyLeave    = "early_leave"    // Vorzeitiges Gehen
+	VarianceUnplannedWork = "unplanned_work" // Arbeit ohne geplante Schicht
+	VarianceNoShow        = "no_show"        // Geplante Schicht ohne Zeitbuchung
+)
+
+// Variance ist eine Abweichung zwischen geplanter Schicht und Zeitbuchung (wird nicht gespeichert)
+type Variance struct {
+	Date          time.Time  `json:"date"` // Kalendertag in der Zeitzone des Benutzers, 00:00 Uhr UTC
+	Type          string     `json:"type"`
+	UserID        uint       `json:"user_id"`
+	UserName      string     `json:"user_name"`
+	AccountNumber string     `json:"account_number"`
+	TeamID        *uint      `json:"team_id"`
+	TeamName      string     `json:"team_name"`
+	ShiftID       *uint      `json:"shift_id"`
+	TimeEntryID   *uint      `json:"time_entry_id"`
+	PlannedStart  *time.Time `json:"planned_start"`
+	PlannedEnd    *time.Time `json:"planned_end"`
+	ActualStart   *time.Time `json:"actual_start"`
+	ActualEnd     *time.Time `json:"actual_end"`
+	Minutes       int        `json:"minutes"` // Umfang der Abweichung
+}
+
+// VarianceSummary zählt die Abweichungen eines Benutzers, Teams oder Kalendertags (wird nicht gespeichert)
+type VarianceSummary struct {
+	Key           string `json:"key"` // Benutzer-ID, Team-ID oder Datum (YYYY-MM-DD)
+	Name          string `json:"name"`
+	LateStarts    int    `json:"late_starts"`
+	EarlyLeaves   int    `json:"early_leaves"`
+	UnplannedWork int    `json:"unplanned_work"`
+	NoShows       int    `json:"no_shows"`
+	Minutes       int    `json:"minutes"`
+}
+
+// VarianceReport ist der Plan-Ist-Vergleich für einen Zeitraum (wird nicht gespeichert)
+type VarianceReport struct {
+	From             time.Time         `json:"from"`
+	To               time.Time         `json:"to"`
+	ToleranceMinutes int               `json:"tolerance_minutes"`
+	Variances        []Variance        `json:"variances"`
+	ByUser           []VarianceSummary `json:"by_user"`
+	ByTeam           []VarianceSummary `json:"by_team"`
+	ByDay            []VarianceSummary `json:"by_day"`
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// WallClock ist eine Uhrzeit ohne Datum und Zeitzone (z.B. 06:00), etwa der Standardbeginn eines Schichttyps.
+// Erst zusammen mit einem Kalendertag und einer Zeitzone ergibt sich ein Zeitpunkt.
+type WallClock struct {
+	Minutes int  // Minuten seit Mitternacht
+	Valid   bool // false = nicht gesetzt
+}
+
+// NewWallClock erstellt eine Uhrzeit aus Stunde und Minute
+func NewWallClock(hour, minute int) WallClock {
+	return WallClock{Minutes: hour*60 + minute, Valid: true}
+}
+
+// ParseWallClock liest eine Uhrzeit im Format HH:MM oder HH:MM:SS.
+// Ältere Werte mit Datum (RFC 3339) werden auf ihre Uhrzeit reduziert.
+func ParseWallClock(value string) (WallClock, error) {
+	if value == "" {
+		return WallClock{}, nil
+	}
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return NewWallClock(t.Hour(), t.Minute()), nil
+		}
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05-07:00", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return NewWallClock(t.Hour(), t.Minute()), nil
+		}
+	}
+	return WallClock{}, fmt.Errorf("ungültige Uhrzeit: %s", value)
+}
+
+// Hour liefert die Stunde
+func (w WallClock) Hour() int {
+	return w.Minutes / 60
+}
+
+// Minute liefert die Minute
+func (w WallClock) Minute() int {
+	return w.Minutes % 60
+}
+
+// String formatiert die Uhrzeit als HH:MM
+func (w WallClock) String() string {
+	if !w.Valid {
+		return ""
+	}
+	return fmt.Sprintf("%02d:%02d", w.Hour(), w.Minute())
+}
+
+// On liefert den Zeitpunkt der Uhrzeit am Kalendertag von day in der Zeitzone loc.
+// Uhrzeiten, die wegen der Sommerzeitumstellung nicht existieren, werden nach vorne verschoben.
+func (w WallClock) On(day time.Time, loc *time.Location) time.Time {
+	local := day.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), w.Hour(), w.Minute(), 0, 0, loc)
+}
+
+// MarshalJSON gibt die Uhrzeit als "HH:MM" oder null aus
+func (w WallClock) MarshalJSON() ([]byte, error) {
+	if !w.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(w.String())
+}
+
+// UnmarshalJSON liest "HH:MM", "HH:MM:SS" oder null
+func (w *WallClock) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*w = WallClock{}
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := ParseWallClock(value)
+	if err != nil {
+		return err
+	}
+	*w = parsed
+	return nil
+}
+
+// Scan liest die Uhrzeit aus der Datenbank
+func (w *WallClock) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*w = WallClock{}
+		return nil
+	case time.Time:
+		// Ältere Datenbanken speichern die Uhrzeit als Datum
+		*w = NewWallClock(v.Hour(), v.Minute())
+		return nil
+	case []byte:
+		parsed, err := ParseWallClock(string(v))
+		*w = parsed
+		return err
+	case string:
+		parsed, err := ParseWallClock(v)
+		*w = parsed
+		return err
+	default:
+		return fmt.Errorf("ungültiger Typ für Uhrzeit: %T", value)
+	}
+}
+
+// Value speichert die Uhrzeit als "HH:MM"
+func (w WallClock) Value() (driver.Value, error) {
+	if !w.Valid {
+		return nil, nil
+	}
+	return w.String(), nil
+}
+
+// GormDataType legt den Spaltentyp für neue Datenbanken fest
+func (WallClock) GormDataType() string {
+	return "string"
+}
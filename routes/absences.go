@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterAbsenceRoutes registriert alle Abwesenheits-bezogenen API-Routen
+func RegisterAbsenceRoutes(api *echo.Group) {
+	// Abwesenheiten
+	api.GET("/absences", handlers.GetAbsences)
+	api.GET("/absences/:id", handlers.GetAbsence)
+	api.POST("/absences", handlers.CreateAbsence)
+	api.PUT("/absences/:id", handlers.UpdateAbsence)
+	api.DELETE("/absences/:id", handlers.DeleteAbsence)
+
+	// Anrechnung von Abwesenheiten auf das Arbeitszeitkonto
+	api.GET("/absence-credit-rules", handlers.GetAbsenceCreditRules)
+	api.PUT("/absence-credit-rules/:type", handlers.UpdateAbsenceCreditRule)
+}
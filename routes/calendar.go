@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterCalendarFeedRoutes registriert die iCalendar-Abonnements. Sie sind per Token im Query-Parameter geschützt
+// und müssen vor der Mandanten-Middleware registriert werden, da Kalender-Apps keine Anmeldung mitsenden.
+func RegisterCalendarFeedRoutes(api *echo.Group) {
+	api.GET("/users/:id/calendar.ics", handlers.GetUserCalendar)
+	api.GET("/teams/:id/calendar.ics", handlers.GetTeamCalendar)
+	api.GET("/schedules/:id/calendar.ics", handlers.GetScheduleCalendar)
+}
+
+// RegisterCalendarRoutes registriert die Verwaltung der Kalender-Abonnements (Token erzeugen und widerrufen)
+func RegisterCalendarRoutes(api *echo.Group) {
+	api.GET("/calendar-feeds", handlers.GetCalendarFeeds)
+	api.POST("/calendar-feeds", handlers.CreateCalendarFeed)
+	api.DELETE("/calendar-feeds/:id", handlers.DeleteCalendarFeed)
+}
+
+// RegisterCalDAVRoutes registriert den schreibgeschützten CalDAV-Server. Er meldet Clients selbst per API-Token an
+// (auch per Basic-Authentifizierung) und muss daher vor der Mandanten-Middleware registriert werden.
+func RegisterCalDAVRoutes(api *echo.Group) {
+	caldav := api.Group("/caldav", handlers.CalDAVMiddleware)
+	caldav.Any("", handlers.CalDAV)
+	caldav.Any("/*", handlers.CalDAV)
+}
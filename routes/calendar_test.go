@@ -0,0 +1,79 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"schichtplaner/database"
+	"schichtplaner/models"
+	"schichtplaner/services"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarFeedWithoutLogin(t *testing.T) {
+	setupTestDB(t)
+	defer cleanupTestDB()
+	assert.NoError(t, database.EnsureDefaultTenant(database.DB))
+
+	user := models.User{Username: "abo", Email: "abo@example.com", Password: "x", Name: "Abo", IsActive: true}
+	assert.NoError(t, database.DB.Create(&user).Error)
+	token, hash, prefix, err := services.GenerateCalendarFeedToken()
+	assert.NoError(t, err)
+	assert.NoError(t, database.DB.Create(&models.CalendarFeed{Scope: models.CalendarScopeUser, TargetID: user.ID, TokenHash: hash, Prefix: prefix}).Error)
+
+	models.SetAuthRequired(true)
+	defer models.SetAuthRequired(false)
+
+	e := echo.New()
+	RegisterAPIRoutes(e)
+
+	// Das Abonnement ist ohne API-Token erreichbar, alle anderen Routen nicht
+	req := httptest.NewRequest(http.MethodGet, "/api/users/1/calendar.ics?token="+token, nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "BEGIN:VCALENDAR")
+
+	req = httptest.NewRequest(http.MethodGet, "/api/users/1", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestCalDAVRoutes(t *testing.T) {
+	setupTestDB(t)
+	defer cleanupTestDB()
+	assert.NoError(t, database.EnsureDefaultTenant(database.DB))
+
+	user := models.User{Username: "dav", Email: "dav@example.com", Password: "x", Name: "DAV", IsActive: true}
+	assert.NoError(t, database.DB.Create(&user).Error)
+	token, hash, prefix, err := services.GenerateAPIToken()
+	assert.NoError(t, err)
+	assert.NoError(t, database.DB.Create(&models.APIToken{UserID: user.ID, Name: "Thunderbird", TokenHash: hash, Prefix: prefix}).Error)
+
+	e := echo.New()
+	RegisterAPIRoutes(e)
+
+	req := httptest.NewRequest(echo.PROPFIND, "/.well-known/caldav", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, "/api/caldav/", rec.Header().Get(echo.HeaderLocation))
+
+	req = httptest.NewRequest(echo.PROPFIND, "/api/caldav/calendars/", nil)
+	req.SetBasicAuth("dav", token)
+	req.Header.Set("Depth", "1")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMultiStatus, rec.Code)
+	assert.Contains(t, rec.Body.String(), "/api/caldav/calendars/user-1/")
+
+	req = httptest.NewRequest(echo.PROPFIND, "/api/caldav/calendars/", nil)
+	req.SetBasicAuth("dav", "sp_falsch")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
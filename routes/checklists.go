@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterChecklistRoutes registriert alle Routen für Checklisten-Vorlagen und Schicht-Checklisten
+func RegisterChecklistRoutes(api *echo.Group) {
+	api.GET("/checklist-templates", handlers.GetChecklistTemplates)
+	api.POST("/checklist-templates", handlers.CreateChecklistTemplate)
+	api.PUT("/checklist-templates/:id", handlers.UpdateChecklistTemplate)
+	api.DELETE("/checklist-templates/:id", handlers.DeleteChecklistTemplate)
+
+	api.GET("/shifts/:id/checklists", handlers.GetShiftChecklists)
+	api.PUT("/shifts/:id/checklists/items/:item_id", handlers.UpdateShiftChecklistItem)
+}
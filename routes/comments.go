@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterCommentRoutes registriert alle Routen für Kommentare, Übergabenotizen und Erwähnungen
+func RegisterCommentRoutes(api *echo.Group) {
+	api.GET("/shifts/:id/comments", handlers.GetShiftComments)
+	api.POST("/shifts/:id/comments", handlers.CreateShiftComment)
+	api.GET("/shifts/:id/handover", handlers.GetShiftHandover)
+	api.GET("/schedules/:id/comments", handlers.GetScheduleComments)
+	api.POST("/schedules/:id/comments", handlers.CreateScheduleComment)
+
+	api.GET("/comments/:id", handlers.GetComment)
+	api.PUT("/comments/:id", handlers.UpdateComment)
+	api.DELETE("/comments/:id", handlers.DeleteComment)
+
+	api.GET("/users/:id/mentions", handlers.GetUserMentions)
+}
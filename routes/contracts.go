@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterContractRoutes registriert alle Routen für Arbeitsverträge
+func RegisterContractRoutes(api *echo.Group) {
+	// Vertragshistorie eines Benutzers
+	api.GET("/users/:id/contracts", handlers.GetUserContracts)
+	api.POST("/users/:id/contracts", handlers.CreateUserContract)
+	api.PUT("/users/:id/contracts/:contract_id", handlers.UpdateUserContract)
+	api.DELETE("/users/:id/contracts/:contract_id", handlers.DeleteUserContract)
+
+	// Anteiliger Urlaubsanspruch
+	api.GET("/users/:id/vacation-entitlement", handlers.GetVacationEntitlement)
+}
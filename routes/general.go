@@ -10,4 +10,7 @@ import (
 func RegisterGeneralRoutes(api *echo.Group) {
 	// Health endpoint
 	api.GET("/health", handlers.HealthCheckHandler)
+
+	// Zeitzone der Organisation
+	api.GET("/timezone", handlers.GetTimeZone)
 }
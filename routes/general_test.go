@@ -21,6 +21,14 @@ func TestRegisterGeneralRoutes(t *testing.T) {
 		assert.NotEqual(t, http.StatusNotFound, rec.Code, "Route /api/health sollte registriert sein")
 	})
 
+	t.Run("GET /api/timezone sollte die Zeitzone der Organisation liefern", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/timezone", nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Europe/Berlin")
+	})
+
 	t.Run("GET /api/invalid sollte 404 zurückgeben", func(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/api/invalid", nil)
 		rec := httptest.NewRecorder()
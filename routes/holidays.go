@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterHolidayRoutes registriert alle Feiertags-bezogenen API-Routen
+func RegisterHolidayRoutes(api *echo.Group) {
+	// Gesetzliche und betriebliche Feiertage eines Jahres
+	api.GET("/holidays", handlers.GetHolidays)
+
+	// Betriebliche Feiertage
+	api.GET("/holidays/company", handlers.GetCompanyHolidays)
+	api.POST("/holidays/company", handlers.CreateCompanyHoliday)
+	api.PUT("/holidays/company/:id", handlers.UpdateCompanyHoliday)
+	api.DELETE("/holidays/company/:id", handlers.DeleteCompanyHoliday)
+}
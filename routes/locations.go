@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterLocationRoutes registriert alle Routen für Standorte
+func RegisterLocationRoutes(api *echo.Group) {
+	api.GET("/locations", handlers.GetLocations)
+	api.POST("/locations", handlers.CreateLocation)
+	api.GET("/locations/report", handlers.GetLocationReport)
+	api.GET("/locations/:id", handlers.GetLocation)
+	api.PUT("/locations/:id", handlers.UpdateLocation)
+	api.DELETE("/locations/:id", handlers.DeleteLocation)
+
+	// Besetzung je Kalendertag
+	api.GET("/locations/:id/staffing", handlers.GetLocationStaffing)
+}
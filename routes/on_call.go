@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterOnCallRoutes registriert alle Routen für Rufbereitschaften und Einsätze
+func RegisterOnCallRoutes(api *echo.Group) {
+	api.GET("/on-call", handlers.GetOnCallDuties)
+	api.GET("/on-call/now", handlers.GetCurrentOnCall)
+	api.GET("/on-call/:id", handlers.GetOnCallDuty)
+	api.POST("/on-call", handlers.CreateOnCallDuty)
+	api.PUT("/on-call/:id", handlers.UpdateOnCallDuty)
+	api.DELETE("/on-call/:id", handlers.DeleteOnCallDuty)
+	api.POST("/on-call/:id/call-outs", handlers.CreateCallOut)
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterPayrollRoutes registriert alle Routen für den Lohnexport nach DATEV LODAS
+func RegisterPayrollRoutes(api *echo.Group) {
+	api.GET("/payroll/settings", handlers.GetPayrollSettings)
+	api.PUT("/payroll/settings", handlers.UpdatePayrollSettings)
+
+	api.GET("/payroll/wage-types", handlers.GetWageTypeMappings)
+	api.POST("/payroll/wage-types", handlers.CreateWageTypeMapping)
+	api.PUT("/payroll/wage-types/:id", handlers.UpdateWageTypeMapping)
+	api.DELETE("/payroll/wage-types/:id", handlers.DeleteWageTypeMapping)
+
+	api.GET("/payroll/preview", handlers.GetPayrollPreview)
+	api.GET("/payroll/exports", handlers.GetPayrollExports)
+	api.POST("/payroll/exports", handlers.CreatePayrollExport)
+	api.GET("/payroll/exports/:id/file", handlers.DownloadPayrollExport)
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterQualificationRoutes registriert alle Routen für Qualifikationen
+func RegisterQualificationRoutes(api *echo.Group) {
+	// Qualifikationskatalog
+	api.GET("/qualifications", handlers.GetQualifications)
+	api.POST("/qualifications", handlers.CreateQualification)
+	api.PUT("/qualifications/:id", handlers.UpdateQualification)
+	api.DELETE("/qualifications/:id", handlers.DeleteQualification)
+
+	// Berichte
+	api.GET("/qualifications/expiring", handlers.GetExpiringQualifications)
+	api.GET("/qualifications/under-qualified-shifts", handlers.GetUnderQualifiedShifts)
+
+	// Nachweise eines Benutzers
+	api.GET("/users/:id/qualifications", handlers.GetUserQualifications)
+	api.POST("/users/:id/qualifications", handlers.CreateUserQualification)
+	api.PUT("/users/:id/qualifications/:entry_id", handlers.UpdateUserQualification)
+	api.DELETE("/users/:id/qualifications/:entry_id", handlers.DeleteUserQualification)
+}
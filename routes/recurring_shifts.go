@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterRecurringShiftRoutes registriert alle RecurringShift-bezogenen API-Routen
+func RegisterRecurringShiftRoutes(api *echo.Group) {
+	// RecurringShift endpoints
+	api.GET("/recurring-shifts", handlers.GetRecurringShifts)
+	api.GET("/recurring-shifts/:id", handlers.GetRecurringShift)
+	api.POST("/recurring-shifts", handlers.CreateRecurringShift)
+	api.PUT("/recurring-shifts/:id", handlers.UpdateRecurringShift)
+	api.DELETE("/recurring-shifts/:id", handlers.DeleteRecurringShift)
+	api.POST("/recurring-shifts/:id/expand", handlers.ExpandRecurringShift)
+
+	// Vorkommen und Ausnahmen (EXDATE)
+	api.GET("/recurring-shifts/:id/occurrences", handlers.GetRecurringShiftOccurrences)
+	api.PUT("/recurring-shifts/:id/occurrences/:shift_id", handlers.UpdateRecurringShiftOccurrence)
+	api.DELETE("/recurring-shifts/:id/occurrences/:shift_id", handlers.DeleteRecurringShiftOccurrence)
+	api.POST("/recurring-shifts/:id/exceptions", handlers.CreateRecurringShiftException)
+	api.DELETE("/recurring-shifts/:id/exceptions/:exception_id", handlers.DeleteRecurringShiftException)
+}
@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterRecurringShiftRoutes(t *testing.T) {
+	// Test-Datenbank initialisieren
+	setupTestDB(t)
+	defer cleanupTestDB()
+
+	// Echo-Instanz für Tests erstellen
+	e := echo.New()
+	api := e.Group("/api")
+
+	// RecurringShift-Routen registrieren
+	RegisterRecurringShiftRoutes(api)
+
+	// Test-Fälle für alle registrierten Routen
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{"GET /api/recurring-shifts sollte registriert sein", http.MethodGet, "/api/recurring-shifts"},
+		{"GET /api/recurring-shifts/:id sollte registriert sein", http.MethodGet, "/api/recurring-shifts/1"},
+		{"POST /api/recurring-shifts sollte registriert sein", http.MethodPost, "/api/recurring-shifts"},
+		{"PUT /api/recurring-shifts/:id sollte registriert sein", http.MethodPut, "/api/recurring-shifts/1"},
+		{"DELETE /api/recurring-shifts/:id sollte registriert sein", http.MethodDelete, "/api/recurring-shifts/1"},
+		{"POST /api/recurring-shifts/:id/expand sollte registriert sein", http.MethodPost, "/api/recurring-shifts/1/expand"},
+		{"GET /api/recurring-shifts/:id/occurrences sollte registriert sein", http.MethodGet, "/api/recurring-shifts/1/occurrences"},
+		{"PUT /api/recurring-shifts/:id/occurrences/:shift_id sollte registriert sein", http.MethodPut, "/api/recurring-shifts/1/occurrences/1"},
+		{"DELETE /api/recurring-shifts/:id/occurrences/:shift_id sollte registriert sein", http.MethodDelete, "/api/recurring-shifts/1/occurrences/1"},
+		{"POST /api/recurring-shifts/:id/exceptions sollte registriert sein", http.MethodPost, "/api/recurring-shifts/1/exceptions"},
+		{"DELETE /api/recurring-shifts/:id/exceptions/:exception_id sollte registriert sein", http.MethodDelete, "/api/recurring-shifts/1/exceptions/1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			// Prüfen, dass die Route existiert und nicht als Methode abgelehnt wird
+			assert.NotEqual(t, http.StatusMethodNotAllowed, rec.Code,
+				"Route %s %s sollte registriert sein", tc.method, tc.path)
+			if rec.Code == http.StatusNotFound {
+				assert.NotContains(t, rec.Body.String(), "Not Found",
+					"Route %s %s sollte registriert sein", tc.method, tc.path)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterReportRoutes registriert alle Routen für Auswertungen
+func RegisterReportRoutes(api *echo.Group) {
+	api.GET("/reports/variances", handlers.GetVarianceReport)
+	api.GET("/reports/checklists", handlers.GetChecklistReport)
+
+	// Fairness bei unbeliebten Schichten und Kandidaten für eine Schicht
+	api.GET("/teams/:id/fairness", handlers.GetTeamFairness)
+	api.GET("/shifts/:id/candidates", handlers.GetShiftCandidates)
+}
@@ -10,14 +10,46 @@ import (
 func RegisterAPIRoutes(e *echo.Echo) {
 	api := e.Group("/api")
 
-	// Registriere alle Routen-Gruppen
+	// Allgemeine Routen (Health-Check, Zeitzone) sind ohne Organisation erreichbar
 	RegisterGeneralRoutes(api)
+
+	// Kalender-Abonnements sind per Token geschützt und bestimmen die Organisation selbst
+	RegisterCalendarFeedRoutes(api)
+
+	// CalDAV-Server mit eigener Anmeldung per API-Token; Kalender-Apps suchen ihn unter /.well-known/caldav
+	RegisterCalDAVRoutes(api)
+	e.Any("/.well-known/caldav", handlers.CalDAVWellKnown)
+
+	// Einladungen importierter Benutzer bestimmen die Organisation selbst
+	RegisterInvitationRoutes(api)
+
+	// Alle weiteren Routen sind auf die Organisation der Anfrage beschränkt
+	api.Use(handlers.TenantMiddleware)
+
+	// Registriere alle Routen-Gruppen
+	RegisterTenantRoutes(api)
 	RegisterUserRoutes(api)
 	RegisterShiftRoutes(api)
 	RegisterScheduleRoutes(api)
 	RegisterShiftTypeRoutes(api)
 	RegisterTeamRoutes(api)
 	RegisterShiftTemplateRoutes(api)
+	RegisterRecurringShiftRoutes(api)
+	RegisterHolidayRoutes(api)
+	RegisterSurchargeRoutes(api)
+	RegisterAbsenceRoutes(api)
+	RegisterTimeAccountRoutes(api)
+	RegisterContractRoutes(api)
+	RegisterQualificationRoutes(api)
+	RegisterLocationRoutes(api)
+	RegisterTimeEntryRoutes(api)
+	RegisterTimesheetRoutes(api)
+	RegisterReportRoutes(api)
+	RegisterPayrollRoutes(api)
+	RegisterOnCallRoutes(api)
+	RegisterChecklistRoutes(api)
+	RegisterCommentRoutes(api)
+	RegisterCalendarRoutes(api)
 
 	// Registriere benutzerdefinierte Error-Handler für API-Endpunkte
 	registerErrorHandlers(e)
@@ -15,4 +15,8 @@ func RegisterScheduleRoutes(api *echo.Group) {
 	api.POST("/schedules", handlers.CreateSchedule)
 	api.PUT("/schedules/:id", handlers.UpdateSchedule)
 	api.DELETE("/schedules/:id", handlers.DeleteSchedule)
+	api.GET("/schedules/:id/compliance", handlers.GetScheduleCompliance)
+	api.GET("/schedules/:id/grid", handlers.GetScheduleGrid)
+	api.GET("/schedules/:id/pdf", handlers.GetSchedulePDF)
+	api.POST("/schedules/:id/import", handlers.ImportSchedule)
 }
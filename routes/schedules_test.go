@@ -73,6 +73,12 @@ func TestRegisterScheduleRoutes(t *testing.T) {
 			path:     "/api/schedules/1",
 			expected: http.StatusOK,
 		},
+		{
+			name:     "GET /api/schedules/:id/compliance sollte registriert sein",
+			method:   http.MethodGet,
+			path:     "/api/schedules/1/compliance",
+			expected: http.StatusOK,
+		},
 		{
 			name:     "DELETE /api/schedules/:id sollte registriert sein",
 			method:   http.MethodDelete,
@@ -135,10 +141,10 @@ func TestRegisterScheduleRoutes_InvalidRoutes(t *testing.T) {
 			expected: http.StatusNotFound,
 		},
 		{
-			name:     "GET /api/schedules/active/invalid sollte 400 zurückgeben",
+			name:     "GET /api/schedules/active/invalid sollte 404 zurückgeben",
 			method:   http.MethodGet,
 			path:     "/api/schedules/active/invalid",
-			expected: http.StatusBadRequest,
+			expected: http.StatusNotFound,
 		},
 	}
 
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterSurchargeRoutes registriert alle Zuschlags-bezogenen API-Routen
+func RegisterSurchargeRoutes(api *echo.Group) {
+	// Zuschlagsregeln
+	api.GET("/surcharge-rules", handlers.GetSurchargeRules)
+	api.POST("/surcharge-rules", handlers.CreateSurchargeRule)
+	api.POST("/surcharge-rules/preset", handlers.ApplySurchargePreset)
+	api.PUT("/surcharge-rules/:id", handlers.UpdateSurchargeRule)
+	api.DELETE("/surcharge-rules/:id", handlers.DeleteSurchargeRule)
+
+	// Zuschläge je Schicht und Monatsauswertung je Benutzer
+	api.GET("/shifts/:id/surcharges", handlers.GetShiftSurcharges)
+	api.GET("/surcharges", handlers.GetSurchargeReport)
+}
@@ -22,4 +22,11 @@ func RegisterTeamRoutes(api *echo.Group) {
 	api.POST("/teams/:id/members", handlers.AddUserToTeam)
 	api.DELETE("/teams/:id/members/:user_id", handlers.RemoveUserFromTeam)
 	api.GET("/teams/:id/members", handlers.GetTeamMembers)
+
+	// Teamspezifische Planungsregeln
+	api.GET("/teams/:id/rules", handlers.GetTeamRules)
+	api.POST("/teams/:id/rules", handlers.CreateTeamRule)
+	api.GET("/teams/:id/rules/evaluate", handlers.EvaluateTeamRules)
+	api.PUT("/teams/:id/rules/:rule_id", handlers.UpdateTeamRule)
+	api.DELETE("/teams/:id/rules/:rule_id", handlers.DeleteTeamRule)
 }
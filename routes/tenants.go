@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterTenantRoutes registriert alle Routen für Organisation, Anmeldung und API-Tokens
+func RegisterTenantRoutes(api *echo.Group) {
+	api.GET("/auth/me", handlers.GetCurrentUser)
+	api.GET("/tenant", handlers.GetTenant)
+	api.PUT("/tenant", handlers.UpdateTenant)
+
+	// API-Tokens eines Benutzers
+	api.GET("/users/:id/api-tokens", handlers.GetUserAPITokens)
+	api.POST("/users/:id/api-tokens", handlers.CreateUserAPIToken)
+	api.DELETE("/users/:id/api-tokens/:token_id", handlers.DeleteUserAPIToken)
+}
@@ -17,9 +17,10 @@ func setupTestDB(t *testing.T) {
 	// Verwende In-Memory SQLite für Tests
 	database.DB, err = gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	assert.NoError(t, err)
+	assert.NoError(t, database.DB.Use(database.TenantGuard{}))
 
 	// Migration durchführen
-	err = database.DB.AutoMigrate(&models.User{}, &models.Shift{}, &models.Schedule{}, &models.Team{}, &models.ShiftType{})
+	err = database.DB.AutoMigrate(&models.User{}, &models.Shift{}, &models.Schedule{}, &models.Team{}, &models.ShiftType{}, &models.RecurringShift{}, &models.RecurringShiftException{}, &models.TeamRule{}, &models.CompanyHoliday{}, &models.SurchargeRule{}, &models.Absence{}, &models.AbsenceCreditRule{}, &models.TimeAccountCorrection{}, &models.EmploymentContract{}, &models.Qualification{}, &models.UserQualification{}, &models.Location{}, &models.Tenant{}, &models.APIToken{}, &models.TimeEntry{}, &models.TimeEntryBreak{}, &models.TimeEntryCorrection{}, &models.Timesheet{}, &models.TimesheetEvent{}, &models.WageTypeMapping{}, &models.PayrollSettings{}, &models.PayrollExport{}, &models.OnCallDuty{}, &models.ChecklistTemplate{}, &models.ShiftChecklist{}, &models.ShiftChecklistItem{}, &models.Comment{}, &models.CommentMention{}, &models.CommentRevision{}, &models.CalendarFeed{}, &models.CalendarSyncState{}, &models.UserInvitation{})
 	assert.NoError(t, err)
 }
 
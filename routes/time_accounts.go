@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterTimeAccountRoutes registriert alle Routen für Arbeitszeitkonten
+func RegisterTimeAccountRoutes(api *echo.Group) {
+	// Arbeitszeitkonto eines Benutzers
+	api.GET("/users/:id/time-account", handlers.GetUserTimeAccount)
+	api.GET("/users/:id/time-account/:month", handlers.GetUserTimeAccountMonth)
+
+	// Manuelle Korrekturen
+	api.GET("/users/:id/time-account/corrections", handlers.GetTimeAccountCorrections)
+	api.POST("/users/:id/time-account/corrections", handlers.CreateTimeAccountCorrection)
+	api.DELETE("/users/:id/time-account/corrections/:correction_id", handlers.DeleteTimeAccountCorrection)
+
+	// Jahresübersicht eines Teams
+	api.GET("/teams/:id/time-accounts", handlers.GetTeamTimeAccounts)
+}
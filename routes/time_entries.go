@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterTimeEntryRoutes registriert alle Routen für die Zeiterfassung
+func RegisterTimeEntryRoutes(api *echo.Group) {
+	api.GET("/time-entries", handlers.GetTimeEntries)
+	api.POST("/time-entries", handlers.CreateTimeEntry)
+
+	// Stempeln (Kommen, Gehen, Pausen) und laufende Zeiterfassung
+	api.GET("/time-entries/current", handlers.GetCurrentTimeEntry)
+	api.POST("/time-entries/clock-in", handlers.ClockIn)
+	api.POST("/time-entries/clock-out", handlers.ClockOut)
+	api.POST("/time-entries/break/start", handlers.StartBreak)
+	api.POST("/time-entries/break/end", handlers.EndBreak)
+
+	api.GET("/time-entries/:id", handlers.GetTimeEntry)
+	api.PUT("/time-entries/:id", handlers.CorrectTimeEntry)
+	api.DELETE("/time-entries/:id", handlers.DeleteTimeEntry)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"schichtplaner/handlers"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RegisterTimesheetRoutes registriert alle Routen für die monatliche Stundenzettel-Freigabe
+func RegisterTimesheetRoutes(api *echo.Group) {
+	api.GET("/timesheets", handlers.GetTimesheets)
+
+	api.GET("/users/:id/timesheets/:month", handlers.GetUserTimesheet)
+	api.POST("/users/:id/timesheets/:month/submit", handlers.SubmitTimesheet)
+	api.POST("/users/:id/timesheets/:month/approve", handlers.ApproveTimesheet)
+	api.POST("/users/:id/timesheets/:month/return", handlers.ReturnTimesheet)
+	api.POST("/users/:id/timesheets/:month/reopen", handlers.ReopenTimesheet)
+}
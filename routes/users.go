@@ -11,6 +11,8 @@ func RegisterUserRoutes(api *echo.Group) {
 	// User endpoints
 	api.GET("/users", handlers.GetUsers)
 	api.GET("/users/active", handlers.GetActiveUsers)
+	api.GET("/users/export", handlers.ExportUsers)
+	api.POST("/users/import", handlers.ImportUsers)
 	api.GET("/users/:id", handlers.GetUser)
 	api.POST("/users", handlers.CreateUser)
 	api.PUT("/users/:id", handlers.UpdateUser)
@@ -20,3 +22,9 @@ func RegisterUserRoutes(api *echo.Group) {
 	api.GET("/teams/:team_id/users", handlers.GetUsersByTeam)
 	api.GET("/users/without-team", handlers.GetUsersWithoutTeam)
 }
+
+// RegisterInvitationRoutes registriert das Annehmen von Einladungen. Eingeladene Benutzer haben noch kein API-Token;
+// die Route muss daher vor der Mandanten-Middleware registriert werden.
+func RegisterInvitationRoutes(api *echo.Group) {
+	api.POST("/invitations/accept", handlers.AcceptInvitation)
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APITokenPrefix kennzeichnet API-Tokens des Schichtplaners
+const APITokenPrefix = "sp_"
+
+// apiTokenDisplayLength ist die Anzahl der Zeichen, die zur Wiedererkennung eines Tokens gespeichert werden
+const apiTokenDisplayLength = 11
+
+// GenerateAPIToken erzeugt ein zufälliges API-Token und liefert Klartext, SHA-256-Hash und Anzeigepräfix
+func GenerateAPIToken() (token, hash, prefix string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("Token konnte nicht erzeugt werden: %w", err)
+	}
+	token = APITokenPrefix + hex.EncodeToString(buf)
+	return token, HashAPIToken(token), token[:apiTokenDisplayLength], nil
+}
+
+// HashAPIToken liefert den SHA-256-Hash eines API-Tokens als Hex-String
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(token)))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidateTenantSlug prüft, ob ein Kürzel als Subdomain verwendet werden kann (a-z, 0-9 und Bindestrich)
+func ValidateTenantSlug(slug string) error {
+	if slug == "" || len(slug) > 63 {
+		return fmt.Errorf("Kürzel muss zwischen 1 und 63 Zeichen lang sein")
+	}
+	if slug[0] == '-' || slug[len(slug)-1] == '-' {
+		return fmt.Errorf("Kürzel darf nicht mit einem Bindestrich beginnen oder enden")
+	}
+	for _, r := range slug {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return fmt.Errorf("Kürzel darf nur Kleinbuchstaben, Ziffern und Bindestriche enthalten")
+		}
+	}
+	return nil
+}
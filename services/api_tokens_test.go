@@ -0,0 +1,31 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAPIToken(t *testing.T) {
+	token, hash, prefix, err := GenerateAPIToken()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, APITokenPrefix))
+	assert.True(t, strings.HasPrefix(token, prefix))
+	assert.Equal(t, HashAPIToken(token), hash)
+	assert.NotContains(t, hash, token)
+
+	other, _, _, err := GenerateAPIToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+}
+
+func TestValidateTenantSlug(t *testing.T) {
+	assert.NoError(t, ValidateTenantSlug("nord"))
+	assert.NoError(t, ValidateTenantSlug("werk-2"))
+	assert.Error(t, ValidateTenantSlug(""))
+	assert.Error(t, ValidateTenantSlug("-nord"))
+	assert.Error(t, ValidateTenantSlug("Nord"))
+	assert.Error(t, ValidateTenantSlug("nord.example"))
+	assert.Error(t, ValidateTenantSlug(strings.Repeat("a", 64)))
+}
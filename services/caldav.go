@@ -0,0 +1,298 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// XML-Namensräume von WebDAV, CalDAV und den von Kalender-Apps verwendeten Erweiterungen
+const (
+	DAVNamespace            = "DAV:"
+	CalDAVNamespace         = "urn:ietf:params:xml:ns:caldav"
+	CalendarServerNamespace = "http://calendarserver.org/ns/"
+	AppleICalNamespace      = "http://apple.com/ns/ical/"
+)
+
+// CalendarSyncTokenPrefix kennzeichnet Sync-Tokens (RFC 6578 verlangt eine URI)
+const CalendarSyncTokenPrefix = "urn:schichtplaner:sync:"
+
+// Arten von WebDAV-Anfragen nach dem Wurzelelement des Rumpfs
+const (
+	DAVRequestPropfind         = "propfind"
+	DAVRequestCalendarQuery    = "calendar-query"
+	DAVRequestCalendarMultiget = "calendar-multiget"
+	DAVRequestSyncCollection   = "sync-collection"
+)
+
+// davPrefixes sind die Präfixe der bekannten Namensräume in Antworten
+var davPrefixes = map[string]string{
+	DAVNamespace:            "d",
+	CalDAVNamespace:         "c",
+	CalendarServerNamespace: "cs",
+	AppleICalNamespace:      "ical",
+}
+
+// DAVRequest ist der ausgewertete Rumpf einer PROPFIND- oder REPORT-Anfrage
+type DAVRequest struct {
+	Kind      string     // Wurzelelement, z.B. propfind oder calendar-query
+	AllProp   bool       // Alle Eigenschaften (allprop, propname oder leerer Rumpf)
+	Props     []xml.Name // Angefragte Eigenschaften
+	Hrefs     []string   // Angefragte Objekte (calendar-multiget)
+	SyncToken string     // Letztes Sync-Token des Clients (sync-collection)
+	Start     *time.Time // Zeitraumfilter (calendar-query)
+	End       *time.Time
+}
+
+// DAVProperty ist eine Eigenschaft einer Ressource; Value enthält bereits maskiertes XML
+type DAVProperty struct {
+	Name  xml.Name
+	Value string
+}
+
+// DAVResponse ist eine Ressource in einer Multistatus-Antwort. Ist Status gesetzt (z.B. 404 für gelöschte Objekte),
+// wird nur der Status ohne Eigenschaften ausgegeben.
+type DAVResponse struct {
+	Href       string
+	Properties []DAVProperty
+	Missing    []xml.Name // Angefragte, aber unbekannte Eigenschaften
+	Status     int
+}
+
+// davNode ist ein beliebiges XML-Element beim Einlesen des Anfragerumpfs
+type davNode struct {
+	XMLName xml.Name
+	Attrs   []xml.Attr `xml:",any,attr"`
+	Nodes   []davNode  `xml:",any"`
+	Text    string     `xml:",chardata"`
+}
+
+// ParseDAVRequest wertet den Rumpf einer PROPFIND- oder REPORT-Anfrage aus. Ein leerer Rumpf entspricht allprop.
+func ParseDAVRequest(body []byte) (DAVRequest, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return DAVRequest{Kind: DAVRequestPropfind, AllProp: true}, nil
+	}
+
+	var root davNode
+	if err := xml.NewDecoder(bytes.NewReader(body)).Decode(&root); err != nil && err != io.EOF {
+		return DAVRequest{}, fmt.Errorf("Ungültiges XML: %w", err)
+	}
+	request := DAVRequest{Kind: root.XMLName.Local}
+	switch request.Kind {
+	case DAVRequestPropfind, DAVRequestCalendarQuery, DAVRequestCalendarMultiget, DAVRequestSyncCollection:
+	default:
+		return DAVRequest{}, fmt.Errorf("Nicht unterstützte Anfrage: %s", request.Kind)
+	}
+
+	for _, node := range root.Nodes {
+		switch node.XMLName.Local {
+		case "allprop", "propname":
+			request.AllProp = true
+		case "prop":
+			for _, prop := range node.Nodes {
+				request.Props = append(request.Props, prop.XMLName)
+			}
+		case "href":
+			request.Hrefs = append(request.Hrefs, strings.TrimSpace(node.Text))
+		case "sync-token":
+			request.SyncToken = strings.TrimSpace(node.Text)
+		case "filter":
+			if timeRange := findDAVNode(node, "time-range"); timeRange != nil {
+				for _, attr := range timeRange.Attrs {
+					value, err := time.Parse("20060102T150405Z", attr.Value)
+					if err != nil {
+						return DAVRequest{}, fmt.Errorf("Ungültiger Zeitraum: %s", attr.Value)
+					}
+					switch attr.Name.Local {
+					case "start":
+						request.Start = &value
+					case "end":
+						request.End = &value
+					}
+				}
+			}
+		}
+	}
+	if len(request.Props) == 0 {
+		request.AllProp = true
+	}
+	return request, nil
+}
+
+// findDAVNode sucht das erste Element mit dem Namen local unterhalb von node
+func findDAVNode(node davNode, local string) *davNode {
+	for i := range node.Nodes {
+		if node.Nodes[i].XMLName.Local == local {
+			return &node.Nodes[i]
+		}
+		if found := findDAVNode(node.Nodes[i], local); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// SelectDAVProperties wählt die angefragten Eigenschaften aus available aus und liefert die unbekannten
+func SelectDAVProperties(request DAVRequest, available []DAVProperty) ([]DAVProperty, []xml.Name) {
+	if request.AllProp {
+		return available, nil
+	}
+	found := make([]DAVProperty, 0, len(request.Props))
+	var missing []xml.Name
+	for _, name := range request.Props {
+		matched := false
+		for _, property := range available {
+			if property.Name == name {
+				found = append(found, property)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			missing = append(missing, name)
+		}
+	}
+	return found, missing
+}
+
+// WantsDAVProperty prüft, ob die Anfrage die Eigenschaft name enthält (bei allprop immer)
+func WantsDAVProperty(request DAVRequest, name xml.Name) bool {
+	if request.AllProp {
+		return true
+	}
+	for _, prop := range request.Props {
+		if prop == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DAVMultistatus erzeugt eine Multistatus-Antwort (RFC 4918); syncToken wird nur ausgegeben, wenn gesetzt
+func DAVMultistatus(responses []DAVResponse, syncToken string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>` + "\n")
+	b.WriteString(`<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav" ` +
+		`xmlns:cs="http://calendarserver.org/ns/" xmlns:ical="http://apple.com/ns/ical/">`)
+	for _, response := range responses {
+		b.WriteString("<d:response><d:href>" + DAVText(response.Href) + "</d:href>")
+		if response.Status != 0 {
+			b.WriteString("<d:status>" + davStatus(response.Status) + "</d:status>")
+		}
+		if response.Status == 0 || len(response.Properties) > 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, property := range response.Properties {
+				b.WriteString(davElement(property.Name, property.Value))
+			}
+			b.WriteString("</d:prop><d:status>" + davStatus(http.StatusOK) + "</d:status></d:propstat>")
+		}
+		if len(response.Missing) > 0 {
+			b.WriteString("<d:propstat><d:prop>")
+			for _, name := range response.Missing {
+				b.WriteString(davElement(name, ""))
+			}
+			b.WriteString("</d:prop><d:status>" + davStatus(http.StatusNotFound) + "</d:status></d:propstat>")
+		}
+		b.WriteString("</d:response>")
+	}
+	if syncToken != "" {
+		b.WriteString("<d:sync-token>" + DAVText(syncToken) + "</d:sync-token>")
+	}
+	b.WriteString("</d:multistatus>")
+	return b.String()
+}
+
+// DAVError erzeugt den Rumpf einer Fehlerantwort mit der verletzten Vorbedingung (z.B. valid-sync-token)
+func DAVError(condition xml.Name) string {
+	return `<?xml version="1.0" encoding="utf-8"?>` + "\n" +
+		`<d:error xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">` + davElement(condition, "") + `</d:error>`
+}
+
+// DAVText maskiert Text für XML
+func DAVText(value string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
+
+// davElement erzeugt ein Element mit bereits maskiertem Inhalt; unbekannte Namensräume werden am Element deklariert
+func davElement(name xml.Name, value string) string {
+	tag, declaration := name.Local, ""
+	if prefix, ok := davPrefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else {
+		declaration = ` xmlns="` + DAVText(name.Space) + `"`
+	}
+	if value == "" {
+		return "<" + tag + declaration + "/>"
+	}
+	return "<" + tag + declaration + ">" + value + "</" + tag + ">"
+}
+
+// davStatus formatiert eine Statuszeile, z.B. HTTP/1.1 200 OK
+func davStatus(code int) string {
+	return fmt.Sprintf("HTTP/1.1 %d %s", code, http.StatusText(code))
+}
+
+// CalendarObjectName liefert den Dateinamen eines Termins in einem CalDAV-Kalender, z.B. shift-1-42.ics
+func CalendarObjectName(event models.CalendarEvent) string {
+	name, _, _ := strings.Cut(event.UID, "@")
+	return name + ".ics"
+}
+
+// CalendarObjectETag liefert das ETag eines Kalenderobjekts (Hash des Inhalts)
+func CalendarObjectETag(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// CalendarEventInRange prüft, ob ein Termin den Zeitraum [start, end) berührt; fehlende Grenzen sind offen
+func CalendarEventInRange(event models.CalendarEvent, start, end *time.Time) bool {
+	if start != nil && !event.End.After(*start) {
+		return false
+	}
+	return end == nil || event.Start.Before(*end)
+}
+
+// CalendarSyncToken bildet das Sync-Token eines Kalenders aus den ETags seiner Objekte (Pfad → ETag).
+// Gleicher Inhalt ergibt dasselbe Token.
+func CalendarSyncToken(etags map[string]string) string {
+	hrefs := make([]string, 0, len(etags))
+	for href := range etags {
+		hrefs = append(hrefs, href)
+	}
+	sort.Strings(hrefs)
+
+	hash := sha256.New()
+	for _, href := range hrefs {
+		fmt.Fprintf(hash, "%s %s\n", href, etags[href])
+	}
+	return CalendarSyncTokenPrefix + hex.EncodeToString(hash.Sum(nil)[:16])
+}
+
+// CalendarSyncChanges vergleicht zwei Stände eines Kalenders (Pfad → ETag) und liefert die neuen oder geänderten
+// sowie die entfernten Objekte, jeweils sortiert
+func CalendarSyncChanges(previous, current map[string]string) (changed, removed []string) {
+	for href, etag := range current {
+		if previous[href] != etag {
+			changed = append(changed, href)
+		}
+	}
+	for href := range previous {
+		if _, ok := current[href]; !ok {
+			removed = append(removed, href)
+		}
+	}
+	sort.Strings(changed)
+	sort.Strings(removed)
+	return changed, removed
+}
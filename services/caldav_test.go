@@ -0,0 +1,110 @@
+package services
+
+import (
+	"encoding/xml"
+	"net/http"
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDAVRequest(t *testing.T) {
+	request, err := ParseDAVRequest(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, DAVRequestPropfind, request.Kind)
+	assert.True(t, request.AllProp)
+
+	request, err = ParseDAVRequest([]byte(`<?xml version="1.0"?>
+<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">
+  <D:prop><D:getetag/><C:calendar-data/></D:prop>
+  <C:filter><C:comp-filter name="VCALENDAR"><C:comp-filter name="VEVENT">
+    <C:time-range start="20240301T000000Z" end="20240401T000000Z"/>
+  </C:comp-filter></C:comp-filter></C:filter>
+</C:calendar-query>`))
+	assert.NoError(t, err)
+	assert.Equal(t, DAVRequestCalendarQuery, request.Kind)
+	assert.False(t, request.AllProp)
+	assert.Equal(t, []xml.Name{{Space: DAVNamespace, Local: "getetag"}, {Space: CalDAVNamespace, Local: "calendar-data"}}, request.Props)
+	if assert.NotNil(t, request.Start) && assert.NotNil(t, request.End) {
+		assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), *request.Start)
+		assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), *request.End)
+	}
+
+	request, err = ParseDAVRequest([]byte(`<d:sync-collection xmlns:d="DAV:"><d:sync-token>urn:schichtplaner:sync:abc</d:sync-token>` +
+		`<d:sync-level>1</d:sync-level><d:prop><d:getetag/></d:prop></d:sync-collection>`))
+	assert.NoError(t, err)
+	assert.Equal(t, DAVRequestSyncCollection, request.Kind)
+	assert.Equal(t, "urn:schichtplaner:sync:abc", request.SyncToken)
+
+	_, err = ParseDAVRequest([]byte(`<d:propertyupdate xmlns:d="DAV:"/>`))
+	assert.Error(t, err)
+	_, err = ParseDAVRequest([]byte(`<d:propfind`))
+	assert.Error(t, err)
+}
+
+func TestDAVMultistatus(t *testing.T) {
+	request := DAVRequest{Kind: DAVRequestPropfind, Props: []xml.Name{
+		{Space: DAVNamespace, Local: "displayname"},
+		{Space: "http://example.com/ns", Local: "unbekannt"},
+	}}
+	available := []DAVProperty{
+		{Name: xml.Name{Space: DAVNamespace, Local: "resourcetype"}, Value: "<d:collection/>"},
+		{Name: xml.Name{Space: DAVNamespace, Local: "displayname"}, Value: DAVText("Schichten Anna & Ben")},
+	}
+	found, missing := SelectDAVProperties(request, available)
+	assert.Len(t, found, 1)
+	assert.Len(t, missing, 1)
+
+	body := DAVMultistatus([]DAVResponse{
+		{Href: "/api/caldav/calendars/user-1/", Properties: found, Missing: missing},
+		{Href: "/api/caldav/calendars/user-1/shift-1-2.ics", Status: http.StatusNotFound},
+	}, "urn:schichtplaner:sync:abc")
+	assert.Contains(t, body, "<d:displayname>Schichten Anna &amp; Ben</d:displayname>")
+	assert.Contains(t, body, `<unbekannt xmlns="http://example.com/ns"/></d:prop><d:status>HTTP/1.1 404 Not Found</d:status>`)
+	assert.Contains(t, body, "<d:href>/api/caldav/calendars/user-1/shift-1-2.ics</d:href><d:status>HTTP/1.1 404 Not Found</d:status></d:response>")
+	assert.Contains(t, body, "<d:sync-token>urn:schichtplaner:sync:abc</d:sync-token>")
+
+	var parsed struct {
+		Responses []struct {
+			Href string `xml:"href"`
+		} `xml:"response"`
+	}
+	assert.NoError(t, xml.Unmarshal([]byte(body), &parsed))
+	assert.Len(t, parsed.Responses, 2)
+}
+
+func TestCalendarSync(t *testing.T) {
+	event := models.CalendarEvent{
+		UID:          "shift-1-42@schichtplaner",
+		Start:        time.Date(2024, 3, 4, 5, 0, 0, 0, time.UTC),
+		End:          time.Date(2024, 3, 4, 13, 0, 0, 0, time.UTC),
+		Summary:      "Frühschicht",
+		LastModified: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+	}
+	assert.Equal(t, "shift-1-42.ics", CalendarObjectName(event))
+
+	data := CalendarObject(event)
+	assert.NotContains(t, data, "METHOD:")
+	assert.Contains(t, data, "DTSTAMP:20240301T090000Z")
+	assert.Equal(t, CalendarObjectETag(data), CalendarObjectETag(CalendarObject(event)))
+
+	start := time.Date(2024, 3, 4, 13, 0, 0, 0, time.UTC)
+	assert.False(t, CalendarEventInRange(event, &start, nil))
+	start = start.Add(-time.Minute)
+	assert.True(t, CalendarEventInRange(event, &start, nil))
+
+	previous := map[string]string{"/a.ics": `"1"`, "/b.ics": `"2"`}
+	current := map[string]string{"/a.ics": `"1"`, "/b.ics": `"3"`, "/c.ics": `"4"`}
+	assert.Equal(t, CalendarSyncToken(previous), CalendarSyncToken(map[string]string{"/b.ics": `"2"`, "/a.ics": `"1"`}))
+	assert.NotEqual(t, CalendarSyncToken(previous), CalendarSyncToken(current))
+
+	changed, removed := CalendarSyncChanges(previous, current)
+	assert.Equal(t, []string{"/b.ics", "/c.ics"}, changed)
+	assert.Empty(t, removed)
+	changed, removed = CalendarSyncChanges(current, map[string]string{"/a.ics": `"1"`})
+	assert.Empty(t, changed)
+	assert.Equal(t, []string{"/b.ics", "/c.ics"}, removed)
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"schichtplaner/models"
+)
+
+// CalendarFeedTokenPrefix kennzeichnet Tokens von Kalender-Abonnements
+const CalendarFeedTokenPrefix = "spc_"
+
+// icsLineLimit ist die maximale Zeilenlänge in Oktetten nach RFC 5545 (ohne CRLF)
+const icsLineLimit = 75
+
+// GenerateCalendarFeedToken erzeugt ein zufälliges Token für ein Kalender-Abonnement und liefert Klartext,
+// SHA-256-Hash und Anzeigepräfix
+func GenerateCalendarFeedToken() (token, hash, prefix string, err error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("Token konnte nicht erzeugt werden: %w", err)
+	}
+	token = CalendarFeedTokenPrefix + hex.EncodeToString(buf)
+	return token, HashAPIToken(token), token[:apiTokenDisplayLength], nil
+}
+
+// ShiftCalendarEvent erzeugt den Kalendertermin einer Schicht. Schichttyp, Schichtplan und Benutzer sollten geladen
+// sein; locations liefert den Standort. withUser ergänzt den Namen des Benutzers im Titel (Team- und Planansicht).
+func ShiftCalendarEvent(tenantID uint, shift models.Shift, locations map[uint]models.Location, withUser bool) models.CalendarEvent {
+	summary := "Schicht"
+	if shift.ShiftType.ID != 0 && shift.ShiftType.Name != "" {
+		summary = shift.ShiftType.Name
+	}
+	if withUser && shift.User.Name != "" {
+		summary += " – " + shift.User.Name
+	}
+
+	description := make([]string, 0, 3)
+	if shift.Schedule.Name != "" {
+		description = append(description, "Schichtplan: "+shift.Schedule.Name)
+	}
+	if shift.BreakTime > 0 {
+		description = append(description, fmt.Sprintf("Pause: %d Minuten", shift.BreakTime))
+	}
+	if text := strings.TrimSpace(shift.Description); text != "" {
+		description = append(description, text)
+	}
+
+	event := models.CalendarEvent{
+		UID:          fmt.Sprintf("shift-%d-%d@schichtplaner", tenantID, shift.ID),
+		Start:        shift.StartTime,
+		End:          shift.EndTime,
+		Summary:      summary,
+		Description:  strings.Join(description, "\n"),
+		Color:        shift.ShiftType.Color,
+		Category:     shift.ShiftType.Name,
+		Cancelled:    !shift.IsActive,
+		LastModified: shift.UpdatedAt,
+	}
+	if location, ok := locations[EffectiveLocationID(shift)]; ok {
+		event.Location = locationAddress(location)
+	}
+	return event
+}
+
+// AbsenceCalendarEvent erzeugt den ganztägigen Kalendertermin einer Abwesenheit; withUser ergänzt den Namen des Benutzers
+func AbsenceCalendarEvent(tenantID uint, absence models.Absence, withUser bool) models.CalendarEvent {
+	summary := models.AbsenceTypeLabel(absence.Type)
+	if withUser && absence.User.Name != "" {
+		summary += " – " + absence.User.Name
+	}
+	return models.CalendarEvent{
+		UID:          fmt.Sprintf("absence-%d-%d@schichtplaner", tenantID, absence.ID),
+		Start:        absence.StartDate,
+		End:          absence.EndDate.AddDate(0, 0, 1),
+		AllDay:       true,
+		Summary:      summary,
+		Description:  strings.TrimSpace(absence.Note),
+		Category:     models.AbsenceTypeLabel(absence.Type),
+		LastModified: absence.UpdatedAt,
+	}
+}
+
+// ICSCalendar erzeugt eine iCalendar-Datei (RFC 5545) mit den Terminen; name wird als Kalendername angezeigt (optional).
+// Zeiten werden in UTC ausgegeben, ganztägige Termine als Datum.
+func ICSCalendar(name string, events []models.CalendarEvent, now time.Time) string {
+	return icsCalendar(name, "PUBLISH", events, now)
+}
+
+// CalendarObject erzeugt das Kalenderobjekt eines einzelnen Termins für CalDAV (RFC 4791, ohne METHOD).
+// Der Inhalt hängt nur vom Termin ab und eignet sich daher als Grundlage für das ETag.
+func CalendarObject(event models.CalendarEvent) string {
+	return icsCalendar("", "", []models.CalendarEvent{event}, event.LastModified)
+}
+
+// icsCalendar erzeugt eine iCalendar-Datei; method und name werden nur ausgegeben, wenn sie gesetzt sind
+func icsCalendar(name, method string, events []models.CalendarEvent, now time.Time) string {
+	var b strings.Builder
+	line := func(value string) {
+		b.WriteString(foldICSLine(value))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Schichtplaner//Schichtplaner//DE")
+	line("CALSCALE:GREGORIAN")
+	if method != "" {
+		line("METHOD:" + method)
+	}
+	if name != "" {
+		line("X-WR-CALNAME:" + escapeICSText(name))
+	}
+	for _, event := range events {
+		stamp := event.LastModified
+		if stamp.IsZero() {
+			stamp = now
+		}
+
+		line("BEGIN:VEVENT")
+		line("UID:" + event.UID)
+		line("DTSTAMP:" + icsDateTime(stamp))
+		line("LAST-MODIFIED:" + icsDateTime(stamp))
+		if event.AllDay {
+			line("DTSTART;VALUE=DATE:" + event.Start.Format("20060102"))
+			line("DTEND;VALUE=DATE:" + event.End.Format("20060102"))
+			line("TRANSP:TRANSPARENT")
+		} else {
+			line("DTSTART:" + icsDateTime(event.Start))
+			line("DTEND:" + icsDateTime(event.End))
+		}
+		line("SUMMARY:" + escapeICSText(event.Summary))
+		if event.Description != "" {
+			line("DESCRIPTION:" + escapeICSText(event.Description))
+		}
+		if event.Location != "" {
+			line("LOCATION:" + escapeICSText(event.Location))
+		}
+		if event.Category != "" {
+			line("CATEGORIES:" + escapeICSText(event.Category))
+		}
+		if event.Color != "" {
+			line("X-SCHICHTPLANER-COLOR:" + event.Color)
+		}
+		if event.Cancelled {
+			line("STATUS:CANCELLED")
+		} else {
+			line("STATUS:CONFIRMED")
+		}
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return b.String()
+}
+
+// icsDateTime formatiert einen Zeitpunkt als UTC-Datum mit Uhrzeit (z.B. 20240304T050000Z)
+func icsDateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeICSText maskiert Backslash, Semikolon, Komma und Zeilenumbrüche in Textwerten
+func escapeICSText(value string) string {
+	value = strings.ReplaceAll(value, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`).Replace(value)
+}
+
+// foldICSLine bricht Zeilen nach höchstens 75 Oktetten um, ohne UTF-8-Zeichen zu trennen;
+// Folgezeilen beginnen mit einem Leerzeichen
+func foldICSLine(value string) string {
+	if len(value) <= icsLineLimit {
+		return value
+	}
+	var b strings.Builder
+	limit := icsLineLimit
+	for len(value) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(value[cut]) {
+			cut--
+		}
+		b.WriteString(value[:cut])
+		b.WriteString("\r\n ")
+		value = value[cut:]
+		limit = icsLineLimit - 1 // Das führende Leerzeichen zählt mit
+	}
+	b.WriteString(value)
+	return b.String()
+}
+
+// locationAddress liefert Name und Anschrift eines Standorts in einer Zeile
+func locationAddress(location models.Location) string {
+	parts := []string{location.Name}
+	if location.Street != "" {
+		parts = append(parts, location.Street)
+	}
+	if city := strings.TrimSpace(location.PostalCode + " " + location.City); city != "" {
+		parts = append(parts, city)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestICSCalendar(t *testing.T) {
+	locationID := uint(2)
+	shift := models.Shift{
+		Base:        models.Base{ID: 7, UpdatedAt: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)},
+		User:        models.User{Name: "Anna"},
+		ShiftType:   models.ShiftType{Base: models.Base{ID: 1}, Name: "Frühschicht", Color: "#F59E0B"},
+		Schedule:    models.Schedule{Name: "März; KW 10"},
+		LocationID:  &locationID,
+		StartTime:   time.Date(2024, 3, 4, 5, 0, 0, 0, time.UTC),
+		EndTime:     time.Date(2024, 3, 4, 13, 30, 0, 0, time.UTC),
+		BreakTime:   30,
+		Description: "Bitte Schlüssel mitnehmen, danke",
+		IsActive:    true,
+	}
+	locations := map[uint]models.Location{locationID: {Name: "Filiale Mitte", Street: "Hauptstr. 1", PostalCode: "10115", City: "Berlin"}}
+	absence := models.Absence{
+		Base:      models.Base{ID: 3},
+		User:      models.User{Name: "Anna"},
+		Type:      models.AbsenceTypeVacation,
+		StartDate: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	events := []models.CalendarEvent{ShiftCalendarEvent(1, shift, locations, true), AbsenceCalendarEvent(1, absence, false)}
+	ics := ICSCalendar("Schichten Anna", events, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC))
+
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+	assert.Contains(t, ics, "UID:shift-1-7@schichtplaner\r\n")
+	assert.Contains(t, ics, "DTSTART:20240304T050000Z\r\n")
+	assert.Contains(t, ics, "DTEND:20240304T133000Z\r\n")
+	assert.Contains(t, ics, "SUMMARY:Frühschicht – Anna\r\n")
+	unfolded := strings.ReplaceAll(ics, "\r\n ", "")
+	assert.Contains(t, unfolded, `DESCRIPTION:Schichtplan: März\; KW 10\nPause: 30 Minuten\nBitte Schlüssel mitnehmen\, danke`)
+	assert.Contains(t, ics, `LOCATION:Filiale Mitte\, Hauptstr. 1\, 10115 Berlin`)
+	assert.Contains(t, ics, "X-SCHICHTPLANER-COLOR:#F59E0B\r\n")
+	assert.Contains(t, ics, "UID:absence-1-3@schichtplaner\r\n")
+	assert.Contains(t, ics, "DTSTART;VALUE=DATE:20240311\r\n")
+	assert.Contains(t, ics, "DTEND;VALUE=DATE:20240316\r\n")
+	assert.Contains(t, ics, "SUMMARY:Urlaub\r\n")
+
+	// Keine Zeile ist länger als 75 Oktette
+	for _, line := range strings.Split(ics, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+}
+
+func TestFoldICSLine(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("ä", 60)
+	folded := foldICSLine(line)
+	parts := strings.Split(folded, "\r\n ")
+	assert.Greater(t, len(parts), 1)
+	assert.Equal(t, line, strings.Join(parts, ""))
+	for _, part := range parts {
+		assert.LessOrEqual(t, len(part), 75)
+		assert.True(t, strings.ToValidUTF8(part, "?") == part)
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// NormalizeChecklistItems entfernt Leerzeichen und leere Aufgaben aus einer Vorlage
+func NormalizeChecklistItems(items []string) []string {
+	normalized := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			normalized = append(normalized, item)
+		}
+	}
+	return normalized
+}
+
+// MissingShiftChecklists liefert die Checklisten, die für eine Schicht aus den Vorlagen ihres Schichttyps noch
+// anzulegen sind. Bereits angelegte Checklisten bleiben unverändert; inaktive Vorlagen werden übersprungen.
+func MissingShiftChecklists(shift models.Shift, templates []models.ChecklistTemplate, existing []models.ShiftChecklist) []models.ShiftChecklist {
+	created := make(map[uint]bool)
+	for _, checklist := range existing {
+		created[checklist.TemplateID] = true
+	}
+
+	missing := make([]models.ShiftChecklist, 0)
+	for _, template := range templates {
+		if !template.IsActive || created[template.ID] {
+			continue
+		}
+		checklist := models.ShiftChecklist{ShiftID: shift.ID, TemplateID: template.ID, Name: template.Name}
+		for i, title := range template.Items {
+			checklist.Items = append(checklist.Items, models.ShiftChecklistItem{Position: i + 1, Title: title})
+		}
+		missing = append(missing, checklist)
+	}
+	return missing
+}
+
+// IncompleteChecklists fasst die unvollständigen Checklisten je Kalendertag und Standort zusammen. Schichten zählen
+// zum Kalendertag ihres Beginns in der Zeitzone des Standorts; Benutzer und Schichtplan müssen geladen sein.
+// Schichten ohne Standort erscheinen unter location_id 0 in der Zeitzone der Organisation.
+func IncompleteChecklists(shifts []models.Shift, checklists []models.ShiftChecklist, locations map[uint]models.Location, now time.Time) []models.ChecklistDayReport {
+	byShift := make(map[uint][]models.ShiftChecklist)
+	for _, checklist := range checklists {
+		byShift[checklist.ShiftID] = append(byShift[checklist.ShiftID], checklist)
+	}
+
+	type reportKey struct {
+		date       time.Time
+		locationID uint
+	}
+	reports := make(map[reportKey]*models.ChecklistDayReport)
+	for _, shift := range shifts {
+		locationID := EffectiveLocationID(shift)
+		location, ok := locations[locationID]
+		if !ok {
+			location = models.Location{Name: "Ohne Standort"}
+		}
+
+		for _, checklist := range byShift[shift.ID] {
+			if checklist.IsComplete() {
+				continue
+			}
+			local := shift.StartTime.In(location.TimeLocation())
+			key := reportKey{date: date(local.Year(), local.Month(), local.Day()), locationID: locationID}
+			if reports[key] == nil {
+				reports[key] = &models.ChecklistDayReport{Date: key.date, LocationID: locationID, LocationName: location.Name}
+			}
+			reports[key].Checklists = append(reports[key].Checklists, models.IncompleteChecklist{
+				ChecklistID: checklist.ID,
+				Name:        checklist.Name,
+				ShiftID:     shift.ID,
+				UserID:      shift.UserID,
+				UserName:    shift.User.Name,
+				StartTime:   shift.StartTime,
+				EndTime:     shift.EndTime,
+				OpenItems:   checklist.OpenItems(),
+				TotalItems:  len(checklist.Items),
+				Overdue:     !shift.EndTime.After(now),
+			})
+		}
+	}
+
+	result := make([]models.ChecklistDayReport, 0, len(reports))
+	for _, report := range reports {
+		sort.SliceStable(report.Checklists, func(i, j int) bool {
+			return report.Checklists[i].StartTime.Before(report.Checklists[j].StartTime)
+		})
+		result = append(result, *report)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].Date.Equal(result[j].Date) {
+			return result[i].Date.Before(result[j].Date)
+		}
+		return result[i].LocationName < result[j].LocationName
+	})
+	return result
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMissingShiftChecklists(t *testing.T) {
+	shift := models.Shift{Base: models.Base{ID: 3}}
+	templates := []models.ChecklistTemplate{
+		{Base: models.Base{ID: 1}, Name: "Schließdienst", Items: []string{"Kassensturz", "Alarmanlage scharf schalten"}, IsActive: true},
+		{Base: models.Base{ID: 2}, Name: "Hygiene", Items: []string{"Kühlschrank prüfen"}, IsActive: true},
+		{Base: models.Base{ID: 4}, Name: "Alt", Items: []string{"Veraltet"}},
+	}
+	existing := []models.ShiftChecklist{{ShiftID: 3, TemplateID: 2}}
+
+	missing := MissingShiftChecklists(shift, templates, existing)
+	if assert.Len(t, missing, 1) {
+		assert.Equal(t, uint(1), missing[0].TemplateID)
+		assert.Equal(t, "Schließdienst", missing[0].Name)
+		if assert.Len(t, missing[0].Items, 2) {
+			assert.Equal(t, 2, missing[0].Items[1].Position)
+			assert.Equal(t, "Alarmanlage scharf schalten", missing[0].Items[1].Title)
+		}
+	}
+
+	assert.Equal(t, []string{"Kassensturz", "Alarm"}, NormalizeChecklistItems([]string{" Kassensturz ", "", "Alarm"}))
+}
+
+func TestIncompleteChecklists(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	locationID := uint(5)
+	done := time.Date(2024, 3, 4, 21, 0, 0, 0, time.UTC)
+	shifts := []models.Shift{
+		{Base: models.Base{ID: 1}, UserID: 7, User: models.User{Name: "Anna"}, LocationID: &locationID,
+			StartTime: time.Date(2024, 3, 4, 23, 30, 0, 0, time.UTC), EndTime: time.Date(2024, 3, 5, 6, 0, 0, 0, time.UTC)},
+		{Base: models.Base{ID: 2}, UserID: 8, User: models.User{Name: "Ben"},
+			StartTime: time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 3, 4, 22, 0, 0, 0, time.UTC)},
+	}
+	checklists := []models.ShiftChecklist{
+		{Base: models.Base{ID: 10}, ShiftID: 1, Name: "Schließdienst", Items: []models.ShiftChecklistItem{{Title: "Kasse"}, {Title: "Alarm", CompletedAt: &done}}},
+		{Base: models.Base{ID: 11}, ShiftID: 2, Name: "Schließdienst", Items: []models.ShiftChecklistItem{{Title: "Kasse", CompletedAt: &done}}},
+	}
+	locations := map[uint]models.Location{locationID: {Base: models.Base{ID: locationID}, Name: "Filiale Mitte", TimeZone: "Europe/Berlin"}}
+
+	reports := IncompleteChecklists(shifts, checklists, locations, time.Date(2024, 3, 5, 12, 0, 0, 0, berlin))
+	if assert.Len(t, reports, 1) {
+		// 23:30 Uhr UTC ist bereits der 05.03. in Berlin
+		assert.Equal(t, date(2024, 3, 5), reports[0].Date)
+		assert.Equal(t, "Filiale Mitte", reports[0].LocationName)
+		if assert.Len(t, reports[0].Checklists, 1) {
+			assert.Equal(t, 1, reports[0].Checklists[0].OpenItems)
+			assert.Equal(t, 2, reports[0].Checklists[0].TotalItems)
+			assert.Equal(t, "Anna", reports[0].Checklists[0].UserName)
+			assert.True(t, reports[0].Checklists[0].Overdue)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"schichtplaner/models"
+)
+
+// MaxCommentLength ist die maximale Länge eines Kommentars in Zeichen
+const MaxCommentLength = 5000
+
+// mentionPattern erkennt @benutzername am Anfang oder nach einem Zeichen, das nicht zu einem Wort oder einer E-Mail-Adresse gehört
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}._@-])@([\p{L}\p{N}._-]+)`)
+
+// ValidateCommentBody prüft den Text eines Kommentars
+func ValidateCommentBody(body string) error {
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("Text ist ein Pflichtfeld")
+	}
+	if utf8.RuneCountInString(body) > MaxCommentLength {
+		return fmt.Errorf("Kommentar darf höchstens %d Zeichen lang sein", MaxCommentLength)
+	}
+	return nil
+}
+
+// MentionedUsernames liefert die im Text erwähnten Benutzernamen (@benutzername) ohne Duplikate und in Kleinschreibung;
+// Satzzeichen am Ende gehören nicht zum Namen
+func MentionedUsernames(body string) []string {
+	seen := make(map[string]bool)
+	usernames := make([]string, 0)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		username := strings.ToLower(strings.TrimRight(match[1], ".-"))
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
+
+// CommentThreads ordnet Kommentare zu Threads: neue Threads und Antworten jeweils chronologisch.
+// Antworten auf nicht mehr vorhandene Kommentare erscheinen als eigener Thread.
+func CommentThreads(comments []models.Comment) []models.Comment {
+	sorted := make([]models.Comment, len(comments))
+	copy(sorted, comments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	known := make(map[uint]bool, len(sorted))
+	children := make(map[uint][]models.Comment)
+	for _, comment := range sorted {
+		known[comment.ID] = true
+	}
+	roots := make([]models.Comment, 0)
+	for _, comment := range sorted {
+		if comment.ParentID != nil && known[*comment.ParentID] && *comment.ParentID != comment.ID {
+			children[*comment.ParentID] = append(children[*comment.ParentID], comment)
+		} else {
+			roots = append(roots, comment)
+		}
+	}
+
+	var attach func(comment models.Comment, depth int) models.Comment
+	attach = func(comment models.Comment, depth int) models.Comment {
+		if depth > len(sorted) {
+			return comment
+		}
+		for _, reply := range children[comment.ID] {
+			comment.Replies = append(comment.Replies, attach(reply, depth+1))
+		}
+		return comment
+	}
+	for i := range roots {
+		roots[i] = attach(roots[i], 0)
+	}
+	return roots
+}
+
+// PreviousHandoverShift liefert aus den Schichten desselben Typs und Teams die letzte, die vor der Schicht beginnt,
+// oder nil
+func PreviousHandoverShift(shift models.Shift, candidates []models.Shift) *models.Shift {
+	var previous *models.Shift
+	for i := range candidates {
+		candidate := candidates[i]
+		if candidate.ID == shift.ID || !candidate.StartTime.Before(shift.StartTime) {
+			continue
+		}
+		if previous == nil || candidate.StartTime.After(previous.StartTime) {
+			previous = &candidates[i]
+		}
+	}
+	return previous
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMentionedUsernames(t *testing.T) {
+	body := "@Anna bitte Kasse prüfen, @ben.k. übernimmt. Rückfragen an info@example.com oder @anna"
+	assert.Equal(t, []string{"anna", "ben.k"}, MentionedUsernames(body))
+	assert.Empty(t, MentionedUsernames("Keine Erwähnung"))
+
+	assert.NoError(t, ValidateCommentBody("Übergabe"))
+	assert.Error(t, ValidateCommentBody("  "))
+	assert.Error(t, ValidateCommentBody(strings.Repeat("x", MaxCommentLength+1)))
+}
+
+func TestCommentThreads(t *testing.T) {
+	base := time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC)
+	parent := uint(1)
+	reply := uint(3)
+	missing := uint(99)
+	comments := []models.Comment{
+		{Base: models.Base{ID: 4, CreatedAt: base.Add(3 * time.Minute)}, ParentID: &reply},
+		{Base: models.Base{ID: 2, CreatedAt: base.Add(time.Minute)}},
+		{Base: models.Base{ID: 3, CreatedAt: base.Add(2 * time.Minute)}, ParentID: &parent},
+		{Base: models.Base{ID: 1, CreatedAt: base}},
+		{Base: models.Base{ID: 5, CreatedAt: base.Add(4 * time.Minute)}, ParentID: &missing},
+	}
+
+	threads := CommentThreads(comments)
+	if assert.Len(t, threads, 3) {
+		assert.Equal(t, uint(1), threads[0].ID)
+		assert.Equal(t, uint(2), threads[1].ID)
+		assert.Equal(t, uint(5), threads[2].ID)
+		if assert.Len(t, threads[0].Replies, 1) {
+			assert.Equal(t, uint(3), threads[0].Replies[0].ID)
+			assert.Len(t, threads[0].Replies[0].Replies, 1)
+		}
+	}
+}
+
+func TestPreviousHandoverShift(t *testing.T) {
+	base := time.Date(2024, 3, 4, 14, 0, 0, 0, time.UTC)
+	shift := models.Shift{Base: models.Base{ID: 3}, StartTime: base}
+	candidates := []models.Shift{
+		{Base: models.Base{ID: 1}, StartTime: base.AddDate(0, 0, -2)},
+		{Base: models.Base{ID: 2}, StartTime: base.AddDate(0, 0, -1)},
+		{Base: models.Base{ID: 4}, StartTime: base.AddDate(0, 0, 1)},
+	}
+
+	if previous := PreviousHandoverShift(shift, candidates); assert.NotNil(t, previous) {
+		assert.Equal(t, uint(2), previous.ID)
+	}
+	assert.Nil(t, PreviousHandoverShift(shift, candidates[2:]))
+}
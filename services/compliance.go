@@ -0,0 +1,277 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// Regelbezeichner des Arbeitszeitgesetzes
+const (
+	RuleArbZGMaxDailyWork  = "arbzg_max_daily_work" // § 3 ArbZG
+	RuleArbZGMinRest       = "arbzg_min_rest"       // § 5 ArbZG
+	RuleArbZGBreaks        = "arbzg_breaks"         // § 4 ArbZG
+	RuleArbZGAverageWork   = "arbzg_average_work"   // § 3 Satz 2 ArbZG
+	RuleArbZGSundayHoliday = "arbzg_sunday_holiday" // § 9 ArbZG
+)
+
+// ArbZGConfig enthält die Grenzwerte des Arbeitszeitgesetzes
+type ArbZGConfig struct {
+	MaxDailyWork       time.Duration
+	MinRest            time.Duration
+	BreakAfter6Hours   time.Duration
+	BreakAfter9Hours   time.Duration
+	AverageDailyWork   time.Duration
+	AveragePeriodWeeks int
+	Location           *time.Location           // Zeitzone für Kalendertage
+	IsHoliday          func(day time.Time) bool // Optional: Feiertagsprüfung für einen Kalendertag
+}
+
+// DefaultArbZGConfig liefert die gesetzlichen Standardwerte
+func DefaultArbZGConfig() ArbZGConfig {
+	return ArbZGConfig{
+		MaxDailyWork:       10 * time.Hour,
+		MinRest:            11 * time.Hour,
+		BreakAfter6Hours:   30 * time.Minute,
+		BreakAfter9Hours:   45 * time.Minute,
+		AverageDailyWork:   8 * time.Hour,
+		AveragePeriodWeeks: 24,
+		Location:           time.UTC,
+	}
+}
+
+// ComplianceRule ist eine einzelne Regel der Arbeitszeitprüfung
+type ComplianceRule struct {
+	Name  string
+	Check func(config ArbZGConfig, userID uint, shifts []models.Shift) []models.ComplianceViolation
+}
+
+// ArbZGRules liefert alle Regeln des Arbeitszeitgesetzes
+func ArbZGRules() []ComplianceRule {
+	return []ComplianceRule{
+		{Name: RuleArbZGMaxDailyWork, Check: checkMaxDailyWork},
+		{Name: RuleArbZGMinRest, Check: checkMinRest},
+		{Name: RuleArbZGBreaks, Check: checkBreaks},
+		{Name: RuleArbZGAverageWork, Check: checkAverageWork},
+		{Name: RuleArbZGSundayHoliday, Check: checkSundayHoliday},
+	}
+}
+
+// ComplianceChecker prüft die Schichten eines Benutzers gegen eine Menge von Regeln
+type ComplianceChecker struct {
+	config ArbZGConfig
+	rules  []ComplianceRule
+}
+
+// NewComplianceChecker erstellt einen Checker mit allen Regeln des Arbeitszeitgesetzes
+func NewComplianceChecker(config ArbZGConfig) *ComplianceChecker {
+	if config.Location == nil {
+		config.Location = time.UTC
+	}
+	return &ComplianceChecker{config: config, rules: ArbZGRules()}
+}
+
+// CheckUser prüft alle Schichten eines Benutzers und liefert die Verstöße chronologisch sortiert
+func (c *ComplianceChecker) CheckUser(userID uint, shifts []models.Shift) []models.ComplianceViolation {
+	sorted := make([]models.Shift, len(shifts))
+	copy(sorted, shifts)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].StartTime.Before(sorted[j].StartTime) })
+
+	violations := make([]models.ComplianceViolation, 0)
+	for _, rule := range c.rules {
+		violations = append(violations, rule.Check(c.config, userID, sorted)...)
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Date.Before(violations[j].Date) })
+	return violations
+}
+
+// checkMaxDailyWork prüft die tägliche Höchstarbeitszeit von 10 Stunden
+func checkMaxDailyWork(config ArbZGConfig, userID uint, shifts []models.Shift) []models.ComplianceViolation {
+	var violations []models.ComplianceViolation
+
+	byDay := make(map[time.Time][]models.Shift)
+	var days []time.Time
+	for _, shift := range shifts {
+		day := startOfDay(shift.StartTime, config.Location)
+		if _, ok := byDay[day]; !ok {
+			days = append(days, day)
+		}
+		byDay[day] = append(byDay[day], shift)
+	}
+
+	for _, day := range days {
+		var total time.Duration
+		for _, shift := range byDay[day] {
+			total += shift.NetDuration()
+		}
+		if total <= config.MaxDailyWork {
+			continue
+		}
+		last := byDay[day][len(byDay[day])-1]
+		violations = append(violations, models.ComplianceViolation{
+			Rule:     RuleArbZGMaxDailyWork,
+			Severity: models.SeverityError,
+			UserID:   userID,
+			ShiftID:  last.ID,
+			Date:     day,
+			Message: fmt.Sprintf("Tägliche Arbeitszeit von %s überschreitet die Höchstgrenze von %s",
+				formatDuration(total), formatDuration(config.MaxDailyWork)),
+		})
+	}
+
+	return violations
+}
+
+// checkMinRest prüft die ununterbrochene Ruhezeit von 11 Stunden zwischen zwei Schichten
+func checkMinRest(config ArbZGConfig, userID uint, shifts []models.Shift) []models.ComplianceViolation {
+	var violations []models.ComplianceViolation
+
+	for i := 1; i < len(shifts); i++ {
+		previous, current := shifts[i-1], shifts[i]
+		rest := current.StartTime.Sub(previous.EndTime)
+		if rest >= config.MinRest {
+			continue
+		}
+
+		message := fmt.Sprintf("Ruhezeit von %s unterschreitet die Mindestruhezeit von %s",
+			formatDuration(rest), formatDuration(config.MinRest))
+		if rest < 0 {
+			message = "Schicht überschneidet sich mit der vorherigen Schicht"
+		}
+		violations = append(violations, models.ComplianceViolation{
+			Rule:     RuleArbZGMinRest,
+			Severity: models.SeverityError,
+			UserID:   userID,
+			ShiftID:  current.ID,
+			Date:     current.StartTime,
+			Message:  message,
+		})
+	}
+
+	return violations
+}
+
+// checkBreaks prüft die Mindestpausen von 30 Minuten ab 6 und 45 Minuten ab 9 Stunden Arbeitszeit
+func checkBreaks(config ArbZGConfig, userID uint, shifts []models.Shift) []models.ComplianceViolation {
+	var violations []models.ComplianceViolation
+
+	for _, shift := range shifts {
+		work := shift.NetDuration()
+		breakTime := time.Duration(shift.BreakTime) * time.Minute
+
+		var required time.Duration
+		switch {
+		case work > 9*time.Hour:
+			required = config.BreakAfter9Hours
+		case work > 6*time.Hour:
+			required = config.BreakAfter6Hours
+		}
+		if breakTime >= required {
+			continue
+		}
+
+		violations = append(violations, models.ComplianceViolation{
+			Rule:     RuleArbZGBreaks,
+			Severity: models.SeverityError,
+			UserID:   userID,
+			ShiftID:  shift.ID,
+			Date:     shift.StartTime,
+			Message: fmt.Sprintf("Pause von %s ist bei %s Arbeitszeit zu kurz, erforderlich sind %s",
+				formatDuration(breakTime), formatDuration(work), formatDuration(required)),
+		})
+	}
+
+	return violations
+}
+
+// checkAverageWork prüft den Durchschnitt von 8 Stunden je Werktag über den Ausgleichszeitraum.
+// Gemeldet wird der Zeitraum mit der höchsten Arbeitszeit.
+func checkAverageWork(config ArbZGConfig, userID uint, shifts []models.Shift) []models.ComplianceViolation {
+	if len(shifts) == 0 || config.AveragePeriodWeeks <= 0 {
+		return nil
+	}
+
+	// Werktage sind Montag bis Samstag
+	period := time.Duration(config.AveragePeriodWeeks) * 7 * 24 * time.Hour
+	limit := time.Duration(config.AveragePeriodWeeks*6) * config.AverageDailyWork
+
+	var maxTotal time.Duration
+	var maxEnd time.Time
+	windowStart := 0
+	var total time.Duration
+	for i, shift := range shifts {
+		total += shift.NetDuration()
+		for shifts[windowStart].StartTime.Before(shift.StartTime.Add(-period)) {
+			total -= shifts[windowStart].NetDuration()
+			windowStart++
+		}
+		if total > maxTotal {
+			maxTotal = total
+			maxEnd = shifts[i].StartTime
+		}
+	}
+
+	if maxTotal <= limit {
+		return nil
+	}
+
+	return []models.ComplianceViolation{{
+		Rule:     RuleArbZGAverageWork,
+		Severity: models.SeverityWarning,
+		UserID:   userID,
+		Date:     startOfDay(maxEnd, config.Location),
+		Message: fmt.Sprintf("Arbeitszeit von %s in %d Wochen überschreitet den Durchschnitt von %s je Werktag (max. %s)",
+			formatDuration(maxTotal), config.AveragePeriodWeeks, formatDuration(config.AverageDailyWork), formatDuration(limit)),
+	}}
+}
+
+// checkSundayHoliday meldet Arbeit an Sonn- und Feiertagen
+func checkSundayHoliday(config ArbZGConfig, userID uint, shifts []models.Shift) []models.ComplianceViolation {
+	var violations []models.ComplianceViolation
+
+	for _, shift := range shifts {
+		for day := startOfDay(shift.StartTime, config.Location); day.Before(shift.EndTime); day = day.AddDate(0, 0, 1) {
+			var reason string
+			switch {
+			case config.IsHoliday != nil && config.IsHoliday(day):
+				reason = "Feiertag"
+			case day.Weekday() == time.Sunday:
+				reason = "Sonntag"
+			default:
+				continue
+			}
+
+			violations = append(violations, models.ComplianceViolation{
+				Rule:     RuleArbZGSundayHoliday,
+				Severity: models.SeverityWarning,
+				UserID:   userID,
+				ShiftID:  shift.ID,
+				Date:     day,
+				Message:  fmt.Sprintf("Arbeit am %s %s ist nur mit Ausnahme nach § 10 ArbZG zulässig", reason, day.Format("02.01.2006")),
+			})
+			break
+		}
+	}
+
+	return violations
+}
+
+// startOfDay liefert den Beginn des Kalendertags in der angegebenen Zeitzone
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+// formatDuration formatiert eine Dauer als "8:30 h"
+func formatDuration(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	return fmt.Sprintf("%s%d:%02d h", sign, minutes/60, minutes%60)
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// complianceShift erstellt eine Schicht für die Regelprüfung
+func complianceShift(id uint, start time.Time, hours float64, breakMinutes int) models.Shift {
+	return models.Shift{
+		Base:      models.Base{ID: id},
+		UserID:    1,
+		StartTime: start,
+		EndTime:   start.Add(time.Duration(hours * float64(time.Hour))),
+		BreakTime: breakMinutes,
+	}
+}
+
+// violationsByRule filtert Verstöße nach Regel
+func violationsByRule(violations []models.ComplianceViolation, rule string) []models.ComplianceViolation {
+	var result []models.ComplianceViolation
+	for _, violation := range violations {
+		if violation.Rule == rule {
+			result = append(result, violation)
+		}
+	}
+	return result
+}
+
+func TestComplianceChecker_CompliantWeek(t *testing.T) {
+	checker := NewComplianceChecker(DefaultArbZGConfig())
+
+	var shifts []models.Shift
+	for day := 0; day < 5; day++ {
+		start := time.Date(2024, 1, 8+day, 6, 0, 0, 0, time.UTC) // Montag bis Freitag
+		shifts = append(shifts, complianceShift(uint(day+1), start, 8.5, 30))
+	}
+
+	assert.Empty(t, checker.CheckUser(1, shifts))
+}
+
+func TestComplianceChecker_MaxDailyWork(t *testing.T) {
+	checker := NewComplianceChecker(DefaultArbZGConfig())
+
+	shifts := []models.Shift{complianceShift(1, time.Date(2024, 1, 8, 6, 0, 0, 0, time.UTC), 11, 45)}
+	violations := violationsByRule(checker.CheckUser(1, shifts), RuleArbZGMaxDailyWork)
+
+	assert.Len(t, violations, 1)
+	assert.Equal(t, models.SeverityError, violations[0].Severity)
+	assert.Equal(t, uint(1), violations[0].ShiftID)
+}
+
+func TestComplianceChecker_MinRest(t *testing.T) {
+	checker := NewComplianceChecker(DefaultArbZGConfig())
+
+	// Spätschicht bis 22:00, Frühschicht ab 06:00 am Folgetag: nur 8 Stunden Ruhezeit
+	shifts := []models.Shift{
+		complianceShift(1, time.Date(2024, 1, 8, 14, 0, 0, 0, time.UTC), 8, 30),
+		complianceShift(2, time.Date(2024, 1, 9, 6, 0, 0, 0, time.UTC), 8, 30),
+	}
+	violations := violationsByRule(checker.CheckUser(1, shifts), RuleArbZGMinRest)
+
+	assert.Len(t, violations, 1)
+	assert.Equal(t, uint(2), violations[0].ShiftID)
+	assert.Contains(t, violations[0].Message, "8:00 h")
+}
+
+func TestComplianceChecker_Breaks(t *testing.T) {
+	checker := NewComplianceChecker(DefaultArbZGConfig())
+
+	shifts := []models.Shift{
+		complianceShift(1, time.Date(2024, 1, 8, 6, 0, 0, 0, time.UTC), 6, 0),       // 6 h ohne Pause: erlaubt
+		complianceShift(2, time.Date(2024, 1, 9, 6, 0, 0, 0, time.UTC), 7, 15),      // 6:45 h mit 15 min: zu kurz
+		complianceShift(3, time.Date(2024, 1, 10, 6, 0, 0, 0, time.UTC), 10, 30),    // 9:30 h mit 30 min: zu kurz
+		complianceShift(4, time.Date(2024, 1, 11, 6, 0, 0, 0, time.UTC), 10.25, 45), // 9:30 h mit 45 min: erlaubt
+	}
+	violations := violationsByRule(checker.CheckUser(1, shifts), RuleArbZGBreaks)
+
+	assert.Len(t, violations, 2)
+	assert.Equal(t, uint(2), violations[0].ShiftID)
+	assert.Equal(t, uint(3), violations[1].ShiftID)
+}
+
+func TestComplianceChecker_AverageWork(t *testing.T) {
+	checker := NewComplianceChecker(DefaultArbZGConfig())
+
+	// 24 Wochen lang sechs Tage mit je 9,5 Stunden netto überschreiten den Durchschnitt von 8 Stunden
+	var shifts []models.Shift
+	start := time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC)
+	for day := 0; day < 24*7; day++ {
+		date := start.AddDate(0, 0, day)
+		if date.Weekday() == time.Sunday {
+			continue
+		}
+		shifts = append(shifts, complianceShift(uint(day+1), date, 10.25, 45))
+	}
+	violations := violationsByRule(checker.CheckUser(1, shifts), RuleArbZGAverageWork)
+
+	assert.Len(t, violations, 1)
+	assert.Equal(t, models.SeverityWarning, violations[0].Severity)
+	assert.Zero(t, violations[0].ShiftID)
+}
+
+func TestComplianceChecker_SundayAndHoliday(t *testing.T) {
+	config := DefaultArbZGConfig()
+	config.IsHoliday = func(day time.Time) bool {
+		return day.Month() == time.January && day.Day() == 1
+	}
+	checker := NewComplianceChecker(config)
+
+	shifts := []models.Shift{
+		complianceShift(1, time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC), 8, 30),  // Neujahr
+		complianceShift(2, time.Date(2024, 1, 6, 22, 0, 0, 0, time.UTC), 8, 30), // Nacht Samstag auf Sonntag
+		complianceShift(3, time.Date(2024, 1, 9, 6, 0, 0, 0, time.UTC), 8, 30),  // Dienstag
+	}
+	violations := violationsByRule(checker.CheckUser(1, shifts), RuleArbZGSundayHoliday)
+
+	assert.Len(t, violations, 2)
+	assert.Contains(t, violations[0].Message, "Feiertag")
+	assert.Contains(t, violations[1].Message, "Sonntag")
+	assert.Equal(t, uint(2), violations[1].ShiftID)
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// Regeln der Vertragsprüfung
+const (
+	RuleContractMissing     = "contract_missing"      // Schicht außerhalb eines gültigen Arbeitsvertrags
+	RuleContractWeeklyHours = "contract_weekly_hours" // Geplante Wochenarbeitszeit über der vertraglichen Sollzeit
+)
+
+// ValidateEmploymentContract prüft die Angaben eines Arbeitsvertrags
+func ValidateEmploymentContract(contract models.EmploymentContract) error {
+	if !models.IsValidEmploymentType(contract.EmploymentType) {
+		return fmt.Errorf("unbekannte Beschäftigungsart: %s", contract.EmploymentType)
+	}
+	if contract.WeeklyHours < 0 || contract.WeeklyHours > 60 {
+		return fmt.Errorf("Wochenarbeitszeit muss zwischen 0 und 60 Stunden liegen")
+	}
+	if contract.WorkingDaysPerWeek < 1 || contract.WorkingDaysPerWeek > 7 {
+		return fmt.Errorf("Arbeitstage pro Woche müssen zwischen 1 und 7 liegen")
+	}
+	if contract.VacationDays < 0 || contract.VacationDays > 366 {
+		return fmt.Errorf("Urlaubstage müssen zwischen 0 und 366 liegen")
+	}
+	if contract.ValidTo != nil && contract.ValidTo.Before(contract.ValidFrom) {
+		return fmt.Errorf("Vertragsende muss nach dem Vertragsbeginn liegen")
+	}
+	return nil
+}
+
+// ContractOn liefert den Vertrag, der am Kalendertag day (00:00 Uhr UTC) gilt
+func ContractOn(contracts []models.EmploymentContract, day time.Time) (models.EmploymentContract, bool) {
+	for _, contract := range contracts {
+		if contract.Covers(day) {
+			return contract, true
+		}
+	}
+	return models.EmploymentContract{}, false
+}
+
+// ContractTarget liefert die Sollstunden je Kalendertag aus dem jeweils gültigen Vertrag.
+// Ohne Vertragshistorie gilt die Wochenarbeitszeit fallbackWeeklyHours von Montag bis Freitag,
+// sonst ist das Soll außerhalb der Vertragslaufzeiten 0. Ein Vertragswechsel im Monat wird so tagesgenau anteilig berücksichtigt.
+func ContractTarget(contracts []models.EmploymentContract, fallbackWeeklyHours float64) DailyTargetFunc {
+	if len(contracts) == 0 {
+		return WeeklyHoursTarget(fallbackWeeklyHours)
+	}
+	return func(day time.Time) float64 {
+		contract, ok := ContractOn(contracts, day)
+		if !ok || !contract.IsWorkingDay(day.Weekday()) {
+			return 0
+		}
+		return contract.DailyHours()
+	}
+}
+
+// VacationEntitlement berechnet den Urlaubsanspruch eines Kalenderjahres anteilig nach Vertragslaufzeit.
+// Bruchteile von mindestens einem halben Tag werden aufgerundet (§ 5 Abs. 2 BUrlG).
+func VacationEntitlement(contracts []models.EmploymentContract, year int) float64 {
+	first := date(year, time.January, 1)
+	daysInYear := float64(date(year+1, time.January, 1).Sub(first).Hours() / 24)
+
+	var entitlement float64
+	for day := first; day.Year() == year; day = day.AddDate(0, 0, 1) {
+		if contract, ok := ContractOn(contracts, day); ok {
+			entitlement += contract.VacationDays / daysInYear
+		}
+	}
+	return math.Round(entitlement)
+}
+
+// CheckContracts prüft die Schichten eines Benutzers im Zeitraum [from, to] gegen seine Vertragshistorie.
+// Ohne Verträge wird nichts geprüft; Kalendertage und Wochen richten sich nach loc.
+func CheckContracts(userID uint, contracts []models.EmploymentContract, shifts []models.Shift, from, to time.Time, loc *time.Location) []models.ComplianceViolation {
+	violations := make([]models.ComplianceViolation, 0)
+	if len(contracts) == 0 {
+		return violations
+	}
+
+	target := ContractTarget(contracts, 0)
+	planned := make(map[time.Time]time.Duration)
+	for _, shift := range shifts {
+		if shift.EndTime.Before(from) || shift.StartTime.After(to) {
+			continue
+		}
+
+		local := shift.StartTime.In(loc)
+		day := date(local.Year(), local.Month(), local.Day())
+		if _, ok := ContractOn(contracts, day); !ok {
+			violations = append(violations, models.ComplianceViolation{
+				Rule:     RuleContractMissing,
+				Severity: models.SeverityWarning,
+				UserID:   userID,
+				ShiftID:  shift.ID,
+				Date:     shift.StartTime,
+				Message:  fmt.Sprintf("Kein gültiger Arbeitsvertrag am %s", day.Format("02.01.2006")),
+			})
+			continue
+		}
+
+		weekStart := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		planned[weekStart] += shift.EndTime.Sub(shift.StartTime) - time.Duration(shift.BreakTime)*time.Minute
+	}
+
+	weeks := make([]time.Time, 0, len(planned))
+	for week := range planned {
+		weeks = append(weeks, week)
+	}
+	sort.Slice(weeks, func(i, j int) bool { return weeks[i].Before(weeks[j]) })
+
+	for _, week := range weeks {
+		var hours float64
+		for day := week; day.Before(week.AddDate(0, 0, 7)); day = day.AddDate(0, 0, 1) {
+			hours += target(day)
+		}
+		weekTarget := time.Duration(hours * float64(time.Hour))
+		if planned[week] > weekTarget {
+			violations = append(violations, models.ComplianceViolation{
+				Rule:     RuleContractWeeklyHours,
+				Severity: models.SeverityWarning,
+				UserID:   userID,
+				Date:     time.Date(week.Year(), week.Month(), week.Day(), 0, 0, 0, 0, loc),
+				Message: fmt.Sprintf("Geplante Arbeitszeit %s in der Woche ab %s überschreitet die vertragliche Sollzeit %s",
+					formatDuration(planned[week]), week.Format("02.01.2006"), formatDuration(weekTarget)),
+			})
+		}
+	}
+	return violations
+}
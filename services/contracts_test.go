@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// contractPeriod erstellt einen Vertrag; to ist optional
+func contractPeriod(from time.Time, to *time.Time, weeklyHours float64, workingDays int, vacationDays float64) models.EmploymentContract {
+	return models.EmploymentContract{
+		ValidFrom: from, ValidTo: to, WeeklyHours: weeklyHours, WorkingDaysPerWeek: workingDays,
+		EmploymentType: models.EmploymentFullTime, VacationDays: vacationDays,
+	}
+}
+
+func TestValidateEmploymentContract(t *testing.T) {
+	valid := contractPeriod(date(2024, time.January, 1), nil, 40, 5, 30)
+	assert.NoError(t, ValidateEmploymentContract(valid))
+
+	invalidType := valid
+	invalidType.EmploymentType = "freelancer"
+	assert.Error(t, ValidateEmploymentContract(invalidType))
+
+	invalidDays := valid
+	invalidDays.WorkingDaysPerWeek = 0
+	assert.Error(t, ValidateEmploymentContract(invalidDays))
+
+	end := date(2023, time.December, 31)
+	invalidPeriod := valid
+	invalidPeriod.ValidTo = &end
+	assert.Error(t, ValidateEmploymentContract(invalidPeriod))
+}
+
+func TestContractTarget_MidMonthChange(t *testing.T) {
+	// Vollzeit bis 14.06., ab 15.06. Teilzeit mit 20 Stunden an 4 Tagen
+	end := date(2024, time.June, 14)
+	contracts := []models.EmploymentContract{
+		contractPeriod(date(2024, time.January, 1), &end, 40, 5, 30),
+		contractPeriod(date(2024, time.June, 15), nil, 20, 4, 20),
+	}
+	target := ContractTarget(contracts, 0)
+
+	assert.Equal(t, 8.0, target(date(2024, time.June, 14))) // Freitag, Vollzeit
+	assert.Equal(t, 5.0, target(date(2024, time.June, 17))) // Montag, Teilzeit
+	assert.Equal(t, 0.0, target(date(2024, time.June, 21))) // Freitag, kein Arbeitstag mehr
+	assert.Equal(t, 0.0, target(date(2023, time.December, 29)))
+
+	// Juni: 10 Vollzeittage à 8 Stunden und 8 Teilzeittage à 5 Stunden
+	calculator := NewTimeAccountCalculator(time.UTC, target, nil, nil, date(2024, time.July, 1))
+	months := calculator.Months(date(2024, time.June, 1), date(2024, time.June, 1), 0, nil, nil, nil, nil, false)
+	assert.Equal(t, 120.0, months[0].TargetHours)
+
+	// Ohne Verträge gilt die Wochenarbeitszeit des Benutzers
+	assert.Equal(t, 7.0, ContractTarget(nil, 35)(date(2024, time.June, 17)))
+}
+
+func TestVacationEntitlement(t *testing.T) {
+	end := date(2024, time.June, 30)
+	contracts := []models.EmploymentContract{
+		contractPeriod(date(2024, time.January, 1), &end, 40, 5, 30),
+		contractPeriod(date(2024, time.July, 1), nil, 20, 4, 24),
+	}
+	// 182/366 * 30 + 184/366 * 24 = 26,98
+	assert.Equal(t, 27.0, VacationEntitlement(contracts, 2024))
+	assert.Equal(t, 24.0, VacationEntitlement(contracts, 2025))
+	assert.Equal(t, 0.0, VacationEntitlement(contracts, 2023))
+
+	// Eintritt zum 01.10.: 92/365 * 30 = 7,56
+	assert.Equal(t, 8.0, VacationEntitlement([]models.EmploymentContract{contractPeriod(date(2023, time.October, 1), nil, 40, 5, 30)}, 2023))
+}
+
+func TestCheckContracts(t *testing.T) {
+	contracts := []models.EmploymentContract{contractPeriod(date(2024, time.June, 3), nil, 20, 5, 20)}
+	shifts := []models.Shift{
+		{Base: models.Base{ID: 1}, StartTime: time.Date(2024, 5, 31, 8, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)},
+		{Base: models.Base{ID: 2}, StartTime: time.Date(2024, 6, 3, 8, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 6, 3, 16, 30, 0, 0, time.UTC), BreakTime: 30},
+		{Base: models.Base{ID: 3}, StartTime: time.Date(2024, 6, 4, 8, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 6, 4, 16, 30, 0, 0, time.UTC), BreakTime: 30},
+		{Base: models.Base{ID: 4}, StartTime: time.Date(2024, 6, 5, 8, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 6, 5, 16, 30, 0, 0, time.UTC), BreakTime: 30},
+	}
+
+	violations := CheckContracts(5, contracts, shifts, date(2024, time.May, 1), date(2024, time.June, 30), time.UTC)
+	assert.Len(t, violations, 2)
+	assert.Equal(t, RuleContractMissing, violations[0].Rule)
+	assert.Equal(t, uint(1), violations[0].ShiftID)
+	assert.Equal(t, RuleContractWeeklyHours, violations[1].Rule)
+	assert.Contains(t, violations[1].Message, "24:00 h")
+	assert.Contains(t, violations[1].Message, "20:00 h")
+
+	assert.Empty(t, CheckContracts(5, nil, shifts, date(2024, time.May, 1), date(2024, time.June, 30), time.UTC))
+}
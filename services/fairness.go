@@ -0,0 +1,151 @@
+package services
+
+import (
+	"sort"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// FairnessInput enthält die Schichten und Sollstunden eines Benutzers im Auswertungszeitraum
+type FairnessInput struct {
+	User        models.User
+	Shifts      []models.Shift
+	TargetHours float64
+}
+
+// ShiftFairnessCategories liefert die Kategorien unbeliebter Schichten, in die eine Schicht in der Zeitzone loc fällt.
+// holidays ist optional.
+func ShiftFairnessCategories(shift models.Shift, loc *time.Location, holidays *HolidayCalendar) []string {
+	categories := make([]string, 0)
+	start := shift.StartTime.In(loc)
+	end := shift.EndTime.In(loc)
+
+	if start.Weekday() == time.Saturday || start.Weekday() == time.Sunday {
+		categories = append(categories, models.FairnessWeekend)
+	}
+
+	night := nightMinutes(start, end, loc) > 120
+	if night {
+		categories = append(categories, models.FairnessNight)
+	}
+	if holidays != nil && holidays.IsHoliday(date(start.Year(), start.Month(), start.Day())) {
+		categories = append(categories, models.FairnessHoliday)
+	}
+	lateLimit := time.Date(start.Year(), start.Month(), start.Day(), 20, 0, 0, 0, loc)
+	if !night && end.After(lateLimit) {
+		categories = append(categories, models.FairnessLate)
+	}
+	return categories
+}
+
+// TeamFairness zählt die unbeliebten Schichten je Benutzer, normalisiert sie auf 100 Sollstunden und berechnet die
+// Abweichung vom Teammittel. Benutzer ohne Sollstunden werden nicht normalisiert und fließen nicht in das Mittel ein.
+// Das Ergebnis ist absteigend nach Score sortiert.
+func TeamFairness(inputs []FairnessInput, holidays *HolidayCalendar) (models.FairnessCounts, []models.UserFairness) {
+	users := make([]models.UserFairness, 0, len(inputs))
+	var sum, mean models.FairnessCounts
+	normalizedUsers := 0
+
+	for _, input := range inputs {
+		fairness := models.UserFairness{
+			UserID:      input.User.ID,
+			UserName:    input.User.Name,
+			TargetHours: roundTo(input.TargetHours, 2),
+			Shifts:      len(input.Shifts),
+		}
+		for _, shift := range input.Shifts {
+			for _, category := range ShiftFairnessCategories(shift, input.User.Location(), holidays) {
+				fairness.Counts.Add(category, 1)
+			}
+		}
+		if input.TargetHours > 0 {
+			for _, category := range models.FairnessCategories {
+				fairness.Normalized.Add(category, roundTo(fairness.Counts.Get(category)/input.TargetHours*100, 2))
+				sum.Add(category, fairness.Normalized.Get(category))
+			}
+			normalizedUsers++
+		}
+		users = append(users, fairness)
+	}
+
+	if normalizedUsers > 0 {
+		for _, category := range models.FairnessCategories {
+			mean.Add(category, roundTo(sum.Get(category)/float64(normalizedUsers), 2))
+		}
+	}
+	for i := range users {
+		if inputs[i].TargetHours <= 0 {
+			continue
+		}
+		for _, category := range models.FairnessCategories {
+			users[i].Deviation.Add(category, roundTo(users[i].Normalized.Get(category)-mean.Get(category), 2))
+		}
+		users[i].Score = roundTo(users[i].Deviation.Total(), 2)
+	}
+
+	sort.SliceStable(users, func(i, j int) bool {
+		if users[i].Score != users[j].Score {
+			return users[i].Score > users[j].Score
+		}
+		return users[i].UserName < users[j].UserName
+	})
+	return mean, users
+}
+
+// RankCandidates ordnet die Benutzer als Kandidaten für eine Schicht mit den Kategorien categories: verfügbare zuerst,
+// dann aufsteigend nach der Abweichung in diesen Kategorien (ohne Kategorien nach dem Score). unavailable enthält je
+// Benutzer den Grund, warum er nicht verfügbar ist.
+func RankCandidates(users []models.UserFairness, categories []string, unavailable map[uint]string) []models.FairnessCandidate {
+	candidates := make([]models.FairnessCandidate, 0, len(users))
+	for _, user := range users {
+		candidate := models.FairnessCandidate{UserFairness: user, ShiftScore: user.Score, Available: unavailable[user.UserID] == ""}
+		candidate.Reason = unavailable[user.UserID]
+		if len(categories) > 0 {
+			candidate.ShiftScore = 0
+			for _, category := range categories {
+				candidate.ShiftScore += user.Deviation.Get(category)
+			}
+			candidate.ShiftScore = roundTo(candidate.ShiftScore, 2)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Available != b.Available {
+			return a.Available
+		}
+		if a.ShiftScore != b.ShiftScore {
+			return a.ShiftScore < b.ShiftScore
+		}
+		if a.Score != b.Score {
+			return a.Score < b.Score
+		}
+		return a.UserName < b.UserName
+	})
+	for i := range candidates {
+		candidates[i].Rank = i + 1
+	}
+	return candidates
+}
+
+// nightMinutes liefert die Minuten der Schicht [start, end) in der Nachtzeit 23:00 bis 06:00 Uhr in loc
+func nightMinutes(start, end time.Time, loc *time.Location) float64 {
+	var minutes float64
+	for day := date(start.Year(), start.Month(), start.Day()).AddDate(0, 0, -1); day.Before(end); day = day.AddDate(0, 0, 1) {
+		nightStart := time.Date(day.Year(), day.Month(), day.Day(), 23, 0, 0, 0, loc)
+		nightEnd := time.Date(day.Year(), day.Month(), day.Day()+1, 6, 0, 0, 0, loc)
+		from, to := start, end
+		if nightStart.After(from) {
+			from = nightStart
+		}
+		if nightEnd.Before(to) {
+			to = nightEnd
+		}
+		if to.After(from) {
+			minutes += to.Sub(from).Minutes()
+		}
+	}
+	return minutes
+}
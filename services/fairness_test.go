@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShiftFairnessCategories(t *testing.T) {
+	calendar, _ := NewHolidayCalendar(models.StateBY, nil)
+	shift := func(year int, month time.Month, day, startHour, hours int) models.Shift {
+		start := time.Date(year, month, day, startHour, 0, 0, 0, time.UTC)
+		return models.Shift{StartTime: start, EndTime: start.Add(time.Duration(hours) * time.Hour)}
+	}
+
+	assert.Empty(t, ShiftFairnessCategories(shift(2024, 3, 4, 6, 8), time.UTC, calendar))
+	assert.Equal(t, []string{models.FairnessLate}, ShiftFairnessCategories(shift(2024, 3, 4, 14, 8), time.UTC, calendar))
+	assert.Equal(t, []string{models.FairnessNight}, ShiftFairnessCategories(shift(2024, 3, 4, 22, 8), time.UTC, calendar))
+	// Bis 01:00 Uhr sind nur 2 Stunden Nachtzeit, daher Spätschicht
+	assert.Equal(t, []string{models.FairnessLate}, ShiftFairnessCategories(shift(2024, 3, 4, 17, 8), time.UTC, calendar))
+	assert.Equal(t, []string{models.FairnessWeekend}, ShiftFairnessCategories(shift(2024, 3, 9, 6, 8), time.UTC, calendar))
+	assert.Equal(t, []string{models.FairnessHoliday}, ShiftFairnessCategories(shift(2024, 12, 25, 6, 8), time.UTC, calendar))
+	// Früher Beginn nach Mitternacht zählt als Nachtschicht
+	assert.Equal(t, []string{models.FairnessNight}, ShiftFairnessCategories(shift(2024, 3, 5, 2, 8), time.UTC, nil))
+}
+
+func TestTeamFairnessAndCandidates(t *testing.T) {
+	user := func(id uint, name string) models.User {
+		u := models.User{Name: name, TimeZone: "UTC"}
+		u.ID = id
+		return u
+	}
+	weekend := func(day int) models.Shift {
+		start := time.Date(2024, 3, day, 6, 0, 0, 0, time.UTC)
+		return models.Shift{StartTime: start, EndTime: start.Add(8 * time.Hour)}
+	}
+
+	inputs := []FairnessInput{
+		// Vollzeit mit zwei Wochenenddiensten
+		{User: user(1, "Anna"), Shifts: []models.Shift{weekend(2), weekend(9)}, TargetHours: 160},
+		// Teilzeit mit einem Wochenenddienst ist normalisiert gleich belastet
+		{User: user(2, "Ben"), Shifts: []models.Shift{weekend(16)}, TargetHours: 80},
+		{User: user(3, "Cem"), TargetHours: 160},
+		// Ohne Sollstunden keine Normalisierung
+		{User: user(4, "Dora"), Shifts: []models.Shift{weekend(23)}},
+	}
+
+	mean, users := TeamFairness(inputs, nil)
+	assert.InDelta(t, 0.83, mean.Weekend, 0.001)
+	if !assert.Len(t, users, 4) {
+		return
+	}
+	assert.Equal(t, "Anna", users[0].UserName)
+	assert.Equal(t, 2.0, users[0].Counts.Weekend)
+	assert.Equal(t, 1.25, users[0].Normalized.Weekend)
+	assert.Equal(t, 0.42, users[0].Score)
+	assert.Equal(t, users[0].Score, users[1].Score)
+	assert.Equal(t, "Dora", users[2].UserName)
+	assert.Zero(t, users[2].Score)
+	assert.Equal(t, "Cem", users[3].UserName)
+	assert.Equal(t, -0.83, users[3].Score)
+
+	candidates := RankCandidates(users, []string{models.FairnessWeekend}, map[uint]string{3: "Abwesend"})
+	if assert.Len(t, candidates, 4) {
+		assert.Equal(t, "Dora", candidates[0].UserName)
+		assert.Equal(t, 1, candidates[0].Rank)
+		assert.Equal(t, "Cem", candidates[3].UserName)
+		assert.False(t, candidates[3].Available)
+		assert.Equal(t, "Abwesend", candidates[3].Reason)
+	}
+}
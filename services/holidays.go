@@ -0,0 +1,230 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// EasterSunday berechnet den Ostersonntag nach der Gaußschen Osterformel (gregorianischer Kalender)
+func EasterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return date(year, time.Month(month), day)
+}
+
+// holidayRule beschreibt einen gesetzlichen Feiertag; States leer = bundesweit
+type holidayRule struct {
+	name   string
+	states []string
+	date   func(year int) time.Time
+	valid  func(year int) bool // Optional: nur in bestimmten Jahren
+}
+
+// statutoryHolidayRules enthält die gesetzlichen Feiertage der Bundesländer
+var statutoryHolidayRules = []holidayRule{
+	{name: "Neujahr", date: fixed(time.January, 1)},
+	{name: "Heilige Drei Könige", states: []string{models.StateBW, models.StateBY, models.StateST}, date: fixed(time.January, 6)},
+	{name: "Internationaler Frauentag", states: []string{models.StateBE}, date: fixed(time.March, 8), valid: since(2019)},
+	{name: "Internationaler Frauentag", states: []string{models.StateMV}, date: fixed(time.March, 8), valid: since(2023)},
+	{name: "Karfreitag", date: easterOffset(-2)},
+	{name: "Ostersonntag", states: []string{models.StateBB}, date: easterOffset(0)},
+	{name: "Ostermontag", date: easterOffset(1)},
+	{name: "Tag der Arbeit", date: fixed(time.May, 1)},
+	{name: "Tag der Befreiung", states: []string{models.StateBE}, date: fixed(time.May, 8), valid: onlyIn(2020, 2025)},
+	{name: "Christi Himmelfahrt", date: easterOffset(39)},
+	{name: "Pfingstsonntag", states: []string{models.StateBB}, date: easterOffset(49)},
+	{name: "Pfingstmontag", date: easterOffset(50)},
+	{name: "Fronleichnam", states: []string{models.StateBW, models.StateBY, models.StateHE, models.StateNW, models.StateRP, models.StateSL}, date: easterOffset(60)},
+	{name: "Mariä Himmelfahrt", states: []string{models.StateSL}, date: fixed(time.August, 15)},
+	{name: "Weltkindertag", states: []string{models.StateTH}, date: fixed(time.September, 20), valid: since(2019)},
+	{name: "Tag der Deutschen Einheit", date: fixed(time.October, 3)},
+	{name: "Reformationstag", states: []string{models.StateBB, models.StateMV, models.StateSN, models.StateST, models.StateTH}, date: fixed(time.October, 31)},
+	{name: "Reformationstag", states: []string{models.StateHB, models.StateHH, models.StateNI, models.StateSH}, date: fixed(time.October, 31), valid: since(2017)},
+	// 500 Jahre Reformation: 2017 einmalig in allen Bundesländern
+	{name: "Reformationstag", states: []string{models.StateBW, models.StateBY, models.StateBE, models.StateHE, models.StateNW, models.StateRP, models.StateSL}, date: fixed(time.October, 31), valid: onlyIn(2017)},
+	{name: "Allerheiligen", states: []string{models.StateBW, models.StateBY, models.StateNW, models.StateRP, models.StateSL}, date: fixed(time.November, 1)},
+	{name: "Buß- und Bettag", states: []string{models.StateSN}, date: repentanceDay},
+	{name: "1. Weihnachtstag", date: fixed(time.December, 25)},
+	{name: "2. Weihnachtstag", date: fixed(time.December, 26)},
+}
+
+// StatutoryHolidays liefert die gesetzlichen Feiertage eines Jahres.
+// Ohne Bundesland werden nur die bundesweiten Feiertage geliefert.
+func StatutoryHolidays(year int, state string) ([]models.Holiday, error) {
+	if state != "" && !models.IsValidState(state) {
+		return nil, fmt.Errorf("unbekanntes Bundesland: %s", state)
+	}
+
+	var holidays []models.Holiday
+	for _, rule := range statutoryHolidayRules {
+		if rule.valid != nil && !rule.valid(year) {
+			continue
+		}
+		if len(rule.states) > 0 && !containsState(rule.states, state) {
+			continue
+		}
+
+		holiday := models.Holiday{Date: rule.date(year), Name: rule.name}
+		if len(rule.states) > 0 {
+			holiday.State = state
+		}
+		holidays = append(holidays, holiday)
+	}
+
+	sortHolidays(holidays)
+	return holidays, nil
+}
+
+// HolidayCalendar vereint die gesetzlichen Feiertage eines Bundeslands mit betrieblichen Feiertagen
+type HolidayCalendar struct {
+	state   string
+	company []models.CompanyHoliday
+	years   map[int]map[time.Time]models.Holiday
+}
+
+// NewHolidayCalendar erstellt einen Kalender für das Bundesland; betriebliche Feiertage anderer Bundesländer werden ignoriert
+func NewHolidayCalendar(state string, company []models.CompanyHoliday) (*HolidayCalendar, error) {
+	if state != "" && !models.IsValidState(state) {
+		return nil, fmt.Errorf("unbekanntes Bundesland: %s", state)
+	}
+	return &HolidayCalendar{state: state, company: company, years: make(map[int]map[time.Time]models.Holiday)}, nil
+}
+
+// Holidays liefert alle Feiertage eines Jahres chronologisch; betriebliche Feiertage ergänzen gesetzliche am selben Tag nicht
+func (c *HolidayCalendar) Holidays(year int) []models.Holiday {
+	byDay := c.year(year)
+	holidays := make([]models.Holiday, 0, len(byDay))
+	for _, holiday := range byDay {
+		holidays = append(holidays, holiday)
+	}
+	sortHolidays(holidays)
+	return holidays
+}
+
+// HolidayOn liefert den Feiertag am Kalendertag von day (in der Zeitzone von day)
+func (c *HolidayCalendar) HolidayOn(day time.Time) (models.Holiday, bool) {
+	key := date(day.Year(), day.Month(), day.Day())
+	holiday, ok := c.year(day.Year())[key]
+	return holiday, ok
+}
+
+// IsHoliday prüft, ob der Kalendertag von day ein Feiertag ist; passend für ArbZGConfig.IsHoliday
+func (c *HolidayCalendar) IsHoliday(day time.Time) bool {
+	_, ok := c.HolidayOn(day)
+	return ok
+}
+
+// HolidayDuring liefert den ersten Feiertag, den der Zeitraum [start, end) in der Zeitzone loc berührt
+func (c *HolidayCalendar) HolidayDuring(start, end time.Time, loc *time.Location) (models.Holiday, bool) {
+	for day := startOfDay(start, loc); day.Before(end); day = day.AddDate(0, 0, 1) {
+		if holiday, ok := c.HolidayOn(day); ok {
+			return holiday, true
+		}
+	}
+	return models.Holiday{}, false
+}
+
+// year berechnet die Feiertage eines Jahres einmalig und legt sie nach Kalendertag ab
+func (c *HolidayCalendar) year(year int) map[time.Time]models.Holiday {
+	if byDay, ok := c.years[year]; ok {
+		return byDay
+	}
+
+	byDay := make(map[time.Time]models.Holiday)
+	statutory, _ := StatutoryHolidays(year, c.state)
+	for _, holiday := range statutory {
+		byDay[holiday.Date] = holiday
+	}
+
+	for _, companyHoliday := range c.company {
+		if companyHoliday.State != "" && companyHoliday.State != c.state {
+			continue
+		}
+		if !companyHoliday.Recurring && companyHoliday.Date.Year() != year {
+			continue
+		}
+
+		day := date(year, companyHoliday.Date.Month(), companyHoliday.Date.Day())
+		if companyHoliday.Date.Month() == time.February && companyHoliday.Date.Day() == 29 && day.Month() != time.February {
+			continue // 29. Februar nur in Schaltjahren
+		}
+		if _, exists := byDay[day]; exists {
+			continue
+		}
+		byDay[day] = models.Holiday{Date: day, Name: companyHoliday.Name, State: companyHoliday.State, Company: true}
+	}
+
+	c.years[year] = byDay
+	return byDay
+}
+
+// fixed liefert einen Feiertag mit festem Datum
+func fixed(month time.Month, day int) func(int) time.Time {
+	return func(year int) time.Time { return date(year, month, day) }
+}
+
+// easterOffset liefert einen beweglichen Feiertag relativ zum Ostersonntag
+func easterOffset(days int) func(int) time.Time {
+	return func(year int) time.Time { return EasterSunday(year).AddDate(0, 0, days) }
+}
+
+// repentanceDay liefert den Buß- und Bettag, den Mittwoch vor dem 23. November
+func repentanceDay(year int) time.Time {
+	day := date(year, time.November, 22)
+	for day.Weekday() != time.Wednesday {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// since gilt ab dem angegebenen Jahr
+func since(first int) func(int) bool {
+	return func(year int) bool { return year >= first }
+}
+
+// onlyIn gilt nur in den angegebenen Jahren
+func onlyIn(years ...int) func(int) bool {
+	return func(year int) bool {
+		for _, y := range years {
+			if y == year {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// containsState prüft, ob das Bundesland in der Liste enthalten ist
+func containsState(states []string, state string) bool {
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// sortHolidays sortiert Feiertage chronologisch
+func sortHolidays(holidays []models.Holiday) {
+	sort.Slice(holidays, func(i, j int) bool { return holidays[i].Date.Before(holidays[j].Date) })
+}
+
+// date liefert den Kalendertag als Zeitpunkt 00:00 Uhr UTC
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
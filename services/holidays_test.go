@@ -0,0 +1,120 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// holidayNames liefert die Namen der Feiertage nach Kalendertag (YYYY-MM-DD)
+func holidayNames(holidays []models.Holiday) map[string]string {
+	names := make(map[string]string)
+	for _, holiday := range holidays {
+		names[holiday.Date.Format("2006-01-02")] = holiday.Name
+	}
+	return names
+}
+
+func TestEasterSunday(t *testing.T) {
+	assert.Equal(t, date(2024, time.March, 31), EasterSunday(2024))
+	assert.Equal(t, date(2025, time.April, 20), EasterSunday(2025))
+	assert.Equal(t, date(2019, time.April, 21), EasterSunday(2019))
+	assert.Equal(t, date(2038, time.April, 25), EasterSunday(2038))
+}
+
+func TestStatutoryHolidays_Nationwide(t *testing.T) {
+	holidays, err := StatutoryHolidays(2024, "")
+	assert.NoError(t, err)
+	assert.Len(t, holidays, 9)
+
+	names := holidayNames(holidays)
+	assert.Equal(t, "Karfreitag", names["2024-03-29"])
+	assert.Equal(t, "Christi Himmelfahrt", names["2024-05-09"])
+	assert.Equal(t, "Pfingstmontag", names["2024-05-20"])
+	assert.NotContains(t, names, "2024-05-30") // Fronleichnam nur in einzelnen Ländern
+}
+
+func TestStatutoryHolidays_States(t *testing.T) {
+	bavaria, err := StatutoryHolidays(2024, models.StateBY)
+	assert.NoError(t, err)
+	names := holidayNames(bavaria)
+	assert.Equal(t, "Fronleichnam", names["2024-05-30"])
+	assert.Equal(t, "Heilige Drei Könige", names["2024-01-06"])
+	assert.Equal(t, "Allerheiligen", names["2024-11-01"])
+	assert.NotContains(t, names, "2024-10-31")
+
+	berlin, _ := StatutoryHolidays(2024, models.StateBE)
+	names = holidayNames(berlin)
+	assert.NotContains(t, names, "2024-05-30")
+	assert.Equal(t, "Internationaler Frauentag", names["2024-03-08"])
+
+	saxony, _ := StatutoryHolidays(2024, models.StateSN)
+	assert.Equal(t, "Buß- und Bettag", holidayNames(saxony)["2024-11-20"])
+
+	_, err = StatutoryHolidays(2024, "XX")
+	assert.Error(t, err)
+}
+
+func TestStatutoryHolidays_YearDependentRules(t *testing.T) {
+	// Reformationstag: Hamburg erst ab 2018 dauerhaft, 2017 bundesweit
+	hamburg2016, _ := StatutoryHolidays(2016, models.StateHH)
+	assert.NotContains(t, holidayNames(hamburg2016), "2016-10-31")
+	hamburg2018, _ := StatutoryHolidays(2018, models.StateHH)
+	assert.Contains(t, holidayNames(hamburg2018), "2018-10-31")
+	bavaria2017, _ := StatutoryHolidays(2017, models.StateBY)
+	assert.Contains(t, holidayNames(bavaria2017), "2017-10-31")
+	bavaria2018, _ := StatutoryHolidays(2018, models.StateBY)
+	assert.NotContains(t, holidayNames(bavaria2018), "2018-10-31")
+
+	berlin2025, _ := StatutoryHolidays(2025, models.StateBE)
+	assert.Equal(t, "Tag der Befreiung", holidayNames(berlin2025)["2025-05-08"])
+	berlin2024, _ := StatutoryHolidays(2024, models.StateBE)
+	assert.NotContains(t, holidayNames(berlin2024), "2024-05-08")
+}
+
+func TestHolidayCalendar_CompanyHolidays(t *testing.T) {
+	calendar, err := NewHolidayCalendar(models.StateBY, []models.CompanyHoliday{
+		{Name: "Heiligabend", Date: date(2023, time.December, 24), Recurring: true},
+		{Name: "Betriebsausflug", Date: date(2024, time.June, 14)},
+		{Name: "Nur Berlin", Date: date(2024, time.June, 21), State: models.StateBE},
+		{Name: "Doppelt", Date: date(2024, time.January, 1)},
+	})
+	assert.NoError(t, err)
+
+	names := holidayNames(calendar.Holidays(2024))
+	assert.Equal(t, "Heiligabend", names["2024-12-24"])
+	assert.Equal(t, "Betriebsausflug", names["2024-06-14"])
+	assert.NotContains(t, names, "2024-06-21")
+	assert.Equal(t, "Neujahr", names["2024-01-01"])
+	assert.NotContains(t, holidayNames(calendar.Holidays(2025)), "2025-06-14")
+	assert.Contains(t, holidayNames(calendar.Holidays(2025)), "2025-12-24")
+
+	holiday, ok := calendar.HolidayOn(time.Date(2024, 12, 24, 22, 0, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.True(t, holiday.Company)
+
+	_, err = NewHolidayCalendar("XX", nil)
+	assert.Error(t, err)
+}
+
+func TestHolidayCalendar_HolidayDuring(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	calendar, _ := NewHolidayCalendar("", nil)
+
+	// Nachtschicht 31.12. 22:00 bis 01.01. 06:00 Ortszeit berührt Neujahr
+	start := time.Date(2024, 12, 31, 22, 0, 0, 0, berlin)
+	holiday, ok := calendar.HolidayDuring(start, start.Add(8*time.Hour), berlin)
+	assert.True(t, ok)
+	assert.Equal(t, "Neujahr", holiday.Name)
+
+	// 30.12. 23:30 UTC ist in Berlin bereits der 31.12. – kein Feiertag
+	start = time.Date(2024, 12, 30, 23, 30, 0, 0, time.UTC)
+	_, ok = calendar.HolidayDuring(start, start.Add(2*time.Hour), berlin)
+	assert.False(t, ok)
+
+	assert.True(t, calendar.IsHoliday(time.Date(2024, 10, 3, 12, 0, 0, 0, berlin)))
+	assert.False(t, calendar.IsHoliday(time.Date(2024, 10, 4, 12, 0, 0, 0, berlin)))
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// ValidateLocation prüft Zeitzone und Geokoordinaten eines Standorts
+func ValidateLocation(location models.Location) error {
+	if _, err := models.LoadLocation(location.TimeZone); err != nil {
+		return err
+	}
+	if (location.Latitude == nil) != (location.Longitude == nil) {
+		return fmt.Errorf("Breiten- und Längengrad müssen gemeinsam angegeben werden")
+	}
+	if location.Latitude != nil && (*location.Latitude < -90 || *location.Latitude > 90) {
+		return fmt.Errorf("Breitengrad muss zwischen -90 und 90 liegen")
+	}
+	if location.Longitude != nil && (*location.Longitude < -180 || *location.Longitude > 180) {
+		return fmt.Errorf("Längengrad muss zwischen -180 und 180 liegen")
+	}
+	return nil
+}
+
+// EffectiveLocationID liefert den Standort einer Schicht: den eigenen, sonst den des geladenen Schichtplans, sonst 0
+func EffectiveLocationID(shift models.Shift) uint {
+	if shift.LocationID != nil {
+		return *shift.LocationID
+	}
+	if shift.Schedule.LocationID != nil {
+		return *shift.Schedule.LocationID
+	}
+	return 0
+}
+
+// LocationStaffing fasst Schichten eines Standorts zusammen. Schichten werden dem Kalendertag ihres Beginns in loc
+// zugeordnet; withDays liefert zusätzlich die Besetzung je Tag. Der Schichttyp muss für die Aufschlüsselung geladen sein.
+func LocationStaffing(location models.Location, shifts []models.Shift, loc *time.Location, withDays bool) models.LocationReport {
+	report := models.LocationReport{LocationID: location.ID, LocationName: location.Name}
+
+	users := make(map[uint]bool)
+	days := make(map[time.Time]*models.LocationStaffingDay)
+	dayUsers := make(map[time.Time]map[uint]bool)
+	for _, shift := range shifts {
+		hours := netShiftHours(shift)
+		report.Shifts++
+		report.PlannedHours += hours
+		users[shift.UserID] = true
+
+		local := shift.StartTime.In(loc)
+		key := date(local.Year(), local.Month(), local.Day())
+		day, ok := days[key]
+		if !ok {
+			day = &models.LocationStaffingDay{Date: key, ByShiftType: make(map[string]int)}
+			days[key] = day
+			dayUsers[key] = make(map[uint]bool)
+		}
+		day.Shifts++
+		day.PlannedHours += hours
+		dayUsers[key][shift.UserID] = true
+		if shift.ShiftType.Name != "" {
+			day.ByShiftType[shift.ShiftType.Name]++
+		}
+	}
+
+	report.Users = len(users)
+	report.PlannedHours = roundTo(report.PlannedHours, 2)
+	if withDays {
+		report.Days = make([]models.LocationStaffingDay, 0, len(days))
+		for key, day := range days {
+			day.Users = len(dayUsers[key])
+			day.PlannedHours = roundTo(day.PlannedHours, 2)
+			report.Days = append(report.Days, *day)
+		}
+		sort.Slice(report.Days, func(i, j int) bool { return report.Days[i].Date.Before(report.Days[j].Date) })
+	}
+	return report
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLocation(t *testing.T) {
+	lat, lon := 52.52, 13.405
+	assert.NoError(t, ValidateLocation(models.Location{Name: "Berlin", TimeZone: "Europe/Berlin", Latitude: &lat, Longitude: &lon}))
+	assert.NoError(t, ValidateLocation(models.Location{Name: "Ohne Koordinaten"}))
+
+	assert.Error(t, ValidateLocation(models.Location{Name: "Zone", TimeZone: "Mars/Olympus"}))
+	assert.Error(t, ValidateLocation(models.Location{Name: "Nur Breite", Latitude: &lat}))
+
+	invalid := 123.0
+	assert.Error(t, ValidateLocation(models.Location{Name: "Breite", Latitude: &invalid, Longitude: &lon}))
+}
+
+func TestEffectiveLocationID(t *testing.T) {
+	site, other := uint(1), uint(2)
+	assert.Equal(t, uint(2), EffectiveLocationID(models.Shift{LocationID: &other, Schedule: models.Schedule{LocationID: &site}}))
+	assert.Equal(t, uint(1), EffectiveLocationID(models.Shift{Schedule: models.Schedule{LocationID: &site}}))
+	assert.Equal(t, uint(0), EffectiveLocationID(models.Shift{}))
+}
+
+func TestLocationStaffing(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	early := models.ShiftType{Name: "Früh"}
+	shifts := []models.Shift{
+		{UserID: 1, ShiftType: early, StartTime: time.Date(2024, 6, 10, 6, 0, 0, 0, berlin), EndTime: time.Date(2024, 6, 10, 14, 0, 0, 0, berlin), BreakTime: 30},
+		{UserID: 2, ShiftType: early, StartTime: time.Date(2024, 6, 10, 6, 0, 0, 0, berlin), EndTime: time.Date(2024, 6, 10, 14, 0, 0, 0, berlin), BreakTime: 30},
+		// Beginnt um 00:30 Uhr Ortszeit, in UTC noch am Vortag
+		{UserID: 1, StartTime: time.Date(2024, 6, 11, 0, 30, 0, 0, berlin), EndTime: time.Date(2024, 6, 11, 6, 30, 0, 0, berlin)},
+	}
+
+	report := LocationStaffing(models.Location{Base: models.Base{ID: 3}, Name: "Werk"}, shifts, berlin, true)
+	assert.Equal(t, uint(3), report.LocationID)
+	assert.Equal(t, 3, report.Shifts)
+	assert.Equal(t, 2, report.Users)
+	assert.Equal(t, 21.0, report.PlannedHours)
+	if assert.Len(t, report.Days, 2) {
+		assert.Equal(t, date(2024, time.June, 10), report.Days[0].Date)
+		assert.Equal(t, 2, report.Days[0].Users)
+		assert.Equal(t, 2, report.Days[0].ByShiftType["Früh"])
+		assert.Equal(t, date(2024, time.June, 11), report.Days[1].Date)
+		assert.Equal(t, 6.0, report.Days[1].PlannedHours)
+	}
+
+	assert.Nil(t, LocationStaffing(models.Location{}, shifts, berlin, false).Days)
+}
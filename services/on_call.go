@@ -0,0 +1,69 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// RuleOnCallRest ist der Regelbezeichner für Ruhezeiten nach Einsätzen in der Rufbereitschaft (§ 5 ArbZG)
+const RuleOnCallRest = "on_call_rest"
+
+// MaxOnCallDuration ist die maximale Dauer einer Rufbereitschaft
+const MaxOnCallDuration = 14 * 24 * time.Hour
+
+// ValidateOnCallDuty prüft Beginn, Ende und Pauschale einer Rufbereitschaft
+func ValidateOnCallDuty(duty models.OnCallDuty) error {
+	if duty.StartTime.IsZero() || duty.EndTime.IsZero() {
+		return fmt.Errorf("Beginn und Ende sind Pflichtfelder")
+	}
+	if !duty.EndTime.After(duty.StartTime) {
+		return fmt.Errorf("Ende muss nach dem Beginn liegen")
+	}
+	if duty.EndTime.Sub(duty.StartTime) > MaxOnCallDuration {
+		return fmt.Errorf("Rufbereitschaft darf höchstens 14 Tage dauern")
+	}
+	if duty.Allowance < 0 {
+		return fmt.Errorf("Pauschale darf nicht negativ sein")
+	}
+	return nil
+}
+
+// OnCallRestViolations prüft die Ruhezeit nach Einsätzen in der Rufbereitschaft. Die Bereitschaft selbst unterbricht
+// die Ruhezeit nicht, ein Einsatz dagegen schon: bis zur nächsten Schicht muss wieder die volle Mindestruhezeit liegen.
+// Verstöße werden als Hinweis gemeldet, da Tarifverträge Kürzungen zulassen können (§ 5 Abs. 3, § 7 ArbZG).
+func OnCallRestViolations(config ArbZGConfig, userID uint, shifts []models.Shift, callOuts []models.TimeEntry) []models.ComplianceViolation {
+	sorted := make([]models.Shift, len(shifts))
+	copy(sorted, shifts)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].StartTime.Before(sorted[j].StartTime) })
+
+	violations := make([]models.ComplianceViolation, 0)
+	for _, callOut := range callOuts {
+		if callOut.ClockOut == nil {
+			continue
+		}
+		end := *callOut.ClockOut
+
+		for _, shift := range sorted {
+			if shift.StartTime.Before(end) {
+				continue
+			}
+			rest := shift.StartTime.Sub(end)
+			if rest < config.MinRest {
+				violations = append(violations, models.ComplianceViolation{
+					Rule:     RuleOnCallRest,
+					Severity: models.SeverityWarning,
+					UserID:   userID,
+					ShiftID:  shift.ID,
+					Date:     shift.StartTime,
+					Message: fmt.Sprintf("Einsatz in der Rufbereitschaft bis %s unterbricht die Ruhezeit; bis zur nächsten Schicht verbleiben %s statt %s",
+						end.In(config.Location).Format("02.01.2006 15:04"), formatDuration(rest), formatDuration(config.MinRest)),
+				})
+			}
+			break
+		}
+	}
+	return violations
+}
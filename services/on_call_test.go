@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateOnCallDuty(t *testing.T) {
+	start := time.Date(2024, 3, 4, 18, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, ValidateOnCallDuty(models.OnCallDuty{StartTime: start, EndTime: start.Add(14 * time.Hour), Allowance: 25}))
+	assert.Error(t, ValidateOnCallDuty(models.OnCallDuty{StartTime: start}))
+	assert.Error(t, ValidateOnCallDuty(models.OnCallDuty{StartTime: start, EndTime: start}))
+	assert.Error(t, ValidateOnCallDuty(models.OnCallDuty{StartTime: start, EndTime: start.AddDate(0, 0, 15)}))
+	assert.Error(t, ValidateOnCallDuty(models.OnCallDuty{StartTime: start, EndTime: start.Add(time.Hour), Allowance: -1}))
+}
+
+func TestOnCallRestViolations(t *testing.T) {
+	config := DefaultArbZGConfig()
+	shifts := []models.Shift{
+		{Base: models.Base{ID: 2}, StartTime: time.Date(2024, 3, 6, 6, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 3, 6, 14, 0, 0, 0, time.UTC)},
+		{Base: models.Base{ID: 1}, StartTime: time.Date(2024, 3, 5, 6, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 3, 5, 14, 0, 0, 0, time.UTC)},
+	}
+	callOut := func(start, end time.Time) models.TimeEntry {
+		return models.TimeEntry{ClockIn: start, ClockOut: &end}
+	}
+
+	// Einsatz bis 02:00 Uhr, Schicht um 06:00 Uhr: nur 4 Stunden Ruhe
+	violations := OnCallRestViolations(config, 7, shifts, []models.TimeEntry{
+		callOut(time.Date(2024, 3, 5, 1, 0, 0, 0, time.UTC), time.Date(2024, 3, 5, 2, 0, 0, 0, time.UTC)),
+	})
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, RuleOnCallRest, violations[0].Rule)
+		assert.Equal(t, models.SeverityWarning, violations[0].Severity)
+		assert.Equal(t, uint(1), violations[0].ShiftID)
+		assert.Equal(t, uint(7), violations[0].UserID)
+	}
+
+	// Einsatz am frühen Abend lässt genug Ruhe; laufende Einsätze werden nicht geprüft
+	start := time.Date(2024, 3, 4, 17, 0, 0, 0, time.UTC)
+	violations = OnCallRestViolations(config, 7, shifts, []models.TimeEntry{
+		callOut(start, time.Date(2024, 3, 4, 18, 0, 0, 0, time.UTC)),
+		{ClockIn: time.Date(2024, 3, 5, 22, 0, 0, 0, time.UTC)},
+	})
+	assert.Empty(t, violations)
+}
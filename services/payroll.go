@@ -0,0 +1,218 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// payrollCategoryLabels sind die Bezeichnungen der Kategorien in Prüfhinweisen
+var payrollCategoryLabels = map[string]string{
+	models.PayrollCategoryRegular:   "Arbeitsstunden",
+	models.PayrollCategoryOvertime:  "Überstunden",
+	models.PayrollCategorySurcharge: "Zuschlag",
+	models.PayrollCategoryAbsence:   "Abwesenheit",
+}
+
+// PayrollLines ermittelt die zu übergebenden Werte eines Benutzers aus dem Monatsabschluss seines Arbeitszeitkontos
+// (mit Tageszeilen) und seinen Zuschlägen. Geleistete Stunden umfassen Schichten und Einsätze während einer
+// Rufbereitschaft; Überstunden sind der positive Monatssaldo, höchstens jedoch die geleisteten Stunden; Abwesenheiten zählen je Art die Arbeitstage (Montag bis Freitag ohne Feiertage) und die gutgeschriebenen Stunden.
+// Werte von 0 werden ausgelassen.
+func PayrollLines(user models.User, month models.TimeAccountMonth, surcharges []models.SurchargeLine) []models.PayrollLine {
+	lines := make([]models.PayrollLine, 0)
+	add := func(category, key string, hours, days float64) {
+		if hours == 0 && days == 0 {
+			return
+		}
+		lines = append(lines, models.PayrollLine{
+			UserID:          user.ID,
+			UserName:        user.Name,
+			PersonnelNumber: user.AccountNumber,
+			Category:        category,
+			Key:             key,
+			Hours:           roundTo(hours, 2),
+			Days:            days,
+		})
+	}
+
+	worked := month.ActualHours + month.CallOutHours
+	overtime := math.Min(math.Max(month.Balance, 0), worked)
+	add(models.PayrollCategoryRegular, "", worked-overtime, 0)
+	add(models.PayrollCategoryOvertime, "", overtime, 0)
+
+	for _, line := range surcharges {
+		add(models.PayrollCategorySurcharge, line.Name, line.Hours, 0)
+	}
+
+	absenceHours := make(map[string]float64)
+	absenceDays := make(map[string]float64)
+	for _, day := range month.Days {
+		if day.AbsenceType == "" || day.Holiday != "" {
+			continue
+		}
+		absenceHours[day.AbsenceType] += day.CreditHours
+		if weekday := day.Date.Weekday(); weekday != time.Saturday && weekday != time.Sunday {
+			absenceDays[day.AbsenceType]++
+		}
+	}
+	for _, absenceType := range models.AbsenceTypes {
+		add(models.PayrollCategoryAbsence, absenceType, absenceHours[absenceType], absenceDays[absenceType])
+	}
+	return lines
+}
+
+// ApplyWageTypes ordnet den Werten ihre Lohnart und Einheit zu. Für jede fehlende Zuordnung wird einmal ein
+// blockierender Hinweis geliefert.
+func ApplyWageTypes(lines []models.PayrollLine, mappings []models.WageTypeMapping) []models.PayrollIssue {
+	byKey := make(map[string]models.WageTypeMapping)
+	for _, mapping := range mappings {
+		byKey[mapping.Category+"\x00"+mapping.Key] = mapping
+	}
+
+	issues := make([]models.PayrollIssue, 0)
+	reported := make(map[string]bool)
+	for i := range lines {
+		line := &lines[i]
+		key := line.Category + "\x00" + line.Key
+		mapping, ok := byKey[key]
+		if !ok {
+			line.Unit = defaultPayrollUnit(line.Category)
+			if !reported[key] {
+				reported[key] = true
+				label := payrollCategoryLabels[line.Category]
+				if line.Key != "" {
+					label += " \"" + line.Key + "\""
+				}
+				issues = append(issues, models.PayrollIssue{
+					Category: line.Category,
+					Key:      line.Key,
+					Message:  fmt.Sprintf("Keine Lohnart für %s zugeordnet", label),
+					Blocking: true,
+				})
+			}
+		} else {
+			line.WageType = mapping.WageType
+			line.Unit = mapping.Unit
+		}
+
+		line.Value = line.Hours
+		if line.Unit == models.PayrollUnitDays {
+			line.Value = line.Days
+		}
+	}
+	return issues
+}
+
+// ValidatePersonnelNumber prüft, ob eine Personalnummer in LODAS verwendet werden kann (1 bis 5 Ziffern)
+func ValidatePersonnelNumber(number string) error {
+	if number == "" {
+		return fmt.Errorf("Personalnummer (AccountNumber) fehlt")
+	}
+	if !isDigits(number) || len(number) > 5 {
+		return fmt.Errorf("Personalnummer %s ist für LODAS ungültig, erwartet 1 bis 5 Ziffern", number)
+	}
+	return nil
+}
+
+// ValidatePayrollSettings prüft Berater- und Mandantennummer
+func ValidatePayrollSettings(settings models.PayrollSettings) error {
+	if !isDigits(settings.ConsultantNumber) || len(settings.ConsultantNumber) < 4 || len(settings.ConsultantNumber) > 7 {
+		return fmt.Errorf("Beraternummer muss aus 4 bis 7 Ziffern bestehen")
+	}
+	if !isDigits(settings.ClientNumber) || len(settings.ClientNumber) > 5 {
+		return fmt.Errorf("Mandantennummer muss aus 1 bis 5 Ziffern bestehen")
+	}
+	return nil
+}
+
+// ValidateWageTypeMapping prüft eine Lohnarten-Zuordnung
+func ValidateWageTypeMapping(mapping models.WageTypeMapping) error {
+	switch mapping.Category {
+	case models.PayrollCategoryRegular, models.PayrollCategoryOvertime:
+		if mapping.Key != "" {
+			return fmt.Errorf("Schlüssel ist nur für Zuschläge und Abwesenheiten erlaubt")
+		}
+	case models.PayrollCategorySurcharge:
+		if mapping.Key == "" {
+			return fmt.Errorf("Schlüssel (Name der Zuschlagsregel) ist ein Pflichtfeld")
+		}
+	case models.PayrollCategoryAbsence:
+		if !models.IsValidAbsenceType(mapping.Key) {
+			return fmt.Errorf("unbekannte Abwesenheitsart: %s", mapping.Key)
+		}
+	default:
+		return fmt.Errorf("unbekannte Kategorie: %s", mapping.Category)
+	}
+
+	if !isDigits(mapping.WageType) || len(mapping.WageType) > 4 {
+		return fmt.Errorf("Lohnart muss aus 1 bis 4 Ziffern bestehen")
+	}
+	if mapping.Unit != models.PayrollUnitHours && mapping.Unit != models.PayrollUnitDays {
+		return fmt.Errorf("unbekannte Einheit: %s", mapping.Unit)
+	}
+	return nil
+}
+
+// LodasFile erzeugt die ASCII-Importdatei für DATEV LODAS mit den Bewegungsdaten des Abrechnungsmonats month
+// (Monatsbeginn). Zeilen ohne Lohnart werden ausgelassen.
+func LodasFile(settings models.PayrollSettings, month time.Time, lines []models.PayrollLine) string {
+	var b strings.Builder
+	writeLine := func(format string, args ...interface{}) {
+		fmt.Fprintf(&b, format+"\r\n", args...)
+	}
+
+	writeLine("[Allgemein]")
+	writeLine("Ziel=LODAS")
+	writeLine("Version_SST=1.0")
+	writeLine("BeraterNr=%s", settings.ConsultantNumber)
+	writeLine("MandantenNr=%s", settings.ClientNumber)
+	writeLine("Datumsangaben=TTMMJJJJ")
+	writeLine("Feldtrennzeichen=;")
+	writeLine("Zahlenkomma=,")
+	writeLine("Kommentarzeichen=*")
+	writeLine("")
+	writeLine("[Satzbeschreibung]")
+	writeLine("10;u_lod_bwd_buchung_standard;abrechnung_zeitraum#bwd;bs_nr#bwd;bs_wert_butab#bwd;la_eigene#bwd;pnr#bwd;")
+	writeLine("")
+	writeLine("[Bewegungsdaten]")
+	writeLine("* Abrechnungsmonat %s", month.Format("01/2006"))
+
+	period := month.Format("02012006")
+	for _, line := range lines {
+		if line.WageType == "" {
+			continue
+		}
+		key := "1"
+		if line.Unit == models.PayrollUnitDays {
+			key = "2"
+		}
+		value := strings.Replace(strconv.FormatFloat(line.Value, 'f', 2, 64), ".", ",", 1)
+		writeLine("10;%s;%s;%s;%s;%s;", period, key, value, line.WageType, line.PersonnelNumber)
+	}
+	return b.String()
+}
+
+// defaultPayrollUnit liefert die Einheit einer Kategorie ohne Zuordnung
+func defaultPayrollUnit(category string) string {
+	if category == models.PayrollCategoryAbsence {
+		return models.PayrollUnitDays
+	}
+	return models.PayrollUnitHours
+}
+
+// isDigits prüft, ob value nicht leer ist und nur aus Ziffern besteht
+func isDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPayrollLines(t *testing.T) {
+	user := models.User{Name: "Lohn", AccountNumber: "00042"}
+	user.ID = 7
+	month := models.TimeAccountMonth{
+		ActualHours: 170,
+		Balance:     10,
+		Days: []models.TimeAccountDay{
+			{Date: date(2024, 3, 4), AbsenceType: models.AbsenceTypeVacation, CreditHours: 8},
+			{Date: date(2024, 3, 5), AbsenceType: models.AbsenceTypeVacation, CreditHours: 8},
+			{Date: date(2024, 3, 9), AbsenceType: models.AbsenceTypeVacation}, // Samstag
+			{Date: date(2024, 3, 29), AbsenceType: models.AbsenceTypeVacation, Holiday: "Karfreitag"},
+		},
+	}
+	surcharges := []models.SurchargeLine{{Name: "Sonntagsarbeit", Hours: 8}}
+
+	lines := PayrollLines(user, month, surcharges)
+	if !assert.Len(t, lines, 4) {
+		return
+	}
+	assert.Equal(t, models.PayrollCategoryRegular, lines[0].Category)
+	assert.Equal(t, 160.0, lines[0].Hours)
+	assert.Equal(t, models.PayrollCategoryOvertime, lines[1].Category)
+	assert.Equal(t, 10.0, lines[1].Hours)
+	assert.Equal(t, "Sonntagsarbeit", lines[2].Key)
+	assert.Equal(t, models.AbsenceTypeVacation, lines[3].Key)
+	assert.Equal(t, 2.0, lines[3].Days)
+	assert.Equal(t, 16.0, lines[3].Hours)
+	assert.Equal(t, "00042", lines[3].PersonnelNumber)
+
+	// Negativer Saldo ergibt keine Überstunden
+	month.Balance = -5
+	assert.Len(t, PayrollLines(user, month, nil), 2)
+
+	mappings := []models.WageTypeMapping{
+		{Category: models.PayrollCategoryRegular, WageType: "100", Unit: models.PayrollUnitHours},
+		{Category: models.PayrollCategoryAbsence, Key: models.AbsenceTypeVacation, WageType: "300", Unit: models.PayrollUnitDays},
+	}
+	issues := ApplyWageTypes(lines, mappings)
+	if assert.Len(t, issues, 2) {
+		assert.True(t, issues[0].Blocking)
+		assert.Equal(t, "Keine Lohnart für Überstunden zugeordnet", issues[0].Message)
+		assert.Equal(t, `Keine Lohnart für Zuschlag "Sonntagsarbeit" zugeordnet`, issues[1].Message)
+	}
+	assert.Equal(t, "100", lines[0].WageType)
+	assert.Equal(t, 160.0, lines[0].Value)
+	assert.Equal(t, 2.0, lines[3].Value)
+}
+
+func TestLodasFile(t *testing.T) {
+	settings := models.PayrollSettings{ConsultantNumber: "1234567", ClientNumber: "12345"}
+	lines := []models.PayrollLine{
+		{PersonnelNumber: "42", WageType: "100", Unit: models.PayrollUnitHours, Value: 160.5},
+		{PersonnelNumber: "42", WageType: "300", Unit: models.PayrollUnitDays, Value: 2},
+		{PersonnelNumber: "42", Unit: models.PayrollUnitHours, Value: 3}, // Ohne Lohnart
+	}
+
+	file := LodasFile(settings, date(2024, 3, 1), lines)
+	assert.Contains(t, file, "[Allgemein]\r\nZiel=LODAS\r\n")
+	assert.Contains(t, file, "BeraterNr=1234567\r\nMandantenNr=12345\r\n")
+	assert.Contains(t, file, "10;01032024;1;160,50;100;42;\r\n")
+	assert.Contains(t, file, "10;01032024;2;2,00;300;42;\r\n")
+	assert.Equal(t, 2, strings.Count(file, "10;01032024;"))
+}
+
+func TestPayrollValidation(t *testing.T) {
+	assert.NoError(t, ValidatePersonnelNumber("12345"))
+	assert.Error(t, ValidatePersonnelNumber(""))
+	assert.Error(t, ValidatePersonnelNumber("123456"))
+	assert.Error(t, ValidatePersonnelNumber("A12"))
+
+	assert.NoError(t, ValidatePayrollSettings(models.PayrollSettings{ConsultantNumber: "1234", ClientNumber: "1"}))
+	assert.Error(t, ValidatePayrollSettings(models.PayrollSettings{ConsultantNumber: "123", ClientNumber: "1"}))
+	assert.Error(t, ValidatePayrollSettings(models.PayrollSettings{ConsultantNumber: "1234", ClientNumber: ""}))
+
+	valid := models.WageTypeMapping{Category: models.PayrollCategorySurcharge, Key: "Nachtarbeit", WageType: "200", Unit: models.PayrollUnitHours}
+	assert.NoError(t, ValidateWageTypeMapping(valid))
+	assert.Error(t, ValidateWageTypeMapping(models.WageTypeMapping{Category: models.PayrollCategorySurcharge, WageType: "200", Unit: models.PayrollUnitHours}))
+	assert.Error(t, ValidateWageTypeMapping(models.WageTypeMapping{Category: models.PayrollCategoryRegular, Key: "x", WageType: "100", Unit: models.PayrollUnitHours}))
+	assert.Error(t, ValidateWageTypeMapping(models.WageTypeMapping{Category: models.PayrollCategoryAbsence, Key: "unbekannt", WageType: "300", Unit: models.PayrollUnitDays}))
+	assert.Error(t, ValidateWageTypeMapping(models.WageTypeMapping{Category: models.PayrollCategoryRegular, WageType: "10000", Unit: models.PayrollUnitHours}))
+	assert.Error(t, ValidateWageTypeMapping(models.WageTypeMapping{Category: "bonus", WageType: "100", Unit: models.PayrollUnitHours}))
+}
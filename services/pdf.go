@@ -0,0 +1,236 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Seitenformate in Punkt (1/72 Zoll)
+const (
+	PDFA4Width  = 595.28
+	PDFA4Height = 841.89
+)
+
+// PDFDocument ist ein einfaches PDF-Dokument mit den Standardschriften Helvetica und Helvetica-Bold.
+// Texte werden in WinAnsiEncoding geschrieben, Umlaute und ß sind daher ohne eingebettete Schrift möglich.
+type PDFDocument struct {
+	Title string
+	pages []*PDFPage
+}
+
+// PDFPage ist eine Seite eines PDF-Dokuments. Koordinaten beginnen oben links und wachsen nach rechts und unten.
+type PDFPage struct {
+	Width   float64
+	Height  float64
+	content bytes.Buffer
+}
+
+// AddPage fügt eine leere Seite mit der Breite und Höhe in Punkt hinzu
+func (d *PDFDocument) AddPage(width, height float64) *PDFPage {
+	page := &PDFPage{Width: width, Height: height}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// PageCount liefert die Anzahl der Seiten
+func (d *PDFDocument) PageCount() int {
+	return len(d.pages)
+}
+
+// FillRect füllt ein Rechteck mit einer Hex-Farbe; ungültige Farben werden ignoriert
+func (p *PDFPage) FillRect(x, y, width, height float64, color string) {
+	rgb, ok := pdfColor(color)
+	if !ok {
+		return
+	}
+	fmt.Fprintf(&p.content, "%s rg %s %s %s %s re f\n", rgb, pdfNumber(x), pdfNumber(p.Height-y-height), pdfNumber(width), pdfNumber(height))
+}
+
+// StrokeRect zeichnet den Rahmen eines Rechtecks in Schwarz
+func (p *PDFPage) StrokeRect(x, y, width, height, lineWidth float64) {
+	fmt.Fprintf(&p.content, "0 0 0 RG %s w %s %s %s %s re S\n", pdfNumber(lineWidth), pdfNumber(x), pdfNumber(p.Height-y-height), pdfNumber(width), pdfNumber(height))
+}
+
+// Line zeichnet eine schwarze Linie
+func (p *PDFPage) Line(x1, y1, x2, y2, lineWidth float64) {
+	fmt.Fprintf(&p.content, "0 0 0 RG %s w %s %s m %s %s l S\n", pdfNumber(lineWidth), pdfNumber(x1), pdfNumber(p.Height-y1), pdfNumber(x2), pdfNumber(p.Height-y2))
+}
+
+// Text schreibt text mit der Grundlinie bei y in der Schriftgröße size; color ist eine Hex-Farbe (leer = Schwarz)
+func (p *PDFPage) Text(x, y, size float64, bold bool, color, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	rgb, ok := pdfColor(color)
+	if !ok {
+		rgb = "0 0 0"
+	}
+	fmt.Fprintf(&p.content, "BT %s rg /%s %s Tf %s %s Td (%s) Tj ET\n", rgb, font, pdfNumber(size), pdfNumber(x), pdfNumber(p.Height-y), pdfString(text))
+}
+
+// TextCentered schreibt text zentriert um x
+func (p *PDFPage) TextCentered(x, y, size float64, bold bool, color, text string) {
+	p.Text(x-PDFTextWidth(text, size, bold)/2, y, size, bold, color, text)
+}
+
+// Bytes erzeugt die PDF-Datei (PDF 1.4, komprimierte Seiteninhalte)
+func (d *PDFDocument) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	offsets := []int{0}
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets)-1, body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// Objekte 1 bis 5: Katalog, Seitenbaum, Schriften und Dokumentinformationen; danach je Seite Seite und Inhalt
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Producer (Schichtplaner) >>", pdfString(d.Title)))
+
+	for i, page := range d.pages {
+		var content bytes.Buffer
+		writer := zlib.NewWriter(&content)
+		if _, err := writer.Write(page.content.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := writer.Close(); err != nil {
+			return nil, err
+		}
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfNumber(page.Width), pdfNumber(page.Height), 7+2*i))
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", content.Len(), content.Bytes()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets))
+	for _, offset := range offsets[1:] {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets), xref)
+	return buf.Bytes(), nil
+}
+
+// PDFTextWidth liefert die Breite von text in Punkt anhand der Zeichenbreiten von Helvetica
+func PDFTextWidth(text string, size float64, bold bool) float64 {
+	widths := helveticaWidths
+	if bold {
+		widths = helveticaBoldWidths
+	}
+	total := 0
+	for _, r := range text {
+		if base, ok := pdfBaseLetters[r]; ok {
+			r = base
+		}
+		switch {
+		case r >= 32 && r <= 126:
+			total += widths[r-32]
+		case r == 'ß':
+			total += 611
+		case r == '…' || r == '—':
+			total += 1000
+		default:
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// PDFFitText kürzt text mit Auslassungspunkten, bis er höchstens width Punkt breit ist
+func PDFFitText(text string, width, size float64, bold bool) string {
+	if PDFTextWidth(text, size, bold) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if candidate := strings.TrimSpace(string(runes)) + "…"; PDFTextWidth(candidate, size, bold) <= width {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// pdfNumber formatiert eine Koordinate mit höchstens zwei Nachkommastellen
+func pdfNumber(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// pdfColor wandelt eine Hex-Farbe in RGB-Anteile für die Operatoren rg und RG
+func pdfColor(color string) (string, bool) {
+	if color == "" {
+		return "", false
+	}
+	value, err := strconv.ParseUint(normalizeHexColor(color), 16, 32)
+	if err != nil {
+		return "", false
+	}
+	channel := func(shift uint) string {
+		return strconv.FormatFloat(float64(value>>shift&0xFF)/255, 'f', 3, 64)
+	}
+	return channel(16) + " " + channel(8) + " " + channel(0), true
+}
+
+// pdfString kodiert text in WinAnsiEncoding und maskiert Klammern und Backslashes; nicht darstellbare Zeichen werden zu ?
+func pdfString(text string) string {
+	var buf strings.Builder
+	for _, r := range text {
+		var b byte
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			buf.WriteByte('\\')
+			b = byte(r)
+		case r >= 32 && r <= 126, r >= 0xA0 && r <= 0xFF:
+			b = byte(r)
+		default:
+			code, ok := winAnsiSpecials[r]
+			if !ok {
+				code = '?'
+			}
+			b = code
+		}
+		buf.WriteByte(b)
+	}
+	return buf.String()
+}
+
+// winAnsiSpecials sind Zeichen, die WinAnsiEncoding im Bereich 0x80–0x9F ablegt
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+}
+
+// pdfBaseLetters ordnet Umlauten den Grundbuchstaben gleicher Breite zu
+var pdfBaseLetters = map[rune]rune{
+	'ä': 'a', 'ö': 'o', 'ü': 'u', 'Ä': 'A', 'Ö': 'O', 'Ü': 'U', 'é': 'e', 'è': 'e', 'á': 'a', 'à': 'a', 'ó': 'o', 'ç': 'c',
+}
+
+// helveticaWidths sind die Zeichenbreiten von Helvetica für die Zeichen 32 bis 126 (in 1/1000 der Schriftgröße)
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// helveticaBoldWidths sind die Zeichenbreiten von Helvetica-Bold für die Zeichen 32 bis 126
+var helveticaBoldWidths = [...]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+	975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+	333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+	611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// Regeln der Qualifikationsprüfung
+const (
+	RuleQualificationMissing = "qualification_missing" // Benutzer besitzt eine geforderte Qualifikation nicht
+	RuleQualificationExpired = "qualification_expired" // Nachweis ist am Tag der Schicht nicht (mehr) gültig
+)
+
+// ValidateQualification prüft einen Eintrag im Qualifikationskatalog
+func ValidateQualification(qualification models.Qualification) error {
+	if qualification.Enforcement != models.QualificationEnforcementWarn && qualification.Enforcement != models.QualificationEnforcementBlock {
+		return fmt.Errorf("unbekannte Durchsetzung: %s", qualification.Enforcement)
+	}
+	if qualification.ValidityMonths < 0 || qualification.ValidityMonths > 1200 {
+		return fmt.Errorf("Gültigkeit muss zwischen 0 und 1200 Monaten liegen")
+	}
+	return nil
+}
+
+// DefaultExpiry liefert das Ablaufdatum eines neuen Nachweises aus der Standardgültigkeit der Qualifikation:
+// den Vortag nach Ablauf von ValidityMonths Monaten ab acquired. Ohne Standardgültigkeit ist der Nachweis unbefristet.
+func DefaultExpiry(qualification models.Qualification, acquired time.Time) *time.Time {
+	if qualification.ValidityMonths <= 0 {
+		return nil
+	}
+	expires := acquired.AddDate(0, qualification.ValidityMonths, -1)
+	return &expires
+}
+
+// RequiredQualificationIDs vereinigt die geforderten Qualifikationen einer Schicht und ihres Schichttyps
+func RequiredQualificationIDs(shift models.Shift, shiftType *models.ShiftType) []uint {
+	seen := make(map[uint]bool)
+	var ids []uint
+	add := func(list []uint) {
+		for _, id := range list {
+			if id != 0 && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	if shiftType != nil {
+		add(shiftType.RequiredQualificationIDs)
+	}
+	add(shift.RequiredQualificationIDs)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// CheckQualifications prüft, ob die Nachweise held des Benutzers am Kalendertag des Schichtbeginns in loc alle
+// geforderten Qualifikationen required abdecken. Inaktive Qualifikationen werden übersprungen; der Schweregrad
+// ist error bei Durchsetzung block, sonst warning.
+func CheckQualifications(shift models.Shift, required []models.Qualification, held []models.UserQualification, loc *time.Location) []models.ComplianceViolation {
+	local := shift.StartTime.In(loc)
+	day := date(local.Year(), local.Month(), local.Day())
+
+	var violations []models.ComplianceViolation
+	for _, qualification := range required {
+		if !qualification.IsActive {
+			continue
+		}
+
+		severity := models.SeverityWarning
+		if qualification.Enforcement == models.QualificationEnforcementBlock {
+			severity = models.SeverityError
+		}
+
+		var latest *models.UserQualification
+		valid := false
+		for i := range held {
+			if held[i].QualificationID != qualification.ID {
+				continue
+			}
+			if held[i].ValidOn(day) {
+				valid = true
+				break
+			}
+			latest = &held[i]
+		}
+		if valid {
+			continue
+		}
+
+		violation := models.ComplianceViolation{
+			Rule:     RuleQualificationMissing,
+			Severity: severity,
+			UserID:   shift.UserID,
+			ShiftID:  shift.ID,
+			Date:     shift.StartTime,
+			Message:  fmt.Sprintf("Qualifikation %q fehlt", qualification.Name),
+		}
+		if latest != nil {
+			violation.Rule = RuleQualificationExpired
+			if latest.ExpiresOn != nil && day.After(*latest.ExpiresOn) {
+				violation.Message = fmt.Sprintf("Qualifikation %q ist seit %s abgelaufen", qualification.Name, latest.ExpiresOn.AddDate(0, 0, 1).Format("02.01.2006"))
+			} else {
+				violation.Message = fmt.Sprintf("Qualifikation %q ist erst ab %s gültig", qualification.Name, latest.AcquiredOn.Format("02.01.2006"))
+			}
+		}
+		violations = append(violations, violation)
+	}
+	return violations
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequiredQualificationIDs(t *testing.T) {
+	shiftType := models.ShiftType{RequiredQualificationIDs: []uint{3, 1}}
+	shift := models.Shift{RequiredQualificationIDs: []uint{2, 3}}
+
+	assert.Equal(t, []uint{1, 2, 3}, RequiredQualificationIDs(shift, &shiftType))
+	assert.Equal(t, []uint{2, 3}, RequiredQualificationIDs(shift, nil))
+	assert.Empty(t, RequiredQualificationIDs(models.Shift{}, nil))
+}
+
+func TestDefaultExpiry(t *testing.T) {
+	acquired := date(2024, time.March, 1)
+	assert.Nil(t, DefaultExpiry(models.Qualification{}, acquired))
+
+	expires := DefaultExpiry(models.Qualification{ValidityMonths: 24}, acquired)
+	if assert.NotNil(t, expires) {
+		assert.Equal(t, date(2026, time.February, 28), *expires)
+	}
+}
+
+func TestCheckQualifications(t *testing.T) {
+	firstAid := models.Qualification{Base: models.Base{ID: 1}, Name: "Ersthelfer", Enforcement: models.QualificationEnforcementWarn, IsActive: true}
+	forklift := models.Qualification{Base: models.Base{ID: 2}, Name: "Staplerschein", Enforcement: models.QualificationEnforcementBlock, IsActive: true}
+	inactive := models.Qualification{Base: models.Base{ID: 3}, Name: "Alt", IsActive: false}
+
+	shift := models.Shift{
+		Base:      models.Base{ID: 7},
+		UserID:    5,
+		StartTime: time.Date(2024, 6, 10, 6, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2024, 6, 10, 14, 0, 0, 0, time.UTC),
+	}
+
+	// Ersthelfer-Nachweis endet am Vortag, Staplerschein fehlt
+	expired := date(2024, time.June, 9)
+	held := []models.UserQualification{{UserID: 5, QualificationID: 1, ExpiresOn: &expired}}
+
+	violations := CheckQualifications(shift, []models.Qualification{firstAid, forklift, inactive}, held, time.UTC)
+	if assert.Len(t, violations, 2) {
+		assert.Equal(t, RuleQualificationExpired, violations[0].Rule)
+		assert.Equal(t, models.SeverityWarning, violations[0].Severity)
+		assert.Contains(t, violations[0].Message, "10.06.2024")
+		assert.Equal(t, RuleQualificationMissing, violations[1].Rule)
+		assert.Equal(t, models.SeverityError, violations[1].Severity)
+		assert.Equal(t, uint(7), violations[1].ShiftID)
+	}
+
+	// Gültig bis einschließlich Schichttag
+	valid := date(2024, time.June, 10)
+	held = []models.UserQualification{
+		{UserID: 5, QualificationID: 1, ExpiresOn: &valid},
+		{UserID: 5, QualificationID: 2},
+	}
+	assert.Empty(t, CheckQualifications(shift, []models.Qualification{firstAid, forklift}, held, time.UTC))
+
+	// Erwerb erst nach dem Schichttag
+	acquired := date(2024, time.July, 1)
+	held = []models.UserQualification{{UserID: 5, QualificationID: 1, AcquiredOn: &acquired}}
+	violations = CheckQualifications(shift, []models.Qualification{firstAid}, held, time.UTC)
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, RuleQualificationExpired, violations[0].Rule)
+		assert.Contains(t, violations[0].Message, "erst ab 01.07.2024")
+	}
+}
@@ -0,0 +1,499 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency ist die Wiederholungsfrequenz einer RRULE (FREQ)
+type Frequency string
+
+const (
+	FrequencyDaily   Frequency = "DAILY"
+	FrequencyWeekly  Frequency = "WEEKLY"
+	FrequencyMonthly Frequency = "MONTHLY"
+	FrequencyYearly  Frequency = "YEARLY"
+)
+
+// maxRecurrencePeriods begrenzt die Anzahl der durchlaufenen Perioden bei der Expansion
+const maxRecurrencePeriods = 10000
+
+// RecurrenceWeekday ist ein Wochentag aus BYDAY, optional mit Ordinalzahl (z.B. 1MO, -1FR)
+type RecurrenceWeekday struct {
+	Weekday time.Weekday
+	N       int // 0 = jedes Vorkommen in der Periode
+}
+
+// RecurrenceRule ist eine geparste RRULE nach RFC 5545
+type RecurrenceRule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []RecurrenceWeekday
+	ByMonthDay []int
+	ByMonth    []time.Month
+	BySetPos   []int
+	WeekStart  time.Weekday
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// ParseRRule parst eine RRULE wie "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU"
+func ParseRRule(value string) (*RecurrenceRule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, fmt.Errorf("RRULE ist leer")
+	}
+
+	rule := &RecurrenceRule{Interval: 1, WeekStart: time.Monday}
+
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("ungültiger RRULE-Bestandteil: %s", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			freq := Frequency(strings.ToUpper(val))
+			switch freq {
+			case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
+				rule.Freq = freq
+			default:
+				return nil, fmt.Errorf("nicht unterstützte Frequenz: %s", val)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("ungültiges INTERVAL: %s", val)
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("ungültiges COUNT: %s", val)
+			}
+			rule.Count = count
+		case "UNTIL":
+			until, err := parseICalTime(val)
+			if err != nil {
+				return nil, fmt.Errorf("ungültiges UNTIL: %s", val)
+			}
+			rule.Until = until
+		case "BYDAY":
+			for _, item := range strings.Split(val, ",") {
+				weekday, err := parseRecurrenceWeekday(item)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			days, err := parseIntList(val, -31, 31)
+			if err != nil {
+				return nil, fmt.Errorf("ungültiges BYMONTHDAY: %s", val)
+			}
+			rule.ByMonthDay = days
+		case "BYMONTH":
+			months, err := parseIntList(val, 1, 12)
+			if err != nil {
+				return nil, fmt.Errorf("ungültiges BYMONTH: %s", val)
+			}
+			for _, month := range months {
+				rule.ByMonth = append(rule.ByMonth, time.Month(month))
+			}
+		case "BYSETPOS":
+			positions, err := parseIntList(val, -366, 366)
+			if err != nil {
+				return nil, fmt.Errorf("ungültiges BYSETPOS: %s", val)
+			}
+			rule.BySetPos = positions
+		case "WKST":
+			weekday, ok := weekdayCodes[strings.ToUpper(val)]
+			if !ok {
+				return nil, fmt.Errorf("ungültiges WKST: %s", val)
+			}
+			rule.WeekStart = weekday
+		default:
+			return nil, fmt.Errorf("nicht unterstützter RRULE-Bestandteil: %s", key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("FREQ ist ein Pflichtbestandteil der RRULE")
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, fmt.Errorf("COUNT und UNTIL dürfen nicht gemeinsam verwendet werden")
+	}
+	if rule.Freq == FrequencyDaily || rule.Freq == FrequencyWeekly {
+		for _, weekday := range rule.ByDay {
+			if weekday.N != 0 {
+				return nil, fmt.Errorf("BYDAY mit Ordinalzahl ist nur bei MONTHLY und YEARLY erlaubt")
+			}
+		}
+	}
+
+	return rule, nil
+}
+
+// String gibt die RRULE in kanonischer Form zurück
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, weekday := range r.ByDay {
+			code := weekdayCode(weekday.Weekday)
+			if weekday.N != 0 {
+				code = strconv.Itoa(weekday.N) + code
+			}
+			days = append(days, code)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]int, 0, len(r.ByMonth))
+		for _, month := range r.ByMonth {
+			months = append(months, int(month))
+		}
+		parts = append(parts, "BYMONTH="+joinInts(months))
+	}
+	if len(r.BySetPos) > 0 {
+		parts = append(parts, "BYSETPOS="+joinInts(r.BySetPos))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCode(r.WeekStart))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Between liefert alle Vorkommen ab dtstart, deren Beginn im Intervall [from, to] liegt.
+// Die Uhrzeit wird als Wanduhrzeit in der Zeitzone von dtstart fortgeschrieben.
+// EXDATE-Einträge werden nach der Zählung von COUNT entfernt (RFC 5545, Abschnitt 3.8.5.1).
+func (r *RecurrenceRule) Between(dtstart, from, to time.Time, exdates []time.Time) []time.Time {
+	var occurrences []time.Time
+	emitted := 0
+
+	for period := 0; period < maxRecurrencePeriods; period++ {
+		if r.periodStart(dtstart, period).After(to) {
+			return occurrences
+		}
+		candidates := r.periodCandidates(dtstart, period)
+
+		for _, candidate := range candidates {
+			if candidate.Before(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && candidate.After(r.Until) {
+				return occurrences
+			}
+			if candidate.After(to) {
+				return occurrences
+			}
+
+			emitted++
+			if !candidate.Before(from) && !containsTime(exdates, candidate) {
+				occurrences = append(occurrences, candidate)
+			}
+			if r.Count > 0 && emitted >= r.Count {
+				return occurrences
+			}
+		}
+	}
+
+	return occurrences
+}
+
+// periodStart liefert den Beginn der n-ten Periode; kein Kandidat der Periode liegt davor
+func (r *RecurrenceRule) periodStart(dtstart time.Time, n int) time.Time {
+	loc := dtstart.Location()
+	step := n * r.Interval
+	switch r.Freq {
+	case FrequencyWeekly:
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		return time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day()-offset+7*step, 0, 0, 0, 0, loc)
+	case FrequencyMonthly:
+		return time.Date(dtstart.Year(), dtstart.Month()+time.Month(step), 1, 0, 0, 0, 0, loc)
+	case FrequencyYearly:
+		return time.Date(dtstart.Year()+step, time.January, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day()+step, 0, 0, 0, 0, loc)
+	}
+}
+
+// periodCandidates erzeugt die sortierten Kandidaten der n-ten Periode ab dtstart
+func (r *RecurrenceRule) periodCandidates(dtstart time.Time, n int) []time.Time {
+	loc := dtstart.Location()
+	hour, minute, second := dtstart.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, 0, loc)
+	}
+
+	var days []time.Time
+	step := n * r.Interval
+
+	switch r.Freq {
+	case FrequencyDaily:
+		day := at(dtstart.Year(), dtstart.Month(), dtstart.Day()+step)
+		if r.matchesMonth(day) && r.matchesMonthDay(day) && r.matchesWeekday(day) {
+			days = append(days, day)
+		}
+
+	case FrequencyWeekly:
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekStart := at(dtstart.Year(), dtstart.Month(), dtstart.Day()-offset+7*step)
+		for i := 0; i < 7; i++ {
+			day := at(weekStart.Year(), weekStart.Month(), weekStart.Day()+i)
+			if len(r.ByDay) == 0 && day.Weekday() != dtstart.Weekday() {
+				continue
+			}
+			if r.matchesMonth(day) && r.matchesWeekday(day) {
+				days = append(days, day)
+			}
+		}
+
+	case FrequencyMonthly:
+		first := at(dtstart.Year(), dtstart.Month()+time.Month(step), 1)
+		if r.matchesMonth(first) {
+			days = r.monthCandidates(first, dtstart.Day(), at)
+		}
+
+	case FrequencyYearly:
+		year := dtstart.Year() + step
+		switch {
+		case len(r.ByMonth) > 0:
+			for _, month := range r.ByMonth {
+				days = append(days, r.monthCandidates(at(year, month, 1), dtstart.Day(), at)...)
+			}
+		case len(r.ByDay) > 0:
+			days = r.yearWeekdayCandidates(year, at)
+		case len(r.ByMonthDay) > 0:
+			days = r.monthCandidates(at(year, dtstart.Month(), 1), dtstart.Day(), at)
+		default:
+			day := at(year, dtstart.Month(), dtstart.Day())
+			if day.Day() == dtstart.Day() {
+				days = append(days, day)
+			}
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return applySetPos(days, r.BySetPos)
+}
+
+// monthCandidates liefert die Tage eines Monats gemäß BYMONTHDAY und BYDAY
+func (r *RecurrenceRule) monthCandidates(first time.Time, defaultDay int, at func(int, time.Month, int) time.Time) []time.Time {
+	year, month := first.Year(), first.Month()
+	daysInMonth := at(year, month+1, 0).Day()
+
+	var days []time.Time
+	if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+		if defaultDay <= daysInMonth {
+			days = append(days, at(year, month, defaultDay))
+		}
+		return days
+	}
+
+	for day := 1; day <= daysInMonth; day++ {
+		candidate := at(year, month, day)
+		if len(r.ByMonthDay) > 0 && !r.matchesMonthDay(candidate) {
+			continue
+		}
+		if len(r.ByDay) > 0 && !matchesOrdinalWeekday(r.ByDay, candidate, day, daysInMonth) {
+			continue
+		}
+		days = append(days, candidate)
+	}
+	return days
+}
+
+// yearWeekdayCandidates liefert die Wochentage eines Jahres gemäß BYDAY (Ordinalzahl bezogen auf das Jahr)
+func (r *RecurrenceRule) yearWeekdayCandidates(year int, at func(int, time.Month, int) time.Time) []time.Time {
+	daysInYear := at(year, time.December, 31).YearDay()
+
+	var days []time.Time
+	for yearDay := 1; yearDay <= daysInYear; yearDay++ {
+		candidate := at(year, time.January, yearDay)
+		if matchesOrdinalWeekday(r.ByDay, candidate, yearDay, daysInYear) {
+			days = append(days, candidate)
+		}
+	}
+	return days
+}
+
+// matchesMonth prüft den BYMONTH-Filter
+func (r *RecurrenceRule) matchesMonth(t time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, month := range r.ByMonth {
+		if t.Month() == month {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesMonthDay prüft den BYMONTHDAY-Filter, negative Werte zählen vom Monatsende
+func (r *RecurrenceRule) matchesMonthDay(t time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	daysInMonth := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, day := range r.ByMonthDay {
+		if day == t.Day() || (day < 0 && daysInMonth+day+1 == t.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWeekday prüft den BYDAY-Filter ohne Ordinalzahlen
+func (r *RecurrenceRule) matchesWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, weekday := range r.ByDay {
+		if weekday.Weekday == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesOrdinalWeekday prüft BYDAY mit Ordinalzahl; position und length beziehen sich auf Monat oder Jahr
+func matchesOrdinalWeekday(byDay []RecurrenceWeekday, t time.Time, position, length int) bool {
+	for _, weekday := range byDay {
+		if weekday.Weekday != t.Weekday() {
+			continue
+		}
+		switch {
+		case weekday.N == 0:
+			return true
+		case weekday.N > 0 && (position-1)/7+1 == weekday.N:
+			return true
+		case weekday.N < 0 && (length-position)/7+1 == -weekday.N:
+			return true
+		}
+	}
+	return false
+}
+
+// applySetPos wendet BYSETPOS auf die sortierten Kandidaten einer Periode an
+func applySetPos(days []time.Time, positions []int) []time.Time {
+	if len(positions) == 0 {
+		return days
+	}
+	var selected []time.Time
+	for _, pos := range positions {
+		index := pos - 1
+		if pos < 0 {
+			index = len(days) + pos
+		}
+		if index >= 0 && index < len(days) && !containsTime(selected, days[index]) {
+			selected = append(selected, days[index])
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Before(selected[j]) })
+	return selected
+}
+
+// parseRecurrenceWeekday parst einen BYDAY-Eintrag wie "TU", "1MO" oder "-1FR"
+func parseRecurrenceWeekday(value string) (RecurrenceWeekday, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) < 2 {
+		return RecurrenceWeekday{}, fmt.Errorf("ungültiger BYDAY-Eintrag: %s", value)
+	}
+	weekday, ok := weekdayCodes[value[len(value)-2:]]
+	if !ok {
+		return RecurrenceWeekday{}, fmt.Errorf("ungültiger BYDAY-Eintrag: %s", value)
+	}
+	result := RecurrenceWeekday{Weekday: weekday}
+	if prefix := value[:len(value)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -53 || n > 53 {
+			return RecurrenceWeekday{}, fmt.Errorf("ungültiger BYDAY-Eintrag: %s", value)
+		}
+		result.N = n
+	}
+	return result, nil
+}
+
+// parseICalTime parst einen iCalendar DATE- oder DATE-TIME-Wert
+func parseICalTime(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			if layout == "20060102" {
+				// Ein reines Datum schließt den gesamten Tag ein
+				return t.Add(24*time.Hour - time.Second), nil
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("ungültiger Zeitwert: %s", value)
+}
+
+// parseIntList parst eine kommagetrennte Zahlenliste im Bereich [min, max] ohne 0
+func parseIntList(value string, min, max int) ([]int, error) {
+	var numbers []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || n == 0 || n < min || n > max {
+			return nil, fmt.Errorf("ungültiger Wert: %s", item)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+func weekdayCode(weekday time.Weekday) string {
+	for code, day := range weekdayCodes {
+		if day == weekday {
+			return code
+		}
+	}
+	return ""
+}
+
+func joinInts(numbers []int) string {
+	items := make([]string, 0, len(numbers))
+	for _, n := range numbers {
+		items = append(items, strconv.Itoa(n))
+	}
+	return strings.Join(items, ",")
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, candidate := range times {
+		if candidate.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRRule(t *testing.T) {
+	rule, err := ParseRRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=TU")
+	assert.NoError(t, err)
+	assert.Equal(t, FrequencyWeekly, rule.Freq)
+	assert.Equal(t, 2, rule.Interval)
+	assert.Equal(t, []RecurrenceWeekday{{Weekday: time.Tuesday}}, rule.ByDay)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU", rule.String())
+
+	rule, err = ParseRRule("RRULE:FREQ=MONTHLY;BYDAY=1MO,-1FR;UNTIL=20240630T000000Z")
+	assert.NoError(t, err)
+	assert.Equal(t, []RecurrenceWeekday{{Weekday: time.Monday, N: 1}, {Weekday: time.Friday, N: -1}}, rule.ByDay)
+	assert.Equal(t, time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC), rule.Until)
+}
+
+func TestParseRRule_Invalid(t *testing.T) {
+	invalid := []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=WEEKLY;INTERVAL=0",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=DAILY;COUNT=3;UNTIL=20240101",
+		"FREQ=DAILY;BYHOUR=5",
+	}
+
+	for _, value := range invalid {
+		_, err := ParseRRule(value)
+		assert.Error(t, err, "RRULE %q sollte ungültig sein", value)
+	}
+}
+
+func TestRecurrenceRule_EverySecondTuesday(t *testing.T) {
+	rule, err := ParseRRule("FREQ=WEEKLY;INTERVAL=2;BYDAY=TU")
+	assert.NoError(t, err)
+
+	dtstart := time.Date(2024, 1, 2, 6, 0, 0, 0, time.UTC) // Dienstag
+	occurrences := rule.Between(dtstart, dtstart, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), nil)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 1, 2, 6, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 16, 6, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 30, 6, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 13, 6, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 27, 6, 0, 0, 0, time.UTC),
+	}, occurrences)
+}
+
+func TestRecurrenceRule_FirstMondayOfMonth(t *testing.T) {
+	rule, err := ParseRRule("FREQ=MONTHLY;BYDAY=1MO;COUNT=3")
+	assert.NoError(t, err)
+
+	dtstart := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	occurrences := rule.Between(dtstart, dtstart, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 5, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 4, 8, 0, 0, 0, time.UTC),
+	}, occurrences)
+}
+
+func TestRecurrenceRule_LastWorkdayOfMonth(t *testing.T) {
+	rule, err := ParseRRule("FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1")
+	assert.NoError(t, err)
+
+	dtstart := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	occurrences := rule.Between(dtstart, dtstart, time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC), nil)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 1, 31, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 29, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 29, 8, 0, 0, 0, time.UTC),
+	}, occurrences)
+}
+
+func TestRecurrenceRule_ExDatesDoNotExtendCount(t *testing.T) {
+	rule, err := ParseRRule("FREQ=DAILY;COUNT=3")
+	assert.NoError(t, err)
+
+	dtstart := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	exdates := []time.Time{time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)}
+	occurrences := rule.Between(dtstart, dtstart, time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), exdates)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 3, 8, 0, 0, 0, time.UTC),
+	}, occurrences)
+}
+
+func TestRecurrenceRule_KeepsWallClockAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("Zeitzonendaten nicht verfügbar")
+	}
+
+	rule, err := ParseRRule("FREQ=WEEKLY;BYDAY=SA")
+	assert.NoError(t, err)
+
+	dtstart := time.Date(2024, 3, 30, 6, 0, 0, 0, berlin)
+	occurrences := rule.Between(dtstart, dtstart, time.Date(2024, 4, 7, 0, 0, 0, 0, berlin), nil)
+
+	assert.Len(t, occurrences, 2)
+	assert.Equal(t, 6, occurrences[1].Hour())
+	assert.Equal(t, 7*24*time.Hour-time.Hour, occurrences[1].Sub(occurrences[0]))
+}
+
+func TestExpandRecurringShift(t *testing.T) {
+	series := models.RecurringShift{
+		Base:        models.Base{ID: 7},
+		UserID:      1,
+		ShiftTypeID: 2,
+		ScheduleID:  3,
+		RRule:       "FREQ=WEEKLY;BYDAY=MO",
+		StartTime:   time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC),
+		EndTime:     time.Date(2024, 1, 1, 14, 0, 0, 0, time.UTC),
+		Count:       4,
+		BreakTime:   30,
+		Exceptions: []models.RecurringShiftException{
+			{Date: time.Date(2024, 1, 15, 6, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	shifts, err := ExpandRecurringShift(series, series.StartTime, time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Len(t, shifts, 3)
+
+	for _, shift := range shifts {
+		assert.Equal(t, uint(1), shift.UserID)
+		assert.Equal(t, uint(2), *shift.ShiftTypeID)
+		assert.Equal(t, uint(3), shift.ScheduleID)
+		assert.Equal(t, uint(7), *shift.RecurringShiftID)
+		assert.Equal(t, 8*time.Hour, shift.EndTime.Sub(shift.StartTime))
+		assert.Equal(t, shift.StartTime, *shift.RecurrenceID)
+	}
+	assert.True(t, time.Date(2024, 1, 22, 6, 0, 0, 0, time.UTC).Equal(shifts[2].StartTime))
+
+	before, err := CountOccurrencesBefore(series, time.Date(2024, 1, 15, 6, 0, 0, 0, time.UTC))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, before)
+}
+
+func TestExpandRecurringShift_NightShiftAcrossDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	assert.NoError(t, err)
+
+	// Nachtschicht 22:00 bis 06:00 Ortszeit, gespeichert in UTC wie aus der Datenbank
+	series := models.RecurringShift{
+		RRule:     "FREQ=DAILY",
+		TimeZone:  "Europe/Berlin",
+		StartTime: time.Date(2024, 3, 29, 22, 0, 0, 0, berlin).UTC(),
+		EndTime:   time.Date(2024, 3, 30, 6, 0, 0, 0, berlin).UTC(),
+	}
+
+	shifts, err := ExpandRecurringShift(series, series.StartTime, time.Date(2024, 4, 1, 0, 0, 0, 0, berlin))
+	assert.NoError(t, err)
+	if assert.Len(t, shifts, 3) {
+		for _, shift := range shifts {
+			assert.Equal(t, 22, shift.StartTime.In(berlin).Hour())
+			assert.Equal(t, 6, shift.EndTime.In(berlin).Hour())
+		}
+		assert.Equal(t, 8*time.Hour, shifts[0].EndTime.Sub(shifts[0].StartTime))
+		assert.Equal(t, 7*time.Hour, shifts[1].EndTime.Sub(shifts[1].StartTime)) // Nacht zum 31.03.
+		assert.Equal(t, 8*time.Hour, shifts[2].EndTime.Sub(shifts[2].StartTime))
+	}
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"time"
+
+	"schichtplaner/models"
+)
+
+// RecurrenceRuleFor liefert die RRULE einer Serie; Until und Count der Serie haben Vorrang vor der RRULE
+func RecurrenceRuleFor(series models.RecurringShift) (*RecurrenceRule, error) {
+	rule, err := ParseRRule(series.RRule)
+	if err != nil {
+		return nil, err
+	}
+	if series.Until != nil {
+		rule.Until = *series.Until
+		rule.Count = 0
+	}
+	if series.Count > 0 {
+		rule.Count = series.Count
+		rule.Until = time.Time{}
+	}
+	return rule, nil
+}
+
+// ExpandRecurringShift erzeugt die Schichten einer Serie, deren Beginn im Intervall [from, to] liegt.
+// Die Uhrzeit bleibt in der Zeitzone der Serie konstant, auch über Zeitumstellungen hinweg.
+// Ausnahmen (EXDATE) der Serie werden übersprungen, die Schichten sind noch nicht gespeichert.
+func ExpandRecurringShift(series models.RecurringShift, from, to time.Time) ([]models.Shift, error) {
+	rule, err := RecurrenceRuleFor(series)
+	if err != nil {
+		return nil, err
+	}
+
+	exdates := make([]time.Time, 0, len(series.Exceptions))
+	for _, exception := range series.Exceptions {
+		exdates = append(exdates, exception.Date)
+	}
+
+	loc := series.Location()
+	dtstart := series.StartTime.In(loc)
+	dtend := series.EndTime.In(loc)
+	seriesID := series.ID
+	shiftTypeID := series.ShiftTypeID
+
+	var shifts []models.Shift
+	for _, start := range rule.Between(dtstart, from, to, exdates) {
+		recurrenceID := start
+		shifts = append(shifts, models.Shift{
+			UserID:           series.UserID,
+			ShiftTypeID:      &shiftTypeID,
+			StartTime:        start,
+			EndTime:          occurrenceEnd(dtstart, dtend, start),
+			BreakTime:        series.BreakTime,
+			Description:      series.Description,
+			IsActive:         series.IsActive,
+			ScheduleID:       series.ScheduleID,
+			RecurringShiftID: &seriesID,
+			RecurrenceID:     &recurrenceID,
+		})
+	}
+	return shifts, nil
+}
+
+// CountOccurrencesBefore zählt die Vorkommen einer Serie, die vor dem Zeitpunkt beginnen (inkl. EXDATEs).
+// Wird beim Aufteilen einer Serie mit COUNT benötigt.
+func CountOccurrencesBefore(series models.RecurringShift, before time.Time) (int, error) {
+	rule, err := RecurrenceRuleFor(series)
+	if err != nil {
+		return 0, err
+	}
+	dtstart := series.StartTime.In(series.Location())
+	return len(rule.Between(dtstart, dtstart, before.Add(-time.Second), nil)), nil
+}
+
+// occurrenceEnd liefert das Ende eines Vorkommens mit derselben Uhrzeit und demselben Tagesabstand wie das erste
+// Vorkommen. Über eine Zeitumstellung ist eine Nachtschicht dadurch eine Stunde kürzer oder länger.
+func occurrenceEnd(dtstart, dtend, start time.Time) time.Time {
+	loc := dtstart.Location()
+	startDay := time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day(), 0, 0, 0, 0, time.UTC)
+	endDay := time.Date(dtend.Year(), dtend.Month(), dtend.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(endDay.Sub(startDay).Hours() / 24)
+
+	start = start.In(loc)
+	end := time.Date(start.Year(), start.Month(), start.Day()+days, dtend.Hour(), dtend.Minute(), dtend.Second(), 0, loc)
+	if !end.After(start) {
+		// Fällt das Ende in eine übersprungene Stunde, bleibt die ursprüngliche Dauer erhalten
+		return start.Add(dtend.Sub(dtstart))
+	}
+	return end
+}
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// ScheduleGridUntypedCode ist das Kürzel für Schichten ohne Schichttyp
+const ScheduleGridUntypedCode = "X"
+
+// germanWeekdays sind die Abkürzungen der Wochentage, beginnend mit Sonntag (wie time.Weekday)
+var germanWeekdays = [...]string{"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"}
+
+// BuildScheduleGrid erstellt das Planraster eines Schichtplans mit den Kalendertagen in der Zeitzone loc.
+// users sind die Zeilen; teams bestimmen die Reihenfolge der Gruppen (SortOrder, dann Name), Benutzer ohne Team stehen
+// am Ende. Schichten (mit geladenem Schichttyp) zählen am Tag ihres Beginns, abgesagte Schichten werden ignoriert.
+// absences sollten nur genehmigte Abwesenheiten enthalten.
+func BuildScheduleGrid(schedule models.Schedule, users []models.User, teams []models.Team, shifts []models.Shift, absences []models.Absence, loc *time.Location) models.ScheduleGrid {
+	grid := models.ScheduleGrid{
+		ScheduleID:          schedule.ID,
+		ScheduleName:        schedule.Name,
+		Days:                make([]time.Time, 0),
+		Groups:              make([]models.ScheduleGridGroup, 0),
+		ShiftTypes:          make([]models.ShiftType, 0),
+		ShiftTypeHeadcounts: make(map[uint][]int),
+	}
+	grid.Days = append(grid.Days, ScheduleDays(schedule, loc)...)
+	first := localDay(schedule.StartDate, loc)
+	dayIndex := func(day time.Time) (int, bool) {
+		index := int(day.Sub(first).Hours() / 24)
+		return index, !day.Before(first) && index < len(grid.Days)
+	}
+
+	rows := make(map[uint]*models.ScheduleGridRow, len(users))
+	for _, user := range users {
+		rows[user.ID] = &models.ScheduleGridRow{
+			UserID:        user.ID,
+			Name:          user.Name,
+			AccountNumber: user.AccountNumber,
+			Cells:         make([]models.ScheduleGridCell, len(grid.Days)),
+		}
+	}
+
+	grid.Headcounts = make([]int, len(grid.Days))
+	usedTypes := make(map[uint]models.ShiftType)
+	sorted := append([]models.Shift(nil), shifts...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].StartTime.Before(sorted[j].StartTime) })
+	for _, shift := range sorted {
+		row, ok := rows[shift.UserID]
+		index, inRange := dayIndex(localDay(shift.StartTime, loc))
+		if !ok || !inRange || !shift.IsActive {
+			continue
+		}
+
+		typeID := uint(0)
+		code := ScheduleGridUntypedCode
+		if shift.ShiftTypeID != nil && shift.ShiftType.ID != 0 {
+			typeID = shift.ShiftType.ID
+			code = shift.ShiftType.Code()
+			usedTypes[typeID] = shift.ShiftType
+		}
+		cell := &row.Cells[index]
+		if cell.Color == "" && typeID != 0 {
+			cell.Color = shift.ShiftType.Color
+		}
+		// Abwesenheiten werden erst danach ergänzt; jeder Benutzer zählt je Tag einmal
+		if len(cell.Codes) == 0 {
+			grid.Headcounts[index]++
+		}
+		minutes := int(shift.NetDuration() / time.Minute)
+		cell.Codes = append(cell.Codes, code)
+		cell.Minutes += minutes
+		row.Shifts++
+		row.Minutes += minutes
+
+		if grid.ShiftTypeHeadcounts[typeID] == nil {
+			grid.ShiftTypeHeadcounts[typeID] = make([]int, len(grid.Days))
+		}
+		grid.ShiftTypeHeadcounts[typeID][index]++
+	}
+
+	for _, absence := range absences {
+		row, ok := rows[absence.UserID]
+		if !ok {
+			continue
+		}
+		start, end := utcDay(absence.StartDate), utcDay(absence.EndDate)
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			if index, inRange := dayIndex(day); inRange {
+				row.Cells[index].Codes = append(row.Cells[index].Codes, models.AbsenceTypeCode(absence.Type))
+			}
+		}
+	}
+
+	for _, shiftType := range usedTypes {
+		grid.ShiftTypes = append(grid.ShiftTypes, shiftType)
+	}
+	sort.Slice(grid.ShiftTypes, func(i, j int) bool {
+		if grid.ShiftTypes[i].SortOrder != grid.ShiftTypes[j].SortOrder {
+			return grid.ShiftTypes[i].SortOrder < grid.ShiftTypes[j].SortOrder
+		}
+		return grid.ShiftTypes[i].Name < grid.ShiftTypes[j].Name
+	})
+
+	orderedTeams := append([]models.Team(nil), teams...)
+	sort.SliceStable(orderedTeams, func(i, j int) bool {
+		if orderedTeams[i].SortOrder != orderedTeams[j].SortOrder {
+			return orderedTeams[i].SortOrder < orderedTeams[j].SortOrder
+		}
+		return orderedTeams[i].Name < orderedTeams[j].Name
+	})
+	groups := make(map[uint]*models.ScheduleGridGroup, len(orderedTeams)+1)
+	for _, team := range orderedTeams {
+		groups[team.ID] = &models.ScheduleGridGroup{TeamID: team.ID, Name: team.Name, Color: team.Color}
+	}
+	withoutTeam := &models.ScheduleGridGroup{Name: "Ohne Team"}
+
+	orderedUsers := append([]models.User(nil), users...)
+	sort.SliceStable(orderedUsers, func(i, j int) bool {
+		if orderedUsers[i].Name != orderedUsers[j].Name {
+			return orderedUsers[i].Name < orderedUsers[j].Name
+		}
+		return orderedUsers[i].ID < orderedUsers[j].ID
+	})
+	for _, user := range orderedUsers {
+		group := withoutTeam
+		if user.TeamID != nil && groups[*user.TeamID] != nil {
+			group = groups[*user.TeamID]
+		}
+		group.Rows = append(group.Rows, *rows[user.ID])
+	}
+	for _, team := range orderedTeams {
+		if len(groups[team.ID].Rows) > 0 {
+			grid.Groups = append(grid.Groups, *groups[team.ID])
+		}
+	}
+	if len(withoutTeam.Rows) > 0 {
+		grid.Groups = append(grid.Groups, *withoutTeam)
+	}
+	return grid
+}
+
+// ScheduleDays liefert die Kalendertage eines Schichtplans in der Zeitzone loc (00:00 Uhr UTC)
+func ScheduleDays(schedule models.Schedule, loc *time.Location) []time.Time {
+	var days []time.Time
+	last := localDay(schedule.EndDate, loc)
+	for day := localDay(schedule.StartDate, loc); !day.After(last); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// ScheduleGridRows liefert das Planraster als Tabelle für CSV und XLSX: Kopfzeile, je Benutzer eine Zeile mit Team,
+// Name, Personalnummer, Kürzeln je Tag, Anzahl Schichten und Stunden, danach die Besetzung je Tag und die Legende
+func ScheduleGridRows(grid models.ScheduleGrid) [][]string {
+	header := []string{"Team", "Name", "Personalnummer"}
+	for _, day := range grid.Days {
+		header = append(header, ScheduleGridDayLabel(day))
+	}
+	rows := [][]string{append(header, "Schichten", "Stunden")}
+
+	for _, group := range grid.Groups {
+		for _, row := range group.Rows {
+			line := []string{group.Name, row.Name, row.AccountNumber}
+			for _, cell := range row.Cells {
+				line = append(line, cell.Text())
+			}
+			rows = append(rows, append(line, strconv.Itoa(row.Shifts), ScheduleGridHours(row.Minutes)))
+		}
+	}
+
+	rows = append(rows, []string{})
+	headcount := func(label string, counts []int) []string {
+		line := []string{"", label, ""}
+		for _, count := range counts {
+			line = append(line, strconv.Itoa(count))
+		}
+		return line
+	}
+	rows = append(rows, headcount("Besetzung gesamt", grid.Headcounts))
+	for _, shiftType := range grid.ShiftTypes {
+		rows = append(rows, headcount("Besetzung "+shiftType.Code(), grid.ShiftTypeHeadcounts[shiftType.ID]))
+	}
+	if counts, ok := grid.ShiftTypeHeadcounts[0]; ok {
+		rows = append(rows, headcount("Besetzung "+ScheduleGridUntypedCode, counts))
+	}
+
+	rows = append(rows, []string{}, []string{"Legende", "Kürzel", "Bedeutung", "Farbe"})
+	for _, shiftType := range grid.ShiftTypes {
+		rows = append(rows, []string{"", shiftType.Code(), scheduleGridShiftTypeLabel(shiftType), shiftType.Color})
+	}
+	if _, ok := grid.ShiftTypeHeadcounts[0]; ok {
+		rows = append(rows, []string{"", ScheduleGridUntypedCode, "Schicht ohne Schichttyp", ""})
+	}
+	for _, absenceType := range models.AbsenceTypes {
+		rows = append(rows, []string{"", models.AbsenceTypeCode(absenceType), models.AbsenceTypeLabel(absenceType), ""})
+	}
+	return rows
+}
+
+// ScheduleGridXLSX liefert das Planraster als Excel-Arbeitsmappe mit den Zeilen von ScheduleGridRows. Tageszellen
+// erhalten die Farbe des Schichttyps, die Teamspalte die Farbe des Teams; Kopfzeile und Namen bleiben beim Blättern
+// sichtbar.
+func ScheduleGridXLSX(grid models.ScheduleGrid) ([]byte, error) {
+	rows := ScheduleGridRows(grid)
+	firstDay, lastDay := 3, 3+len(grid.Days)
+	sheet := XLSXSheet{
+		Name:          grid.ScheduleName,
+		Rows:          make([][]XLSXCell, len(rows)),
+		ColumnWidths:  make([]float64, lastDay+2),
+		FreezeRows:    1,
+		FreezeColumns: firstDay,
+	}
+	sheet.ColumnWidths[0], sheet.ColumnWidths[1], sheet.ColumnWidths[2] = 18, 24, 15
+	for col := firstDay; col < lastDay; col++ {
+		sheet.ColumnWidths[col] = 10
+	}
+
+	// Benutzerzeilen folgen in der Reihenfolge der Gruppen direkt auf die Kopfzeile
+	var teamColors []string
+	var userCells [][]models.ScheduleGridCell
+	for _, group := range grid.Groups {
+		for _, row := range group.Rows {
+			teamColors = append(teamColors, group.Color)
+			userCells = append(userCells, row.Cells)
+		}
+	}
+
+	for r, row := range rows {
+		cells := make([]XLSXCell, len(row))
+		for col, value := range row {
+			cells[col] = XLSXCell{Value: value, Bold: r == 0}
+		}
+		if r >= 1 && r <= len(userCells) {
+			cells[0].Fill = teamColors[r-1]
+			for day, cell := range userCells[r-1] {
+				cells[firstDay+day].Fill = cell.Color
+			}
+			cells[lastDay].Number = true
+			cells[lastDay+1] = XLSXCell{Value: strings.Replace(row[lastDay+1], ",", ".", 1), Number: true}
+		} else if r > len(userCells) && len(row) > firstDay && row[0] == "" && strings.HasPrefix(row[1], "Besetzung") {
+			cells[1].Bold = true
+			for col := firstDay; col < len(cells); col++ {
+				cells[col].Number = true
+			}
+		} else if len(row) > 0 && row[0] == "Legende" {
+			for col := range cells {
+				cells[col].Bold = true
+			}
+		} else if len(row) == 4 && row[3] != "" {
+			cells[1].Fill = row[3]
+		}
+		sheet.Rows[r] = cells
+	}
+	return WriteXLSX(sheet)
+}
+
+// ScheduleGridDayLabel liefert die Spaltenüberschrift eines Tages, z.B. Mo 01.04.
+func ScheduleGridDayLabel(day time.Time) string {
+	return germanWeekdays[day.Weekday()] + " " + day.Format("02.01.")
+}
+
+// ScheduleGridHours formatiert Minuten als Stunden mit Dezimalkomma, z.B. 38,50
+func ScheduleGridHours(minutes int) string {
+	return strings.Replace(strconv.FormatFloat(float64(minutes)/60, 'f', 2, 64), ".", ",", 1)
+}
+
+// scheduleGridShiftTypeLabel liefert Name und Standardzeiten eines Schichttyps für die Legende, z.B. Frühschicht (06:00–14:00)
+func scheduleGridShiftTypeLabel(shiftType models.ShiftType) string {
+	if shiftType.DefaultStart.Valid && shiftType.DefaultEnd.Valid {
+		return fmt.Sprintf("%s (%s–%s)", shiftType.Name, shiftType.DefaultStart, shiftType.DefaultEnd)
+	}
+	return shiftType.Name
+}
+
+// localDay liefert den Kalendertag von t in der Zeitzone loc als 00:00 Uhr UTC
+func localDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// utcDay liefert den Kalendertag eines als Datum gespeicherten Zeitpunkts
+func utcDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scheduleGridFixture() models.ScheduleGrid {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	early := models.ShiftType{Name: "Frühschicht", ShortCode: "F", Color: "#F59E0B", SortOrder: 1, DefaultStart: models.NewWallClock(6, 0), DefaultEnd: models.NewWallClock(14, 0)}
+	early.ID = 1
+	night := models.ShiftType{Name: "Nachtschicht", Color: "#1E3A8A", SortOrder: 2}
+	night.ID = 2
+
+	kitchen := models.Team{Name: "Küche", Color: "#10B981", SortOrder: 2}
+	kitchen.ID = 1
+	service := models.Team{Name: "Service", SortOrder: 1}
+	service.ID = 2
+
+	anna := models.User{Name: "Anna", AccountNumber: "1001", TeamID: &kitchen.ID}
+	anna.ID = 1
+	bernd := models.User{Name: "Bernd", AccountNumber: "1002", TeamID: &service.ID}
+	bernd.ID = 2
+	carla := models.User{Name: "Carla"}
+	carla.ID = 3
+
+	schedule := models.Schedule{Name: "KW 14", StartDate: time.Date(2024, 3, 31, 22, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 4, 3, 22, 0, 0, 0, time.UTC)}
+	schedule.ID = 7
+	shift := func(user models.User, shiftType *models.ShiftType, start time.Time, hours int) models.Shift {
+		s := models.Shift{UserID: user.ID, StartTime: start, EndTime: start.Add(time.Duration(hours) * time.Hour), BreakTime: 30, IsActive: true}
+		if shiftType != nil {
+			s.ShiftTypeID = &shiftType.ID
+			s.ShiftType = *shiftType
+		}
+		return s
+	}
+	cancelled := shift(bernd, &early, time.Date(2024, 4, 2, 4, 0, 0, 0, time.UTC), 8)
+	cancelled.IsActive = false
+	shifts := []models.Shift{
+		shift(anna, &early, time.Date(2024, 4, 1, 4, 0, 0, 0, time.UTC), 8),
+		// Nachtschicht beginnt am 01.04. um 23:00 Uhr Ortszeit
+		shift(anna, &night, time.Date(2024, 4, 1, 21, 0, 0, 0, time.UTC), 8),
+		shift(bernd, &early, time.Date(2024, 4, 1, 4, 0, 0, 0, time.UTC), 8),
+		shift(carla, nil, time.Date(2024, 4, 3, 8, 0, 0, 0, time.UTC), 4),
+		cancelled,
+	}
+	absences := []models.Absence{
+		{UserID: bernd.ID, Type: models.AbsenceTypeVacation, StartDate: time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 4, 10, 0, 0, 0, 0, time.UTC)},
+	}
+	return BuildScheduleGrid(schedule, []models.User{carla, bernd, anna}, []models.Team{kitchen, service}, shifts, absences, berlin)
+}
+
+func TestBuildScheduleGrid(t *testing.T) {
+	grid := scheduleGridFixture()
+
+	if assert.Len(t, grid.Days, 4) {
+		assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), grid.Days[0])
+	}
+	if assert.Len(t, grid.Groups, 3) {
+		assert.Equal(t, "Service", grid.Groups[0].Name)
+		assert.Equal(t, "Küche", grid.Groups[1].Name)
+		assert.Equal(t, "Ohne Team", grid.Groups[2].Name)
+	}
+
+	anna := grid.Groups[1].Rows[0]
+	assert.Equal(t, []string{"F", "N"}, anna.Cells[0].Codes)
+	assert.Equal(t, "F/N", anna.Cells[0].Text())
+	assert.Equal(t, "#F59E0B", anna.Cells[0].Color)
+	assert.Equal(t, 2, anna.Shifts)
+	assert.Equal(t, 15*60, anna.Minutes)
+
+	bernd := grid.Groups[0].Rows[0]
+	assert.Equal(t, 1, bernd.Shifts)
+	assert.Empty(t, bernd.Cells[1].Codes, "abgesagte Schicht")
+	assert.Equal(t, []string{"U"}, bernd.Cells[2].Codes)
+	assert.Equal(t, []string{"U"}, bernd.Cells[3].Codes)
+
+	assert.Equal(t, []string{ScheduleGridUntypedCode}, grid.Groups[2].Rows[0].Cells[2].Codes)
+	assert.Equal(t, []int{2, 0, 1, 0}, grid.Headcounts)
+	assert.Equal(t, []int{2, 0, 0, 0}, grid.ShiftTypeHeadcounts[1])
+	assert.Equal(t, []int{0, 0, 1, 0}, grid.ShiftTypeHeadcounts[0])
+	if assert.Len(t, grid.ShiftTypes, 2) {
+		assert.Equal(t, "Frühschicht", grid.ShiftTypes[0].Name)
+	}
+}
+
+func TestScheduleGridRows(t *testing.T) {
+	rows := ScheduleGridRows(scheduleGridFixture())
+
+	assert.Equal(t, []string{"Team", "Name", "Personalnummer", "Mo 01.04.", "Di 02.04.", "Mi 03.04.", "Do 04.04.", "Schichten", "Stunden"}, rows[0])
+	assert.Equal(t, []string{"Service", "Bernd", "1002", "F", "", "U", "U", "1", "7,50"}, rows[1])
+	assert.Equal(t, []string{"Küche", "Anna", "1001", "F/N", "", "", "", "2", "15,00"}, rows[2])
+	assert.Equal(t, []string{}, rows[4])
+	assert.Equal(t, []string{"", "Besetzung gesamt", "", "2", "0", "1", "0"}, rows[5])
+	assert.Equal(t, []string{"", "Besetzung X", "", "0", "0", "1", "0"}, rows[8])
+
+	assert.Contains(t, rows, []string{"Legende", "Kürzel", "Bedeutung", "Farbe"})
+	assert.Contains(t, rows, []string{"", "F", "Frühschicht (06:00–14:00)", "#F59E0B"})
+	assert.Contains(t, rows, []string{"", "X", "Schicht ohne Schichttyp", ""})
+	assert.Contains(t, rows, []string{"", "U", "Urlaub", ""})
+}
+
+func TestScheduleGridXLSX(t *testing.T) {
+	data, err := ScheduleGridXLSX(scheduleGridFixture())
+	assert.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if !assert.NoError(t, err) {
+		return
+	}
+	parts := make(map[string]string)
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		assert.NoError(t, err)
+		content, _ := io.ReadAll(reader)
+		reader.Close()
+		parts[file.Name] = string(content)
+	}
+	assert.Contains(t, parts, "[Content_Types].xml")
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="KW 14"`)
+
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, sheet, `state="frozen"`)
+	assert.Contains(t, sheet, `<t xml:space="preserve">F/N</t>`)
+	assert.Contains(t, sheet, `<v>15.00</v>`)
+
+	styles := parts["xl/styles.xml"]
+	assert.Contains(t, styles, `<fgColor rgb="FFF59E0B"/>`)
+	assert.Contains(t, styles, `<fgColor rgb="FF10B981"/>`)
+}
+
+func TestXLSXHelpers(t *testing.T) {
+	assert.Equal(t, "A", XLSXColumnName(0))
+	assert.Equal(t, "Z", XLSXColumnName(25))
+	assert.Equal(t, "AA", XLSXColumnName(26))
+	assert.Equal(t, "BA", XLSXColumnName(52))
+
+	assert.Equal(t, "F59E0B", normalizeHexColor("#f59e0b"))
+	assert.Equal(t, "FFFFFF", normalizeHexColor("fff"))
+	assert.Equal(t, "", normalizeHexColor("blau"))
+	assert.True(t, isDarkColor("#1E3A8A"))
+	assert.False(t, isDarkColor("#F59E0B"))
+
+	assert.Equal(t, "Plan 01-04", xlsxSheetName("Plan 01/04"))
+	assert.Equal(t, "Tabelle1", xlsxSheetName(" "))
+}
@@ -0,0 +1,562 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"schichtplaner/models"
+)
+
+// ScheduleImportIgnoredCodes sind Kürzel für freie Tage, die ohne eigene Zuordnung übersprungen werden
+var ScheduleImportIgnoredCodes = []string{"-", "–", "FREI", "OFF"}
+
+// Beschriftungen von Summen- und Legendenzeilen (z.B. aus dem Export des Planrasters)
+var (
+	scheduleImportSummaryPrefixes = []string{"besetzung", "summe", "gesamt"}
+	scheduleImportStopLabel       = "legende"
+)
+
+// Formate der Tagesüberschriften: Datum mit Jahr, Tag und Monat oder nur Tag; ein Wochentag davor wird ignoriert
+var (
+	scheduleImportFullDate  = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})\.(\d{2}|\d{4})$`)
+	scheduleImportISODate   = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+	scheduleImportDayMonth  = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})\.?$`)
+	scheduleImportDayNumber = regexp.MustCompile(`^(\d{1,2})\.?$`)
+	scheduleImportExcelDate = regexp.MustCompile(`^\d{5}(\.0+)?$`)
+)
+
+// excelEpoch ist der Tag 0 der Datumswerte in Excel (1900er-Datumssystem)
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// ReadCSVRows liest eine CSV-Datei mit Semikolon oder Komma als Trennzeichen als Zeilen mit Zellwerten.
+// Das Trennzeichen wird an der ersten Zeile erkannt, die eines enthält (Titelzeilen werden übersprungen).
+func ReadCSVRows(data string) ([][]string, error) {
+	data = strings.TrimPrefix(data, "\ufeff")
+	if strings.TrimSpace(data) == "" {
+		return nil, fmt.Errorf("CSV-Datei ist leer")
+	}
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.Comma = ','
+	for _, line := range strings.Split(data, "\n") {
+		if strings.ContainsAny(line, ";,") {
+			reader.Comma = csvDelimiter(line)
+			break
+		}
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("CSV-Datei konnte nicht gelesen werden: %w", err)
+	}
+	return rows, nil
+}
+
+// ScheduleImportCodes erstellt die Kürzeltabelle eines Imports. Vorrang haben die angegebenen Zuordnungen
+// (codes: Kürzel → Schichttyp-ID, absenceCodes: Kürzel → Abwesenheitsart, ignoreCodes: freie Tage), danach die
+// Kürzel der Schichttypen (ShortCode), die Kürzel der Abwesenheiten, freie Tage und zuletzt die Anfangsbuchstaben
+// aktiver Schichttypen ohne Kürzel. Groß- und Kleinschreibung wird nicht unterschieden.
+func ScheduleImportCodes(shiftTypes []models.ShiftType, codes map[string]uint, absenceCodes map[string]string, ignoreCodes []string) (map[string]models.ScheduleImportCode, error) {
+	byID := make(map[uint]models.ShiftType, len(shiftTypes))
+	for _, shiftType := range shiftTypes {
+		byID[shiftType.ID] = shiftType
+	}
+
+	table := make(map[string]models.ScheduleImportCode)
+	for code, shiftTypeID := range codes {
+		key := normalizeImportCode(code)
+		shiftType, ok := byID[shiftTypeID]
+		if key == "" || !ok {
+			return nil, fmt.Errorf("Kürzel %q: Schichttyp %d nicht gefunden", code, shiftTypeID)
+		}
+		table[key] = models.ScheduleImportCode{Code: key, ShiftTypeID: shiftType.ID, ShiftType: shiftType.Name}
+	}
+	for code, absenceType := range absenceCodes {
+		key := normalizeImportCode(code)
+		if key == "" || !models.IsValidAbsenceType(absenceType) {
+			return nil, fmt.Errorf("Kürzel %q: Unbekannte Abwesenheitsart %q", code, absenceType)
+		}
+		if _, taken := table[key]; taken {
+			return nil, fmt.Errorf("Kürzel %q ist mehrfach zugeordnet", code)
+		}
+		table[key] = models.ScheduleImportCode{Code: key, AbsenceType: absenceType}
+	}
+	for _, code := range ignoreCodes {
+		key := normalizeImportCode(code)
+		if key == "" {
+			continue
+		}
+		if _, taken := table[key]; taken {
+			return nil, fmt.Errorf("Kürzel %q ist mehrfach zugeordnet", code)
+		}
+		table[key] = models.ScheduleImportCode{Code: key, Ignore: true}
+	}
+
+	// Vorgaben ergänzen die Tabelle nur, wo ein Kürzel noch frei ist; gleiche Kürzel einer Stufe sind mehrdeutig
+	addDefaults := func(defaults map[string][]models.ScheduleImportCode) {
+		for key, entries := range defaults {
+			if _, taken := table[key]; taken {
+				continue
+			}
+			if len(entries) == 1 {
+				table[key] = entries[0]
+				continue
+			}
+			ambiguous := models.ScheduleImportCode{Code: key}
+			for _, entry := range entries {
+				ambiguous.Ambiguous = append(ambiguous.Ambiguous, entry.ShiftType)
+			}
+			sort.Strings(ambiguous.Ambiguous)
+			table[key] = ambiguous
+		}
+	}
+	shortCodes := make(map[string][]models.ScheduleImportCode)
+	initials := make(map[string][]models.ScheduleImportCode)
+	for _, shiftType := range shiftTypes {
+		entry := models.ScheduleImportCode{ShiftTypeID: shiftType.ID, ShiftType: shiftType.Name}
+		if shiftType.ShortCode != "" {
+			entry.Code = normalizeImportCode(shiftType.ShortCode)
+			shortCodes[entry.Code] = append(shortCodes[entry.Code], entry)
+		} else if shiftType.IsActive {
+			entry.Code = normalizeImportCode(shiftType.Code())
+			initials[entry.Code] = append(initials[entry.Code], entry)
+		}
+	}
+	addDefaults(shortCodes)
+	absences := make(map[string][]models.ScheduleImportCode, len(models.AbsenceTypes))
+	for _, absenceType := range models.AbsenceTypes {
+		key := normalizeImportCode(models.AbsenceTypeCode(absenceType))
+		absences[key] = []models.ScheduleImportCode{{Code: key, AbsenceType: absenceType}}
+	}
+	addDefaults(absences)
+	ignored := make(map[string][]models.ScheduleImportCode, len(ScheduleImportIgnoredCodes))
+	for _, code := range ScheduleImportIgnoredCodes {
+		key := normalizeImportCode(code)
+		ignored[key] = []models.ScheduleImportCode{{Code: key, Ignore: true}}
+	}
+	addDefaults(ignored)
+	addDefaults(initials)
+	return table, nil
+}
+
+// SortedScheduleImportCodes liefert die Kürzeltabelle sortiert nach Kürzel für den Bericht
+func SortedScheduleImportCodes(table map[string]models.ScheduleImportCode) []models.ScheduleImportCode {
+	codes := make([]models.ScheduleImportCode, 0, len(table))
+	for _, code := range table {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+	return codes
+}
+
+// ParseScheduleImport wertet eine Tabelle im Stil des Planrasters aus: Die erste Zeile mit Tagesüberschriften
+// bestimmt die Tagesspalten, die Spalten davor beschriften die Zeilen. Zeilen werden über Personalnummer oder Name
+// (auch "Nachname, Vorname") Benutzern zugeordnet, die Zellen über die Kürzeltabelle Schichttypen und
+// Abwesenheitsarten. Schichten erhalten die Standardzeiten des Schichttyps in der Zeitzone des Benutzers.
+// Zeilen ohne Kürzel (z.B. Teamüberschriften) und Summenzeilen werden übersprungen, ab der Legende endet die Tabelle.
+func ParseScheduleImport(rows [][]string, schedule models.Schedule, users []models.User, shiftTypes []models.ShiftType, codes map[string]models.ScheduleImportCode, loc *time.Location) (models.ScheduleImportReport, error) {
+	report := models.ScheduleImportReport{
+		ScheduleID: schedule.ID,
+		Columns:    make([]models.ScheduleImportColumn, 0),
+		Codes:      SortedScheduleImportCodes(codes),
+		Rows:       make([]models.ScheduleImportRow, 0),
+	}
+	days := ScheduleDays(schedule, loc)
+
+	header := -1
+	var positions []int
+	for i, row := range rows {
+		columns, warnings := scheduleImportColumns(row, days)
+		if len(columns) > 0 {
+			header = i
+			report.HeaderLine = i + 1
+			report.Warnings = warnings
+			for _, column := range columns {
+				report.Columns = append(report.Columns, column.column)
+				positions = append(positions, column.index)
+			}
+			break
+		}
+	}
+	if header < 0 {
+		return report, fmt.Errorf("Keine Zeile mit Tagesüberschriften im Zeitraum des Schichtplans gefunden")
+	}
+
+	shiftTypesByID := make(map[uint]models.ShiftType, len(shiftTypes))
+	for _, shiftType := range shiftTypes {
+		shiftTypesByID[shiftType.ID] = shiftType
+	}
+	matcher := newScheduleImportMatcher(users)
+	seen := make(map[uint]int)
+
+	for i := header + 1; i < len(rows); i++ {
+		cells := rows[i]
+		labels := make([]string, 0, positions[0])
+		for col := 0; col < positions[0] && col < len(cells); col++ {
+			if label := strings.TrimSpace(cells[col]); label != "" {
+				labels = append(labels, label)
+			}
+		}
+		if len(labels) > 0 {
+			first := strings.ToLower(labels[0])
+			if first == scheduleImportStopLabel {
+				break
+			}
+			if hasAnyPrefix(first, scheduleImportSummaryPrefixes) {
+				continue
+			}
+		}
+
+		hasCodes := false
+		for _, position := range positions {
+			if position < len(cells) && strings.TrimSpace(cells[position]) != "" {
+				hasCodes = true
+				break
+			}
+		}
+		if !hasCodes {
+			continue
+		}
+
+		row := models.ScheduleImportRow{Line: i + 1, Label: strings.Join(labels, " "), Entries: make([]models.ScheduleImportEntry, 0)}
+		user, message := matcher.match(labels)
+		if message != "" {
+			row.Errors = append(row.Errors, message)
+		} else {
+			row.UserID, row.UserName = user.ID, user.Name
+			if line, ok := seen[user.ID]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("%s steht bereits in Zeile %d", user.Name, line))
+			}
+			seen[user.ID] = row.Line
+		}
+
+		for c, position := range positions {
+			if position >= len(cells) {
+				continue
+			}
+			column := report.Columns[c]
+			absences := 0
+			for _, code := range splitImportCodes(cells[position]) {
+				where := column.Header + ": "
+				entry, ok := codes[normalizeImportCode(code)]
+				switch {
+				case !ok:
+					row.Errors = append(row.Errors, fmt.Sprintf("%sUnbekanntes Kürzel „%s“", where, code))
+					continue
+				case len(entry.Ambiguous) > 0:
+					row.Errors = append(row.Errors, fmt.Sprintf("%sKürzel „%s“ ist mehrdeutig (%s)", where, code, strings.Join(entry.Ambiguous, ", ")))
+					continue
+				case entry.Ignore:
+					continue
+				}
+
+				item := models.ScheduleImportEntry{Date: column.Date, Code: entry.Code}
+				if entry.AbsenceType != "" {
+					if absences++; absences > 1 {
+						row.Errors = append(row.Errors, where+"Mehrere Abwesenheiten an einem Tag")
+						continue
+					}
+					item.AbsenceType = entry.AbsenceType
+					row.Entries = append(row.Entries, item)
+					continue
+				}
+
+				shiftType := shiftTypesByID[entry.ShiftTypeID]
+				userLoc := loc
+				if user != nil {
+					userLoc = user.Location()
+				}
+				start, end, ok := shiftType.ShiftTimesOn(time.Date(column.Date.Year(), column.Date.Month(), column.Date.Day(), 12, 0, 0, 0, userLoc), userLoc)
+				if !ok {
+					row.Errors = append(row.Errors, fmt.Sprintf("%sSchichttyp %s hat keine Standardzeiten", where, shiftType.Name))
+					continue
+				}
+				item.ShiftTypeID = shiftType.ID
+				item.StartTime, item.EndTime, item.BreakTime = &start, &end, shiftType.DefaultBreak
+				row.Entries = append(row.Entries, item)
+			}
+		}
+		report.Rows = append(report.Rows, row)
+	}
+
+	SummarizeScheduleImport(&report)
+	return report, nil
+}
+
+// CheckScheduleImport prüft die Einträge gegen bestehende Schichten und Abwesenheiten der Benutzer. Gleiche Schichten
+// und Abwesenheiten werden übersprungen, abweichende Abwesenheiten am selben Tag sind Fehler, Schichten an Tagen mit
+// Abwesenheit oder mit überschneidenden Schichten werden als Hinweis gemeldet.
+func CheckScheduleImport(report *models.ScheduleImportReport, shifts []models.Shift, absences []models.Absence) {
+	for r := range report.Rows {
+		row := &report.Rows[r]
+		if row.UserID == 0 {
+			continue
+		}
+		for e := range row.Entries {
+			entry := &row.Entries[e]
+			day := entry.Date.Format("02.01.")
+			var absence *models.Absence
+			for i := range absences {
+				if absences[i].UserID == row.UserID && absences[i].Status != models.AbsenceStatusRejected && absences[i].Covers(entry.Date) {
+					absence = &absences[i]
+					break
+				}
+			}
+
+			if entry.AbsenceType != "" {
+				switch {
+				case absence == nil:
+				case absence.Type == entry.AbsenceType:
+					entry.Skipped = true
+					row.Warnings = append(row.Warnings, fmt.Sprintf("%s: %s ist bereits eingetragen", day, models.AbsenceTypeLabel(entry.AbsenceType)))
+				default:
+					row.Errors = append(row.Errors, fmt.Sprintf("%s: Es ist bereits %s eingetragen", day, models.AbsenceTypeLabel(absence.Type)))
+				}
+				continue
+			}
+
+			if absence != nil {
+				row.Warnings = append(row.Warnings, fmt.Sprintf("%s: Schicht trotz %s", day, models.AbsenceTypeLabel(absence.Type)))
+			}
+			for _, shift := range shifts {
+				if shift.UserID != row.UserID || !shift.IsActive {
+					continue
+				}
+				if shift.StartTime.Equal(*entry.StartTime) && shift.EndTime.Equal(*entry.EndTime) {
+					entry.Skipped = true
+					row.Warnings = append(row.Warnings, fmt.Sprintf("%s: Schicht %s ist bereits vorhanden", day, entry.Code))
+					break
+				}
+				if shift.StartTime.Before(*entry.EndTime) && entry.StartTime.Before(shift.EndTime) {
+					row.Warnings = append(row.Warnings, fmt.Sprintf("%s: Schicht %s überschneidet sich mit einer bestehenden Schicht", day, entry.Code))
+				}
+			}
+		}
+	}
+	SummarizeScheduleImport(report)
+}
+
+// SummarizeScheduleImport zählt gültige und fehlerhafte Zeilen sowie anzulegende Schichten und Abwesenheiten
+func SummarizeScheduleImport(report *models.ScheduleImportReport) {
+	report.Total, report.Valid, report.Invalid = len(report.Rows), 0, 0
+	report.Shifts, report.Absences = 0, 0
+	for _, row := range report.Rows {
+		if len(row.Errors) > 0 {
+			report.Invalid++
+			continue
+		}
+		report.Valid++
+		shifts, absences := ScheduleImportRecords(row, report.ScheduleID)
+		report.Shifts += len(shifts)
+		report.Absences += len(absences)
+	}
+}
+
+// ScheduleImportRecords liefert die anzulegenden Schichten und genehmigten Abwesenheiten einer Zeile;
+// Abwesenheiten an aufeinanderfolgenden Tagen mit derselben Art werden zusammengefasst
+func ScheduleImportRecords(row models.ScheduleImportRow, scheduleID uint) ([]models.Shift, []models.Absence) {
+	var shifts []models.Shift
+	var absences []models.Absence
+	for _, entry := range row.Entries {
+		if entry.Skipped {
+			continue
+		}
+		if entry.AbsenceType == "" {
+			shiftTypeID := entry.ShiftTypeID
+			shifts = append(shifts, models.Shift{
+				UserID:      row.UserID,
+				ScheduleID:  scheduleID,
+				ShiftTypeID: &shiftTypeID,
+				StartTime:   *entry.StartTime,
+				EndTime:     *entry.EndTime,
+				BreakTime:   entry.BreakTime,
+				IsActive:    true,
+			})
+			continue
+		}
+		if last := len(absences) - 1; last >= 0 && absences[last].Type == entry.AbsenceType &&
+			absences[last].EndDate.AddDate(0, 0, 1).Equal(entry.Date) {
+			absences[last].EndDate = entry.Date
+			continue
+		}
+		absences = append(absences, models.Absence{
+			UserID:    row.UserID,
+			Type:      entry.AbsenceType,
+			StartDate: entry.Date,
+			EndDate:   entry.Date,
+			Status:    models.AbsenceStatusApproved,
+			Note:      "Aus Planraster importiert",
+		})
+	}
+	return shifts, absences
+}
+
+// scheduleImportColumn ist eine erkannte Tagesspalte mit ihrem Index in der Zeile
+type scheduleImportColumn struct {
+	index  int
+	column models.ScheduleImportColumn
+}
+
+// scheduleImportColumns erkennt die Tagesspalten einer Kopfzeile. Überschriften ohne Jahr werden dem ersten passenden
+// Tag des Plans nach der vorherigen Spalte zugeordnet; Tage außerhalb des Plans werden als Hinweis gemeldet.
+func scheduleImportColumns(header []string, days []time.Time) ([]scheduleImportColumn, []string) {
+	var columns []scheduleImportColumn
+	var warnings []string
+	var previous time.Time
+	for i, cell := range header {
+		label := strings.TrimSpace(cell)
+		match, ok := scheduleImportDateMatcher(label)
+		if !ok {
+			continue
+		}
+		var date time.Time
+		for _, day := range days {
+			if day.After(previous) && match(day) {
+				date = day
+				break
+			}
+		}
+		if date.IsZero() {
+			warnings = append(warnings, fmt.Sprintf("Spalte %s (%s) liegt außerhalb des Schichtplans und wird ignoriert", XLSXColumnName(i), label))
+			continue
+		}
+		previous = date
+		columns = append(columns, scheduleImportColumn{index: i, column: models.ScheduleImportColumn{Column: XLSXColumnName(i), Header: label, Date: date}})
+	}
+	if len(columns) == 0 {
+		return nil, nil
+	}
+	return columns, warnings
+}
+
+// scheduleImportDateMatcher liefert für eine Tagesüberschrift eine Prüfung, ob ein Kalendertag zu ihr passt
+func scheduleImportDateMatcher(label string) (func(time.Time) bool, bool) {
+	// Wochentag wie in "Mo 01.04." oder "Mo, 01.04.2024" entfernen
+	if fields := strings.Fields(strings.ReplaceAll(label, ",", " ")); len(fields) == 2 && !strings.ContainsAny(fields[0], "0123456789") {
+		label = fields[1]
+	}
+	atoi := func(value string) int {
+		number, _ := strconv.Atoi(value)
+		return number
+	}
+	exact := func(year, month, day int) (func(time.Time) bool, bool) {
+		date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		if date.Day() != day || int(date.Month()) != month {
+			return nil, false
+		}
+		return func(candidate time.Time) bool { return candidate.Equal(date) }, true
+	}
+
+	if match := scheduleImportFullDate.FindStringSubmatch(label); match != nil {
+		year := atoi(match[3])
+		if year < 100 {
+			year += 2000
+		}
+		return exact(year, atoi(match[2]), atoi(match[1]))
+	}
+	if match := scheduleImportISODate.FindStringSubmatch(label); match != nil {
+		return exact(atoi(match[1]), atoi(match[2]), atoi(match[3]))
+	}
+	if match := scheduleImportDayNumber.FindStringSubmatch(label); match != nil {
+		day := atoi(match[1])
+		if day < 1 || day > 31 {
+			return nil, false
+		}
+		return func(candidate time.Time) bool { return candidate.Day() == day }, true
+	}
+	if match := scheduleImportDayMonth.FindStringSubmatch(label); match != nil {
+		day, month := atoi(match[1]), atoi(match[2])
+		if day < 1 || day > 31 || month < 1 || month > 12 {
+			return nil, false
+		}
+		return func(candidate time.Time) bool { return candidate.Day() == day && int(candidate.Month()) == month }, true
+	}
+	if scheduleImportExcelDate.MatchString(label) {
+		serial, _ := strconv.ParseFloat(label, 64)
+		date := excelEpoch.AddDate(0, 0, int(serial))
+		return func(candidate time.Time) bool { return candidate.Equal(date) }, true
+	}
+	return nil, false
+}
+
+// scheduleImportMatcher ordnet Zeilenbeschriftungen Benutzern zu
+type scheduleImportMatcher struct {
+	byAccount map[string]*models.User
+	byName    map[string][]*models.User
+}
+
+// newScheduleImportMatcher erstellt die Zuordnung über Personalnummer und Name
+func newScheduleImportMatcher(users []models.User) scheduleImportMatcher {
+	matcher := scheduleImportMatcher{byAccount: make(map[string]*models.User), byName: make(map[string][]*models.User)}
+	for i := range users {
+		user := &users[i]
+		if user.AccountNumber != "" {
+			matcher.byAccount[strings.ToLower(strings.TrimSpace(user.AccountNumber))] = user
+		}
+		name := normalizeImportName(user.Name)
+		matcher.byName[name] = append(matcher.byName[name], user)
+	}
+	return matcher
+}
+
+// match sucht den Benutzer zu den Beschriftungen einer Zeile; Personalnummern haben Vorrang vor Namen
+func (m scheduleImportMatcher) match(labels []string) (*models.User, string) {
+	if len(labels) == 0 {
+		return nil, "Zeile ohne Name oder Personalnummer"
+	}
+	for _, label := range labels {
+		if user, ok := m.byAccount[strings.ToLower(label)]; ok {
+			return user, ""
+		}
+	}
+	for _, label := range labels {
+		candidates := m.byName[normalizeImportName(label)]
+		if len(candidates) == 0 {
+			// "Nachname, Vorname"
+			if last, first, ok := strings.Cut(label, ","); ok {
+				candidates = m.byName[normalizeImportName(first+" "+last)]
+			}
+		}
+		switch len(candidates) {
+		case 0:
+		case 1:
+			return candidates[0], ""
+		default:
+			return nil, fmt.Sprintf("Name „%s“ ist nicht eindeutig, bitte Personalnummer angeben", label)
+		}
+	}
+	return nil, fmt.Sprintf("Kein Benutzer zu „%s“ gefunden", strings.Join(labels, " "))
+}
+
+// normalizeImportName vereinheitlicht Namen für den Vergleich (Kleinschreibung, einfache Leerzeichen)
+func normalizeImportName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// normalizeImportCode vereinheitlicht Kürzel für den Vergleich
+func normalizeImportCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// splitImportCodes teilt eine Zelle in Kürzel, getrennt durch /, +, Komma, Semikolon oder Leerzeichen
+func splitImportCodes(cell string) []string {
+	return strings.FieldsFunc(cell, func(r rune) bool {
+		return r == '/' || r == '+' || r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+}
+
+// hasAnyPrefix prüft, ob value mit einem der Präfixe beginnt
+func hasAnyPrefix(value string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
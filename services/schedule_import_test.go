@@ -0,0 +1,244 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scheduleImportFixture() (models.Schedule, []models.User, []models.ShiftType) {
+	schedule := models.Schedule{Name: "April", StartDate: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC)}
+	schedule.ID = 3
+	early := models.ShiftType{Name: "Frühschicht", ShortCode: "F", DefaultStart: models.NewWallClock(6, 0), DefaultEnd: models.NewWallClock(14, 0), DefaultBreak: 30, IsActive: true}
+	early.ID = 1
+	night := models.ShiftType{Name: "Nachtschicht", DefaultStart: models.NewWallClock(22, 0), DefaultEnd: models.NewWallClock(6, 0), IsActive: true}
+	night.ID = 2
+	meeting := models.ShiftType{Name: "Besprechung", IsActive: true}
+	meeting.ID = 3
+	users := []models.User{
+		{Name: "Anna Berg", AccountNumber: "1001", TimeZone: "Europe/Berlin"},
+		{Name: "Bernd Krause", AccountNumber: "1002", TimeZone: "UTC"},
+		{Name: "Chris Meier", AccountNumber: "1003"},
+		{Name: "Chris Meier", AccountNumber: "1004"},
+	}
+	for i := range users {
+		users[i].ID = uint(i + 1)
+	}
+	return schedule, users, []models.ShiftType{early, night, meeting}
+}
+
+func TestScheduleImportCodes(t *testing.T) {
+	_, _, shiftTypes := scheduleImportFixture()
+	sick := models.ShiftType{Name: "Kurier", IsActive: true}
+	sick.ID = 4
+	fortbildung := models.ShiftType{Name: "Frühdienst", ShortCode: "F", IsActive: true}
+	fortbildung.ID = 5
+
+	table, err := ScheduleImportCodes(append(shiftTypes, sick), nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), table["F"].ShiftTypeID)
+	assert.Equal(t, uint(2), table["N"].ShiftTypeID)
+	assert.Equal(t, "sick", table["K"].AbsenceType, "Abwesenheiten vor Anfangsbuchstaben")
+	assert.Equal(t, "vacation", table["U"].AbsenceType)
+	assert.True(t, table["FREI"].Ignore)
+
+	// Gleiche Kürzel sind ohne Zuordnung mehrdeutig, mit Zuordnung eindeutig
+	table, err = ScheduleImportCodes(append(shiftTypes, fortbildung), nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Frühdienst", "Frühschicht"}, table["F"].Ambiguous)
+	table, err = ScheduleImportCodes(append(shiftTypes, fortbildung), map[string]uint{"f": 5, "fs": 1}, map[string]string{"url": "vacation"}, []string{"x"})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), table["F"].ShiftTypeID)
+	assert.Equal(t, uint(1), table["FS"].ShiftTypeID)
+	assert.Equal(t, "vacation", table["URL"].AbsenceType)
+	assert.True(t, table["X"].Ignore)
+
+	_, err = ScheduleImportCodes(shiftTypes, map[string]uint{"F": 99}, nil, nil)
+	assert.Error(t, err)
+	_, err = ScheduleImportCodes(shiftTypes, nil, map[string]string{"U": "holiday"}, nil)
+	assert.Error(t, err)
+	_, err = ScheduleImportCodes(shiftTypes, map[string]uint{"U": 1}, map[string]string{"u": "vacation"}, nil)
+	assert.EqualError(t, err, `Kürzel "u" ist mehrfach zugeordnet`)
+}
+
+func TestParseScheduleImport(t *testing.T) {
+	schedule, users, shiftTypes := scheduleImportFixture()
+	codes, _ := ScheduleImportCodes(shiftTypes, nil, nil, nil)
+	rows, err := ReadCSVRows("Dienstplan April\n" +
+		"Name;Nr.;1;2;3;4;31\n" +
+		"Küche;;;;;;\n" +
+		"\"Berg, Anna\";;F;N;U;U;F\n" +
+		"Krause;1002;f / n;-;K;;\n" +
+		"Chris Meier;;F;;;;\n" +
+		"Dora Unbekannt;;F;;;;\n" +
+		"Bernd Krause;;;;Q;B;\n" +
+		"Summe;;3;2;;;\n")
+	assert.NoError(t, err)
+
+	report, err := ParseScheduleImport(rows, schedule, users, shiftTypes, codes, time.UTC)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.HeaderLine)
+	if assert.Len(t, report.Columns, 4) {
+		assert.Equal(t, "C", report.Columns[0].Column)
+		assert.Equal(t, time.Date(2024, 4, 4, 0, 0, 0, 0, time.UTC), report.Columns[3].Date)
+	}
+	assert.Len(t, report.Warnings, 1, "Spalte 31 liegt außerhalb des April")
+	if !assert.Len(t, report.Rows, 5) {
+		return
+	}
+
+	anna := report.Rows[0]
+	assert.Equal(t, 4, anna.Line)
+	assert.Equal(t, uint(1), anna.UserID)
+	assert.Empty(t, anna.Errors)
+	if assert.Len(t, anna.Entries, 4) {
+		// Frühschicht 06:00–14:00 Ortszeit des Benutzers (Sommerzeit)
+		assert.Equal(t, time.Date(2024, 4, 1, 4, 0, 0, 0, time.UTC), anna.Entries[0].StartTime.UTC())
+		assert.Equal(t, 30, anna.Entries[0].BreakTime)
+		// Nachtschicht endet am Folgetag
+		assert.Equal(t, time.Date(2024, 4, 3, 4, 0, 0, 0, time.UTC), anna.Entries[1].EndTime.UTC())
+		assert.Equal(t, "vacation", anna.Entries[2].AbsenceType)
+	}
+	shifts, absences := ScheduleImportRecords(anna, schedule.ID)
+	assert.Len(t, shifts, 2)
+	if assert.Len(t, absences, 1, "zusammenhängender Urlaub") {
+		assert.Equal(t, time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC), absences[0].StartDate)
+		assert.Equal(t, time.Date(2024, 4, 4, 0, 0, 0, 0, time.UTC), absences[0].EndDate)
+		assert.Equal(t, models.AbsenceStatusApproved, absences[0].Status)
+	}
+
+	// Personalnummer hat Vorrang, mehrere Kürzel je Zelle
+	assert.Equal(t, uint(2), report.Rows[1].UserID)
+	assert.Len(t, report.Rows[1].Entries, 3)
+
+	assert.Equal(t, []string{"Name „Chris Meier“ ist nicht eindeutig, bitte Personalnummer angeben"}, report.Rows[2].Errors)
+	assert.Equal(t, []string{"Kein Benutzer zu „Dora Unbekannt“ gefunden"}, report.Rows[3].Errors)
+	assert.Equal(t, []string{
+		"Bernd Krause steht bereits in Zeile 5",
+		"3: Unbekanntes Kürzel „Q“",
+		"4: Schichttyp Besprechung hat keine Standardzeiten",
+	}, report.Rows[4].Errors)
+
+	assert.Equal(t, 5, report.Total)
+	assert.Equal(t, 2, report.Valid)
+	assert.Equal(t, 3, report.Invalid)
+	assert.Equal(t, 4, report.Shifts)
+	assert.Equal(t, 2, report.Absences)
+
+	_, err = ParseScheduleImport([][]string{{"Name", "Montag"}}, schedule, users, shiftTypes, codes, time.UTC)
+	assert.Error(t, err)
+}
+
+func TestCheckScheduleImport(t *testing.T) {
+	schedule, users, shiftTypes := scheduleImportFixture()
+	codes, _ := ScheduleImportCodes(shiftTypes, nil, nil, nil)
+	rows := [][]string{{"Personalnummer", "01.04.2024", "02.04.2024", "03.04.2024"}, {"1002", "F", "F", "U"}}
+	report, err := ParseScheduleImport(rows, schedule, users, shiftTypes, codes, time.UTC)
+	assert.NoError(t, err)
+
+	existing := []models.Shift{{UserID: 2, StartTime: time.Date(2024, 4, 1, 6, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 4, 1, 14, 0, 0, 0, time.UTC), IsActive: true}}
+	absences := []models.Absence{{UserID: 2, Type: models.AbsenceTypeSick, Status: models.AbsenceStatusApproved, StartDate: time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 4, 3, 0, 0, 0, 0, time.UTC)}}
+	CheckScheduleImport(&report, existing, absences)
+
+	row := report.Rows[0]
+	assert.True(t, row.Entries[0].Skipped)
+	assert.Equal(t, []string{"01.04.: Schicht F ist bereits vorhanden", "02.04.: Schicht trotz Krankheit"}, row.Warnings)
+	assert.Equal(t, []string{"03.04.: Es ist bereits Krankheit eingetragen"}, row.Errors)
+	assert.Equal(t, 1, report.Invalid)
+}
+
+func TestScheduleImportRoundTrip(t *testing.T) {
+	schedule, users, shiftTypes := scheduleImportFixture()
+	users = users[:2]
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	users[1].TimeZone = "Europe/Berlin"
+	team := models.Team{Name: "Pflege"}
+	team.ID = 1
+	users[0].TeamID = &team.ID
+
+	start := time.Date(2024, 4, 2, 4, 0, 0, 0, time.UTC)
+	shifts := []models.Shift{
+		{UserID: 1, ShiftTypeID: &shiftTypes[0].ID, ShiftType: shiftTypes[0], StartTime: start, EndTime: start.Add(8 * time.Hour), IsActive: true},
+		{UserID: 2, ShiftTypeID: &shiftTypes[1].ID, ShiftType: shiftTypes[1], StartTime: time.Date(2024, 4, 5, 20, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 4, 6, 4, 0, 0, 0, time.UTC), IsActive: true},
+	}
+	absences := []models.Absence{{UserID: 2, Type: models.AbsenceTypeVacation, StartDate: time.Date(2024, 4, 10, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 4, 12, 0, 0, 0, 0, time.UTC)}}
+	grid := BuildScheduleGrid(schedule, users, []models.Team{team}, shifts, absences, loc)
+	codes, _ := ScheduleImportCodes(shiftTypes, nil, nil, nil)
+
+	data, err := ScheduleGridXLSX(grid)
+	assert.NoError(t, err)
+	fromXLSX, err := ReadXLSX(data)
+	assert.NoError(t, err)
+	assert.Equal(t, ScheduleGridRows(grid)[1][:5], fromXLSX[1][:5])
+
+	for _, rows := range [][][]string{ScheduleGridRows(grid), fromXLSX} {
+		report, err := ParseScheduleImport(rows, schedule, users, shiftTypes, codes, loc)
+		assert.NoError(t, err)
+		assert.Len(t, report.Columns, 30)
+		assert.Equal(t, 2, report.Valid)
+		assert.Equal(t, 0, report.Invalid)
+		assert.Equal(t, 2, report.Shifts)
+		assert.Equal(t, 1, report.Absences)
+
+		imported, _ := ScheduleImportRecords(report.Rows[0], schedule.ID)
+		if assert.Len(t, imported, 1) {
+			assert.True(t, imported[0].StartTime.Equal(shifts[0].StartTime))
+			assert.True(t, imported[0].EndTime.Equal(shifts[0].EndTime))
+		}
+	}
+}
+
+func TestReadXLSXErrors(t *testing.T) {
+	_, err := ReadXLSX([]byte("keine Datei"))
+	assert.EqualError(t, err, "Keine gültige Excel-Datei (XLSX)")
+
+	index, err := XLSXColumnIndex("AA12")
+	assert.NoError(t, err)
+	assert.Equal(t, 26, index)
+	_, err = XLSXColumnIndex("12")
+	assert.Error(t, err)
+}
+
+// minimalXLSX erzeugt eine Arbeitsmappe mit einer Tabelle aus dem angegebenen Inhalt von sheetData
+func minimalXLSX(t *testing.T, sheetData string) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Plan" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml":   `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	} {
+		writer, err := archive.Create(name)
+		assert.NoError(t, err)
+		_, err = writer.Write([]byte(content))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, archive.Close())
+	return buf.Bytes()
+}
+
+func TestReadXLSXLimits(t *testing.T) {
+	rows, err := ReadXLSX(minimalXLSX(t, `<row r="2"><c r="B2" t="inlineStr"><is><t>F</t></is></c></row>`))
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{nil, {"", "F"}}, rows)
+
+	// Zeilen- und Spaltennummern aus der Datei werden vor dem Anlegen geprüft
+	_, err = ReadXLSX(minimalXLSX(t, `<row r="400000000"><c r="A400000000"><v>1</v></c></row>`))
+	assert.EqualError(t, err, fmt.Sprintf("Excel-Datei hat zu viele Zeilen (höchstens %d)", maxXLSXRows))
+	_, err = ReadXLSX(minimalXLSX(t, `<row r="1"><c r="XFD1"><v>1</v></c></row>`))
+	assert.EqualError(t, err, fmt.Sprintf("Excel-Datei hat zu viele Spalten (höchstens %d)", maxXLSXColumns))
+
+	var sheetData strings.Builder
+	for i := 1; i <= maxXLSXCells/(maxXLSXColumns-1)+1; i++ {
+		fmt.Fprintf(&sheetData, `<row r="%d"><c r="ALK%d"><v>1</v></c></row>`, i, i)
+	}
+	_, err = ReadXLSX(minimalXLSX(t, sheetData.String()))
+	assert.EqualError(t, err, fmt.Sprintf("Excel-Datei hat zu viele Zellen (höchstens %d)", maxXLSXCells))
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// germanMonths sind die Monatsnamen für Überschriften, beginnend mit Januar
+var germanMonths = [...]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"}
+
+// Maße des gedruckten Dienstplans in Punkt (A4 quer)
+const (
+	rosterMargin         = 28.0
+	rosterTableTop       = 72.0
+	rosterHeaderHeight   = 22.0
+	rosterTotalWidth     = 42.0
+	rosterSignatureSpace = 52.0
+	rosterLegendLine     = 12.0
+	rosterGridColor      = "#D1D5DB"
+	rosterWeekendColor   = "#F3F4F6"
+	rosterGroupColor     = "#6B7280"
+)
+
+// rosterLayout enthält die Maße eines Layouts
+type rosterLayout struct {
+	days      int     // Tagesspalten je Seite
+	nameWidth float64 // Breite der Namensspalte
+	rowHeight float64
+	fontSize  float64
+}
+
+// rosterLayouts sind die Maße je Layout
+var rosterLayouts = map[string]rosterLayout{
+	models.ScheduleGridLayoutWeek:  {days: 7, nameWidth: 170, rowHeight: 17, fontSize: 9},
+	models.ScheduleGridLayoutMonth: {days: 31, nameWidth: 120, rowHeight: 13, fontSize: 6.5},
+}
+
+// rosterPeriod ist ein Abschnitt des Plans, der auf eigenen Seiten gedruckt wird (Tage from bis to exklusive)
+type rosterPeriod struct {
+	from, to int
+	label    string
+}
+
+// rosterEntry ist eine Zeile der Tabelle: Teamüberschrift (row == nil) oder Benutzer
+type rosterEntry struct {
+	group *models.ScheduleGridGroup
+	row   *models.ScheduleGridRow
+}
+
+// ScheduleGridPDF erstellt den druckbaren Dienstplan als PDF (A4 quer): je Kalenderwoche (layout week) oder
+// Kalendermonat (layout month) eine Tabelle mit den Benutzern nach Teams gruppiert, Kürzeln in der Farbe des
+// Schichttyps, Stunden und Besetzung je Tag. Jede Seite enthält die Legende und Unterschriftszeilen für Arbeitgeber
+// und Betriebsrat. generatedAt erscheint als Erstellungszeitpunkt in der Kopfzeile.
+func ScheduleGridPDF(grid models.ScheduleGrid, layout string, generatedAt time.Time) ([]byte, error) {
+	dims, ok := rosterLayouts[layout]
+	if !ok {
+		return nil, fmt.Errorf("Unbekanntes Layout: %s", layout)
+	}
+
+	doc := &PDFDocument{Title: "Dienstplan " + grid.ScheduleName}
+	width, height := PDFA4Height, PDFA4Width
+	dayWidth := (width - 2*rosterMargin - dims.nameWidth - rosterTotalWidth) / float64(dims.days)
+	legend := scheduleGridLegend(grid)
+	legendHeight := rosterLegendHeight(legend, width-2*rosterMargin)
+	tableBottom := height - rosterMargin - rosterSignatureSpace - legendHeight - 8
+
+	var entries []rosterEntry
+	for g := range grid.Groups {
+		group := &grid.Groups[g]
+		entries = append(entries, rosterEntry{group: group})
+		for r := range group.Rows {
+			entries = append(entries, rosterEntry{group: group, row: &group.Rows[r]})
+		}
+	}
+
+	for _, period := range scheduleGridPeriods(grid.Days, layout) {
+		next := 0
+		for {
+			page := doc.AddPage(width, height)
+			rosterPageHeader(page, grid, period, generatedAt)
+			y := rosterTableHeader(page, grid, period, dims, dayWidth)
+
+			// Die Besetzungszeile steht unter der letzten Zeile des Abschnitts und wird stets freigehalten
+			if next < len(entries) && entries[next].row != nil {
+				rosterGroupRow(page, entries[next].group, y, width, dims, true)
+				y += dims.rowHeight
+			}
+			// Jede Seite erhält mindestens eine Zeile, auch wenn die Legende kaum Platz lässt
+			for placed := 0; next < len(entries) && (placed == 0 || y+2*dims.rowHeight <= tableBottom); placed++ {
+				entry := entries[next]
+				// Keine Teamüberschrift allein am Seitenende
+				if placed > 0 && entry.row == nil && y+3*dims.rowHeight > tableBottom {
+					break
+				}
+				if entry.row == nil {
+					rosterGroupRow(page, entry.group, y, width, dims, false)
+				} else {
+					rosterUserRow(page, *entry.row, grid.Days, period, y, dims, dayWidth)
+				}
+				y += dims.rowHeight
+				next++
+			}
+			if next >= len(entries) {
+				rosterHeadcountRow(page, grid, period, y, dims, dayWidth)
+			}
+			rosterLegend(page, legend, tableBottom+8)
+			rosterSignatures(page)
+			if next >= len(entries) {
+				break
+			}
+		}
+	}
+
+	for i, page := range doc.pages {
+		label := fmt.Sprintf("Seite %d von %d", i+1, len(doc.pages))
+		page.Text(page.Width-rosterMargin-PDFTextWidth(label, 7, false), page.Height-14, 7, false, "", label)
+	}
+	return doc.Bytes()
+}
+
+// scheduleGridPeriods teilt die Tage des Plans in Kalenderwochen (Montag bis Sonntag) oder Kalendermonate
+func scheduleGridPeriods(days []time.Time, layout string) []rosterPeriod {
+	if len(days) == 0 {
+		return []rosterPeriod{{label: "Keine Tage im Zeitraum"}}
+	}
+	var periods []rosterPeriod
+	from := 0
+	for i := 1; i <= len(days); i++ {
+		if i < len(days) {
+			day := days[i]
+			if (layout == models.ScheduleGridLayoutWeek && day.Weekday() != time.Monday) ||
+				(layout == models.ScheduleGridLayoutMonth && day.Day() != 1) {
+				continue
+			}
+		}
+		first, last := days[from], days[i-1]
+		label := fmt.Sprintf("%s %d", germanMonths[first.Month()-1], first.Year())
+		if layout == models.ScheduleGridLayoutWeek {
+			year, week := first.ISOWeek()
+			label = fmt.Sprintf("KW %d/%d: %s–%s", week, year, first.Format("02.01."), last.Format("02.01.2006"))
+		}
+		periods = append(periods, rosterPeriod{from: from, to: i, label: label})
+		from = i
+	}
+	return periods
+}
+
+// scheduleGridLegend liefert die Einträge der Legende: Kürzel, Bedeutung und Farbe
+func scheduleGridLegend(grid models.ScheduleGrid) [][3]string {
+	legend := make([][3]string, 0, len(grid.ShiftTypes)+len(models.AbsenceTypes)+1)
+	for _, shiftType := range grid.ShiftTypes {
+		legend = append(legend, [3]string{shiftType.Code(), scheduleGridShiftTypeLabel(shiftType), shiftType.Color})
+	}
+	if _, ok := grid.ShiftTypeHeadcounts[0]; ok {
+		legend = append(legend, [3]string{ScheduleGridUntypedCode, "Schicht ohne Schichttyp", ""})
+	}
+	for _, absenceType := range models.AbsenceTypes {
+		legend = append(legend, [3]string{models.AbsenceTypeCode(absenceType), models.AbsenceTypeLabel(absenceType), ""})
+	}
+	return legend
+}
+
+// rosterLegendItemWidth liefert die Breite eines Legendeneintrags (Farbfeld mit Kürzel, Bedeutung, Abstand)
+func rosterLegendItemWidth(item [3]string) float64 {
+	return 24 + PDFTextWidth(item[1], 7, false) + 14
+}
+
+// rosterLegendHeight liefert die Höhe der Legende bei der verfügbaren Breite
+func rosterLegendHeight(legend [][3]string, width float64) float64 {
+	lines, x := 1, 0.0
+	for _, item := range legend {
+		itemWidth := rosterLegendItemWidth(item)
+		if x > 0 && x+itemWidth > width {
+			lines++
+			x = 0
+		}
+		x += itemWidth
+	}
+	return 12 + float64(lines)*rosterLegendLine
+}
+
+// rosterPageHeader schreibt Titel, Zeitraum und Erstellungszeitpunkt
+func rosterPageHeader(page *PDFPage, grid models.ScheduleGrid, period rosterPeriod, generatedAt time.Time) {
+	page.Text(rosterMargin, 40, 14, true, "", "Dienstplan "+grid.ScheduleName)
+	page.Text(rosterMargin, 58, 10, false, "", period.label)
+	created := "Erstellt am " + generatedAt.Format("02.01.2006 15:04")
+	page.Text(page.Width-rosterMargin-PDFTextWidth(created, 8, false), 40, 8, false, "", created)
+}
+
+// rosterTableHeader zeichnet die Kopfzeile der Tabelle und liefert die y-Position der ersten Zeile
+func rosterTableHeader(page *PDFPage, grid models.ScheduleGrid, period rosterPeriod, dims rosterLayout, dayWidth float64) float64 {
+	y := rosterTableTop
+	page.FillRect(rosterMargin, y, page.Width-2*rosterMargin, rosterHeaderHeight, "#E5E7EB")
+	page.Text(rosterMargin+3, y+14, 8, true, "", "Name")
+	x := rosterMargin + dims.nameWidth
+	for i := period.from; i < period.to; i++ {
+		day := grid.Days[i]
+		if isWeekend(day) {
+			page.FillRect(x, y, dayWidth, rosterHeaderHeight, rosterGridColor)
+		}
+		center := x + dayWidth/2
+		if dims.days > 7 {
+			page.TextCentered(center, y+9, 6.5, true, "", germanWeekdays[day.Weekday()])
+			page.TextCentered(center, y+18, 6.5, false, "", day.Format("02."))
+		} else {
+			page.TextCentered(center, y+14, 8, true, "", ScheduleGridDayLabel(day))
+		}
+		page.StrokeRect(x, y, dayWidth, rosterHeaderHeight, 0.25)
+		x += dayWidth
+	}
+	page.TextCentered(page.Width-rosterMargin-rosterTotalWidth/2, y+14, 8, true, "", "Std.")
+	page.StrokeRect(rosterMargin, y, page.Width-2*rosterMargin, rosterHeaderHeight, 0.5)
+	return y + rosterHeaderHeight
+}
+
+// rosterGroupRow zeichnet die Überschrift eines Teams in der Teamfarbe
+func rosterGroupRow(page *PDFPage, group *models.ScheduleGridGroup, y, width float64, dims rosterLayout, continued bool) {
+	color := group.Color
+	if _, ok := pdfColor(color); !ok {
+		color = rosterGroupColor
+	}
+	page.FillRect(rosterMargin, y, width-2*rosterMargin, dims.rowHeight, color)
+	label := group.Name
+	if continued {
+		label += " (Fortsetzung)"
+	}
+	page.Text(rosterMargin+3, y+dims.rowHeight-4, dims.fontSize, true, rosterTextColor(color), label)
+}
+
+// rosterUserRow zeichnet die Zeile eines Benutzers mit Kürzeln und Stunden des Abschnitts
+func rosterUserRow(page *PDFPage, row models.ScheduleGridRow, days []time.Time, period rosterPeriod, y float64, dims rosterLayout, dayWidth float64) {
+	baseline := y + dims.rowHeight - 4
+	name := row.Name
+	if row.AccountNumber != "" && dims.days <= 7 {
+		name += " (" + row.AccountNumber + ")"
+	}
+	page.Text(rosterMargin+3, baseline, dims.fontSize, false, "", PDFFitText(name, dims.nameWidth-6, dims.fontSize, false))
+	page.StrokeRect(rosterMargin, y, dims.nameWidth, dims.rowHeight, 0.25)
+
+	minutes := 0
+	x := rosterMargin + dims.nameWidth
+	for i := period.from; i < period.to; i++ {
+		cell := row.Cells[i]
+		minutes += cell.Minutes
+		switch {
+		case cell.Color != "":
+			page.FillRect(x, y, dayWidth, dims.rowHeight, cell.Color)
+		case isWeekend(days[i]):
+			page.FillRect(x, y, dayWidth, dims.rowHeight, rosterWeekendColor)
+		}
+		if text := cell.Text(); text != "" {
+			page.TextCentered(x+dayWidth/2, baseline, dims.fontSize, true, rosterTextColor(cell.Color), PDFFitText(text, dayWidth-2, dims.fontSize, true))
+		}
+		page.StrokeRect(x, y, dayWidth, dims.rowHeight, 0.25)
+		x += dayWidth
+	}
+	totalX := page.Width - rosterMargin - rosterTotalWidth
+	hours := ScheduleGridHours(minutes)
+	page.Text(totalX+rosterTotalWidth-3-PDFTextWidth(hours, dims.fontSize, false), baseline, dims.fontSize, false, "", hours)
+	page.StrokeRect(totalX, y, rosterTotalWidth, dims.rowHeight, 0.25)
+}
+
+// rosterHeadcountRow zeichnet die Besetzung je Tag unter der Tabelle
+func rosterHeadcountRow(page *PDFPage, grid models.ScheduleGrid, period rosterPeriod, y float64, dims rosterLayout, dayWidth float64) {
+	baseline := y + dims.rowHeight - 4
+	page.FillRect(rosterMargin, y, page.Width-2*rosterMargin, dims.rowHeight, "#E5E7EB")
+	page.Text(rosterMargin+3, baseline, dims.fontSize, true, "", "Besetzung")
+	x := rosterMargin + dims.nameWidth
+	for i := period.from; i < period.to; i++ {
+		page.TextCentered(x+dayWidth/2, baseline, dims.fontSize, true, "", strconv.Itoa(grid.Headcounts[i]))
+		page.StrokeRect(x, y, dayWidth, dims.rowHeight, 0.25)
+		x += dayWidth
+	}
+	page.StrokeRect(rosterMargin, y, page.Width-2*rosterMargin, dims.rowHeight, 0.5)
+}
+
+// rosterLegend zeichnet die Legende mit Farbfeldern der Schichttypen und den Kürzeln der Abwesenheiten
+func rosterLegend(page *PDFPage, legend [][3]string, top float64) {
+	page.Text(rosterMargin, top+8, 8, true, "", "Legende")
+	x, y := rosterMargin, top+12
+	for _, item := range legend {
+		itemWidth := rosterLegendItemWidth(item)
+		if x > rosterMargin && x+itemWidth > page.Width-rosterMargin {
+			x = rosterMargin
+			y += rosterLegendLine
+		}
+		page.FillRect(x, y+1, 20, 9, item[2])
+		page.StrokeRect(x, y+1, 20, 9, 0.25)
+		page.TextCentered(x+10, y+8, 6.5, true, rosterTextColor(item[2]), item[0])
+		page.Text(x+24, y+8, 7, false, "", item[1])
+		x += itemWidth
+	}
+}
+
+// rosterSignatures zeichnet die Unterschriftszeilen für Arbeitgeber und Betriebsrat
+func rosterSignatures(page *PDFPage) {
+	y := page.Height - rosterMargin - 14
+	lineWidth := (page.Width - 2*rosterMargin - 60) / 2
+	for i, label := range []string{"Datum, Unterschrift Arbeitgeber", "Datum, Unterschrift Betriebsrat"} {
+		x := rosterMargin + float64(i)*(lineWidth+60)
+		page.Line(x, y, x+lineWidth, y, 0.5)
+		page.Text(x, y+9, 7, false, "", label)
+	}
+}
+
+// rosterTextColor liefert Weiß für dunkle Hintergründe, sonst Schwarz
+func rosterTextColor(background string) string {
+	if background != "" && isDarkColor(background) {
+		return "#FFFFFF"
+	}
+	return ""
+}
+
+// isWeekend prüft, ob day ein Samstag oder Sonntag ist
+func isWeekend(day time.Time) bool {
+	return day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pdfContents liefert die entpackten Seiteninhalte einer PDF-Datei
+func pdfContents(t *testing.T, data []byte) []string {
+	var contents []string
+	for _, match := range regexp.MustCompile(`(?s)/FlateDecode >>\nstream\n(.*?)\nendstream`).FindAllSubmatch(data, -1) {
+		reader, err := zlib.NewReader(bytes.NewReader(match[1]))
+		if !assert.NoError(t, err) {
+			return nil
+		}
+		content, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		contents = append(contents, string(content))
+	}
+	return contents
+}
+
+func TestScheduleGridPDF(t *testing.T) {
+	generatedAt := time.Date(2024, 3, 28, 14, 30, 0, 0, time.UTC)
+	data, err := ScheduleGridPDF(scheduleGridFixture(), models.ScheduleGridLayoutWeek, generatedAt)
+	assert.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte("%PDF-1.4")))
+	assert.True(t, bytes.HasSuffix(data, []byte("%%EOF\n")))
+
+	// Der Querverweis zeigt auf die Tabelle am Ende der Datei
+	match := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(data)
+	if assert.NotNil(t, match) {
+		offset, _ := strconv.Atoi(string(match[1]))
+		assert.True(t, bytes.HasPrefix(data[offset:], []byte("xref\n0 8\n")))
+	}
+	assert.Contains(t, string(data), "/Count 1")
+	assert.Contains(t, string(data), "/MediaBox [0 0 841.89 595.28]")
+
+	contents := pdfContents(t, data)
+	if assert.Len(t, contents, 1) {
+		page := contents[0]
+		assert.Contains(t, page, "(Dienstplan KW 14)")
+		assert.Contains(t, page, "("+pdfString("KW 14/2024: 01.04.–04.04.2024")+")")
+		assert.Contains(t, page, "(Erstellt am 28.03.2024 14:30)")
+		assert.Contains(t, page, "("+pdfString("Küche")+")")
+		assert.Contains(t, page, "(Anna \\(1001\\))")
+		assert.Contains(t, page, "(F/N)")
+		assert.Contains(t, page, "(15,00)")
+		assert.Contains(t, page, "(Besetzung)")
+		assert.Contains(t, page, "("+pdfString("Frühschicht (06:00–14:00)")[:4])
+		assert.Contains(t, page, "(Datum, Unterschrift Betriebsrat)")
+		assert.Contains(t, page, "(Seite 1 von 1)")
+		// Frühschicht in ihrer Farbe
+		assert.Contains(t, page, "0.961 0.620 0.043 rg")
+	}
+
+	_, err = ScheduleGridPDF(scheduleGridFixture(), "year", generatedAt)
+	assert.Error(t, err)
+}
+
+func TestScheduleGridPDFPagination(t *testing.T) {
+	team := models.Team{Name: "Lager"}
+	team.ID = 1
+	users := make([]models.User, 60)
+	for i := range users {
+		users[i] = models.User{Name: fmt.Sprintf("Mitarbeiter %02d", i+1), TeamID: &team.ID}
+		users[i].ID = uint(i + 1)
+	}
+	schedule := models.Schedule{Name: "Frühjahr", StartDate: time.Date(2024, 3, 28, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 4, 9, 0, 0, 0, 0, time.UTC)}
+	grid := BuildScheduleGrid(schedule, users, []models.Team{team}, nil, nil, time.UTC)
+
+	// Drei Kalenderwochen mit je mehreren Seiten
+	data, err := ScheduleGridPDF(grid, models.ScheduleGridLayoutWeek, time.Now())
+	assert.NoError(t, err)
+	contents := pdfContents(t, data)
+	assert.Greater(t, len(contents), 3)
+	assert.Equal(t, 0, len(contents)%3)
+	assert.Contains(t, contents[1], "(Lager \\(Fortsetzung\\))")
+	assert.Contains(t, contents[len(contents)-1], fmt.Sprintf("(Seite %d von %d)", len(contents), len(contents)))
+	for _, content := range contents {
+		assert.Contains(t, content, "(Legende)")
+		assert.Contains(t, content, "(Datum, Unterschrift Arbeitgeber)")
+	}
+
+	data, err = ScheduleGridPDF(grid, models.ScheduleGridLayoutMonth, time.Now())
+	assert.NoError(t, err)
+	contents = pdfContents(t, data)
+	if assert.Len(t, contents, 6) {
+		assert.Contains(t, contents[0], "("+pdfString("März 2024")+")")
+		assert.Contains(t, contents[2], "("+pdfString("März 2024")+")")
+		assert.Contains(t, contents[3], "(April 2024)")
+	}
+}
+
+func TestScheduleGridPeriods(t *testing.T) {
+	var days []time.Time
+	for day := time.Date(2024, 3, 28, 0, 0, 0, 0, time.UTC); day.Day() != 10; day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+	}
+
+	weeks := scheduleGridPeriods(days, models.ScheduleGridLayoutWeek)
+	if assert.Len(t, weeks, 3) {
+		assert.Equal(t, rosterPeriod{from: 0, to: 4, label: "KW 13/2024: 28.03.–31.03.2024"}, weeks[0])
+		assert.Equal(t, rosterPeriod{from: 4, to: 11, label: "KW 14/2024: 01.04.–07.04.2024"}, weeks[1])
+		assert.Equal(t, 13, weeks[2].to)
+	}
+	months := scheduleGridPeriods(days, models.ScheduleGridLayoutMonth)
+	if assert.Len(t, months, 2) {
+		assert.Equal(t, "März 2024", months[0].label)
+		assert.Equal(t, 4, months[1].from)
+	}
+	assert.Len(t, scheduleGridPeriods(nil, models.ScheduleGridLayoutWeek), 1)
+}
+
+func TestPDFText(t *testing.T) {
+	assert.Equal(t, "Gr\xfc\xdfe \\(Test\\) \x96 \x80", pdfString("Grüße (Test) – €"))
+	assert.Equal(t, "?", pdfString("✓"))
+	assert.InDelta(t, 5.56*2+2.78, PDFTextWidth("ab.", 10, false), 0.001)
+	assert.InDelta(t, PDFTextWidth("a", 10, true), PDFTextWidth("ä", 10, true), 0.001)
+	assert.Equal(t, "Mitarb…", PDFFitText("Mitarbeiterin", 40, 10, false))
+	assert.Equal(t, "Kurz", PDFFitText("Kurz", 40, 10, false))
+	assert.Equal(t, "1.25", pdfNumber(1.2549))
+	assert.Equal(t, "595.28", pdfNumber(PDFA4Width))
+}
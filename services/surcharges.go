@@ -0,0 +1,212 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// EStG3bSurchargeRules liefert die steuerfreien Höchstsätze nach §3b EStG als Voreinstellung
+func EStG3bSurchargeRules() []models.SurchargeRule {
+	return []models.SurchargeRule{
+		{Name: "Nachtarbeit", Type: models.SurchargeTypeNight, Percentage: 25, WindowStart: models.NewWallClock(20, 0), WindowEnd: models.NewWallClock(6, 0), IsActive: true},
+		{Name: "Nachtarbeit 0 bis 4 Uhr", Type: models.SurchargeTypeNight, Percentage: 40, WindowStart: models.NewWallClock(0, 0), WindowEnd: models.NewWallClock(4, 0), StartedBeforeMidnight: true, IsActive: true},
+		{Name: "Sonntagsarbeit", Type: models.SurchargeTypeSunday, Percentage: 50, IsActive: true},
+		{Name: "Feiertagsarbeit", Type: models.SurchargeTypeHoliday, Percentage: 125, IsActive: true},
+		{Name: "Heiligabend ab 14 Uhr", Type: models.SurchargeTypeSpecialDay, Percentage: 150, Month: 12, Day: 24, WindowStart: models.NewWallClock(14, 0), WindowEnd: models.NewWallClock(0, 0), IsActive: true},
+		{Name: "1. Weihnachtstag", Type: models.SurchargeTypeSpecialDay, Percentage: 150, Month: 12, Day: 25, IsActive: true},
+		{Name: "2. Weihnachtstag", Type: models.SurchargeTypeSpecialDay, Percentage: 150, Month: 12, Day: 26, IsActive: true},
+		{Name: "Silvester ab 14 Uhr", Type: models.SurchargeTypeSpecialDay, Percentage: 125, Month: 12, Day: 31, WindowStart: models.NewWallClock(14, 0), WindowEnd: models.NewWallClock(0, 0), IsActive: true},
+		{Name: "1. Mai", Type: models.SurchargeTypeSpecialDay, Percentage: 150, Month: 5, Day: 1, IsActive: true},
+	}
+}
+
+// ValidateSurchargeRule prüft die Parameter einer Zuschlagsregel
+func ValidateSurchargeRule(rule models.SurchargeRule) error {
+	if rule.Percentage <= 0 || rule.Percentage > 1000 {
+		return fmt.Errorf("Zuschlagssatz muss zwischen 0 und 1000 Prozent liegen")
+	}
+	if rule.WindowStart.Valid != rule.WindowEnd.Valid {
+		return fmt.Errorf("Zeitfenster benötigt Beginn und Ende")
+	}
+	if rule.WindowStart.Valid && rule.WindowStart.Minutes == rule.WindowEnd.Minutes && rule.WindowStart.Minutes != 0 {
+		return fmt.Errorf("Beginn und Ende des Zeitfensters dürfen nicht gleich sein")
+	}
+
+	switch rule.Type {
+	case models.SurchargeTypeNight:
+		if !rule.WindowStart.Valid {
+			return fmt.Errorf("Nachtzuschlag benötigt ein Zeitfenster")
+		}
+	case models.SurchargeTypeSunday, models.SurchargeTypeHoliday:
+	case models.SurchargeTypeSpecialDay:
+		// Gegen ein Schaltjahr prüfen, damit der 29. Februar erlaubt ist
+		day := time.Date(2024, time.Month(rule.Month), rule.Day, 0, 0, 0, 0, time.UTC)
+		if rule.Month < 1 || rule.Month > 12 || day.Day() != rule.Day {
+			return fmt.Errorf("Sondertag benötigt einen gültigen Monat und Tag")
+		}
+	default:
+		return fmt.Errorf("unbekannter Zuschlagstyp: %s", rule.Type)
+	}
+
+	return nil
+}
+
+// SurchargeCalculator teilt Schichten in Zeitabschnitte und ordnet ihnen Zuschlagsregeln zu
+type SurchargeCalculator struct {
+	rules    []models.SurchargeRule
+	holidays *HolidayCalendar
+}
+
+// NewSurchargeCalculator erstellt einen Rechner mit den aktiven Regeln; holidays ist optional
+func NewSurchargeCalculator(rules []models.SurchargeRule, holidays *HolidayCalendar) *SurchargeCalculator {
+	active := make([]models.SurchargeRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.IsActive {
+			active = append(active, rule)
+		}
+	}
+	return &SurchargeCalculator{rules: active, holidays: holidays}
+}
+
+// Calculate berechnet die zuschlagspflichtige Zeit einer Schicht in der Zeitzone loc.
+// Die Pause wird anteilig von allen Zeitabschnitten abgezogen, da ihre Lage nicht erfasst ist.
+func (c *SurchargeCalculator) Calculate(shift models.Shift, loc *time.Location) models.ShiftSurcharge {
+	result := models.ShiftSurcharge{
+		ShiftID:   shift.ID,
+		UserID:    shift.UserID,
+		StartTime: shift.StartTime,
+		EndTime:   shift.EndTime,
+		Lines:     make([]models.SurchargeLine, 0),
+	}
+
+	gross := shift.EndTime.Sub(shift.StartTime)
+	if gross <= 0 {
+		return result
+	}
+	net := gross - time.Duration(shift.BreakTime)*time.Minute
+	if net < 0 {
+		net = 0
+	}
+	factor := float64(net) / float64(gross)
+	result.WorkedMinutes = roundTo(net.Minutes(), 2)
+
+	minutes := make([]float64, len(c.rules))
+	for t := shift.StartTime; t.Before(shift.EndTime); t = t.Add(time.Minute) {
+		step := time.Minute
+		if remaining := shift.EndTime.Sub(t); remaining < step {
+			step = remaining
+		}
+
+		night, day := c.matchingRules(t.In(loc), shift.StartTime, loc)
+		if night >= 0 {
+			minutes[night] += step.Minutes() * factor
+		}
+		if day >= 0 {
+			minutes[day] += step.Minutes() * factor
+		}
+	}
+
+	for i, rule := range c.rules {
+		if minutes[i] == 0 {
+			continue
+		}
+		result.Lines = append(result.Lines, models.SurchargeLine{
+			RuleID:     rule.ID,
+			Name:       rule.Name,
+			Type:       rule.Type,
+			Percentage: rule.Percentage,
+			Minutes:    roundTo(minutes[i], 2),
+			Hours:      roundTo(minutes[i]/60, 2),
+		})
+	}
+	return result
+}
+
+// SummarizeSurcharges fasst die Zuschläge mehrerer Schichten je Regel zusammen
+func SummarizeSurcharges(results []models.ShiftSurcharge) (float64, []models.SurchargeLine) {
+	type lineKey struct {
+		ruleID     uint
+		name       string
+		percentage float64
+	}
+
+	var worked float64
+	lines := make([]models.SurchargeLine, 0)
+	index := make(map[lineKey]int)
+	for _, result := range results {
+		worked += result.WorkedMinutes
+		for _, line := range result.Lines {
+			key := lineKey{line.RuleID, line.Name, line.Percentage}
+			i, ok := index[key]
+			if !ok {
+				i = len(lines)
+				index[key] = i
+				lines = append(lines, models.SurchargeLine{RuleID: line.RuleID, Name: line.Name, Type: line.Type, Percentage: line.Percentage})
+			}
+			lines[i].Minutes += line.Minutes
+		}
+	}
+
+	for i := range lines {
+		lines[i].Minutes = roundTo(lines[i].Minutes, 2)
+		lines[i].Hours = roundTo(lines[i].Minutes/60, 2)
+	}
+	return roundTo(worked, 2), lines
+}
+
+// matchingRules liefert je Gruppe (Nacht, Tag) die Regel mit dem höchsten Satz oder -1
+func (c *SurchargeCalculator) matchingRules(local time.Time, shiftStart time.Time, loc *time.Location) (int, int) {
+	night, day := -1, -1
+	for i, rule := range c.rules {
+		if !c.applies(rule, local, shiftStart, loc) {
+			continue
+		}
+		if rule.IsNight() {
+			if night < 0 || rule.Percentage > c.rules[night].Percentage {
+				night = i
+			}
+		} else if day < 0 || rule.Percentage > c.rules[day].Percentage {
+			day = i
+		}
+	}
+	return night, day
+}
+
+// applies prüft, ob eine Regel für die Minute local gilt
+func (c *SurchargeCalculator) applies(rule models.SurchargeRule, local time.Time, shiftStart time.Time, loc *time.Location) bool {
+	if rule.WindowStart.Valid && !inWindow(local.Hour()*60+local.Minute(), rule.WindowStart.Minutes, rule.WindowEnd.Minutes) {
+		return false
+	}
+	if rule.StartedBeforeMidnight && !shiftStart.Before(startOfDay(local, loc)) {
+		return false
+	}
+
+	switch rule.Type {
+	case models.SurchargeTypeNight:
+		return true
+	case models.SurchargeTypeSunday:
+		return local.Weekday() == time.Sunday
+	case models.SurchargeTypeHoliday:
+		return c.holidays != nil && c.holidays.IsHoliday(local)
+	case models.SurchargeTypeSpecialDay:
+		return int(local.Month()) == rule.Month && local.Day() == rule.Day
+	}
+	return false
+}
+
+// inWindow prüft, ob die Minute des Tages im Zeitfenster liegt; Ende <= Beginn bedeutet über Mitternacht
+func inWindow(minute, start, end int) bool {
+	if end > start {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// roundTo rundet auf die angegebene Anzahl Nachkommastellen
+func roundTo(value float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(value*factor) / factor
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// surchargeMinutes liefert die Minuten je Regelname
+func surchargeMinutes(result models.ShiftSurcharge) map[string]float64 {
+	minutes := make(map[string]float64)
+	for _, line := range result.Lines {
+		minutes[line.Name] = line.Minutes
+	}
+	return minutes
+}
+
+func TestValidateSurchargeRule(t *testing.T) {
+	for _, rule := range EStG3bSurchargeRules() {
+		assert.NoError(t, ValidateSurchargeRule(rule), rule.Name)
+	}
+
+	assert.Error(t, ValidateSurchargeRule(models.SurchargeRule{Type: models.SurchargeTypeSunday}))
+	assert.Error(t, ValidateSurchargeRule(models.SurchargeRule{Type: models.SurchargeTypeNight, Percentage: 25}))
+	assert.Error(t, ValidateSurchargeRule(models.SurchargeRule{Type: models.SurchargeTypeSpecialDay, Percentage: 50, Month: 2, Day: 30}))
+	assert.Error(t, ValidateSurchargeRule(models.SurchargeRule{Type: models.SurchargeTypeSunday, Percentage: 50, WindowStart: models.NewWallClock(6, 0)}))
+	assert.Error(t, ValidateSurchargeRule(models.SurchargeRule{Type: "overtime", Percentage: 50}))
+	assert.NoError(t, ValidateSurchargeRule(models.SurchargeRule{Type: models.SurchargeTypeSpecialDay, Percentage: 50, Month: 2, Day: 29}))
+}
+
+func TestSurchargeCalculator_NightIntoSunday(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	calculator := NewSurchargeCalculator(EStG3bSurchargeRules(), nil)
+
+	// Samstag 22:00 bis Sonntag 06:00
+	shift := models.Shift{
+		Base:      models.Base{ID: 1},
+		UserID:    2,
+		StartTime: time.Date(2024, 6, 1, 22, 0, 0, 0, berlin),
+		EndTime:   time.Date(2024, 6, 2, 6, 0, 0, 0, berlin),
+	}
+
+	result := calculator.Calculate(shift, berlin)
+	minutes := surchargeMinutes(result)
+	assert.Equal(t, 480.0, result.WorkedMinutes)
+	assert.Equal(t, 240.0, minutes["Nachtarbeit"])             // 22–24 und 4–6 Uhr
+	assert.Equal(t, 240.0, minutes["Nachtarbeit 0 bis 4 Uhr"]) // Beginn vor Mitternacht
+	assert.Equal(t, 360.0, minutes["Sonntagsarbeit"])          // 0–6 Uhr am Sonntag
+}
+
+func TestSurchargeCalculator_NightStartedAfterMidnight(t *testing.T) {
+	calculator := NewSurchargeCalculator(EStG3bSurchargeRules(), nil)
+
+	// Mittwoch 00:00 bis 06:00: kein erhöhter Satz, da nicht vor Mitternacht begonnen
+	shift := models.Shift{StartTime: time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 6, 5, 6, 0, 0, 0, time.UTC)}
+	minutes := surchargeMinutes(calculator.Calculate(shift, time.UTC))
+	assert.Equal(t, 360.0, minutes["Nachtarbeit"])
+	assert.NotContains(t, minutes, "Nachtarbeit 0 bis 4 Uhr")
+}
+
+func TestSurchargeCalculator_BreakIsDeductedProportionally(t *testing.T) {
+	calculator := NewSurchargeCalculator(EStG3bSurchargeRules(), nil)
+
+	// Sonntag 14:00 bis 22:00 mit 60 Minuten Pause
+	shift := models.Shift{
+		StartTime: time.Date(2024, 6, 2, 14, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2024, 6, 2, 22, 0, 0, 0, time.UTC),
+		BreakTime: 60,
+	}
+
+	result := calculator.Calculate(shift, time.UTC)
+	minutes := surchargeMinutes(result)
+	assert.Equal(t, 420.0, result.WorkedMinutes)
+	assert.Equal(t, 420.0, minutes["Sonntagsarbeit"])
+	assert.Equal(t, 105.0, minutes["Nachtarbeit"]) // 2 von 8 Stunden, abzüglich anteiliger Pause
+}
+
+func TestSurchargeCalculator_HolidaysAndSpecialDays(t *testing.T) {
+	calendar, _ := NewHolidayCalendar("", nil)
+	calculator := NewSurchargeCalculator(EStG3bSurchargeRules(), calendar)
+
+	// Tag der Deutschen Einheit
+	unity := models.Shift{StartTime: time.Date(2024, 10, 3, 8, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 10, 3, 16, 0, 0, 0, time.UTC)}
+	assert.Equal(t, map[string]float64{"Feiertagsarbeit": 480}, surchargeMinutes(calculator.Calculate(unity, time.UTC)))
+
+	// 1. Weihnachtstag: der höhere Sondersatz ersetzt den Feiertagssatz
+	christmas := models.Shift{StartTime: time.Date(2024, 12, 25, 8, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 12, 25, 16, 0, 0, 0, time.UTC)}
+	result := calculator.Calculate(christmas, time.UTC)
+	assert.Equal(t, map[string]float64{"1. Weihnachtstag": 480}, surchargeMinutes(result))
+	assert.Equal(t, 150.0, result.Lines[0].Percentage)
+	assert.Equal(t, 8.0, result.Lines[0].Hours)
+
+	// Heiligabend erst ab 14 Uhr
+	eve := models.Shift{StartTime: time.Date(2024, 12, 24, 12, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 12, 24, 20, 0, 0, 0, time.UTC)}
+	assert.Equal(t, map[string]float64{"Heiligabend ab 14 Uhr": 360}, surchargeMinutes(calculator.Calculate(eve, time.UTC)))
+}
+
+func TestSurchargeCalculator_DaylightSavingTime(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	calculator := NewSurchargeCalculator(EStG3bSurchargeRules(), nil)
+
+	// Nacht der Zeitumstellung: 22:00 bis 06:00 Ortszeit sind nur 7 Stunden
+	shift := models.Shift{StartTime: time.Date(2024, 3, 30, 22, 0, 0, 0, berlin), EndTime: time.Date(2024, 3, 31, 6, 0, 0, 0, berlin)}
+	result := calculator.Calculate(shift, berlin)
+	minutes := surchargeMinutes(result)
+	assert.Equal(t, 420.0, result.WorkedMinutes)
+	assert.Equal(t, 240.0, minutes["Nachtarbeit"])
+	assert.Equal(t, 180.0, minutes["Nachtarbeit 0 bis 4 Uhr"])
+	assert.Equal(t, 300.0, minutes["Sonntagsarbeit"])
+}
+
+func TestSurchargeCalculator_InactiveRules(t *testing.T) {
+	rules := EStG3bSurchargeRules()
+	for i := range rules {
+		rules[i].IsActive = rules[i].Type != models.SurchargeTypeSunday
+	}
+	calculator := NewSurchargeCalculator(rules, nil)
+
+	shift := models.Shift{StartTime: time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 6, 2, 16, 0, 0, 0, time.UTC)}
+	assert.Empty(t, calculator.Calculate(shift, time.UTC).Lines)
+}
+
+func TestSummarizeSurcharges(t *testing.T) {
+	calculator := NewSurchargeCalculator(EStG3bSurchargeRules(), nil)
+
+	first := calculator.Calculate(models.Shift{StartTime: time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 6, 2, 16, 0, 0, 0, time.UTC)}, time.UTC)
+	second := calculator.Calculate(models.Shift{StartTime: time.Date(2024, 6, 9, 18, 0, 0, 0, time.UTC), EndTime: time.Date(2024, 6, 9, 22, 0, 0, 0, time.UTC)}, time.UTC)
+
+	worked, lines := SummarizeSurcharges([]models.ShiftSurcharge{first, second})
+	assert.Equal(t, 720.0, worked)
+
+	minutes := make(map[string]float64)
+	for _, line := range lines {
+		minutes[line.Name] = line.Minutes
+	}
+	assert.Equal(t, 720.0, minutes["Sonntagsarbeit"])
+	assert.Equal(t, 120.0, minutes["Nachtarbeit"])
+}
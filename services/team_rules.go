@@ -0,0 +1,232 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// TeamRuleEvaluator wertet teamspezifische Planungsregeln für die Schichtfolge eines Benutzers aus
+type TeamRuleEvaluator struct {
+	location *time.Location
+}
+
+// NewTeamRuleEvaluator erstellt einen Evaluator, der Kalendertage in der angegebenen Zeitzone bildet
+func NewTeamRuleEvaluator(loc *time.Location) *TeamRuleEvaluator {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &TeamRuleEvaluator{location: loc}
+}
+
+// ValidateTeamRule prüft Typ, Verbindlichkeit und Parameter einer Regel
+func ValidateTeamRule(rule models.TeamRule) error {
+	if rule.Severity != "" && rule.Severity != models.TeamRuleSeverityHard && rule.Severity != models.TeamRuleSeveritySoft {
+		return fmt.Errorf("Verbindlichkeit muss hard oder soft sein")
+	}
+
+	switch rule.Type {
+	case models.TeamRuleMaxConsecutiveDays, models.TeamRuleMaxConsecutiveNights:
+		if rule.Parameters.Max < 1 {
+			return fmt.Errorf("Parameter max muss größer als 0 sein")
+		}
+	case models.TeamRuleForbiddenSequence:
+		if rule.Parameters.FromShiftTypeID == 0 || rule.Parameters.ToShiftTypeID == 0 {
+			return fmt.Errorf("Parameter from_shift_type_id und to_shift_type_id sind Pflichtfelder")
+		}
+	case models.TeamRuleMinFreeWeekends:
+		if rule.Parameters.Min < 1 || rule.Parameters.Min > 5 {
+			return fmt.Errorf("Parameter min muss zwischen 1 und 5 liegen")
+		}
+	default:
+		return fmt.Errorf("unbekannter Regeltyp: %s", rule.Type)
+	}
+
+	return nil
+}
+
+// Evaluate wertet alle aktiven Regeln für die Schichten eines Benutzers im Zeitraum [from, to] aus
+func (e *TeamRuleEvaluator) Evaluate(rules []models.TeamRule, userID uint, shifts []models.Shift, from, to time.Time) []models.ComplianceViolation {
+	sorted := make([]models.Shift, len(shifts))
+	copy(sorted, shifts)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].StartTime.Before(sorted[j].StartTime) })
+
+	violations := make([]models.ComplianceViolation, 0)
+	for _, rule := range rules {
+		if !rule.IsActive {
+			continue
+		}
+
+		var found []models.ComplianceViolation
+		switch rule.Type {
+		case models.TeamRuleMaxConsecutiveDays:
+			found = e.checkConsecutive(rule, sorted, func(models.Shift) bool { return true }, "Arbeitstage")
+		case models.TeamRuleMaxConsecutiveNights:
+			found = e.checkConsecutive(rule, sorted, func(shift models.Shift) bool { return e.isNightShift(rule, shift) }, "Nachtschichten")
+		case models.TeamRuleForbiddenSequence:
+			found = e.checkForbiddenSequence(rule, sorted)
+		case models.TeamRuleMinFreeWeekends:
+			found = e.checkFreeWeekends(rule, sorted, from, to)
+		}
+
+		for _, violation := range found {
+			violation.Rule = rule.Type
+			violation.RuleID = rule.ID
+			violation.Severity = rule.ViolationSeverity()
+			violation.UserID = userID
+			violations = append(violations, violation)
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Date.Before(violations[j].Date) })
+	return violations
+}
+
+// checkConsecutive meldet Folgen von mehr als Max aufeinanderfolgenden Kalendertagen mit passenden Schichten
+func (e *TeamRuleEvaluator) checkConsecutive(rule models.TeamRule, shifts []models.Shift, matches func(models.Shift) bool, label string) []models.ComplianceViolation {
+	var violations []models.ComplianceViolation
+
+	var previousDay time.Time
+	run := 0
+	for _, shift := range shifts {
+		if !matches(shift) {
+			continue
+		}
+
+		day := startOfDay(shift.StartTime, e.location)
+		switch {
+		case day.Equal(previousDay):
+			continue
+		case !previousDay.IsZero() && day.Equal(previousDay.AddDate(0, 0, 1)):
+			run++
+		default:
+			run = 1
+		}
+		previousDay = day
+
+		// Nur der erste Tag über der Grenze wird gemeldet
+		if run == rule.Parameters.Max+1 {
+			violations = append(violations, models.ComplianceViolation{
+				ShiftID: shift.ID,
+				Date:    day,
+				Message: fmt.Sprintf("%s: mehr als %d %s in Folge", rule.Name, rule.Parameters.Max, label),
+			})
+		}
+	}
+
+	return violations
+}
+
+// checkForbiddenSequence meldet Schichten vom Typ To am Tag nach einer Schicht vom Typ From
+func (e *TeamRuleEvaluator) checkForbiddenSequence(rule models.TeamRule, shifts []models.Shift) []models.ComplianceViolation {
+	var violations []models.ComplianceViolation
+
+	for i := 1; i < len(shifts); i++ {
+		previous, current := shifts[i-1], shifts[i]
+		if previous.ShiftTypeID == nil || current.ShiftTypeID == nil {
+			continue
+		}
+		if *previous.ShiftTypeID != rule.Parameters.FromShiftTypeID || *current.ShiftTypeID != rule.Parameters.ToShiftTypeID {
+			continue
+		}
+
+		previousDay := startOfDay(previous.StartTime, e.location)
+		currentDay := startOfDay(current.StartTime, e.location)
+		if currentDay.After(previousDay.AddDate(0, 0, 1)) {
+			continue
+		}
+
+		violations = append(violations, models.ComplianceViolation{
+			ShiftID: current.ID,
+			Date:    currentDay,
+			Message: fmt.Sprintf("%s: unzulässige Schichtfolge am %s", rule.Name, currentDay.Format("02.01.2006")),
+		})
+	}
+
+	return violations
+}
+
+// checkFreeWeekends zählt freie Wochenenden je Kalendermonat; geprüft werden nur Monate, die vollständig im Zeitraum liegen.
+// Ein Wochenende gehört zu dem Monat, in dem sein Samstag liegt.
+func (e *TeamRuleEvaluator) checkFreeWeekends(rule models.TeamRule, shifts []models.Shift, from, to time.Time) []models.ComplianceViolation {
+	var violations []models.ComplianceViolation
+
+	// Vergleich auf Kalendertagen, damit ein Zeitraum ab Mitternacht UTC den Monat in Ortszeit einschließt
+	fromDay := startOfDay(from, e.location)
+	month := time.Date(fromDay.Year(), fromDay.Month(), 1, 0, 0, 0, 0, e.location)
+	if month.Before(fromDay) {
+		month = month.AddDate(0, 1, 0)
+	}
+
+	for ; !month.AddDate(0, 1, 0).Add(-time.Second).After(to); month = month.AddDate(0, 1, 0) {
+		free := 0
+		for day := month; day.Month() == month.Month(); day = day.AddDate(0, 0, 1) {
+			if day.Weekday() != time.Saturday {
+				continue
+			}
+			if !hasShiftBetween(shifts, day, day.AddDate(0, 0, 2)) {
+				free++
+			}
+		}
+
+		if free < rule.Parameters.Min {
+			violations = append(violations, models.ComplianceViolation{
+				Date: month,
+				Message: fmt.Sprintf("%s: nur %d von mindestens %d freien Wochenenden im %s",
+					rule.Name, free, rule.Parameters.Min, month.Format("01/2006")),
+			})
+		}
+	}
+
+	return violations
+}
+
+// isNightShift erkennt Nachtschichten über die konfigurierten Schichttypen oder nach § 2 ArbZG
+// (mehr als zwei Stunden zwischen 23 und 6 Uhr)
+func (e *TeamRuleEvaluator) isNightShift(rule models.TeamRule, shift models.Shift) bool {
+	if len(rule.Parameters.ShiftTypeIDs) > 0 {
+		if shift.ShiftTypeID == nil {
+			return false
+		}
+		for _, id := range rule.Parameters.ShiftTypeIDs {
+			if id == *shift.ShiftTypeID {
+				return true
+			}
+		}
+		return false
+	}
+
+	var night time.Duration
+	for day := startOfDay(shift.StartTime, e.location).AddDate(0, 0, -1); day.Before(shift.EndTime); day = day.AddDate(0, 0, 1) {
+		nightStart := time.Date(day.Year(), day.Month(), day.Day(), 23, 0, 0, 0, e.location)
+		nightEnd := time.Date(day.Year(), day.Month(), day.Day()+1, 6, 0, 0, 0, e.location)
+		night += overlap(shift.StartTime, shift.EndTime, nightStart, nightEnd)
+	}
+	return night > 2*time.Hour
+}
+
+// hasShiftBetween prüft, ob eine Schicht den Zeitraum [from, to) berührt
+func hasShiftBetween(shifts []models.Shift, from, to time.Time) bool {
+	for _, shift := range shifts {
+		if shift.StartTime.Before(to) && shift.EndTime.After(from) {
+			return true
+		}
+	}
+	return false
+}
+
+// overlap liefert die Überschneidung zweier Zeiträume
+func overlap(start, end, otherStart, otherEnd time.Time) time.Duration {
+	if otherStart.After(start) {
+		start = otherStart
+	}
+	if otherEnd.Before(end) {
+		end = otherEnd
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// typedShift erstellt eine Schicht mit Schichttyp für die Regelprüfung
+func typedShift(id uint, shiftTypeID uint, start time.Time, hours float64) models.Shift {
+	shift := complianceShift(id, start, hours, 30)
+	shift.ShiftTypeID = &shiftTypeID
+	return shift
+}
+
+func TestValidateTeamRule(t *testing.T) {
+	valid := models.TeamRule{Type: models.TeamRuleMaxConsecutiveDays, Parameters: models.TeamRuleParameters{Max: 5}}
+	assert.NoError(t, ValidateTeamRule(valid))
+
+	assert.Error(t, ValidateTeamRule(models.TeamRule{Type: "unknown"}))
+	assert.Error(t, ValidateTeamRule(models.TeamRule{Type: models.TeamRuleMaxConsecutiveNights}))
+	assert.Error(t, ValidateTeamRule(models.TeamRule{Type: models.TeamRuleForbiddenSequence, Parameters: models.TeamRuleParameters{FromShiftTypeID: 1}}))
+	assert.Error(t, ValidateTeamRule(models.TeamRule{Type: models.TeamRuleMinFreeWeekends, Parameters: models.TeamRuleParameters{Min: 6}}))
+
+	invalidSeverity := valid
+	invalidSeverity.Severity = "medium"
+	assert.Error(t, ValidateTeamRule(invalidSeverity))
+}
+
+func TestTeamRuleEvaluator_MaxConsecutiveDays(t *testing.T) {
+	evaluator := NewTeamRuleEvaluator(time.UTC)
+	rule := models.TeamRule{
+		Base: models.Base{ID: 7}, Name: "Max. 5 Tage", Type: models.TeamRuleMaxConsecutiveDays,
+		Severity: models.TeamRuleSeverityHard, IsActive: true, Parameters: models.TeamRuleParameters{Max: 5},
+	}
+
+	var shifts []models.Shift
+	for day := 0; day < 7; day++ {
+		shifts = append(shifts, complianceShift(uint(day+1), time.Date(2024, 1, 8+day, 6, 0, 0, 0, time.UTC), 8, 30))
+	}
+
+	violations := evaluator.Evaluate([]models.TeamRule{rule}, 1, shifts, shifts[0].StartTime, shifts[6].EndTime)
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, uint(6), violations[0].ShiftID)
+		assert.Equal(t, uint(7), violations[0].RuleID)
+		assert.Equal(t, models.SeverityError, violations[0].Severity)
+		assert.Equal(t, models.TeamRuleMaxConsecutiveDays, violations[0].Rule)
+	}
+
+	// Ein freier Tag unterbricht die Folge
+	withGap := append([]models.Shift{}, shifts[:4]...)
+	withGap = append(withGap, shifts[5:]...)
+	assert.Empty(t, evaluator.Evaluate([]models.TeamRule{rule}, 1, withGap, shifts[0].StartTime, shifts[6].EndTime))
+}
+
+func TestTeamRuleEvaluator_MaxConsecutiveNights(t *testing.T) {
+	evaluator := NewTeamRuleEvaluator(time.UTC)
+	rule := models.TeamRule{
+		Name: "Max. 2 Nächte", Type: models.TeamRuleMaxConsecutiveNights,
+		Severity: models.TeamRuleSeveritySoft, IsActive: true, Parameters: models.TeamRuleParameters{Max: 2},
+	}
+
+	var shifts []models.Shift
+	for day := 0; day < 3; day++ {
+		shifts = append(shifts, complianceShift(uint(day+1), time.Date(2024, 1, 8+day, 22, 0, 0, 0, time.UTC), 8, 30))
+	}
+	from, to := shifts[0].StartTime, shifts[2].EndTime
+
+	violations := evaluator.Evaluate([]models.TeamRule{rule}, 1, shifts, from, to)
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, uint(3), violations[0].ShiftID)
+		assert.Equal(t, models.SeverityWarning, violations[0].Severity)
+	}
+
+	// Frühschichten zählen nicht als Nachtschichten
+	early := []models.Shift{shifts[0], shifts[1], complianceShift(3, time.Date(2024, 1, 10, 6, 0, 0, 0, time.UTC), 8, 30)}
+	assert.Empty(t, evaluator.Evaluate([]models.TeamRule{rule}, 1, early, from, to))
+
+	// Mit konfigurierten Schichttypen entscheidet nur der Typ
+	rule.Parameters.ShiftTypeIDs = []uint{9}
+	assert.Empty(t, evaluator.Evaluate([]models.TeamRule{rule}, 1, shifts, from, to))
+}
+
+func TestTeamRuleEvaluator_ForbiddenSequence(t *testing.T) {
+	evaluator := NewTeamRuleEvaluator(time.UTC)
+	rule := models.TeamRule{
+		Name: "Keine Früh nach Nacht", Type: models.TeamRuleForbiddenSequence,
+		Severity: models.TeamRuleSeverityHard, IsActive: true,
+		Parameters: models.TeamRuleParameters{FromShiftTypeID: 3, ToShiftTypeID: 1},
+	}
+
+	night := typedShift(1, 3, time.Date(2024, 1, 8, 22, 0, 0, 0, time.UTC), 8)
+	early := typedShift(2, 1, time.Date(2024, 1, 9, 14, 0, 0, 0, time.UTC), 8)
+	violations := evaluator.Evaluate([]models.TeamRule{rule}, 1, []models.Shift{night, early}, night.StartTime, early.EndTime)
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, uint(2), violations[0].ShiftID)
+	}
+
+	// Mit einem freien Tag dazwischen ist die Folge erlaubt
+	later := typedShift(2, 1, time.Date(2024, 1, 10, 14, 0, 0, 0, time.UTC), 8)
+	assert.Empty(t, evaluator.Evaluate([]models.TeamRule{rule}, 1, []models.Shift{night, later}, night.StartTime, later.EndTime))
+}
+
+func TestTeamRuleEvaluator_MinFreeWeekends(t *testing.T) {
+	evaluator := NewTeamRuleEvaluator(time.UTC)
+	rule := models.TeamRule{
+		Name: "Zwei freie Wochenenden", Type: models.TeamRuleMinFreeWeekends,
+		Severity: models.TeamRuleSeveritySoft, IsActive: true, Parameters: models.TeamRuleParameters{Min: 2},
+	}
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	// Januar 2024 hat vier Wochenenden (Samstage 6., 13., 20., 27.), drei davon mit Schichten
+	shifts := []models.Shift{
+		complianceShift(1, time.Date(2024, 1, 6, 6, 0, 0, 0, time.UTC), 8, 30),
+		complianceShift(2, time.Date(2024, 1, 14, 6, 0, 0, 0, time.UTC), 8, 30),
+		complianceShift(3, time.Date(2024, 1, 20, 6, 0, 0, 0, time.UTC), 8, 30),
+	}
+	violations := evaluator.Evaluate([]models.TeamRule{rule}, 1, shifts, from, to)
+	if assert.Len(t, violations, 1) {
+		assert.Zero(t, violations[0].ShiftID)
+		assert.Equal(t, from, violations[0].Date)
+	}
+
+	assert.Empty(t, evaluator.Evaluate([]models.TeamRule{rule}, 1, shifts[:2], from, to))
+
+	// Unvollständige Monate werden nicht bewertet
+	assert.Empty(t, evaluator.Evaluate([]models.TeamRule{rule}, 1, shifts, from.AddDate(0, 0, 1), to))
+}
+
+func TestTeamRuleEvaluator_SkipsInactiveRules(t *testing.T) {
+	evaluator := NewTeamRuleEvaluator(nil)
+	rule := models.TeamRule{Type: models.TeamRuleMaxConsecutiveDays, Parameters: models.TeamRuleParameters{Max: 1}}
+
+	shifts := []models.Shift{
+		complianceShift(1, time.Date(2024, 1, 8, 6, 0, 0, 0, time.UTC), 8, 30),
+		complianceShift(2, time.Date(2024, 1, 9, 6, 0, 0, 0, time.UTC), 8, 30),
+	}
+	assert.Empty(t, evaluator.Evaluate([]models.TeamRule{rule}, 1, shifts, shifts[0].StartTime, shifts[1].EndTime))
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// DailyTargetFunc liefert die Sollstunden eines Kalendertags (00:00 Uhr UTC) ohne Berücksichtigung von Feiertagen
+type DailyTargetFunc func(day time.Time) float64
+
+// WeeklyHoursTarget verteilt die Wochenarbeitszeit gleichmäßig auf Montag bis Freitag
+func WeeklyHoursTarget(weeklyHours float64) DailyTargetFunc {
+	return func(day time.Time) float64 {
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			return 0
+		}
+		return weeklyHours / 5
+	}
+}
+
+// DefaultAbsenceCreditRules liefert die Anrechnung von Abwesenheiten, solange keine eigenen Regeln gespeichert sind
+func DefaultAbsenceCreditRules() []models.AbsenceCreditRule {
+	return []models.AbsenceCreditRule{
+		{AbsenceType: models.AbsenceTypeVacation, Mode: models.AbsenceCreditTarget},
+		{AbsenceType: models.AbsenceTypeSick, Mode: models.AbsenceCreditTarget},
+		{AbsenceType: models.AbsenceTypeTraining, Mode: models.AbsenceCreditTarget},
+		{AbsenceType: models.AbsenceTypeSpecialLeave, Mode: models.AbsenceCreditTarget},
+		{AbsenceType: models.AbsenceTypeUnpaid, Mode: models.AbsenceCreditReduceTarget},
+		{AbsenceType: models.AbsenceTypeCompensatory, Mode: models.AbsenceCreditNone},
+	}
+}
+
+// ValidateAbsenceCreditRule prüft eine Anrechnungsregel
+func ValidateAbsenceCreditRule(rule models.AbsenceCreditRule) error {
+	if !models.IsValidAbsenceType(rule.AbsenceType) {
+		return fmt.Errorf("unbekannte Abwesenheitsart: %s", rule.AbsenceType)
+	}
+
+	switch rule.Mode {
+	case models.AbsenceCreditTarget, models.AbsenceCreditNone, models.AbsenceCreditReduceTarget:
+	case models.AbsenceCreditFixed:
+		if rule.Hours <= 0 || rule.Hours > 24 {
+			return fmt.Errorf("Stunden je Arbeitstag müssen zwischen 0 und 24 liegen")
+		}
+	default:
+		return fmt.Errorf("unbekannte Anrechnungsart: %s", rule.Mode)
+	}
+	return nil
+}
+
+// TimeAccountCalculator berechnet das Arbeitszeitkonto eines Benutzers aus Soll, Schichten, Abwesenheiten und Korrekturen
+type TimeAccountCalculator struct {
+	location *time.Location
+	target   DailyTargetFunc
+	holidays *HolidayCalendar
+	credits  map[string]models.AbsenceCreditRule
+	now      time.Time
+}
+
+// NewTimeAccountCalculator erstellt einen Rechner; Schichten werden dem Kalendertag ihres Beginns in loc zugeordnet.
+// Als Ist zählen Schichten, die vor now geendet haben; holidays ist optional.
+func NewTimeAccountCalculator(loc *time.Location, target DailyTargetFunc, holidays *HolidayCalendar, rules []models.AbsenceCreditRule, now time.Time) *TimeAccountCalculator {
+	credits := make(map[string]models.AbsenceCreditRule)
+	for _, rule := range DefaultAbsenceCreditRules() {
+		credits[rule.AbsenceType] = rule
+	}
+	for _, rule := range rules {
+		credits[rule.AbsenceType] = rule
+	}
+	return &TimeAccountCalculator{location: loc, target: target, holidays: holidays, credits: credits, now: now}
+}
+
+// Days berechnet die Kontozeilen für die Kalendertage [from, to); from und to sind Kalendertage (00:00 Uhr UTC).
+// Es werden nur genehmigte Abwesenheiten angerechnet. Einsätze während einer Rufbereitschaft (callOuts) zählen mit
+// ihrer Arbeitszeit am Tag des Kommens, sobald sie abgeschlossen sind.
+func (c *TimeAccountCalculator) Days(from, to time.Time, shifts []models.Shift, callOuts []models.TimeEntry, absences []models.Absence, corrections []models.TimeAccountCorrection) []models.TimeAccountDay {
+	planned := make(map[time.Time]float64)
+	actual := make(map[time.Time]float64)
+	for _, shift := range shifts {
+		local := shift.StartTime.In(c.location)
+		key := date(local.Year(), local.Month(), local.Day())
+		hours := netShiftHours(shift)
+		planned[key] += hours
+		if !shift.EndTime.After(c.now) {
+			actual[key] += hours
+		}
+	}
+
+	called := make(map[time.Time]float64)
+	for _, callOut := range callOuts {
+		if callOut.ClockOut == nil || callOut.ClockOut.After(c.now) {
+			continue
+		}
+		local := callOut.ClockIn.In(c.location)
+		called[date(local.Year(), local.Month(), local.Day())] += callOut.WorkedDuration(c.now).Hours()
+	}
+
+	corrected := make(map[time.Time]float64)
+	for _, correction := range corrections {
+		corrected[correction.Date] += correction.Hours
+	}
+
+	days := make([]models.TimeAccountDay, 0)
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		entry := models.TimeAccountDay{
+			Date:            day,
+			TargetHours:     c.target(day),
+			PlannedHours:    roundTo(planned[day], 2),
+			ActualHours:     roundTo(actual[day], 2),
+			CallOutHours:    roundTo(called[day], 2),
+			CorrectionHours: roundTo(corrected[day], 2),
+		}
+
+		// Feiertage mindern die Sollzeit
+		if c.holidays != nil {
+			if holiday, ok := c.holidays.HolidayOn(day); ok {
+				entry.Holiday = holiday.Name
+				entry.TargetHours = 0
+			}
+		}
+
+		if absence, ok := approvedAbsenceOn(absences, day); ok {
+			entry.AbsenceType = absence.Type
+			rule := c.credits[absence.Type]
+			switch rule.Mode {
+			case models.AbsenceCreditTarget:
+				entry.CreditHours = entry.TargetHours
+			case models.AbsenceCreditFixed:
+				if entry.TargetHours > 0 {
+					entry.CreditHours = rule.Hours
+				}
+			case models.AbsenceCreditReduceTarget:
+				entry.TargetHours = 0
+			}
+		}
+
+		entry.TargetHours = roundTo(entry.TargetHours, 2)
+		entry.CreditHours = roundTo(entry.CreditHours, 2)
+		days = append(days, entry)
+	}
+	return days
+}
+
+// Months berechnet die Monatsabschlüsse für die Monate von from bis einschließlich to (jeweils Monatsbeginn, 00:00 Uhr UTC).
+// Der Kontostand wird ab opening fortgeschrieben; withDays liefert zusätzlich die Tageszeilen.
+func (c *TimeAccountCalculator) Months(from, to time.Time, opening float64, shifts []models.Shift, callOuts []models.TimeEntry, absences []models.Absence, corrections []models.TimeAccountCorrection, withDays bool) []models.TimeAccountMonth {
+	months := make([]models.TimeAccountMonth, 0)
+	balance := opening
+	for month := date(from.Year(), from.Month(), 1); !month.After(to); month = month.AddDate(0, 1, 0) {
+		days := c.Days(month, month.AddDate(0, 1, 0), shifts, callOuts, absences, corrections)
+
+		summary := models.TimeAccountMonth{Month: month.Format("2006-01"), OpeningBalance: roundTo(balance, 2)}
+		for _, day := range days {
+			summary.TargetHours += day.TargetHours
+			summary.PlannedHours += day.PlannedHours
+			summary.ActualHours += day.ActualHours
+			summary.CallOutHours += day.CallOutHours
+			summary.AbsenceCreditHours += day.CreditHours
+			summary.CorrectionHours += day.CorrectionHours
+		}
+		summary.TargetHours = roundTo(summary.TargetHours, 2)
+		summary.PlannedHours = roundTo(summary.PlannedHours, 2)
+		summary.ActualHours = roundTo(summary.ActualHours, 2)
+		summary.CallOutHours = roundTo(summary.CallOutHours, 2)
+		summary.AbsenceCreditHours = roundTo(summary.AbsenceCreditHours, 2)
+		summary.CorrectionHours = roundTo(summary.CorrectionHours, 2)
+		summary.Balance = roundTo(summary.ActualHours+summary.CallOutHours+summary.AbsenceCreditHours+summary.CorrectionHours-summary.TargetHours, 2)
+
+		balance += summary.Balance
+		summary.ClosingBalance = roundTo(balance, 2)
+		if withDays {
+			summary.Days = days
+		}
+		months = append(months, summary)
+	}
+	return months
+}
+
+// SummarizeTimeAccount fasst Monatsabschlüsse zu einer Zeitraumübersicht zusammen
+func SummarizeTimeAccount(months []models.TimeAccountMonth) models.TimeAccountSummary {
+	var summary models.TimeAccountSummary
+	for i, month := range months {
+		if i == 0 {
+			summary.From = month.Month
+		}
+		summary.To = month.Month
+		summary.TargetHours += month.TargetHours
+		summary.PlannedHours += month.PlannedHours
+		summary.ActualHours += month.ActualHours
+		summary.CallOutHours += month.CallOutHours
+		summary.AbsenceCreditHours += month.AbsenceCreditHours
+		summary.CorrectionHours += month.CorrectionHours
+		summary.Balance += month.Balance
+		summary.ClosingBalance = month.ClosingBalance
+	}
+
+	summary.TargetHours = roundTo(summary.TargetHours, 2)
+	summary.PlannedHours = roundTo(summary.PlannedHours, 2)
+	summary.ActualHours = roundTo(summary.ActualHours, 2)
+	summary.CallOutHours = roundTo(summary.CallOutHours, 2)
+	summary.AbsenceCreditHours = roundTo(summary.AbsenceCreditHours, 2)
+	summary.CorrectionHours = roundTo(summary.CorrectionHours, 2)
+	summary.Balance = roundTo(summary.Balance, 2)
+	return summary
+}
+
+// approvedAbsenceOn liefert die genehmigte Abwesenheit am Kalendertag day
+func approvedAbsenceOn(absences []models.Absence, day time.Time) (models.Absence, bool) {
+	for _, absence := range absences {
+		if absence.Status == models.AbsenceStatusApproved && absence.Covers(day) {
+			return absence, true
+		}
+	}
+	return models.Absence{}, false
+}
+
+// netShiftHours liefert die Arbeitszeit einer Schicht abzüglich Pause in Stunden
+func netShiftHours(shift models.Shift) float64 {
+	net := shift.EndTime.Sub(shift.StartTime) - time.Duration(shift.BreakTime)*time.Minute
+	if net < 0 {
+		return 0
+	}
+	return net.Hours()
+}
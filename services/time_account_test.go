@@ -0,0 +1,130 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// accountShift erstellt eine Schicht mit 30 Minuten Pause für das Arbeitszeitkonto
+func accountShift(start time.Time, hours float64) models.Shift {
+	return models.Shift{StartTime: start, EndTime: start.Add(time.Duration(hours * float64(time.Hour))), BreakTime: 30}
+}
+
+func TestWeeklyHoursTarget(t *testing.T) {
+	target := WeeklyHoursTarget(38.5)
+	assert.Equal(t, 7.7, target(date(2024, time.June, 3)))
+	assert.Equal(t, 0.0, target(date(2024, time.June, 8)))
+	assert.Equal(t, 0.0, target(date(2024, time.June, 9)))
+}
+
+func TestValidateAbsenceCreditRule(t *testing.T) {
+	for _, rule := range DefaultAbsenceCreditRules() {
+		assert.NoError(t, ValidateAbsenceCreditRule(rule))
+	}
+	assert.NoError(t, ValidateAbsenceCreditRule(models.AbsenceCreditRule{AbsenceType: models.AbsenceTypeSick, Mode: models.AbsenceCreditFixed, Hours: 6}))
+	assert.Error(t, ValidateAbsenceCreditRule(models.AbsenceCreditRule{AbsenceType: models.AbsenceTypeSick, Mode: models.AbsenceCreditFixed}))
+	assert.Error(t, ValidateAbsenceCreditRule(models.AbsenceCreditRule{AbsenceType: "holiday", Mode: models.AbsenceCreditTarget}))
+	assert.Error(t, ValidateAbsenceCreditRule(models.AbsenceCreditRule{AbsenceType: models.AbsenceTypeSick, Mode: "double"}))
+}
+
+func TestTimeAccountCalculator_TargetWithHolidays(t *testing.T) {
+	calendar, _ := NewHolidayCalendar(models.StateBY, nil)
+	calculator := NewTimeAccountCalculator(time.UTC, WeeklyHoursTarget(40), calendar, nil, date(2024, time.July, 1))
+
+	// Mai 2024: 23 Werktage, davon 4 Feiertage in Bayern
+	months := calculator.Months(date(2024, time.May, 1), date(2024, time.May, 1), 0, nil, nil, nil, nil, true)
+	assert.Len(t, months, 1)
+	assert.Equal(t, "2024-05", months[0].Month)
+	assert.Equal(t, 152.0, months[0].TargetHours)
+	assert.Equal(t, -152.0, months[0].Balance)
+	assert.Len(t, months[0].Days, 31)
+	assert.Equal(t, "Fronleichnam", months[0].Days[29].Holiday)
+}
+
+func TestTimeAccountCalculator_ShiftsAndAbsences(t *testing.T) {
+	now := time.Date(2024, 6, 12, 12, 0, 0, 0, time.UTC)
+	calculator := NewTimeAccountCalculator(time.UTC, WeeklyHoursTarget(40), nil, []models.AbsenceCreditRule{
+		{AbsenceType: models.AbsenceTypeSick, Mode: models.AbsenceCreditFixed, Hours: 6},
+	}, now)
+
+	shifts := []models.Shift{
+		accountShift(time.Date(2024, 6, 10, 6, 0, 0, 0, time.UTC), 9.5), // 9 Stunden netto
+		accountShift(time.Date(2024, 6, 11, 6, 0, 0, 0, time.UTC), 9.5),
+		accountShift(time.Date(2024, 6, 13, 6, 0, 0, 0, time.UTC), 8.5), // Noch nicht gearbeitet
+	}
+	absences := []models.Absence{
+		{Type: models.AbsenceTypeVacation, Status: models.AbsenceStatusApproved, StartDate: date(2024, time.June, 3), EndDate: date(2024, time.June, 4)},
+		{Type: models.AbsenceTypeSick, Status: models.AbsenceStatusApproved, StartDate: date(2024, time.June, 5), EndDate: date(2024, time.June, 5)},
+		{Type: models.AbsenceTypeUnpaid, Status: models.AbsenceStatusApproved, StartDate: date(2024, time.June, 6), EndDate: date(2024, time.June, 6)},
+		{Type: models.AbsenceTypeCompensatory, Status: models.AbsenceStatusApproved, StartDate: date(2024, time.June, 7), EndDate: date(2024, time.June, 7)},
+		{Type: models.AbsenceTypeVacation, Status: models.AbsenceStatusRequested, StartDate: date(2024, time.June, 14), EndDate: date(2024, time.June, 14)},
+	}
+	corrections := []models.TimeAccountCorrection{{Date: date(2024, time.June, 28), Hours: -2.5, Reason: "Arzttermin"}}
+
+	days := calculator.Days(date(2024, time.June, 3), date(2024, time.June, 15), shifts, nil, absences, corrections)
+	assert.Equal(t, 8.0, days[0].CreditHours)   // Urlaub: Sollzeit
+	assert.Equal(t, 6.0, days[2].CreditHours)   // Krankheit: fester Wert
+	assert.Equal(t, 0.0, days[3].TargetHours)   // Unbezahlt: Soll entfällt
+	assert.Equal(t, 8.0, days[4].TargetHours)   // Freizeitausgleich: Soll bleibt
+	assert.Equal(t, 0.0, days[4].CreditHours)   // ... ohne Gutschrift
+	assert.Equal(t, 9.0, days[7].ActualHours)   // 10.06.
+	assert.Equal(t, 8.0, days[10].PlannedHours) // 13.06. geplant
+	assert.Equal(t, 0.0, days[10].ActualHours)  // ... aber noch nicht gearbeitet
+	assert.Equal(t, "", days[11].AbsenceType)   // Beantragter Urlaub zählt nicht
+
+	months := calculator.Months(date(2024, time.June, 1), date(2024, time.July, 1), 10, shifts, nil, absences, corrections, false)
+	assert.Len(t, months, 2)
+	june := months[0]
+	assert.Equal(t, 152.0, june.TargetHours) // 20 Werktage abzüglich unbezahltem Tag
+	assert.Equal(t, 26.0, june.PlannedHours)
+	assert.Equal(t, 18.0, june.ActualHours)
+	assert.Equal(t, 22.0, june.AbsenceCreditHours)
+	assert.Equal(t, -2.5, june.CorrectionHours)
+	assert.Equal(t, -114.5, june.Balance)
+	assert.Equal(t, 10.0, june.OpeningBalance)
+	assert.Equal(t, -104.5, june.ClosingBalance)
+	assert.Equal(t, -104.5, months[1].OpeningBalance)
+	assert.Nil(t, june.Days)
+
+	summary := SummarizeTimeAccount(months)
+	assert.Equal(t, "2024-06", summary.From)
+	assert.Equal(t, "2024-07", summary.To)
+	assert.Equal(t, 152.0+184.0, summary.TargetHours)
+	assert.Equal(t, months[1].ClosingBalance, summary.ClosingBalance)
+}
+
+func TestTimeAccountCalculator_ShiftDayInUserTimeZone(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	calculator := NewTimeAccountCalculator(berlin, WeeklyHoursTarget(0), nil, nil, date(2025, time.January, 1))
+
+	// Beginnt am 30.06. um 23:00 UTC, in Berlin bereits am 01.07.
+	shift := accountShift(time.Date(2024, 6, 30, 23, 0, 0, 0, time.UTC), 8.5)
+	months := calculator.Months(date(2024, time.June, 1), date(2024, time.July, 1), 0, []models.Shift{shift}, nil, nil, nil, false)
+	assert.Equal(t, 0.0, months[0].PlannedHours)
+	assert.Equal(t, 8.0, months[1].PlannedHours)
+}
+
+func TestTimeAccountCalculator_CallOuts(t *testing.T) {
+	calculator := NewTimeAccountCalculator(time.UTC, WeeklyHoursTarget(0), nil, nil, date(2024, time.July, 1))
+
+	clockOut := time.Date(2024, 6, 8, 3, 30, 0, 0, time.UTC)
+	callOuts := []models.TimeEntry{
+		{ClockIn: time.Date(2024, 6, 8, 1, 0, 0, 0, time.UTC), ClockOut: &clockOut, Source: models.TimeEntrySourceCallOut},
+		{ClockIn: time.Date(2024, 6, 9, 1, 0, 0, 0, time.UTC), Source: models.TimeEntrySourceCallOut}, // Läuft noch
+	}
+	months := calculator.Months(date(2024, time.June, 1), date(2024, time.June, 1), 0, nil, callOuts, nil, nil, true)
+	assert.Equal(t, 0.0, months[0].ActualHours)
+	assert.Equal(t, 2.5, months[0].CallOutHours)
+	assert.Equal(t, 2.5, months[0].Balance)
+	assert.Equal(t, 2.5, months[0].Days[7].CallOutHours)
+
+	lines := PayrollLines(models.User{Name: "Ruf"}, months[0], nil)
+	if assert.Len(t, lines, 1) {
+		assert.Equal(t, models.PayrollCategoryOvertime, lines[0].Category)
+		assert.Equal(t, 2.5, lines[0].Hours)
+	}
+}
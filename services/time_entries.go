@@ -0,0 +1,78 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// ShiftMatchLeadTime ist der Zeitraum vor Schichtbeginn, in dem ein Kommen der Schicht zugeordnet wird
+const ShiftMatchLeadTime = 2 * time.Hour
+
+// MaxTimeEntryDuration ist die maximale Dauer einer Zeitbuchung
+const MaxTimeEntryDuration = 24 * time.Hour
+
+// MatchShift ordnet einem Kommen zum Zeitpunkt at die passende geplante Schicht zu: die Schicht, in deren Zeitraum
+// [Beginn - ShiftMatchLeadTime, Ende) at liegt und deren Beginn at am nächsten ist. Liefert nil, wenn keine passt.
+func MatchShift(at time.Time, shifts []models.Shift) *models.Shift {
+	var best *models.Shift
+	var bestDistance time.Duration
+	for i := range shifts {
+		shift := &shifts[i]
+		if at.Before(shift.StartTime.Add(-ShiftMatchLeadTime)) || !at.Before(shift.EndTime) {
+			continue
+		}
+		distance := at.Sub(shift.StartTime)
+		if distance < 0 {
+			distance = -distance
+		}
+		if best == nil || distance < bestDistance {
+			best, bestDistance = shift, distance
+		}
+	}
+	return best
+}
+
+// ValidateTimeEntry prüft Kommen, Gehen und Pausen einer Zeitbuchung
+func ValidateTimeEntry(entry models.TimeEntry) error {
+	if entry.ClockIn.IsZero() {
+		return fmt.Errorf("Kommen ist ein Pflichtfeld")
+	}
+	if entry.ClockOut != nil {
+		if !entry.ClockOut.After(entry.ClockIn) {
+			return fmt.Errorf("Gehen muss nach dem Kommen liegen")
+		}
+		if entry.ClockOut.Sub(entry.ClockIn) > MaxTimeEntryDuration {
+			return fmt.Errorf("Zeitbuchung darf höchstens 24 Stunden dauern")
+		}
+	}
+
+	breaks := append([]models.TimeEntryBreak(nil), entry.Breaks...)
+	sort.Slice(breaks, func(i, j int) bool { return breaks[i].Start.Before(breaks[j].Start) })
+	for i, b := range breaks {
+		if b.Start.Before(entry.ClockIn) {
+			return fmt.Errorf("Pause darf nicht vor dem Kommen beginnen")
+		}
+		if b.End == nil {
+			if !entry.IsRunning() {
+				return fmt.Errorf("Pause muss vor dem Gehen beendet sein")
+			}
+			if i != len(breaks)-1 {
+				return fmt.Errorf("Es kann nur eine laufende Pause geben")
+			}
+			continue
+		}
+		if !b.End.After(b.Start) {
+			return fmt.Errorf("Pausenende muss nach dem Pausenbeginn liegen")
+		}
+		if entry.ClockOut != nil && b.End.After(*entry.ClockOut) {
+			return fmt.Errorf("Pause muss vor dem Gehen enden")
+		}
+		if i+1 < len(breaks) && breaks[i+1].Start.Before(*b.End) {
+			return fmt.Errorf("Pausen dürfen sich nicht überschneiden")
+		}
+	}
+	return nil
+}
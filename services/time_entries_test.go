@@ -0,0 +1,49 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchShift(t *testing.T) {
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	early := models.Shift{StartTime: day.Add(6 * time.Hour), EndTime: day.Add(14 * time.Hour)}
+	early.ID = 1
+	late := models.Shift{StartTime: day.Add(14 * time.Hour), EndTime: day.Add(22 * time.Hour)}
+	late.ID = 2
+	shifts := []models.Shift{early, late}
+
+	assert.Equal(t, uint(1), MatchShift(day.Add(5*time.Hour+45*time.Minute), shifts).ID)
+	assert.Equal(t, uint(1), MatchShift(day.Add(9*time.Hour), shifts).ID)
+	// Kurz vor der Spätschicht liegt deren Beginn näher
+	assert.Equal(t, uint(2), MatchShift(day.Add(13*time.Hour+50*time.Minute), shifts).ID)
+	assert.Nil(t, MatchShift(day.Add(3*time.Hour), shifts))
+	assert.Nil(t, MatchShift(day.Add(22*time.Hour), shifts))
+}
+
+func TestValidateTimeEntry(t *testing.T) {
+	start := time.Date(2024, 3, 4, 6, 0, 0, 0, time.UTC)
+	end := start.Add(8 * time.Hour)
+	at := func(minutes int) *time.Time {
+		value := start.Add(time.Duration(minutes) * time.Minute)
+		return &value
+	}
+
+	assert.NoError(t, ValidateTimeEntry(models.TimeEntry{ClockIn: start, ClockOut: &end}))
+	assert.NoError(t, ValidateTimeEntry(models.TimeEntry{ClockIn: start, Breaks: []models.TimeEntryBreak{{Start: *at(60)}}}))
+	assert.Error(t, ValidateTimeEntry(models.TimeEntry{ClockIn: end, ClockOut: &start}))
+	assert.Error(t, ValidateTimeEntry(models.TimeEntry{ClockIn: start, ClockOut: at(25 * 60)}))
+
+	// Pausen außerhalb der Buchung, überschneidend oder offen nach dem Gehen
+	assert.Error(t, ValidateTimeEntry(models.TimeEntry{ClockIn: start, ClockOut: &end, Breaks: []models.TimeEntryBreak{{Start: *at(-10), End: at(10)}}}))
+	assert.Error(t, ValidateTimeEntry(models.TimeEntry{ClockIn: start, ClockOut: &end, Breaks: []models.TimeEntryBreak{{Start: *at(470), End: at(490)}}}))
+	assert.Error(t, ValidateTimeEntry(models.TimeEntry{ClockIn: start, ClockOut: &end, Breaks: []models.TimeEntryBreak{
+		{Start: *at(60), End: at(90)}, {Start: *at(80), End: at(100)},
+	}}))
+	assert.Error(t, ValidateTimeEntry(models.TimeEntry{ClockIn: start, ClockOut: &end, Breaks: []models.TimeEntryBreak{{Start: *at(60)}}}))
+	assert.Error(t, ValidateTimeEntry(models.TimeEntry{ClockIn: start, Breaks: []models.TimeEntryBreak{{Start: *at(60)}, {Start: *at(120), End: at(130)}}}))
+}
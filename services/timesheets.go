@@ -0,0 +1,86 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// timesheetTransitions legt je Aktion die erlaubten Ausgangsstatus und den Zielstatus fest
+var timesheetTransitions = map[string]struct {
+	from []string
+	to   string
+}{
+	models.TimesheetActionSubmit:  {from: []string{models.TimesheetStatusOpen, models.TimesheetStatusReturned}, to: models.TimesheetStatusSubmitted},
+	models.TimesheetActionApprove: {from: []string{models.TimesheetStatusSubmitted}, to: models.TimesheetStatusApproved},
+	models.TimesheetActionReturn:  {from: []string{models.TimesheetStatusSubmitted}, to: models.TimesheetStatusReturned},
+	models.TimesheetActionReopen:  {from: []string{models.TimesheetStatusApproved}, to: models.TimesheetStatusOpen},
+}
+
+// TimesheetTransition liefert den Status eines Stundenzettels nach der Aktion action oder einen Fehler,
+// wenn die Aktion im Status status nicht erlaubt ist
+func TimesheetTransition(status, action string) (string, error) {
+	transition, ok := timesheetTransitions[action]
+	if !ok {
+		return "", fmt.Errorf("unbekannte Aktion: %s", action)
+	}
+	for _, from := range transition.from {
+		if from == status {
+			return transition.to, nil
+		}
+	}
+	return "", fmt.Errorf("Aktion %s ist im Status %s nicht möglich", action, status)
+}
+
+// TimesheetDays fasst geplante Schichten und Zeitbuchungen je Kalendertag [from, to) zusammen; from und to sind
+// Kalendertage (00:00 Uhr UTC). Schichten und Buchungen zählen zum Kalendertag ihres Beginns in loc,
+// laufende Buchungen bis now.
+func TimesheetDays(from, to time.Time, loc *time.Location, shifts []models.Shift, entries []models.TimeEntry, now time.Time) []models.TimesheetDay {
+	byDay := make(map[time.Time]*models.TimesheetDay)
+	dayOf := func(t time.Time) *models.TimesheetDay {
+		local := t.In(loc)
+		key := date(local.Year(), local.Month(), local.Day())
+		if byDay[key] == nil {
+			byDay[key] = &models.TimesheetDay{Date: key}
+		}
+		return byDay[key]
+	}
+
+	for _, shift := range shifts {
+		day := dayOf(shift.StartTime)
+		day.PlannedHours += netShiftHours(shift)
+		day.Shifts++
+	}
+	for _, entry := range entries {
+		day := dayOf(entry.ClockIn)
+		day.ActualHours += entry.WorkedDuration(now).Hours()
+		day.BreakMinutes += int(entry.BreakDuration(now) / time.Minute)
+		day.Entries++
+	}
+
+	days := make([]models.TimesheetDay, 0)
+	for key := from; key.Before(to); key = key.AddDate(0, 0, 1) {
+		day := models.TimesheetDay{Date: key}
+		if stored := byDay[key]; stored != nil {
+			day = *stored
+		}
+		day.PlannedHours = roundTo(day.PlannedHours, 2)
+		day.ActualHours = roundTo(day.ActualHours, 2)
+		day.Difference = roundTo(day.ActualHours-day.PlannedHours, 2)
+		days = append(days, day)
+	}
+	return days
+}
+
+// SummarizeTimesheet überträgt die Summen der Tageszeilen in den Stundenzettel
+func SummarizeTimesheet(timesheet *models.Timesheet, days []models.TimesheetDay) {
+	timesheet.PlannedHours, timesheet.ActualHours, timesheet.BreakMinutes = 0, 0, 0
+	for _, day := range days {
+		timesheet.PlannedHours += day.PlannedHours
+		timesheet.ActualHours += day.ActualHours
+		timesheet.BreakMinutes += day.BreakMinutes
+	}
+	timesheet.PlannedHours = roundTo(timesheet.PlannedHours, 2)
+	timesheet.ActualHours = roundTo(timesheet.ActualHours, 2)
+}
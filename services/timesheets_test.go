@@ -0,0 +1,75 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimesheetTransition(t *testing.T) {
+	status, err := TimesheetTransition(models.TimesheetStatusOpen, models.TimesheetActionSubmit)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TimesheetStatusSubmitted, status)
+
+	status, err = TimesheetTransition(models.TimesheetStatusReturned, models.TimesheetActionSubmit)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TimesheetStatusSubmitted, status)
+
+	status, err = TimesheetTransition(models.TimesheetStatusSubmitted, models.TimesheetActionReturn)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TimesheetStatusReturned, status)
+
+	status, err = TimesheetTransition(models.TimesheetStatusApproved, models.TimesheetActionReopen)
+	assert.NoError(t, err)
+	assert.Equal(t, models.TimesheetStatusOpen, status)
+
+	// Nicht eingereichte Stundenzettel können nicht freigegeben werden
+	_, err = TimesheetTransition(models.TimesheetStatusOpen, models.TimesheetActionApprove)
+	assert.Error(t, err)
+	_, err = TimesheetTransition(models.TimesheetStatusApproved, models.TimesheetActionSubmit)
+	assert.Error(t, err)
+	_, err = TimesheetTransition(models.TimesheetStatusOpen, "unbekannt")
+	assert.Error(t, err)
+}
+
+func TestTimesheetDays(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	from := date(2024, 3, 1)
+	to := date(2024, 4, 1)
+
+	// Nachtschicht ab 23:30 Uhr Ortszeit zählt zum 4. März
+	shifts := []models.Shift{
+		{StartTime: time.Date(2024, 3, 4, 6, 0, 0, 0, berlin), EndTime: time.Date(2024, 3, 4, 14, 0, 0, 0, berlin)},
+		{StartTime: time.Date(2024, 3, 4, 23, 30, 0, 0, berlin), EndTime: time.Date(2024, 3, 5, 6, 30, 0, 0, berlin)},
+	}
+	clockIn := time.Date(2024, 3, 4, 6, 0, 0, 0, berlin)
+	clockOut := clockIn.Add(9 * time.Hour)
+	breakEnd := clockIn.Add(4*time.Hour + 30*time.Minute)
+	entries := []models.TimeEntry{{
+		ClockIn:  clockIn,
+		ClockOut: &clockOut,
+		Breaks:   []models.TimeEntryBreak{{Start: clockIn.Add(4 * time.Hour), End: &breakEnd}},
+	}}
+
+	days := TimesheetDays(from, to, berlin, shifts, entries, clockOut)
+	assert.Len(t, days, 31)
+
+	day := days[3]
+	assert.Equal(t, date(2024, 3, 4), day.Date)
+	assert.Equal(t, 15.0, day.PlannedHours)
+	assert.Equal(t, 8.5, day.ActualHours)
+	assert.Equal(t, 30, day.BreakMinutes)
+	assert.Equal(t, -6.5, day.Difference)
+	assert.Equal(t, 2, day.Shifts)
+	assert.Equal(t, 1, day.Entries)
+	assert.Zero(t, days[4].PlannedHours)
+
+	var timesheet models.Timesheet
+	SummarizeTimesheet(&timesheet, days)
+	assert.Equal(t, 15.0, timesheet.PlannedHours)
+	assert.Equal(t, 8.5, timesheet.ActualHours)
+	assert.Equal(t, 30, timesheet.BreakMinutes)
+}
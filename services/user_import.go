@@ -0,0 +1,251 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/mail"
+	"strings"
+
+	"schichtplaner/models"
+)
+
+// InvitationTokenPrefix kennzeichnet Einladungen importierter Benutzer
+const InvitationTokenPrefix = "spi_"
+
+// initialPasswordAlphabet enthält keine leicht verwechselbaren Zeichen (0/O, 1/l/I)
+const initialPasswordAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// UserImportFields sind die Felder des Benutzerimports in der Reihenfolge des Exports
+var UserImportFields = []string{
+	models.UserImportFieldUsername,
+	models.UserImportFieldEmail,
+	models.UserImportFieldName,
+	models.UserImportFieldAccountNumber,
+	models.UserImportFieldTeam,
+	models.UserImportFieldRole,
+}
+
+// userImportRequiredFields müssen einer Spalte zugeordnet sein
+var userImportRequiredFields = []string{models.UserImportFieldUsername, models.UserImportFieldEmail, models.UserImportFieldName}
+
+// userImportColumns sind die Spaltenüberschriften im Export
+var userImportColumns = map[string]string{
+	models.UserImportFieldUsername:      "Benutzername",
+	models.UserImportFieldEmail:         "E-Mail",
+	models.UserImportFieldName:          "Name",
+	models.UserImportFieldAccountNumber: "Personalnummer",
+	models.UserImportFieldTeam:          "Team",
+	models.UserImportFieldRole:          "Rolle",
+}
+
+// userImportColumnAliases sind die Spaltenüberschriften (klein geschrieben), die ohne Zuordnung erkannt werden
+var userImportColumnAliases = map[string][]string{
+	models.UserImportFieldUsername:      {"benutzername", "username", "login", "anmeldename"},
+	models.UserImportFieldEmail:         {"e-mail", "email", "mail", "e-mail-adresse"},
+	models.UserImportFieldName:          {"name", "vollständiger name", "mitarbeiter"},
+	models.UserImportFieldAccountNumber: {"personalnummer", "account_number", "personalnr", "personalnr."},
+	models.UserImportFieldTeam:          {"team", "abteilung"},
+	models.UserImportFieldRole:          {"rolle", "role"},
+}
+
+// UserImportColumn liefert die Spaltenüberschrift eines Felds im Export, z.B. E-Mail
+func UserImportColumn(field string) string {
+	return userImportColumns[field]
+}
+
+// ParseUserImportCSV liest eine CSV-Datei mit Kopfzeile (Trennzeichen Semikolon oder Komma, optional mit UTF-8-BOM).
+// mapping ordnet Feldern Spalten zu (Feld → Überschrift); fehlende Felder werden anhand üblicher Überschriften erkannt.
+// Liefert die Spalten, die verwendete Zuordnung und die nicht leeren Zeilen.
+func ParseUserImportCSV(data string, mapping map[string]string) ([]string, map[string]string, []models.UserImportRow, error) {
+	data = strings.TrimPrefix(data, "\ufeff")
+	if strings.TrimSpace(data) == "" {
+		return nil, nil, nil, fmt.Errorf("CSV-Datei ist leer")
+	}
+
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.Comma = csvDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	columns, err := reader.Read()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("Kopfzeile konnte nicht gelesen werden: %w", err)
+	}
+	index := make(map[string]int, len(columns))
+	for i, column := range columns {
+		columns[i] = strings.TrimSpace(column)
+		index[strings.ToLower(columns[i])] = i
+	}
+
+	used := make(map[string]string, len(UserImportFields))
+	positions := make(map[string]int, len(UserImportFields))
+	for _, field := range UserImportFields {
+		if column, ok := mapping[field]; ok && strings.TrimSpace(column) != "" {
+			position, found := index[strings.ToLower(strings.TrimSpace(column))]
+			if !found {
+				return nil, nil, nil, fmt.Errorf("Spalte %q für %s nicht gefunden", column, field)
+			}
+			used[field], positions[field] = columns[position], position
+			continue
+		}
+		for _, alias := range userImportColumnAliases[field] {
+			if position, found := index[alias]; found {
+				used[field], positions[field] = columns[position], position
+				break
+			}
+		}
+	}
+	for field := range mapping {
+		if _, ok := userImportColumnAliases[field]; !ok {
+			return nil, nil, nil, fmt.Errorf("Unbekanntes Feld in der Zuordnung: %s", field)
+		}
+	}
+	for _, field := range userImportRequiredFields {
+		if _, ok := used[field]; !ok {
+			return columns, used, nil, fmt.Errorf("Keine Spalte für %s zugeordnet", field)
+		}
+	}
+
+	var rows []models.UserImportRow
+	for {
+		record, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, nil, nil, fmt.Errorf("CSV-Datei konnte nicht gelesen werden: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		value := func(field string) string {
+			position, ok := positions[field]
+			if !ok || position >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[position])
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		rows = append(rows, models.UserImportRow{
+			Line:          line,
+			Username:      value(models.UserImportFieldUsername),
+			Email:         value(models.UserImportFieldEmail),
+			Name:          value(models.UserImportFieldName),
+			AccountNumber: value(models.UserImportFieldAccountNumber),
+			Team:          value(models.UserImportFieldTeam),
+			Role:          value(models.UserImportFieldRole),
+		})
+	}
+	return columns, used, rows, nil
+}
+
+// csvDelimiter erkennt das Trennzeichen anhand der Kopfzeile (Semikolon, sonst Komma)
+func csvDelimiter(data string) rune {
+	header, _, _ := strings.Cut(data, "\n")
+	if strings.Count(header, ";") >= strings.Count(header, ",") && strings.Contains(header, ";") {
+		return ';'
+	}
+	return ','
+}
+
+// ValidateUserImport prüft die Zeilen eines Imports gegen die vorhandenen Benutzer und Teams: Pflichtfelder,
+// E-Mail-Format, Rolle, bekannte Teams sowie doppelte Benutzernamen, E-Mail-Adressen und Personalnummern
+// (in der Datei und im Bestand). Ergänzt Errors und TeamID und vereinheitlicht die Rolle.
+func ValidateUserImport(rows []models.UserImportRow, existing []models.User, teams []models.Team) {
+	teamIDs := make(map[string]uint, len(teams))
+	for _, team := range teams {
+		teamIDs[strings.ToLower(strings.TrimSpace(team.Name))] = team.ID
+	}
+	taken := map[string]map[string]string{"username": {}, "email": {}, "account_number": {}}
+	for _, user := range existing {
+		taken["username"][strings.ToLower(user.Username)] = "bereits vergeben"
+		taken["email"][strings.ToLower(user.Email)] = "bereits vergeben"
+		if user.AccountNumber != "" {
+			taken["account_number"][strings.ToLower(user.AccountNumber)] = "bereits vergeben"
+		}
+	}
+	labels := map[string]string{"username": "Benutzername", "email": "E-Mail", "account_number": "Personalnummer"}
+
+	for i := range rows {
+		row := &rows[i]
+		row.Errors = nil
+		if row.Username == "" {
+			row.Errors = append(row.Errors, "Benutzername fehlt")
+		}
+		if row.Email == "" {
+			row.Errors = append(row.Errors, "E-Mail fehlt")
+		} else if !ValidEmail(row.Email) {
+			row.Errors = append(row.Errors, fmt.Sprintf("Ungültige E-Mail-Adresse: %s", row.Email))
+		}
+		if row.Name == "" {
+			row.Errors = append(row.Errors, "Name fehlt")
+		}
+
+		row.Role = strings.ToLower(row.Role)
+		switch row.Role {
+		case "":
+			row.Role = models.RoleUser
+		case models.RoleAdmin, models.RoleManager, models.RoleEmployee, models.RoleUser:
+		default:
+			row.Errors = append(row.Errors, fmt.Sprintf("Unbekannte Rolle: %s", row.Role))
+		}
+
+		row.TeamID = nil
+		if row.Team != "" {
+			if id, ok := teamIDs[strings.ToLower(row.Team)]; ok {
+				row.TeamID = &id
+			} else {
+				row.Errors = append(row.Errors, fmt.Sprintf("Unbekanntes Team: %s", row.Team))
+			}
+		}
+
+		for _, check := range []struct{ key, value string }{
+			{"username", row.Username}, {"email", row.Email}, {"account_number", row.AccountNumber},
+		} {
+			if check.value == "" {
+				continue
+			}
+			value := strings.ToLower(check.value)
+			if previous, ok := taken[check.key][value]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("%s %s %s", labels[check.key], check.value, previous))
+				continue
+			}
+			taken[check.key][value] = fmt.Sprintf("doppelt (Zeile %d)", row.Line)
+		}
+	}
+}
+
+// ValidEmail prüft, ob value eine einzelne E-Mail-Adresse ohne Anzeigenamen ist
+func ValidEmail(value string) bool {
+	address, err := mail.ParseAddress(value)
+	return err == nil && address.Address == value && strings.Contains(value[strings.LastIndex(value, "@"):], ".")
+}
+
+// GenerateInitialPassword erzeugt ein zufälliges Startpasswort mit 12 Zeichen
+func GenerateInitialPassword() (string, error) {
+	password := make([]byte, 12)
+	limit := big.NewInt(int64(len(initialPasswordAlphabet)))
+	for i := range password {
+		n, err := rand.Int(rand.Reader, limit)
+		if err != nil {
+			return "", fmt.Errorf("Passwort konnte nicht erzeugt werden: %w", err)
+		}
+		password[i] = initialPasswordAlphabet[n.Int64()]
+	}
+	return string(password), nil
+}
+
+// GenerateInvitationToken erzeugt ein zufälliges Einladungstoken und liefert Klartext, SHA-256-Hash und Anzeigepräfix
+func GenerateInvitationToken() (token, hash, prefix string, err error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", fmt.Errorf("Token konnte nicht erzeugt werden: %w", err)
+	}
+	token = InvitationTokenPrefix + hex.EncodeToString(buf)
+	return token, HashAPIToken(token), token[:apiTokenDisplayLength], nil
+}
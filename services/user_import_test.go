@@ -0,0 +1,66 @@
+package services
+
+import (
+	"testing"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUserImportCSV(t *testing.T) {
+	data := "\ufeffBenutzername;E-Mail;Name;Personalnummer;Abteilung;Rolle\n" +
+		"saison1;saison1@example.com;Saison Eins;2001;Küche;\n" +
+		";;;;;\n" +
+		"\"saison2\";saison2@example.com;\"Zwei; Saison\";2002;;manager\n"
+	columns, mapping, rows, err := ParseUserImportCSV(data, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Benutzername", "E-Mail", "Name", "Personalnummer", "Abteilung", "Rolle"}, columns)
+	assert.Equal(t, "Abteilung", mapping[models.UserImportFieldTeam])
+	if assert.Len(t, rows, 2) {
+		assert.Equal(t, 2, rows[0].Line)
+		assert.Equal(t, "Küche", rows[0].Team)
+		assert.Equal(t, 4, rows[1].Line)
+		assert.Equal(t, "Zwei; Saison", rows[1].Name)
+	}
+
+	// Eigene Zuordnung mit Komma als Trennzeichen
+	data = "Login,Mail,Mitarbeitername\nsaison3,saison3@example.com,Saison Drei\n"
+	_, _, _, err = ParseUserImportCSV(data, nil)
+	assert.EqualError(t, err, "Keine Spalte für name zugeordnet")
+	_, mapping, rows, err = ParseUserImportCSV(data, map[string]string{"name": "mitarbeitername"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Mitarbeitername", mapping[models.UserImportFieldName])
+	assert.Equal(t, "Saison Drei", rows[0].Name)
+
+	_, _, _, err = ParseUserImportCSV(data, map[string]string{"name": "Vorname"})
+	assert.Error(t, err)
+	_, _, _, err = ParseUserImportCSV(data, map[string]string{"geburtstag": "Login"})
+	assert.Error(t, err)
+}
+
+func TestValidateUserImport(t *testing.T) {
+	team := models.Team{Base: models.Base{ID: 3}, Name: "Küche"}
+	existing := []models.User{{Username: "anna", Email: "anna@example.com", AccountNumber: "1001"}}
+	rows := []models.UserImportRow{
+		{Line: 2, Username: "ben", Email: "ben@example.com", Name: "Ben", Team: "küche", Role: "Manager"},
+		{Line: 3, Username: "Anna", Email: "anna2@example.com", Name: "Anna", AccountNumber: "1001"},
+		{Line: 4, Username: "carl", Email: "carl@", Name: "Carl", Team: "Bar", Role: "chef"},
+		{Line: 5, Username: "BEN", Email: "BEN@example.com", Name: ""},
+	}
+	ValidateUserImport(rows, existing, []models.Team{team})
+
+	assert.Empty(t, rows[0].Errors)
+	assert.Equal(t, models.RoleManager, rows[0].Role)
+	if assert.NotNil(t, rows[0].TeamID) {
+		assert.Equal(t, uint(3), *rows[0].TeamID)
+	}
+	assert.Equal(t, []string{"Benutzername Anna bereits vergeben", "Personalnummer 1001 bereits vergeben"}, rows[1].Errors)
+	assert.Equal(t, []string{"Ungültige E-Mail-Adresse: carl@", "Unbekannte Rolle: chef", "Unbekanntes Team: Bar"}, rows[2].Errors)
+	assert.Equal(t, []string{"Name fehlt", "Benutzername BEN doppelt (Zeile 2)", "E-Mail BEN@example.com doppelt (Zeile 2)"}, rows[3].Errors)
+	assert.Equal(t, models.RoleUser, rows[3].Role)
+
+	assert.True(t, ValidEmail("saison@example.com"))
+	assert.False(t, ValidEmail("Saison <saison@example.com>"))
+	assert.False(t, ValidEmail("saison@localhost"))
+}
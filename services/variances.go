@@ -0,0 +1,207 @@
+package services
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"schichtplaner/models"
+)
+
+// DefaultVarianceToleranceMinutes ist die Standardtoleranz für verspäteten Beginn und vorzeitiges Gehen
+const DefaultVarianceToleranceMinutes = 5
+
+// PlannedActualVariances vergleicht geplante Schichten mit Zeitbuchungen. Eine Zeitbuchung gehört zur zugeordneten
+// Schicht (ShiftID) oder zur nach MatchShift passenden Schicht des Benutzers. Abweichungen innerhalb der Toleranz
+// werden nicht gemeldet; Schichten ohne Zeitbuchung gelten erst nach ihrem Ende als nicht angetreten, an Tagen mit
+// genehmigter Abwesenheit (z.B. Krankheit oder Urlaub) gar nicht. users liefert Namen, Team und Zeitzone der Benutzer.
+func PlannedActualVariances(shifts []models.Shift, entries []models.TimeEntry, absences []models.Absence, users map[uint]models.User, tolerance time.Duration, now time.Time) []models.Variance {
+	shiftsByUser := make(map[uint][]models.Shift)
+	for _, shift := range shifts {
+		shiftsByUser[shift.UserID] = append(shiftsByUser[shift.UserID], shift)
+	}
+
+	absencesByUser := make(map[uint][]models.Absence)
+	for _, absence := range absences {
+		absencesByUser[absence.UserID] = append(absencesByUser[absence.UserID], absence)
+	}
+
+	variances := make([]models.Variance, 0)
+	entriesByShift := make(map[uint][]models.TimeEntry)
+	for _, entry := range entries {
+		// Einsätze in der Rufbereitschaft sind weder geplante noch ungeplante Schichtarbeit
+		if entry.OnCallDutyID != nil {
+			continue
+		}
+		var shift *models.Shift
+		if entry.ShiftID != nil {
+			for i := range shiftsByUser[entry.UserID] {
+				if shiftsByUser[entry.UserID][i].ID == *entry.ShiftID {
+					shift = &shiftsByUser[entry.UserID][i]
+				}
+			}
+		} else {
+			shift = MatchShift(entry.ClockIn, shiftsByUser[entry.UserID])
+		}
+		if shift != nil {
+			entriesByShift[shift.ID] = append(entriesByShift[shift.ID], entry)
+			continue
+		}
+
+		// Zugeordnete Schicht außerhalb des Zeitraums ist keine ungeplante Arbeit
+		if entry.ShiftID != nil {
+			continue
+		}
+		variance := newVariance(users[entry.UserID], models.VarianceUnplannedWork, entry.ClockIn)
+		variance.TimeEntryID = &entry.ID
+		variance.ActualStart = &entry.ClockIn
+		variance.ActualEnd = entry.ClockOut
+		variance.Minutes = int(entry.WorkedDuration(now) / time.Minute)
+		variances = append(variances, variance)
+	}
+
+	for _, shift := range shifts {
+		matched := entriesByShift[shift.ID]
+		base := newVariance(users[shift.UserID], "", shift.StartTime)
+		base.ShiftID = &shift.ID
+		base.PlannedStart = &shift.StartTime
+		base.PlannedEnd = &shift.EndTime
+
+		if len(matched) == 0 {
+			if shift.EndTime.After(now) {
+				continue
+			}
+			if _, ok := approvedAbsenceOn(absencesByUser[shift.UserID], base.Date); ok {
+				continue
+			}
+			variance := base
+			variance.Type = models.VarianceNoShow
+			variance.Minutes = int(netShiftHours(shift) * 60)
+			variances = append(variances, variance)
+			continue
+		}
+
+		first, last := matched[0], matched[0]
+		for _, entry := range matched[1:] {
+			if entry.ClockIn.Before(first.ClockIn) {
+				first = entry
+			}
+			if last.ClockOut != nil && (entry.ClockOut == nil || entry.ClockOut.After(*last.ClockOut)) {
+				last = entry
+			}
+		}
+
+		if late := first.ClockIn.Sub(shift.StartTime); late > tolerance {
+			variance := base
+			variance.Type = models.VarianceLateStart
+			variance.TimeEntryID = &first.ID
+			variance.ActualStart = &first.ClockIn
+			variance.ActualEnd = first.ClockOut
+			variance.Minutes = int(late / time.Minute)
+			variances = append(variances, variance)
+		}
+		if last.ClockOut != nil {
+			if early := shift.EndTime.Sub(*last.ClockOut); early > tolerance {
+				variance := base
+				variance.Type = models.VarianceEarlyLeave
+				variance.TimeEntryID = &last.ID
+				variance.ActualStart = &last.ClockIn
+				variance.ActualEnd = last.ClockOut
+				variance.Minutes = int(early / time.Minute)
+				variances = append(variances, variance)
+			}
+		}
+	}
+
+	sort.SliceStable(variances, func(i, j int) bool {
+		a, b := variances[i], variances[j]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		if a.UserName != b.UserName {
+			return a.UserName < b.UserName
+		}
+		return varianceStart(a).Before(varianceStart(b))
+	})
+	return variances
+}
+
+// SummarizeVariances zählt die Abweichungen je Benutzer, Team und Kalendertag
+func SummarizeVariances(variances []models.Variance) (byUser, byTeam, byDay []models.VarianceSummary) {
+	users := make(map[string]*models.VarianceSummary)
+	teams := make(map[string]*models.VarianceSummary)
+	days := make(map[string]*models.VarianceSummary)
+	var userKeys, teamKeys, dayKeys []string
+
+	add := func(index map[string]*models.VarianceSummary, keys *[]string, key, name string, variance models.Variance) {
+		summary := index[key]
+		if summary == nil {
+			summary = &models.VarianceSummary{Key: key, Name: name}
+			index[key] = summary
+			*keys = append(*keys, key)
+		}
+		switch variance.Type {
+		case models.VarianceLateStart:
+			summary.LateStarts++
+		case models.VarianceEarlyLeave:
+			summary.EarlyLeaves++
+		case models.VarianceUnplannedWork:
+			summary.UnplannedWork++
+		case models.VarianceNoShow:
+			summary.NoShows++
+		}
+		summary.Minutes += variance.Minutes
+	}
+
+	for _, variance := range variances {
+		add(users, &userKeys, strconv.FormatUint(uint64(variance.UserID), 10), variance.UserName, variance)
+		if variance.TeamID != nil {
+			add(teams, &teamKeys, strconv.FormatUint(uint64(*variance.TeamID), 10), variance.TeamName, variance)
+		} else {
+			add(teams, &teamKeys, "0", "Ohne Team", variance)
+		}
+		day := variance.Date.Format("2006-01-02")
+		add(days, &dayKeys, day, day, variance)
+	}
+
+	collect := func(index map[string]*models.VarianceSummary, keys []string, byName bool) []models.VarianceSummary {
+		result := make([]models.VarianceSummary, 0, len(keys))
+		for _, key := range keys {
+			result = append(result, *index[key])
+		}
+		if byName {
+			sort.SliceStable(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+		}
+		return result
+	}
+	// Abweichungen sind nach Datum sortiert, die Tage daher bereits in Reihenfolge
+	return collect(users, userKeys, true), collect(teams, teamKeys, true), collect(days, dayKeys, false)
+}
+
+// newVariance erzeugt eine Abweichung des Benutzers am Kalendertag des Zeitpunkts at in seiner Zeitzone
+func newVariance(user models.User, varianceType string, at time.Time) models.Variance {
+	local := at.In(user.Location())
+	variance := models.Variance{
+		Date:          date(local.Year(), local.Month(), local.Day()),
+		Type:          varianceType,
+		UserID:        user.ID,
+		UserName:      user.Name,
+		AccountNumber: user.AccountNumber,
+		TeamID:        user.TeamID,
+	}
+	if user.TeamID != nil {
+		variance.TeamName = user.Team.Name
+	}
+	return variance
+}
+
+// varianceStart liefert den Beginn einer Abweichung für die Sortierung
+func varianceStart(variance models.Variance) time.Time {
+	if variance.PlannedStart != nil {
+		return *variance.PlannedStart
+	}
+	if variance.ActualStart != nil {
+		return *variance.ActualStart
+	}
+	return variance.Date
+}
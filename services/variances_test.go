@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"schichtplaner/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlannedActualVariances(t *testing.T) {
+	teamID := uint(3)
+	alice := models.User{Name: "Alice", TimeZone: "UTC", TeamID: &teamID, Team: models.Team{Name: "Pflege"}}
+	alice.ID = 1
+	bob := models.User{Name: "Bob", TimeZone: "UTC"}
+	bob.ID = 2
+	users := map[uint]models.User{1: alice, 2: bob}
+
+	day := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	shift := func(id, userID uint, startHour, endHour int) models.Shift {
+		s := models.Shift{UserID: userID, StartTime: day.Add(time.Duration(startHour) * time.Hour), EndTime: day.Add(time.Duration(endHour) * time.Hour)}
+		s.ID = id
+		return s
+	}
+	entry := func(id, userID uint, start, end time.Duration) models.TimeEntry {
+		clockOut := day.Add(end)
+		e := models.TimeEntry{UserID: userID, ClockIn: day.Add(start), ClockOut: &clockOut}
+		e.ID = id
+		return e
+	}
+
+	shifts := []models.Shift{
+		shift(1, 1, 6, 14),  // 20 Minuten zu spät, 30 Minuten früher gegangen
+		shift(2, 1, 22, 30), // Nicht angetreten
+		shift(3, 2, 6, 14),  // Innerhalb der Toleranz
+		shift(4, 2, 40, 48), // Liegt in der Zukunft
+		shift(5, 2, 24, 30), // Genehmigte Krankmeldung
+	}
+	entries := []models.TimeEntry{
+		entry(1, 1, 6*time.Hour+20*time.Minute, 13*time.Hour+30*time.Minute),
+		entry(2, 2, 5*time.Hour+58*time.Minute, 13*time.Hour+57*time.Minute),
+		entry(3, 2, 16*time.Hour, 18*time.Hour), // Ohne Schicht
+	}
+	absences := []models.Absence{
+		{UserID: 2, Type: models.AbsenceTypeSick, Status: models.AbsenceStatusApproved, StartDate: day.AddDate(0, 0, 1), EndDate: day.AddDate(0, 0, 1)},
+	}
+	now := day.Add(32 * time.Hour)
+
+	variances := PlannedActualVariances(shifts, entries, absences, users, 5*time.Minute, now)
+	if !assert.Len(t, variances, 4) {
+		return
+	}
+
+	assert.Equal(t, models.VarianceLateStart, variances[0].Type)
+	assert.Equal(t, 20, variances[0].Minutes)
+	assert.Equal(t, "Pflege", variances[0].TeamName)
+	assert.Equal(t, models.VarianceEarlyLeave, variances[1].Type)
+	assert.Equal(t, 30, variances[1].Minutes)
+	assert.Equal(t, models.VarianceNoShow, variances[2].Type)
+	assert.Equal(t, 480, variances[2].Minutes)
+	assert.Equal(t, models.VarianceUnplannedWork, variances[3].Type)
+	assert.Equal(t, uint(2), variances[3].UserID)
+	assert.Equal(t, 120, variances[3].Minutes)
+	for _, variance := range variances {
+		assert.Equal(t, day, variance.Date)
+	}
+
+	// Ohne Toleranz wird auch Bobs Schicht gemeldet
+	assert.Len(t, PlannedActualVariances(shifts, entries, absences, users, 0, now), 5)
+
+	// Eine nur beantragte Abwesenheit entschuldigt die Schicht nicht
+	absences[0].Status = models.AbsenceStatusRequested
+	assert.Len(t, PlannedActualVariances(shifts, entries, absences, users, 5*time.Minute, now), 5)
+
+	byUser, byTeam, byDay := SummarizeVariances(variances)
+	if assert.Len(t, byUser, 2) {
+		assert.Equal(t, "Alice", byUser[0].Name)
+		assert.Equal(t, 1, byUser[0].LateStarts)
+		assert.Equal(t, 1, byUser[0].NoShows)
+		assert.Equal(t, 530, byUser[0].Minutes)
+	}
+	if assert.Len(t, byTeam, 2) {
+		assert.Equal(t, "Ohne Team", byTeam[0].Name)
+		assert.Equal(t, "3", byTeam[1].Key)
+	}
+	if assert.Len(t, byDay, 1) {
+		assert.Equal(t, "2024-03-04", byDay[0].Key)
+		assert.Equal(t, 1, byDay[0].UnplannedWork)
+	}
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// XLSXCell ist eine Zelle einer XLSX-Tabelle
+type XLSXCell struct {
+	Value  string
+	Number bool   // Value ist eine Zahl mit Punkt als Dezimaltrennzeichen
+	Fill   string // Hintergrundfarbe als Hex-Farbe, z.B. #F59E0B
+	Bold   bool
+}
+
+// XLSXSheet ist eine Tabelle mit optional fixierten Kopfzeilen und -spalten
+type XLSXSheet struct {
+	Name          string
+	Rows          [][]XLSXCell
+	ColumnWidths  []float64 // Breite je Spalte in Zeichen, 0 = Standard
+	FreezeRows    int
+	FreezeColumns int
+}
+
+// xlsxStyle ist eine Kombination aus Hintergrundfarbe und Schriftschnitt
+type xlsxStyle struct {
+	fill string
+	bold bool
+}
+
+// WriteXLSX erzeugt eine Excel-Arbeitsmappe (Office Open XML) mit einer Tabelle
+func WriteXLSX(sheet XLSXSheet) ([]byte, error) {
+	styles := []xlsxStyle{{}}
+	styleIndex := map[xlsxStyle]int{{}: 0}
+	fills := []string{}
+	fillIndex := map[string]int{}
+
+	var data strings.Builder
+	for r, row := range sheet.Rows {
+		fmt.Fprintf(&data, `<row r="%d">`, r+1)
+		for col, cell := range row {
+			style := xlsxStyle{fill: normalizeHexColor(cell.Fill), bold: cell.Bold}
+			index, ok := styleIndex[style]
+			if !ok {
+				index = len(styles)
+				styles = append(styles, style)
+				styleIndex[style] = index
+			}
+			if _, ok := fillIndex[style.fill]; style.fill != "" && !ok {
+				fillIndex[style.fill] = len(fills) + 2 // 0 und 1 sind von Excel vorgegeben
+				fills = append(fills, style.fill)
+			}
+
+			ref := XLSXColumnName(col) + strconv.Itoa(r+1)
+			switch {
+			case cell.Value == "":
+				if index != 0 {
+					fmt.Fprintf(&data, `<c r="%s" s="%d"/>`, ref, index)
+				}
+			case cell.Number:
+				fmt.Fprintf(&data, `<c r="%s" s="%d"><v>%s</v></c>`, ref, index, DAVText(cell.Value))
+			default:
+				fmt.Fprintf(&data, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, index, DAVText(cell.Value))
+			}
+		}
+		data.WriteString("</row>")
+	}
+
+	var worksheet strings.Builder
+	worksheet.WriteString(xml10Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if sheet.FreezeRows > 0 || sheet.FreezeColumns > 0 {
+		topLeft := XLSXColumnName(sheet.FreezeColumns) + strconv.Itoa(sheet.FreezeRows+1)
+		fmt.Fprintf(&worksheet, `<sheetViews><sheetView workbookViewId="0"><pane xSplit="%d" ySplit="%d" topLeftCell="%s" activePane="bottomRight" state="frozen"/></sheetView></sheetViews>`,
+			sheet.FreezeColumns, sheet.FreezeRows, topLeft)
+	}
+	if len(sheet.ColumnWidths) > 0 {
+		worksheet.WriteString("<cols>")
+		for i, width := range sheet.ColumnWidths {
+			if width > 0 {
+				fmt.Fprintf(&worksheet, `<col min="%d" max="%d" width="%.1f" customWidth="1"/>`, i+1, i+1, width)
+			}
+		}
+		worksheet.WriteString("</cols>")
+	}
+	worksheet.WriteString("<sheetData>" + data.String() + "</sheetData></worksheet>")
+
+	var stylesheet strings.Builder
+	stylesheet.WriteString(xml10Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	stylesheet.WriteString(`<fonts count="4"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font>` +
+		`<font><sz val="11"/><color rgb="FFFFFFFF"/><name val="Calibri"/></font><font><b/><sz val="11"/><color rgb="FFFFFFFF"/><name val="Calibri"/></font></fonts>`)
+	fmt.Fprintf(&stylesheet, `<fills count="%d"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>`, len(fills)+2)
+	for _, fill := range fills {
+		fmt.Fprintf(&stylesheet, `<fill><patternFill patternType="solid"><fgColor rgb="FF%s"/><bgColor indexed="64"/></patternFill></fill>`, fill)
+	}
+	stylesheet.WriteString(`</fills><borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>`)
+	stylesheet.WriteString(`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`)
+	fmt.Fprintf(&stylesheet, `<cellXfs count="%d">`, len(styles))
+	for _, style := range styles {
+		font, fill := 0, 0
+		if style.bold {
+			font = 1
+		}
+		if style.fill != "" {
+			fill = fillIndex[style.fill]
+			if isDarkColor(style.fill) {
+				font += 2
+			}
+		}
+		fmt.Fprintf(&stylesheet, `<xf numFmtId="0" fontId="%d" fillId="%d" borderId="0" xfId="0" applyFont="1" applyFill="1"/>`, font, fill)
+	}
+	stylesheet.WriteString(`</cellXfs><cellStyles count="1"><cellStyle name="Standard" xfId="0" builtinId="0"/></cellStyles></styleSheet>`)
+
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", xml10Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xml10Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml10Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` +
+			`<sheet name="` + DAVText(xlsxSheetName(sheet.Name)) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml10Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+			`</Relationships>`},
+		{"xl/worksheets/sheet1.xml", worksheet.String()},
+		{"xl/styles.xml", stylesheet.String()},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := writer.Write([]byte(file.content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Grenzen beim Lesen von Arbeitsmappen: entpackte Größe eines Teils sowie Zeilen, Spalten und Zellen der Tabelle.
+// Zeilen- und Spaltennummern stammen aus der Datei und werden vor dem Anlegen der Zeilen geprüft.
+const (
+	maxXLSXPartSize = 50 << 20
+	maxXLSXRows     = 10000
+	maxXLSXColumns  = 1000
+	maxXLSXCells    = 1000000
+)
+
+// xlsxRichText ist ein Text aus sharedStrings.xml oder einer Inline-Zelle, ggf. aufgeteilt in formatierte Abschnitte
+type xlsxRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+// String liefert den vollständigen Text
+func (t xlsxRichText) String() string {
+	text := t.Text
+	for _, run := range t.Runs {
+		text += run.Text
+	}
+	return text
+}
+
+// ReadXLSX liest die erste Tabelle einer Excel-Arbeitsmappe als Zeilen mit Zellwerten. Zeile i des Ergebnisses ist
+// Zeile i+1 der Tabelle, fehlende Zeilen und Zellen sind leer. Zahlen (auch Datumswerte) werden unverändert als Text
+// geliefert, z.B. 45383 für den 01.04.2024.
+func ReadXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("Keine gültige Excel-Datei (XLSX)")
+	}
+	parts := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		parts[file.Name] = file
+	}
+	readPart := func(name string, target interface{}) error {
+		file, ok := parts[name]
+		if !ok {
+			return fmt.Errorf("Excel-Datei enthält %s nicht", name)
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		if err := xml.NewDecoder(io.LimitReader(reader, maxXLSXPartSize)).Decode(target); err != nil {
+			return fmt.Errorf("%s konnte nicht gelesen werden: %w", name, err)
+		}
+		return nil
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			RelationID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := readPart("xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if len(workbook.Sheets) == 0 {
+		return nil, fmt.Errorf("Excel-Datei enthält keine Tabelle")
+	}
+	var relations struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := readPart("xl/_rels/workbook.xml.rels", &relations); err != nil {
+		return nil, err
+	}
+	sheetPart := ""
+	for _, relation := range relations.Relationships {
+		if relation.ID == workbook.Sheets[0].RelationID {
+			if strings.HasPrefix(relation.Target, "/") {
+				sheetPart = strings.TrimPrefix(relation.Target, "/")
+			} else {
+				sheetPart = path.Join("xl", relation.Target)
+			}
+		}
+	}
+	if sheetPart == "" {
+		return nil, fmt.Errorf("Erste Tabelle der Excel-Datei nicht gefunden")
+	}
+
+	var shared struct {
+		Items []xlsxRichText `xml:"si"`
+	}
+	if _, ok := parts["xl/sharedStrings.xml"]; ok {
+		if err := readPart("xl/sharedStrings.xml", &shared); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet struct {
+		Rows []struct {
+			Number int `xml:"r,attr"`
+			Cells  []struct {
+				Ref    string       `xml:"r,attr"`
+				Type   string       `xml:"t,attr"`
+				Value  string       `xml:"v"`
+				Inline xlsxRichText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := readPart(sheetPart, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	cells := 0
+	for _, row := range sheet.Rows {
+		number := row.Number
+		if number <= 0 {
+			number = len(rows) + 1
+		}
+		if number > maxXLSXRows {
+			return nil, fmt.Errorf("Excel-Datei hat zu viele Zeilen (höchstens %d)", maxXLSXRows)
+		}
+		for len(rows) < number {
+			rows = append(rows, nil)
+		}
+		var values []string
+		for _, cell := range row.Cells {
+			column := len(values)
+			if cell.Ref != "" {
+				if column, err = XLSXColumnIndex(cell.Ref); err != nil {
+					return nil, err
+				}
+			}
+			value := cell.Value
+			switch cell.Type {
+			case "s":
+				index, err := strconv.Atoi(strings.TrimSpace(cell.Value))
+				if err != nil || index < 0 || index >= len(shared.Items) {
+					return nil, fmt.Errorf("Zelle %s verweist auf einen unbekannten Text", cell.Ref)
+				}
+				value = shared.Items[index].String()
+			case "inlineStr":
+				value = cell.Inline.String()
+			}
+			if column >= maxXLSXColumns {
+				return nil, fmt.Errorf("Excel-Datei hat zu viele Spalten (höchstens %d)", maxXLSXColumns)
+			}
+			if grow := column + 1 - len(values); grow > 0 {
+				if cells += grow; cells > maxXLSXCells {
+					return nil, fmt.Errorf("Excel-Datei hat zu viele Zellen (höchstens %d)", maxXLSXCells)
+				}
+			}
+			for len(values) <= column {
+				values = append(values, "")
+			}
+			values[column] = value
+		}
+		rows[number-1] = values
+	}
+	return rows, nil
+}
+
+// XLSXColumnIndex liefert den Spaltenindex ab 0 eines Zellbezugs wie B7 oder AA12
+func XLSXColumnIndex(ref string) (int, error) {
+	index := 0
+	letters := 0
+	for _, r := range strings.ToUpper(ref) {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+		letters++
+	}
+	if letters == 0 || letters > 3 {
+		return 0, fmt.Errorf("Ungültiger Zellbezug %q", ref)
+	}
+	return index - 1, nil
+}
+
+// xml10Header ist die XML-Deklaration der Teile einer Arbeitsmappe
+const xml10Header = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// XLSXColumnName liefert den Spaltennamen zu einem Index ab 0, z.B. A, Z, AA
+func XLSXColumnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// xlsxSheetName entfernt in Tabellennamen unzulässige Zeichen und kürzt auf 31 Zeichen
+func xlsxSheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	if name == "" {
+		return "Tabelle1"
+	}
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+	return name
+}
+
+// normalizeHexColor liefert eine Hex-Farbe wie #f59e0b oder F59E0B als F59E0B; ungültige Werte als leeren String
+func normalizeHexColor(color string) string {
+	color = strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(color), "#"))
+	if len(color) == 3 {
+		color = string([]byte{color[0], color[0], color[1], color[1], color[2], color[2]})
+	}
+	if len(color) != 6 {
+		return ""
+	}
+	if _, err := strconv.ParseUint(color, 16, 32); err != nil {
+		return ""
+	}
+	return color
+}
+
+// isDarkColor prüft anhand der relativen Helligkeit, ob auf der Farbe weiße Schrift besser lesbar ist
+func isDarkColor(hex string) bool {
+	value, err := strconv.ParseUint(normalizeHexColor(hex), 16, 32)
+	if err != nil {
+		return false
+	}
+	r, g, b := float64(value>>16&0xFF), float64(value>>8&0xFF), float64(value&0xFF)
+	return 0.299*r+0.587*g+0.114*b < 150
+}